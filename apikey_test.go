@@ -0,0 +1,179 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	t.Parallel()
+
+	keys := map[string]*api.APIKey{
+		"orders-key": {ID: "acme", Tags: []string{"orders"}},
+		"refund-key": {ID: "support", Operations: []string{"refundInvoice"}},
+		"admin-key":  {ID: "ops", Tags: []string{"*"}},
+	}
+	r := api.New()
+	r.Use(api.APIKeyAuth(func(_ context.Context, key string) (*api.APIKey, error) {
+		if key == "broken" {
+			return nil, errors.New("key store unavailable")
+		}
+		return keys[key], nil
+	}))
+	whoami := func(ctx context.Context, _ *api.Void) (*api.Resp[string], error) {
+		p, _ := api.GetPrincipal(ctx)
+		key, ok := api.GetAPIKey(ctx)
+		require.True(t, ok)
+		assert.Equal(t, key.ID, p.ID)
+		return &api.Resp[string]{Body: p.ID}, nil
+	}
+	api.Get(r, "/orders", whoami, api.WithTags("orders"))
+	api.Get(r, "/invoices", whoami, api.WithTags("invoices"))
+	api.Post(r, "/invoices/{id}/refund", whoami, api.WithTags("invoices"), api.WithOperationID("refundInvoice"))
+	api.Get(r, "/status", func(_ context.Context, _ *api.Void) (*api.Resp[string], error) {
+		return &api.Resp[string]{Body: "up"}, nil
+	}, api.WithNoSecurity())
+
+	tests := map[string]struct {
+		method     string
+		path       string
+		key        string
+		wantStatus int
+		wantBody   string
+	}{
+		"allowed by tag": {
+			method:     http.MethodGet,
+			path:       "/orders",
+			key:        "orders-key",
+			wantStatus: http.StatusOK,
+			wantBody:   `"acme"`,
+		},
+		"tag not allowed": {
+			method:     http.MethodGet,
+			path:       "/invoices",
+			key:        "orders-key",
+			wantStatus: http.StatusForbidden,
+		},
+		"allowed by operation": {
+			method:     http.MethodPost,
+			path:       "/invoices/7/refund",
+			key:        "refund-key",
+			wantStatus: http.StatusOK,
+			wantBody:   `"support"`,
+		},
+		"operation not allowed": {
+			method:     http.MethodGet,
+			path:       "/invoices",
+			key:        "refund-key",
+			wantStatus: http.StatusForbidden,
+		},
+		"wildcard": {
+			method:     http.MethodGet,
+			path:       "/invoices",
+			key:        "admin-key",
+			wantStatus: http.StatusOK,
+			wantBody:   `"ops"`,
+		},
+		"missing key": {
+			method:     http.MethodGet,
+			path:       "/orders",
+			wantStatus: http.StatusUnauthorized,
+		},
+		"unknown key": {
+			method:     http.MethodGet,
+			path:       "/orders",
+			key:        "stolen",
+			wantStatus: http.StatusUnauthorized,
+		},
+		"lookup failure": {
+			method:     http.MethodGet,
+			path:       "/orders",
+			key:        "broken",
+			wantStatus: http.StatusInternalServerError,
+		},
+		"public route": {
+			method:     http.MethodGet,
+			path:       "/status",
+			wantStatus: http.StatusOK,
+			wantBody:   `"up"`,
+		},
+		"no route": {
+			method:     http.MethodGet,
+			path:       "/missing",
+			key:        "orders-key",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tc.method, tc.path, http.NoBody)
+			if tc.key != "" {
+				req.Header.Set("X-API-Key", tc.key)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantStatus, w.Code, w.Body.String())
+			if tc.wantBody != "" {
+				assert.JSONEq(t, tc.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuth_group_and_raw(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(_ context.Context, key string) (*api.APIKey, error) {
+		if key == "orders-key" {
+			return &api.APIKey{ID: "acme", Tags: []string{"orders"}}, nil
+		}
+		return nil, nil
+	}
+	r := api.New()
+	g := r.Group("/v1", api.WithGroupMiddleware(api.APIKeyAuth(lookup)))
+	api.Get(g, "/orders", func(_ context.Context, _ *api.Void) (*api.Resp[string], error) {
+		return &api.Resp[string]{Body: "orders"}, nil
+	}, api.WithTags("orders"))
+	api.Raw(g, http.MethodGet, "/invoices", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, api.OperationInfo{Tags: []string{"invoices"}})
+
+	tests := map[string]struct {
+		path       string
+		key        string
+		wantStatus int
+	}{
+		"typed allowed":    {path: "/v1/orders", key: "orders-key", wantStatus: http.StatusOK},
+		"typed missing":    {path: "/v1/orders", wantStatus: http.StatusUnauthorized},
+		"raw forbidden":    {path: "/v1/invoices", key: "orders-key", wantStatus: http.StatusForbidden},
+		"raw unknown key":  {path: "/v1/invoices", key: "stolen", wantStatus: http.StatusUnauthorized},
+		"outside of group": {path: "/v1/missing", key: "orders-key", wantStatus: http.StatusNotFound},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, http.NoBody)
+			if tc.key != "" {
+				req.Header.Set("X-API-Key", tc.key)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Principal is the authenticated caller. Authentication middleware stores it
+// with SetPrincipal; authorization reads it with GetPrincipal.
+type Principal struct {
+	// ID identifies the caller (user ID, client ID, subject).
+	ID string
+
+	// Roles lists the caller's roles, as consumed by RBAC.
+	Roles []string
+
+	// Scopes lists the OAuth2 scopes granted to the caller's token.
+	Scopes []string
+
+	// Attributes holds any further claims policies may inspect.
+	Attributes map[string]any
+}
+
+// HasRole reports whether the principal holds role.
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && slices.Contains(p.Roles, role)
+}
+
+// SetPrincipal stores the authenticated principal in the request context.
+// For use in authentication middleware.
+func SetPrincipal(r *http.Request, p *Principal) *http.Request {
+	return SetValue(r, p)
+}
+
+// GetPrincipal returns the principal stored by SetPrincipal.
+func GetPrincipal(ctx context.Context) (*Principal, bool) {
+	p, ok := GetValue[*Principal](ctx)
+	return p, ok && p != nil
+}
+
+// AccessRequest is the input to a PolicyEngine decision.
+type AccessRequest struct {
+	// Principal is the caller, or nil when the request is unauthenticated.
+	Principal *Principal
+
+	// Route describes the operation being invoked, with the group prefix
+	// applied and security resolved.
+	Route RouteDescription
+
+	// Input is the decoded request (a *Req for typed handlers), so policies
+	// can decide on path parameters or body fields.
+	Input any
+}
+
+// Decision is a PolicyEngine verdict. Reason is reported to the client on
+// denial, so keep it free of sensitive detail.
+type Decision struct {
+	Allow  bool
+	Reason string
+}
+
+// Allow returns an allowing Decision.
+func Allow() Decision { return Decision{Allow: true} }
+
+// Deny returns a denying Decision with the given reason.
+func Deny(reason string) Decision { return Decision{Reason: reason} }
+
+// PolicyEngine decides whether a request may proceed. An error (as opposed
+// to a denial) means no decision could be made and fails the request with
+// 500.
+type PolicyEngine interface {
+	Decide(ctx context.Context, req AccessRequest) (Decision, error)
+}
+
+// PolicyFunc is a function adapter that satisfies PolicyEngine.
+type PolicyFunc func(ctx context.Context, req AccessRequest) (Decision, error)
+
+// Decide implements PolicyEngine.
+func (f PolicyFunc) Decide(ctx context.Context, req AccessRequest) (Decision, error) {
+	return f(ctx, req)
+}
+
+// Authorize enforces policy on typed routes. The decision runs after the
+// request is decoded and before validation and the handler. Denials become
+// 403 Forbidden (401 Unauthorized when no principal is present) through the
+// route's error pipeline, so they render as ProblemDetails by default:
+//
+//	r := api.New(api.Authorize(api.NewRBAC(map[string][]string{
+//	    "admin":  {"*"},
+//	    "reader": {"listOrders", "getOrder"},
+//	})))
+//
+// Like WithError, the returned value can be passed to New, Group, or a
+// route; the innermost scope's policy replaces outer ones. Raw routes are
+// not covered.
+func Authorize(policy PolicyEngine) *AuthorizeScope {
+	return &AuthorizeScope{policy: policy}
+}
+
+// AuthorizeScope attaches a PolicyEngine at router, group, or route scope.
+// It implements RouterOption, GroupOption, and RouteOption.
+type AuthorizeScope struct {
+	policy PolicyEngine
+}
+
+// applyRouter implements the router-level option interface.
+func (s *AuthorizeScope) applyRouter(r *Router) { r.policy = s.policy }
+
+// applyGroup implements the group-level option interface.
+func (s *AuthorizeScope) applyGroup(g *Group) { g.policy = s.policy }
+
+// applyRoute implements the route-level option interface.
+func (s *AuthorizeScope) applyRoute(ri *routeInfo) { ri.policy = s.policy }
+
+// authorize consults policy and returns the API error for a denial.
+func authorize(ctx context.Context, policy PolicyEngine, route *RouteDescription, input any) error {
+	principal, _ := GetPrincipal(ctx)
+	d, err := policy.Decide(ctx, AccessRequest{Principal: principal, Route: *route, Input: input})
+	if err != nil {
+		return err
+	}
+	if d.Allow {
+		return nil
+	}
+	code := CodeForbidden
+	if principal == nil {
+		code = CodeUnauthorized
+	}
+	if d.Reason == "" {
+		return Error(code)
+	}
+	return Error(code, WithMessage(d.Reason))
+}
+
+// RBAC is a role-based PolicyEngine granting operation IDs to roles.
+type RBAC struct {
+	grants map[string]map[string]struct{}
+}
+
+// NewRBAC creates an RBAC policy from role → operation ID grants. The
+// operation ID "*" grants every operation. A principal is allowed when any
+// of its roles grants the route's operation ID.
+func NewRBAC(grants map[string][]string) *RBAC {
+	p := &RBAC{grants: make(map[string]map[string]struct{}, len(grants))}
+	for role, ops := range grants {
+		set := make(map[string]struct{}, len(ops))
+		for _, op := range ops {
+			set[op] = struct{}{}
+		}
+		p.grants[role] = set
+	}
+	return p
+}
+
+// Decide implements PolicyEngine.
+func (p *RBAC) Decide(_ context.Context, req AccessRequest) (Decision, error) {
+	if req.Principal == nil {
+		return Deny("authentication required"), nil
+	}
+	for _, role := range req.Principal.Roles {
+		ops := p.grants[role]
+		if _, ok := ops["*"]; ok {
+			return Allow(), nil
+		}
+		if _, ok := ops[req.Route.OperationID]; ok {
+			return Allow(), nil
+		}
+	}
+	if len(req.Principal.Roles) == 0 {
+		return Deny("no role grants " + req.Route.OperationID), nil
+	}
+	return Deny("roles " + strings.Join(req.Principal.Roles, ", ") + " do not grant " + req.Route.OperationID), nil
+}
+
+// PolicyInput maps an AccessRequest to the JSON-friendly document external
+// policy engines expect:
+//
+//	{
+//	  "principal": {"id": "...", "roles": [...], "scopes": [...], "attributes": {...}},
+//	  "operation": "getOrder",
+//	  "method": "GET",
+//	  "path": "/orders/{id}",
+//	  "tags": [...],
+//	  "input": <decoded request>
+//	}
+//
+// "principal" is null for unauthenticated requests.
+func PolicyInput(req AccessRequest) map[string]any {
+	var principal any
+	if p := req.Principal; p != nil {
+		principal = map[string]any{
+			"id":         p.ID,
+			"roles":      nonNil(p.Roles),
+			"scopes":     nonNil(p.Scopes),
+			"attributes": p.Attributes,
+		}
+	}
+	return map[string]any{
+		"principal": principal,
+		"operation": req.Route.OperationID,
+		"method":    req.Route.Method,
+		"path":      req.Route.Pattern,
+		"tags":      nonNil(req.Route.Tags),
+		"input":     req.Input,
+	}
+}
+
+// nonNil returns s, or an empty slice when s is nil, so it encodes as [].
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
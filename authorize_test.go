@@ -0,0 +1,186 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+// withPrincipal is test authentication middleware that reads roles from a
+// header.
+func withPrincipal(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if roles := r.Header.Get("X-Roles"); roles != "" {
+			r = api.SetPrincipal(r, &api.Principal{ID: "u1", Roles: strings.Split(roles, ",")})
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type authzOrderReq struct {
+	ID string `path:"id" doc:"Order ID"`
+}
+
+func TestAuthorize_rbac(t *testing.T) {
+	t.Parallel()
+
+	rbac := api.NewRBAC(map[string][]string{
+		"admin":  {"*"},
+		"reader": {"getOrder"},
+	})
+
+	r := api.New(api.Authorize(rbac))
+	r.Use(withPrincipal)
+	g := r.Group("/v1")
+	api.Get(g, "/orders/{id}", func(_ context.Context, req *authzOrderReq) (*api.Resp[string], error) {
+		return &api.Resp[string]{Body: req.ID}, nil
+	}, api.WithOperationID("getOrder"))
+	api.Delete(g, "/orders/{id}", func(_ context.Context, _ *authzOrderReq) (*api.Void, error) {
+		return &api.Void{}, nil
+	}, api.WithOperationID("deleteOrder"))
+
+	tests := map[string]struct {
+		method     string
+		roles      string
+		wantCode   int
+		wantDetail string
+	}{
+		"reader can read": {
+			method:   http.MethodGet,
+			roles:    "reader",
+			wantCode: http.StatusOK,
+		},
+		"reader cannot delete": {
+			method:     http.MethodDelete,
+			roles:      "reader",
+			wantCode:   http.StatusForbidden,
+			wantDetail: "roles reader do not grant deleteOrder",
+		},
+		"admin wildcard": {
+			method:   http.MethodDelete,
+			roles:    "guest,admin",
+			wantCode: http.StatusNoContent,
+		},
+		"anonymous": {
+			method:     http.MethodGet,
+			wantCode:   http.StatusUnauthorized,
+			wantDetail: "authentication required",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.method, "/v1/orders/o1", nil)
+			if tt.roles != "" {
+				req.Header.Set("X-Roles", tt.roles)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantDetail != "" {
+				assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+				var pd api.ProblemDetails
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pd))
+				assert.Equal(t, tt.wantDetail, pd.Detail)
+			}
+		})
+	}
+}
+
+func TestAuthorize_access_request(t *testing.T) {
+	t.Parallel()
+
+	var got api.AccessRequest
+	policy := api.PolicyFunc(func(_ context.Context, req api.AccessRequest) (api.Decision, error) {
+		got = req
+		return api.Allow(), nil
+	})
+
+	r := api.New()
+	r.Use(withPrincipal)
+	g := r.Group("/v1", api.WithGroupTags("orders"))
+	api.Get(g, "/orders/{id}", func(_ context.Context, req *authzOrderReq) (*api.Resp[string], error) {
+		return &api.Resp[string]{Body: req.ID}, nil
+	}, api.Authorize(policy))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/orders/o1", nil)
+	req.Header.Set("X-Roles", "reader")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NotNil(t, got.Principal)
+	assert.Equal(t, "u1", got.Principal.ID)
+	assert.True(t, got.Principal.HasRole("reader"))
+	assert.Equal(t, "/v1/orders/{id}", got.Route.Pattern)
+	assert.Equal(t, "getV1OrdersById", got.Route.OperationID)
+	assert.Equal(t, []string{"orders"}, got.Route.Tags)
+	in, ok := got.Input.(*authzOrderReq)
+	require.True(t, ok)
+	assert.Equal(t, "o1", in.ID)
+}
+
+func TestAuthorize_scopes(t *testing.T) {
+	t.Parallel()
+
+	deny := api.PolicyFunc(func(context.Context, api.AccessRequest) (api.Decision, error) {
+		return api.Deny("nope"), nil
+	})
+	allow := api.PolicyFunc(func(context.Context, api.AccessRequest) (api.Decision, error) {
+		return api.Allow(), nil
+	})
+	broken := api.PolicyFunc(func(context.Context, api.AccessRequest) (api.Decision, error) {
+		return api.Decision{}, errors.New("policy backend down")
+	})
+
+	r := api.New(api.Authorize(deny))
+	r.Use(withPrincipal)
+	api.Get(r, "/denied", voidHandler)
+	pub := r.Group("/public", api.Authorize(allow))
+	api.Get(pub, "/ok", voidHandler)
+	api.Get(pub, "/broken", voidHandler, api.Authorize(broken))
+
+	tests := map[string]struct {
+		path     string
+		wantCode int
+	}{
+		"router policy":         {path: "/denied", wantCode: http.StatusForbidden},
+		"group overrides":       {path: "/public/ok", wantCode: http.StatusNoContent},
+		"route overrides group": {path: "/public/broken", wantCode: http.StatusInternalServerError},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("X-Roles", "user")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestAuthorize_spec_documents_denials(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.Authorize(api.NewRBAC(nil)))
+	api.Get(r, "/things", voidHandler)
+
+	responses := r.Spec().Paths["/things"]["get"].Responses
+	assert.Contains(t, responses, "401")
+	assert.Contains(t, responses, "403")
+}
@@ -32,14 +32,6 @@ type bgQueue struct {
 	funcs []func(context.Context)
 }
 
-// withBackgroundQueue returns a context that carries an empty background
-// queue. Handlers append tasks via Background; the framework drains the queue
-// after the response is sent.
-func withBackgroundQueue(ctx context.Context) (context.Context, *bgQueue) {
-	q := &bgQueue{}
-	return context.WithValue(ctx, bgQueueKey{}, q), q
-}
-
 // runBackgroundTasks launches each queued task in its own goroutine with a
 // fresh background context. Panics are recovered and logged.
 func runBackgroundTasks(q *bgQueue) {
@@ -0,0 +1,268 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// BatchConfig tunes a PostBatch endpoint.
+type BatchConfig struct {
+	// Concurrency bounds how many items are handled at once. Zero or one
+	// handles them one at a time, in order.
+	Concurrency int
+
+	// MaxItems caps the number of items per request; a larger batch fails
+	// as a whole with 422. Zero means unlimited.
+	MaxItems int
+
+	// Status is the status of a successful item, as WithStatus sets it for
+	// the single-item route. A result whose status-tagged field is set
+	// reports that instead. Default: 200, or 204 for a Void result.
+	Status int
+}
+
+// BatchResponse is the 207 Multi-Status body of a PostBatch endpoint.
+type BatchResponse[T any] struct {
+	// Results holds one entry per request item, in request order.
+	Results []BatchResult[T] `json:"results"`
+}
+
+// BatchResult is the outcome of one item in a batch.
+type BatchResult[T any] struct {
+	// Index is the item's position in the request array.
+	Index int `json:"index"`
+
+	// Status is the HTTP status the item would have had on its own: its
+	// error's status on failure, else the result's status; see
+	// BatchConfig.Status.
+	Status int `json:"status"`
+
+	// Body is the item handler's response, set on success.
+	Body *T `json:"body,omitempty"`
+
+	// Error describes the failure, set when Status is 400 or above.
+	Error *ProblemDetails `json:"error,omitempty"`
+}
+
+// batchRequest is the request type registered for a PostBatch endpoint.
+type batchRequest[Item any] struct {
+	Body []Item
+}
+
+// PostBatch registers a POST endpoint that accepts a JSON array of items
+// and runs h once per item, so bulk endpoints reuse the single-item
+// handler:
+//
+//	api.PostBatch(r, "/users/batch", h.CreateUser, api.BatchConfig{Concurrency: 8, MaxItems: 100})
+//
+// Each item is validated on its own, as the single-item route would be.
+// The response is always 207 Multi-Status with a BatchResult per item: the
+// handler's response on success, or its error rendered as ProblemDetails.
+// One item failing does not affect the others. The spec documents the
+// array request body and the BatchResponse schema, named after the result
+// type (UserBatchResponse for User).
+func PostBatch[Item, Result any](reg Registrar, pattern string, h Handler[Item, Result], cfg BatchConfig, opts ...RouteOption) {
+	validator, mode := reg.getValidator(), reg.getMode()
+	batch := func(ctx context.Context, req *batchRequest[Item]) (*Resp[BatchResponse[Result]], error) {
+		body, err := runBulk(ctx, req.Body, h, cfg, validator, mode)
+		if err != nil {
+			return nil, err
+		}
+		return &Resp[BatchResponse[Result]]{Body: body}, nil
+	}
+
+	// Items are validated one by one, not as a whole request.
+	opts = append([]RouteOption{
+		WithStatus(http.StatusMultiStatus),
+		RouteOptionFunc(func(ri *routeInfo) {
+			ri.mode = ValidateConstraintsOff
+			nameBatchComponents[Result](ri)
+		}),
+	}, opts...)
+	register(reg, http.MethodPost, pattern, batch, opts...)
+}
+
+// BulkResp is the 207 Multi-Status response of a handler built with Bulk.
+// A route whose handler returns it defaults to status 207.
+type BulkResp[Result any] struct {
+	Body BatchResponse[Result]
+}
+
+func (BulkResp[Result]) multiStatus() {}
+
+func (BulkResp[Result]) nameComponents(ri *routeInfo) { nameBatchComponents[Result](ri) }
+
+// Bulk runs h once per item and collects a BatchResult per item, for bulk
+// endpoints that PostBatch does not fit, such as a PATCH with path params:
+//
+//	type PatchUsersReq struct {
+//	    OrgID string `path:"org"`
+//	    Body  []UserPatch
+//	}
+//
+//	func (h *Handlers) PatchUsers(ctx context.Context, req *PatchUsersReq) (*api.BulkResp[User], error) {
+//	    return api.Bulk(ctx, req.Body, func(ctx context.Context, p *UserPatch) (*User, error) {
+//	        return h.store.Patch(ctx, req.OrgID, p)
+//	    }, api.BatchConfig{Concurrency: 8, MaxItems: 100})
+//	}
+//
+// Items are validated and handled as PostBatch does, under the router's
+// validation mode, and a batch larger than cfg.MaxItems fails as a whole
+// with 422. Register the route WithMode(ValidateConstraintsOff), so that
+// one invalid item does not fail the whole request before Bulk runs.
+func Bulk[Item, Result any](ctx context.Context, items []Item, h Handler[Item, Result], cfg BatchConfig) (*BulkResp[Result], error) {
+	var (
+		validator ValidatorFunc
+		mode      ValidationMode
+	)
+	if r, ok := ctx.Value(routerKey{}).(*Router); ok {
+		validator, mode = r.validator, r.mode
+	}
+	body, err := runBulk(ctx, items, h, cfg, validator, mode)
+	if err != nil {
+		return nil, err
+	}
+	return &BulkResp[Result]{Body: body}, nil
+}
+
+// runBulk validates and handles each item, returning the per-item results.
+func runBulk[Item, Result any](ctx context.Context, items []Item, h Handler[Item, Result], cfg BatchConfig, validator ValidatorFunc, mode ValidationMode) (BatchResponse[Result], error) {
+	if cfg.MaxItems > 0 && len(items) > cfg.MaxItems {
+		return BatchResponse[Result]{}, ValidationErrors{{
+			Field:   "body",
+			Message: fmt.Sprintf("must have at most %d items", cfg.MaxItems),
+			Value:   len(items),
+		}}
+	}
+
+	runConstraints := func(item *Item) error { return validateConstraints(item) }
+	runPerType := func(ctx context.Context, item *Item) error {
+		if v, ok := any(item).(Validator); ok {
+			return v.Validate(ctx)
+		}
+		return nil
+	}
+	runRouter := func(item *Item) error {
+		if validator == nil {
+			return nil
+		}
+		return validator(item)
+	}
+
+	status := batchItemStatus[Result](cfg)
+	results := make([]BatchResult[Result], len(items))
+	runBatch(len(items), cfg.Concurrency, func(i int) {
+		res := BatchResult[Result]{Index: i}
+		item := &items[i]
+		var err error
+		for _, step := range validationSteps(ctx, mode, item, runConstraints, runPerType, runRouter) {
+			if err = step(); err != nil {
+				break
+			}
+		}
+		var out *Result
+		if err == nil {
+			out, err = h(ctx, item)
+		}
+		if err != nil {
+			pd := batchProblem(err)
+			res.Status, res.Error = pd.Status, pd
+		} else {
+			res.Status, res.Body = status(out), out
+		}
+		results[i] = res
+	})
+	return BatchResponse[Result]{Results: results}, nil
+}
+
+// runBatch calls fn for each index in [0, n), on up to concurrency
+// goroutines. A panic in fn is re-raised on the calling goroutine once the
+// other workers finish, so Recovery middleware still sees it.
+func runBatch(n, concurrency int, fn func(i int)) {
+	if concurrency <= 1 {
+		for i := range n {
+			fn(i)
+		}
+		return
+	}
+
+	var (
+		wg       sync.WaitGroup
+		panicked any
+		once     sync.Once
+	)
+	sem := make(chan struct{}, concurrency)
+	for i := range n {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() {
+				<-sem
+				if p := recover(); p != nil {
+					once.Do(func() { panicked = p })
+				}
+			}()
+			fn(i)
+		})
+	}
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+}
+
+// batchProblem renders an item error as the route would render it on its
+// own, minus the request-specific instance.
+func batchProblem(err error) *ProblemDetails {
+	err = resolveErr(err)
+	var apiErr *Err
+	if !errors.As(err, &apiErr) {
+		apiErr = &Err{code: CodeInternal, message: err.Error(), cause: err}
+	}
+	return NewProblemDetails(apiErr)
+}
+
+// batchItemStatus returns the status of a successful item with result
+// out, as the single-item route would answer.
+func batchItemStatus[Result any](cfg BatchConfig) func(out *Result) int {
+	t := reflect.TypeFor[Result]()
+	def := cfg.Status
+	if def == 0 {
+		def = http.StatusOK
+		if t == reflect.TypeFor[Void]() {
+			def = http.StatusNoContent
+		}
+	}
+	var index []int
+	if t.Kind() == reflect.Struct {
+		for _, f := range reflect.VisibleFields(t) {
+			if _, ok := f.Tag.Lookup("status"); ok {
+				index = f.Index
+				break
+			}
+		}
+	}
+	return func(out *Result) int {
+		if index != nil && out != nil {
+			if s := intFieldValue(reflect.ValueOf(out).Elem().FieldByIndex(index)); s != 0 {
+				return s
+			}
+		}
+		return def
+	}
+}
+
+// nameBatchComponents names the generic batch schemas of ri after the
+// result type, since Go's instantiated type names are not valid component
+// names.
+func nameBatchComponents[Result any](ri *routeInfo) {
+	name := componentName(reflect.TypeFor[Result]())
+	if name == "" {
+		return
+	}
+	ri.nameComponent(reflect.TypeFor[BatchResponse[Result]](), name+"BatchResponse")
+	ri.nameComponent(reflect.TypeFor[BatchResult[Result]](), name+"BatchResult")
+}
@@ -0,0 +1,255 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type btUser struct {
+	Name string `json:"name" minLength:"1"`
+}
+
+type btCreated struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+var errBtTaken = errors.New("name taken")
+
+func btCreate(_ context.Context, req *btUser) (*btCreated, error) {
+	switch req.Name {
+	case "taken":
+		return nil, api.Error(api.CodeConflict, api.WithMessage("name taken"))
+	case "boom":
+		return nil, errBtTaken
+	}
+	return &btCreated{ID: "id-" + req.Name, Name: req.Name}, nil
+}
+
+func postBatch(t *testing.T, r http.Handler, body string) (*httptest.ResponseRecorder, api.BatchResponse[btCreated]) {
+	t.Helper()
+
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/users/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var out api.BatchResponse[btCreated]
+	if rec.Code == http.StatusMultiStatus {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	}
+	return rec, out
+}
+
+func TestPostBatch(t *testing.T) {
+	t.Parallel()
+
+	for name, concurrency := range map[string]int{"sequential": 0, "concurrent": 4} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New()
+			api.PostBatch(r, "/users/batch", btCreate, api.BatchConfig{Concurrency: concurrency})
+
+			rec, out := postBatch(t, r, `[{"name":"ann"},{"name":""},{"name":"taken"},{"name":"boom"},{"name":"bob"}]`)
+			require.Equal(t, http.StatusMultiStatus, rec.Code, rec.Body.String())
+			require.Len(t, out.Results, 5)
+
+			for i, res := range out.Results {
+				assert.Equal(t, i, res.Index)
+			}
+			assert.Equal(t, http.StatusOK, out.Results[0].Status)
+			assert.Equal(t, &btCreated{ID: "id-ann", Name: "ann"}, out.Results[0].Body)
+			assert.Nil(t, out.Results[0].Error)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, out.Results[1].Status)
+			require.NotNil(t, out.Results[1].Error)
+			assert.Equal(t, api.CodeUnprocessableContent, out.Results[1].Error.Code)
+			assert.Nil(t, out.Results[1].Body)
+
+			assert.Equal(t, http.StatusConflict, out.Results[2].Status)
+			assert.Equal(t, "name taken", out.Results[2].Error.Detail)
+
+			assert.Equal(t, http.StatusInternalServerError, out.Results[3].Status)
+			assert.Equal(t, http.StatusOK, out.Results[4].Status)
+		})
+	}
+}
+
+func TestPostBatch_maxItems(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.PostBatch(r, "/users/batch", btCreate, api.BatchConfig{MaxItems: 1})
+
+	rec, _ := postBatch(t, r, `[{"name":"a"},{"name":"b"}]`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec, out := postBatch(t, r, `[]`)
+	assert.Equal(t, http.StatusMultiStatus, rec.Code)
+	assert.Empty(t, out.Results)
+}
+
+func TestPostBatch_concurrencyBound(t *testing.T) {
+	t.Parallel()
+
+	var running, peak atomic.Int32
+	h := func(_ context.Context, req *btUser) (*btCreated, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return &btCreated{Name: req.Name}, nil
+	}
+
+	r := api.New()
+	api.PostBatch(r, "/users/batch", h, api.BatchConfig{Concurrency: 2})
+
+	rec, out := postBatch(t, r, `[{"name":"a"},{"name":"b"},{"name":"c"},{"name":"d"},{"name":"e"}]`)
+	require.Equal(t, http.StatusMultiStatus, rec.Code)
+	assert.Len(t, out.Results, 5)
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestPostBatch_panicReachesRecovery(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	r.Use(api.Recovery())
+	api.PostBatch(r, "/users/batch", func(_ context.Context, _ *btUser) (*btCreated, error) {
+		panic("boom")
+	}, api.BatchConfig{Concurrency: 2})
+
+	rec, _ := postBatch(t, r, `[{"name":"a"},{"name":"b"}]`)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestPostBatch_spec(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.PostBatch(r, "/users/batch", btCreate, api.BatchConfig{})
+
+	spec := r.Spec()
+	op := spec.Paths["/users/batch"]["post"]
+
+	body := op.RequestBody.Content["application/json"].Schema
+	require.NotNil(t, body)
+	assert.Equal(t, "array", body.Type)
+	assert.Equal(t, "#/components/schemas/btUser", body.Items.Ref)
+
+	resp, ok := op.Responses["207"]
+	require.True(t, ok)
+	assert.Equal(t, "#/components/schemas/btCreatedBatchResponse", resp.Content["application/json"].Schema.Ref)
+
+	result := spec.Components.Schemas["btCreatedBatchResult"]
+	assert.Contains(t, result.Properties, "error")
+	assert.Equal(t, "#/components/schemas/btCreated", result.Properties["body"].Ref)
+}
+
+func TestBulk(t *testing.T) {
+	t.Parallel()
+
+	type patchUsersReq struct {
+		Org  string `path:"org"`
+		Body []btUser
+	}
+
+	r := api.New()
+	api.Patch(r, "/orgs/{org}/users", func(ctx context.Context, req *patchUsersReq) (*api.BulkResp[btCreated], error) {
+		return api.Bulk(ctx, req.Body, func(ctx context.Context, u *btUser) (*btCreated, error) {
+			created, err := btCreate(ctx, u)
+			if created != nil {
+				created.ID = req.Org + "/" + created.ID
+			}
+			return created, err
+		}, api.BatchConfig{Concurrency: 2, MaxItems: 3})
+	}, api.WithMode(api.ValidateConstraintsOff))
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/orgs/acme/users", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(`[{"name":"ann"},{"name":""},{"name":"taken"}]`)
+	require.Equal(t, http.StatusMultiStatus, rec.Code, rec.Body.String())
+	var out api.BatchResponse[btCreated]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Len(t, out.Results, 3)
+	assert.Equal(t, &btCreated{ID: "acme/id-ann", Name: "ann"}, out.Results[0].Body)
+	assert.Equal(t, http.StatusUnprocessableEntity, out.Results[1].Status)
+	assert.Equal(t, http.StatusConflict, out.Results[2].Status)
+
+	rec = send(`[{"name":"a"},{"name":"b"},{"name":"c"},{"name":"d"}]`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	op := r.Spec().Paths["/orgs/{org}/users"]["patch"]
+	resp, ok := op.Responses["207"]
+	require.True(t, ok)
+	assert.Equal(t, "#/components/schemas/btCreatedBatchResponse", resp.Content["application/json"].Schema.Ref)
+}
+
+func TestPostBatch_status(t *testing.T) {
+	t.Parallel()
+
+	type btUpserted struct {
+		Status int    `json:"-" status:""`
+		ID     string `json:"id"`
+	}
+	upsert := func(_ context.Context, req *btUser) (*btUpserted, error) {
+		if req.Name == "ann" {
+			return &btUpserted{Status: http.StatusOK, ID: "ann"}, nil
+		}
+		return &btUpserted{ID: req.Name}, nil
+	}
+
+	r := api.New()
+	api.PostBatch(r, "/users/batch", upsert, api.BatchConfig{Status: http.StatusCreated})
+
+	req := httptest.NewRequest(http.MethodPost, "/users/batch", strings.NewReader(`[{"name":"ann"},{"name":"bob"}]`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusMultiStatus, rec.Code, rec.Body.String())
+
+	var out api.BatchResponse[btUpserted]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Len(t, out.Results, 2)
+	assert.Equal(t, http.StatusOK, out.Results[0].Status)
+	assert.Equal(t, http.StatusCreated, out.Results[1].Status)
+}
+
+func TestPostBatch_components_per_router(t *testing.T) {
+	t.Parallel()
+
+	batched := api.New()
+	api.PostBatch(batched, "/users/batch", btCreate, api.BatchConfig{})
+
+	plain := api.New()
+	api.Get(plain, "/results", func(_ context.Context, _ *api.Void) (*api.Resp[api.BatchResponse[btCreated]], error) {
+		return &api.Resp[api.BatchResponse[btCreated]]{}, nil
+	})
+
+	assert.Contains(t, batched.Spec().Components.Schemas, "btCreatedBatchResponse")
+	assert.NotContains(t, plain.Spec().Components.Schemas, "btCreatedBatchResponse")
+}
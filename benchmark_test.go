@@ -3,6 +3,7 @@ package api_test
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/bjaus/api"
 )
 
@@ -42,6 +45,24 @@ func BenchmarkEmit_smallJSON(b *testing.B) {
 	}
 }
 
+// TestEmit_smallJSON_allocs keeps BenchmarkEmit_smallJSON's allocation
+// count from creeping up unnoticed.
+func TestEmit_smallJSON_allocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector adds allocations")
+	}
+	r := api.New()
+	api.Get(r, "/s", func(_ context.Context, _ *api.Void) (*api.Resp[benchSmallResp], error) {
+		return &api.Resp[benchSmallResp]{Body: benchSmallResp{ID: "x", Name: "n", Age: 42}}, nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "/s", http.NoBody)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	})
+	assert.LessOrEqual(t, allocs, float64(17))
+}
+
 // --- Medium JSON response (10 tagged fields, embedded type) ---
 
 type benchCacheHeaders struct {
@@ -193,6 +214,35 @@ func BenchmarkBind_smallRequest(b *testing.B) {
 	}
 }
 
+// --- Request binding: constraint tags ---
+
+type benchConstraintReq struct {
+	SKU    string `query:"sku" pattern:"^[A-Z]{3}-[0-9]{4}$"`
+	Email  string `query:"email" pattern:"^[^@]+@[^@]+$" maxLength:"254"`
+	Region string `query:"region" enum:"us,eu,ap"`
+	Limit  int    `query:"limit" minimum:"1" maximum:"100"`
+}
+
+func BenchmarkBind_constraints(b *testing.B) {
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *benchConstraintReq) (*api.Resp[benchSmallResp], error) {
+		return &api.Resp[benchSmallResp]{Body: benchSmallResp{ID: "x"}}, nil
+	})
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/items?sku=ABC-1234&email=a@b.c&region=eu&limit=10", nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for b.Loop() {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+	}
+}
+
 // --- Request binding: medium with body ---
 
 type benchMediumReq struct {
@@ -233,3 +283,109 @@ func BenchmarkBind_mediumRequestWithBody(b *testing.B) {
 		r.ServeHTTP(rec, req)
 	}
 }
+
+// --- Accept negotiation with and without the cache ---
+
+func BenchmarkNegotiate_accept(b *testing.B) {
+	for name, size := range map[string]int{"cached": 256, "uncached": 0} {
+		b.Run(name, func(b *testing.B) {
+			r := api.New(api.WithNegotiationCache(size))
+			api.Get(r, "/s", func(_ context.Context, _ *api.Void) (*api.Resp[benchSmallResp], error) {
+				return &api.Resp[benchSmallResp]{Body: benchSmallResp{ID: "x"}}, nil
+			})
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/s", nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			req.Header.Set("Accept", "text/html;q=0.9, application/xhtml+xml;q=0.8, application/json;q=0.7, */*;q=0.1")
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for b.Loop() {
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+			}
+		})
+	}
+}
+
+// --- Spec generation for a large route table ---
+
+type benchSpecItem struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name" minLength:"1"`
+	Labels  map[string]string `json:"labels"`
+	Created time.Time         `json:"created"`
+}
+
+type benchSpecReq struct {
+	ID    string `path:"id"`
+	Limit int    `query:"limit" default:"20"`
+	Body  benchSpecItem
+}
+
+func benchSpecRouter(b *testing.B, routes int) *api.Router {
+	b.Helper()
+	r := api.New(api.WithTitle("bench"))
+	for i := range routes {
+		api.Put(r, fmt.Sprintf("/r%d/{id}", i), func(_ context.Context, _ *benchSpecReq) (*api.Resp[benchSpecItem], error) {
+			return &api.Resp[benchSpecItem]{}, nil
+		})
+	}
+	return r
+}
+
+func BenchmarkSpec_write(b *testing.B) {
+	r := benchSpecRouter(b, 2000)
+
+	b.Run("WriteSpec", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if err := r.WriteSpec(io.Discard); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("WriteSpecStream", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if err := r.WriteSpecStream(io.Discard); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// --- Compress middleware ---
+
+// benchDiscardWriter is a ResponseWriter that drops the body, so the
+// benchmark counts only the middleware's allocations.
+type benchDiscardWriter struct{ header http.Header }
+
+func (w *benchDiscardWriter) Header() http.Header         { return w.header }
+func (w *benchDiscardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *benchDiscardWriter) WriteHeader(int)             {}
+
+func BenchmarkCompress(b *testing.B) {
+	for name, size := range map[string]int{"small": 64, "large": 16 << 10} {
+		body := bytes.Repeat([]byte(`{"id":"x","n":1},`), size/17+1)
+		handler := api.Compress()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(body) //nolint:errcheck
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				w := &benchDiscardWriter{header: make(http.Header)}
+				for pb.Next() {
+					clear(w.header)
+					handler.ServeHTTP(w, req)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// Patterns documenting string-encoded numbers in the spec.
+const (
+	bigIntPattern  = `^-?[0-9]+$`
+	decimalPattern = `^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`
+)
+
+// decimalTypes holds the types declared via RegisterDecimal.
+var decimalTypes sync.Map // map[reflect.Type]struct{}
+
+// RegisterDecimal declares T as an arbitrary-precision decimal, such as
+// shopspring/decimal.Decimal, so it is documented as a pattern-constrained
+// string instead of an object. Register once at init:
+//
+//	func init() { api.RegisterDecimal[decimal.Decimal]() }
+//
+// T must encode itself as a JSON string and implement
+// encoding.TextUnmarshaler for path, query, header, and cookie binding.
+// math/big.Float is supported without registration and is always carried
+// as a JSON string, so values never round-trip through float64.
+func RegisterDecimal[T any]() {
+	decimalTypes.Store(reflect.TypeFor[T](), struct{}{})
+}
+
+// WithBigIntStrings carries math/big.Int fields of JSON bodies as strings,
+// documented with a digits-only pattern, instead of the JSON numbers
+// encoding/json writes. Clients that parse numbers as float64, such as
+// JavaScript, then keep every digit:
+//
+//	r := api.New(api.WithBigIntStrings())
+//
+// Request bodies accept either form. Other codecs are unaffected.
+func WithBigIntStrings() RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.bigIntStrings = true
+	})
+}
+
+// numericStringSchema returns the schema for big numbers and registered
+// decimal types.
+func numericStringSchema(t reflect.Type) (JSONSchema, bool) {
+	switch t {
+	case reflect.TypeFor[big.Int]():
+		return JSONSchema{Type: "string", Format: "bigint", Pattern: bigIntPattern}, true
+	case reflect.TypeFor[big.Float]():
+		return JSONSchema{Type: "string", Format: "decimal", Pattern: decimalPattern}, true
+	}
+	if _, ok := decimalTypes.Load(t); ok {
+		return JSONSchema{Type: "string", Format: "decimal", Pattern: decimalPattern}, true
+	}
+	return JSONSchema{}, false
+}
+
+// bigIntConverter writes big.Int as a JSON number, or as a string when
+// quoted is set, even for values that are not addressable, which
+// encoding/json writes as {}. It decodes either form.
+func bigIntConverter(quoted bool) jsonConverter {
+	return jsonConverter{
+		encode: func(v reflect.Value) ([]byte, error) {
+			s := addrOf[big.Int](v).String()
+			if quoted {
+				return strconv.AppendQuote(nil, s), nil
+			}
+			return []byte(s), nil
+		},
+		decode: func(data []byte, dst reflect.Value) error {
+			s := unquoteNumber(data)
+			if _, ok := addrOf[big.Int](dst).SetString(s, 10); !ok {
+				return fmt.Errorf("big.Int: invalid value %s", data)
+			}
+			return nil
+		},
+	}
+}
+
+var bigFloatConverter = jsonConverter{
+	encode: func(v reflect.Value) ([]byte, error) {
+		return strconv.AppendQuote(nil, addrOf[big.Float](v).Text('g', -1)), nil
+	},
+	decode: func(data []byte, dst reflect.Value) error {
+		s := unquoteNumber(data)
+		if _, ok := addrOf[big.Float](dst).SetString(s); !ok {
+			return fmt.Errorf("big.Float: invalid value %s", data)
+		}
+		return nil
+	},
+}
+
+// addrOf returns a pointer to v's value, copying it when v is not
+// addressable. Copies are only made for reading.
+func addrOf[T any](v reflect.Value) *T {
+	if v.CanAddr() {
+		return v.Addr().Interface().(*T) //nolint:forcetypeassert // converter is registered for T
+	}
+	c := v.Interface().(T) //nolint:forcetypeassert // converter is registered for T
+	return &c
+}
+
+// unquoteNumber accepts a number either as a JSON string or a bare JSON
+// number, so clients that send numeric literals still decode exactly.
+func unquoteNumber(data []byte) string {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return s
+	}
+	return string(data)
+}
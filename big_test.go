@@ -0,0 +1,275 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type ledgerEntry struct {
+	Amount  big.Int    `json:"amount"`
+	Rate    *big.Float `json:"rate,omitempty"`
+	Balance *big.Int   `json:"balance,omitempty"`
+}
+
+// testDecimal stands in for a third-party decimal type.
+type testDecimal struct {
+	units int64
+	scale int
+}
+
+func (d testDecimal) MarshalText() ([]byte, error) {
+	return fmt.Appendf(nil, "%d.%02d", d.units/100, d.units%100), nil
+}
+
+func (d *testDecimal) UnmarshalText(b []byte) error {
+	var whole, frac int64
+	if _, err := fmt.Sscanf(string(b), "%d.%d", &whole, &frac); err != nil {
+		return err
+	}
+	d.units, d.scale = whole*100+frac, 2
+	return nil
+}
+
+func TestBigNumbers_round_trip(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Min  *big.Int `query:"min"`
+		Body ledgerEntry
+	}
+
+	r := api.New(api.WithBigIntStrings())
+	api.Post(r, "/ledger", func(_ context.Context, req *Req) (*api.Resp[ledgerEntry], error) {
+		out := req.Body
+		if req.Min != nil && out.Amount.Cmp(req.Min) < 0 {
+			return nil, api.Error(api.CodeBadRequest)
+		}
+		out.Balance = new(big.Int).Mul(&out.Amount, big.NewInt(2))
+		return &api.Resp[ledgerEntry]{Body: out}, nil
+	})
+
+	tests := map[string]struct {
+		query    string
+		body     string
+		wantCode int
+		want     string
+	}{
+		"strings": {
+			body:     `{"amount":"123456789012345678901234567890","rate":"0.1"}`,
+			wantCode: http.StatusOK,
+			want:     `{"amount":"123456789012345678901234567890","rate":"0.1","balance":"246913578024691357802469135780"}`,
+		},
+		"numeric literals accepted": {
+			body:     `{"amount":123456789012345678901234567890}`,
+			wantCode: http.StatusOK,
+			want:     `{"amount":"123456789012345678901234567890","balance":"246913578024691357802469135780"}`,
+		},
+		"query binding": {
+			query:    "?min=99999999999999999999999",
+			body:     `{"amount":"1"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid": {
+			body:     `{"amount":"12x"}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/ledger"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.want != "" {
+				assert.JSONEq(t, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBigNumbers_schema(t *testing.T) {
+	t.Parallel()
+
+	api.RegisterDecimal[testDecimal]()
+
+	type Price struct {
+		Total testDecimal `json:"total"`
+	}
+	type Req struct {
+		Limit testDecimal `query:"limit"`
+	}
+
+	r := api.New(api.WithBigIntStrings())
+	api.Get(r, "/ledger", func(_ context.Context, _ *Req) (*api.Resp[ledgerEntry], error) {
+		return nil, nil //nolint:nilnil // never called
+	})
+	api.Get(r, "/price", func(_ context.Context, _ *api.Void) (*api.Resp[Price], error) {
+		return nil, nil //nolint:nilnil // never called
+	})
+
+	spec := r.Spec()
+	entry := spec.Components.Schemas["ledgerEntry"]
+	assert.Equal(t, "string", entry.Properties["amount"].Type)
+	assert.Equal(t, "bigint", entry.Properties["amount"].Format)
+	assert.Equal(t, `^-?[0-9]+$`, entry.Properties["amount"].Pattern)
+	assert.Equal(t, "decimal", entry.Properties["rate"].Format)
+	assert.NotContains(t, spec.Components.Schemas, "Int")
+
+	assert.Equal(t, "decimal", spec.Components.Schemas["Price"].Properties["total"].Format)
+	assert.NotContains(t, spec.Components.Schemas, "testDecimal")
+
+	params := spec.Paths["/ledger"]["get"].Parameters
+	require.Len(t, params, 1)
+	assert.Equal(t, "string", params[0].Schema.Type)
+	assert.Equal(t, "decimal", params[0].Schema.Format)
+}
+
+func TestBigInt_numbers_by_default(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Post(r, "/ledger", func(_ context.Context, req *struct{ Body ledgerEntry }) (*api.Resp[ledgerEntry], error) {
+		out := req.Body
+		out.Balance = new(big.Int).Mul(&out.Amount, big.NewInt(2))
+		return &api.Resp[ledgerEntry]{Body: out}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/ledger", strings.NewReader(`{"amount":123456789012345678901234567890,"rate":"0.1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"amount":123456789012345678901234567890,"rate":"0.1","balance":246913578024691357802469135780}`, w.Body.String())
+
+	entry := r.Spec().Components.Schemas["ledgerEntry"]
+	assert.Equal(t, api.JSONSchema{Type: "integer"}, entry.Properties["amount"])
+	assert.Equal(t, "decimal", entry.Properties["rate"].Format)
+}
+
+func TestDecimal_binding(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Limit testDecimal `query:"limit"`
+	}
+
+	var got testDecimal
+	r := api.New()
+	api.Get(r, "/price", func(_ context.Context, req *Req) (*api.Void, error) {
+		got = req.Limit
+		return &api.Void{}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/price?limit=12.34", nil))
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	assert.Equal(t, int64(1234), got.units)
+}
+
+type mirrorInner struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Code string
+}
+
+type mirrorOther struct {
+	Code string
+}
+
+type mirrorShadowed struct {
+	mirrorInner
+	mirrorOther
+	ID     string  `json:"id"`
+	Amount big.Int `json:"amount"`
+}
+
+func TestBigNumbers_embedded_shadowed(t *testing.T) {
+	t.Parallel()
+
+	body := mirrorShadowed{
+		mirrorInner: mirrorInner{ID: "inner", Name: "n", Code: "a"},
+		mirrorOther: mirrorOther{Code: "b"},
+		ID:          "outer",
+	}
+	body.Amount.SetInt64(42)
+
+	r := api.New()
+	api.Get(r, "/shadowed", func(_ context.Context, _ *api.Void) (*api.Resp[mirrorShadowed], error) {
+		return &api.Resp[mirrorShadowed]{Body: body}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/shadowed", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"id":"outer","name":"n","amount":42}`, w.Body.String())
+}
+
+// MirrorAmounts is exported: encoding/json cannot allocate an embedded
+// pointer to an unexported struct.
+type MirrorAmounts struct {
+	Total big.Int `json:"total"`
+}
+
+type mirrorEmbeddedPtr struct {
+	*MirrorAmounts
+	Name string `json:"name"`
+}
+
+func TestBigNumbers_embedded_pointer(t *testing.T) {
+	t.Parallel()
+
+	var got mirrorEmbeddedPtr
+	r := api.New(api.WithBigIntStrings())
+	api.Post(r, "/amounts", func(_ context.Context, req *struct{ Body mirrorEmbeddedPtr }) (*api.Resp[mirrorEmbeddedPtr], error) {
+		got = req.Body
+		return &api.Resp[mirrorEmbeddedPtr]{Body: req.Body}, nil
+	})
+
+	tests := map[string]struct {
+		body      string
+		want      string
+		wantTotal string
+	}{
+		"set": {
+			body:      `{"name":"a","total":"123456789012345678901234567890"}`,
+			want:      `{"name":"a","total":"123456789012345678901234567890"}`,
+			wantTotal: "123456789012345678901234567890",
+		},
+		"nil pointer": {
+			body: `{"name":"a"}`,
+			want: `{"name":"a"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/amounts", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.JSONEq(t, tt.want, w.Body.String())
+			if tt.wantTotal == "" {
+				assert.Nil(t, got.MirrorAmounts)
+				return
+			}
+			require.NotNil(t, got.MirrorAmounts)
+			assert.Equal(t, tt.wantTotal, got.Total.String())
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithBodyDefaults applies `default` tags on request body fields after the
+// body is decoded, so handlers receive the values the schema documents. A
+// pointer field is defaulted when it is nil (absent from the payload); any
+// other field is defaulted when it holds its zero value, which cannot be
+// told apart from an explicit zero — use a pointer when zero is meaningful:
+//
+//	type CreateReq struct {
+//	    Body struct {
+//	        Name  string `json:"name"`
+//	        Limit *int   `json:"limit" default:"20"`
+//	    }
+//	}
+//
+// Defaults are applied through nested structs and non-nil struct pointers,
+// but not inside slices or maps. Defaults run before validation. A default
+// that cannot be parsed into its field's type panics at registration.
+func WithBodyDefaults() RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.bodyDefaults = true
+	})
+}
+
+// defaultPlan lists the fields of one struct type that carry a default or
+// contain nested fields that do.
+type defaultPlan struct {
+	fields []defaultField
+}
+
+type defaultField struct {
+	index int
+
+	// value is the parsed default, typed as the field (or its pointer
+	// element). Invalid when the field only has nested defaults.
+	value reflect.Value
+
+	// tag is the default as written, re-parsed on each use when value
+	// may hold references, such as a net.IP or a big.Int parsed through
+	// UnmarshalText, so requests never share its memory.
+	tag    string
+	shared bool
+
+	// nested is the plan for a struct or struct-pointer field.
+	nested *defaultPlan
+}
+
+// buildDefaultPlan compiles the default tags reachable from t. It returns
+// nil when there are none.
+func buildDefaultPlan(t reflect.Type) (*defaultPlan, error) {
+	return buildDefaultPlanSeen(t, map[reflect.Type]*defaultPlan{})
+}
+
+func buildDefaultPlanSeen(t reflect.Type, seen map[reflect.Type]*defaultPlan) (*defaultPlan, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil //nolint:nilnil // no plan for non-structs
+	}
+	if p, ok := seen[t]; ok {
+		// A recursive type shares the plan still being built.
+		return p, nil
+	}
+	plan := &defaultPlan{}
+	seen[t] = plan
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		df := defaultField{index: i}
+
+		if tag := f.Tag.Get("default"); tag != "" {
+			target := f.Type
+			if target.Kind() == reflect.Pointer {
+				target = target.Elem()
+			}
+			v := reflect.New(target).Elem()
+			if err := setFieldValue(v, tag); err != nil {
+				return nil, fmt.Errorf("field %s.%s: default %q: %w", t, f.Name, tag, err)
+			}
+			df.value = v
+			df.tag = tag
+			df.shared = holdsReferences(target)
+		}
+
+		if hasBodyDefaults(f.Type, map[reflect.Type]bool{}) {
+			nested, err := buildDefaultPlanSeen(f.Type, seen)
+			if err != nil {
+				return nil, err
+			}
+			df.nested = nested
+		}
+
+		if df.value.IsValid() || df.nested != nil {
+			plan.fields = append(plan.fields, df)
+		}
+	}
+
+	if len(plan.fields) == 0 {
+		return nil, nil //nolint:nilnil // nothing to default
+	}
+	return plan, nil
+}
+
+// hasBodyDefaults reports whether a default tag is reachable from t
+// through structs and struct pointers.
+func hasBodyDefaults(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visited[t] {
+		return false
+	}
+	visited[t] = true
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Tag.Get("default") != "" || hasBodyDefaults(f.Type, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply fills unset fields of the struct v.
+func (p *defaultPlan) apply(v reflect.Value) {
+	for i := range p.fields {
+		df := &p.fields[i]
+		fv := v.Field(df.index)
+		if df.value.IsValid() {
+			switch {
+			case fv.Kind() == reflect.Pointer && fv.IsNil():
+				ptr := reflect.New(df.value.Type())
+				ptr.Elem().Set(df.defaultValue())
+				fv.Set(ptr)
+			case fv.Kind() != reflect.Pointer && fv.IsZero():
+				fv.Set(df.defaultValue())
+			}
+		}
+		if df.nested == nil {
+			continue
+		}
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		df.nested.apply(fv)
+	}
+}
+
+// holdsReferences reports whether a value of t may point to memory that
+// copying it would share.
+func holdsReferences(t reflect.Type) bool {
+	//exhaustive:ignore
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return false
+	}
+	return true
+}
+
+// defaultValue returns the default to store in a request, a fresh copy
+// when it would otherwise be shared.
+func (df *defaultField) defaultValue() reflect.Value {
+	if !df.shared {
+		return df.value
+	}
+	v := reflect.New(df.value.Type()).Elem()
+	setFieldValue(v, df.tag) //nolint:errcheck,gosec // parsed at registration
+	return v
+}
+
+// applyBodyDefaults applies plan to the decoded body of req.
+func applyBodyDefaults(req any, desc *requestDescriptor, plan *defaultPlan) {
+	v := reflect.ValueOf(req).Elem()
+	if desc.category == catMixed {
+		v = v.FieldByIndex(desc.body.index)
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	plan.apply(v)
+}
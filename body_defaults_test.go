@@ -0,0 +1,186 @@
+package api_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type bdPaging struct {
+	Limit *int   `json:"limit,omitempty" default:"20"`
+	Order string `json:"order" default:"asc"`
+}
+
+type bdFilter struct {
+	Name    string        `json:"name"`
+	Active  *bool         `json:"active,omitempty" default:"true"`
+	Timeout time.Duration `json:"timeout" default:"5s"`
+	Paging  bdPaging      `json:"paging"`
+	Next    *bdFilter     `json:"next,omitempty"`
+}
+
+func TestWithBodyDefaults(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Tenant string `query:"tenant"`
+		Body   bdFilter
+	}
+
+	tests := map[string]struct {
+		body  string
+		check func(t *testing.T, got bdFilter)
+	}{
+		"absent fields are defaulted": {
+			body: `{"name":"x"}`,
+			check: func(t *testing.T, got bdFilter) {
+				require.NotNil(t, got.Active)
+				assert.True(t, *got.Active)
+				assert.Equal(t, 5*time.Second, got.Timeout)
+				require.NotNil(t, got.Paging.Limit)
+				assert.Equal(t, 20, *got.Paging.Limit)
+				assert.Equal(t, "asc", got.Paging.Order)
+				assert.Nil(t, got.Next)
+			},
+		},
+		"explicit pointer zero is kept": {
+			body: `{"active":false,"paging":{"limit":0,"order":"desc"}}`,
+			check: func(t *testing.T, got bdFilter) {
+				require.NotNil(t, got.Active)
+				assert.False(t, *got.Active)
+				require.NotNil(t, got.Paging.Limit)
+				assert.Equal(t, 0, *got.Paging.Limit)
+				assert.Equal(t, "desc", got.Paging.Order)
+			},
+		},
+		"recursive pointers are defaulted when present": {
+			body: `{"next":{"name":"y"}}`,
+			check: func(t *testing.T, got bdFilter) {
+				require.NotNil(t, got.Next)
+				assert.Equal(t, "asc", got.Next.Paging.Order)
+				require.NotNil(t, got.Next.Active)
+				assert.Nil(t, got.Next.Next)
+			},
+		},
+		"empty body": {
+			check: func(t *testing.T, got bdFilter) {
+				assert.Equal(t, "asc", got.Paging.Order)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got bdFilter
+			r := api.New(api.WithBodyDefaults())
+			api.Post(r, "/search", func(_ context.Context, req *Req) (*api.Void, error) {
+				got = req.Body
+				return &api.Void{}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestWithBodyDefaults_body_only_and_group(t *testing.T) {
+	t.Parallel()
+
+	var got bdPaging
+	r := api.New(api.WithBodyDefaults())
+	g := r.Group("/v1")
+	api.Post(g, "/page", func(_ context.Context, req *bdPaging) (*api.Void, error) {
+		got = *req
+		return &api.Void{}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/page", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	require.NotNil(t, got.Limit)
+	assert.Equal(t, 20, *got.Limit)
+}
+
+func TestWithBodyDefaults_disabled_by_default(t *testing.T) {
+	t.Parallel()
+
+	var got bdPaging
+	r := api.New()
+	api.Post(r, "/page", func(_ context.Context, req *bdPaging) (*api.Void, error) {
+		got = *req
+		return &api.Void{}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/page", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Nil(t, got.Limit)
+	assert.Empty(t, got.Order)
+}
+
+func TestWithBodyDefaults_invalid_default_panics(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Body struct {
+			Count int `json:"count" default:"many"`
+		}
+	}
+
+	r := api.New(api.WithBodyDefaults())
+	assert.Panics(t, func() {
+		api.Post(r, "/count", func(_ context.Context, _ *Req) (*api.Void, error) {
+			return &api.Void{}, nil
+		})
+	})
+}
+
+func TestWithBodyDefaults_reference_defaults_not_shared(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Body struct {
+			Bind net.IP `json:"bind" default:"127.0.0.1"`
+		}
+	}
+
+	r := api.New(api.WithBodyDefaults())
+	api.Post(r, "/listeners", func(_ context.Context, req *Req) (*api.Resp[string], error) {
+		bind := req.Body.Bind.String()
+		req.Body.Bind.To4()[3] = 99 // handlers may modify what they are given
+		return &api.Resp[string]{Body: bind}, nil
+	})
+
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, "/listeners", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.JSONEq(t, `"127.0.0.1"`, w.Body.String())
+	}
+}
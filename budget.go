@@ -0,0 +1,289 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// BudgetPhase names the part of a request a budget limits.
+type BudgetPhase string
+
+// Budget phases.
+const (
+	BudgetDecode   BudgetPhase = "decode"   // reading the request body
+	BudgetHandler  BudgetPhase = "handler"  // running the handler
+	BudgetResponse BudgetPhase = "response" // writing the response body
+)
+
+// BudgetViolation describes a request that exceeded one of its budgets. It
+// is passed to the WithBudgetObserver hook and attached to the error
+// response as a detail.
+type BudgetViolation struct {
+	Phase BudgetPhase `json:"phase"`
+
+	// Operation is the matched route as "METHOD pattern".
+	Operation string `json:"-"`
+
+	// MaxBytes is the byte budget of the decode and response phases.
+	MaxBytes int64 `json:"maxBytes,omitempty"`
+
+	// MaxTime is the time budget of the handler phase, encoded as
+	// nanoseconds in JSON.
+	MaxTime time.Duration `json:"maxTime,omitempty"`
+}
+
+// WithBudgetLimits caps what a single request may consume in each phase,
+// so one oversized or slow request cannot starve the others:
+//
+//	r := api.New(api.WithBudgetLimits(1<<20, 5*time.Second, 4<<20))
+//	api.Post(r, "/reports", h.Build, api.WithBudgetLimits(0, 30*time.Second, 64<<20))
+//
+// maxDecodeBytes bounds the request body the framework reads; exceeding it
+// fails with 413. maxHandlerTime is the deadline of the handler's context;
+// a handler that returns an error after it passes fails with 503 instead,
+// while a response the handler still completes is served.
+// maxResponseBytes bounds the encoded response body; the body is held back
+// until it completes or is flushed, so exceeding it fails with 500 rather
+// than a truncated body. A streamed response that exceeds it after flushing
+// is cut short. Zero leaves a phase unlimited.
+//
+// Each failure carries its BudgetViolation as a detail and is reported to
+// the WithBudgetObserver hook. Like WithError, the returned value can be
+// passed to New, Group, or a route; the innermost scope's limits replace
+// outer ones. Raw routes are not covered.
+func WithBudgetLimits(maxDecodeBytes int64, maxHandlerTime time.Duration, maxResponseBytes int64) *BudgetScope {
+	return &BudgetScope{limits: &budgetLimits{
+		decodeBytes:   maxDecodeBytes,
+		handlerTime:   maxHandlerTime,
+		responseBytes: maxResponseBytes,
+	}}
+}
+
+// BudgetScope attaches budget limits at router, group, or route scope. It
+// implements RouterOption, GroupOption, and RouteOption.
+type BudgetScope struct {
+	limits *budgetLimits
+}
+
+// applyRouter implements the router-level option interface.
+func (s *BudgetScope) applyRouter(r *Router) { r.budget = s.limits }
+
+// applyGroup implements the group-level option interface.
+func (s *BudgetScope) applyGroup(g *Group) { g.budget = s.limits }
+
+// applyRoute implements the route-level option interface.
+func (s *BudgetScope) applyRoute(ri *routeInfo) { ri.budget = s.limits }
+
+// WithBudgetObserver sets a hook called for every budget violation, to
+// count them per phase and operation in a metrics system.
+func WithBudgetObserver(fn func(ctx context.Context, v BudgetViolation)) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.budgetObserver = fn
+	})
+}
+
+type budgetLimits struct {
+	decodeBytes   int64
+	handlerTime   time.Duration
+	responseBytes int64
+}
+
+// codes returns the error codes the limits can produce, for the spec.
+func (l *budgetLimits) codes() []Code {
+	if l == nil {
+		return nil
+	}
+	var codes []Code
+	if l.decodeBytes > 0 {
+		codes = append(codes, CodeContentTooLarge)
+	}
+	if l.handlerTime > 0 {
+		codes = append(codes, CodeServiceUnavailable)
+	}
+	if l.responseBytes > 0 {
+		codes = append(codes, CodeInternal)
+	}
+	return codes
+}
+
+// budget enforces a route's limits on one request.
+type budget struct {
+	limits   *budgetLimits
+	observer func(ctx context.Context, v BudgetViolation)
+}
+
+// violation reports v to the observer and returns its error response.
+func (b budget) violation(r *http.Request, v BudgetViolation) error {
+	v.Operation = r.Pattern
+	if b.observer != nil {
+		b.observer(r.Context(), v)
+	}
+	switch v.Phase {
+	case BudgetDecode:
+		return Error(CodeContentTooLarge, WithMessagef("request body exceeds the decode budget of %d bytes", v.MaxBytes), WithDetail(v))
+	case BudgetHandler:
+		return Error(CodeServiceUnavailable, WithMessagef("handler exceeded its time budget of %s", v.MaxTime), WithDetail(v))
+	default:
+		return Error(CodeInternal, WithMessagef("response exceeds the budget of %d bytes", v.MaxBytes), WithDetail(v))
+	}
+}
+
+// limitBody caps the request body at the decode budget. exceeded reports
+// whether a read went past it.
+func (b budget) limitBody(r *http.Request) (exceeded func() bool) {
+	if b.limits == nil || b.limits.decodeBytes <= 0 || r.Body == nil {
+		return func() bool { return false }
+	}
+	br := &budgetReader{ReadCloser: r.Body, remaining: b.limits.decodeBytes}
+	r.Body = br
+	return func() bool { return br.exceeded }
+}
+
+// decodeErr replaces a decode error caused by the decode budget.
+func (b budget) decodeErr(r *http.Request, err error, exceeded func() bool) error {
+	if !exceeded() {
+		return err
+	}
+	return b.violation(r, BudgetViolation{Phase: BudgetDecode, MaxBytes: b.limits.decodeBytes})
+}
+
+// handlerContext applies the handler time budget to ctx.
+func (b budget) handlerContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.limits == nil || b.limits.handlerTime <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, b.limits.handlerTime, errHandlerBudget)
+}
+
+// handlerErr returns the violation when the handler, which returned err,
+// failed after outliving its budget. A handler that finished keeps its
+// response.
+func (b budget) handlerErr(ctx context.Context, r *http.Request, err error) error {
+	if err == nil || !errors.Is(context.Cause(ctx), errHandlerBudget) {
+		return err
+	}
+	return b.violation(r, BudgetViolation{Phase: BudgetHandler, MaxTime: b.limits.handlerTime})
+}
+
+// responseWriter holds the response back so an over-budget body can be
+// replaced by an error. finish commits it, or returns the violation.
+func (b budget) responseWriter(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, func() error) {
+	if b.limits == nil || b.limits.responseBytes <= 0 {
+		return w, func() error { return nil }
+	}
+	bw := &budgetWriter{ResponseWriter: w, remaining: b.limits.responseBytes}
+	return bw, func() error {
+		if bw.exceeded && !bw.committed {
+			h := w.Header()
+			h.Del("Content-Length")
+			h.Del("Content-Encoding")
+			return b.violation(r, BudgetViolation{Phase: BudgetResponse, MaxBytes: b.limits.responseBytes})
+		}
+		if bw.exceeded {
+			b.violation(r, BudgetViolation{Phase: BudgetResponse, MaxBytes: b.limits.responseBytes}) //nolint:errcheck,gosec // reported only; the body is already cut short
+		}
+		bw.commit()
+		return nil
+	}
+}
+
+var (
+	errHandlerBudget  = errors.New("handler time budget exceeded")
+	errDecodeBudget   = errors.New("request body exceeds decode budget")
+	errResponseBudget = errors.New("response exceeds budget")
+)
+
+// budgetReader fails reads past the decode budget.
+type budgetReader struct {
+	io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+func (br *budgetReader) Read(p []byte) (int, error) {
+	if br.remaining <= 0 {
+		// Probe for one more byte, so a body exactly at the budget passes.
+		var one [1]byte
+		n, err := br.ReadCloser.Read(one[:])
+		if n == 0 {
+			return 0, err
+		}
+		br.exceeded = true
+		return 0, errDecodeBudget
+	}
+	if int64(len(p)) > br.remaining {
+		p = p[:br.remaining]
+	}
+	n, err := br.ReadCloser.Read(p)
+	br.remaining -= int64(n)
+	return n, err
+}
+
+// budgetWriter buffers the response until it completes or is flushed,
+// failing writes past the response budget.
+type budgetWriter struct {
+	http.ResponseWriter
+	remaining int64
+	status    int
+	buf       bytes.Buffer
+	committed bool
+	exceeded  bool
+}
+
+func (bw *budgetWriter) WriteHeader(code int) {
+	if code < http.StatusOK {
+		bw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if bw.status == 0 {
+		bw.status = code
+	}
+}
+
+func (bw *budgetWriter) Write(p []byte) (int, error) {
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	if bw.exceeded || int64(len(p)) > bw.remaining {
+		bw.exceeded = true
+		return 0, errResponseBudget
+	}
+	bw.remaining -= int64(len(p))
+	if bw.committed {
+		return bw.ResponseWriter.Write(p)
+	}
+	return bw.buf.Write(p)
+}
+
+// Flush commits what is buffered so streams still stream.
+func (bw *budgetWriter) Flush() {
+	if bw.exceeded {
+		return
+	}
+	bw.commit()
+	if f, ok := bw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// commit sends the held status and body.
+func (bw *budgetWriter) commit() {
+	if bw.committed {
+		return
+	}
+	bw.committed = true
+	if bw.status == 0 {
+		return
+	}
+	bw.ResponseWriter.WriteHeader(bw.status)
+	//nolint:errcheck,gosec // best-effort after WriteHeader
+	bw.ResponseWriter.Write(bw.buf.Bytes())
+	bw.buf.Reset()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (bw *budgetWriter) Unwrap() http.ResponseWriter { return bw.ResponseWriter }
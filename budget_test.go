@@ -0,0 +1,175 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type budgetItem struct {
+	Name string `json:"name"`
+}
+
+type budgetReq struct {
+	Body budgetItem
+}
+
+func TestWithBudgetLimits(t *testing.T) {
+	t.Parallel()
+
+	echo := func(_ context.Context, req *budgetReq) (*api.Resp[budgetItem], error) {
+		return &api.Resp[budgetItem]{Body: req.Body}, nil
+	}
+	slow := func(ctx context.Context, _ *budgetReq) (*api.Resp[budgetItem], error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	late := func(ctx context.Context, req *budgetReq) (*api.Resp[budgetItem], error) {
+		<-ctx.Done()
+		return &api.Resp[budgetItem]{Body: req.Body}, nil
+	}
+
+	tests := map[string]struct {
+		handler    api.Handler[budgetReq, api.Resp[budgetItem]]
+		limits     *api.BudgetScope
+		body       string
+		wantStatus int
+		wantPhase  api.BudgetPhase
+	}{
+		"within budget": {
+			handler:    echo,
+			limits:     api.WithBudgetLimits(64, time.Second, 64),
+			body:       `{"name":"ok"}`,
+			wantStatus: http.StatusOK,
+		},
+		"body exactly at budget": {
+			handler:    echo,
+			limits:     api.WithBudgetLimits(int64(len(`{"name":"ok"}`)), 0, 0),
+			body:       `{"name":"ok"}`,
+			wantStatus: http.StatusOK,
+		},
+		"decode over budget": {
+			handler:    echo,
+			limits:     api.WithBudgetLimits(8, 0, 0),
+			body:       `{"name":"` + strings.Repeat("x", 64) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantPhase:  api.BudgetDecode,
+		},
+		"handler over budget": {
+			handler:    slow,
+			limits:     api.WithBudgetLimits(0, 10*time.Millisecond, 0),
+			body:       `{"name":"ok"}`,
+			wantStatus: http.StatusServiceUnavailable,
+			wantPhase:  api.BudgetHandler,
+		},
+		"handler finished after budget": {
+			handler:    late,
+			limits:     api.WithBudgetLimits(0, 10*time.Millisecond, 0),
+			body:       `{"name":"ok"}`,
+			wantStatus: http.StatusOK,
+		},
+		"response over budget": {
+			handler:    echo,
+			limits:     api.WithBudgetLimits(0, 0, 16),
+			body:       `{"name":"` + strings.Repeat("x", 32) + `"}`,
+			wantStatus: http.StatusInternalServerError,
+			wantPhase:  api.BudgetResponse,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu       sync.Mutex
+				observed []api.BudgetViolation
+			)
+			r := api.New(api.WithBudgetObserver(func(_ context.Context, v api.BudgetViolation) {
+				mu.Lock()
+				defer mu.Unlock()
+				observed = append(observed, v)
+			}))
+			api.Post(r, "/items", tt.handler, tt.limits)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(tt.body)))
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantPhase == "" {
+				assert.JSONEq(t, tt.body, w.Body.String())
+				assert.Empty(t, observed)
+				return
+			}
+
+			var pd struct {
+				Errors []api.BudgetViolation `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pd))
+			require.Len(t, pd.Errors, 1)
+			assert.Equal(t, tt.wantPhase, pd.Errors[0].Phase)
+
+			require.Len(t, observed, 1)
+			assert.Equal(t, tt.wantPhase, observed[0].Phase)
+			assert.Equal(t, "POST /items", observed[0].Operation)
+		})
+	}
+}
+
+func TestWithBudgetLimits_scopes(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.WithBudgetLimits(8, 0, 0))
+	relaxed := r.Group("/relaxed", api.WithBudgetLimits(1024, 0, 0))
+
+	handler := func(_ context.Context, req *budgetReq) (*api.Resp[budgetItem], error) {
+		return &api.Resp[budgetItem]{Body: req.Body}, nil
+	}
+	api.Post(r, "/strict", handler)
+	api.Post(relaxed, "/group", handler)
+	api.Post(r, "/route", handler, api.WithBudgetLimits(1024, 0, 0))
+
+	body := `{"name":"longer than eight bytes"}`
+	tests := map[string]struct {
+		path string
+		want int
+	}{
+		"router limit": {path: "/strict", want: http.StatusRequestEntityTooLarge},
+		"group limit":  {path: "/relaxed/group", want: http.StatusOK},
+		"route limit":  {path: "/route", want: http.StatusOK},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(body)))
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestWithBudgetLimits_spec(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Post(r, "/items", func(_ context.Context, req *budgetReq) (*api.Resp[budgetItem], error) {
+		return &api.Resp[budgetItem]{Body: req.Body}, nil
+	}, api.WithBudgetLimits(1024, time.Second, 4096))
+
+	responses := r.Spec().Paths["/items"]["post"].Responses
+	assert.Contains(t, responses, "413")
+	assert.Contains(t, responses, "503")
+	assert.Contains(t, responses, "500")
+}
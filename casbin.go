@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+)
+
+// CasbinEnforcer is the subset of *casbin.Enforcer used by Casbin, so this
+// package does not depend on casbin.
+type CasbinEnforcer interface {
+	Enforce(rvals ...any) (bool, error)
+}
+
+// CasbinConfig configures a Casbin-backed PolicyEngine.
+type CasbinConfig struct {
+	// Enforcer evaluates requests. Required.
+	Enforcer CasbinEnforcer
+
+	// Request maps an AccessRequest to the enforcer's request values.
+	// Defaults to CasbinSubjectObjectAction.
+	Request func(AccessRequest) []any
+}
+
+// Casbin returns a PolicyEngine backed by a casbin enforcer:
+//
+//	e, _ := casbin.NewEnforcer("model.conf", "policy.csv")
+//	r := api.New(api.Authorize(api.Casbin(api.CasbinConfig{Enforcer: e})))
+//
+// With the default mapping and an RBAC model, the policy lines
+// "p, reader, /orders/{id}, GET" and "g, alice, reader" grant the principal
+// with ID "alice" that route.
+func Casbin(cfg CasbinConfig) PolicyEngine {
+	if cfg.Enforcer == nil {
+		panic("api: Casbin requires an Enforcer")
+	}
+	if cfg.Request == nil {
+		cfg.Request = CasbinSubjectObjectAction
+	}
+	return PolicyFunc(func(_ context.Context, req AccessRequest) (Decision, error) {
+		if req.Principal == nil {
+			return Deny("authentication required"), nil
+		}
+		ok, err := cfg.Enforcer.Enforce(cfg.Request(req)...)
+		if err != nil {
+			return Decision{}, err
+		}
+		if !ok {
+			return Deny(""), nil
+		}
+		return Allow(), nil
+	})
+}
+
+// CasbinSubjectObjectAction maps a request to the classic (sub, obj, act)
+// triple: the principal ID, the route pattern, and the HTTP method.
+func CasbinSubjectObjectAction(req AccessRequest) []any {
+	var sub string
+	if req.Principal != nil {
+		sub = req.Principal.ID
+	}
+	return []any{sub, req.Route.Pattern, req.Route.Method}
+}
+
+// CasbinOperation maps a request to (sub, operationID), for models that
+// grant operations rather than paths.
+func CasbinOperation(req AccessRequest) []any {
+	var sub string
+	if req.Principal != nil {
+		sub = req.Principal.ID
+	}
+	return []any{sub, req.Route.OperationID}
+}
@@ -0,0 +1,93 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+// fakeEnforcer allows the request tuples it was built with.
+type fakeEnforcer struct {
+	allowed map[string]bool
+	err     error
+}
+
+func (e *fakeEnforcer) Enforce(rvals ...any) (bool, error) {
+	if e.err != nil {
+		return false, e.err
+	}
+	return e.allowed[fmt.Sprint(rvals...)], nil
+}
+
+func TestCasbin(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		enforcer *fakeEnforcer
+		request  func(api.AccessRequest) []any
+		roles    string
+		wantCode int
+	}{
+		"subject object action": {
+			enforcer: &fakeEnforcer{allowed: map[string]bool{fmt.Sprint("u1", "/orders/{id}", "GET"): true}},
+			roles:    "reader",
+			wantCode: http.StatusOK,
+		},
+		"denied": {
+			enforcer: &fakeEnforcer{allowed: map[string]bool{}},
+			roles:    "reader",
+			wantCode: http.StatusForbidden,
+		},
+		"operation mapping": {
+			enforcer: &fakeEnforcer{allowed: map[string]bool{fmt.Sprint("u1", "getOrder"): true}},
+			request:  api.CasbinOperation,
+			roles:    "reader",
+			wantCode: http.StatusOK,
+		},
+		"anonymous": {
+			enforcer: &fakeEnforcer{allowed: map[string]bool{}},
+			wantCode: http.StatusUnauthorized,
+		},
+		"enforcer error": {
+			enforcer: &fakeEnforcer{err: errors.New("adapter unavailable")},
+			roles:    "reader",
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policy := api.Casbin(api.CasbinConfig{Enforcer: tt.enforcer, Request: tt.request})
+			r := api.New(api.Authorize(policy))
+			r.Use(withPrincipal)
+			api.Get(r, "/orders/{id}", func(_ context.Context, req *authzOrderReq) (*api.Resp[string], error) {
+				return &api.Resp[string]{Body: req.ID}, nil
+			}, api.WithOperationID("getOrder"))
+
+			req := httptest.NewRequest(http.MethodGet, "/orders/o1", nil)
+			if tt.roles != "" {
+				req.Header.Set("X-Roles", tt.roles)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestCasbin_requires_enforcer(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() { api.Casbin(api.CasbinConfig{}) })
+}
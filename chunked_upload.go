@@ -0,0 +1,425 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // offered for client compatibility, not security
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // offered for client compatibility, not security
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Sentinel errors returned by UploadStore implementations.
+var (
+	// ErrUploadNotFound is returned when an upload ID is unknown.
+	ErrUploadNotFound = errors.New("upload not found")
+
+	// ErrUploadOffsetMismatch is returned when a chunk's offset does not
+	// match the upload's current offset (e.g. a concurrent append won).
+	ErrUploadOffsetMismatch = errors.New("upload offset mismatch")
+)
+
+// defaultMaxChunkSize bounds a single PATCH when ChunkedUploadConfig
+// leaves MaxChunkSize unset.
+const defaultMaxChunkSize = 8 << 20
+
+// UploadInfo is the persisted state of a resumable upload.
+type UploadInfo struct {
+	ID        string
+	Size      int64
+	Offset    int64
+	Metadata  map[string]string
+	Completed bool
+}
+
+// UploadStore persists resumable uploads. Implementations must make
+// WriteChunk atomic with respect to the offset check so that concurrent
+// appends cannot interleave.
+type UploadStore interface {
+	// Create records a new, empty upload.
+	Create(ctx context.Context, info UploadInfo) error
+
+	// Info returns the upload's current state, or ErrUploadNotFound.
+	Info(ctx context.Context, id string) (UploadInfo, error)
+
+	// WriteChunk appends data at offset and advances the upload's offset.
+	// It returns ErrUploadOffsetMismatch if offset is no longer current.
+	WriteChunk(ctx context.Context, id string, offset int64, data []byte) error
+
+	// Complete marks a fully written upload as finished.
+	Complete(ctx context.Context, id string) error
+}
+
+// ChunkedUploadConfig configures ChunkedUpload.
+type ChunkedUploadConfig struct {
+	// Store persists upload state and data. Required.
+	Store UploadStore
+
+	// MaxSize caps the declared size of an upload. Zero means unlimited.
+	MaxSize int64
+
+	// MaxChunkSize caps the bytes accepted by one append. Defaults to 8 MB.
+	MaxChunkSize int64
+
+	// OnComplete runs after an upload is marked complete, before the
+	// response is sent. An error fails the complete request.
+	OnComplete func(ctx context.Context, info UploadInfo) error
+
+	// Tags are applied to the generated operations. Defaults to "uploads".
+	Tags []string
+}
+
+// UploadCreateRequest starts a resumable upload.
+type UploadCreateRequest struct {
+	RawRequest
+	Body struct {
+		Size     int64             `json:"size" minimum:"1" required:"true" doc:"Total upload size in bytes"`
+		Metadata map[string]string `json:"metadata,omitempty" doc:"Opaque client metadata"`
+	}
+}
+
+// UploadIDRequest addresses an existing upload.
+type UploadIDRequest struct {
+	RawRequest
+	ID string `path:"id" doc:"Upload ID"`
+}
+
+// UploadChunkRequest appends bytes to an upload. The chunk is the raw
+// request body, sent as application/offset+octet-stream.
+type UploadChunkRequest struct {
+	RawRequest
+	ID       string `path:"id" doc:"Upload ID"`
+	Offset   int64  `header:"Upload-Offset" required:"true" doc:"Byte offset the chunk starts at; must equal the upload's current offset"`
+	Checksum string `header:"Upload-Checksum" doc:"Chunk checksum as '<algorithm> <base64 digest>'; algorithms: sha256, sha1, md5"`
+}
+
+// UploadStatus describes an upload's progress.
+type UploadStatus struct {
+	ID        string            `json:"id"`
+	Size      int64             `json:"size"`
+	Offset    int64             `json:"offset"`
+	Completed bool              `json:"completed"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// UploadStatusResponse carries an upload's status in the body and headers.
+type UploadStatusResponse struct {
+	Location     string `header:"Location" doc:"URL of the upload"`
+	Offset       int64  `header:"Upload-Offset" doc:"Bytes received so far"`
+	Length       int64  `header:"Upload-Length" doc:"Total upload size"`
+	CacheControl string `header:"Cache-Control"`
+	Body         UploadStatus
+}
+
+// UploadChunkResponse acknowledges an appended chunk.
+type UploadChunkResponse struct {
+	Offset int64 `header:"Upload-Offset" doc:"Offset after the chunk was applied"`
+}
+
+// ChunkedUpload registers a resumable, checksum-verified upload protocol
+// under prefix, modeled on tus:
+//
+//	POST  {prefix}                 create an upload of a declared size
+//	GET   {prefix}/{id}            report progress (HEAD is derived)
+//	PATCH {prefix}/{id}            append a chunk at Upload-Offset
+//	POST  {prefix}/{id}/complete   finish a fully written upload
+//
+// A chunk whose Upload-Offset is stale is rejected with 409 so clients can
+// re-sync via GET and resume. A chunk with an Upload-Checksum that does not
+// match is discarded with 400 and the offset is left unchanged. Location
+// headers use the path the request arrived on, so uploads registered on a
+// group or a mounted router point back through its prefix.
+func ChunkedUpload(reg Registrar, prefix string, cfg ChunkedUploadConfig) {
+	if cfg.Store == nil {
+		panic("api: ChunkedUpload requires a Store")
+	}
+	if cfg.MaxChunkSize <= 0 {
+		cfg.MaxChunkSize = defaultMaxChunkSize
+	}
+	if len(cfg.Tags) == 0 {
+		cfg.Tags = []string{"uploads"}
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	u := &chunkedUploads{cfg: cfg}
+
+	Post(reg, prefix, u.create,
+		WithStatus(http.StatusCreated),
+		WithSummary("Create a resumable upload"),
+		WithTags(cfg.Tags...),
+		WithError(WithErrors(CodeContentTooLarge)),
+	)
+	Get(reg, prefix+"/{id}", u.status,
+		WithSummary("Get upload progress"),
+		WithTags(cfg.Tags...),
+	)
+	Patch(reg, prefix+"/{id}", u.append,
+		WithStatus(http.StatusNoContent),
+		WithSummary("Append a chunk to an upload"),
+		WithTags(cfg.Tags...),
+		withRequestContent("application/offset+octet-stream", JSONSchema{Type: "string", Format: "binary"}),
+		WithError(WithErrors(CodeConflict, CodeContentTooLarge, CodeUnsupportedMediaType)),
+	)
+	Post(reg, prefix+"/{id}/complete", u.complete,
+		WithSummary("Complete an upload"),
+		WithTags(cfg.Tags...),
+		WithError(WithErrors(CodeConflict)),
+	)
+}
+
+type chunkedUploads struct {
+	cfg ChunkedUploadConfig
+}
+
+func (u *chunkedUploads) create(ctx context.Context, req *UploadCreateRequest) (*UploadStatusResponse, error) {
+	if req.Body.Size <= 0 {
+		return nil, Error(CodeBadRequest, WithMessage("size must be positive"))
+	}
+	if u.cfg.MaxSize > 0 && req.Body.Size > u.cfg.MaxSize {
+		return nil, Error(CodeContentTooLarge, WithMessagef("upload exceeds %d bytes", u.cfg.MaxSize))
+	}
+	id, err := newUploadID()
+	if err != nil {
+		return nil, err
+	}
+	info := UploadInfo{ID: id, Size: req.Body.Size, Metadata: req.Body.Metadata}
+	if err := u.cfg.Store.Create(ctx, info); err != nil {
+		return nil, err
+	}
+	return u.statusResponse(info, requestPath(req.Request)+"/"+id), nil
+}
+
+func (u *chunkedUploads) status(ctx context.Context, req *UploadIDRequest) (*UploadStatusResponse, error) {
+	info, err := u.info(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return u.statusResponse(info, requestPath(req.Request)), nil
+}
+
+func (u *chunkedUploads) append(ctx context.Context, req *UploadChunkRequest) (*UploadChunkResponse, error) {
+	if ct, _, err := mime.ParseMediaType(req.Request.Header.Get("Content-Type")); err != nil || ct != "application/offset+octet-stream" {
+		return nil, Error(CodeUnsupportedMediaType, WithMessage("chunks must be sent as application/offset+octet-stream"))
+	}
+
+	info, err := u.info(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if info.Completed {
+		return nil, Error(CodeConflict, WithMessage("upload is already complete"))
+	}
+	if req.Offset != info.Offset {
+		return nil, Error(CodeConflict, WithMessagef("offset %d does not match current offset %d", req.Offset, info.Offset))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(req.Request.Body, u.cfg.MaxChunkSize+1))
+	if err != nil {
+		return nil, Error(CodeBadRequest, WithMessage("reading chunk failed"))
+	}
+	if int64(len(data)) > u.cfg.MaxChunkSize {
+		return nil, Error(CodeContentTooLarge, WithMessagef("chunk exceeds %d bytes", u.cfg.MaxChunkSize))
+	}
+	if info.Offset+int64(len(data)) > info.Size {
+		return nil, Error(CodeContentTooLarge, WithMessage("chunk extends past the declared upload size"))
+	}
+	if req.Checksum != "" {
+		if err := verifyChecksum(req.Checksum, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.cfg.Store.WriteChunk(ctx, req.ID, req.Offset, data); err != nil {
+		if errors.Is(err, ErrUploadOffsetMismatch) {
+			return nil, Error(CodeConflict, WithMessage("upload offset changed; re-sync and retry"))
+		}
+		return nil, uploadErr(err)
+	}
+	return &UploadChunkResponse{Offset: req.Offset + int64(len(data))}, nil
+}
+
+func (u *chunkedUploads) complete(ctx context.Context, req *UploadIDRequest) (*UploadStatusResponse, error) {
+	info, err := u.info(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if info.Offset != info.Size {
+		return nil, Error(CodeConflict, WithMessagef("upload has %d of %d bytes", info.Offset, info.Size))
+	}
+	if !info.Completed {
+		if err := u.cfg.Store.Complete(ctx, req.ID); err != nil {
+			return nil, uploadErr(err)
+		}
+		info.Completed = true
+		if u.cfg.OnComplete != nil {
+			if err := u.cfg.OnComplete(ctx, info); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return u.statusResponse(info, strings.TrimSuffix(requestPath(req.Request), "/complete")), nil
+}
+
+// info loads an upload, mapping ErrUploadNotFound to a 404.
+func (u *chunkedUploads) info(ctx context.Context, id string) (UploadInfo, error) {
+	info, err := u.cfg.Store.Info(ctx, id)
+	if err != nil {
+		return UploadInfo{}, uploadErr(err)
+	}
+	return info, nil
+}
+
+// uploadErr maps ErrUploadNotFound from the store to a 404, for uploads
+// that disappear between requests or mid-request.
+func uploadErr(err error) error {
+	if errors.Is(err, ErrUploadNotFound) {
+		return Error(CodeNotFound, WithMessage("upload not found"), WithCause(err))
+	}
+	return err
+}
+
+// requestPath returns the path r was sent to, before any Mount stripped
+// its prefix, without a trailing slash.
+func requestPath(r *http.Request) string {
+	path := r.URL.EscapedPath()
+	if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		path = u.EscapedPath()
+	}
+	return strings.TrimSuffix(path, "/")
+}
+
+func (u *chunkedUploads) statusResponse(info UploadInfo, location string) *UploadStatusResponse {
+	return &UploadStatusResponse{
+		Location:     location,
+		Offset:       info.Offset,
+		Length:       info.Size,
+		CacheControl: "no-store",
+		Body: UploadStatus{
+			ID:        info.ID,
+			Size:      info.Size,
+			Offset:    info.Offset,
+			Completed: info.Completed,
+			Metadata:  info.Metadata,
+		},
+	}
+}
+
+// verifyChecksum checks data against an Upload-Checksum header value.
+func verifyChecksum(header string, data []byte) error {
+	algo, digest, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok {
+		return Error(CodeBadRequest, WithMessage("malformed Upload-Checksum header"))
+	}
+	var h hash.Hash
+	switch strings.ToLower(algo) {
+	case "sha256":
+		h = sha256.New()
+	case "sha1":
+		h = sha1.New() //nolint:gosec // client-selected integrity check
+	case "md5":
+		h = md5.New() //nolint:gosec // client-selected integrity check
+	default:
+		return Error(CodeBadRequest, WithMessagef("unsupported checksum algorithm %q", algo))
+	}
+	want, err := base64.StdEncoding.DecodeString(strings.TrimSpace(digest))
+	if err != nil {
+		return Error(CodeBadRequest, WithMessage("malformed Upload-Checksum digest"))
+	}
+	h.Write(data)
+	if !bytes.Equal(h.Sum(nil), want) {
+		return Error(CodeBadRequest, WithMessage("checksum mismatch; chunk discarded"))
+	}
+	return nil
+}
+
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// MemoryUploadStore is an in-memory UploadStore for tests and development.
+// Data is lost on restart.
+type MemoryUploadStore struct {
+	mu      sync.Mutex
+	uploads map[string]*memoryUpload
+}
+
+type memoryUpload struct {
+	info UploadInfo
+	data []byte
+}
+
+// NewMemoryUploadStore creates an empty in-memory upload store.
+func NewMemoryUploadStore() *MemoryUploadStore {
+	return &MemoryUploadStore{uploads: make(map[string]*memoryUpload)}
+}
+
+// Create implements UploadStore.
+func (s *MemoryUploadStore) Create(_ context.Context, info UploadInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploads[info.ID] = &memoryUpload{info: info}
+	return nil
+}
+
+// Info implements UploadStore.
+func (s *MemoryUploadStore) Info(_ context.Context, id string) (UploadInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	up, ok := s.uploads[id]
+	if !ok {
+		return UploadInfo{}, ErrUploadNotFound
+	}
+	return up.info, nil
+}
+
+// WriteChunk implements UploadStore.
+func (s *MemoryUploadStore) WriteChunk(_ context.Context, id string, offset int64, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	up, ok := s.uploads[id]
+	if !ok {
+		return ErrUploadNotFound
+	}
+	if up.info.Offset != offset {
+		return ErrUploadOffsetMismatch
+	}
+	up.data = append(up.data, data...)
+	up.info.Offset += int64(len(data))
+	return nil
+}
+
+// Complete implements UploadStore.
+func (s *MemoryUploadStore) Complete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	up, ok := s.uploads[id]
+	if !ok {
+		return ErrUploadNotFound
+	}
+	up.info.Completed = true
+	return nil
+}
+
+// Data returns a copy of the bytes received for an upload.
+func (s *MemoryUploadStore) Data(id string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	up, ok := s.uploads[id]
+	if !ok {
+		return nil, false
+	}
+	return bytes.Clone(up.data), true
+}
@@ -0,0 +1,282 @@
+package api_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func newChunkedUploadRouter(t *testing.T, cfg api.ChunkedUploadConfig) (*api.Router, *api.MemoryUploadStore) {
+	t.Helper()
+	store := api.NewMemoryUploadStore()
+	cfg.Store = store
+	r := api.New()
+	api.ChunkedUpload(r, "/uploads", cfg)
+	return r, store
+}
+
+func createUpload(t *testing.T, r http.Handler, size int) api.UploadStatus {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/uploads", strings.NewReader(`{"size":`+strconv.Itoa(size)+`,"metadata":{"name":"a.bin"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var st api.UploadStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+	assert.Equal(t, "/uploads/"+st.ID, w.Header().Get("Location"))
+	return st
+}
+
+func appendChunk(r http.Handler, id string, offset int, data, checksum string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/uploads/"+id, strings.NewReader(data))
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", strconv.Itoa(offset))
+	if checksum != "" {
+		req.Header.Set("Upload-Checksum", checksum)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func sha256Checksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return "sha256 " + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestChunkedUpload_flow(t *testing.T) {
+	t.Parallel()
+
+	var completed api.UploadInfo
+	r, store := newChunkedUploadRouter(t, api.ChunkedUploadConfig{
+		OnComplete: func(_ context.Context, info api.UploadInfo) error {
+			completed = info
+			return nil
+		},
+	})
+
+	st := createUpload(t, r, 11)
+	assert.Equal(t, int64(11), st.Size)
+	assert.Equal(t, int64(0), st.Offset)
+
+	w := appendChunk(r, st.ID, 0, "hello ", sha256Checksum("hello "))
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	assert.Equal(t, "6", w.Header().Get("Upload-Offset"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/uploads/"+st.ID, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "6", w.Header().Get("Upload-Offset"))
+	assert.Equal(t, "11", w.Header().Get("Upload-Length"))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	w = appendChunk(r, st.ID, 6, "world", "")
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/uploads/"+st.ID+"/complete", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"id":"`+st.ID+`","size":11,"offset":11,"completed":true,"metadata":{"name":"a.bin"}}`, w.Body.String())
+
+	assert.True(t, completed.Completed)
+	data, ok := store.Data(st.ID)
+	require.True(t, ok)
+	assert.Equal(t, "hello world", string(data))
+}
+
+func TestChunkedUpload_append_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		offset   int
+		data     string
+		checksum string
+		wantCode int
+	}{
+		"stale offset": {
+			offset:   3,
+			data:     "abc",
+			wantCode: http.StatusConflict,
+		},
+		"checksum mismatch": {
+			data:     "abc",
+			checksum: sha256Checksum("abd"),
+			wantCode: http.StatusBadRequest,
+		},
+		"unsupported algorithm": {
+			data:     "abc",
+			checksum: "crc32 AAAA",
+			wantCode: http.StatusBadRequest,
+		},
+		"past declared size": {
+			data:     "abcdefghijk",
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		"chunk too large": {
+			data:     "abcdefg",
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r, store := newChunkedUploadRouter(t, api.ChunkedUploadConfig{MaxChunkSize: 6})
+			st := createUpload(t, r, 10)
+
+			w := appendChunk(r, st.ID, tt.offset, tt.data, tt.checksum)
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+
+			info, err := store.Info(context.Background(), st.ID)
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), info.Offset)
+		})
+	}
+}
+
+func TestChunkedUpload_not_found_and_incomplete(t *testing.T) {
+	t.Parallel()
+
+	r, _ := newChunkedUploadRouter(t, api.ChunkedUploadConfig{})
+
+	w := appendChunk(r, "missing", 0, "x", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	st := createUpload(t, r, 4)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/uploads/"+st.ID+"/complete", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestChunkedUpload_location(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		router func() *api.Router
+		base   string
+	}{
+		"group": {
+			router: func() *api.Router {
+				r := api.New()
+				api.ChunkedUpload(r.Group("/v1"), "/uploads", api.ChunkedUploadConfig{Store: api.NewMemoryUploadStore()})
+				return r
+			},
+			base: "/v1/uploads",
+		},
+		"mount": {
+			router: func() *api.Router {
+				sub := api.New()
+				api.ChunkedUpload(sub, "/uploads", api.ChunkedUploadConfig{Store: api.NewMemoryUploadStore()})
+				r := api.New()
+				r.Mount("/files", sub)
+				return r
+			},
+			base: "/files/uploads",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := tc.router()
+			req := httptest.NewRequest(http.MethodPost, tc.base, strings.NewReader(`{"size":4}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+			var st api.UploadStatus
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+			assert.Equal(t, tc.base+"/"+st.ID, w.Header().Get("Location"))
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.base+"/"+st.ID, nil))
+			assert.Equal(t, tc.base+"/"+st.ID, w.Header().Get("Location"))
+
+			req = httptest.NewRequest(http.MethodPatch, tc.base+"/"+st.ID, strings.NewReader("abcd"))
+			req.Header.Set("Content-Type", "application/offset+octet-stream")
+			req.Header.Set("Upload-Offset", "0")
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tc.base+"/"+st.ID+"/complete", nil))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, tc.base+"/"+st.ID, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestChunkedUpload_content_type_parameters(t *testing.T) {
+	t.Parallel()
+
+	r, _ := newChunkedUploadRouter(t, api.ChunkedUploadConfig{})
+	st := createUpload(t, r, 3)
+
+	req := httptest.NewRequest(http.MethodPatch, "/uploads/"+st.ID, strings.NewReader("abc"))
+	req.Header.Set("Content-Type", "Application/Offset+Octet-Stream; charset=binary")
+	req.Header.Set("Upload-Offset", "0")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+}
+
+// vanishingUploadStore loses uploads between Info and WriteChunk, as a
+// store shared with a cleanup job can.
+type vanishingUploadStore struct {
+	*api.MemoryUploadStore
+}
+
+func (vanishingUploadStore) WriteChunk(context.Context, string, int64, []byte) error {
+	return api.ErrUploadNotFound
+}
+
+func TestChunkedUpload_write_not_found(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.ChunkedUpload(r, "/uploads", api.ChunkedUploadConfig{Store: vanishingUploadStore{api.NewMemoryUploadStore()}})
+	st := createUpload(t, r, 3)
+
+	w := appendChunk(r, st.ID, 0, "abc", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func TestChunkedUpload_spec(t *testing.T) {
+	t.Parallel()
+
+	r, _ := newChunkedUploadRouter(t, api.ChunkedUploadConfig{})
+	spec := r.Spec()
+
+	patch := spec.Paths["/uploads/{id}"]["patch"]
+	require.NotNil(t, patch.RequestBody)
+	media, ok := patch.RequestBody.Content["application/offset+octet-stream"]
+	require.True(t, ok)
+	assert.Equal(t, "binary", media.Schema.Format)
+	assert.Contains(t, patch.Responses, "409")
+
+	var names []string
+	for _, p := range patch.Parameters {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"id", "Upload-Offset", "Upload-Checksum"}, names)
+
+	assert.Contains(t, spec.Paths["/uploads"], "post")
+	assert.Contains(t, spec.Paths["/uploads/{id}/complete"], "post")
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ComponentOption configures a component registered with RegisterComponent.
+type ComponentOption func(*component)
+
+// WithComponentDescription sets the description of the component schema.
+func WithComponentDescription(desc string) ComponentOption {
+	return func(c *component) { c.description = desc }
+}
+
+type component struct {
+	name        string
+	description string
+}
+
+// components holds the types declared via RegisterComponent.
+var components struct {
+	mu     sync.RWMutex
+	byType map[reflect.Type]component
+	byName map[string]reflect.Type
+}
+
+// RegisterComponent documents T as the schema component name, in place of
+// its Go type name. A shared package registers its types at init so every
+// service that imports it publishes the same component:
+//
+//	package money
+//
+//	type Amount struct {
+//	    Units    int64  `json:"units"`
+//	    Currency string `json:"currency"`
+//	}
+//
+//	func init() {
+//	    api.RegisterComponent[Amount]("Money",
+//	        api.WithComponentDescription("An amount in minor units of a currency."))
+//	}
+//
+// T may be any type, not just a struct: a registered `type Currency string`
+// becomes a $ref to a string component. Components appear in a spec only
+// when an operation uses them. Registering a name already taken by another
+// type panics, and so does building a spec in which the name is also the
+// Go name of another struct the spec documents.
+func RegisterComponent[T any](name string, opts ...ComponentOption) {
+	if name == "" {
+		panic("api: RegisterComponent requires a name")
+	}
+	c := component{name: name}
+	for _, o := range opts {
+		o(&c)
+	}
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	components.mu.Lock()
+	defer components.mu.Unlock()
+	if prev, ok := components.byName[name]; ok && prev != t {
+		panic(fmt.Sprintf("api: RegisterComponent: %q is already registered for %s", name, prev))
+	}
+	if components.byType == nil {
+		components.byType = make(map[reflect.Type]component)
+		components.byName = make(map[string]reflect.Type)
+	}
+	if prev, ok := components.byType[t]; ok {
+		delete(components.byName, prev.name)
+	}
+	components.byType[t] = c
+	components.byName[name] = t
+}
+
+// componentFor returns the registration for t, if any.
+func componentFor(t reflect.Type) (component, bool) {
+	components.mu.RLock()
+	defer components.mu.RUnlock()
+	c, ok := components.byType[t]
+	return c, ok
+}
+
+// componentByName returns the type registered under name, if any.
+func componentByName(name string) (reflect.Type, bool) {
+	components.mu.RLock()
+	defer components.mu.RUnlock()
+	t, ok := components.byName[name]
+	return t, ok
+}
+
+// componentName returns the schema name t is documented under: its
+// registered component name, else its Go name. Empty for unnamed types.
+func componentName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if c, ok := componentFor(t); ok {
+		return c.name
+	}
+	return t.Name()
+}
+
+// componentNamer is implemented by generic response types that name their
+// schemas after a type argument, since Go's instantiated type names are
+// not valid component names. The names are kept with the route and apply
+// to the specs of the router documenting it.
+type componentNamer interface {
+	nameComponents(ri *routeInfo)
+}
+
+// nameComponent documents t under name in the specs that include ri,
+// unless t is registered with RegisterComponent.
+func (ri *routeInfo) nameComponent(t reflect.Type, name string) {
+	if ri.componentNames == nil {
+		ri.componentNames = make(map[reflect.Type]string)
+	}
+	ri.componentNames[t] = name
+}
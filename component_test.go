@@ -0,0 +1,142 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type cmpAmount struct {
+	Units    int64       `json:"units"`
+	Currency cmpCurrency `json:"currency"`
+}
+
+type cmpCurrency string
+
+type cmpInvoice struct {
+	Total cmpAmount  `json:"total"`
+	Tax   *cmpAmount `json:"tax,omitempty"`
+}
+
+func init() {
+	api.RegisterComponent[cmpAmount]("Money",
+		api.WithComponentDescription("An amount in minor units of a currency."))
+	api.RegisterComponent[cmpCurrency]("CurrencyCode")
+	api.RegisterComponent[cmpLabel]("cmpNote")
+}
+
+// cmpLabel is registered under the Go name of cmpNote.
+type cmpLabel struct {
+	Text string `json:"text"`
+}
+
+type cmpNote struct {
+	Body string `json:"body"`
+}
+
+func TestRegisterComponent(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/invoice", func(_ context.Context, _ *api.Void) (*api.Resp[cmpInvoice], error) {
+		return &api.Resp[cmpInvoice]{}, nil
+	})
+
+	spec := r.Spec()
+	schemas := spec.Components.Schemas
+
+	inv := schemas["cmpInvoice"]
+	assert.Equal(t, "#/components/schemas/Money", inv.Properties["total"].Ref)
+	assert.Equal(t, "#/components/schemas/Money", inv.Properties["tax"].Ref)
+
+	money, ok := schemas["Money"]
+	require.True(t, ok)
+	assert.Equal(t, "An amount in minor units of a currency.", money.Description)
+	assert.Equal(t, "#/components/schemas/CurrencyCode", money.Properties["currency"].Ref)
+	assert.NotContains(t, schemas, "cmpAmount")
+
+	assert.Equal(t, "string", schemas["CurrencyCode"].Type)
+}
+
+func TestRegisterComponent_invalid(t *testing.T) {
+	t.Parallel()
+
+	assert.PanicsWithValue(t, "api: RegisterComponent requires a name", func() {
+		api.RegisterComponent[cmpInvoice]("")
+	})
+	assert.Panics(t, func() {
+		api.RegisterComponent[cmpInvoice]("Money")
+	})
+}
+
+func TestSchemaRef(t *testing.T) {
+	t.Parallel()
+
+	type cmpEnvelope struct {
+		Kind    string          `json:"kind"`
+		Payload json.RawMessage `json:"payload" schemaRef:"Money" doc:"The amount"`
+		Extra   any             `json:"extra" schemaRef:"#/components/schemas/External"`
+		Raw     json.RawMessage `json:"raw"`
+	}
+
+	r := api.New()
+	api.Post(r, "/envelopes", func(_ context.Context, _ *cmpEnvelope) (*api.Void, error) { return nil, nil })
+
+	schemas := r.Spec().Components.Schemas
+	env := schemas["cmpEnvelope"]
+	assert.Equal(t, api.JSONSchema{Ref: "#/components/schemas/Money", Description: "The amount"}, env.Properties["payload"])
+	assert.Equal(t, api.JSONSchema{Ref: "#/components/schemas/External"}, env.Properties["extra"])
+	assert.Equal(t, api.JSONSchema{}, env.Properties["raw"])
+	// The registered component is brought into the spec by the tag alone.
+	assert.Equal(t, "object", schemas["Money"].Type)
+}
+
+func TestRegisterComponent_name_of_another_schema(t *testing.T) {
+	t.Parallel()
+
+	type labelFirst struct {
+		Label cmpLabel `json:"label"`
+		Note  cmpNote  `json:"note"`
+	}
+	type noteFirst struct {
+		Note  cmpNote  `json:"note"`
+		Label cmpLabel `json:"label"`
+	}
+
+	tests := map[string]struct {
+		register func(r *api.Router)
+		want     string
+	}{
+		"registered first": {
+			register: func(r *api.Router) {
+				api.Get(r, "/x", func(_ context.Context, _ *api.Void) (*api.Resp[labelFirst], error) {
+					return nil, nil //nolint:nilnil // never called
+				})
+			},
+			want: `api: component "cmpNote" names both api_test.cmpLabel and api_test.cmpNote`,
+		},
+		"registered last": {
+			register: func(r *api.Router) {
+				api.Get(r, "/x", func(_ context.Context, _ *api.Void) (*api.Resp[noteFirst], error) {
+					return nil, nil //nolint:nilnil // never called
+				})
+			},
+			want: `api: component "cmpNote" names both api_test.cmpNote and api_test.cmpLabel`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New()
+			tt.register(r)
+			assert.PanicsWithValue(t, tt.want, func() { r.Spec() })
+		})
+	}
+}
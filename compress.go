@@ -2,6 +2,7 @@ package api
 
 import (
 	"compress/gzip"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -34,53 +35,60 @@ func Compress(cfg ...CompressConfig) Middleware {
 		}
 	}
 
-	pool := &sync.Pool{
-		New: func() any {
-			gz, _ := gzip.NewWriterLevel(io.Discard, c.Level) //nolint:errcheck // level is pre-validated
-			return gz
-		},
+	if c.Level > gzip.BestCompression {
+		panic(fmt.Sprintf("api: Compress: invalid gzip level %d", c.Level))
 	}
 
-	return func(next http.Handler) http.Handler {
+	return Named("compress", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			gz := pool.Get().(*gzip.Writer) //nolint:errcheck,forcetypeassert // pool.New always returns *gzip.Writer
-			gz.Reset(w)
-			defer func() {
-				//nolint:errcheck,gosec // best-effort flush
-				gz.Close()
-				pool.Put(gz)
-			}()
-
-			gw := &gzipResponseWriter{
+			gw := gzipResponseWriters.Get().(*gzipResponseWriter) //nolint:errcheck,forcetypeassert // pool.New always returns *gzipResponseWriter
+			*gw = gzipResponseWriter{
 				ResponseWriter: w,
-				writer:         gz,
+				level:          c.Level,
 				minSize:        c.MinSize,
 				types:          c.Types,
 			}
+			defer gw.release()
 
 			w.Header().Set("Vary", "Accept-Encoding")
 			next.ServeHTTP(gw, r)
-
-			// If gzip was activated, close and set headers.
-			if gw.gzipActive {
-				w.Header().Set("Content-Encoding", "gzip")
-				w.Header().Del("Content-Length")
-			}
 		})
+	})
+}
+
+// gzipWriters pools gzip writers by compression level, indexed by level
+// minus gzip.HuffmanOnly, so every Compress middleware with the same level
+// shares one pool.
+var gzipWriters [gzip.BestCompression - gzip.HuffmanOnly + 1]sync.Pool
+
+var gzipResponseWriters = sync.Pool{New: func() any { return new(gzipResponseWriter) }}
+
+// getGzipWriter returns a pooled gzip writer for level that writes to w.
+func getGzipWriter(level int, w io.Writer) *gzip.Writer {
+	if gz, ok := gzipWriters[level-gzip.HuffmanOnly].Get().(*gzip.Writer); ok {
+		gz.Reset(w)
+		return gz
 	}
+	gz, _ := gzip.NewWriterLevel(w, level) //nolint:errcheck // level is validated by Compress
+	return gz
+}
+
+// putGzipWriter returns gz, created for level, to its pool.
+func putGzipWriter(level int, gz *gzip.Writer) {
+	gzipWriters[level-gzip.HuffmanOnly].Put(gz)
 }
 
 type gzipResponseWriter struct {
 	http.ResponseWriter
-	writer     *gzip.Writer
+	writer     *gzip.Writer // set once compression starts
+	level      int
 	minSize    int
 	types      []string
-	gzipActive bool
 	headerSent bool
 }
 
@@ -89,18 +97,30 @@ func (g *gzipResponseWriter) Write(b []byte) (int, error) {
 		g.headerSent = true
 		ct := g.Header().Get("Content-Type")
 		if g.shouldCompress(ct) && len(b) >= g.minSize {
-			g.gzipActive = true
+			g.writer = getGzipWriter(g.level, g.ResponseWriter)
 			g.Header().Set("Content-Encoding", "gzip")
 			g.Header().Del("Content-Length")
 		}
 	}
 
-	if g.gzipActive {
+	if g.writer != nil {
 		return g.writer.Write(b)
 	}
 	return g.ResponseWriter.Write(b)
 }
 
+// release flushes the gzip stream, if compression started, and returns the
+// writers to their pools.
+func (g *gzipResponseWriter) release() {
+	if g.writer != nil {
+		//nolint:errcheck,gosec // best-effort flush
+		g.writer.Close()
+		putGzipWriter(g.level, g.writer)
+	}
+	*g = gzipResponseWriter{}
+	gzipResponseWriters.Put(g)
+}
+
 func (g *gzipResponseWriter) shouldCompress(contentType string) bool {
 	// Skip SSE and already-compressed responses.
 	if strings.Contains(contentType, "event-stream") {
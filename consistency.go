@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// ConsistencyConfig configures Consistency.
+type ConsistencyConfig struct {
+	// Header carries the token in both directions. Default:
+	// "X-Consistency-Token".
+	Header string
+}
+
+type consistencyKey struct{}
+
+// consistencyState holds the token a request carried and the one its
+// handler issued.
+type consistencyState struct {
+	read   string
+	mu     sync.Mutex
+	issued string
+}
+
+// Consistency returns middleware for read-your-writes consistency over
+// replicated storage. A handler that writes issues a token identifying the
+// write, such as a log sequence number or row version, with
+// SetConsistencyToken; it is returned in the X-Consistency-Token header.
+// The client sends it back on later reads, and the read handler gets it
+// from ConsistencyToken to wait for a replica that has caught up, or to
+// read from the primary:
+//
+//	api.Get(r, "/orders/{id}", func(ctx context.Context, req *GetOrderReq) (*api.Resp[Order], error) {
+//	    db := replica
+//	    if token, ok := api.ConsistencyToken(ctx); ok && !replica.CaughtUp(token) {
+//	        db = primary
+//	    }
+//	    ...
+//	})
+//
+// Tokens are opaque to the middleware; sign or encrypt them when clients
+// must not forge them.
+func Consistency(cfg ...ConsistencyConfig) Middleware {
+	var c ConsistencyConfig
+	if len(cfg) > 0 {
+		c = cfg[0]
+	}
+	if c.Header == "" {
+		c.Header = "X-Consistency-Token"
+	}
+
+	return Named("consistency", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state := &consistencyState{read: r.Header.Get(c.Header)}
+			r = r.WithContext(context.WithValue(r.Context(), consistencyKey{}, state))
+			next.ServeHTTP(&consistencyWriter{ResponseWriter: w, state: state, header: c.Header}, r)
+		})
+	})
+}
+
+// ConsistencyToken returns the consistency token the client sent, as read
+// by Consistency. ok is false when it sent none.
+func ConsistencyToken(ctx context.Context) (string, bool) {
+	state, ok := ctx.Value(consistencyKey{}).(*consistencyState)
+	if !ok || state.read == "" {
+		return "", false
+	}
+	return state.read, true
+}
+
+// SetConsistencyToken issues token with the current response, for the
+// client to send on its next read. A later call replaces an earlier one.
+// Calling SetConsistencyToken outside Consistency is a no-op.
+func SetConsistencyToken(ctx context.Context, token string) {
+	state, ok := ctx.Value(consistencyKey{}).(*consistencyState)
+	if !ok {
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.issued = token
+}
+
+// consistencyWriter adds the issued token to the response headers when
+// they are written.
+type consistencyWriter struct {
+	http.ResponseWriter
+	state       *consistencyState
+	header      string
+	wroteHeader bool
+}
+
+func (w *consistencyWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.state.mu.Lock()
+		if w.state.issued != "" {
+			w.Header().Set(w.header, w.state.issued)
+		}
+		w.state.mu.Unlock()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *consistencyWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter (supports http.ResponseController).
+func (w *consistencyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
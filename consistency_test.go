@@ -0,0 +1,95 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func TestConsistency(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	r.Use(api.Consistency())
+	api.Post(r, "/orders", func(ctx context.Context, _ *api.Void) (*api.Resp[string], error) {
+		api.SetConsistencyToken(ctx, "lsn-1")
+		api.SetConsistencyToken(ctx, "lsn-2")
+		return &api.Resp[string]{Body: "created"}, nil
+	})
+	api.Post(r, "/fail", func(ctx context.Context, _ *api.Void) (*api.Void, error) {
+		api.SetConsistencyToken(ctx, "lsn-3")
+		return nil, errors.New("write failed after commit")
+	})
+	api.Get(r, "/orders", func(ctx context.Context, _ *api.Void) (*api.Resp[string], error) {
+		token, ok := api.ConsistencyToken(ctx)
+		if !ok {
+			return &api.Resp[string]{Body: "replica"}, nil
+		}
+		return &api.Resp[string]{Body: "at " + token}, nil
+	})
+
+	tests := map[string]struct {
+		method    string
+		path      string
+		token     string
+		wantBody  string
+		wantToken string
+	}{
+		"write issues token": {
+			method:    http.MethodPost,
+			path:      "/orders",
+			wantBody:  `"created"`,
+			wantToken: "lsn-2",
+		},
+		"error response keeps token": {
+			method:    http.MethodPost,
+			path:      "/fail",
+			wantToken: "lsn-3",
+		},
+		"read with token": {
+			method:   http.MethodGet,
+			path:     "/orders",
+			token:    "lsn-2",
+			wantBody: `"at lsn-2"`,
+		},
+		"read without token": {
+			method:   http.MethodGet,
+			path:     "/orders",
+			wantBody: `"replica"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tc.method, tc.path, http.NoBody)
+			if tc.token != "" {
+				req.Header.Set("X-Consistency-Token", tc.token)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if tc.wantBody != "" {
+				require.Less(t, w.Code, 300, w.Body.String())
+				assert.JSONEq(t, tc.wantBody, w.Body.String())
+			}
+			assert.Equal(t, tc.wantToken, w.Header().Get("X-Consistency-Token"))
+		})
+	}
+}
+
+func TestSetConsistencyToken_without_middleware(t *testing.T) {
+	t.Parallel()
+
+	assert.NotPanics(t, func() { api.SetConsistencyToken(context.Background(), "lsn-1") })
+	_, ok := api.ConsistencyToken(context.Background())
+	assert.False(t, ok)
+}
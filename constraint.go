@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // validateConstraints checks all constraint tags on the struct fields and
@@ -31,153 +35,341 @@ func validateConstraints(v any) error {
 	return nil
 }
 
-func collectConstraintErrors(rv reflect.Value, prefix string, errs *[]ValidationError) {
-	t := rv.Type()
+// constraintPlans caches the constraint plan of each struct type, so tags
+// are parsed and patterns compiled once per type rather than per request.
+var constraintPlans sync.Map // reflect.Type → []constraintField
+
+// constraintField is the plan for one field of a struct: how to reach it,
+// the path segment it reports violations under, and its parsed checks.
+type constraintField struct {
+	index  int
+	name   string
+	kind   constraintFieldKind
+	checks []constraintCheck
+
+	// recurse validates the fields of a nested struct value.
+	recurse bool
+}
+
+type constraintFieldKind int
+
+const (
+	constraintScalar     constraintFieldKind = iota
+	constraintBodyStruct                     // struct Body, reported under "body"
+	constraintBodyItems                      // array or map Body, checked element by element
+	constraintCookie                         // CookieParam, checked on Value when present
+)
+
+// constraintCheck reports the violation of one constraint tag by v, if
+// any.
+type constraintCheck func(v reflect.Value, path string) (ValidationError, bool)
 
+// constraintPlanFor returns the cached constraint plan of struct type t.
+func constraintPlanFor(t reflect.Type) []constraintField {
+	if plan, ok := constraintPlans.Load(t); ok {
+		return plan.([]constraintField) //nolint:errcheck,forcetypeassert // only plans are stored
+	}
+	plan, _ := constraintPlans.LoadOrStore(t, buildConstraintPlan(t))
+	return plan.([]constraintField) //nolint:errcheck,forcetypeassert // only plans are stored
+}
+
+func buildConstraintPlan(t reflect.Type) []constraintField {
+	var plan []constraintField
 	for i := range t.NumField() {
 		f := t.Field(i)
 		if !f.IsExported() {
 			continue
 		}
 
-		fv := rv.Field(i)
-
 		// Determine field path.
 		name := jsonFieldName(f)
 		if name == "-" {
 			continue
 		}
 
-		path := name
-		if prefix != "" {
-			path = prefix + "." + name
+		// Structured multipart parts report violations under the part name.
+		if isFormPartField(f) {
+			name = f.Tag.Get("form")
 		}
 
-		// If this is the Body field, recurse into it.
-		if f.Name == "Body" && f.Type.Kind() == reflect.Struct {
-			collectConstraintErrors(fv, "body", errs)
-			continue
+		cf := constraintField{index: i, name: name}
+
+		// If this is the Body field, recurse into it. Array and map bodies
+		// (batch endpoints) check their item count, then each element.
+		if f.Name == "Body" {
+			//exhaustive:ignore
+			switch f.Type.Kind() {
+			case reflect.Struct:
+				cf.kind = constraintBodyStruct
+				plan = append(plan, cf)
+				continue
+			case reflect.Slice, reflect.Array, reflect.Map:
+				cf.kind = constraintBodyItems
+				cf.checks = fieldConstraintChecks(f, f.Type)
+				plan = append(plan, cf)
+				continue
+			}
 		}
 
-		// Skip RawRequest.
-		if f.Type == reflect.TypeFor[RawRequest]() {
+		// Skip RawRequest, and FileUpload — it's a multipart file, not a
+		// scalar.
+		if f.Type == reflect.TypeFor[RawRequest]() || f.Type == reflect.TypeFor[FileUpload]() {
 			continue
 		}
 
-		// Skip FileUpload — it's a multipart file, not a scalar.
-		if f.Type == reflect.TypeFor[FileUpload]() {
+		// CookieParam validates its Value, and only when the cookie was sent.
+		if b, ok := asCookieBinder(f.Type); ok {
+			cf.kind = constraintCookie
+			cf.checks = fieldConstraintChecks(f, b.cookieValueType())
+			plan = append(plan, cf)
 			continue
 		}
 
-		checkFieldConstraints(f, fv, path, errs)
+		cf.checks = fieldConstraintChecks(f, f.Type)
+
+		// Recurse into nested structs, including structured multipart parts
+		// and deep object query params.
+		cf.recurse = f.Type.Kind() == reflect.Struct && (!isParamField(f) || isFormPartField(f) || isDeepObjectParam(f))
+
+		if len(cf.checks) > 0 || cf.recurse {
+			plan = append(plan, cf)
+		}
+	}
+	return plan
+}
+
+func collectConstraintErrors(rv reflect.Value, prefix string, errs *[]ValidationError) {
+	for _, cf := range constraintPlanFor(rv.Type()) {
+		fv := rv.Field(cf.index)
+
+		path := cf.name
+		if prefix != "" {
+			path = prefix + "." + cf.name
+		}
 
-		// Recurse into nested structs.
-		if fv.Kind() == reflect.Struct && f.Type != reflect.TypeFor[RawRequest]() && !isParamField(f) {
+		//exhaustive:ignore
+		switch cf.kind {
+		case constraintBodyStruct:
+			collectConstraintErrors(fv, "body", errs)
+			continue
+		case constraintBodyItems:
+			runConstraintChecks(cf.checks, fv, "body", errs)
+			collectElementConstraintErrors(fv, "body", errs)
+			continue
+		case constraintCookie:
+			if fv.FieldByName("Present").Bool() {
+				runConstraintChecks(cf.checks, fv.FieldByName("Value"), path, errs)
+			}
+			continue
+		}
+
+		runConstraintChecks(cf.checks, fv, path, errs)
+		if cf.recurse {
 			collectConstraintErrors(fv, path, errs)
 		}
 	}
 }
 
-func checkFieldConstraints(f reflect.StructField, fv reflect.Value, path string, errs *[]ValidationError) {
-	// minLength / maxLength — strings.
-	if fv.Kind() == reflect.String {
-		val := fv.String()
+func runConstraintChecks(checks []constraintCheck, fv reflect.Value, path string, errs *[]ValidationError) {
+	for _, check := range checks {
+		if ve, ok := check(fv, path); ok {
+			*errs = append(*errs, ve)
+		}
+	}
+}
+
+// collectElementConstraintErrors validates the struct elements of an array
+// or map body, reporting violations as body[i].field or body.key.field.
+func collectElementConstraintErrors(rv reflect.Value, prefix string, errs *[]ValidationError) {
+	elem := rv.Type().Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return
+	}
+
+	check := func(v reflect.Value, path string) {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return
+			}
+			v = v.Elem()
+		}
+		collectConstraintErrors(v, path, errs)
+	}
+
+	if rv.Kind() == reflect.Map {
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			check(rv.MapIndex(k), fmt.Sprintf("%s.%v", prefix, k))
+		}
+		return
+	}
+	for i := range rv.Len() {
+		check(rv.Index(i), fmt.Sprintf("%s[%d]", prefix, i))
+	}
+}
+
+// fieldConstraintChecks parses the constraint tags of f that apply to
+// values of type t. Malformed tags are ignored, as in the spec.
+func fieldConstraintChecks(f reflect.StructField, t reflect.Type) []constraintCheck {
+	var checks []constraintCheck
+	add := func(check constraintCheck) { checks = append(checks, check) }
+
+	// minLength / maxLength / pattern / enum — strings.
+	if t.Kind() == reflect.String {
 		if tag := f.Tag.Get("minLength"); tag != "" {
-			if n, err := strconv.Atoi(tag); err == nil && len(val) < n {
-				*errs = append(*errs, ValidationError{
-					Field:   path,
-					Message: fmt.Sprintf("must be at least %d characters", n),
-					Value:   val,
+			if n, err := strconv.Atoi(tag); err == nil {
+				add(func(v reflect.Value, path string) (ValidationError, bool) {
+					if val := v.String(); len(val) < n {
+						return ValidationError{
+							Field:   path,
+							Message: fmt.Sprintf("must be at least %d characters", n),
+							Value:   val,
+						}, true
+					}
+					return ValidationError{}, false
 				})
 			}
 		}
 		if tag := f.Tag.Get("maxLength"); tag != "" {
-			if n, err := strconv.Atoi(tag); err == nil && len(val) > n {
-				*errs = append(*errs, ValidationError{
-					Field:   path,
-					Message: fmt.Sprintf("must be at most %d characters", n),
-					Value:   val,
+			if n, err := strconv.Atoi(tag); err == nil {
+				add(func(v reflect.Value, path string) (ValidationError, bool) {
+					if val := v.String(); len(val) > n {
+						return ValidationError{
+							Field:   path,
+							Message: fmt.Sprintf("must be at most %d characters", n),
+							Value:   val,
+						}, true
+					}
+					return ValidationError{}, false
 				})
 			}
 		}
 		if tag := f.Tag.Get("pattern"); tag != "" {
-			if matched, err := regexp.MatchString(tag, val); err == nil && !matched {
-				*errs = append(*errs, ValidationError{
-					Field:   path,
-					Message: fmt.Sprintf("must match pattern %s", tag),
-					Value:   val,
+			if re, err := regexp.Compile(tag); err == nil {
+				add(func(v reflect.Value, path string) (ValidationError, bool) {
+					if val := v.String(); !re.MatchString(val) {
+						return ValidationError{
+							Field:   path,
+							Message: fmt.Sprintf("must match pattern %s", tag),
+							Value:   val,
+						}, true
+					}
+					return ValidationError{}, false
 				})
 			}
 		}
 	}
 
+	// enum — time zones, by name.
+	if t == reflect.PointerTo(locationType) {
+		if tag := f.Tag.Get("enum"); tag != "" {
+			allowed := strings.Split(tag, ",")
+			add(func(v reflect.Value, path string) (ValidationError, bool) {
+				if v.IsNil() {
+					return ValidationError{}, false
+				}
+				if val := v.Interface().(*time.Location).String(); !slices.Contains(allowed, val) { //nolint:errcheck,forcetypeassert // type checked above
+					return ValidationError{
+						Field:   path,
+						Message: fmt.Sprintf("must be one of [%s]", tag),
+						Value:   val,
+					}, true
+				}
+				return ValidationError{}, false
+			})
+		}
+	}
+
 	// minimum / maximum — numeric types.
-	if isNumericKind(fv.Kind()) {
-		floatVal := toFloat64(fv)
+	if isNumericKind(t.Kind()) {
 		if tag := f.Tag.Get("minimum"); tag != "" {
-			if lower, err := strconv.ParseFloat(tag, 64); err == nil && floatVal < lower {
-				*errs = append(*errs, ValidationError{
-					Field:   path,
-					Message: fmt.Sprintf("must be at least %s", tag),
-					Value:   floatVal,
+			if lower, err := strconv.ParseFloat(tag, 64); err == nil {
+				add(func(v reflect.Value, path string) (ValidationError, bool) {
+					if floatVal := toFloat64(v); floatVal < lower {
+						return ValidationError{
+							Field:   path,
+							Message: fmt.Sprintf("must be at least %s", tag),
+							Value:   floatVal,
+						}, true
+					}
+					return ValidationError{}, false
 				})
 			}
 		}
 		if tag := f.Tag.Get("maximum"); tag != "" {
-			if upper, err := strconv.ParseFloat(tag, 64); err == nil && floatVal > upper {
-				*errs = append(*errs, ValidationError{
-					Field:   path,
-					Message: fmt.Sprintf("must be at most %s", tag),
-					Value:   floatVal,
+			if upper, err := strconv.ParseFloat(tag, 64); err == nil {
+				add(func(v reflect.Value, path string) (ValidationError, bool) {
+					if floatVal := toFloat64(v); floatVal > upper {
+						return ValidationError{
+							Field:   path,
+							Message: fmt.Sprintf("must be at most %s", tag),
+							Value:   floatVal,
+						}, true
+					}
+					return ValidationError{}, false
 				})
 			}
 		}
 	}
 
 	// enum — strings.
-	if fv.Kind() == reflect.String {
+	if t.Kind() == reflect.String {
 		if tag := f.Tag.Get("enum"); tag != "" {
-			val := fv.String()
 			allowed := strings.Split(tag, ",")
-			found := false
-			for _, a := range allowed {
-				if a == val {
-					found = true
-					break
+			add(func(v reflect.Value, path string) (ValidationError, bool) {
+				if val := v.String(); !slices.Contains(allowed, val) {
+					return ValidationError{
+						Field:   path,
+						Message: fmt.Sprintf("must be one of [%s]", tag),
+						Value:   val,
+					}, true
 				}
-			}
-			if !found {
-				*errs = append(*errs, ValidationError{
-					Field:   path,
-					Message: fmt.Sprintf("must be one of [%s]", tag),
-					Value:   val,
-				})
-			}
+				return ValidationError{}, false
+			})
 		}
 	}
 
-	// minItems / maxItems — slices.
-	if fv.Kind() == reflect.Slice {
-		length := fv.Len()
+	// minItems / maxItems — slices, and map entries.
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		unit := "items"
+		if t.Kind() == reflect.Map {
+			unit = "entries"
+		}
 		if tag := f.Tag.Get("minItems"); tag != "" {
-			if n, err := strconv.Atoi(tag); err == nil && length < n {
-				*errs = append(*errs, ValidationError{
-					Field:   path,
-					Message: fmt.Sprintf("must have at least %d items", n),
-					Value:   length,
+			if n, err := strconv.Atoi(tag); err == nil {
+				add(func(v reflect.Value, path string) (ValidationError, bool) {
+					if length := v.Len(); length < n {
+						return ValidationError{
+							Field:   path,
+							Message: fmt.Sprintf("must have at least %d %s", n, unit),
+							Value:   length,
+						}, true
+					}
+					return ValidationError{}, false
 				})
 			}
 		}
 		if tag := f.Tag.Get("maxItems"); tag != "" {
-			if n, err := strconv.Atoi(tag); err == nil && length > n {
-				*errs = append(*errs, ValidationError{
-					Field:   path,
-					Message: fmt.Sprintf("must have at most %d items", n),
-					Value:   length,
+			if n, err := strconv.Atoi(tag); err == nil {
+				add(func(v reflect.Value, path string) (ValidationError, bool) {
+					if length := v.Len(); length > n {
+						return ValidationError{
+							Field:   path,
+							Message: fmt.Sprintf("must have at most %d %s", n, unit),
+							Value:   length,
+						}, true
+					}
+					return ValidationError{}, false
 				})
 			}
 		}
 	}
+
+	return checks
 }
 
 func isNumericKind(k reflect.Kind) bool {
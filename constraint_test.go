@@ -598,3 +598,17 @@ func TestValidateConstraints_unexported_field_skipped(t *testing.T) {
 	err := api.ValidateConstraints(input)
 	require.NoError(t, err)
 }
+
+func TestValidateConstraints_valid_does_not_allocate(t *testing.T) {
+	type Req struct {
+		Name  string `query:"name" minLength:"1" pattern:"^[a-z]+$"`
+		Limit int    `query:"limit" minimum:"1" maximum:"100"`
+	}
+	req := &Req{Name: "abc", Limit: 10}
+	require.NoError(t, api.ValidateConstraints(req))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = api.ValidateConstraints(req) //nolint:errcheck // checked above
+	})
+	assert.Zero(t, allocs)
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// contentEncodings lists the encodings WithContentEncoding supports.
+var contentEncodings = []string{"gzip", "deflate"}
+
+// deflateWriters pools the deflate writers of WithContentEncoding. Its gzip
+// writers come from the pool Compress uses.
+var deflateWriters = sync.Pool{New: func() any {
+	fw, _ := flate.NewWriter(io.Discard, flate.DefaultCompression) //nolint:errcheck // level is valid
+	return fw
+}}
+
+// WithContentEncoding compresses this route's codec-encoded responses at
+// encode time with the given encoding ("gzip" or "deflate") whenever the
+// client's Accept-Encoding allows it, independent of the Compress
+// middleware (which leaves already-encoded responses alone). Use it for
+// large, cacheable payloads where the compressed length should be known
+// up front:
+//
+//	api.Get(r, "/catalog", h.Catalog, api.WithContentEncoding("gzip"))
+//
+// The response carries Content-Length and Vary: Accept-Encoding. Clients
+// that do not accept the encoding receive the identity body. The route
+// keeps the last compressed body per content type: when the handler serves
+// the same bytes again, typically from its own cache, they are not
+// compressed again. Stream, reader, and byte-slice bodies are not
+// affected. It panics on an unsupported encoding.
+func WithContentEncoding(encoding string) RouteOption {
+	encoding = strings.ToLower(encoding)
+	if !slices.Contains(contentEncodings, encoding) {
+		panic(fmt.Sprintf("api: WithContentEncoding: unsupported encoding %q", encoding))
+	}
+	return RouteOptionFunc(func(ri *routeInfo) {
+		ri.contentEncoding = encoding
+	})
+}
+
+// contentEncoder compresses the codec bodies of one route; see
+// WithContentEncoding.
+type contentEncoder struct {
+	encoding string
+
+	mu   sync.Mutex
+	last map[string]compressedBody // by content type
+}
+
+// compressedBody is a compressed body and the digest of its identity form.
+type compressedBody struct {
+	sum  [sha256.Size]byte
+	data []byte
+}
+
+// newContentEncoder returns the encoder for encoding, or nil when it is
+// empty.
+func newContentEncoder(encoding string) *contentEncoder {
+	if encoding == "" {
+		return nil
+	}
+	return &contentEncoder{encoding: encoding, last: make(map[string]compressedBody)}
+}
+
+// write encodes v with enc into memory, compresses it when the client
+// accepts the encoding, and writes it as contentType with an exact
+// Content-Length.
+func (ce *contentEncoder) write(w http.ResponseWriter, r *http.Request, enc Encoder, contentType string, v any, status int) {
+	var body bytes.Buffer
+	if err := enc.Encode(&body, v); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	if !slices.Contains(h.Values("Vary"), "Accept-Encoding") {
+		h.Add("Vary", "Accept-Encoding")
+	}
+
+	out := body.Bytes()
+	if acceptsEncoding(r.Header.Get("Accept-Encoding"), ce.encoding) {
+		compressed, err := ce.compress(out, contentType)
+		if err == nil {
+			out = compressed
+			h.Set("Content-Encoding", ce.encoding)
+		}
+	}
+	h.Set("Content-Length", strconv.Itoa(len(out)))
+	w.WriteHeader(status)
+	//nolint:errcheck,gosec // best-effort after WriteHeader
+	w.Write(out)
+}
+
+// compress returns b compressed, reusing the stored result when b is the
+// body last compressed for contentType. Stored bodies are never modified,
+// so concurrent requests may write them.
+func (ce *contentEncoder) compress(b []byte, contentType string) ([]byte, error) {
+	sum := sha256.Sum256(b)
+	ce.mu.Lock()
+	last, ok := ce.last[contentType]
+	ce.mu.Unlock()
+	if ok && last.sum == sum {
+		return last.data, nil
+	}
+
+	data, err := compressBody(b, ce.encoding)
+	if err != nil {
+		return nil, err
+	}
+	ce.mu.Lock()
+	ce.last[contentType] = compressedBody{sum: sum, data: data}
+	ce.mu.Unlock()
+	return data, nil
+}
+
+func compressBody(b []byte, encoding string) ([]byte, error) {
+	var buf bytes.Buffer
+	var cw io.WriteCloser
+	switch encoding {
+	case "gzip":
+		gz := getGzipWriter(gzip.DefaultCompression, &buf)
+		defer putGzipWriter(gzip.DefaultCompression, gz)
+		cw = gz
+	default:
+		fw := deflateWriters.Get().(*flate.Writer) //nolint:errcheck,forcetypeassert // pool.New returns *flate.Writer
+		defer deflateWriters.Put(fw)
+		fw.Reset(&buf)
+		cw = fw
+	}
+
+	if _, err := cw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := cw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header value permits
+// encoding, honoring q=0 refusals and the "*" wildcard.
+func acceptsEncoding(header, encoding string) bool {
+	wildcard := false
+	for part := range strings.SplitSeq(header, ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		token = strings.ToLower(strings.TrimSpace(token))
+		refused := false
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(q, 64); err == nil && f == 0 {
+				refused = true
+			}
+		}
+		switch token {
+		case encoding:
+			return !refused
+		case "*":
+			wildcard = !refused
+		}
+	}
+	return wildcard
+}
@@ -0,0 +1,152 @@
+package api_test
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type ceCatalog struct {
+	Items []string `json:"items"`
+}
+
+func newContentEncodingRouter(encoding string, mw ...api.Middleware) *api.Router {
+	r := api.New()
+	r.Use(mw...)
+	api.Get(r, "/catalog", func(_ context.Context, _ *api.Void) (*api.Resp[ceCatalog], error) {
+		return &api.Resp[ceCatalog]{Body: ceCatalog{Items: []string{"a", "b"}}}, nil
+	}, api.WithContentEncoding(encoding))
+	return r
+}
+
+func TestWithContentEncoding(t *testing.T) {
+	t.Parallel()
+
+	const want = `{"items":["a","b"]}`
+
+	tests := map[string]struct {
+		encoding       string
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		"gzip accepted": {
+			encoding:       "gzip",
+			acceptEncoding: "br, gzip",
+			wantEncoding:   "gzip",
+		},
+		"deflate accepted": {
+			encoding:       "deflate",
+			acceptEncoding: "deflate;q=0.5",
+			wantEncoding:   "deflate",
+		},
+		"wildcard": {
+			encoding:       "gzip",
+			acceptEncoding: "*",
+			wantEncoding:   "gzip",
+		},
+		"refused with q=0": {
+			encoding:       "gzip",
+			acceptEncoding: "gzip;q=0, *",
+		},
+		"not accepted": {
+			encoding:       "gzip",
+			acceptEncoding: "br",
+		},
+		"no header": {
+			encoding: "gzip",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := newContentEncodingRouter(tt.encoding)
+			req := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantEncoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			assert.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+
+			var body io.Reader = w.Body
+			switch tt.wantEncoding {
+			case "gzip":
+				gz, err := gzip.NewReader(w.Body)
+				require.NoError(t, err)
+				body = gz
+			case "deflate":
+				body = flate.NewReader(w.Body)
+			}
+			got, err := io.ReadAll(body)
+			require.NoError(t, err)
+			assert.JSONEq(t, want, string(got))
+		})
+	}
+}
+
+func TestWithContentEncoding_with_compress_middleware(t *testing.T) {
+	t.Parallel()
+
+	r := newContentEncodingRouter("gzip", api.Compress(api.CompressConfig{MinSize: 1}))
+	req := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, []string{"Accept-Encoding"}, w.Header().Values("Vary"))
+
+	// Compressed once, not twice.
+	gz, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	got, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(got), `{"items"`))
+}
+
+func TestWithContentEncoding_stores_compressed_body(t *testing.T) {
+	t.Parallel()
+
+	compress := api.NewContentEncoderCompress("gzip")
+	catalog := []byte(strings.Repeat(`{"sku":"a"},`, 100))
+
+	first, err := compress(catalog, "application/json")
+	require.NoError(t, err)
+	again, err := compress(catalog, "application/json")
+	require.NoError(t, err)
+	assert.Same(t, &first[0], &again[0], "the same body is compressed once")
+
+	other, err := compress([]byte(`{"sku":"b"}`), "application/json")
+	require.NoError(t, err)
+	assert.NotSame(t, &first[0], &other[0])
+
+	zr, err := gzip.NewReader(strings.NewReader(string(first)))
+	require.NoError(t, err)
+	plain, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, catalog, plain)
+}
+
+func TestWithContentEncoding_unsupported_panics(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() { api.WithContentEncoding("br") })
+}
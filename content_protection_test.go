@@ -0,0 +1,132 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type cpItem struct {
+	ID string `json:"id"`
+}
+
+func newContentProtectionRouter(opts ...api.RouterOption) *api.Router {
+	r := api.New(opts...)
+	api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[[]cpItem], error) {
+		return &api.Resp[[]cpItem]{Body: []cpItem{{ID: "a"}}}, nil
+	})
+	api.Get(r, "/item", func(_ context.Context, _ *api.Void) (*api.Resp[cpItem], error) {
+		return &api.Resp[cpItem]{Body: cpItem{ID: "a"}}, nil
+	})
+	api.Get(r, "/missing", func(_ context.Context, _ *api.Void) (*api.Resp[cpItem], error) {
+		return nil, api.Error(api.CodeNotFound)
+	})
+	return r
+}
+
+func TestWithContentProtection(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		opts        []api.RouterOption
+		path        string
+		accept      string
+		wantCode    int
+		wantBody    string
+		wantNoSniff bool
+	}{
+		"no option": {
+			path:     "/items",
+			wantCode: http.StatusOK,
+			wantBody: `[{"id":"a"}]` + "\n",
+		},
+		"nosniff on arrays allowed": {
+			opts:        []api.RouterOption{api.WithContentProtection()},
+			path:        "/items",
+			wantCode:    http.StatusOK,
+			wantBody:    `[{"id":"a"}]` + "\n",
+			wantNoSniff: true,
+		},
+		"array prefixed": {
+			opts:        []api.RouterOption{api.WithContentProtection(api.ContentProtection{JSONArrays: api.JSONArrayPrefix})},
+			path:        "/items",
+			wantCode:    http.StatusOK,
+			wantBody:    ")]}',\n" + `[{"id":"a"}]` + "\n",
+			wantNoSniff: true,
+		},
+		"custom prefix": {
+			opts:        []api.RouterOption{api.WithContentProtection(api.ContentProtection{JSONArrays: api.JSONArrayPrefix, Prefix: "while(1);"})},
+			path:        "/items",
+			wantCode:    http.StatusOK,
+			wantBody:    `while(1);[{"id":"a"}]` + "\n",
+			wantNoSniff: true,
+		},
+		"objects not prefixed": {
+			opts:        []api.RouterOption{api.WithContentProtection(api.ContentProtection{JSONArrays: api.JSONArrayPrefix})},
+			path:        "/item",
+			wantCode:    http.StatusOK,
+			wantBody:    `{"id":"a"}` + "\n",
+			wantNoSniff: true,
+		},
+		"xml arrays not prefixed": {
+			opts:        []api.RouterOption{api.WithContentProtection(api.ContentProtection{JSONArrays: api.JSONArrayPrefix})},
+			path:        "/items",
+			accept:      "application/xml",
+			wantCode:    http.StatusOK,
+			wantNoSniff: true,
+		},
+		"error bodies": {
+			opts:        []api.RouterOption{api.WithContentProtection()},
+			path:        "/missing",
+			wantCode:    http.StatusNotFound,
+			wantNoSniff: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := newContentProtectionRouter(tt.opts...)
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+			if tt.wantNoSniff {
+				assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+			} else {
+				assert.Empty(t, w.Header().Get("X-Content-Type-Options"))
+			}
+		})
+	}
+}
+
+func TestWithContentProtection_reject_arrays(t *testing.T) {
+	t.Parallel()
+
+	opt := api.WithContentProtection(api.ContentProtection{JSONArrays: api.JSONArrayReject})
+	assert.Panics(t, func() { newContentProtectionRouter(opt) })
+
+	r := api.New(opt)
+	assert.NotPanics(t, func() {
+		api.Get(r, "/item", func(_ context.Context, _ *api.Void) (*api.Resp[cpItem], error) {
+			return &api.Resp[cpItem]{Body: cpItem{ID: "a"}}, nil
+		})
+		api.Get(r, "/raw", func(_ context.Context, _ *api.Void) (*api.Resp[[]byte], error) {
+			return &api.Resp[[]byte]{Body: []byte("x")}, nil
+		})
+	})
+}
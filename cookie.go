@@ -1,7 +1,10 @@
 package api
 
 import (
+	"context"
 	"net/http"
+	"reflect"
+	"sync"
 	"time"
 )
 
@@ -68,3 +71,135 @@ func CookieFromHTTP(c *http.Cookie) Cookie {
 		Quoted:      c.Quoted,
 	}
 }
+
+// CookieParam is a request field type for cookies that exposes whether the
+// cookie was sent and its raw wire value alongside the typed value. Browsers
+// only send a cookie's name and value, so attributes such as Path and
+// Expires are never available on the request side.
+//
+//	type Req struct {
+//	    Session api.CookieParam[string] `cookie:"session,secure" required:"true"`
+//	}
+type CookieParam[T any] struct {
+	// Value is the cookie value converted to T (after secure decoding when
+	// the field is tagged with the secure option).
+	Value T
+
+	// Present reports whether the request carried the cookie.
+	Present bool
+
+	// Raw is the cookie value exactly as received.
+	Raw string
+
+	// Quoted reports whether the value was sent double-quoted.
+	Quoted bool
+}
+
+// cookieBinder is implemented by *CookieParam[T]. The binder receives the
+// parsed request cookie and the (possibly secure-decoded) value.
+type cookieBinder interface {
+	bindCookie(c *http.Cookie, value string) error
+	cookieValueType() reflect.Type
+}
+
+func (p *CookieParam[T]) bindCookie(c *http.Cookie, value string) error {
+	p.Present = true
+	p.Raw = c.Value
+	p.Quoted = c.Quoted
+	return setFieldValue(reflect.ValueOf(&p.Value).Elem(), value)
+}
+
+func (*CookieParam[T]) cookieValueType() reflect.Type { return reflect.TypeFor[T]() }
+
+var cookieBinderType = reflect.TypeFor[cookieBinder]()
+
+// asCookieBinder returns the cookieBinder for a field of CookieParam type.
+func asCookieBinder(t reflect.Type) (cookieBinder, bool) {
+	if !reflect.PointerTo(t).Implements(cookieBinderType) {
+		return nil, false
+	}
+	b, ok := reflect.New(t).Interface().(cookieBinder)
+	return b, ok
+}
+
+// CookieDefaults holds attributes applied to every cookie the framework
+// emits — response cookie fields, error cookies, and cookies set via
+// SetCookie. Defaults fill attributes the cookie leaves unset; Secure and
+// HttpOnly can only be switched on by a default, never off.
+type CookieDefaults struct {
+	Path     string
+	Domain   string
+	Secure   bool
+	HttpOnly bool //nolint:staticcheck // ST1003: matches net/http.Cookie.HttpOnly for interop
+	SameSite http.SameSite
+}
+
+// WithCookieDefaults sets router-wide cookie attribute defaults so
+// individual response types don't need to repeat security attributes.
+func WithCookieDefaults(d CookieDefaults) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.cookieDefaults = &d
+	})
+}
+
+// apply returns c with unset attributes filled from the defaults.
+func (d *CookieDefaults) apply(c Cookie) Cookie {
+	if d == nil {
+		return c
+	}
+	if c.Path == "" {
+		c.Path = d.Path
+	}
+	if c.Domain == "" {
+		c.Domain = d.Domain
+	}
+	if c.SameSite == 0 {
+		c.SameSite = d.SameSite
+	}
+	c.Secure = c.Secure || d.Secure
+	c.HttpOnly = c.HttpOnly || d.HttpOnly
+	return c
+}
+
+// SetCookie queues a cookie to be emitted with the current handler's
+// response. Router cookie defaults are applied. Cookies queued before the
+// handler returns an error are still emitted with the error response.
+// Calling SetCookie outside a typed handler is a no-op.
+func SetCookie(ctx context.Context, name string, c Cookie) {
+	jar, ok := ctx.Value(cookieJarKey{}).(*cookieJar)
+	if !ok {
+		return
+	}
+	jar.mu.Lock()
+	defer jar.mu.Unlock()
+	jar.cookies = append(jar.cookies, namedCookie{name: name, cookie: c})
+}
+
+type cookieJarKey struct{}
+
+type namedCookie struct {
+	name   string
+	cookie Cookie
+}
+
+// cookieJar collects cookies queued via SetCookie during a handler call.
+type cookieJar struct {
+	mu      sync.Mutex
+	cookies []namedCookie
+}
+
+// flush writes the queued cookies to w and empties the jar.
+func (j *cookieJar) flush(w http.ResponseWriter, d *CookieDefaults) {
+	j.mu.Lock()
+	cookies := j.cookies
+	j.cookies = nil
+	j.mu.Unlock()
+	for _, nc := range cookies {
+		writeCookie(w, nc.name, nc.cookie, d)
+	}
+}
+
+// writeCookie emits a single Set-Cookie header after applying defaults.
+func writeCookie(w http.ResponseWriter, name string, c Cookie, d *CookieDefaults) {
+	http.SetCookie(w, d.apply(c).ToHTTPCookie(name))
+}
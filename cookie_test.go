@@ -1,11 +1,15 @@
 package api_test
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/bjaus/api"
 )
@@ -122,3 +126,224 @@ func TestCookie_Roundtrip(t *testing.T) {
 	back := api.CookieFromHTTP(stdlib)
 	assert.Equal(t, original, back, "roundtrip should be lossless for emission fields")
 }
+
+type cookieParamReq struct {
+	Theme   api.CookieParam[string] `cookie:"theme" default:"light"`
+	Visits  api.CookieParam[int]    `cookie:"visits" minimum:"1"`
+	Session api.CookieParam[string] `cookie:"session,secure" required:"true" doc:"Signed session"`
+}
+
+type cookieParamResp struct {
+	Theme          string `json:"theme"`
+	ThemePresent   bool   `json:"theme_present"`
+	Visits         int    `json:"visits"`
+	VisitsPresent  bool   `json:"visits_present"`
+	Session        string `json:"session"`
+	SessionRaw     string `json:"session_raw"`
+	SessionPresent bool   `json:"session_present"`
+}
+
+func newCookieParamRouter(t *testing.T) (*api.Router, *api.SecureCookies) {
+	t.Helper()
+
+	sc, err := api.NewSecureCookies([]byte("0123456789abcdef0123456789abcdef"), nil)
+	require.NoError(t, err)
+
+	r := api.New(api.WithSecureCookies(sc))
+	api.Get(r, "/prefs", func(_ context.Context, req *cookieParamReq) (*api.Resp[cookieParamResp], error) {
+		return &api.Resp[cookieParamResp]{Body: cookieParamResp{
+			Theme:          req.Theme.Value,
+			ThemePresent:   req.Theme.Present,
+			Visits:         req.Visits.Value,
+			VisitsPresent:  req.Visits.Present,
+			Session:        req.Session.Value,
+			SessionRaw:     req.Session.Raw,
+			SessionPresent: req.Session.Present,
+		}}, nil
+	})
+	return r, sc
+}
+
+func TestCookieParam_binding(t *testing.T) {
+	t.Parallel()
+
+	r, sc := newCookieParamRouter(t)
+	signed, err := sc.Encode("session", "user-42")
+	require.NoError(t, err)
+	forged, err := sc.Encode("other", "user-42")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		cookies    []*http.Cookie
+		wantStatus int
+		want       cookieParamResp
+	}{
+		"absent cookies use defaults and report presence": {
+			wantStatus: http.StatusOK,
+			want:       cookieParamResp{Theme: "light"},
+		},
+		"present cookies decode": {
+			cookies: []*http.Cookie{
+				{Name: "theme", Value: "dark"},
+				{Name: "visits", Value: "3"},
+				{Name: "session", Value: signed},
+			},
+			wantStatus: http.StatusOK,
+			want: cookieParamResp{
+				Theme: "dark", ThemePresent: true,
+				Visits: 3, VisitsPresent: true,
+				Session: "user-42", SessionRaw: signed, SessionPresent: true,
+			},
+		},
+		"tampered secure cookie is rejected": {
+			cookies:    []*http.Cookie{{Name: "session", Value: forged}},
+			wantStatus: http.StatusBadRequest,
+		},
+		"constraint applies when present": {
+			cookies:    []*http.Cookie{{Name: "visits", Value: "0"}},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		"type conversion error": {
+			cookies:    []*http.Cookie{{Name: "visits", Value: "many"}},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/prefs", nil)
+			for _, c := range tc.cookies {
+				req.AddCookie(c)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.wantStatus, rec.Code, rec.Body.String())
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var got cookieParamResp
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestCookieParam_openapi(t *testing.T) {
+	t.Parallel()
+
+	r, _ := newCookieParamRouter(t)
+	params := r.Spec().Paths["/prefs"]["get"].Parameters
+
+	byName := map[string]api.Parameter{}
+	for _, p := range params {
+		byName[p.Name] = p
+	}
+
+	require.Contains(t, byName, "session")
+	assert.Equal(t, "cookie", byName["session"].In)
+	assert.True(t, byName["session"].Required)
+	assert.Equal(t, "string", byName["session"].Schema.Type)
+	assert.Equal(t, "Signed session", byName["session"].Description)
+
+	require.Contains(t, byName, "visits")
+	assert.Equal(t, "integer", byName["visits"].Schema.Type)
+	assert.False(t, byName["visits"].Required)
+}
+
+func TestCookieParam_registration_errors(t *testing.T) {
+	t.Parallel()
+
+	type NoCodec struct {
+		Session string `cookie:"session,secure"`
+	}
+	type SecureQuery struct {
+		Q string `query:"q,secure"`
+	}
+	type WrongTag struct {
+		Q api.CookieParam[string] `query:"q"`
+	}
+
+	assert.Panics(t, func() {
+		api.Get(api.New(), "/a", func(_ context.Context, _ *NoCodec) (*api.Void, error) { return nil, nil })
+	})
+	assert.Panics(t, func() {
+		api.Get(api.New(), "/b", func(_ context.Context, _ *SecureQuery) (*api.Void, error) { return nil, nil })
+	})
+	assert.Panics(t, func() {
+		api.Get(api.New(), "/c", func(_ context.Context, _ *WrongTag) (*api.Void, error) { return nil, nil })
+	})
+}
+
+func TestWithCookieDefaults(t *testing.T) {
+	t.Parallel()
+
+	type Resp struct {
+		Session api.Cookie `cookie:"session"`
+		Pref    api.Cookie `cookie:"pref"`
+	}
+
+	r := api.New(api.WithCookieDefaults(api.CookieDefaults{
+		Path:     "/",
+		Domain:   "example.com",
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	}))
+	api.Get(r, "/login", func(ctx context.Context, _ *api.Void) (*Resp, error) {
+		api.SetCookie(ctx, "flash", api.Cookie{Value: "welcome"})
+		return &Resp{
+			Session: api.Cookie{Value: "abc"},
+			Pref:    api.Cookie{Value: "x", Path: "/prefs", SameSite: http.SameSiteLaxMode},
+		}, nil
+	})
+	api.Get(r, "/fail", func(ctx context.Context, _ *api.Void) (*api.Void, error) {
+		api.SetCookie(ctx, "attempt", api.Cookie{Value: "1"})
+		return nil, api.Error(api.CodeUnauthorized, api.WithCookie("session", api.Cookie{MaxAge: -1}))
+	})
+
+	cookiesFor := func(path string) (int, map[string]*http.Cookie) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		out := map[string]*http.Cookie{}
+		for _, c := range rec.Result().Cookies() { //nolint:bodyclose // recorder body
+			out[c.Name] = c
+		}
+		return rec.Code, out
+	}
+
+	status, cookies := cookiesFor("/login")
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, cookies, 3)
+
+	for _, name := range []string{"session", "flash"} {
+		c := cookies[name]
+		assert.Equal(t, "/", c.Path, name)
+		assert.Equal(t, "example.com", c.Domain, name)
+		assert.True(t, c.Secure, name)
+		assert.True(t, c.HttpOnly, name)
+		assert.Equal(t, http.SameSiteStrictMode, c.SameSite, name)
+	}
+	assert.Equal(t, "welcome", cookies["flash"].Value)
+
+	// Explicit attributes win over defaults.
+	assert.Equal(t, "/prefs", cookies["pref"].Path)
+	assert.Equal(t, http.SameSiteLaxMode, cookies["pref"].SameSite)
+
+	status, cookies = cookiesFor("/fail")
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Contains(t, cookies, "attempt")
+	require.Contains(t, cookies, "session")
+	assert.True(t, cookies["session"].Secure)
+	assert.True(t, cookies["attempt"].HttpOnly)
+}
+
+func TestSetCookie_outside_handler(t *testing.T) {
+	t.Parallel()
+
+	assert.NotPanics(t, func() {
+		api.SetCookie(context.Background(), "x", api.Cookie{Value: "y"})
+	})
+}
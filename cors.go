@@ -2,13 +2,18 @@ package api
 
 import (
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 )
 
 // CORSConfig configures the CORS middleware.
 type CORSConfig struct {
-	AllowOrigins     []string
+	AllowOrigins []string
+
+	// AllowMethods limits the methods preflight requests may ask for.
+	// When empty, a preflight through a Router is answered with the
+	// methods registered for its path.
 	AllowMethods     []string
 	AllowHeaders     []string
 	ExposeHeaders    []string
@@ -18,14 +23,23 @@ type CORSConfig struct {
 
 // CORS returns middleware that handles Cross-Origin Resource Sharing.
 // If no config is provided, permissive defaults are used.
+//
+// Installed with Router.Use, CORS answers preflight requests from the route
+// table: Access-Control-Allow-Methods lists the methods registered for the
+// requested path, narrowed to AllowMethods when the config sets them, and
+// a preflight for a path with no routes falls through to the router's 404.
+// No OPTIONS routes are needed.
 func CORS(cfg ...CORSConfig) Middleware {
 	c := CORSConfig{
 		AllowOrigins: []string{"*"},
 		AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders: []string{"Content-Type", "Authorization"},
 	}
+	// Only methods the caller chose narrow the routed ones.
+	var restrict []string
 	if len(cfg) > 0 {
 		c = cfg[0]
+		restrict = c.AllowMethods
 	}
 
 	origins := strings.Join(c.AllowOrigins, ", ")
@@ -37,7 +51,7 @@ func CORS(cfg ...CORSConfig) Middleware {
 		maxAge = strconv.Itoa(c.MaxAge)
 	}
 
-	return func(next http.Handler) http.Handler {
+	return Named("cors", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", origins)
 			w.Header().Set("Access-Control-Allow-Methods", methods)
@@ -56,11 +70,44 @@ func CORS(cfg ...CORSConfig) Middleware {
 			w.Header().Set("Vary", "Origin")
 
 			if r.Method == http.MethodOptions {
+				if rt, ok := r.Context().Value(routerKey{}).(*Router); ok && isPreflight(r) {
+					routed := routedMethods(rt, r, restrict)
+					if routed == nil {
+						next.ServeHTTP(w, r)
+						return
+					}
+					w.Header().Set("Access-Control-Allow-Methods", strings.Join(routed, ", "))
+				}
 				w.WriteHeader(http.StatusNoContent)
 				return
 			}
 
 			next.ServeHTTP(w, r)
 		})
+	})
+}
+
+// isPreflight reports whether r is a CORS preflight request.
+func isPreflight(r *http.Request) bool {
+	return r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// routedMethods returns the methods rt routes for r's path, kept to those
+// in restrict when it is non-empty, or nil when the path has no routes.
+func routedMethods(rt *Router, r *http.Request, restrict []string) []string {
+	methods := rt.allowedMethods(r)
+	if len(methods) == 0 {
+		return nil
+	}
+	methods = appendMethod(methods, http.MethodOptions)
+	if len(restrict) == 0 {
+		return methods
+	}
+	out := make([]string, 0, len(methods))
+	for _, m := range methods {
+		if slices.Contains(restrict, m) {
+			out = append(out, m)
+		}
 	}
+	return out
 }
@@ -102,3 +102,59 @@ func TestCORS(t *testing.T) {
 		})
 	}
 }
+
+func TestCORS_routed_preflight(t *testing.T) {
+	t.Parallel()
+
+	handler := func(_ context.Context, _ *struct{}) (*api.Void, error) { return &api.Void{}, nil }
+
+	tests := map[string]struct {
+		cfg         []api.CORSConfig
+		path        string
+		preflight   bool
+		wantStatus  int
+		wantMethods string
+	}{
+		"methods from routes": {
+			path: "/items/1", preflight: true,
+			wantStatus: http.StatusNoContent, wantMethods: "DELETE, GET, HEAD, OPTIONS",
+		},
+		"narrowed by config": {
+			cfg:  []api.CORSConfig{{AllowOrigins: []string{"*"}, AllowMethods: []string{"GET", "POST"}}},
+			path: "/items/1", preflight: true,
+			wantStatus: http.StatusNoContent, wantMethods: "GET",
+		},
+		"unrouted path": {
+			path: "/nope", preflight: true,
+			wantStatus: http.StatusNotFound,
+		},
+		"plain options keeps config": {
+			path:       "/items/1",
+			wantStatus: http.StatusNoContent, wantMethods: "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New()
+			r.Use(api.CORS(tc.cfg...))
+			api.Get(r, "/items/{id}", handler)
+			api.Delete(r, "/items/{id}", handler)
+
+			req := httptest.NewRequest(http.MethodOptions, tc.path, http.NoBody)
+			if tc.preflight {
+				req.Header.Set("Origin", "https://app.example.com")
+				req.Header.Set("Access-Control-Request-Method", http.MethodDelete)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantMethods != "" {
+				assert.Equal(t, tc.wantMethods, w.Header().Get("Access-Control-Allow-Methods"))
+			}
+		})
+	}
+}
@@ -19,7 +19,8 @@ type CSRFConfig struct {
 type csrfTokenKey struct{}
 
 // CSRF returns middleware that implements double-submit cookie CSRF protection.
-// Safe methods (GET, HEAD, OPTIONS) are skipped.
+// Safe methods (GET, HEAD, OPTIONS) are skipped. A mismatch is written with
+// WriteError as 403.
 func CSRF(cfg ...CSRFConfig) Middleware {
 	c := CSRFConfig{
 		TokenLength: 32,
@@ -43,7 +44,7 @@ func CSRF(cfg ...CSRFConfig) Middleware {
 		}
 	}
 
-	return func(next http.Handler) http.Handler {
+	return Named("csrf", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Read existing token from cookie.
 			cookie, err := r.Cookie(c.CookieName)
@@ -78,13 +79,13 @@ func CSRF(cfg ...CSRFConfig) Middleware {
 			// Validate token from header matches cookie.
 			headerToken := r.Header.Get(c.HeaderName)
 			if headerToken == "" || headerToken != token {
-				http.Error(w, "CSRF token mismatch", http.StatusForbidden)
+				WriteError(w, r, Error(CodeForbidden, WithMessage("CSRF token mismatch")))
 				return
 			}
 
 			next.ServeHTTP(w, r)
 		})
-	}
+	})
 }
 
 // GetCSRFToken retrieves the CSRF token from the request context.
@@ -0,0 +1,234 @@
+// Package csv provides a CSV response encoder for the api framework.
+//
+// Offer it on the routes that export data, to serve text/csv to clients
+// that ask for it, so list endpoints double as exports without a separate
+// handler:
+//
+//	api.Get(r, "/orders", h.List, api.WithResponseEncoder(csv.Encoder{}))
+//
+// Registering it on the router with api.WithEncoder offers it on every
+// operation instead.
+//
+// A slice of structs is encoded as a header row followed by one row per
+// element; a single struct, such as an error body, is encoded as one row.
+// Columns are named by csv tags, falling back to json tags and then the
+// field name, and a tag of "-" omits the field:
+//
+//	type Order struct {
+//	    ID    string    `json:"id"`
+//	    Total float64   `json:"total" csv:"total_usd"`
+//	    Notes string    `json:"notes" csv:"-"`
+//	    At    time.Time `json:"at"`
+//	}
+//
+// Exported embedded structs contribute their fields. TextMarshaler values,
+// such as time.Time, are written as their text; other nested structs,
+// maps, and slices are written as JSON. Nil pointers are empty cells.
+//
+// Spreadsheets run cells that start with =, +, -, or @ as formulas, so
+// text cells starting with one of them, or with a tab or carriage return,
+// are prefixed with a single quote to keep client-supplied values from
+// running on export. Numbers are written as they are.
+package csv
+
+import (
+	"encoding"
+	stdcsv "encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type negotiated for CSV bodies.
+const ContentType = "text/csv"
+
+// Encoder implements api.Encoder for CSV.
+type Encoder struct{}
+
+// ContentType implements api.Encoder.
+func (Encoder) ContentType() string { return ContentType }
+
+// Encode implements api.Encoder.
+func (Encoder) Encode(w io.Writer, v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	var rows []reflect.Value
+	var elem reflect.Type
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		elem = rv.Type().Elem()
+		for i := range rv.Len() {
+			rows = append(rows, rv.Index(i))
+		}
+	case reflect.Struct:
+		elem = rv.Type()
+		rows = append(rows, rv)
+	default:
+		return fmt.Errorf("csv: cannot encode %s; want a struct or a slice of structs", rv.Type())
+	}
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: cannot encode %s; want a struct or a slice of structs", rv.Type())
+	}
+
+	cols := columnsOf(elem)
+	cw := stdcsv.NewWriter(w)
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(cols))
+	for _, row := range rows {
+		if row.Kind() == reflect.Pointer {
+			if row.IsNil() {
+				continue
+			}
+			row = row.Elem()
+		}
+		for i, c := range cols {
+			cell, err := cellOf(row, c.index)
+			if err != nil {
+				return fmt.Errorf("csv: column %s: %w", c.name, err)
+			}
+			record[i] = cell
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// column is one CSV column: its header and the field path to its value.
+type column struct {
+	name  string
+	index []int
+}
+
+var columnCache sync.Map // reflect.Type → []column
+
+// columnsOf returns the columns of a struct type, in field order.
+func columnsOf(t reflect.Type) []column {
+	if cached, ok := columnCache.Load(t); ok {
+		return cached.([]column) //nolint:errcheck,forcetypeassert // only []column is stored
+	}
+	cols := appendColumns(nil, t, nil)
+	columnCache.Store(t, cols)
+	return cols
+}
+
+func appendColumns(cols []column, t reflect.Type, index []int) []column {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		path := append(append([]int{}, index...), i)
+		name, tagged := fieldName(f)
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && !tagged {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				cols = appendColumns(cols, ft, path)
+				continue
+			}
+		}
+		cols = append(cols, column{name: name, index: path})
+	}
+	return cols
+}
+
+// fieldName returns the column name of f and whether a tag named it.
+func fieldName(f reflect.StructField) (string, bool) {
+	for _, key := range []string{"csv", "json"} {
+		tag, ok := f.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name != "" {
+			return name, true
+		}
+	}
+	return f.Name, false
+}
+
+// cellOf formats the field at index, or "" when a nil embedded pointer
+// is on the path.
+func cellOf(v reflect.Value, index []int) (string, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return "", nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return format(v)
+}
+
+func format(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+	}
+	if tm, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return escapeFormula(string(b)), err
+	}
+	if v.CanAddr() {
+		if tm, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+			b, err := tm.MarshalText()
+			return escapeFormula(string(b)), err
+		}
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return format(v.Elem())
+	case reflect.String:
+		return escapeFormula(v.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	default:
+		b, err := json.Marshal(v.Interface())
+		return string(b), err
+	}
+}
+
+// escapeFormula prefixes s with a single quote when a spreadsheet would
+// read it as a formula.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
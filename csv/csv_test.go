@@ -0,0 +1,145 @@
+package csv_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+	"github.com/bjaus/api/csv"
+)
+
+var _ api.Encoder = csv.Encoder{}
+
+type Base struct {
+	ID string `json:"id"`
+}
+
+type orderReq struct {
+	Body Base
+}
+
+type Order struct {
+	Base
+	Customer string            `json:"customer"`
+	Total    float64           `json:"total" csv:"total_usd"`
+	Notes    string            `json:"notes" csv:"-"`
+	Placed   time.Time         `json:"placed"`
+	Shipped  *time.Time        `json:"shipped,omitempty"`
+	Labels   map[string]string `json:"labels"`
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	t.Parallel()
+
+	placed := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		value   any
+		want    string
+		wantErr bool
+	}{
+		"slice": {
+			value: []Order{
+				{Base: Base{ID: "o1"}, Customer: "Ada, Ltd.", Total: 12.5, Notes: "x", Placed: placed, Labels: map[string]string{"rush": "yes"}},
+				{Base: Base{ID: "o2"}, Customer: `Grace "Amazing"`, Total: 3, Placed: placed, Shipped: &placed},
+			},
+			want: "id,customer,total_usd,placed,shipped,labels\n" +
+				`o1,"Ada, Ltd.",12.5,2026-03-01T09:30:00Z,,"{""rush"":""yes""}"` + "\n" +
+				`o2,"Grace ""Amazing""",3,2026-03-01T09:30:00Z,2026-03-01T09:30:00Z,null` + "\n",
+		},
+		"pointer elements": {
+			value: []*Order{{Base: Base{ID: "o1"}, Placed: placed}, nil},
+			want:  "id,customer,total_usd,placed,shipped,labels\no1,,0,2026-03-01T09:30:00Z,,null\n",
+		},
+		"empty slice": {
+			value: []Order{},
+			want:  "id,customer,total_usd,placed,shipped,labels\n",
+		},
+		"formulas": {
+			value: []Base{{ID: "=HYPERLINK(\"http://x\")"}, {ID: "+1"}, {ID: "-1"}, {ID: "@SUM(A1)"}, {ID: "\tx"}, {ID: "a=b"}},
+			want:  "id\n\"'=HYPERLINK(\"\"http://x\"\")\"\n'+1\n'-1\n'@SUM(A1)\n'\tx\na=b\n",
+		},
+		"negative numbers": {
+			value: []struct {
+				N int     `json:"n"`
+				F float64 `json:"f"`
+			}{{N: -1, F: -2.5}},
+			want: "n,f\n-1,-2.5\n",
+		},
+		"single struct": {
+			value: &Base{ID: "o1"},
+			want:  "id\no1\n",
+		},
+		"not tabular": {
+			value:   []string{"a"},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := csv.Encoder{}.Encode(&buf, tc.value)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestEncoder_negotiated(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/orders", func(_ context.Context, _ *api.Void) (*api.Resp[[]Base], error) {
+		return &api.Resp[[]Base]{Body: []Base{{ID: "o1"}, {ID: "o2"}}}, nil
+	}, api.WithResponseEncoder(csv.Encoder{}))
+	api.Post(r, "/orders", func(_ context.Context, req *orderReq) (*api.Resp[Base], error) {
+		return &api.Resp[Base]{Body: req.Body}, nil
+	})
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/orders", http.NoBody)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("text/csv")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, "id\no1\no2\n", w.Body.String())
+
+	w = get("application/json")
+	assert.JSONEq(t, `[{"id":"o1"},{"id":"o2"}]`, w.Body.String())
+
+	op := r.Spec().Paths["/orders"]["get"]
+	assert.Contains(t, op.Responses["200"].Content, "text/csv")
+	assert.Contains(t, op.Responses["500"].Content, "text/csv")
+
+	// Other routes neither negotiate nor document CSV.
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"id":"o3"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/csv")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+
+	post := r.Spec().Paths["/orders"]["post"]
+	assert.NotContains(t, post.Responses["200"].Content, "text/csv")
+	assert.NotContains(t, post.RequestBody.Content, "text/csv")
+}
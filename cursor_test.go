@@ -0,0 +1,147 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type crKey struct {
+	ID int `json:"id"`
+}
+
+type crListReq struct {
+	After api.Cursor[crKey] `query:"after" doc:"Cursor from a previous page"`
+}
+
+type crListResp struct {
+	Body struct {
+		After *int   `json:"after,omitempty"`
+		Next  string `json:"next"`
+	}
+}
+
+func newCursorRouter(opts ...api.RouterOption) *api.Router {
+	r := api.New(opts...)
+	api.Get(r, "/events", func(ctx context.Context, req *crListReq) (*crListResp, error) {
+		resp := &crListResp{}
+		start := 0
+		if req.After.Present {
+			resp.Body.After = &req.After.Value.ID
+			start = req.After.Value.ID
+		}
+		next, err := api.EncodeCursor(ctx, crKey{ID: start + 10})
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Next = next
+		return resp, nil
+	})
+	return r
+}
+
+func getEvents(t *testing.T, r http.Handler, after string) (int, crListResp) {
+	t.Helper()
+
+	target := "/events"
+	if after != "" {
+		target += "?after=" + url.QueryEscape(after)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, target, nil))
+
+	var resp crListResp
+	if rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp.Body))
+	}
+	return rec.Code, resp
+}
+
+func TestCursor_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	for name, opts := range map[string][]api.RouterOption{
+		"unsigned": nil,
+		"signed":   {api.WithCursorKey([]byte("0123456789abcdef0123456789abcdef"))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := newCursorRouter(opts...)
+
+			status, first := getEvents(t, r, "")
+			require.Equal(t, http.StatusOK, status)
+			assert.Nil(t, first.Body.After)
+
+			status, second := getEvents(t, r, first.Body.Next)
+			require.Equal(t, http.StatusOK, status)
+			require.NotNil(t, second.Body.After)
+			assert.Equal(t, 10, *second.Body.After)
+
+			status, _ = getEvents(t, r, "not a cursor")
+			assert.Equal(t, http.StatusBadRequest, status)
+		})
+	}
+}
+
+func TestCursor_signature(t *testing.T) {
+	t.Parallel()
+
+	signed := newCursorRouter(api.WithCursorKey([]byte("0123456789abcdef0123456789abcdef")))
+	other := newCursorRouter(api.WithCursorKey([]byte("fedcba9876543210fedcba9876543210")))
+	unsigned := newCursorRouter()
+
+	_, page := getEvents(t, signed, "")
+	_, plain := getEvents(t, unsigned, "")
+
+	tests := map[string]struct {
+		router http.Handler
+		token  string
+		want   int
+	}{
+		"valid":             {router: signed, token: page.Body.Next, want: http.StatusOK},
+		"other key":         {router: other, token: page.Body.Next, want: http.StatusBadRequest},
+		"unsigned token":    {router: signed, token: plain.Body.Next, want: http.StatusBadRequest},
+		"signed to unkeyed": {router: unsigned, token: page.Body.Next, want: http.StatusBadRequest},
+		"edited payload":    {router: signed, token: "eyJpZCI6OTl9." + page.Body.Next[len(plain.Body.Next)+1:], want: http.StatusBadRequest},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			status, _ := getEvents(t, tc.router, tc.token)
+			assert.Equal(t, tc.want, status)
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	t.Parallel()
+
+	token, err := api.EncodeCursor(context.Background(), crKey{ID: 7})
+	require.NoError(t, err)
+
+	var got crKey
+	require.NoError(t, api.DecodeCursor(context.Background(), token, &got))
+	assert.Equal(t, crKey{ID: 7}, got)
+
+	assert.ErrorIs(t, api.DecodeCursor(context.Background(), "!!", &got), api.ErrInvalidCursor)
+}
+
+func TestCursor_spec(t *testing.T) {
+	t.Parallel()
+
+	op := newCursorRouter().Spec().Paths["/events"]["get"]
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, "after", op.Parameters[0].Name)
+	assert.Equal(t, "string", op.Parameters[0].Schema.Type)
+}
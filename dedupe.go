@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DedupeStore records the event IDs of webhook deliveries for
+// DedupeWebhook. Implementations backed by Redis or a database share the
+// record across instances.
+type DedupeStore interface {
+	// Seen records key for window and reports whether it was already
+	// recorded within its window. It must be atomic, so that of two
+	// concurrent deliveries only one sees false.
+	Seen(ctx context.Context, key string, window time.Duration) (bool, error)
+
+	// Forget removes key, so a redelivery of an event whose handling
+	// failed is processed again.
+	Forget(ctx context.Context, key string) error
+}
+
+// DedupeConfig configures DedupeWebhook.
+type DedupeConfig struct {
+	// Window is how long an event ID is remembered. Default: 24h, longer
+	// than the retry schedules of common providers.
+	Window time.Duration
+
+	// OnDuplicate is called for each dropped delivery, to count
+	// duplicates in a metrics system.
+	OnDuplicate func(r *http.Request, key string)
+}
+
+// DedupeWebhook returns middleware that acknowledges repeated deliveries of
+// the same webhook event with 200 OK instead of handling them again.
+// keyFunc returns the event ID of a delivery, or "" to handle it without
+// deduplication:
+//
+//	hooks := r.Group("/webhooks", api.WithGroupMiddleware(
+//	    api.DedupeWebhook(store, api.DedupeHeader("X-GitHub-Delivery")),
+//	))
+//	api.Post(hooks, "/github", h.GitHub)
+//
+// A keyFunc that reads the body, such as for a Stripe event ID, must
+// restore r.Body for the handler. When handling fails with a 5xx status or
+// a panic, the event ID is forgotten so the provider's retry is processed;
+// a duplicate that arrives while the first delivery is still being handled
+// is acknowledged regardless.
+// When the store fails, the delivery is handled, since handling an event
+// twice is better than losing it.
+func DedupeWebhook(store DedupeStore, keyFunc func(r *http.Request) string, cfg ...DedupeConfig) Middleware {
+	var c DedupeConfig
+	if len(cfg) > 0 {
+		c = cfg[0]
+	}
+	if c.Window <= 0 {
+		c.Window = 24 * time.Hour
+	}
+
+	return Named("dedupe", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := keyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			seen, err := store.Seen(r.Context(), key, c.Window)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if seen {
+				if c.OnDuplicate != nil {
+					c.OnDuplicate(r, key)
+				}
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			handled := false
+			defer func() {
+				if !handled || rec.status >= http.StatusInternalServerError {
+					//nolint:errcheck,gosec // the ID expires with its window regardless
+					store.Forget(context.WithoutCancel(r.Context()), key)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+			handled = true
+		})
+	})
+}
+
+// DedupeHeader returns a DedupeWebhook key function that reads the event
+// ID from the named request header, such as X-GitHub-Delivery.
+func DedupeHeader(name string) func(r *http.Request) string {
+	return func(r *http.Request) string { return r.Header.Get(name) }
+}
+
+// MemoryDedupeStore is an in-memory DedupeStore for a single instance,
+// tests, and development. Records are lost on restart.
+type MemoryDedupeStore struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+	pruned  time.Time
+}
+
+// NewMemoryDedupeStore creates an empty in-memory dedupe store.
+func NewMemoryDedupeStore() *MemoryDedupeStore {
+	return &MemoryDedupeStore{expires: make(map[string]time.Time)}
+}
+
+// Seen implements DedupeStore.
+func (s *MemoryDedupeStore) Seen(_ context.Context, key string, window time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.pruned) >= time.Minute {
+		for k, exp := range s.expires {
+			if !now.Before(exp) {
+				delete(s.expires, k)
+			}
+		}
+		s.pruned = now
+	}
+	if exp, ok := s.expires[key]; ok && now.Before(exp) {
+		return true, nil
+	}
+	s.expires[key] = now.Add(window)
+	return false, nil
+}
+
+// Forget implements DedupeStore.
+func (s *MemoryDedupeStore) Forget(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.expires, key)
+	return nil
+}
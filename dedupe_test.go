@@ -0,0 +1,90 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func TestDedupeWebhook(t *testing.T) {
+	t.Parallel()
+
+	var handled, duplicates atomic.Int32
+	fail := errors.New("downstream unavailable")
+	r := api.New()
+	hooks := r.Group("/webhooks", api.WithGroupMiddleware(
+		api.DedupeWebhook(api.NewMemoryDedupeStore(), api.DedupeHeader("X-Delivery"), api.DedupeConfig{
+			OnDuplicate: func(_ *http.Request, _ string) { duplicates.Add(1) },
+		}),
+	))
+	type hookReq struct {
+		Fail bool `query:"fail"`
+	}
+	api.Post(hooks, "/github", func(_ context.Context, req *hookReq) (*api.Void, error) {
+		handled.Add(1)
+		if req.Fail {
+			return nil, fail
+		}
+		return &api.Void{}, nil
+	})
+
+	deliver := func(id, query string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/github"+query, http.NoBody)
+		if id != "" {
+			req.Header.Set("X-Delivery", id)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, deliver("a", ""))
+	assert.Equal(t, http.StatusOK, deliver("a", ""))
+	assert.Equal(t, http.StatusNoContent, deliver("b", ""))
+	assert.Equal(t, int32(2), handled.Load())
+	assert.Equal(t, int32(1), duplicates.Load())
+
+	// A failed delivery is processed again on retry.
+	assert.Equal(t, http.StatusInternalServerError, deliver("c", "?fail=true"))
+	assert.Equal(t, http.StatusNoContent, deliver("c", ""))
+	assert.Equal(t, int32(4), handled.Load())
+
+	// Deliveries without an ID are never deduplicated.
+	assert.Equal(t, http.StatusNoContent, deliver("", ""))
+	assert.Equal(t, http.StatusNoContent, deliver("", ""))
+	assert.Equal(t, int32(6), handled.Load())
+}
+
+func TestMemoryDedupeStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := api.NewMemoryDedupeStore()
+
+	seen, err := s.Seen(ctx, "evt", 20*time.Millisecond)
+	require.NoError(t, err)
+	assert.False(t, seen)
+
+	seen, err = s.Seen(ctx, "evt", 20*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, seen)
+
+	require.NoError(t, s.Forget(ctx, "evt"))
+	seen, err = s.Seen(ctx, "evt", 20*time.Millisecond)
+	require.NoError(t, err)
+	assert.False(t, seen)
+
+	time.Sleep(30 * time.Millisecond)
+	seen, err = s.Seen(ctx, "evt", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, seen, "expired")
+}
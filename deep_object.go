@@ -0,0 +1,198 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// isDeepObjectType reports whether a query param of type t binds as a deep
+// object: a struct, or a pointer to one, that does not parse itself. Each
+// field of the struct tagged query is read from name[field], or name.field,
+// and nested structs nest the same way:
+//
+//	type Filter struct {
+//	    Status   string  `query:"status"`
+//	    MinPrice float64 `query:"min_price" minimum:"0"`
+//	    Created  struct {
+//	        After time.Time `query:"after"`
+//	    } `query:"created"`
+//	}
+//
+//	type ListReq struct {
+//	    Filter Filter `query:"filter"`
+//	}
+//
+// binds ?filter[status]=active&filter[min_price]=10&filter[created][after]=...
+// and is documented as a style: deepObject parameter. A pointer to a struct
+// stays nil unless at least one of its keys is present.
+func isDeepObjectType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct &&
+		!reflect.PointerTo(t).Implements(textUnmarshalerType) &&
+		!isCursorType(t) && t != locationType
+}
+
+// isDeepObjectParam reports whether f is a query param bound as a deep
+// object.
+func isDeepObjectParam(f reflect.StructField) bool {
+	return f.Tag.Get("query") != "" && isDeepObjectType(f.Type)
+}
+
+// deepObjectFields returns the query-tagged fields of a deep object struct
+// with their names.
+func deepObjectFields(t reflect.Type) (fields []reflect.StructField, names []string) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || (f.Anonymous && f.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		name, _ := tagOptions(f.Tag.Get("query"))
+		if name == "" {
+			continue
+		}
+		fields = append(fields, f)
+		names = append(names, name)
+	}
+	return fields, names
+}
+
+// bindDeepObject binds the struct, or pointer to struct, in v from the
+// query keys under path. bound reports whether any key was present.
+func bindDeepObject(v reflect.Value, q url.Values, path []string) (bound bool, err error) {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		bound, err = bindDeepObject(elem.Elem(), q, path)
+		if bound && err == nil {
+			v.Set(elem)
+		}
+		return bound, err
+	}
+
+	fields, names := deepObjectFields(v.Type())
+	for i, f := range fields {
+		fieldPath := append(path[:len(path):len(path)], names[i])
+		fv := v.FieldByIndex(f.Index)
+
+		if isDeepObjectType(f.Type) {
+			ok, err := bindDeepObject(fv, q, fieldPath)
+			if err != nil {
+				return bound, err
+			}
+			bound = bound || ok
+			continue
+		}
+
+		vals := deepObjectValues(q, fieldPath)
+		if len(vals) == 0 {
+			def := f.Tag.Get("default")
+			if def == "" {
+				continue
+			}
+			vals = []string{def}
+		} else {
+			bound = true
+		}
+
+		if isMultiValueType(f.Type) {
+			var split []string
+			for _, v := range vals {
+				split = append(split, strings.Split(v, ",")...)
+			}
+			out := reflect.MakeSlice(f.Type, len(split), len(split))
+			for j, s := range split {
+				if err := setFieldValue(out.Index(j), s); err != nil {
+					return bound, fmt.Errorf("%s: %w", bracketKey(fieldPath), err)
+				}
+			}
+			fv.Set(out)
+			continue
+		}
+		if vals[0] == "" {
+			bindEmptyPointer(fv)
+			continue
+		}
+		if err := setFieldValue(fv, vals[0]); err != nil {
+			return bound, fmt.Errorf("%s: %w", bracketKey(fieldPath), err)
+		}
+	}
+	return bound, nil
+}
+
+// deepObjectName returns the name p's keys are sent under: its current
+// name, or else the first alias with keys. ok is false when neither has
+// any, so the param is absent.
+func deepObjectName(q url.Values, p requestParamDesc) (name string, ok bool) {
+	for _, n := range append([]string{p.name}, p.aliases...) {
+		for key := range q {
+			if strings.HasPrefix(key, n+"[") || strings.HasPrefix(key, n+".") {
+				return n, true
+			}
+		}
+	}
+	return p.name, false
+}
+
+// deepObjectValues returns the values of the key at path, written with
+// brackets, filter[price][min], or with dots, filter.price.min.
+func deepObjectValues(q url.Values, path []string) []string {
+	if vals, ok := q[bracketKey(path)]; ok {
+		return vals
+	}
+	return q[strings.Join(path, ".")]
+}
+
+// bracketKey writes path in deepObject form.
+func bracketKey(path []string) string {
+	return path[0] + "[" + strings.Join(path[1:], "][") + "]"
+}
+
+// deepObjectSchema documents a deep object param as an object whose
+// properties are its query-tagged fields.
+func deepObjectSchema(t reflect.Type) JSONSchema {
+	schema := JSONSchema{Type: "object", Properties: map[string]JSONSchema{}}
+	fields, names := deepObjectFields(t)
+	for i, f := range fields {
+		var prop JSONSchema
+		if isDeepObjectType(f.Type) {
+			prop = deepObjectSchema(f.Type)
+		} else {
+			prop = typeToSchema(f.Type)
+		}
+		applyConstraintTags(&prop, f)
+		if doc := f.Tag.Get("doc"); doc != "" {
+			prop.Description = doc
+		}
+		schema.Properties[names[i]] = prop
+		if f.Tag.Get("required") == "true" {
+			schema.Required = append(schema.Required, names[i])
+		}
+	}
+	return schema
+}
+
+// checkDeepObjectTypes reports fields of a deep object param that binding
+// cannot parse.
+func checkDeepObjectTypes(p *registrationProblems, name string, t reflect.Type) {
+	fields, names := deepObjectFields(t)
+	for i, f := range fields {
+		key := name + "[" + names[i] + "]"
+		ft := f.Type
+		switch {
+		case isDeepObjectType(ft):
+			checkDeepObjectTypes(p, key, ft)
+			continue
+		case isMultiValueType(ft):
+			ft = ft.Elem()
+		}
+		if !paramTypeSupported(ft) {
+			p.add("use a string, bool, integer, float, or time.Duration, a pointer to one, a slice of one, a nested struct, or a type implementing encoding.TextUnmarshaler",
+				"query param %q has unsupported type %s", key, f.Type)
+		}
+	}
+}
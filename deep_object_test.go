@@ -0,0 +1,230 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type priceRange struct {
+	Min float64 `query:"min" minimum:"0"`
+	Max float64 `query:"max"`
+}
+
+type listFilter struct {
+	Status string     `query:"status" doc:"Lifecycle state."`
+	Tags   []string   `query:"tags"`
+	Limit  *int       `query:"limit"`
+	Price  priceRange `query:"price"`
+}
+
+type listReq struct {
+	Filter listFilter  `query:"filter"`
+	Sort   *priceRange `query:"sort"`
+}
+
+func TestDeepObject_binding(t *testing.T) {
+	t.Parallel()
+
+	limit := 5
+	tests := map[string]struct {
+		query string
+		want  listReq
+	}{
+		"absent": {},
+		"brackets": {
+			query: "filter[status]=active&filter[limit]=5&filter[price][min]=10&filter[price][max]=20",
+			want:  listReq{Filter: listFilter{Status: "active", Limit: &limit, Price: priceRange{Min: 10, Max: 20}}},
+		},
+		"dots": {
+			query: "filter.status=archived&filter.price.min=1.5",
+			want:  listReq{Filter: listFilter{Status: "archived", Price: priceRange{Min: 1.5}}},
+		},
+		"repeated and comma-separated slice": {
+			query: "filter[tags]=a,b&filter[tags]=c",
+			want:  listReq{Filter: listFilter{Tags: []string{"a", "b", "c"}}},
+		},
+		"pointer struct set when a key is present": {
+			query: "sort[max]=3",
+			want:  listReq{Sort: &priceRange{Max: 3}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got listReq
+			r := api.New()
+			api.Get(r, "/items", func(_ context.Context, req *listReq) (*api.Void, error) {
+				got = *req
+				return &api.Void{}, nil
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items?"+tt.query, http.NoBody))
+
+			require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDeepObject_errors(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *listReq) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	tests := map[string]struct {
+		query      string
+		wantStatus int
+		want       string
+	}{
+		"unparsable value": {
+			query:      url.Values{"filter[price][min]": {"cheap"}}.Encode(),
+			wantStatus: http.StatusBadRequest,
+			want:       "filter[price][min]",
+		},
+		"constraint violation": {
+			query:      url.Values{"filter[price][min]": {"-1"}}.Encode(),
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items?"+tt.query, http.NoBody))
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			var pd api.ProblemDetails
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pd))
+			assert.Contains(t, pd.Detail, tt.want)
+		})
+	}
+}
+
+func TestDeepObject_spec(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *listReq) (*api.Void, error) { return nil, nil })
+
+	params := r.Spec().Paths["/items"]["get"].Parameters
+	require.Len(t, params, 2)
+
+	filter := params[0]
+	if filter.Name != "filter" {
+		filter = params[1]
+	}
+	assert.Equal(t, "query", filter.In)
+	assert.Equal(t, "deepObject", filter.Style)
+	require.NotNil(t, filter.Explode)
+	assert.True(t, *filter.Explode)
+	assert.Equal(t, "object", filter.Schema.Type)
+	assert.Equal(t, "Lifecycle state.", filter.Schema.Properties["status"].Description)
+	assert.Equal(t, "array", filter.Schema.Properties["tags"].Type)
+	price := filter.Schema.Properties["price"]
+	assert.Equal(t, "object", price.Type)
+	require.NotNil(t, price.Properties["min"].Minimum)
+	assert.InDelta(t, 0, *price.Properties["min"].Minimum, 0)
+}
+
+func TestDeepObject_unsupported_field(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Filter struct {
+			N complex64 `query:"n"`
+		} `query:"filter"`
+	}
+
+	err := registrationPanic(t, func() {
+		api.Get(api.New(), "/items", func(_ context.Context, _ *Req) (*api.Void, error) { return nil, nil })
+	})
+	require.Len(t, err.Problems, 1)
+	assert.Contains(t, err.Problems[0].Message, `query param "filter[n]" has unsupported type complex64`)
+}
+
+func TestDeepObject_alias(t *testing.T) {
+	t.Parallel()
+
+	type req struct {
+		Filter priceRange `query:"filter,alias=where"`
+	}
+
+	var got priceRange
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, req *req) (*api.Void, error) {
+		got = req.Filter
+		return &api.Void{}, nil
+	})
+
+	tests := map[string]struct {
+		query    string
+		wantCode int
+		want     priceRange
+	}{
+		"current name": {query: "filter[min]=1", wantCode: http.StatusNoContent, want: priceRange{Min: 1}},
+		"alias":        {query: "where[max]=2", wantCode: http.StatusNoContent, want: priceRange{Max: 2}},
+		"dots":         {query: "filter.max=3", wantCode: http.StatusNoContent, want: priceRange{Max: 3}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items?"+tt.query, http.NoBody))
+
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode == http.StatusNoContent {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDeepObject_required(t *testing.T) {
+	t.Parallel()
+
+	type req struct {
+		Filter priceRange `query:"filter,alias=where" required:"true"`
+	}
+
+	r := api.New(api.WithRequiredParams())
+	api.Get(r, "/items", func(_ context.Context, _ *req) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	tests := map[string]struct {
+		query    string
+		wantCode int
+	}{
+		"brackets": {query: "filter[min]=1", wantCode: http.StatusNoContent},
+		"dots":     {query: "filter.max=3", wantCode: http.StatusNoContent},
+		"alias":    {query: "where[max]=2", wantCode: http.StatusNoContent},
+		"missing":  {query: "other[min]=1", wantCode: http.StatusBadRequest},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items?"+tt.query, http.NoBody))
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+		})
+	}
+}
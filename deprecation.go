@@ -0,0 +1,222 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// UsageSource reports how often an operation was called recently, for
+// DeprecationReport. Back it with whatever metrics system already counts
+// requests per route; what "recently" means is up to the source.
+type UsageSource interface {
+	Calls(ctx context.Context, route RouteDescription) (int64, error)
+}
+
+// UsageSourceFunc adapts a function to the UsageSource interface.
+type UsageSourceFunc func(ctx context.Context, route RouteDescription) (int64, error)
+
+// Calls implements UsageSource.
+func (f UsageSourceFunc) Calls(ctx context.Context, route RouteDescription) (int64, error) {
+	return f(ctx, route)
+}
+
+// DeprecationReport lists the operations that are deprecated or accept
+// deprecated fields, with their recent call counts.
+type DeprecationReport struct {
+	GeneratedAt time.Time             `json:"generatedAt"`
+	Operations  []DeprecatedOperation `json:"operations"`
+}
+
+// DeprecatedOperation is one entry in a DeprecationReport.
+type DeprecatedOperation struct {
+	Method      string `json:"method"`
+	Pattern     string `json:"pattern"`
+	OperationID string `json:"operationId"`
+
+	// Deprecated is true when the whole operation is deprecated; false
+	// when only some of its fields are.
+	Deprecated bool `json:"deprecated"`
+
+	// Fields lists the deprecated request fields the operation accepts.
+	Fields []DeprecatedField `json:"fields,omitempty"`
+
+	// Calls is the recent call count from the UsageSource, or nil when no
+	// source was given or it failed for this operation.
+	Calls *int64 `json:"calls,omitempty"`
+}
+
+// DeprecatedField is a request field tagged `deprecated:"true"`.
+type DeprecatedField struct {
+	// In is the field's location: path, query, header, cookie, or body.
+	In string `json:"in"`
+
+	// Name is the parameter name, or the dotted JSON path within the body.
+	Name string `json:"name"`
+}
+
+// DeprecationReport collects deprecated operations and fields. When usage
+// is non-nil each entry carries its call count; an operation whose count
+// cannot be read is reported without one rather than failing the report.
+func (r *Router) DeprecationReport(ctx context.Context, usage UsageSource) DeprecationReport {
+	r.mu.Lock()
+	var ops []DeprecatedOperation
+	var descs []RouteDescription
+	for i := range r.routes {
+		ri := &r.routes[i]
+		fields := deprecatedFields(ri)
+		if !ri.deprecated && len(fields) == 0 {
+			continue
+		}
+		d := r.describeRoute(ri)
+		descs = append(descs, d)
+		ops = append(ops, DeprecatedOperation{
+			Method:      d.Method,
+			Pattern:     d.Pattern,
+			OperationID: d.OperationID,
+			Deprecated:  ri.deprecated,
+			Fields:      fields,
+		})
+	}
+	r.mu.Unlock()
+
+	if usage != nil {
+		for i, d := range descs {
+			if n, err := usage.Calls(ctx, d); err == nil {
+				ops[i].Calls = &n
+			}
+		}
+	}
+
+	if ops == nil {
+		ops = []DeprecatedOperation{}
+	}
+	return DeprecationReport{GeneratedAt: time.Now().UTC(), Operations: ops}
+}
+
+// ServeDeprecationReport registers a GET handler at the given path that
+// serves DeprecationReport as JSON. Like ServeSpec, the endpoint is not
+// part of the spec; protect it with middleware when exposed publicly.
+func (r *Router) ServeDeprecationReport(pattern string, usage UsageSource) {
+	r.handle("GET "+pattern, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.DeprecationReport(req.Context(), usage)
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck,gosec // best-effort after WriteHeader
+		json.NewEncoder(w).Encode(report)
+	}))
+}
+
+// deprecatedFields lists the route's request parameters and body fields
+// tagged `deprecated:"true"`.
+func deprecatedFields(ri *routeInfo) []DeprecatedField {
+	if ri.reqType == nil || ri.reqType.Kind() != reflect.Struct {
+		return nil
+	}
+	var out []DeprecatedField
+	for _, f := range reflect.VisibleFields(ri.reqType) {
+		if !f.IsExported() {
+			continue
+		}
+		for _, tag := range paramTags {
+			name, opts := tagOptions(f.Tag.Get(tag))
+			if name == "" {
+				continue
+			}
+			if f.Tag.Get("deprecated") == "true" {
+				out = append(out, DeprecatedField{In: tagToIn(tag), Name: name})
+			}
+			if tag == "query" {
+				for _, alias := range tagValues(opts, "alias") {
+					out = append(out, DeprecatedField{In: "query", Name: alias})
+				}
+			}
+		}
+	}
+	if body := requestBodyType(ri); body != nil {
+		collectDeprecatedBodyFields(body, "", map[reflect.Type]bool{}, &out)
+	}
+	return out
+}
+
+func collectDeprecatedBodyFields(t reflect.Type, prefix string, visited map[reflect.Type]bool, out *[]DeprecatedField) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visited[t] {
+		return
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous && f.Tag.Get("json") == "" || isParamField(f) {
+			continue
+		}
+		name := jsonFieldName(f)
+		if name == "-" {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		if f.Tag.Get("deprecated") == "true" {
+			*out = append(*out, DeprecatedField{In: "body", Name: path})
+		}
+		collectDeprecatedBodyFields(f.Type, path, visited, out)
+	}
+}
+
+// CallCounter is an in-memory UsageSource that counts requests per route
+// since it was created or last reset. Install it with WithCallCounter when
+// no external metrics system is available:
+//
+//	calls := api.NewCallCounter()
+//	r := api.New(api.WithCallCounter(calls))
+//	r.ServeDeprecationReport("/admin/deprecations", calls)
+type CallCounter struct {
+	counts sync.Map // "METHOD pattern" → *atomic.Int64
+}
+
+// NewCallCounter returns an empty CallCounter.
+func NewCallCounter() *CallCounter {
+	return &CallCounter{}
+}
+
+// WithCallCounter counts every request to every route in c.
+func WithCallCounter(c *CallCounter) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.callCounter = c
+	})
+}
+
+// wrap counts requests to h under key.
+func (c *CallCounter) wrap(key string, h http.Handler) http.Handler {
+	v, _ := c.counts.LoadOrStore(key, new(atomic.Int64))
+	n := v.(*atomic.Int64) //nolint:errcheck,forcetypeassert // map holds *atomic.Int64 only
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.Add(1)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Calls implements UsageSource.
+func (c *CallCounter) Calls(_ context.Context, route RouteDescription) (int64, error) {
+	v, ok := c.counts.Load(route.Method + " " + route.Host + route.Pattern)
+	if !ok {
+		return 0, nil
+	}
+	return v.(*atomic.Int64).Load(), nil //nolint:errcheck,forcetypeassert // map holds *atomic.Int64 only
+}
+
+// Reset zeroes every count, starting a new observation window.
+func (c *CallCounter) Reset() {
+	c.counts.Range(func(_, v any) bool {
+		v.(*atomic.Int64).Store(0) //nolint:errcheck,forcetypeassert // map holds *atomic.Int64 only
+		return true
+	})
+}
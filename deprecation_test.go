@@ -0,0 +1,121 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type dpAddress struct {
+	Street string `json:"street"`
+	Line2  string `json:"line2" deprecated:"true"`
+}
+
+type dpUpdateReq struct {
+	ID     string `path:"id"`
+	Legacy string `query:"legacy" deprecated:"true"`
+	Limit  int    `query:"limit,alias=max"`
+	Body   struct {
+		Name     string     `json:"name"`
+		Nickname string     `json:"nickname" deprecated:"true"`
+		Address  *dpAddress `json:"address"`
+	}
+}
+
+func newDeprecationRouter(opts ...api.RouterOption) *api.Router {
+	r := api.New(opts...)
+	api.Get(r, "/users", voidHandler)
+	api.Get(r, "/v1/users", voidHandler, api.WithDeprecated(), api.WithOperationID("listUsersV1"))
+	api.Put(r, "/users/{id}", func(_ context.Context, _ *dpUpdateReq) (*api.Void, error) {
+		return &api.Void{}, nil
+	}, api.WithOperationID("updateUser"))
+	return r
+}
+
+func TestDeprecationReport(t *testing.T) {
+	t.Parallel()
+
+	calls := api.NewCallCounter()
+	r := newDeprecationRouter(api.WithCallCounter(calls))
+	for range 3 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/users", nil))
+	}
+
+	report := r.DeprecationReport(context.Background(), calls)
+	require.Len(t, report.Operations, 2)
+
+	v1 := report.Operations[0]
+	assert.Equal(t, "listUsersV1", v1.OperationID)
+	assert.True(t, v1.Deprecated)
+	assert.Empty(t, v1.Fields)
+	require.NotNil(t, v1.Calls)
+	assert.Equal(t, int64(3), *v1.Calls)
+
+	update := report.Operations[1]
+	assert.Equal(t, "updateUser", update.OperationID)
+	assert.False(t, update.Deprecated)
+	assert.Equal(t, []api.DeprecatedField{
+		{In: "query", Name: "legacy"},
+		{In: "query", Name: "max"},
+		{In: "body", Name: "nickname"},
+		{In: "body", Name: "address.line2"},
+	}, update.Fields)
+	require.NotNil(t, update.Calls)
+	assert.Equal(t, int64(0), *update.Calls)
+
+	calls.Reset()
+	report = r.DeprecationReport(context.Background(), calls)
+	assert.Equal(t, int64(0), *report.Operations[0].Calls)
+
+	body := r.Spec().Paths["/users/{id}"]["put"].RequestBody.Content["application/json"].Schema
+	require.NotNil(t, body)
+	assert.True(t, body.Properties["nickname"].Deprecated, "body schema documents the deprecated field")
+}
+
+func TestDeprecationReport_usage_source(t *testing.T) {
+	t.Parallel()
+
+	r := newDeprecationRouter()
+	usage := api.UsageSourceFunc(func(_ context.Context, d api.RouteDescription) (int64, error) {
+		if d.OperationID == "updateUser" {
+			return 0, errors.New("metrics unavailable")
+		}
+		return 42, nil
+	})
+
+	report := r.DeprecationReport(context.Background(), usage)
+	require.Len(t, report.Operations, 2)
+	require.NotNil(t, report.Operations[0].Calls)
+	assert.Equal(t, int64(42), *report.Operations[0].Calls)
+	assert.Nil(t, report.Operations[1].Calls)
+
+	report = api.New().DeprecationReport(context.Background(), nil)
+	assert.NotNil(t, report.Operations)
+	assert.Empty(t, report.Operations)
+}
+
+func TestServeDeprecationReport(t *testing.T) {
+	t.Parallel()
+
+	r := newDeprecationRouter()
+	r.ServeDeprecationReport("/admin/deprecations", nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/deprecations", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var report api.DeprecationReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Len(t, report.Operations, 2)
+	assert.Nil(t, report.Operations[0].Calls)
+	assert.NotContains(t, r.Spec().Paths, "/admin/deprecations")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"regexp"
 )
 
 // responseDescriptor is a precomputed map of a response struct's tagged
@@ -50,6 +51,9 @@ type responseBodyDesc struct {
 	// typ is the field's static type. Used for sanity checks and OpenAPI
 	// schema generation; the encoder itself consults kind.
 	typ reflect.Type
+	// oneOf is true when the body may hold a OneOf, which encodes as its
+	// member: a OneOf type or an interface.
+	oneOf bool
 }
 
 // bodyKind identifies how the framework emits the value stored in the
@@ -58,9 +62,14 @@ type responseBodyDesc struct {
 type bodyKind int
 
 const (
-	bodyKindCodec  bodyKind = iota // encode via negotiated codec (JSON/XML/...)
-	bodyKindReader                 // io.Copy raw bytes
-	bodyKindChan                   // emit each channel value as an SSE event
+	bodyKindCodec     bodyKind = iota // encode via negotiated codec (JSON/XML/...)
+	bodyKindReader                    // io.Copy raw bytes
+	bodyKindChan                      // emit each channel value as an SSE event
+	bodyKindJSONArray                 // emit each channel value as a JSON array element
+	bodyKindNDJSON                    // emit each channel value as an NDJSON line
+	bodyKindSeq                       // emit each iterator value as a JSON array element or NDJSON line
+	bodyKindEventSeq                  // emit each iterator value as an SSE event
+	bodyKindFile                      // serve a File with http.ServeContent
 )
 
 var (
@@ -105,6 +114,7 @@ func buildResponseDescriptor(t reflect.Type) (*responseDescriptor, error) {
 				index: f.Index,
 				kind:  classifyBodyKind(f.Type),
 				typ:   f.Type,
+				oneOf: f.Type.Implements(oneOfType) || f.Type.Kind() == reflect.Interface,
 			}
 			continue
 		}
@@ -176,6 +186,11 @@ type requestDescriptor struct {
 	body       *requestFieldDesc  // nil if no Body field
 	params     []requestParamDesc // path/query/header/cookie bindings
 	forms      []requestFormDesc  // multipart form bindings
+	timeRanges []timeRangeDesc    // embedded TimeRanges, resolved after binding
+
+	// streamContent is the documented media type of a StreamBody Body
+	// field; empty when the body is decoded by a codec.
+	streamContent string
 }
 
 // requestFieldDesc locates a field by its reflect.VisibleFields index path.
@@ -192,13 +207,57 @@ const (
 	paramInQuery
 	paramInHeader
 	paramInCookie
+	paramInClaim   // authenticated principal's claims
+	paramInSession // values of the Session in the request context
 )
 
+// String returns the struct tag that selects the source.
+func (p paramIn) String() string {
+	for tag, in := range requestParamTagIn {
+		if in == p {
+			return tag
+		}
+	}
+	return "unknown"
+}
+
 type requestParamDesc struct {
 	requestFieldDesc
 	in           paramIn
 	name         string
 	defaultValue string
+
+	// aliases are former names of a query param, still accepted when the
+	// current name is absent (tag option `query:"name,alias=old"`).
+	aliases []string
+
+	// secure marks cookie params whose value is decoded via the router's
+	// SecureCookies (tag option `cookie:"name,secure"`).
+	secure bool
+
+	// cookieParam is true when the field is a CookieParam[T].
+	cookieParam bool
+
+	// cursor is true when the field is a Cursor[T].
+	cursor bool
+
+	// required rejects a claim or session param with 401 when the value
+	// is absent (tag `required:"true"`).
+	required bool
+
+	// multi marks slice-typed query params, bound from every occurrence.
+	// explode is false when the tag `explode:"false"` selects
+	// comma-separated values instead of repeated keys.
+	multi   bool
+	explode bool
+
+	// deep marks struct-typed query params, bound as a deep object from
+	// name[field] keys.
+	deep bool
+
+	// pattern is the compiled pattern tag of a path param, which its raw
+	// value must match before it is parsed.
+	pattern *regexp.Regexp
 }
 
 // formFieldKind identifies how a form field is bound at request time.
@@ -208,24 +267,31 @@ const (
 	formScalar formFieldKind = iota
 	formSingleFile
 	formMultiFile
+	formCodecPart // part decoded by the codec registered for contentType
 )
 
 type requestFormDesc struct {
 	requestFieldDesc
 	name string
 	kind formFieldKind
+	// contentType is the media type declared via the `content` tag for
+	// formCodecPart fields.
+	contentType string
 }
 
 var (
 	rawRequestType    = reflect.TypeFor[RawRequest]()
 	fileUploadType    = reflect.TypeFor[FileUpload]()
 	fileUploadSlice   = reflect.TypeFor[[]FileUpload]()
+	streamBodyType    = reflect.TypeFor[StreamBody]()
 	voidRequestType   = reflect.TypeFor[Void]()
 	requestParamTagIn = map[string]paramIn{
-		"path":   paramInPath,
-		"query":  paramInQuery,
-		"header": paramInHeader,
-		"cookie": paramInCookie,
+		"path":    paramInPath,
+		"query":   paramInQuery,
+		"header":  paramInHeader,
+		"cookie":  paramInCookie,
+		"claim":   paramInClaim,
+		"session": paramInSession,
 	}
 )
 
@@ -255,6 +321,13 @@ func buildRequestDescriptor(t reflect.Type) (*requestDescriptor, error) {
 		// appear separately in VisibleFields), unless the type IS RawRequest,
 		// which we set as a whole value.
 		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Type != rawRequestType {
+			if f.Type == timeRangeType {
+				tr, err := newTimeRangeDesc(f)
+				if err != nil {
+					return nil, fmt.Errorf("%w (field %s in %s)", err, f.Name, t)
+				}
+				desc.timeRanges = append(desc.timeRanges, tr)
+			}
 			continue
 		}
 
@@ -271,28 +344,65 @@ func buildRequestDescriptor(t reflect.Type) (*requestDescriptor, error) {
 				return nil, fmt.Errorf("multiple Body fields in request type %s", t)
 			}
 			desc.body = &requestFieldDesc{index: f.Index, typ: f.Type}
+			if f.Type == streamBodyType {
+				desc.streamContent = f.Tag.Get("content")
+				if desc.streamContent == "" {
+					desc.streamContent = defaultStreamContentType
+				}
+			}
 			continue
 		}
 
 		fd := requestFieldDesc{index: f.Index, typ: f.Type}
 
 		for tagName, in := range requestParamTagIn {
-			name := f.Tag.Get(tagName)
+			name, opts := tagOptions(f.Tag.Get(tagName))
 			if name == "" {
 				continue
 			}
+			secure := tagContains(opts, "secure")
+			if secure && in != paramInCookie {
+				return nil, fmt.Errorf("secure option is only valid on cookie params (field %s in %s)", f.Name, t)
+			}
+			_, isCookieParam := asCookieBinder(f.Type)
+			if isCookieParam && in != paramInCookie {
+				return nil, fmt.Errorf("CookieParam field %s in %s must use the cookie tag", f.Name, t)
+			}
+			aliases := tagValues(opts, "alias")
+			if len(aliases) > 0 && in != paramInQuery {
+				return nil, fmt.Errorf("alias option is only valid on query params (field %s in %s)", f.Name, t)
+			}
 			if seenParam[in] == nil {
 				seenParam[in] = map[string]struct{}{}
 			}
-			if _, dup := seenParam[in][name]; dup {
-				return nil, fmt.Errorf("duplicate %s param %q in request type %s", tagName, name, t)
+			for _, n := range append([]string{name}, aliases...) {
+				if _, dup := seenParam[in][n]; dup {
+					return nil, fmt.Errorf("duplicate %s param %q in request type %s", tagName, n, t)
+				}
+				seenParam[in][n] = struct{}{}
+			}
+			var pattern *regexp.Regexp
+			if tag := f.Tag.Get("pattern"); tag != "" && in == paramInPath {
+				re, err := regexp.Compile(tag)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pattern tag on path param %q in %s: %w", name, t, err)
+				}
+				pattern = re
 			}
-			seenParam[in][name] = struct{}{}
 			desc.params = append(desc.params, requestParamDesc{
 				requestFieldDesc: fd,
 				in:               in,
 				name:             name,
+				aliases:          aliases,
 				defaultValue:     f.Tag.Get("default"),
+				secure:           secure,
+				cookieParam:      isCookieParam,
+				cursor:           isCursorType(f.Type),
+				required:         f.Tag.Get("required") == "true",
+				multi:            in == paramInQuery && isMultiValueType(f.Type),
+				deep:             in == paramInQuery && isDeepObjectType(f.Type),
+				pattern:          pattern,
+				explode:          f.Tag.Get("explode") != "false",
 			})
 		}
 
@@ -308,10 +418,18 @@ func buildRequestDescriptor(t reflect.Type) (*requestDescriptor, error) {
 			case fileUploadSlice:
 				kind = formMultiFile
 			}
+			contentType := f.Tag.Get("content")
+			if contentType != "" {
+				if kind != formScalar {
+					return nil, fmt.Errorf("content tag not allowed on file field %s in %s", f.Name, t)
+				}
+				kind = formCodecPart
+			}
 			desc.forms = append(desc.forms, requestFormDesc{
 				requestFieldDesc: fd,
 				name:             name,
 				kind:             kind,
+				contentType:      contentType,
 			})
 		}
 	}
@@ -330,13 +448,49 @@ func buildRequestDescriptor(t reflect.Type) (*requestDescriptor, error) {
 	return desc, nil
 }
 
+// usesSecureCookies reports whether any cookie param requires the router's
+// SecureCookies codec.
+func (d *requestDescriptor) usesSecureCookies() bool {
+	for _, p := range d.params {
+		if p.secure {
+			return true
+		}
+	}
+	return false
+}
+
+// requiresContext reports whether any claim or session param is required.
+func (d *requestDescriptor) requiresContext() bool {
+	for _, p := range d.params {
+		if p.required && (p.in == paramInClaim || p.in == paramInSession) {
+			return true
+		}
+	}
+	return false
+}
+
 // classifyBodyKind picks the emission path for a Body field based on its
 // static type. The field's declared type wins: a field typed io.Reader
 // streams even if the concrete value also satisfies some other interface.
 func classifyBodyKind(t reflect.Type) bodyKind {
+	if isJSONArrayStreamType(t) {
+		return bodyKindJSONArray
+	}
+	if isNDJSONStreamType(t) {
+		return bodyKindNDJSON
+	}
+	if elem, _, ok := seqElem(t); ok {
+		if elem == eventType {
+			return bodyKindEventSeq
+		}
+		return bodyKindSeq
+	}
 	if t.Kind() == reflect.Interface && t == readerInterfaceType {
 		return bodyKindReader
 	}
+	if t == fileType {
+		return bodyKindFile
+	}
 	if t.Kind() == reflect.Chan && t.Elem() == eventType {
 		dir := t.ChanDir()
 		if dir == reflect.RecvDir || dir == reflect.BothDir {
@@ -11,8 +11,29 @@ type DocsOption func(*docsConfig)
 type docsConfig struct {
 	title   string
 	specURL string
+	ui      DocsUI
+	assets  *DocsAssets
 }
 
+// DocsUI selects the documentation renderer used by ServeDocs. Each loads
+// an exact release of its assets from jsDelivr unless WithDocsAssets points
+// elsewhere.
+type DocsUI int
+
+const (
+	// DocsElements renders Stoplight Elements. This is the default.
+	DocsElements DocsUI = iota
+
+	// DocsSwaggerUI renders Swagger UI, with try-it-out requests.
+	DocsSwaggerUI
+
+	// DocsRedoc renders a read-only Redoc reference.
+	DocsRedoc
+
+	// DocsScalar renders the Scalar API reference.
+	DocsScalar
+)
+
 // WithDocsTitle sets the page title for the docs UI.
 func WithDocsTitle(title string) DocsOption {
 	return func(c *docsConfig) {
@@ -20,8 +41,72 @@ func WithDocsTitle(title string) DocsOption {
 	}
 }
 
+// WithDocsUI selects the documentation renderer. Defaults to DocsElements.
+func WithDocsUI(ui DocsUI) DocsOption {
+	return func(c *docsConfig) {
+		c.ui = ui
+	}
+}
+
+// DocsAssets locates the script and stylesheet a docs renderer loads.
+// Integrity values are Subresource Integrity hashes, such as
+// "sha384-…"; when set, browsers refuse assets that do not match them.
+// Stylesheet is only used by renderers that load one (DocsElements and
+// DocsSwaggerUI).
+type DocsAssets struct {
+	Script              string
+	ScriptIntegrity     string
+	Stylesheet          string
+	StylesheetIntegrity string
+}
+
+// defaultDocsAssets are the pinned CDN releases each renderer loads by
+// default.
+var defaultDocsAssets = map[DocsUI]DocsAssets{
+	DocsElements: {
+		Script:     "https://cdn.jsdelivr.net/npm/@stoplight/elements@8.0.0/web-components.min.js",
+		Stylesheet: "https://cdn.jsdelivr.net/npm/@stoplight/elements@8.0.0/styles.min.css",
+	},
+	DocsSwaggerUI: {
+		Script:     "https://cdn.jsdelivr.net/npm/swagger-ui-dist@5.17.14/swagger-ui-bundle.js",
+		Stylesheet: "https://cdn.jsdelivr.net/npm/swagger-ui-dist@5.17.14/swagger-ui.css",
+	},
+	DocsRedoc: {
+		Script: "https://cdn.jsdelivr.net/npm/redoc@2.1.5/bundles/redoc.standalone.js",
+	},
+	DocsScalar: {
+		Script: "https://cdn.jsdelivr.net/npm/@scalar/api-reference@1.25.0/dist/browser/standalone.js",
+	},
+}
+
+// WithDocsAssets loads the renderer's assets from a, instead of the
+// release ServeDocs pins, to serve them from the application itself or
+// to pin them with integrity hashes:
+//
+//	r.ServeDocs("/docs", api.WithDocsUI(api.DocsRedoc), api.WithDocsAssets(api.DocsAssets{
+//	    Script:          "https://cdn.jsdelivr.net/npm/redoc@2.1.5/bundles/redoc.standalone.js",
+//	    ScriptIntegrity: "sha384-…",
+//	}))
+func WithDocsAssets(a DocsAssets) DocsOption {
+	return func(c *docsConfig) {
+		c.assets = &a
+	}
+}
+
+// WithDocsSpecURL sets the spec URL the docs UI loads. Defaults to
+// "/openapi.json"; pair it with the pattern given to ServeSpec.
+func WithDocsSpecURL(url string) DocsOption {
+	return func(c *docsConfig) {
+		c.specURL = url
+	}
+}
+
 // ServeDocs serves an interactive API documentation UI at the given path.
-// It renders Stoplight Elements pointing at the router's OpenAPI spec.
+// It renders Stoplight Elements pointing at the router's OpenAPI spec
+// unless another renderer is chosen with WithDocsUI:
+//
+//	r.ServeSpec("/openapi.json")
+//	r.ServeDocs("/docs", api.WithDocsUI(api.DocsScalar))
 func (r *Router) ServeDocs(path string, opts ...DocsOption) {
 	cfg := &docsConfig{
 		title:   r.title,
@@ -31,23 +116,38 @@ func (r *Router) ServeDocs(path string, opts ...DocsOption) {
 		opt(cfg)
 	}
 
-	tmpl := template.Must(template.New("docs").Parse(docsHTML))
+	page, ok := docsPages[cfg.ui]
+	if !ok {
+		panic("api: ServeDocs: unknown DocsUI")
+	}
+	if cfg.assets == nil {
+		assets := defaultDocsAssets[cfg.ui]
+		cfg.assets = &assets
+	}
+	tmpl := template.Must(template.New("docs").Parse(page))
 
-	r.mux.HandleFunc("GET "+path, func(w http.ResponseWriter, _ *http.Request) {
+	r.handle("GET "+path, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		//nolint:errcheck,gosec // best-effort template render
 		tmpl.Execute(w, cfg)
-	})
+	}))
+}
+
+var docsPages = map[DocsUI]string{
+	DocsElements:  elementsHTML,
+	DocsSwaggerUI: swaggerUIHTML,
+	DocsRedoc:     redocHTML,
+	DocsScalar:    scalarHTML,
 }
 
-const docsHTML = `<!doctype html>
+const elementsHTML = `<!doctype html>
 <html lang="en">
 <head>
   <meta charset="utf-8">
   <meta name="viewport" content="width=device-width, initial-scale=1">
   <title>{{.Title}}</title>
-  <link rel="stylesheet" href="https://unpkg.com/@stoplight/elements/styles.min.css">
-  <script src="https://unpkg.com/@stoplight/elements/web-components.min.js"></script>
+  <link rel="stylesheet" href="{{.Assets.Stylesheet}}"{{with .Assets.StylesheetIntegrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}>
+  <script src="{{.Assets.Script}}"{{with .Assets.ScriptIntegrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}></script>
 </head>
 <body>
   <elements-api
@@ -58,8 +158,56 @@ const docsHTML = `<!doctype html>
 </body>
 </html>`
 
+const swaggerUIHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="{{.Assets.Stylesheet}}"{{with .Assets.StylesheetIntegrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}>
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="{{.Assets.Script}}"{{with .Assets.ScriptIntegrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>`
+
+const redocHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+</head>
+<body>
+  <redoc spec-url="{{.SpecURL}}"></redoc>
+  <script src="{{.Assets.Script}}"{{with .Assets.ScriptIntegrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}></script>
+</body>
+</html>`
+
+const scalarHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+</head>
+<body>
+  <script id="api-reference" data-url="{{.SpecURL}}"></script>
+  <script src="{{.Assets.Script}}"{{with .Assets.ScriptIntegrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}></script>
+</body>
+</html>`
+
 // Title returns the docs config title (used in the template).
 func (c *docsConfig) Title() string { return c.title }
 
 // SpecURL returns the docs config spec URL (used in the template).
 func (c *docsConfig) SpecURL() string { return c.specURL }
+
+// Assets returns the renderer's assets (used in the template).
+func (c *docsConfig) Assets() DocsAssets { return *c.assets }
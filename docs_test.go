@@ -89,3 +89,67 @@ func TestServeDocs_spec_url_rendered(t *testing.T) {
 
 	assert.Contains(t, string(body), `apiDescriptionUrl="/openapi.json"`)
 }
+
+func TestServeDocs_ui(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		ui   api.DocsUI
+		want []string
+	}{
+		"elements":   {ui: api.DocsElements, want: []string{"elements-api", `apiDescriptionUrl="/spec.json"`}},
+		"swagger ui": {ui: api.DocsSwaggerUI, want: []string{"SwaggerUIBundle", `url: "/spec.json"`}},
+		"redoc":      {ui: api.DocsRedoc, want: []string{"redoc@2.1.5/bundles/redoc.standalone.js", `spec-url="/spec.json"`}},
+		"scalar":     {ui: api.DocsScalar, want: []string{"@scalar/api-reference@1.25.0", `data-url="/spec.json"`}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New(api.WithTitle("UI Test"))
+			r.ServeDocs("/docs", api.WithDocsUI(tt.ui), api.WithDocsSpecURL("/spec.json"))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+			require.Equal(t, http.StatusOK, w.Code)
+
+			body := w.Body.String()
+			assert.Contains(t, body, "<title>UI Test</title>")
+			for _, s := range tt.want {
+				assert.Contains(t, body, s)
+			}
+		})
+	}
+
+	assert.Panics(t, func() { api.New().ServeDocs("/docs", api.WithDocsUI(api.DocsUI(99))) })
+}
+
+func TestServeDocs_assets(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	r.ServeDocs("/docs", api.WithDocsUI(api.DocsSwaggerUI), api.WithDocsAssets(api.DocsAssets{
+		Script:              "/static/swagger-ui-bundle.js",
+		ScriptIntegrity:     "sha384-script",
+		Stylesheet:          "/static/swagger-ui.css",
+		StylesheetIntegrity: "sha384-style",
+	}))
+	r.ServeDocs("/redoc", api.WithDocsUI(api.DocsRedoc))
+
+	get := func(path string) string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	body := get("/docs")
+	assert.Contains(t, body, `<script src="/static/swagger-ui-bundle.js" integrity="sha384-script" crossorigin="anonymous"></script>`)
+	assert.Contains(t, body, `<link rel="stylesheet" href="/static/swagger-ui.css" integrity="sha384-style" crossorigin="anonymous">`)
+	assert.NotContains(t, body, "jsdelivr")
+
+	body = get("/redoc")
+	assert.NotContains(t, body, "latest")
+	assert.NotContains(t, body, "integrity")
+}
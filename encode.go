@@ -6,11 +6,15 @@ import (
 	"errors"
 	"io"
 	"mime"
+	"slices"
 	"strconv"
 	"strings"
 )
 
-// Encoder encodes response values to a wire format.
+// Encoder encodes response values to a wire format. ContentType may carry
+// parameters, such as "application/json; charset=utf-8"; they are sent in
+// the Content-Type header, while negotiation and the spec use the bare
+// media type.
 type Encoder interface {
 	ContentType() string
 	Encode(w io.Writer, v any) error
@@ -22,36 +26,70 @@ type Decoder interface {
 	Decode(r io.Reader, v any) error
 }
 
-// jsonCodec implements both Encoder and Decoder for JSON.
-type jsonCodec struct{}
+// jsonCodec implements both Encoder and Decoder for JSON. mirror, when set,
+// applies the router's custom representations (time format, big numbers).
+type jsonCodec struct {
+	mirror *jsonMirror
+}
 
 func (jsonCodec) ContentType() string { return "application/json" }
 
-func (jsonCodec) Encode(w io.Writer, v any) error {
+func (c jsonCodec) Encode(w io.Writer, v any) error {
+	if c.mirror != nil {
+		return c.mirror.encode(w, v)
+	}
 	return json.NewEncoder(w).Encode(v)
 }
 
-func (jsonCodec) Decode(r io.Reader, v any) error {
-	err := json.NewDecoder(r).Decode(v)
+// marshal returns the encoding of v without the trailing newline Encode
+// writes, for values written one at a time into a stream.
+func (c jsonCodec) marshal(v any) ([]byte, error) {
+	if c.mirror != nil {
+		return c.mirror.marshal(v)
+	}
+	return json.Marshal(v)
+}
+
+func (c jsonCodec) Decode(r io.Reader, v any) error {
+	var err error
+	if c.mirror != nil {
+		err = c.mirror.decode(r, v)
+	} else {
+		err = json.NewDecoder(r).Decode(v)
+	}
 	if errors.Is(err, io.EOF) {
 		return nil
 	}
 	return err
 }
 
-// xmlCodec implements both Encoder and Decoder for XML.
-type xmlCodec struct{}
+// xmlCodec implements both Encoder and Decoder for XML. env, when set,
+// switches to the envelope mode configured by WithXMLEnvelope.
+type xmlCodec struct {
+	env *XMLEnvelope
+}
 
-func (xmlCodec) ContentType() string { return "application/xml" }
+func (c xmlCodec) ContentType() string {
+	if c.env != nil && c.env.ContentType != "" {
+		return c.env.ContentType
+	}
+	return "application/xml"
+}
 
-func (xmlCodec) Encode(w io.Writer, v any) error {
+func (c xmlCodec) Encode(w io.Writer, v any) error {
+	if c.env != nil {
+		return c.encodeEnvelope(w, v)
+	}
 	if _, err := io.WriteString(w, xml.Header); err != nil {
 		return err
 	}
 	return xml.NewEncoder(w).Encode(v)
 }
 
-func (xmlCodec) Decode(r io.Reader, v any) error {
+func (c xmlCodec) Decode(r io.Reader, v any) error {
+	if c.env != nil {
+		return c.decodeEnvelope(r, v)
+	}
 	err := xml.NewDecoder(r).Decode(v)
 	if errors.Is(err, io.EOF) {
 		return nil
@@ -64,22 +102,42 @@ func (xmlCodec) Decode(r io.Reader, v any) error {
 type codecRegistry struct {
 	encoders []Encoder
 	decoders []Decoder
+
+	// json is the JSON codec, which also encodes streamed elements.
+	json jsonCodec
+
+	// cache memoizes negotiate by Accept value; nil when disabled.
+	cache *negotiationCache
+
+	// protection hardens encoded bodies; nil unless WithContentProtection.
+	protection *ContentProtection
+
+	// charset is added to textual content types; "" unless
+	// WithDefaultCharset.
+	charset string
 }
 
-// newCodecRegistry builds a registry with JSON first, XML second, then any
-// user-registered encoders and decoders.
-func newCodecRegistry(userEncoders []Encoder, userDecoders []Decoder) *codecRegistry {
+// newCodecRegistry builds a registry with JSON (jc) first, XML (xc) second,
+// then any user-registered encoders and decoders.
+func newCodecRegistry(jc jsonCodec, xc xmlCodec, userEncoders []Encoder, userDecoders []Decoder) *codecRegistry {
 	cr := &codecRegistry{
 		encoders: make([]Encoder, 0, 2+len(userEncoders)),
 		decoders: make([]Decoder, 0, 2+len(userDecoders)),
+		json:     jc,
 	}
-	cr.encoders = append(cr.encoders, jsonCodec{}, xmlCodec{})
+	cr.encoders = append(cr.encoders, jc, xc)
 	cr.encoders = append(cr.encoders, userEncoders...)
-	cr.decoders = append(cr.decoders, jsonCodec{}, xmlCodec{})
+	cr.decoders = append(cr.decoders, jc, xc)
 	cr.decoders = append(cr.decoders, userDecoders...)
 	return cr
 }
 
+// marshalJSON encodes one element of a JSON array, NDJSON, or event
+// stream, with the router's JSON settings such as WithTimeFormat.
+func (cr *codecRegistry) marshalJSON(v any) ([]byte, error) {
+	return cr.json.marshal(v)
+}
+
 // negotiate picks an encoder based on the Accept header value.
 // Returns (JSON, true) for empty or */* accept values.
 // Returns (nil, false) if an explicit Accept has no match.
@@ -87,6 +145,15 @@ func (cr *codecRegistry) negotiate(accept string) (Encoder, bool) {
 	if accept == "" {
 		return cr.encoders[0], true
 	}
+	if cr.cache != nil {
+		return cr.cache.get(accept, cr.negotiateAccept)
+	}
+	return cr.negotiateAccept(accept)
+}
+
+// negotiateAccept parses a non-empty Accept value and picks the encoder
+// with the highest quality.
+func (cr *codecRegistry) negotiateAccept(accept string) (Encoder, bool) {
 
 	type candidate struct {
 		encoder Encoder
@@ -119,7 +186,7 @@ func (cr *codecRegistry) negotiate(accept string) (Encoder, bool) {
 		}
 
 		for _, enc := range cr.encoders {
-			if enc.ContentType() == mediaType {
+			if mediaTypeOf(enc.ContentType()) == mediaType {
 				best = candidate{encoder: enc, quality: q}
 				break
 			}
@@ -146,13 +213,42 @@ func (cr *codecRegistry) decoderFor(contentType string) (Decoder, bool) {
 	}
 
 	for _, dec := range cr.decoders {
-		if dec.ContentType() == mediaType {
+		if mediaTypeOf(dec.ContentType()) == mediaType {
 			return dec, true
 		}
 	}
 	return nil, false
 }
 
+// WithResponseEncoder offers enc for this route's responses, in addition
+// to the router's encoders, for formats that only make sense on some
+// routes, such as a CSV export of a list endpoint:
+//
+//	api.Get(r, "/orders", h.List, api.WithResponseEncoder(csv.Encoder{}))
+//
+// The route negotiates enc from Accept like a router encoder, for its
+// error responses as well, and documents its media type on its responses
+// only. Request bodies are unaffected.
+func WithResponseEncoder(enc Encoder) RouteOption {
+	return RouteOptionFunc(func(ri *routeInfo) {
+		ri.encoders = append(ri.encoders, enc)
+	})
+}
+
+// withEncoders returns a copy of cr that also negotiates encs, after its
+// own encoders, or cr itself when encs is empty.
+func (cr *codecRegistry) withEncoders(encs []Encoder) *codecRegistry {
+	if len(encs) == 0 {
+		return cr
+	}
+	c := *cr
+	c.encoders = append(slices.Clip(cr.encoders), encs...)
+	if cr.cache != nil {
+		c.cache = newNegotiationCache(cr.cache.size)
+	}
+	return &c
+}
+
 // contentTypes returns all encoder content types (for OpenAPI).
 // defaultEncoder returns the router's primary encoder, used as a
 // fallback when codec negotiation fails (e.g., unsupported Accept on
@@ -164,7 +260,34 @@ func (cr *codecRegistry) defaultEncoder() Encoder {
 func (cr *codecRegistry) contentTypes() []string {
 	cts := make([]string, len(cr.encoders))
 	for i, enc := range cr.encoders {
-		cts[i] = enc.ContentType()
+		cts[i] = mediaTypeOf(enc.ContentType())
 	}
 	return cts
 }
+
+// contentType returns the Content-Type header for ct, adding the default
+// charset to a textual media type that has none.
+func (cr *codecRegistry) contentType(ct string) string {
+	if cr.charset == "" {
+		return ct
+	}
+	mt, params, err := mime.ParseMediaType(ct)
+	if err != nil || params["charset"] != "" || !isTextualMediaType(mt) {
+		return ct
+	}
+	params["charset"] = cr.charset
+	return mime.FormatMediaType(mt, params)
+}
+
+// mediaTypeOf returns the lowercased media type of ct without parameters.
+func mediaTypeOf(ct string) string {
+	mt, _, _ := strings.Cut(ct, ";")
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+// isTextualMediaType reports whether a charset applies to mt.
+func isTextualMediaType(mt string) bool {
+	return strings.HasPrefix(mt, "text/") ||
+		mt == "application/json" || mt == "application/xml" ||
+		strings.HasSuffix(mt, "+json") || strings.HasSuffix(mt, "+xml")
+}
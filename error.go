@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strconv"
+	"time"
 )
 
 // ErrorInfo is the narrow read-only view of an API error exposed to body
@@ -44,6 +46,11 @@ type Err struct {
 	cookies         map[string]Cookie
 	body            bodyMapper
 	cause           error
+	typeURI         string // ProblemDetails type; see WithProblemType
+	title           string // ProblemDetails title; see WithProblemTitle
+	retryable       *bool  // see WithRetryable and WithRetryAfter
+	retryAfter      time.Duration
+	titles          map[string]string
 	documentedCodes []Code // populated by WithErrors when used at scope level
 }
 
@@ -82,6 +89,12 @@ func (e *Err) Details() []any { return e.details }
 // Instance with the request's URI.
 func (e *Err) Instance() string { return "" }
 
+// problem returns the ProblemDetails type and title overrides, if any.
+func (e *Err) problem() (typeURI, title string) { return e.typeURI, e.title }
+
+// retry returns the retry guidance set by WithRetryable or WithRetryAfter.
+func (e *Err) retry() (retryable *bool, after time.Duration) { return e.retryable, e.retryAfter }
+
 // Unwrap exposes a wrapped cause for errors.Is / errors.As chains.
 func (e *Err) Unwrap() error { return e.cause }
 
@@ -147,6 +160,42 @@ func WithCause(cause error) ErrorOption {
 	return errOptFunc(func(e *Err) { e.cause = cause })
 }
 
+// WithProblemType sets the problem type URI reported in the
+// ProblemDetails body, in place of "about:blank".
+func WithProblemType(uri string) ErrorOption {
+	return errOptFunc(func(e *Err) { e.typeURI = uri })
+}
+
+// WithProblemTitle sets the problem title reported in the ProblemDetails
+// body, in place of the status text.
+func WithProblemTitle(title string) ErrorOption {
+	return errOptFunc(func(e *Err) { e.title = title })
+}
+
+// WithRetryable reports in the ProblemDetails body whether the client
+// may retry the request unchanged, as the retryable extension member.
+func WithRetryable(retryable bool) ErrorOption {
+	return errOptFunc(func(e *Err) { e.retryable = &retryable })
+}
+
+// WithRetryAfter marks the error retryable after d, typically with
+// CodeServiceUnavailable, CodeTooManyRequests, or CodeConflict. It sets the
+// Retry-After header and the retryable and retryAfter members of the
+// ProblemDetails body, in whole seconds rounded up.
+//
+//	return nil, api.Error(api.CodeServiceUnavailable, api.WithRetryAfter(30*time.Second))
+func WithRetryAfter(d time.Duration) ErrorOption {
+	secs := int((d + time.Second - 1) / time.Second)
+	return errOptFunc(func(e *Err) {
+		e.retryable = new(true)
+		e.retryAfter = time.Duration(secs) * time.Second
+		if e.headers == nil {
+			e.headers = make(http.Header)
+		}
+		e.headers.Set("Retry-After", strconv.Itoa(secs))
+	})
+}
+
 // WithErrors declares which Codes a route may return. Used for OpenAPI
 // documentation only; has no runtime effect. Declarations accumulate
 // across scopes.
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -397,3 +398,62 @@ func TestError_plainErrorWrappedAsInternal(t *testing.T) {
 type plainStringError string
 
 func (e plainStringError) Error() string { return string(e) }
+
+func TestWithRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		scope          []api.ErrorOption
+		inline         []api.ErrorOption
+		wantHeader     string
+		wantRetryable  any
+		wantRetryAfter any
+	}{
+		"no guidance": {},
+		"retry after rounds up to seconds": {
+			inline:         []api.ErrorOption{api.WithRetryAfter(1500 * time.Millisecond)},
+			wantHeader:     "2",
+			wantRetryable:  true,
+			wantRetryAfter: float64(2),
+		},
+		"not retryable": {
+			inline:        []api.ErrorOption{api.WithRetryable(false)},
+			wantRetryable: false,
+		},
+		"scope guidance applies": {
+			scope:          []api.ErrorOption{api.WithRetryAfter(time.Minute)},
+			wantHeader:     "60",
+			wantRetryable:  true,
+			wantRetryAfter: float64(60),
+		},
+		"inline overrides scope": {
+			scope:      []api.ErrorOption{api.WithRetryAfter(time.Minute)},
+			inline:     []api.ErrorOption{api.WithRetryable(false)},
+			wantHeader: "60",
+			// The scope's header still applies; the body follows the
+			// inline guidance.
+			wantRetryable: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New(api.WithError(tc.scope...))
+			api.Get(r, "/fail", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+				return nil, api.Error(api.CodeServiceUnavailable, tc.inline...)
+			})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", http.NoBody))
+
+			assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+			assert.Equal(t, tc.wantHeader, rec.Header().Get("Retry-After"))
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tc.wantRetryable, body["retryable"])
+			assert.Equal(t, tc.wantRetryAfter, body["retryAfter"])
+		})
+	}
+}
@@ -13,6 +13,9 @@ var (
 	ErrBindCookie = errors.New("bind cookie")
 	ErrBindBody   = errors.New("bind body")
 	ErrBindForm   = errors.New("bind form")
+
+	ErrBindClaim   = errors.New("bind claim")
+	ErrBindSession = errors.New("bind session")
 )
 
 // StatusCoder is implemented by errors that carry an HTTP status code.
@@ -26,7 +26,7 @@ func ETag(cfg ...ETagConfig) Middleware {
 		c = cfg[0]
 	}
 
-	return func(next http.Handler) http.Handler {
+	return Named("etag", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Only apply to GET/HEAD.
 			if r.Method != http.MethodGet && r.Method != http.MethodHead {
@@ -79,7 +79,7 @@ func ETag(cfg ...ETagConfig) Middleware {
 			//nolint:errcheck,gosec // best-effort write
 			w.Write(buf.Bytes())
 		})
-	}
+	})
 }
 
 type etagRecorder struct {
@@ -1,10 +1,13 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -14,6 +17,14 @@ import (
 //
 // Only populated fields are emitted. A zero-value Event produces a blank
 // separator, which clients ignore.
+//
+// A reconnecting client sends the ID of the last event it received in the
+// Last-Event-ID header; bind it with a header tag to resume the stream,
+// such as with ReplayEvents:
+//
+//	type EventsReq struct {
+//	    LastEventID string `header:"Last-Event-ID"`
+//	}
 type Event struct {
 	// Name is the event type. Emitted as `event: <Name>`.
 	Name string
@@ -33,7 +44,7 @@ type Event struct {
 // writeEvent serializes a single Event in the text/event-stream wire format
 // and terminates with a blank line. Fields set to their zero values are
 // omitted. If Data is not a string or []byte, it is JSON-encoded.
-func writeEvent(w io.Writer, e Event) error {
+func writeEvent(w io.Writer, e Event, marshal func(any) ([]byte, error)) error {
 	if e.ID != "" {
 		if _, err := fmt.Fprintf(w, "id: %s\n", e.ID); err != nil {
 			return err
@@ -51,7 +62,7 @@ func writeEvent(w io.Writer, e Event) error {
 		}
 	}
 
-	if err := writeEventData(w, e.Data); err != nil {
+	if err := writeEventData(w, e.Data, marshal); err != nil {
 		return err
 	}
 
@@ -60,8 +71,9 @@ func writeEvent(w io.Writer, e Event) error {
 }
 
 // writeEventData emits the data field, choosing a serialization appropriate
-// to the payload type.
-func writeEventData(w io.Writer, data any) error {
+// to the payload type. Other payloads are encoded with marshal, the
+// router's JSON encoding.
+func writeEventData(w io.Writer, data any, marshal func(any) ([]byte, error)) error {
 	switch v := data.(type) {
 	case nil:
 		return nil
@@ -72,7 +84,7 @@ func writeEventData(w io.Writer, data any) error {
 		_, err := fmt.Fprintf(w, "data: %s\n", v)
 		return err
 	default:
-		b, err := json.Marshal(v)
+		b, err := marshal(v)
 		if err != nil {
 			return err
 		}
@@ -80,3 +92,110 @@ func writeEventData(w io.Writer, data any) error {
 		return err
 	}
 }
+
+// WithHeartbeat sends a comment line on a route's server-sent event stream
+// whenever no event has been written for d, so proxies and load balancers
+// do not close an idle connection and clients notice a dead one. Clients
+// ignore comments.
+//
+//	api.Get(r, "/events", h.Events, api.WithHeartbeat(15*time.Second))
+func WithHeartbeat(d time.Duration) RouteOption {
+	return RouteOptionFunc(func(ri *routeInfo) {
+		ri.heartbeat = d
+	})
+}
+
+// heartbeatComment is the comment line written by WithHeartbeat.
+const heartbeatComment = ": ping\n\n"
+
+// eventWriter writes events to a started event stream, flushing after
+// each, and sends heartbeats between them when an interval is set.
+type eventWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	marshal func(any) ([]byte, error)
+	mu      sync.Mutex
+	last    time.Time
+	stop    chan struct{}
+	closed  bool
+}
+
+// newEventWriter returns an eventWriter for w, encoding event data with
+// the route's JSON codec. With a positive heartbeat, it pings from a
+// goroutine until close is called.
+func newEventWriter(w http.ResponseWriter, cfg *handlerConfig) *eventWriter {
+	flusher, _ := w.(http.Flusher) //nolint:errcheck // ok being false means no flushing
+	ew := &eventWriter{w: w, flusher: flusher, marshal: cfg.codecs.marshalJSON, last: time.Now()}
+	if cfg.heartbeat > 0 {
+		ew.stop = make(chan struct{})
+		go ew.heartbeat(cfg.heartbeat)
+	}
+	return ew
+}
+
+// send writes ev and flushes.
+func (ew *eventWriter) send(ev Event) {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+	//nolint:errcheck,gosec // best-effort SSE write
+	writeEvent(ew.w, ev, ew.marshal)
+	ew.flushLocked()
+}
+
+// heartbeat pings whenever d has passed since the last write.
+func (ew *eventWriter) heartbeat(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ew.stop:
+			return
+		case <-timer.C:
+		}
+		ew.mu.Lock()
+		if ew.closed {
+			ew.mu.Unlock()
+			return
+		}
+		next := d - time.Since(ew.last)
+		if next <= 0 {
+			//nolint:errcheck,gosec // best-effort SSE write
+			io.WriteString(ew.w, heartbeatComment)
+			ew.flushLocked()
+			next = d
+		}
+		ew.mu.Unlock()
+		timer.Reset(next)
+	}
+}
+
+// flushLocked flushes the response and records the write. ew.mu is held.
+func (ew *eventWriter) flushLocked() {
+	ew.last = time.Now()
+	if ew.flusher != nil {
+		ew.flusher.Flush()
+	}
+}
+
+// shutdownEvent ends event streams when the router shuts down. It carries
+// an empty data line, since clients do not dispatch events without one.
+var shutdownEvent = Event{Name: "shutdown", Data: ""}
+
+// end sends shutdownEvent when ctx, from streamContext, ended the stream
+// for shutdown.
+func (ew *eventWriter) end(ctx context.Context) {
+	if errors.Is(context.Cause(ctx), ErrShuttingDown) {
+		ew.send(shutdownEvent)
+	}
+}
+
+// close stops the heartbeat; no write happens after it returns.
+func (ew *eventWriter) close() {
+	if ew.stop == nil {
+		return
+	}
+	ew.mu.Lock()
+	ew.closed = true
+	ew.mu.Unlock()
+	close(ew.stop)
+}
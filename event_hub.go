@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// EventHubConfig configures an EventHub.
+type EventHubConfig struct {
+	// Buffer is the number of events held for each subscriber before it
+	// counts as slow. Default: 16.
+	Buffer int
+
+	// Store, when set, records every published event before it is
+	// broadcast, assigning its ID, so reconnecting clients can resume with
+	// ReplayEvents.
+	Store EventStore
+
+	// OnEvict is called for each slow subscriber dropped from topic, to
+	// count evictions in a metrics system.
+	OnEvict func(topic string)
+}
+
+// EventHub broadcasts server-sent events to the clients subscribed to a
+// topic, so handlers stream events without managing goroutines:
+//
+//	hub := api.NewEventHub(api.EventHubConfig{Store: store})
+//
+//	api.Get(r, "/orders/events", func(ctx context.Context, req *StreamReq) (*api.Resp[<-chan api.Event], error) {
+//	    live := hub.Subscribe(ctx, "orders")
+//	    ch, err := api.ReplayEvents(ctx, store, "orders", req.LastEventID, live)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return &api.Resp[<-chan api.Event]{Body: ch}, nil
+//	})
+//
+//	hub.Publish(ctx, "orders", api.Event{Name: "created", Data: order})
+//
+// Publishing never blocks on a client. A subscriber whose buffer is full
+// is evicted: its channel closes, ending its response, and the client
+// reconnects to resume from its Last-Event-ID. An EventHub is safe for
+// concurrent use and serves a single instance; fan out across instances by
+// publishing from a shared message bus on each.
+type EventHub struct {
+	buffer  int
+	store   EventStore
+	onEvict func(topic string)
+
+	mu     sync.Mutex
+	topics map[string]map[*hubSubscriber]struct{}
+}
+
+// hubSubscriber is a subscription of one client to one topic.
+type hubSubscriber struct {
+	ch   chan Event
+	stop func() bool
+}
+
+// NewEventHub creates an EventHub with no subscribers.
+func NewEventHub(cfg ...EventHubConfig) *EventHub {
+	var c EventHubConfig
+	if len(cfg) > 0 {
+		c = cfg[0]
+	}
+	if c.Buffer <= 0 {
+		c.Buffer = 16
+	}
+	return &EventHub{
+		buffer:  c.Buffer,
+		store:   c.Store,
+		onEvict: c.OnEvict,
+		topics:  make(map[string]map[*hubSubscriber]struct{}),
+	}
+}
+
+// Subscribe returns a channel of the events published to topic from now
+// on. It closes when ctx is done, normally when the client disconnects, or
+// when the subscriber is evicted for falling behind.
+func (h *EventHub) Subscribe(ctx context.Context, topic string) <-chan Event {
+	sub := &hubSubscriber{ch: make(chan Event, h.buffer)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs := h.topics[topic]
+	if subs == nil {
+		subs = make(map[*hubSubscriber]struct{})
+		h.topics[topic] = subs
+	}
+	subs[sub] = struct{}{}
+	sub.stop = context.AfterFunc(ctx, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.removeLocked(topic, sub)
+	})
+	return sub.ch
+}
+
+// Publish sends e to every subscriber of topic, after recording it in the
+// Store when one is configured. It returns the Store's error, in which
+// case nothing is sent.
+func (h *EventHub) Publish(ctx context.Context, topic string, e Event) error {
+	if h.store != nil {
+		stored, err := h.store.Append(ctx, topic, e)
+		if err != nil {
+			return err
+		}
+		e = stored
+	}
+
+	var evicted int
+	h.mu.Lock()
+	for sub := range h.topics[topic] {
+		select {
+		case sub.ch <- e:
+		default:
+			sub.stop()
+			h.removeLocked(topic, sub)
+			evicted++
+		}
+	}
+	h.mu.Unlock()
+
+	if h.onEvict != nil {
+		for range evicted {
+			h.onEvict(topic)
+		}
+	}
+	return nil
+}
+
+// Subscribers returns the number of clients subscribed to topic.
+func (h *EventHub) Subscribers(topic string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.topics[topic])
+}
+
+// removeLocked unsubscribes sub from topic and closes its channel, unless
+// it was already removed. h.mu is held.
+func (h *EventHub) removeLocked(topic string, sub *hubSubscriber) {
+	subs := h.topics[topic]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.topics, topic)
+	}
+	close(sub.ch)
+}
@@ -0,0 +1,69 @@
+package api_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func TestEventHub_publish(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	hub := api.NewEventHub(api.EventHubConfig{Store: api.NewMemoryEventStore(10)})
+	a := hub.Subscribe(ctx, "orders")
+	b := hub.Subscribe(ctx, "orders")
+	other := hub.Subscribe(ctx, "invoices")
+	assert.Equal(t, 2, hub.Subscribers("orders"))
+
+	require.NoError(t, hub.Publish(ctx, "orders", api.Event{Name: "created", Data: "o-1"}))
+
+	for _, ch := range []<-chan api.Event{a, b} {
+		ev := <-ch
+		assert.Equal(t, "created", ev.Name)
+		assert.Equal(t, "o-1", ev.Data)
+		assert.NotEmpty(t, ev.ID, "store assigns the ID")
+	}
+	assert.Empty(t, other)
+}
+
+func TestEventHub_evicts_slow_subscriber(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var evicted atomic.Int32
+	hub := api.NewEventHub(api.EventHubConfig{
+		Buffer:  1,
+		OnEvict: func(_ string) { evicted.Add(1) },
+	})
+	slow := hub.Subscribe(ctx, "orders")
+
+	require.NoError(t, hub.Publish(ctx, "orders", api.Event{Data: "1"}))
+	require.NoError(t, hub.Publish(ctx, "orders", api.Event{Data: "2"}))
+
+	assert.Equal(t, "1", (<-slow).Data)
+	_, ok := <-slow
+	assert.False(t, ok, "channel closes on eviction")
+	assert.Equal(t, int32(1), evicted.Load())
+	assert.Zero(t, hub.Subscribers("orders"))
+}
+
+func TestEventHub_unsubscribes_on_cancel(t *testing.T) {
+	t.Parallel()
+
+	hub := api.NewEventHub()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := hub.Subscribe(ctx, "orders")
+	cancel()
+
+	assert.Eventually(t, func() bool { return hub.Subscribers("orders") == 0 }, time.Second, time.Millisecond)
+	_, ok := <-ch
+	assert.False(t, ok)
+	require.NoError(t, hub.Publish(context.Background(), "orders", api.Event{Data: "late"}))
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+)
+
+// ErrEventCursorExpired is returned by EventStore.After when the requested
+// Last-Event-ID is no longer retained, so a gap-free replay is impossible.
+var ErrEventCursorExpired = errors.New("event cursor expired")
+
+// EventStore persists recent events per stream so reconnecting SSE clients
+// can resume from their Last-Event-ID.
+type EventStore interface {
+	// Append records e on stream, assigns its ID, and returns the stored
+	// event. The ID must sort after every earlier ID on the same stream.
+	Append(ctx context.Context, stream string, e Event) (Event, error)
+
+	// After returns the events recorded on stream after lastID, oldest
+	// first. An empty lastID returns nothing: the client is new and starts
+	// from live events. An unknown or evicted lastID returns
+	// ErrEventCursorExpired.
+	After(ctx context.Context, stream, lastID string) ([]Event, error)
+}
+
+// ReplayEvents resumes an SSE stream for a client that reconnected with
+// lastEventID. The returned channel first yields the stored events after
+// that cursor, then forwards live. Subscribe to live before calling so no
+// event falls between the replay and the subscription; events delivered by
+// both are sent once.
+//
+//	type StreamReq struct {
+//	    LastEventID string `header:"Last-Event-ID"`
+//	}
+//
+//	func (h *H) Stream(ctx context.Context, req *StreamReq) (*api.Resp[<-chan api.Event], error) {
+//	    live := h.hub.Subscribe(ctx, "orders")
+//	    ch, err := api.ReplayEvents(ctx, h.store, "orders", req.LastEventID, live)
+//	    if errors.Is(err, api.ErrEventCursorExpired) {
+//	        ch, err = api.ReplayEvents(ctx, h.store, "orders", "", live)
+//	    }
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return &api.Resp[<-chan api.Event]{Body: ch}, nil
+//	}
+//
+// The channel closes when live closes or ctx is cancelled.
+func ReplayEvents(ctx context.Context, store EventStore, stream, lastEventID string, live <-chan Event) (<-chan Event, error) {
+	backlog, err := store.After(ctx, stream, lastEventID)
+	if err != nil {
+		return nil, err
+	}
+
+	replayed := make(map[string]struct{}, len(backlog))
+	for _, e := range backlog {
+		replayed[e.ID] = struct{}{}
+	}
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		send := func(e Event) bool {
+			select {
+			case out <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for _, e := range backlog {
+			if !send(e) {
+				return
+			}
+		}
+		for {
+			select {
+			case e, ok := <-live:
+				if !ok {
+					return
+				}
+				if _, dup := replayed[e.ID]; dup && e.ID != "" {
+					continue
+				}
+				if !send(e) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// MemoryEventStore is an in-process EventStore that keeps the most recent
+// events per stream in a fixed-size ring. IDs are decimal sequence numbers.
+type MemoryEventStore struct {
+	capacity int
+
+	mu      sync.Mutex
+	streams map[string]*eventRing
+}
+
+type eventRing struct {
+	next   uint64
+	events []Event // oldest first, at most capacity
+}
+
+// NewMemoryEventStore creates a store retaining up to capacity events per
+// stream. It panics if capacity is not positive.
+func NewMemoryEventStore(capacity int) *MemoryEventStore {
+	if capacity <= 0 {
+		panic("api: NewMemoryEventStore capacity must be positive")
+	}
+	return &MemoryEventStore{capacity: capacity, streams: make(map[string]*eventRing)}
+}
+
+// Append implements EventStore.
+func (s *MemoryEventStore) Append(_ context.Context, stream string, e Event) (Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ring, ok := s.streams[stream]
+	if !ok {
+		ring = &eventRing{next: 1}
+		s.streams[stream] = ring
+	}
+	e.ID = strconv.FormatUint(ring.next, 10)
+	ring.next++
+	if len(ring.events) == s.capacity {
+		copy(ring.events, ring.events[1:])
+		ring.events = ring.events[:len(ring.events)-1]
+	}
+	ring.events = append(ring.events, e)
+	return e, nil
+}
+
+// After implements EventStore.
+func (s *MemoryEventStore) After(_ context.Context, stream, lastID string) ([]Event, error) {
+	if lastID == "" {
+		return nil, nil
+	}
+	seq, err := strconv.ParseUint(lastID, 10, 64)
+	if err != nil {
+		return nil, ErrEventCursorExpired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ring, ok := s.streams[stream]
+	if !ok || seq >= ring.next {
+		return nil, ErrEventCursorExpired
+	}
+	// The oldest retained event has ID next-len; the cursor must be that
+	// event's predecessor or later for the replay to be gap-free.
+	oldest := ring.next - uint64(len(ring.events))
+	if seq+1 < oldest {
+		return nil, ErrEventCursorExpired
+	}
+	start := int(seq + 1 - oldest)
+	return append([]Event(nil), ring.events[start:]...), nil
+}
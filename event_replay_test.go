@@ -0,0 +1,162 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func appendEvents(t *testing.T, store api.EventStore, stream string, data ...string) {
+	t.Helper()
+	for _, d := range data {
+		_, err := store.Append(context.Background(), stream, api.Event{Name: "msg", Data: d})
+		require.NoError(t, err)
+	}
+}
+
+func TestMemoryEventStore_After(t *testing.T) {
+	t.Parallel()
+
+	store := api.NewMemoryEventStore(3)
+	appendEvents(t, store, "s", "a", "b", "c", "d")
+
+	tests := map[string]struct {
+		stream  string
+		lastID  string
+		wantIDs []string
+		wantErr error
+	}{
+		"new client": {
+			stream: "s",
+		},
+		"resume mid stream": {
+			stream:  "s",
+			lastID:  "2",
+			wantIDs: []string{"3", "4"},
+		},
+		"oldest predecessor is still gap free": {
+			stream:  "s",
+			lastID:  "1",
+			wantIDs: []string{"2", "3", "4"},
+		},
+		"caught up": {
+			stream: "s",
+			lastID: "4",
+		},
+		"evicted": {
+			stream:  "s",
+			lastID:  "0",
+			wantErr: api.ErrEventCursorExpired,
+		},
+		"from the future": {
+			stream:  "s",
+			lastID:  "9",
+			wantErr: api.ErrEventCursorExpired,
+		},
+		"foreign id": {
+			stream:  "s",
+			lastID:  "abc",
+			wantErr: api.ErrEventCursorExpired,
+		},
+		"unknown stream": {
+			stream:  "other",
+			lastID:  "1",
+			wantErr: api.ErrEventCursorExpired,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			events, err := store.After(context.Background(), tt.stream, tt.lastID)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			ids := make([]string, 0, len(events))
+			for _, e := range events {
+				ids = append(ids, e.ID)
+			}
+			assert.ElementsMatch(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestReplayEvents_resumes_from_last_event_id(t *testing.T) {
+	t.Parallel()
+
+	store := api.NewMemoryEventStore(10)
+	appendEvents(t, store, "orders", "a", "b", "c")
+
+	type Req struct {
+		LastEventID string `header:"Last-Event-ID"`
+	}
+
+	r := api.New()
+	api.Get(r, "/events", func(ctx context.Context, req *Req) (*api.Resp[<-chan api.Event], error) {
+		live := make(chan api.Event, 2)
+		// "3" raced the subscription and is delivered by both sources.
+		live <- api.Event{ID: "3", Name: "msg", Data: "c"}
+		live <- api.Event{ID: "4", Name: "msg", Data: "d"}
+		close(live)
+
+		ch, err := api.ReplayEvents(ctx, store, "orders", req.LastEventID, live)
+		if err != nil {
+			return nil, err
+		}
+		return &api.Resp[<-chan api.Event]{Body: ch}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t,
+		"id: 2\nevent: msg\ndata: b\n\n"+
+			"id: 3\nevent: msg\ndata: c\n\n"+
+			"id: 4\nevent: msg\ndata: d\n\n",
+		w.Body.String())
+}
+
+func TestReplayEvents_expired_cursor(t *testing.T) {
+	t.Parallel()
+
+	store := api.NewMemoryEventStore(1)
+	appendEvents(t, store, "s", "a", "b", "c")
+
+	ch, err := api.ReplayEvents(context.Background(), store, "s", "1", nil)
+	require.ErrorIs(t, err, api.ErrEventCursorExpired)
+	assert.Nil(t, ch)
+}
+
+func TestReplayEvents_stops_on_cancel(t *testing.T) {
+	t.Parallel()
+
+	store := api.NewMemoryEventStore(4)
+	appendEvents(t, store, "s", "a", "b")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := api.ReplayEvents(ctx, store, "s", "", make(chan api.Event))
+	require.NoError(t, err)
+
+	cancel()
+	for range ch { //nolint:revive // drain until closed
+	}
+}
+
+func TestNewMemoryEventStore_panics_on_zero_capacity(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() { api.NewMemoryEventStore(0) })
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// WithRequestExample documents a named example of the whole request body.
+// The value is encoded with the router's JSON codec, following the same
+// json tags as the body, and added to the examples of each JSON request
+// media type:
+//
+//	api.Post(r, "/orders", h.Create,
+//	    api.WithRequestExample("minimal", CreateOrder{SKU: "A-1", Qty: 1}),
+//	    api.WithRequestExample("gift", CreateOrder{SKU: "A-1", Qty: 1, Gift: true}),
+//	)
+//
+// A later example with the same name replaces an earlier one. Field-level
+// example tags still document individual properties.
+func WithRequestExample(name string, value any) RouteOption {
+	return RouteOptionFunc(func(ri *routeInfo) {
+		if ri.requestExamples == nil {
+			ri.requestExamples = make(map[string]any)
+		}
+		ri.requestExamples[name] = value
+	})
+}
+
+// WithResponseExample documents a named example of the response body for
+// status, added to the examples of each of its JSON media types. status must be
+// one the route documents: its success status, an error status, or one
+// added with WithResponse.
+func WithResponseExample(status int, name string, value any) RouteOption {
+	return RouteOptionFunc(func(ri *routeInfo) {
+		if ri.responseExamples == nil {
+			ri.responseExamples = make(map[int]map[string]any)
+		}
+		if ri.responseExamples[status] == nil {
+			ri.responseExamples[status] = make(map[string]any)
+		}
+		ri.responseExamples[status][name] = value
+	})
+}
+
+// checkExamples reports examples that the router's JSON codec cannot
+// encode, and replaces the others with their decoded JSON, so the spec
+// renders them as the route's JSON bodies, whether written as JSON or
+// YAML.
+func checkExamples(p *registrationProblems, ri *routeInfo, codecs *codecRegistry) {
+	for name, v := range ri.requestExamples {
+		ri.requestExamples[name] = normalizeExample(p, codecs, "request", name, v)
+	}
+	for status, examples := range ri.responseExamples {
+		for name, v := range examples {
+			examples[name] = normalizeExample(p, codecs, statusToString(status)+" response", name, v)
+		}
+	}
+}
+
+func normalizeExample(p *registrationProblems, codecs *codecRegistry, where, name string, v any) any {
+	b, err := codecs.marshalJSON(v)
+	if err != nil {
+		p.add("pass a value that the router's JSON codec can marshal", "%s example %q: %v", where, name, err)
+		return nil
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		p.add("", "%s example %q: %v", where, name, err)
+		return nil
+	}
+	return out
+}
+
+// applyExamples adds the route's examples to the media types of its
+// request body and responses.
+func applyExamples(op *Operation, ri *routeInfo) {
+	if op.RequestBody != nil && len(ri.requestExamples) > 0 {
+		body := *op.RequestBody
+		body.Content = withExamples(body.Content, ri.requestExamples)
+		op.RequestBody = &body
+	}
+	for status, examples := range ri.responseExamples {
+		key := statusToString(status)
+		if resp, ok := op.Responses[key]; ok {
+			resp.Content = withExamples(resp.Content, examples)
+			op.Responses[key] = resp
+		}
+	}
+}
+
+// withExamples returns a copy of content with examples added to each JSON
+// media type; they are encoded as JSON, so they do not show what an XML
+// or other body looks like. Content maps can be shared between responses,
+// so they are never modified in place.
+func withExamples(content map[string]MediaObj, examples map[string]any) map[string]MediaObj {
+	out := make(map[string]MediaObj, len(content))
+	for ct, media := range content {
+		if !isJSONExampleType(ct) {
+			out[ct] = media
+			continue
+		}
+		merged := maps.Clone(media.Examples)
+		if merged == nil {
+			merged = make(map[string]ExampleObj, len(examples))
+		}
+		for name, v := range examples {
+			merged[name] = ExampleObj{Value: v}
+		}
+		media.Examples = merged
+		out[ct] = media
+	}
+	return out
+}
+
+// isJSONExampleType reports whether ct is a JSON media type, such as
+// application/json or application/problem+json.
+func isJSONExampleType(ct string) bool {
+	mt := mediaTypeOf(ct)
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// ExampleCatalog lists the documented examples of every operation, for
+// tooling and contract tests. See Router.ServeExamples.
+type ExampleCatalog struct {
+	Operations []OperationExamples `json:"operations"`
+}
+
+// OperationExamples are the examples of one operation. Request and
+// Responses hold the named body examples from WithRequestExample and
+// WithResponseExample, keyed by status for responses. When a body has no
+// named examples but its properties have example tags, they are combined
+// into one example named "default". Parameters holds the example of each
+// parameter that has one, keyed by location (path, query, header, or
+// cookie) and then name, as a query and a header param may share a name.
+type OperationExamples struct {
+	OperationID string                    `json:"operationId,omitempty"`
+	Method      string                    `json:"method"`
+	Path        string                    `json:"path"`
+	Parameters  map[string]map[string]any `json:"parameters,omitempty"`
+	Request     map[string]any            `json:"request,omitempty"`
+	Responses   map[string]map[string]any `json:"responses,omitempty"`
+}
+
+// ServeExamples registers a GET handler at the given path that serves the
+// ExampleCatalog of the spec as JSON, per host like ServeSpec. Operations
+// without examples are left out.
+func (r *Router) ServeExamples(pattern string) {
+	r.handle("GET "+pattern, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		catalog := newExampleCatalog(r.HostSpec(r.specHost(req)))
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck,gosec // best-effort after WriteHeader
+		json.NewEncoder(w).Encode(catalog)
+	}))
+}
+
+// newExampleCatalog collects the examples of spec's operations, sorted by
+// path and method.
+func newExampleCatalog(spec OpenAPISpec) ExampleCatalog {
+	var schemas map[string]JSONSchema
+	if spec.Components != nil {
+		schemas = spec.Components.Schemas
+	}
+	catalog := ExampleCatalog{Operations: []OperationExamples{}}
+	for path, item := range spec.Paths {
+		for method, op := range item {
+			ex := OperationExamples{OperationID: op.OperationID, Method: strings.ToUpper(method), Path: path}
+			for _, p := range op.Parameters {
+				if p.Example == nil {
+					continue
+				}
+				if ex.Parameters == nil {
+					ex.Parameters = make(map[string]map[string]any)
+				}
+				if ex.Parameters[p.In] == nil {
+					ex.Parameters[p.In] = make(map[string]any)
+				}
+				ex.Parameters[p.In][p.Name] = p.Example
+			}
+			if op.RequestBody != nil {
+				ex.Request = contentExamples(op.RequestBody.Content, schemas)
+			}
+			for status, resp := range op.Responses {
+				if examples := contentExamples(resp.Content, schemas); examples != nil {
+					if ex.Responses == nil {
+						ex.Responses = make(map[string]map[string]any)
+					}
+					ex.Responses[status] = examples
+				}
+			}
+			if ex.Parameters != nil || ex.Request != nil || ex.Responses != nil {
+				catalog.Operations = append(catalog.Operations, ex)
+			}
+		}
+	}
+	slices.SortFunc(catalog.Operations, func(a, b OperationExamples) int {
+		return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Method, b.Method))
+	})
+	return catalog
+}
+
+// contentExamples returns the named examples of the first media type that
+// has any, or a "default" example built from property example tags.
+func contentExamples(content map[string]MediaObj, schemas map[string]JSONSchema) map[string]any {
+	cts := slices.Sorted(maps.Keys(content))
+	for _, ct := range cts {
+		if media := content[ct]; len(media.Examples) > 0 {
+			out := make(map[string]any, len(media.Examples))
+			for name, ex := range media.Examples {
+				out[name] = ex.Value
+			}
+			return out
+		}
+	}
+	for _, ct := range cts {
+		if media := content[ct]; media.Schema != nil {
+			if v := schemaExample(*media.Schema, schemas, 0); v != nil {
+				return map[string]any{"default": v}
+			}
+		}
+	}
+	return nil
+}
+
+// maxExampleDepth bounds schemaExample on recursive schemas.
+const maxExampleDepth = 8
+
+// schemaExample builds an example value from the example of schema or of
+// its properties, following component references. It returns nil when
+// there are none.
+func schemaExample(s JSONSchema, schemas map[string]JSONSchema, depth int) any {
+	if depth > maxExampleDepth {
+		return nil
+	}
+	if name, ok := strings.CutPrefix(s.Ref, componentRefPrefix); ok {
+		return schemaExample(schemas[name], schemas, depth+1)
+	}
+	if s.Example != nil {
+		if ex, ok := s.Example.(string); ok {
+			return typedExample(ex, s.Type)
+		}
+		return s.Example
+	}
+	switch {
+	case len(s.Properties) > 0:
+		var obj map[string]any
+		for name, prop := range s.Properties {
+			if v := schemaExample(prop, schemas, depth+1); v != nil {
+				if obj == nil {
+					obj = make(map[string]any)
+				}
+				obj[name] = v
+			}
+		}
+		if obj != nil {
+			return obj
+		}
+	case s.Items != nil:
+		if v := schemaExample(*s.Items, schemas, depth+1); v != nil {
+			return []any{v}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type exampleOrder struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty,omitempty"`
+}
+
+type exampleOrderReq struct {
+	Body exampleOrder
+}
+
+func TestWithExamples(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Post(r, "/orders", func(_ context.Context, req *exampleOrderReq) (*api.Resp[exampleOrder], error) {
+		return &api.Resp[exampleOrder]{Body: req.Body}, nil
+	},
+		api.WithRequestExample("minimal", exampleOrder{SKU: "A-1"}),
+		api.WithRequestExample("bulk", exampleOrder{SKU: "A-1", Qty: 100}),
+		api.WithResponseExample(http.StatusOK, "created", exampleOrder{SKU: "A-1", Qty: 1}),
+		api.WithResponseExample(http.StatusInternalServerError, "outage", map[string]string{"detail": "inventory unavailable"}),
+		api.WithResponseExample(http.StatusTeapot, "ignored", "not documented"),
+	)
+
+	op := r.Spec().Paths["/orders"]["post"]
+
+	req := op.RequestBody.Content["application/json"].Examples
+	require.Len(t, req, 2)
+	assert.Equal(t, map[string]any{"sku": "A-1"}, req["minimal"].Value)
+	assert.Equal(t, map[string]any{"sku": "A-1", "qty": float64(100)}, req["bulk"].Value)
+
+	ok := op.Responses["200"].Content["application/json"].Examples
+	assert.Equal(t, map[string]any{"sku": "A-1", "qty": float64(1)}, ok["created"].Value)
+
+	failed := op.Responses["500"].Content["application/json"].Examples
+	assert.Equal(t, map[string]any{"detail": "inventory unavailable"}, failed["outage"].Value)
+	assert.NotContains(t, op.Responses, "418")
+
+	// Error responses share their content; examples stay on their status.
+	assert.Empty(t, op.Responses["400"].Content["application/json"].Examples)
+}
+
+func TestWithExamples_yaml_uses_json_names(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Post(r, "/orders", func(_ context.Context, req *exampleOrderReq) (*api.Resp[exampleOrder], error) {
+		return &api.Resp[exampleOrder]{Body: req.Body}, nil
+	}, api.WithRequestExample("minimal", exampleOrder{SKU: "A-1"}))
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteSpecYAML(&buf))
+
+	assert.Contains(t, buf.String(), "sku: A-1")
+	assert.NotContains(t, buf.String(), "SKU:")
+}
+
+func TestWithExamples_unencodable(t *testing.T) {
+	t.Parallel()
+
+	err := registrationPanic(t, func() {
+		api.Post(api.New(), "/orders", func(_ context.Context, _ *exampleOrderReq) (*api.Void, error) { return nil, nil },
+			api.WithRequestExample("bad", make(chan int)))
+	})
+	require.Len(t, err.Problems, 1)
+	assert.Contains(t, err.Problems[0].Message, `request example "bad"`)
+}
+
+type exampleEvent struct {
+	At time.Time `json:"at"`
+}
+
+type exampleEventReq struct {
+	Body exampleEvent
+}
+
+func TestWithExamples_router_codec(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := api.New(api.WithTimeFormat(api.TimeUnix))
+	api.Post(r, "/events", func(_ context.Context, req *exampleEventReq) (*api.Resp[exampleEvent], error) {
+		return &api.Resp[exampleEvent]{Body: req.Body}, nil
+	},
+		api.WithRequestExample("new", exampleEvent{At: at}),
+		api.WithResponseExample(http.StatusOK, "stored", exampleEvent{At: at}),
+	)
+
+	op := r.Spec().Paths["/events"]["post"]
+
+	req := op.RequestBody.Content["application/json"].Examples
+	assert.Equal(t, map[string]any{"at": float64(at.Unix())}, req["new"].Value)
+	require.Contains(t, op.RequestBody.Content, "application/xml")
+	assert.Empty(t, op.RequestBody.Content["application/xml"].Examples)
+
+	ok := op.Responses["200"].Content
+	assert.Equal(t, map[string]any{"at": float64(at.Unix())}, ok["application/json"].Examples["stored"].Value)
+	require.Contains(t, ok, "application/xml")
+	assert.Empty(t, ok["application/xml"].Examples)
+}
+
+func TestServeExamples(t *testing.T) {
+	t.Parallel()
+
+	type tagged struct {
+		Name string `json:"name" example:"Ada"`
+		Age  int    `json:"age" example:"36"`
+		Note string `json:"note"`
+	}
+	type getReq struct {
+		ID    string `path:"id" example:"u_1"`
+		Shard string `query:"id" example:"s_2"`
+	}
+
+	r := api.New()
+	api.Post(r, "/orders", func(_ context.Context, req *exampleOrderReq) (*api.Resp[exampleOrder], error) {
+		return &api.Resp[exampleOrder]{Body: req.Body}, nil
+	},
+		api.WithRequestExample("minimal", exampleOrder{SKU: "A-1"}),
+		api.WithResponseExample(http.StatusOK, "created", exampleOrder{SKU: "A-1", Qty: 1}),
+	)
+	api.Get(r, "/users/{id}", func(_ context.Context, _ *getReq) (*api.Resp[tagged], error) {
+		return nil, nil
+	})
+	api.Get(r, "/plain", func(_ context.Context, _ *api.Void) (*api.Resp[exampleOrder], error) {
+		return nil, nil
+	})
+	r.ServeExamples("/examples")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/examples", http.NoBody))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"operations": [
+		{
+			"operationId": "postOrders", "method": "POST", "path": "/orders",
+			"request": {"minimal": {"sku": "A-1"}},
+			"responses": {"200": {"created": {"sku": "A-1", "qty": 1}}}
+		},
+		{
+			"operationId": "getUsersById", "method": "GET", "path": "/users/{id}",
+			"parameters": {"path": {"id": "u_1"}, "query": {"id": "s_2"}},
+			"responses": {"200": {"default": {"name": "Ada", "age": 36}}}
+		}
+	]}`, w.Body.String())
+}
@@ -1,6 +1,10 @@
 package api
 
-import "reflect"
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+)
 
 // Test-only exports for internal functions.
 var (
@@ -17,10 +21,11 @@ var (
 
 	ValidateConstraints = validateConstraints
 	GenerateOperationID = generateOperationID
-
-	WriteEvent = writeEvent
 )
 
+// WriteEvent writes e with the default JSON encoding.
+func WriteEvent(w io.Writer, e Event) error { return writeEvent(w, e, json.Marshal) }
+
 // BuildResponseDescriptor exposes the internal descriptor builder to tests,
 // wrapped so the external test package can inspect it without importing
 // unexported types.
@@ -94,3 +99,9 @@ func NewSchemaRegistry() *TestSchemaRegistry {
 func (t *TestSchemaRegistry) TypeToSchema(typ reflect.Type) JSONSchema {
 	return t.reg.typeToSchema(typ)
 }
+
+// NewContentEncoderCompress returns the compress method of a route
+// content encoder for encoding.
+func NewContentEncoderCompress(encoding string) func(b []byte, contentType string) ([]byte, error) {
+	return newContentEncoder(encoding).compress
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FieldMask is a sparse fieldset: the dotted JSON paths a client asked
+// for. Bind it from a comma-separated query parameter and hand it to the
+// data layer so it can select only the needed columns:
+//
+//	type ListReq struct {
+//	    Fields api.FieldMask `query:"fields" explode:"false" doc:"Fields to return"`
+//	}
+//
+//	func (h *H) List(ctx context.Context, req *ListReq) (*api.Resp[[]User], error) {
+//	    if err := api.ValidateFieldMask[User](req.Fields); err != nil {
+//	        return nil, err
+//	    }
+//	    users, err := h.store.ListUsers(ctx, req.Fields)
+//	    ...
+//	}
+//
+// An empty mask selects every field.
+type FieldMask []string
+
+// IsEmpty reports whether the mask selects every field.
+func (m FieldMask) IsEmpty() bool {
+	return len(m) == 0
+}
+
+// Has reports whether path (e.g. "address.city") is selected, either
+// directly, through a selected ancestor ("address"), or because a
+// descendant is selected and the parent object is needed to hold it.
+func (m FieldMask) Has(path string) bool {
+	if m.IsEmpty() {
+		return true
+	}
+	for _, p := range m {
+		if p == path || strings.HasPrefix(path, p+".") || strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Top returns the distinct top-level fields the mask touches, in order.
+// Stores that map JSON fields to columns usually need only these.
+func (m FieldMask) Top() []string {
+	out := make([]string, 0, len(m))
+	for _, p := range m {
+		top, _, _ := strings.Cut(p, ".")
+		if !slices.Contains(out, top) {
+			out = append(out, top)
+		}
+	}
+	return out
+}
+
+// ValidateFieldMask checks that every path in m names a JSON field of T,
+// returning a 400 *Err listing the unknown paths.
+func ValidateFieldMask[T any](m FieldMask) error {
+	var unknown []string
+	t := reflect.TypeFor[T]()
+	for _, p := range m {
+		if !fieldPathExists(t, strings.Split(p, ".")) {
+			unknown = append(unknown, p)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	return Error(CodeBadRequest, WithMessage("unknown fields: "+strings.Join(unknown, ", ")))
+}
+
+// fieldPathExists walks JSON field names through structs, pointers, slices,
+// and embedded structs.
+func fieldPathExists(t reflect.Type, path []string) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if len(path) == 0 {
+		return true
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous && f.Tag.Get("json") == "" {
+			continue
+		}
+		if jsonFieldName(f) == path[0] {
+			return fieldPathExists(f.Type, path[1:])
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type fmAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type fmMeta struct {
+	Created string `json:"created"`
+}
+
+type fmUser struct {
+	fmMeta
+	ID      string      `json:"id"`
+	Name    string      `json:"name"`
+	Address *fmAddress  `json:"address"`
+	Past    []fmAddress `json:"past"`
+}
+
+func TestFieldMask_Has(t *testing.T) {
+	t.Parallel()
+
+	m := api.FieldMask{"name", "address.city"}
+
+	tests := map[string]struct {
+		mask api.FieldMask
+		path string
+		want bool
+	}{
+		"direct":             {mask: m, path: "name", want: true},
+		"unselected":         {mask: m, path: "id", want: false},
+		"selected leaf":      {mask: m, path: "address.city", want: true},
+		"parent of selected": {mask: m, path: "address", want: true},
+		"sibling leaf":       {mask: m, path: "address.zip", want: false},
+		"ancestor selected":  {mask: api.FieldMask{"address"}, path: "address.zip", want: true},
+		"prefix is not path": {mask: api.FieldMask{"name"}, path: "names", want: false},
+		"empty selects all":  {path: "anything", want: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.mask.Has(tt.path))
+		})
+	}
+
+	assert.Equal(t, []string{"name", "address"}, api.FieldMask{"name", "address.city", "address.zip"}.Top())
+}
+
+func TestValidateFieldMask(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, api.ValidateFieldMask[fmUser](api.FieldMask{"id", "address.city", "past.zip", "created"}))
+
+	err := api.ValidateFieldMask[fmUser](api.FieldMask{"id", "secret", "address.street"})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, api.ErrorStatus(err))
+	assert.Contains(t, err.Error(), "secret, address.street")
+}
+
+func TestFieldMask_query_binding(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Fields api.FieldMask `query:"fields" explode:"false" doc:"Fields to return"`
+	}
+
+	var got api.FieldMask
+	r := api.New()
+	api.Get(r, "/users", func(_ context.Context, req *Req) (*api.Void, error) {
+		if err := api.ValidateFieldMask[fmUser](req.Fields); err != nil {
+			return nil, err
+		}
+		got = req.Fields
+		return &api.Void{}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?fields=id,address.city", nil))
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	assert.Equal(t, api.FieldMask{"id", "address.city"}, got)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?fields=password", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	params := r.Spec().Paths["/users"]["get"].Parameters
+	require.Len(t, params, 1)
+	assert.Equal(t, "array", params[0].Schema.Type)
+	require.NotNil(t, params[0].Explode)
+	assert.False(t, *params[0].Explode)
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldFilter decides, per request, which policy-tagged fields survive
+// encoding. A nil func leaves the corresponding tag unenforced.
+type fieldFilter struct {
+	// reveal reports whether a redact-tagged field keeps its value.
+	reveal func(category string) bool
+	// allow reports whether the caller holds a scope named by a scope tag.
+	allow func(scope string) bool
+}
+
+// newFieldFilter binds the configured policies to the request context.
+// Returns false when no policy is configured.
+func newFieldFilter(ctx context.Context, redaction RedactionPolicy, scopes ScopePolicy) (fieldFilter, bool) {
+	var f fieldFilter
+	if redaction != nil {
+		f.reveal = func(category string) bool { return redaction(ctx, category) }
+	}
+	if scopes != nil {
+		f.allow = func(scope string) bool { return scopes(ctx, scope) }
+	}
+	return f, f.reveal != nil || f.allow != nil
+}
+
+// apply zeroes or masks field f of a struct copy according to its tags.
+// Returns true when the field was handled and must not be recursed into.
+func (ff fieldFilter) apply(sf reflect.StructField, f reflect.Value) bool {
+	if scope, ok := sf.Tag.Lookup("scope"); ok && ff.allow != nil {
+		if !ff.allowsAny(scope) {
+			f.SetZero()
+			return true
+		}
+	}
+	if category, ok := sf.Tag.Lookup("redact"); ok {
+		if ff.reveal != nil && !ff.reveal(category) {
+			maskField(f)
+		}
+		return true
+	}
+	return false
+}
+
+// allowsAny reports whether any scope in a comma-separated list is allowed.
+func (ff fieldFilter) allowsAny(list string) bool {
+	for s := range strings.SplitSeq(list, ",") {
+		if ff.allow(strings.TrimSpace(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldPolicyCache memoizes typeHasFieldPolicy per type.
+var fieldPolicyCache sync.Map // map[reflect.Type]bool
+
+// typeHasFieldPolicy reports whether values of t may contain redact- or
+// scope-tagged fields. Interface types are assumed to, since their dynamic
+// type is only known at encode time.
+func typeHasFieldPolicy(t reflect.Type) bool {
+	if v, ok := fieldPolicyCache.Load(t); ok {
+		return v.(bool) //nolint:forcetypeassert // cache only stores bools
+	}
+	has := scanFieldPolicy(t, map[reflect.Type]bool{})
+	fieldPolicyCache.Store(t, has)
+	return has
+}
+
+func scanFieldPolicy(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	//exhaustive:ignore
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return scanFieldPolicy(t.Elem(), seen)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if _, ok := f.Tag.Lookup("redact"); ok {
+				return true
+			}
+			if _, ok := f.Tag.Lookup("scope"); ok {
+				return true
+			}
+			if scanFieldPolicy(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filterFields returns a copy of v with ff applied to every tagged field.
+// Only the parts of v that can contain tagged fields are copied; everything
+// else is shared with v, and v itself is never mutated.
+func filterFields(v reflect.Value, ff fieldFilter) reflect.Value {
+	t := v.Type()
+	if !typeHasFieldPolicy(t) {
+		return v
+	}
+
+	//exhaustive:ignore
+	switch t.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(t).Elem()
+		out.Set(filterFields(v.Elem(), ff))
+		return out
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(t.Elem())
+		out.Elem().Set(filterFields(v.Elem(), ff))
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(filterFields(v.Index(i), ff))
+		}
+		return out
+
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		for i := range v.Len() {
+			out.Index(i).Set(filterFields(v.Index(i), ff))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), filterFields(iter.Value(), ff))
+		}
+		return out
+
+	case reflect.Struct:
+		out := reflect.New(t).Elem()
+		out.Set(v)
+		for i := range t.NumField() {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			if ff.apply(sf, out.Field(i)) {
+				continue
+			}
+			out.Field(i).Set(filterFields(v.Field(i), ff))
+		}
+		return out
+	}
+	return v
+}
+
+// maskField replaces a redacted field's value in place.
+func maskField(f reflect.Value) {
+	if f.Kind() == reflect.String {
+		f.SetString(RedactedMask)
+		return
+	}
+	f.SetZero()
+}
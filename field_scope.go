@@ -0,0 +1,34 @@
+package api
+
+import "context"
+
+// ScopePolicy reports whether the caller holds scope. It is consulted for
+// response fields tagged with scope:
+//
+//	type User struct {
+//	    Name  string `json:"name"`
+//	    Email string `json:"email,omitempty" scope:"admin"`
+//	}
+//
+// A tag may list several scopes separated by commas; the field is kept when
+// the caller holds any of them.
+type ScopePolicy func(ctx context.Context, scope string) bool
+
+// WithFieldScopes filters scope-tagged response fields. Before a response is
+// encoded, each field whose scopes the caller lacks is reset to its zero
+// value, so it is dropped from the output when its encoding tag carries
+// omitempty. The handler's value is never mutated.
+//
+// Combined with Introspect, the policy is typically:
+//
+//	api.WithFieldScopes(func(ctx context.Context, scope string) bool {
+//	    info, ok := api.GetIntrospection(ctx)
+//	    return ok && info.HasScope(scope)
+//	})
+//
+// Scope-tagged properties are marked in the spec with x-required-scopes.
+func WithFieldScopes(p ScopePolicy) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.fieldScopes = p
+	})
+}
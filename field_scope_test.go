@@ -0,0 +1,99 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type scopedUser struct {
+	Name   string `json:"name"`
+	Email  string `json:"email,omitempty" scope:"admin"`
+	Notes  string `json:"notes,omitempty" scope:"admin, support"`
+	Secret string `json:"secret" scope:"admin" redact:"true"`
+}
+
+type callerScopesKey struct{}
+
+func TestWithFieldScopes(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(
+		api.WithFieldScopes(func(ctx context.Context, scope string) bool {
+			held, _ := ctx.Value(callerScopesKey{}).([]string)
+			for _, s := range held {
+				if s == scope {
+					return true
+				}
+			}
+			return false
+		}),
+		api.WithRedactionPolicy(func(context.Context, string) bool { return false }),
+	)
+	api.Get(r, "/users", func(_ context.Context, _ *api.Void) (*api.Resp[[]scopedUser], error) {
+		return &api.Resp[[]scopedUser]{Body: []scopedUser{
+			{Name: "Ada", Email: "ada@example.com", Notes: "vip", Secret: "s3"},
+		}}, nil
+	})
+
+	tests := map[string]struct {
+		scopes []string
+		want   string
+	}{
+		"no scopes": {
+			want: `[{"name":"Ada","secret":""}]`,
+		},
+		"support": {
+			scopes: []string{"support"},
+			want:   `[{"name":"Ada","notes":"vip","secret":""}]`,
+		},
+		"admin sees fields but redaction still applies": {
+			scopes: []string{"admin"},
+			want:   `[{"name":"Ada","email":"ada@example.com","notes":"vip","secret":"[REDACTED]"}]`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			req = req.WithContext(context.WithValue(req.Context(), callerScopesKey{}, tt.scopes))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, tt.want, w.Body.String())
+		})
+	}
+}
+
+func TestWithFieldScopes_unset(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/user", func(_ context.Context, _ *api.Void) (*api.Resp[scopedUser], error) {
+		return &api.Resp[scopedUser]{Body: scopedUser{Name: "Ada", Email: "ada@example.com"}}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user", nil))
+
+	assert.Contains(t, w.Body.String(), "ada@example.com")
+}
+
+func TestScopeTag_schema(t *testing.T) {
+	t.Parallel()
+
+	s := api.StructToSchema(reflect.TypeFor[scopedUser]())
+	assert.Equal(t, []string{"admin"}, s.Properties["email"].Extensions["x-required-scopes"])
+	assert.Equal(t, []string{"admin", "support"}, s.Properties["notes"].Extensions["x-required-scopes"])
+	assert.Nil(t, s.Properties["name"].Extensions)
+}
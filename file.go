@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// File is a response body served as a file download, with Range requests,
+// conditional requests (If-Modified-Since, If-None-Match, If-Range), and
+// Content-Disposition handled by http.ServeContent:
+//
+//	func(ctx context.Context, req *AvatarReq) (*api.Resp[api.File], error) {
+//	    return &api.Resp[api.File]{Body: api.File{
+//	        Path:     filepath.Join(avatarDir, req.UserID+".png"),
+//	        Filename: "avatar.png",
+//	        Inline:   true,
+//	    }}, nil
+//	}
+//
+// Set either Path, to serve a file from disk, or Content. A Path that does
+// not exist is answered with 404. The status is owned by ServeContent (200,
+// 206, 304, 412, or 416), so a Status field on the response is ignored.
+type File struct {
+	// Path is the file to serve. Its modification time is the default
+	// ModTime and its base name the default Filename.
+	Path string
+
+	// Content is served when Path is empty. It is closed after the
+	// response when it implements io.Closer.
+	Content io.ReadSeeker
+
+	// Filename is the name the client saves the file as, sent in
+	// Content-Disposition. Empty with Content sends no Content-Disposition.
+	Filename string
+
+	// ContentType is the media type of the file. Default: from the
+	// extension of Filename or Path, or sniffed from the content.
+	ContentType string
+
+	// ModTime is the Last-Modified time, validating If-Modified-Since.
+	// The zero time sends no Last-Modified.
+	ModTime time.Time
+
+	// Inline asks the client to display the file rather than download it,
+	// as for an avatar shown in a page.
+	Inline bool
+}
+
+var fileType = reflect.TypeFor[File]()
+
+// writeFileBody serves a File body with http.ServeContent.
+func writeFileBody(w http.ResponseWriter, r *http.Request, bv reflect.Value, cfg *handlerConfig) {
+	f := bv.Interface().(File) //nolint:errcheck,forcetypeassert // descriptor guarantees File
+
+	content := f.Content
+	if f.Path != "" {
+		file, err := os.Open(f.Path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				err = Error(CodeNotFound, WithMessage("file not found"), WithCause(err))
+			}
+			cfg.writeErr(w, r, err)
+			return
+		}
+		defer file.Close() //nolint:errcheck // read-only file
+		info, err := file.Stat()
+		if err != nil {
+			cfg.writeErr(w, r, err)
+			return
+		}
+		if info.IsDir() {
+			cfg.writeErr(w, r, Error(CodeNotFound, WithMessage("file not found")))
+			return
+		}
+		if f.ModTime.IsZero() {
+			f.ModTime = info.ModTime()
+		}
+		if f.Filename == "" {
+			f.Filename = filepath.Base(f.Path)
+		}
+		content = file
+	} else if c, ok := content.(io.Closer); ok {
+		defer c.Close() //nolint:errcheck // best-effort release after the response
+	}
+	if content == nil {
+		cfg.writeErr(w, r, errors.New("api.File has neither Path nor Content"))
+		return
+	}
+
+	if f.ContentType != "" {
+		w.Header().Set("Content-Type", f.ContentType)
+	}
+	if f.Filename != "" {
+		disposition := "attachment"
+		if f.Inline {
+			disposition = "inline"
+		}
+		w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": f.Filename}))
+	}
+	// ServeContent derives the type from the name's extension when
+	// Content-Type is unset.
+	http.ServeContent(w, r, f.Filename, f.ModTime, content)
+}
@@ -0,0 +1,107 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.csv")
+	require.NoError(t, os.WriteFile(path, []byte("id,total\n1,42\n"), 0o600))
+	modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	type fileReq struct {
+		Name string `path:"name"`
+	}
+	r := api.New()
+	api.Get(r, "/files/{name}", func(_ context.Context, req *fileReq) (*api.Resp[api.File], error) {
+		return &api.Resp[api.File]{Body: api.File{Path: filepath.Join(dir, req.Name)}}, nil
+	})
+	api.Get(r, "/avatar", func(_ context.Context, _ *api.Void) (*api.Resp[api.File], error) {
+		return &api.Resp[api.File]{Body: api.File{
+			Content:     strings.NewReader("PNG"),
+			Filename:    "avatar.png",
+			ContentType: "image/png",
+			Inline:      true,
+		}}, nil
+	})
+
+	tests := map[string]struct {
+		path            string
+		header          http.Header
+		wantStatus      int
+		wantBody        string
+		wantType        string
+		wantDisposition string
+	}{
+		"full file": {
+			path:            "/files/report.csv",
+			wantStatus:      http.StatusOK,
+			wantBody:        "id,total\n1,42\n",
+			wantType:        "text/csv; charset=utf-8",
+			wantDisposition: `attachment; filename=report.csv`,
+		},
+		"range": {
+			path:       "/files/report.csv",
+			header:     http.Header{"Range": {"bytes=0-1"}},
+			wantStatus: http.StatusPartialContent,
+			wantBody:   "id",
+		},
+		"not modified": {
+			path:       "/files/report.csv",
+			header:     http.Header{"If-Modified-Since": {modTime.Format(http.TimeFormat)}},
+			wantStatus: http.StatusNotModified,
+		},
+		"missing": {
+			path:       "/files/absent.csv",
+			wantStatus: http.StatusNotFound,
+		},
+		"inline content": {
+			path:            "/avatar",
+			header:          http.Header{"Accept": {"image/png"}},
+			wantStatus:      http.StatusOK,
+			wantBody:        "PNG",
+			wantType:        "image/png",
+			wantDisposition: `inline; filename=avatar.png`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, http.NoBody)
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantStatus, w.Code, w.Body.String())
+			if tc.wantBody != "" {
+				assert.Equal(t, tc.wantBody, w.Body.String())
+			}
+			if tc.wantType != "" {
+				assert.Equal(t, tc.wantType, w.Header().Get("Content-Type"))
+			}
+			if tc.wantDisposition != "" {
+				assert.Equal(t, tc.wantDisposition, w.Header().Get("Content-Disposition"))
+			}
+		})
+	}
+}
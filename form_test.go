@@ -889,3 +889,138 @@ func TestForm_embedded_form_fields_bind(t *testing.T) {
 	assert.Equal(t, "hello", got.Title)
 	assert.Equal(t, "world", got.Note)
 }
+
+type formMetadata struct {
+	Name string `json:"name" minLength:"3"`
+	Size int    `json:"size" minimum:"1"`
+}
+
+type formJSONPartReq struct {
+	Metadata formMetadata   `form:"metadata" content:"application/json"`
+	File     api.FileUpload `form:"file"`
+}
+
+func TestForm_json_part(t *testing.T) {
+	t.Parallel()
+
+	type Resp struct {
+		Name string `json:"name"`
+		Size int    `json:"size"`
+	}
+
+	r := api.New()
+	api.Post(r, "/upload", func(_ context.Context, req *formJSONPartReq) (*api.Resp[Resp], error) {
+		return &api.Resp[Resp]{Body: Resp{Name: req.Metadata.Name, Size: req.Metadata.Size}}, nil
+	})
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	tests := map[string]struct {
+		write      func(w *multipart.Writer)
+		wantStatus int
+		wantName   string
+	}{
+		"value part": {
+			write: func(w *multipart.Writer) {
+				require.NoError(t, w.WriteField("metadata", `{"name":"report","size":3}`))
+			},
+			wantStatus: http.StatusOK,
+			wantName:   "report",
+		},
+		"file part": {
+			write: func(w *multipart.Writer) {
+				fw, err := w.CreateFormFile("metadata", "meta.json")
+				require.NoError(t, err)
+				_, err = fw.Write([]byte(`{"name":"from-file","size":1}`))
+				require.NoError(t, err)
+			},
+			wantStatus: http.StatusOK,
+			wantName:   "from-file",
+		},
+		"malformed json": {
+			write: func(w *multipart.Writer) {
+				require.NoError(t, w.WriteField("metadata", `{"name":`))
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		"constraint violation": {
+			write: func(w *multipart.Writer) {
+				require.NoError(t, w.WriteField("metadata", `{"name":"ab","size":0}`))
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			w := multipart.NewWriter(&buf)
+			tc.write(w)
+			require.NoError(t, w.Close())
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+"/upload", &buf)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", w.FormDataContentType())
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer func() { require.NoError(t, resp.Body.Close()) }()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var got Resp
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+			assert.Equal(t, tc.wantName, got.Name)
+		})
+	}
+}
+
+func TestForm_json_part_violation_paths(t *testing.T) {
+	t.Parallel()
+
+	err := api.ValidateConstraints(&formJSONPartReq{Metadata: formMetadata{Name: "ab", Size: 5}})
+	var ve api.ValidationErrors
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve, 1)
+	assert.Equal(t, "metadata.name", ve[0].Field)
+}
+
+func TestForm_json_part_openapi(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Post(r, "/upload", func(_ context.Context, _ *formJSONPartReq) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	media := r.Spec().Paths["/upload"]["post"].RequestBody.Content["multipart/form-data"]
+	require.NotNil(t, media.Schema)
+
+	meta := media.Schema.Properties["metadata"]
+	assert.Equal(t, "object", meta.Type)
+	assert.Contains(t, meta.Properties, "name")
+
+	require.Contains(t, media.Encoding, "metadata")
+	assert.Equal(t, "application/json", media.Encoding["metadata"].ContentType)
+	assert.NotContains(t, media.Encoding, "file")
+}
+
+func TestForm_content_tag_on_file_rejected(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		File api.FileUpload `form:"file" content:"application/json"`
+	}
+
+	r := api.New()
+	assert.Panics(t, func() {
+		api.Post(r, "/upload", func(_ context.Context, _ *Req) (*api.Void, error) {
+			return &api.Void{}, nil
+		})
+	})
+}
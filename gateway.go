@@ -0,0 +1,489 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Gateway exporters derive edge configuration from the registered routes so
+// the gateway and the service cannot drift apart. Each route contributes its
+// method and pattern, the limit set via WithRateLimit and WithBodyLimit, and
+// its effective security requirements. Routes bound to a virtual host with
+// Router.Host match only that host.
+
+// patternParam matches a mux wildcard: {name}, {name...}, {$}, or a
+// constrained {name:re}.
+var patternParam = regexp.MustCompile(`\{([^}]*)\}`)
+
+// patternRegex converts a mux pattern into an anchored regular expression.
+// param renders a single-segment or, when rest is true, a multi-segment
+// wildcard.
+func patternRegex(pattern string, param func(name string, rest bool) string) string {
+	var b strings.Builder
+	b.WriteByte('^')
+	last := 0
+	for _, m := range patternParam.FindAllStringSubmatchIndex(pattern, -1) {
+		b.WriteString(regexp.QuoteMeta(pattern[last:m[0]]))
+		name, _, _ := strings.Cut(pattern[m[2]:m[3]], ":")
+		if name != "$" {
+			rest := strings.HasSuffix(name, "...")
+			b.WriteString(param(strings.TrimSuffix(name, "..."), rest))
+		}
+		last = m[1]
+	}
+	b.WriteString(regexp.QuoteMeta(pattern[last:]))
+	b.WriteByte('$')
+	return b.String()
+}
+
+func hasPatternParams(pattern string) bool {
+	return patternParam.MatchString(pattern)
+}
+
+// gatewayName names d's gateway route after its operation ID, prefixed by
+// its host so a route registered on several hosts gets distinct names.
+func gatewayName(d RouteDescription) string {
+	if d.Host == "" {
+		return d.OperationID
+	}
+	return gatewayHostName(d.Host) + "_" + d.OperationID
+}
+
+// gatewayHostName renders host with only the characters gateway names
+// allow.
+func gatewayHostName(host string) string {
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '-':
+			return c
+		}
+		return '-'
+	}, host)
+}
+
+// --- Kong ---
+
+// KongConfig configures WriteKongConfig.
+type KongConfig struct {
+	// Service is the Kong service name. Defaults to "api".
+	Service string
+
+	// Upstream is the URL Kong proxies to, e.g. "http://users:8080".
+	Upstream string
+
+	// AuthPlugins maps security scheme names to the Kong plugin that
+	// enforces them, e.g. {"bearer": "jwt", "apiKey": "key-auth"}. Routes
+	// requiring a mapped scheme get that plugin enabled.
+	AuthPlugins map[string]string
+}
+
+type kongFile struct {
+	FormatVersion string        `yaml:"_format_version"`
+	Services      []kongService `yaml:"services"`
+}
+
+type kongService struct {
+	Name   string      `yaml:"name"`
+	URL    string      `yaml:"url"`
+	Routes []kongRoute `yaml:"routes"`
+}
+
+type kongRoute struct {
+	Name      string       `yaml:"name"`
+	Methods   []string     `yaml:"methods"`
+	Hosts     []string     `yaml:"hosts,omitempty"`
+	Paths     []string     `yaml:"paths"`
+	StripPath bool         `yaml:"strip_path"`
+	Tags      []string     `yaml:"tags,omitempty"`
+	Plugins   []kongPlugin `yaml:"plugins,omitempty"`
+}
+
+type kongPlugin struct {
+	Name   string         `yaml:"name"`
+	Config map[string]any `yaml:"config,omitempty"`
+}
+
+// WriteKongConfig writes a Kong declarative configuration (format 3.0) with
+// one route per registered operation. Rate limits become rate-limiting
+// plugins, body limits become request-size-limiting plugins, and security
+// schemes listed in cfg.AuthPlugins enable the mapped auth plugin. Routes
+// bound to a host match only that host, and take precedence in Kong over
+// routes serving every host.
+func (r *Router) WriteKongConfig(w io.Writer, cfg KongConfig) error {
+	if cfg.Upstream == "" {
+		return errors.New("api: WriteKongConfig requires an Upstream")
+	}
+	if cfg.Service == "" {
+		cfg.Service = "api"
+	}
+
+	svc := kongService{Name: cfg.Service, URL: cfg.Upstream, Routes: []kongRoute{}}
+	for _, d := range r.Routes() {
+		kr := kongRoute{
+			Name:    gatewayName(d),
+			Methods: []string{d.Method},
+			Paths: []string{"~" + patternRegex(d.Pattern, func(name string, rest bool) string {
+				if rest {
+					return "(?<" + name + ">.*)"
+				}
+				return "(?<" + name + ">[^/]+)"
+			})},
+			Tags: d.Tags,
+		}
+		if d.Host != "" {
+			kr.Hosts = []string{d.Host}
+		}
+		if rl := d.RateLimit; rl != nil {
+			kr.Plugins = append(kr.Plugins, kongPlugin{Name: "rate-limiting", Config: kongRateLimit(rl.Rate)})
+		}
+		if d.BodyLimit > 0 {
+			kr.Plugins = append(kr.Plugins, kongPlugin{Name: "request-size-limiting", Config: map[string]any{
+				"allowed_payload_size": d.BodyLimit,
+				"size_unit":            "bytes",
+			}})
+		}
+		for _, scheme := range d.Security {
+			if plugin, ok := cfg.AuthPlugins[scheme]; ok {
+				kr.Plugins = append(kr.Plugins, kongPlugin{Name: plugin})
+			}
+		}
+		svc.Routes = append(svc.Routes, kr)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(kongFile{FormatVersion: "3.0", Services: []kongService{svc}}); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// kongRateLimit expresses rate (requests per second) in the coarsest
+// whole-number window Kong accepts.
+func kongRateLimit(rate float64) map[string]any {
+	cfg := map[string]any{"policy": "local"}
+	switch {
+	case rate >= 1:
+		cfg["second"] = int(math.Round(rate))
+	case rate*60 >= 1:
+		cfg["minute"] = int(math.Round(rate * 60))
+	default:
+		cfg["hour"] = max(1, int(math.Round(rate*3600)))
+	}
+	return cfg
+}
+
+// --- Envoy ---
+
+// EnvoyConfig configures WriteEnvoyRoutes.
+type EnvoyConfig struct {
+	// Name is the RouteConfiguration name. Defaults to "api".
+	Name string
+
+	// Cluster is the upstream cluster every route forwards to.
+	Cluster string
+
+	// Domains are the domains of the virtual host serving the routes not
+	// bound to a host. Defaults to ["*"].
+	Domains []string
+
+	// JWTRequirements maps security scheme names to requirement names in
+	// the listener's jwt_authn filter. Routes requiring a mapped scheme
+	// select that requirement; when any mapping is set, public routes
+	// disable the filter.
+	JWTRequirements map[string]string
+}
+
+type envoyRouteConfig struct {
+	Name         string             `yaml:"name"`
+	VirtualHosts []envoyVirtualHost `yaml:"virtual_hosts"`
+}
+
+type envoyVirtualHost struct {
+	Name    string       `yaml:"name"`
+	Domains []string     `yaml:"domains"`
+	Routes  []envoyRoute `yaml:"routes"`
+}
+
+type envoyRoute struct {
+	Name                 string         `yaml:"name"`
+	Match                envoyMatch     `yaml:"match"`
+	Route                envoyAction    `yaml:"route"`
+	TypedPerFilterConfig map[string]any `yaml:"typed_per_filter_config,omitempty"`
+}
+
+type envoyMatch struct {
+	Path      string             `yaml:"path,omitempty"`
+	SafeRegex *envoyRegex        `yaml:"safe_regex,omitempty"`
+	Headers   []envoyHeaderMatch `yaml:"headers"`
+}
+
+type envoyRegex struct {
+	Regex string `yaml:"regex"`
+}
+
+type envoyHeaderMatch struct {
+	Name        string            `yaml:"name"`
+	StringMatch map[string]string `yaml:"string_match"`
+}
+
+type envoyAction struct {
+	Cluster string `yaml:"cluster"`
+}
+
+// WriteEnvoyRoutes writes an Envoy RouteConfiguration (v3) with one route
+// per registered operation, matched on path and :method. Rate limits become
+// per-route local_ratelimit filter configs and security requirements select
+// jwt_authn requirements per cfg.JWTRequirements. Each host of Router.Host
+// gets its own virtual host holding its routes followed by the routes
+// serving every host that it does not shadow.
+func (r *Router) WriteEnvoyRoutes(w io.Writer, cfg EnvoyConfig) error {
+	if cfg.Cluster == "" {
+		return errors.New("api: WriteEnvoyRoutes requires a Cluster")
+	}
+	if cfg.Name == "" {
+		cfg.Name = "api"
+	}
+	if len(cfg.Domains) == 0 {
+		cfg.Domains = []string{"*"}
+	}
+
+	routes := r.Routes()
+	vh := envoyVirtualHost{Name: cfg.Name, Domains: cfg.Domains, Routes: []envoyRoute{}}
+	var hosts []string
+	byHost := make(map[string][]envoyRoute)
+	own := make(map[string]bool)
+	for _, d := range routes {
+		if d.Host == "" {
+			vh.Routes = append(vh.Routes, envoyRouteFor(d, cfg))
+			continue
+		}
+		if _, ok := byHost[d.Host]; !ok {
+			hosts = append(hosts, d.Host)
+		}
+		byHost[d.Host] = append(byHost[d.Host], envoyRouteFor(d, cfg))
+		own[d.Host+" "+d.Method+" "+d.Pattern] = true
+	}
+	vhosts := []envoyVirtualHost{vh}
+	for _, host := range hosts {
+		hvh := envoyVirtualHost{Name: cfg.Name + "-" + gatewayHostName(host), Domains: envoyDomains(host), Routes: byHost[host]}
+		for _, d := range routes {
+			if d.Host == "" && !own[host+" "+d.Method+" "+d.Pattern] {
+				hvh.Routes = append(hvh.Routes, envoyRouteFor(d, cfg))
+			}
+		}
+		vhosts = append(vhosts, hvh)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(envoyRouteConfig{Name: cfg.Name, VirtualHosts: vhosts}); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// envoyRouteFor builds the Envoy route for d.
+func envoyRouteFor(d RouteDescription, cfg EnvoyConfig) envoyRoute {
+	er := envoyRoute{
+		Name:  gatewayName(d),
+		Route: envoyAction{Cluster: cfg.Cluster},
+	}
+	er.Match.Headers = []envoyHeaderMatch{{Name: ":method", StringMatch: map[string]string{"exact": d.Method}}}
+	if hasPatternParams(d.Pattern) {
+		er.Match.SafeRegex = &envoyRegex{Regex: patternRegex(d.Pattern, func(_ string, rest bool) string {
+			if rest {
+				return ".*"
+			}
+			return "[^/]+"
+		})}
+	} else {
+		er.Match.Path = d.Pattern
+	}
+
+	filters := map[string]any{}
+	if rl := d.RateLimit; rl != nil {
+		filters["envoy.filters.http.local_ratelimit"] = envoyLocalRateLimit(rl)
+	}
+	if len(cfg.JWTRequirements) > 0 {
+		filters["envoy.filters.http.jwt_authn"] = envoyJWTPerRoute(d, cfg.JWTRequirements)
+	}
+	if len(filters) > 0 {
+		er.TypedPerFilterConfig = filters
+	}
+	return er
+}
+
+// envoyDomains returns the virtual host domains matching host as the mux
+// does: exactly, and on any port when host has none.
+func envoyDomains(host string) []string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return []string{host}
+	}
+	return []string{host, host + ":*"}
+}
+
+// envoyLocalRateLimit builds a token bucket refilling at rl.Rate.
+func envoyLocalRateLimit(rl *RouteRateLimit) map[string]any {
+	tokens, interval := 1, 1/rl.Rate
+	if rl.Rate >= 1 {
+		tokens, interval = int(math.Round(rl.Rate)), 1
+	}
+	enabled := map[string]any{
+		"runtime_key":   "local_rate_limit_enabled",
+		"default_value": map[string]any{"numerator": 100, "denominator": "HUNDRED"},
+	}
+	return map[string]any{
+		"@type":       "type.googleapis.com/envoy.extensions.filters.http.local_ratelimit.v3.LocalRateLimit",
+		"stat_prefix": "http_local_rate_limiter",
+		"token_bucket": map[string]any{
+			"max_tokens":      max(rl.Burst, tokens),
+			"tokens_per_fill": tokens,
+			"fill_interval":   strconv.FormatFloat(interval, 'f', -1, 64) + "s",
+		},
+		"filter_enabled":  enabled,
+		"filter_enforced": enabled,
+	}
+}
+
+func envoyJWTPerRoute(d RouteDescription, requirements map[string]string) map[string]any {
+	cfg := map[string]any{
+		"@type": "type.googleapis.com/envoy.extensions.filters.http.jwt_authn.v3.PerRouteConfig",
+	}
+	for _, scheme := range d.Security {
+		if name, ok := requirements[scheme]; ok {
+			cfg["requirement_name"] = name
+			return cfg
+		}
+	}
+	if len(d.Security) == 0 {
+		cfg["disabled"] = true
+	}
+	return cfg
+}
+
+// --- AWS API Gateway ---
+
+// AWSGatewayConfig configures WriteAWSGatewaySpec.
+type AWSGatewayConfig struct {
+	// BaseURI is the backend URL prefix each path is appended to, e.g.
+	// "https://internal.example.com" or "http://nlb.internal" with a VPC
+	// link.
+	BaseURI string
+
+	// VPCLinkID routes integrations through a VPC link when set.
+	VPCLinkID string
+
+	// Authorizers maps security scheme names to the
+	// x-amazon-apigateway-authorizer object attached to that scheme.
+	Authorizers map[string]map[string]any
+
+	// Host selects the routes exported, as HostSpec does: the routes bound
+	// to it with Router.Host plus the routes serving every host. Empty
+	// exports only the routes serving every host. Map each host's API to
+	// its custom domain.
+	Host string
+}
+
+// WriteAWSGatewaySpec writes the OpenAPI spec as JSON with the API Gateway
+// extensions needed to import it: an http_proxy integration on every
+// operation forwarding path parameters, and the configured authorizers on
+// their security schemes. API Gateway has no import-time extension for
+// per-method throttling; apply the limits reported by Routes through a
+// usage plan or stage method settings.
+func (r *Router) WriteAWSGatewaySpec(w io.Writer, cfg AWSGatewayConfig) error {
+	if cfg.BaseURI == "" {
+		return errors.New("api: WriteAWSGatewaySpec requires a BaseURI")
+	}
+
+	// Round-trip through JSON so extensions can sit beside the standard
+	// fields, as API Gateway requires.
+	raw, err := json.Marshal(r.HostSpec(cfg.Host))
+	if err != nil {
+		return err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+
+	paths, _ := doc["paths"].(map[string]any) //nolint:errcheck // always an object
+	awsPaths := make(map[string]any, len(paths))
+	for _, d := range r.Routes() {
+		if d.Host != "" && d.Host != cfg.Host {
+			continue
+		}
+		path := toOpenAPIPath(d.Pattern)
+		item, _ := paths[path].(map[string]any) //nolint:errcheck // always an object
+		op, _ := item[strings.ToLower(d.Method)].(map[string]any)
+		if op == nil {
+			continue
+		}
+		op["x-amazon-apigateway-integration"] = awsIntegration(d, cfg)
+
+		// API Gateway spells greedy path parameters {name+}.
+		awsPath := patternParam.ReplaceAllStringFunc(d.Pattern, func(m string) string {
+			if m == "{$}" {
+				return ""
+			}
+			if name, ok := strings.CutSuffix(m, "...}"); ok {
+				return name + "+}"
+			}
+			return m
+		})
+		awsPaths[awsPath] = item
+	}
+	doc["paths"] = awsPaths
+
+	if len(cfg.Authorizers) > 0 {
+		comps, _ := doc["components"].(map[string]any)          //nolint:errcheck // always an object
+		schemes, _ := comps["securitySchemes"].(map[string]any) //nolint:errcheck // absent when none registered
+		for name, authorizer := range cfg.Authorizers {
+			if scheme, ok := schemes[name].(map[string]any); ok {
+				scheme["x-amazon-apigateway-authorizer"] = authorizer
+			}
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func awsIntegration(d RouteDescription, cfg AWSGatewayConfig) map[string]any {
+	uri := strings.TrimSuffix(cfg.BaseURI, "/") + patternParam.ReplaceAllStringFunc(d.Pattern, func(m string) string {
+		if m == "{$}" {
+			return ""
+		}
+		return strings.Replace(m, "...}", "}", 1)
+	})
+
+	integration := map[string]any{
+		"type":                "http_proxy",
+		"httpMethod":          d.Method,
+		"uri":                 uri,
+		"passthroughBehavior": "when_no_match",
+	}
+	params := map[string]any{}
+	for _, m := range patternParam.FindAllStringSubmatch(d.Pattern, -1) {
+		if name := strings.TrimSuffix(m[1], "..."); name != "$" {
+			params["integration.request.path."+name] = "method.request.path." + name
+		}
+	}
+	if len(params) > 0 {
+		integration["requestParameters"] = params
+	}
+	if cfg.VPCLinkID != "" {
+		integration["connectionType"] = "VPC_LINK"
+		integration["connectionId"] = cfg.VPCLinkID
+	}
+	return integration
+}
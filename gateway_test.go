@@ -0,0 +1,312 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/bjaus/api"
+)
+
+func newGatewayRouter() *api.Router {
+	r := api.New(
+		api.WithSecurityScheme("bearer", api.SecurityScheme{Type: "http", Scheme: "bearer"}),
+	)
+	type GetReq struct {
+		ID string `path:"id"`
+	}
+	api.Get(r, "/users/{id}", func(_ context.Context, _ *GetReq) (*api.Void, error) {
+		return &api.Void{}, nil
+	}, api.WithOperationID("getUser"), api.WithSecurity("bearer"),
+		api.WithRateLimit(api.RateLimitConfig{Rate: 10, Burst: 20}))
+	api.Post(r, "/uploads", voidHandler, api.WithOperationID("upload"), api.WithBodyLimit(1<<20))
+	api.Get(r, "/files/{path...}", voidHandler, api.WithOperationID("getFile"),
+		api.WithRateLimit(api.RateLimitConfig{Rate: 0.5, Burst: 1}))
+	return r
+}
+
+func TestWithRateLimit(t *testing.T) {
+	t.Parallel()
+
+	r := newGatewayRouter()
+
+	var codes []int
+	for range 3 {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/files/a.txt", nil))
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []int{http.StatusNoContent, http.StatusTooManyRequests, http.StatusTooManyRequests}, codes)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/uploads", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code, "other routes keep their own limiter")
+
+	routes := r.Routes()
+	require.NotNil(t, routes[0].RateLimit)
+	assert.Equal(t, api.RouteRateLimit{Rate: 10, Burst: 20}, *routes[0].RateLimit)
+	assert.Equal(t, int64(1<<20), routes[1].BodyLimit)
+	assert.Nil(t, routes[1].RateLimit)
+}
+
+func TestWriteKongConfig(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := newGatewayRouter().WriteKongConfig(&buf, api.KongConfig{
+		Upstream:    "http://users:8080",
+		AuthPlugins: map[string]string{"bearer": "jwt"},
+	})
+	require.NoError(t, err)
+
+	var cfg struct {
+		FormatVersion string `yaml:"_format_version"`
+		Services      []struct {
+			Name   string `yaml:"name"`
+			URL    string `yaml:"url"`
+			Routes []struct {
+				Name    string   `yaml:"name"`
+				Methods []string `yaml:"methods"`
+				Paths   []string `yaml:"paths"`
+				Plugins []struct {
+					Name   string         `yaml:"name"`
+					Config map[string]any `yaml:"config"`
+				} `yaml:"plugins"`
+			} `yaml:"routes"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &cfg))
+
+	assert.Equal(t, "3.0", cfg.FormatVersion)
+	require.Len(t, cfg.Services, 1)
+	svc := cfg.Services[0]
+	assert.Equal(t, "api", svc.Name)
+	assert.Equal(t, "http://users:8080", svc.URL)
+	require.Len(t, svc.Routes, 3)
+
+	get := svc.Routes[0]
+	assert.Equal(t, "getUser", get.Name)
+	assert.Equal(t, []string{http.MethodGet}, get.Methods)
+	assert.Equal(t, []string{`~^/users/(?<id>[^/]+)$`}, get.Paths)
+	require.Len(t, get.Plugins, 2)
+	assert.Equal(t, "rate-limiting", get.Plugins[0].Name)
+	assert.Equal(t, 10, get.Plugins[0].Config["second"])
+	assert.Equal(t, "jwt", get.Plugins[1].Name)
+
+	upload := svc.Routes[1]
+	require.Len(t, upload.Plugins, 1)
+	assert.Equal(t, "request-size-limiting", upload.Plugins[0].Name)
+	assert.Equal(t, 1<<20, upload.Plugins[0].Config["allowed_payload_size"])
+
+	file := svc.Routes[2]
+	assert.Equal(t, []string{`~^/files/(?<path>.*)$`}, file.Paths)
+	assert.Equal(t, 30, file.Plugins[0].Config["minute"])
+
+	require.Error(t, api.New().WriteKongConfig(&buf, api.KongConfig{}))
+}
+
+func TestWriteEnvoyRoutes(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := newGatewayRouter().WriteEnvoyRoutes(&buf, api.EnvoyConfig{
+		Cluster:         "users",
+		JWTRequirements: map[string]string{"bearer": "users_jwt"},
+	})
+	require.NoError(t, err)
+
+	var cfg struct {
+		Name         string `yaml:"name"`
+		VirtualHosts []struct {
+			Domains []string `yaml:"domains"`
+			Routes  []struct {
+				Name  string `yaml:"name"`
+				Match struct {
+					Path      string `yaml:"path"`
+					SafeRegex struct {
+						Regex string `yaml:"regex"`
+					} `yaml:"safe_regex"`
+					Headers []struct {
+						Name        string            `yaml:"name"`
+						StringMatch map[string]string `yaml:"string_match"`
+					} `yaml:"headers"`
+				} `yaml:"match"`
+				Route struct {
+					Cluster string `yaml:"cluster"`
+				} `yaml:"route"`
+				TypedPerFilterConfig map[string]map[string]any `yaml:"typed_per_filter_config"`
+			} `yaml:"routes"`
+		} `yaml:"virtual_hosts"`
+	}
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &cfg))
+
+	assert.Equal(t, "api", cfg.Name)
+	require.Len(t, cfg.VirtualHosts, 1)
+	vh := cfg.VirtualHosts[0]
+	assert.Equal(t, []string{"*"}, vh.Domains)
+	require.Len(t, vh.Routes, 3)
+
+	get := vh.Routes[0]
+	assert.Equal(t, "users", get.Route.Cluster)
+	assert.Equal(t, `^/users/[^/]+$`, get.Match.SafeRegex.Regex)
+	assert.Regexp(t, regexp.MustCompile(get.Match.SafeRegex.Regex), "/users/42")
+	assert.Equal(t, http.MethodGet, get.Match.Headers[0].StringMatch["exact"])
+	bucket, ok := get.TypedPerFilterConfig["envoy.filters.http.local_ratelimit"]["token_bucket"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, 20, bucket["max_tokens"])
+	assert.Equal(t, 10, bucket["tokens_per_fill"])
+	assert.Equal(t, "1s", bucket["fill_interval"])
+	assert.Equal(t, "users_jwt", get.TypedPerFilterConfig["envoy.filters.http.jwt_authn"]["requirement_name"])
+
+	upload := vh.Routes[1]
+	assert.Equal(t, "/uploads", upload.Match.Path)
+	assert.Equal(t, true, upload.TypedPerFilterConfig["envoy.filters.http.jwt_authn"]["disabled"])
+
+	file := vh.Routes[2]
+	bucket, ok = file.TypedPerFilterConfig["envoy.filters.http.local_ratelimit"]["token_bucket"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "2s", bucket["fill_interval"])
+}
+
+func TestWriteAWSGatewaySpec(t *testing.T) {
+	t.Parallel()
+
+	authorizer := map[string]any{"type": "token", "authorizerUri": "arn:aws:apigateway:lambda"}
+
+	var buf bytes.Buffer
+	err := newGatewayRouter().WriteAWSGatewaySpec(&buf, api.AWSGatewayConfig{
+		BaseURI:     "http://nlb.internal/",
+		VPCLinkID:   "abc123",
+		Authorizers: map[string]map[string]any{"bearer": authorizer},
+	})
+	require.NoError(t, err)
+
+	var doc struct {
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			SecuritySchemes map[string]map[string]any `json:"securitySchemes"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	get := doc.Paths["/users/{id}"]["get"]["x-amazon-apigateway-integration"]
+	assert.Equal(t, map[string]any{
+		"type":                "http_proxy",
+		"httpMethod":          "GET",
+		"uri":                 "http://nlb.internal/users/{id}",
+		"passthroughBehavior": "when_no_match",
+		"requestParameters":   map[string]any{"integration.request.path.id": "method.request.path.id"},
+		"connectionType":      "VPC_LINK",
+		"connectionId":        "abc123",
+	}, get)
+
+	require.Contains(t, doc.Paths, "/files/{path+}")
+	file, ok := doc.Paths["/files/{path+}"]["get"]["x-amazon-apigateway-integration"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "http://nlb.internal/files/{path}", file["uri"])
+
+	assert.Equal(t, authorizer, doc.Components.SecuritySchemes["bearer"]["x-amazon-apigateway-authorizer"])
+}
+
+func TestGatewayExporters_hosts(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/users", voidHandler, api.WithOperationID("listUsers"))
+	api.Get(r, "/health", voidHandler, api.WithOperationID("health"))
+	admin := r.Host("admin.example.com")
+	api.Get(admin, "/users", voidHandler, api.WithOperationID("listUsers"))
+	api.Get(admin, "/audit", voidHandler, api.WithOperationID("audit"))
+
+	t.Run("kong", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, r.WriteKongConfig(&buf, api.KongConfig{Upstream: "http://users:8080"}))
+
+		var cfg struct {
+			Services []struct {
+				Routes []struct {
+					Name  string   `yaml:"name"`
+					Hosts []string `yaml:"hosts"`
+				} `yaml:"routes"`
+			} `yaml:"services"`
+		}
+		require.NoError(t, yaml.Unmarshal(buf.Bytes(), &cfg))
+		routes := cfg.Services[0].Routes
+		require.Len(t, routes, 4)
+		assert.Equal(t, "listUsers", routes[0].Name)
+		assert.Empty(t, routes[0].Hosts)
+		assert.Equal(t, "admin.example.com_listUsers", routes[2].Name)
+		assert.Equal(t, []string{"admin.example.com"}, routes[2].Hosts)
+	})
+
+	t.Run("envoy", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, r.WriteEnvoyRoutes(&buf, api.EnvoyConfig{Cluster: "users"}))
+
+		var cfg struct {
+			VirtualHosts []struct {
+				Name    string   `yaml:"name"`
+				Domains []string `yaml:"domains"`
+				Routes  []struct {
+					Name string `yaml:"name"`
+				} `yaml:"routes"`
+			} `yaml:"virtual_hosts"`
+		}
+		require.NoError(t, yaml.Unmarshal(buf.Bytes(), &cfg))
+		require.Len(t, cfg.VirtualHosts, 2)
+
+		names := func(i int) []string {
+			var out []string
+			for _, route := range cfg.VirtualHosts[i].Routes {
+				out = append(out, route.Name)
+			}
+			return out
+		}
+		assert.Equal(t, []string{"*"}, cfg.VirtualHosts[0].Domains)
+		assert.Equal(t, []string{"listUsers", "health"}, names(0))
+		assert.Equal(t, "api-admin.example.com", cfg.VirtualHosts[1].Name)
+		assert.Equal(t, []string{"admin.example.com", "admin.example.com:*"}, cfg.VirtualHosts[1].Domains)
+		assert.Equal(t, []string{"admin.example.com_listUsers", "admin.example.com_audit", "health"}, names(1))
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		t.Parallel()
+
+		tests := map[string]struct {
+			host  string
+			paths []string
+		}{
+			"every host": {paths: []string{"/health", "/users"}},
+			"admin host": {host: "admin.example.com", paths: []string{"/audit", "/health", "/users"}},
+		}
+
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				var buf bytes.Buffer
+				require.NoError(t, r.WriteAWSGatewaySpec(&buf, api.AWSGatewayConfig{BaseURI: "http://nlb.internal", Host: tc.host}))
+
+				var doc struct {
+					Paths map[string]any `json:"paths"`
+				}
+				require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+				assert.ElementsMatch(t, tc.paths, slices.Collect(maps.Keys(doc.Paths)))
+			})
+		}
+	})
+}
@@ -1,16 +1,27 @@
 package api
 
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
 // Group is a collection of routes under a shared prefix with shared middleware and tags.
 // Groups can be nested: child groups inherit prefix, middleware, tags, and security
 // from their parent unless explicitly reset.
 type Group struct {
 	parent          Registrar
 	prefix          string
+	host            string
 	middleware      []Middleware
 	tags            []string
 	security        []string
 	resetMiddleware bool
 	errorOpts       []ErrorOption
+	policy          PolicyEngine
+	budget          *budgetLimits
 }
 
 // GroupOption configures a Group at construction time. Implement this
@@ -63,9 +74,96 @@ func (r *Router) Group(prefix string, opts ...GroupOption) *Group {
 	return newGroup(r, prefix, opts...)
 }
 
+// Host creates a group whose routes only match requests for host, so one
+// Router can serve a different route set per virtual host:
+//
+//	admin := r.Host("admin.example.com", api.WithGroupMiddleware(requireStaff))
+//	api.Get(admin, "/users", h.ListAllUsers)
+//
+// host may include a port. Routes registered on the Router itself still
+// serve every host, unless a host route claims the same method and path.
+// Nested groups inherit the host. Each host gets its own spec from
+// HostSpec, and ServeSpec serves the spec of the requested host.
+func (r *Router) Host(host string, opts ...GroupOption) *Group {
+	g := newGroup(r, "", opts...)
+	g.host = host
+	return g
+}
+
+// Mount adds the routes of sub under prefix, so per-domain routers can be
+// built separately and assembled at startup:
+//
+//	r.Mount("/billing", billing.Routes())
+//	r.Mount("/users", users.Routes())
+//
+// The routes join r's spec and route table with the prefix applied, and
+// sub's security schemes and tag descriptions are added where r has none
+// of the same name. Requests are served by sub, with the prefix stripped,
+// so sub's middleware, error handling, and other options still apply;
+// r's middleware runs first. Only the routes registered on sub before
+// Mount are added, and handlers sub registers outside the spec, such as
+// Static or ServeSpec, are not.
+func (r *Router) Mount(prefix string, sub *Router) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	handler := http.StripPrefix(prefix, sub)
+
+	sub.mu.Lock()
+	routes := slices.Clone(sub.routes)
+	security := slices.Clone(sub.security)
+	schemes := maps.Clone(sub.securitySchemes)
+	tagDescs := maps.Clone(sub.tagDescs)
+	tagDocs := maps.Clone(sub.tagDocs)
+	sub.mu.Unlock()
+
+	r.mu.Lock()
+	for name, scheme := range schemes {
+		if _, ok := r.securitySchemes[name]; !ok {
+			if r.securitySchemes == nil {
+				r.securitySchemes = make(map[string]SecurityScheme)
+			}
+			r.securitySchemes[name] = scheme
+		}
+	}
+	for name, desc := range tagDescs {
+		if _, ok := r.tagDescs[name]; !ok {
+			if r.tagDescs == nil {
+				r.tagDescs = make(map[string]string)
+			}
+			r.tagDescs[name] = desc
+		}
+	}
+	for name, docs := range tagDocs {
+		if _, ok := r.tagDocs[name]; !ok {
+			if r.tagDocs == nil {
+				r.tagDocs = make(map[string]*ExternalDocs)
+			}
+			r.tagDocs[name] = docs
+		}
+	}
+	r.mu.Unlock()
+
+	for _, ri := range routes {
+		ri.pattern = prefix + ri.pattern
+		ri.handler = handler
+		// sub's default security applies to the routes it covered.
+		if len(ri.security) == 0 && !ri.noSecurity && len(security) > 0 {
+			ri.security = security
+		}
+		// The description belongs to sub's copy of the route, and sub
+		// puts itself in the context of the requests it serves.
+		ri.meta = nil
+		ri.attachesRouter = true
+		r.addRoute(ri)
+	}
+}
+
 // Group creates a nested route group. The child's prefix is concatenated onto
 // the parent's; tags, middleware (unless reset), and security (when child has
 // none) inherit from the parent.
+//
+//	v1 := r.Group("/v1", api.WithGroupTags("v1"), api.WithGroupSecurity("bearerAuth"))
+//	tenant := v1.Group("/tenants/{tenant}", api.WithGroupMiddleware(loadTenant))
+//	api.Get(tenant.Group("/users", api.WithGroupTags("users")), "", h.ListUsers)
 func (g *Group) Group(prefix string, opts ...GroupOption) *Group {
 	return newGroup(g, prefix, opts...)
 }
@@ -86,6 +184,9 @@ func newGroup(parent Registrar, prefix string, opts ...GroupOption) *Group {
 // compose correctly.
 func (g *Group) addRoute(ri routeInfo) {
 	ri.pattern = g.prefix + ri.pattern
+	if g.host != "" {
+		ri.host = g.host
+	}
 	ri.tags = append(append([]string{}, g.tags...), ri.tags...)
 	if len(g.security) > 0 && len(ri.security) == 0 && !ri.noSecurity {
 		ri.security = append([]string{}, g.security...)
@@ -93,11 +194,48 @@ func (g *Group) addRoute(ri routeInfo) {
 	g.parent.addRoute(ri)
 }
 
-func (g *Group) getValidator() ValidatorFunc   { return g.parent.getValidator() }
-func (g *Group) getErrorHandler() ErrorHandler { return g.parent.getErrorHandler() }
-func (g *Group) getMode() ValidationMode       { return g.parent.getMode() }
-func (g *Group) getCodecs() *codecRegistry     { return g.parent.getCodecs() }
-func (g *Group) getValidateResponses() bool    { return g.parent.getValidateResponses() }
+func (g *Group) getValidator() ValidatorFunc         { return g.parent.getValidator() }
+func (g *Group) getErrorHandler() ErrorHandler       { return g.parent.getErrorHandler() }
+func (g *Group) getMode() ValidationMode             { return g.parent.getMode() }
+func (g *Group) getCodecs() *codecRegistry           { return g.parent.getCodecs() }
+func (g *Group) getValidateResponses() bool          { return g.parent.getValidateResponses() }
+func (g *Group) getRequiredParams() bool             { return g.parent.getRequiredParams() }
+func (g *Group) getPathMismatch() Code               { return g.parent.getPathMismatch() }
+func (g *Group) getSecureCookies() *SecureCookies    { return g.parent.getSecureCookies() }
+func (g *Group) getCursorKey() []byte                { return g.parent.getCursorKey() }
+func (g *Group) getCookieDefaults() *CookieDefaults  { return g.parent.getCookieDefaults() }
+func (g *Group) getRedactionPolicy() RedactionPolicy { return g.parent.getRedactionPolicy() }
+func (g *Group) getFieldScopes() ScopePolicy         { return g.parent.getFieldScopes() }
+func (g *Group) getBodyDefaults() bool               { return g.parent.getBodyDefaults() }
+func (g *Group) getPrefix() string                   { return g.parent.getPrefix() + g.prefix }
+func (g *Group) getMatcher() Matcher                 { return g.parent.getMatcher() }
+
+// getPolicy returns the group's own policy, falling back to the parent's.
+func (g *Group) getPolicy() PolicyEngine {
+	if g.policy != nil {
+		return g.policy
+	}
+	return g.parent.getPolicy()
+}
+
+// getBudget returns the group's own budget limits, falling back to the
+// parent's.
+func (g *Group) getBudget() *budgetLimits {
+	if g.budget != nil {
+		return g.budget
+	}
+	return g.parent.getBudget()
+}
+
+func (g *Group) getBudgetObserver() func(context.Context, BudgetViolation) {
+	return g.parent.getBudgetObserver()
+}
+
+func (g *Group) getRouter() *Router { return g.parent.getRouter() }
+
+func (g *Group) getPayloadObserver() func(context.Context, PayloadMetrics) {
+	return g.parent.getPayloadObserver()
+}
 
 // errorOptionChain returns the parent's chain followed by this group's
 // own error options. Outer scopes come first so later scopes can
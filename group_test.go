@@ -321,3 +321,147 @@ func TestGroup_nested_security_child_replaces(t *testing.T) {
 	assert.Contains(t, (*op.Security)[0], "apiKey")
 	assert.NotContains(t, (*op.Security)[0], "bearerAuth")
 }
+
+func TestRouter_Host(t *testing.T) {
+	t.Parallel()
+
+	type Resp struct {
+		Site string `json:"site"`
+	}
+	site := func(name string) api.Handler[api.Void, api.Resp[Resp]] {
+		return func(_ context.Context, _ *api.Void) (*api.Resp[Resp], error) {
+			return &api.Resp[Resp]{Body: Resp{Site: name}}, nil
+		}
+	}
+
+	var adminMW int
+	r := api.New()
+	api.Get(r, "/users", site("public"))
+	api.Get(r, "/health", site("any"))
+	admin := r.Host("admin.example.com", api.WithGroupMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			adminMW++
+			next.ServeHTTP(w, req)
+		})
+	}))
+	api.Get(admin, "/users", site("admin"))
+	api.Delete(admin.Group("/users"), "/{id}", voidHandler)
+
+	tests := map[string]struct {
+		method   string
+		host     string
+		path     string
+		wantCode int
+		wantSite string
+	}{
+		"admin host":           {method: http.MethodGet, host: "admin.example.com", path: "/users", wantCode: http.StatusOK, wantSite: "admin"},
+		"admin host with port": {method: http.MethodGet, host: "admin.example.com:8080", path: "/users", wantCode: http.StatusOK, wantSite: "admin"},
+		"other host":           {method: http.MethodGet, host: "www.example.com", path: "/users", wantCode: http.StatusOK, wantSite: "public"},
+		"shared route":         {method: http.MethodGet, host: "admin.example.com", path: "/health", wantCode: http.StatusOK, wantSite: "any"},
+		"nested group":         {method: http.MethodDelete, host: "admin.example.com", path: "/users/7", wantCode: http.StatusNoContent},
+		"nested on other host": {method: http.MethodDelete, host: "www.example.com", path: "/users/7", wantCode: http.StatusNotFound},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(context.Background(), tc.method, tc.path, nil)
+			req.Host = tc.host
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantCode, rec.Code)
+			if tc.wantSite != "" {
+				var got Resp
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+				assert.Equal(t, tc.wantSite, got.Site)
+			}
+		})
+	}
+	assert.Equal(t, 3, adminMW)
+}
+
+func TestRouter_HostSpec(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/users", voidHandler, api.WithOperationID("listPublicUsers"))
+	api.Get(r, "/health", voidHandler)
+	admin := r.Host("admin.example.com")
+	api.Get(admin, "/users", voidHandler, api.WithOperationID("listAllUsers"))
+	api.Post(admin, "/users", voidHandler)
+	r.ServeSpec("/openapi.json")
+
+	spec := r.Spec()
+	assert.Equal(t, "listPublicUsers", spec.Paths["/users"]["get"].OperationID)
+	assert.NotContains(t, spec.Paths["/users"], "post")
+
+	adminSpec := r.HostSpec("admin.example.com")
+	assert.Equal(t, "listAllUsers", adminSpec.Paths["/users"]["get"].OperationID)
+	assert.Contains(t, adminSpec.Paths["/users"], "post")
+	assert.Contains(t, adminSpec.Paths, "/health")
+
+	served := func(host string) api.OpenAPISpec {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/openapi.json", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		var spec api.OpenAPISpec
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+		return spec
+	}
+	assert.Equal(t, "listAllUsers", served("admin.example.com:443").Paths["/users"]["get"].OperationID)
+	assert.Equal(t, "listPublicUsers", served("www.example.com").Paths["/users"]["get"].OperationID)
+
+	var hosts []string
+	for _, d := range r.Routes() {
+		hosts = append(hosts, d.Host)
+	}
+	assert.Equal(t, []string{"", "", "admin.example.com", "admin.example.com"}, hosts)
+}
+
+func TestRouter_Mount(t *testing.T) {
+	t.Parallel()
+
+	type invoiceReq struct {
+		ID string `path:"id"`
+	}
+	billing := api.New(
+		api.WithSecurityScheme("apiKey", api.SecurityScheme{Type: "apiKey", In: "header", Name: "X-API-Key"}),
+		api.WithGlobalSecurity("apiKey"),
+		api.WithTagDescriptions(map[string]string{"invoices": "Invoice management"}),
+	)
+	billing.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Domain", "billing")
+			next.ServeHTTP(w, r)
+		})
+	})
+	api.Get(billing, "/invoices/{id}", func(_ context.Context, req *invoiceReq) (*api.Resp[string], error) {
+		return &api.Resp[string]{Body: "invoice " + req.ID}, nil
+	}, api.WithTags("invoices"))
+
+	r := api.New(api.WithTitle("Platform"))
+	api.Get(r, "/health", func(_ context.Context, _ *api.Void) (*api.Resp[string], error) {
+		return &api.Resp[string]{Body: "ok"}, nil
+	})
+	r.Mount("/billing/", billing)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/billing/invoices/7", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `"invoice 7"`, w.Body.String())
+	assert.Equal(t, "billing", w.Header().Get("X-Domain"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/invoices/7", http.NoBody))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	spec := r.Spec()
+	require.Contains(t, spec.Paths, "/billing/invoices/{id}")
+	op := spec.Paths["/billing/invoices/{id}"]["get"]
+	require.NotNil(t, op.Security)
+	assert.Contains(t, (*op.Security)[0], "apiKey")
+	assert.Contains(t, spec.Components.SecuritySchemes, "apiKey")
+	assert.Contains(t, spec.Paths, "/health")
+	assert.Contains(t, spec.Tags, api.TagObj{Name: "invoices", Description: "Invoice management"})
+}
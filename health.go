@@ -0,0 +1,221 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// OnStart registers a hook that ListenAndServe and ListenAndServeTLS run,
+// in registration order, before they start listening: open connection
+// pools, warm caches, and so on. The first error aborts startup and is
+// returned from ListenAndServe.
+func (r *Router) OnStart(fn func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onStart = append(r.onStart, fn)
+}
+
+// OnShutdown registers a hook that runs after the server has shut down and
+// in-flight requests have finished: flush buffers, close pools. Hooks also
+// run when the server fails to listen after the OnStart hooks succeeded.
+// Hooks run in reverse registration order, like deferred calls, under
+// their own shutdown deadline; see WithShutdownTimeout. Their errors are
+// joined into ListenAndServe's result.
+func (r *Router) OnShutdown(fn func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onShutdown = append(r.onShutdown, fn)
+}
+
+// WithDrainDelay keeps serving for d after shutdown begins, with readiness
+// already reporting 503. This gives load balancers, such as Kubernetes
+// endpoints, time to stop sending new traffic before the listener closes.
+// Set it a little above the readiness probe's period.
+func WithDrainDelay(d time.Duration) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.drainDelay = d
+	})
+}
+
+// defaultShutdownTimeout bounds each shutdown phase when
+// WithShutdownTimeout is not set.
+const defaultShutdownTimeout = 30 * time.Second
+
+// WithShutdownTimeout bounds each phase of a graceful shutdown separately:
+// the drain delay, waiting for in-flight requests to finish, and the
+// OnShutdown hooks. A slow phase then cannot eat into the next one's time.
+// Defaults to 30s. Size it with the platform's termination grace period in
+// mind, such as Kubernetes' terminationGracePeriodSeconds.
+func WithShutdownTimeout(d time.Duration) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.shutdownTimeout = d
+	})
+}
+
+// ErrShuttingDown is the cause of the contexts cancelled when the router
+// shuts down; see ShuttingDown and WithStreamGrace.
+var ErrShuttingDown = errors.New("server shutting down")
+
+// WithStreamGrace gives streaming responses d to finish on their own once
+// shutdown begins, after the drain delay. Handlers learn of the shutdown
+// from ShuttingDown. When d has passed, the router ends the remaining
+// server-sent event streams with a final "shutdown" event, so clients
+// reconnect to another instance rather than seeing the connection cut,
+// and ends the remaining JSON array and NDJSON streams. Without it,
+// streams end as soon as shutdown begins. Keep d below the shutdown
+// timeout; see WithShutdownTimeout.
+func WithStreamGrace(d time.Duration) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.streamGrace = d
+	})
+}
+
+// ShuttingDown returns a channel that is closed when the router serving
+// ctx begins shutting down, for long-running handlers such as event
+// streams to wind down within the grace period. Outside a router it
+// returns nil, which never receives.
+func ShuttingDown(ctx context.Context) <-chan struct{} {
+	rt, ok := ctx.Value(routerKey{}).(*Router)
+	if !ok {
+		return nil
+	}
+	return rt.signals.Load().stopping.Done()
+}
+
+// Ready reports whether the router is accepting traffic: true until
+// ListenAndServe begins shutting down.
+func (r *Router) Ready() bool {
+	return !r.draining.Load()
+}
+
+// start runs the OnStart hooks.
+func (r *Router) start(ctx context.Context) error {
+	r.mu.Lock()
+	hooks := slices.Clone(r.onStart)
+	r.mu.Unlock()
+
+	for _, fn := range hooks {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drain marks the router not ready and waits out the drain delay.
+func (r *Router) drain(ctx context.Context) {
+	r.draining.Store(true)
+	if r.drainDelay <= 0 {
+		return
+	}
+	t := time.NewTimer(r.drainDelay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// shutdownSignals are the contexts cancelled as one serve shuts down:
+// stopping when shutdown begins, and streams when streaming responses must
+// end.
+type shutdownSignals struct {
+	stopping   context.Context
+	stop       context.CancelCauseFunc
+	streams    context.Context
+	endStreams context.CancelCauseFunc
+}
+
+func newShutdownSignals() *shutdownSignals {
+	s := &shutdownSignals{}
+	s.stopping, s.stop = context.WithCancelCause(context.Background())
+	s.streams, s.endStreams = context.WithCancelCause(context.Background())
+	return s
+}
+
+// stopStreams signals shutdown to handlers and ends streaming responses
+// once the grace period has passed.
+func (r *Router) stopStreams() {
+	s := r.signals.Load()
+	s.stop(ErrShuttingDown)
+	if r.streamGrace <= 0 {
+		s.endStreams(ErrShuttingDown)
+		return
+	}
+	time.AfterFunc(r.streamGrace, func() { s.endStreams(ErrShuttingDown) })
+}
+
+// streamContext returns ctx, cancelled with ErrShuttingDown when rt ends
+// streaming responses.
+func streamContext(ctx context.Context, rt *Router) (context.Context, context.CancelFunc) {
+	if rt == nil {
+		return ctx, func() {}
+	}
+	sctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(rt.signals.Load().streams, func() { cancel(ErrShuttingDown) })
+	return sctx, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
+// shutdownPhase runs one shutdown phase under its own deadline. ctx is
+// usually already done, so only its values are kept.
+func (r *Router) shutdownPhase(ctx context.Context, phase func(context.Context) error) error {
+	pctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cmp.Or(r.shutdownTimeout, defaultShutdownTimeout))
+	defer cancel()
+	return phase(pctx)
+}
+
+// shutdown runs the OnShutdown hooks, last registered first.
+func (r *Router) shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	hooks := slices.Clone(r.onShutdown)
+	r.mu.Unlock()
+
+	var errs []error
+	for _, fn := range slices.Backward(hooks) {
+		errs = append(errs, fn(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// HealthStatus is the body served by the HealthRoutes endpoints.
+type HealthStatus struct {
+	Status string `json:"status"`
+}
+
+// HealthRoutes registers the probes Kubernetes and most load balancers
+// expect:
+//
+//	GET /healthz  liveness: 200 while the process is serving
+//	GET /readyz   readiness: 200, or 503 once shutdown begins
+//
+// Like ServeSpec, the endpoints are not part of the spec. Pair them with
+// WithDrainDelay so the failing readiness probe is seen before the listener
+// closes.
+func HealthRoutes(r *Router) {
+	r.handle("GET /healthz", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeHealth(w, http.StatusOK, "ok")
+	}))
+	r.handle("GET /readyz", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if !r.Ready() {
+			writeHealth(w, http.StatusServiceUnavailable, "draining")
+			return
+		}
+		writeHealth(w, http.StatusOK, "ok")
+	}))
+}
+
+func writeHealth(w http.ResponseWriter, status int, s string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	//nolint:errcheck,gosec // best-effort after WriteHeader
+	json.NewEncoder(w).Encode(HealthStatus{Status: s})
+}
@@ -0,0 +1,243 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+// freeAddr returns a loopback address with a port nothing is listening on.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	var lc net.ListenConfig
+	ln, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+	return addr
+}
+
+func TestHealthRoutes(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.HealthRoutes(r)
+
+	tests := map[string]struct {
+		path string
+	}{
+		"liveness":  {path: "/healthz"},
+		"readiness": {path: "/readyz"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, http.NoBody))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+			assert.True(t, r.Ready())
+		})
+	}
+}
+
+func TestHealthRoutes_not_in_spec(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.HealthRoutes(r)
+
+	assert.NotContains(t, r.Spec().Paths, "/healthz")
+	assert.NotContains(t, r.Spec().Paths, "/readyz")
+}
+
+func TestRouter_lifecycle(t *testing.T) {
+	t.Parallel()
+
+	addr := freeAddr(t)
+	r := api.New(api.WithDrainDelay(200 * time.Millisecond))
+	api.HealthRoutes(r)
+
+	var events []string
+	r.OnStart(func(context.Context) error {
+		events = append(events, "start 1")
+		return nil
+	})
+	r.OnStart(func(context.Context) error {
+		events = append(events, "start 2")
+		return nil
+	})
+	r.OnShutdown(func(context.Context) error {
+		events = append(events, "shutdown 1")
+		return nil
+	})
+	r.OnShutdown(func(context.Context) error {
+		events = append(events, "shutdown 2")
+		return errors.New("flush failed")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- r.ListenAndServe(ctx, addr) }()
+
+	probe := func() int {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+addr+"/readyz", http.NoBody)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return 0
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Eventually(t, func() bool { return probe() == http.StatusOK }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"start 1", "start 2"}, events)
+
+	cancel()
+	require.Eventually(t, func() bool { return probe() == http.StatusServiceUnavailable }, time.Second, 10*time.Millisecond)
+	assert.False(t, r.Ready())
+
+	err := <-done
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "flush failed")
+	assert.Equal(t, []string{"start 1", "start 2", "shutdown 2", "shutdown 1"}, events)
+}
+
+func TestRouter_OnStart_error(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	startErr := errors.New("database unreachable")
+	r.OnStart(func(context.Context) error { return startErr })
+
+	var shutdown bool
+	r.OnShutdown(func(context.Context) error {
+		shutdown = true
+		return nil
+	})
+
+	err := r.ListenAndServe(context.Background(), freeAddr(t))
+	require.ErrorIs(t, err, startErr)
+	assert.False(t, shutdown)
+	assert.True(t, r.Ready())
+}
+
+func TestRouter_listen_error_runs_OnShutdown(t *testing.T) {
+	t.Parallel()
+
+	var lc net.ListenConfig
+	ln, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	r := api.New()
+	var shutdown bool
+	r.OnStart(func(context.Context) error { return nil })
+	r.OnShutdown(func(context.Context) error {
+		shutdown = true
+		return nil
+	})
+
+	err = r.ListenAndServe(context.Background(), ln.Addr().String())
+	require.Error(t, err)
+	assert.True(t, shutdown)
+}
+
+func TestRouter_serve_again(t *testing.T) {
+	t.Parallel()
+
+	type stateResp struct {
+		Stopping bool `json:"stopping"`
+	}
+	r := api.New(api.WithShutdownTimeout(time.Second))
+	api.Get(r, "/state", func(ctx context.Context, _ *api.Void) (*api.Resp[stateResp], error) {
+		select {
+		case <-api.ShuttingDown(ctx):
+			return &api.Resp[stateResp]{Body: stateResp{Stopping: true}}, nil
+		default:
+			return &api.Resp[stateResp]{Body: stateResp{}}, nil
+		}
+	})
+
+	for range 2 {
+		addr := freeAddr(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- r.ListenAndServe(ctx, addr) }()
+
+		var body string
+		require.Eventually(t, func() bool {
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+addr+"/state", http.NoBody)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return false
+			}
+			b, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			body = string(b)
+			return true
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.JSONEq(t, `{"stopping":false}`, body)
+		assert.True(t, r.Ready())
+
+		cancel()
+		require.NoError(t, <-done)
+		assert.False(t, r.Ready())
+	}
+}
+
+func TestRouter_stream_grace(t *testing.T) {
+	t.Parallel()
+
+	addr := freeAddr(t)
+	r := api.New(api.WithStreamGrace(100 * time.Millisecond))
+	api.Get(r, "/events", func(ctx context.Context, _ *api.Void) (*api.Resp[<-chan api.Event], error) {
+		ch := make(chan api.Event, 2)
+		ch <- api.Event{Data: "hello"}
+		go func() {
+			<-api.ShuttingDown(ctx)
+			ch <- api.Event{Data: "bye"} // left open: the grace period ends the stream
+		}()
+		return &api.Resp[<-chan api.Event]{Body: ch}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- r.ListenAndServe(ctx, addr) }()
+
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+addr+"/events", http.NoBody)
+		var err error
+		resp, err = http.DefaultClient.Do(req)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	defer func() { require.NoError(t, resp.Body.Close()) }()
+
+	first := make([]byte, len("data: hello\n\n"))
+	_, err := io.ReadFull(resp.Body, first)
+	require.NoError(t, err)
+	assert.Equal(t, "data: hello\n\n", string(first))
+
+	start := time.Now()
+	cancel()
+	rest, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "data: bye\n\nevent: shutdown\ndata: \n\n", string(rest))
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,631 @@
+package api
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+	marshalerType  = reflect.TypeFor[json.Marshaler]()
+	unmarshalType  = reflect.TypeFor[json.Unmarshaler]()
+)
+
+// jsonConverter renders one Go type as custom JSON. encode receives a
+// non-nil value of the registered type; decode receives a settable value of
+// it and the raw JSON (never null).
+type jsonConverter struct {
+	encode func(v reflect.Value) ([]byte, error)
+	decode func(data []byte, dst reflect.Value) error
+}
+
+// jsonMirror encodes and decodes JSON with custom representations for
+// types encoding/json cannot be told about (time formats, big numbers).
+// Each body type is translated once into a mirror type in which converted
+// fields become json.RawMessage; values are copied into the mirror,
+// rendered by encoding/json, and copied back on decode. This keeps field
+// order, tags, and omitempty semantics identical to plain encoding/json.
+type jsonMirror struct {
+	converters map[reflect.Type]jsonConverter
+	mirrors    sync.Map // map[reflect.Type]mirrorEntry
+	plans      sync.Map // map[planKey][]mirrorField
+}
+
+// mirrorEntry caches a mirror type; typ is nil when the source type needs
+// no conversion.
+type mirrorEntry struct {
+	typ reflect.Type
+}
+
+// planKey identifies the field mapping between a source struct type and
+// one of its mirrors.
+type planKey struct {
+	src, dst reflect.Type
+}
+
+type mirrorField struct {
+	src []int // index path in the source struct (through flattened embeds)
+	dst int
+
+	// indirect is set when src passes through an embedded pointer; the
+	// mirror field is then a pointer, nil when the field is left out.
+	indirect bool
+	omit     omitOption
+}
+
+// omitOption records a field's omitempty and omitzero tag options, which
+// indirect mirror fields apply themselves.
+type omitOption struct {
+	empty, zero bool
+}
+
+func omitOptions(opts string) omitOption {
+	var o omitOption
+	for opt := range strings.SplitSeq(opts, ",") {
+		switch opt {
+		case "omitempty":
+			o.empty = true
+		case "omitzero":
+			o.zero = true
+		}
+	}
+	return o
+}
+
+// omits reports whether encoding/json would leave out a field holding v.
+func (o omitOption) omits(v reflect.Value) bool {
+	if o.zero {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return true
+		}
+		if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+			if z.IsZero() {
+				return true
+			}
+		} else if v.IsZero() {
+			return true
+		}
+	}
+	if !o.empty {
+		return false
+	}
+	//exhaustive:ignore
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// newJSONMirror returns the mirror for a router's JSON codec. big.Float is
+// always carried as a string, and big.Int as a number unless
+// bigIntStrings is set; times only need converting when a non-default
+// TimeFormat is set.
+func newJSONMirror(tf TimeFormat, bigIntStrings bool) *jsonMirror {
+	m := &jsonMirror{converters: map[reflect.Type]jsonConverter{
+		reflect.TypeFor[big.Int]():   bigIntConverter(bigIntStrings),
+		reflect.TypeFor[big.Float](): bigFloatConverter,
+		locationType:                 locationConverter,
+	}}
+	if tf.enc != timeEncRFC3339 {
+		m.converters[reflect.TypeFor[time.Time]()] = tf.jsonConverter()
+	}
+	return m
+}
+
+func (c *jsonMirror) encode(w io.Writer, v any) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return json.NewEncoder(w).Encode(v)
+	}
+	mt := c.mirror(rv.Type())
+	if mt == nil {
+		return json.NewEncoder(w).Encode(v)
+	}
+	mv, err := c.toMirror(rv, mt)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(mv.Interface())
+}
+
+// marshal is encode for a single value, returning it without the trailing
+// newline.
+func (c *jsonMirror) marshal(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return json.Marshal(v)
+	}
+	mt := c.mirror(rv.Type())
+	if mt == nil {
+		return json.Marshal(v)
+	}
+	mv, err := c.toMirror(rv, mt)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(mv.Interface())
+}
+
+func (c *jsonMirror) decode(r io.Reader, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return json.NewDecoder(r).Decode(v)
+	}
+	mt := c.mirror(rv.Type().Elem())
+	if mt == nil {
+		return json.NewDecoder(r).Decode(v)
+	}
+	// Seed the mirror with the target's current contents so fields absent
+	// from the body keep their values, as with plain encoding/json.
+	seed, err := c.toMirror(rv.Elem(), mt)
+	if err != nil {
+		return err
+	}
+	mv := reflect.New(mt)
+	mv.Elem().Set(seed)
+	if err := json.NewDecoder(r).Decode(mv.Interface()); err != nil {
+		return err
+	}
+	return c.fromMirror(mv.Elem(), rv.Elem())
+}
+
+// converterFor returns the converter for t or for the type t points to.
+func (c *jsonMirror) converterFor(t reflect.Type) (jsonConverter, bool) {
+	if conv, ok := c.converters[t]; ok {
+		return conv, true
+	}
+	if t.Kind() == reflect.Pointer {
+		conv, ok := c.converters[t.Elem()]
+		return conv, ok
+	}
+	return jsonConverter{}, false
+}
+
+// mirror returns the cached mirror type of t, or nil when t needs no
+// conversion.
+func (c *jsonMirror) mirror(t reflect.Type) reflect.Type {
+	if e, ok := c.mirrors.Load(t); ok {
+		return e.(mirrorEntry).typ //nolint:forcetypeassert // cache only stores mirrorEntry
+	}
+	mt := c.buildMirror(t, map[reflect.Type]bool{})
+	c.mirrors.Store(t, mirrorEntry{typ: mt})
+	return mt
+}
+
+func (c *jsonMirror) buildMirror(t reflect.Type, inProgress map[reflect.Type]bool) reflect.Type {
+	if !c.needsMirror(t, map[reflect.Type]bool{}) {
+		return nil
+	}
+	if _, ok := c.converterFor(t); ok {
+		return rawMessageType
+	}
+	if inProgress[t] {
+		// Recursive types cannot be mirrored with reflect; the back-edge is
+		// rendered as a nested document instead.
+		return rawMessageType
+	}
+	inProgress[t] = true
+	defer delete(inProgress, t)
+
+	//exhaustive:ignore
+	switch t.Kind() {
+	case reflect.Pointer:
+		return reflect.PointerTo(c.elemMirror(t.Elem(), inProgress))
+	case reflect.Slice:
+		return reflect.SliceOf(c.elemMirror(t.Elem(), inProgress))
+	case reflect.Array:
+		return reflect.ArrayOf(t.Len(), c.elemMirror(t.Elem(), inProgress))
+	case reflect.Map:
+		return reflect.MapOf(t.Key(), c.elemMirror(t.Elem(), inProgress))
+	case reflect.Struct:
+		return c.structMirror(t, inProgress)
+	}
+	return nil
+}
+
+// elemMirror returns the mirror type of t, or t itself when unchanged.
+func (c *jsonMirror) elemMirror(t reflect.Type, inProgress map[reflect.Type]bool) reflect.Type {
+	if mt := c.buildMirror(t, inProgress); mt != nil {
+		return mt
+	}
+	return t
+}
+
+// mirrorCandidate is a struct field, possibly promoted through embedded
+// structs, competing for a JSON name in a mirror.
+type mirrorCandidate struct {
+	field    reflect.StructField
+	index    []int
+	name     string
+	opts     string
+	tagged   bool
+	indirect bool
+}
+
+func (c *jsonMirror) structMirror(t reflect.Type, inProgress map[reflect.Type]bool) reflect.Type {
+	var (
+		fields []reflect.StructField
+		plan   []mirrorField
+	)
+	for _, cand := range jsonFields(t) {
+		ft := c.elemMirror(cand.field.Type, inProgress)
+		tag := cand.name
+		if cand.opts != "" {
+			tag += "," + cand.opts
+		}
+		if cand.indirect {
+			// Fields promoted through an embedded pointer are absent when
+			// the pointer is nil; a nil pointer with omitempty keeps them
+			// out of the output the same way.
+			ft = reflect.PointerTo(ft)
+			tag += ",omitempty"
+		}
+		plan = append(plan, mirrorField{
+			src:      cand.index,
+			dst:      len(fields),
+			indirect: cand.indirect,
+			omit:     omitOptions(cand.opts),
+		})
+		fields = append(fields, reflect.StructField{
+			Name: "F" + strconv.Itoa(len(fields)),
+			Type: ft,
+			Tag:  reflect.StructTag(`json:` + strconv.Quote(tag)),
+		})
+	}
+	mt := reflect.StructOf(fields)
+	c.plans.Store(planKey{src: t, dst: mt}, plan)
+	return mt
+}
+
+// jsonFields returns the fields encoding/json encodes for struct type t, in
+// encoding order. Untagged embedded structs, and pointers to them, are
+// flattened; of several fields with the same JSON name the shallowest
+// wins, a tagged field breaking a tie, and ambiguous names are dropped.
+func jsonFields(t reflect.Type) []mirrorCandidate {
+	var all []mirrorCandidate
+	var walk func(st reflect.Type, prefix []int, indirect bool, path map[reflect.Type]bool)
+	walk = func(st reflect.Type, prefix []int, indirect bool, path map[reflect.Type]bool) {
+		path[st] = true
+		defer delete(path, st)
+		for i := range st.NumField() {
+			f := st.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			idx := append(append([]int{}, prefix...), i)
+			if f.Anonymous && name == "" {
+				et, ptr := f.Type, false
+				if et.Kind() == reflect.Pointer {
+					et, ptr = et.Elem(), true
+				}
+				if et.Kind() == reflect.Struct {
+					if !path[et] {
+						walk(et, idx, indirect || ptr, path)
+					}
+					continue
+				}
+			}
+			if !f.IsExported() {
+				continue
+			}
+			all = append(all, mirrorCandidate{
+				field:    f,
+				index:    idx,
+				name:     cmp.Or(name, f.Name),
+				opts:     opts,
+				tagged:   name != "",
+				indirect: indirect,
+			})
+		}
+	}
+	walk(t, nil, false, map[reflect.Type]bool{})
+
+	byName := make(map[string][]mirrorCandidate, len(all))
+	for _, cand := range all {
+		byName[cand.name] = append(byName[cand.name], cand)
+	}
+	var out []mirrorCandidate
+	for _, cand := range all {
+		if win, ok := dominantField(byName[cand.name]); ok && slices.Equal(win.index, cand.index) {
+			out = append(out, cand)
+		}
+	}
+	return out
+}
+
+// dominantField picks the field encoding/json keeps among fields sharing a
+// JSON name. Returns false when the name is ambiguous.
+func dominantField(cands []mirrorCandidate) (mirrorCandidate, bool) {
+	cands = slices.Clone(cands)
+	slices.SortStableFunc(cands, func(a, b mirrorCandidate) int {
+		if d := len(a.index) - len(b.index); d != 0 {
+			return d
+		}
+		switch {
+		case a.tagged && !b.tagged:
+			return -1
+		case b.tagged && !a.tagged:
+			return 1
+		}
+		return 0
+	})
+	if len(cands) > 1 && len(cands[0].index) == len(cands[1].index) && cands[0].tagged == cands[1].tagged {
+		return mirrorCandidate{}, false
+	}
+	return cands[0], true
+}
+
+// needsMirror reports whether t holds values of a converted type. Types
+// with their own JSON methods are left alone.
+func (c *jsonMirror) needsMirror(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if _, ok := c.converterFor(t); ok {
+		return true
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(unmarshalType) {
+		return false
+	}
+
+	//exhaustive:ignore
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return c.needsMirror(t.Elem(), seen)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() && !f.Anonymous {
+				continue
+			}
+			if f.Tag.Get("json") == "-" {
+				continue
+			}
+			if c.needsMirror(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toMirror copies v into a new value of mirror type mt.
+func (c *jsonMirror) toMirror(v reflect.Value, mt reflect.Type) (reflect.Value, error) {
+	t := v.Type()
+	if mt == t {
+		return v, nil
+	}
+
+	if mt == rawMessageType {
+		if conv, ok := c.converterFor(t); ok {
+			if t.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					return reflect.Zero(rawMessageType), nil
+				}
+				v = v.Elem()
+			}
+			b, err := conv.encode(v)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(json.RawMessage(b)), nil
+		}
+		var buf bytes.Buffer
+		if err := c.encode(&buf, v.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(json.RawMessage(bytes.TrimRight(buf.Bytes(), "\n"))), nil
+	}
+
+	out := reflect.New(mt).Elem()
+	//exhaustive:ignore
+	switch t.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return out, nil
+		}
+		ev, err := c.toMirror(v.Elem(), mt.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		p := reflect.New(mt.Elem())
+		p.Elem().Set(ev)
+		return p, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return out, nil
+		}
+		out = reflect.MakeSlice(mt, v.Len(), v.Len())
+		fallthrough
+	case reflect.Array:
+		for i := range v.Len() {
+			ev, err := c.toMirror(v.Index(i), mt.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Index(i).Set(ev)
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return out, nil
+		}
+		out = reflect.MakeMapWithSize(mt, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			ev, err := c.toMirror(iter.Value(), mt.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.SetMapIndex(iter.Key(), ev)
+		}
+	case reflect.Struct:
+		for _, f := range c.plan(t, mt) {
+			fv, err := v.FieldByIndexErr(f.src)
+			if err != nil {
+				// A nil embedded pointer; its fields are left out.
+				continue
+			}
+			ft := mt.Field(f.dst).Type
+			if f.indirect {
+				if f.omit.omits(fv) {
+					continue
+				}
+				ft = ft.Elem()
+			}
+			ev, err := c.toMirror(fv, ft)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if f.indirect {
+				p := reflect.New(ft)
+				p.Elem().Set(ev)
+				ev = p
+			}
+			out.Field(f.dst).Set(ev)
+		}
+	}
+	return out, nil
+}
+
+// fromMirror copies a decoded mirror value mv back into dst.
+func (c *jsonMirror) fromMirror(mv, dst reflect.Value) error {
+	t, mt := dst.Type(), mv.Type()
+	if mt == t {
+		dst.Set(mv)
+		return nil
+	}
+
+	if mt == rawMessageType {
+		raw := mv.Bytes()
+		conv, ok := c.converterFor(t)
+		if !ok {
+			if len(raw) == 0 {
+				return nil
+			}
+			return c.decode(bytes.NewReader(raw), dst.Addr().Interface())
+		}
+		isNull := len(raw) == 0 || string(raw) == "null"
+		if t.Kind() == reflect.Pointer {
+			if isNull {
+				dst.SetZero()
+				return nil
+			}
+			p := reflect.New(t.Elem())
+			if err := conv.decode(raw, p.Elem()); err != nil {
+				return err
+			}
+			dst.Set(p)
+			return nil
+		}
+		if isNull {
+			return nil
+		}
+		return conv.decode(raw, dst)
+	}
+
+	//exhaustive:ignore
+	switch t.Kind() {
+	case reflect.Pointer:
+		if mv.IsNil() {
+			dst.SetZero()
+			return nil
+		}
+		p := reflect.New(t.Elem())
+		if err := c.fromMirror(mv.Elem(), p.Elem()); err != nil {
+			return err
+		}
+		dst.Set(p)
+	case reflect.Slice:
+		if mv.IsNil() {
+			dst.SetZero()
+			return nil
+		}
+		dst.Set(reflect.MakeSlice(t, mv.Len(), mv.Len()))
+		fallthrough
+	case reflect.Array:
+		for i := range mv.Len() {
+			if err := c.fromMirror(mv.Index(i), dst.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if mv.IsNil() {
+			dst.SetZero()
+			return nil
+		}
+		out := reflect.MakeMapWithSize(t, mv.Len())
+		iter := mv.MapRange()
+		for iter.Next() {
+			ev := reflect.New(t.Elem()).Elem()
+			if err := c.fromMirror(iter.Value(), ev); err != nil {
+				return err
+			}
+			out.SetMapIndex(iter.Key(), ev)
+		}
+		dst.Set(out)
+	case reflect.Struct:
+		for _, f := range c.plan(t, mt) {
+			fv := mv.Field(f.dst)
+			if f.indirect {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			fd, err := allocFieldByIndex(dst, f.src)
+			if err != nil {
+				return err
+			}
+			if err := c.fromMirror(fv, fd); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// allocFieldByIndex returns the field of v at index, allocating nil
+// embedded pointers on the way as encoding/json does.
+func allocFieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("json: cannot set embedded pointer to unexported struct: %v", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// plan returns the field mapping recorded when mt was built from t.
+func (c *jsonMirror) plan(t, mt reflect.Type) []mirrorField {
+	p, _ := c.plans.Load(planKey{src: t, dst: mt})
+	plan, _ := p.([]mirrorField) //nolint:errcheck // every mirror struct has a plan
+	return plan
+}
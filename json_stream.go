@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// JSONArrayStream is a response body that is written as a JSON array one
+// element at a time, as the handler sends them. The response is flushed
+// whenever the channel has nothing ready, so memory stays constant however
+// long the list is, and the spec documents the body as []T:
+//
+//	func (h *H) Export(ctx context.Context, _ *api.Void) (*api.Resp[api.JSONArrayStream[User]], error) {
+//	    ch := make(chan User)
+//	    go func() {
+//	        defer close(ch)
+//	        for u := range h.store.IterUsers(ctx) {
+//	            select {
+//	            case ch <- u:
+//	            case <-ctx.Done():
+//	                return
+//	            }
+//	        }
+//	    }()
+//	    return &api.Resp[api.JSONArrayStream[User]]{Body: ch}, nil
+//	}
+//
+// The array is closed when the channel is closed. The producer must stop
+// when ctx is done: the framework stops reading once the client goes away,
+// leaving the array unterminated. A nil channel is written as [].
+//
+// The body is always JSON, whatever the Accept header; errors cannot be
+// reported once the first element is written, so fail before returning
+// the stream when possible.
+//
+// A Body typed iter.Seq[T] or iter.Seq2[T, error] streams the same way
+// without a goroutine, and is written as NDJSON when the client prefers
+// application/x-ndjson. An iterator of Event is written as server-sent
+// events. An iterator error before the first element becomes the route's
+// error response.
+type JSONArrayStream[T any] <-chan T
+
+func (JSONArrayStream[T]) jsonArrayStream() {}
+
+// jsonArrayStreamer is implemented by every JSONArrayStream instantiation.
+type jsonArrayStreamer interface {
+	jsonArrayStream()
+}
+
+var jsonArrayStreamerType = reflect.TypeFor[jsonArrayStreamer]()
+
+// isJSONArrayStreamType reports whether t is a JSONArrayStream[T].
+func isJSONArrayStreamType(t reflect.Type) bool {
+	return t.Kind() == reflect.Chan && t.Implements(jsonArrayStreamerType)
+}
+
+// WithFlushInterval batches the flushes of a route's streamed JSON array
+// and NDJSON bodies: after a value is written, the response is flushed
+// only once d has passed since the previous flush. Without it, iterator
+// bodies flush after every value, which costs a write per row on exports
+// of millions of rows. With it, a value may wait for the next one, or the
+// end of the stream, before it reaches the client. A JSONArrayStream also
+// flushes whenever its channel has nothing ready.
+//
+//	api.Get(r, "/export", h.Export, api.WithFlushInterval(100*time.Millisecond))
+func WithFlushInterval(d time.Duration) RouteOption {
+	return RouteOptionFunc(func(ri *routeInfo) {
+		ri.flushInterval = d
+	})
+}
+
+// streamFlusher flushes a streamed body after each value, or at most once
+// per interval when one is set.
+type streamFlusher struct {
+	flusher  http.Flusher
+	interval time.Duration
+	last     time.Time
+	pending  bool // written since the last flush; the header at first
+}
+
+func newStreamFlusher(w http.ResponseWriter, interval time.Duration) *streamFlusher {
+	flusher, _ := w.(http.Flusher) //nolint:errcheck // ok being false means no flushing
+	return &streamFlusher{flusher: flusher, interval: interval, last: time.Now(), pending: true}
+}
+
+// written is called after each value: it flushes when the interval, if
+// any, has passed.
+func (s *streamFlusher) written() {
+	s.pending = true
+	if s.interval > 0 && time.Since(s.last) < s.interval {
+		return
+	}
+	s.flush()
+}
+
+// flush flushes whatever was written since the last flush.
+func (s *streamFlusher) flush() {
+	if s.flusher != nil && s.pending {
+		s.flusher.Flush()
+		s.last = time.Now()
+		s.pending = false
+	}
+}
+
+// writeJSONArrayBody writes the elements received from the channel in bv
+// as a JSON array, flushing when the producer falls behind.
+func writeJSONArrayBody(ctx context.Context, w http.ResponseWriter, bv reflect.Value, status int, cfg *handlerConfig) {
+	w.Header().Set("Content-Type", "application/json")
+	cfg.codecs.protection.setNoSniff(w.Header())
+	w.WriteHeader(status)
+
+	if p := cfg.codecs.protection; p != nil && p.JSONArrays == JSONArrayPrefix {
+		//nolint:errcheck,gosec // best-effort after WriteHeader
+		io.WriteString(w, p.Prefix)
+	}
+
+	//nolint:errcheck,gosec // best-effort streaming writes
+	io.WriteString(w, "[")
+	ctx, cancel := streamContext(ctx, cfg.router)
+	defer cancel()
+	flusher := newStreamFlusher(w, cfg.flushInterval)
+	done := recvJSON(ctx, bv, cfg, flusher, func(n int, b []byte) {
+		if n > 0 {
+			//nolint:errcheck,gosec // best-effort streaming writes
+			io.WriteString(w, ",")
+		}
+		//nolint:errcheck,gosec // best-effort streaming writes
+		w.Write(b)
+		if flusher.interval > 0 {
+			flusher.written()
+		}
+	})
+	if done {
+		//nolint:errcheck,gosec // best-effort streaming writes
+		io.WriteString(w, "]\n")
+	}
+	// Otherwise the unterminated array signals the failure to the client.
+}
+
+// NDJSONStream is a response body that is written as newline-delimited
+// JSON, one line per value the handler sends, for log tailing and exports.
+// It is fed like a JSONArrayStream:
+//
+//	func (h *H) Tail(ctx context.Context, _ *api.Void) (*api.Resp[api.NDJSONStream[LogLine]], error) {
+//	    ch := make(chan LogLine)
+//	    go h.logs.Follow(ctx, ch) // closes ch when ctx is done
+//	    return &api.Resp[api.NDJSONStream[LogLine]]{Body: ch}, nil
+//	}
+//
+// Each line is flushed as it is written, or per WithFlushInterval. The
+// stream ends when the channel is closed or the client goes away, so the
+// producer must stop when ctx is done. A nil channel is an empty body.
+// The body is always application/x-ndjson, documented with the schema of
+// T; an iter.Seq body serves clients that may ask for either a JSON array
+// or NDJSON.
+type NDJSONStream[T any] <-chan T
+
+func (NDJSONStream[T]) ndjsonStream() {}
+
+// ndjsonStreamer is implemented by every NDJSONStream instantiation.
+type ndjsonStreamer interface {
+	ndjsonStream()
+}
+
+var ndjsonStreamerType = reflect.TypeFor[ndjsonStreamer]()
+
+// isNDJSONStreamType reports whether t is an NDJSONStream[T].
+func isNDJSONStreamType(t reflect.Type) bool {
+	return t.Kind() == reflect.Chan && t.Implements(ndjsonStreamerType)
+}
+
+// writeNDJSONBody writes the elements received from the channel in bv as
+// NDJSON lines.
+func writeNDJSONBody(ctx context.Context, w http.ResponseWriter, bv reflect.Value, status int, cfg *handlerConfig) {
+	w.Header().Set("Content-Type", ndjsonContentType)
+	cfg.codecs.protection.setNoSniff(w.Header())
+	w.WriteHeader(status)
+
+	ctx, cancel := streamContext(ctx, cfg.router)
+	defer cancel()
+	flusher := newStreamFlusher(w, cfg.flushInterval)
+	recvJSON(ctx, bv, cfg, flusher, func(_ int, b []byte) {
+		//nolint:errcheck,gosec // best-effort streaming writes
+		w.Write(append(b, '\n'))
+		flusher.written()
+	})
+}
+
+// recvJSON encodes each element received from the channel in bv and
+// passes it to emit with its position, flushing whenever the channel has
+// nothing ready. It reports whether the channel was closed, as opposed to
+// the client going away or an element failing to encode.
+func recvJSON(ctx context.Context, bv reflect.Value, cfg *handlerConfig, flusher *streamFlusher, emit func(n int, b []byte)) bool {
+	ff, filter := newFieldFilter(ctx, cfg.redaction, cfg.fieldScopes)
+	filter = filter && typeHasFieldPolicy(bv.Type().Elem())
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: bv},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+	}
+	for n := 0; !bv.IsNil(); n++ {
+		item, ok := bv.TryRecv()
+		if !item.IsValid() {
+			// Nothing ready: push what we have before blocking.
+			flusher.flush()
+			var chosen int
+			chosen, item, ok = reflect.Select(cases)
+			if chosen == 1 {
+				return false
+			}
+		}
+		if !ok {
+			break
+		}
+		if filter {
+			item = filterFields(item, ff)
+		}
+		b, err := cfg.codecs.marshalJSON(item.Interface())
+		if err != nil {
+			return false
+		}
+		emit(n, b)
+	}
+	return true
+}
@@ -0,0 +1,207 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type jsItem struct {
+	ID     int    `json:"id"`
+	Secret string `json:"secret,omitempty" redact:"true"`
+}
+
+func streamItems(items ...jsItem) api.JSONArrayStream[jsItem] {
+	ch := make(chan jsItem)
+	go func() {
+		defer close(ch)
+		for _, it := range items {
+			ch <- it
+		}
+	}()
+	return ch
+}
+
+func TestJSONArrayStream(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		body func() api.JSONArrayStream[jsItem]
+		want string
+	}{
+		"elements": {
+			body: func() api.JSONArrayStream[jsItem] { return streamItems(jsItem{ID: 1}, jsItem{ID: 2}, jsItem{ID: 3}) },
+			want: `[{"id":1},{"id":2},{"id":3}]` + "\n",
+		},
+		"closed channel": {
+			body: func() api.JSONArrayStream[jsItem] { return streamItems() },
+			want: "[]\n",
+		},
+		"nil channel": {
+			body: func() api.JSONArrayStream[jsItem] { return nil },
+			want: "[]\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New()
+			api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[api.JSONArrayStream[jsItem]], error) {
+				return &api.Resp[api.JSONArrayStream[jsItem]]{Body: tt.body()}, nil
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.want, w.Body.String())
+		})
+	}
+}
+
+func TestJSONArrayStream_redaction_and_prefix(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(
+		api.WithRedactionPolicy(func(context.Context, string) bool { return false }),
+		api.WithContentProtection(api.ContentProtection{JSONArrays: api.JSONArrayPrefix}),
+	)
+	api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[api.JSONArrayStream[jsItem]], error) {
+		return &api.Resp[api.JSONArrayStream[jsItem]]{Body: streamItems(jsItem{ID: 1, Secret: "s"})}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.Equal(t, ")]}',\n"+`[{"id":1,"secret":"[REDACTED]"}]`+"\n", w.Body.String())
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+}
+
+func TestJSONArrayStream_client_gone(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[api.JSONArrayStream[jsItem]], error) {
+		ch := make(chan jsItem, 1)
+		ch <- jsItem{ID: 1}
+		return &api.Resp[api.JSONArrayStream[jsItem]]{Body: ch}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/items", nil)
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.ServeHTTP(w, req)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream did not stop after the client went away")
+	}
+	assert.Equal(t, `[{"id":1}`, w.Body.String())
+	assert.True(t, w.Flushed)
+}
+
+func TestJSONArrayStream_spec(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[api.JSONArrayStream[jsItem]], error) {
+		return &api.Resp[api.JSONArrayStream[jsItem]]{}, nil
+	})
+
+	content := r.Spec().Paths["/items"]["get"].Responses["200"].Content
+	require.Contains(t, content, "application/json")
+	schema := content["application/json"].Schema
+	assert.Equal(t, "array", schema.Type)
+	require.NotNil(t, schema.Items)
+
+	raw, err := json.Marshal(schema.Items)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "jsItem")
+
+	assert.Panics(t, func() {
+		rr := api.New(api.WithContentProtection(api.ContentProtection{JSONArrays: api.JSONArrayReject}))
+		api.Get(rr, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[api.JSONArrayStream[jsItem]], error) {
+			return &api.Resp[api.JSONArrayStream[jsItem]]{}, nil
+		})
+	})
+}
+
+func TestNDJSONStream(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/tail", func(_ context.Context, _ *api.Void) (*api.Resp[api.NDJSONStream[jsItem]], error) {
+		ch := make(chan jsItem, 2)
+		ch <- jsItem{ID: 1, Secret: "s"}
+		ch <- jsItem{ID: 2}
+		close(ch)
+		return &api.Resp[api.NDJSONStream[jsItem]]{Body: ch}, nil
+	})
+	api.Get(r, "/empty", func(_ context.Context, _ *api.Void) (*api.Resp[api.NDJSONStream[jsItem]], error) {
+		return &api.Resp[api.NDJSONStream[jsItem]]{}, nil
+	})
+
+	w := &flushCounter{ResponseRecorder: httptest.NewRecorder()}
+	req := httptest.NewRequest(http.MethodGet, "/tail", http.NoBody)
+	req.Header.Set("Accept", "application/x-ndjson")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"id":1,"secret":"s"}`+"\n"+`{"id":2}`+"\n", w.Body.String())
+	assert.Equal(t, 2, w.flushes, "each line is flushed")
+
+	w = &flushCounter{ResponseRecorder: httptest.NewRecorder()}
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/empty", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	content := r.Spec().Paths["/tail"]["get"].Responses["200"].Content
+	require.Len(t, content, 1)
+	schema := content["application/x-ndjson"].Schema
+	require.NotNil(t, schema)
+	assert.Equal(t, "#/components/schemas/jsItem", schema.Ref)
+}
+
+func TestNDJSONStream_client_gone(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/tail", func(_ context.Context, _ *api.Void) (*api.Resp[api.NDJSONStream[jsItem]], error) {
+		ch := make(chan jsItem, 1)
+		ch <- jsItem{ID: 1}
+		return &api.Resp[api.NDJSONStream[jsItem]]{Body: ch}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.ServeHTTP(w, httptest.NewRequestWithContext(ctx, http.MethodGet, "/tail", nil))
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream did not stop after the client went away")
+	}
+	assert.Equal(t, `{"id":1}`+"\n", w.Body.String())
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrKeyNotFound is returned by Loader.Load when the batch function's result
+// has no entry for the requested key.
+var ErrKeyNotFound = errors.New("loader: key not found")
+
+// defaultLoaderWait is how long a Loader collects keys before fetching when
+// LoaderConfig.Wait is unset.
+const defaultLoaderWait = 2 * time.Millisecond
+
+// BatchFunc resolves many keys in a single downstream call. Keys missing
+// from the returned map resolve to ErrKeyNotFound; a non-nil error fails
+// every key in the batch.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// LoaderConfig tunes how a Loader groups keys into batches.
+type LoaderConfig struct {
+	// MaxBatch caps the number of keys per fetch. A full batch is sent
+	// immediately. Zero means unlimited.
+	MaxBatch int
+
+	// Wait is how long the first key in a batch waits for others to join
+	// before the batch is sent. Defaults to 2ms.
+	Wait time.Duration
+}
+
+// Loader batches and caches key lookups for the lifetime of one request, so
+// resolving the same relationship across many items costs one downstream
+// call instead of N. Concurrent Load calls made within the batch window are
+// coalesced, and each key is fetched at most once per Loader.
+//
+// Loaders are normally created per request by ProvideLoader and retrieved
+// with GetLoader. A Loader is safe for concurrent use.
+type Loader[K comparable, V any] struct {
+	ctx   context.Context //nolint:containedctx // fetches run on the owning request's context
+	fetch BatchFunc[K, V]
+	cfg   LoaderConfig
+
+	mu    sync.Mutex
+	cache map[K]*loaderEntry[V]
+	batch *loaderBatch[K, V]
+}
+
+type loaderEntry[V any] struct {
+	done chan struct{}
+	val  V
+	err  error
+}
+
+type loaderBatch[K comparable, V any] struct {
+	keys    []K
+	entries []*loaderEntry[V]
+	timer   *time.Timer
+}
+
+// NewLoader creates a Loader whose fetches run with ctx. Most callers use
+// ProvideLoader instead.
+func NewLoader[K comparable, V any](ctx context.Context, fetch BatchFunc[K, V], cfg LoaderConfig) *Loader[K, V] {
+	if cfg.Wait <= 0 {
+		cfg.Wait = defaultLoaderWait
+	}
+	return &Loader[K, V]{
+		ctx:   ctx,
+		fetch: fetch,
+		cfg:   cfg,
+		cache: make(map[K]*loaderEntry[V]),
+	}
+}
+
+// ProvideLoader returns middleware that installs a fresh Loader in each
+// request's context. Loaders are looked up by their key and value types, so
+// use distinct key types (e.g. UserID, OrgID) when a request needs several
+// loaders with the same shape.
+//
+//	r.Use(api.ProvideLoader(func(ctx context.Context, ids []UserID) (map[UserID]*User, error) {
+//	    return store.UsersByID(ctx, ids)
+//	}, api.LoaderConfig{MaxBatch: 100}))
+func ProvideLoader[K comparable, V any](fetch BatchFunc[K, V], cfg LoaderConfig) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, SetValue(r, NewLoader(r.Context(), fetch, cfg)))
+		})
+	}
+}
+
+// GetLoader returns the request's Loader for K and V installed by
+// ProvideLoader.
+func GetLoader[K comparable, V any](ctx context.Context) (*Loader[K, V], bool) {
+	return GetValue[*Loader[K, V]](ctx)
+}
+
+// Load returns the value for key, joining the pending batch if one is
+// collecting. ctx only bounds how long the caller waits; the fetch itself
+// runs with the Loader's context.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	return l.enqueue(key).wait(ctx)
+}
+
+// LoadMany returns values for keys in order, fetching the uncached ones in
+// as few batches as possible. It returns the first error encountered.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, error) {
+	entries := make([]*loaderEntry[V], len(keys))
+	for i, k := range keys {
+		entries[i] = l.enqueue(k)
+	}
+	out := make([]V, len(keys))
+	for i, e := range entries {
+		v, err := e.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// Prime caches val for key without fetching. Existing entries are kept.
+func (l *Loader[K, V]) Prime(key K, val V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.cache[key]; ok {
+		return
+	}
+	e := &loaderEntry[V]{done: make(chan struct{}), val: val}
+	close(e.done)
+	l.cache[key] = e
+}
+
+// enqueue returns the cache entry for key, adding it to the pending batch
+// if it has not been requested before.
+func (l *Loader[K, V]) enqueue(key K) *loaderEntry[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e, ok := l.cache[key]; ok {
+		return e
+	}
+	e := &loaderEntry[V]{done: make(chan struct{})}
+	l.cache[key] = e
+
+	if l.batch == nil {
+		b := &loaderBatch[K, V]{}
+		b.timer = time.AfterFunc(l.cfg.Wait, func() { l.dispatch(b) })
+		l.batch = b
+	}
+	b := l.batch
+	b.keys = append(b.keys, key)
+	b.entries = append(b.entries, e)
+
+	if l.cfg.MaxBatch > 0 && len(b.keys) >= l.cfg.MaxBatch {
+		b.timer.Stop()
+		l.batch = nil
+		go l.run(b)
+	}
+	return e
+}
+
+// dispatch sends b when its wait elapses, unless it was already sent for
+// being full.
+func (l *Loader[K, V]) dispatch(b *loaderBatch[K, V]) {
+	l.mu.Lock()
+	if l.batch != b {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+	l.run(b)
+}
+
+// run fetches a batch and resolves its entries. A panicking BatchFunc fails
+// the batch instead of crashing the server.
+func (l *Loader[K, V]) run(b *loaderBatch[K, V]) {
+	vals, err := l.safeFetch(b.keys)
+	for i, k := range b.keys {
+		e := b.entries[i]
+		switch v, ok := vals[k]; {
+		case err != nil:
+			e.err = err
+		case !ok:
+			e.err = fmt.Errorf("%w: %v", ErrKeyNotFound, k)
+		default:
+			e.val = v
+		}
+		close(e.done)
+	}
+}
+
+func (l *Loader[K, V]) safeFetch(keys []K) (vals map[K]V, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("loader: batch function panicked: %v", rec)
+		}
+	}()
+	return l.fetch(l.ctx, keys)
+}
+
+// wait blocks until the entry resolves or ctx is done.
+func (e *loaderEntry[V]) wait(ctx context.Context) (V, error) {
+	select {
+	case <-e.done:
+		return e.val, e.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,169 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (b *batchRecorder) fetch(_ context.Context, keys []int) (map[int]string, error) {
+	b.mu.Lock()
+	b.batches = append(b.batches, append([]int{}, keys...))
+	b.mu.Unlock()
+
+	out := make(map[int]string, len(keys))
+	for _, k := range keys {
+		if k < 0 {
+			continue
+		}
+		out[k] = string(rune('a' + k))
+	}
+	return out, nil
+}
+
+func (b *batchRecorder) count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.batches)
+}
+
+func TestLoader_coalesces_concurrent_loads(t *testing.T) {
+	t.Parallel()
+
+	rec := &batchRecorder{}
+	l := api.NewLoader(context.Background(), rec.fetch, api.LoaderConfig{Wait: 20 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range 5 {
+		wg.Go(func() {
+			v, err := l.Load(context.Background(), i%3)
+			assert.NoError(t, err)
+			results[i] = v
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, []string{"a", "b", "c", "a", "b"}, results)
+	require.Equal(t, 1, rec.count())
+	assert.ElementsMatch(t, []int{0, 1, 2}, rec.batches[0])
+
+	// Cached keys do not trigger another fetch.
+	v, err := l.Load(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "b", v)
+	assert.Equal(t, 1, rec.count())
+}
+
+func TestLoader_max_batch(t *testing.T) {
+	t.Parallel()
+
+	rec := &batchRecorder{}
+	l := api.NewLoader(context.Background(), rec.fetch, api.LoaderConfig{MaxBatch: 2, Wait: time.Hour})
+
+	vals, err := l.LoadMany(context.Background(), []int{0, 1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, vals)
+	assert.Equal(t, 2, rec.count())
+}
+
+func TestLoader_errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing key", func(t *testing.T) {
+		t.Parallel()
+		rec := &batchRecorder{}
+		l := api.NewLoader(context.Background(), rec.fetch, api.LoaderConfig{})
+		_, err := l.Load(context.Background(), -1)
+		assert.ErrorIs(t, err, api.ErrKeyNotFound)
+	})
+
+	t.Run("batch error", func(t *testing.T) {
+		t.Parallel()
+		boom := errors.New("boom")
+		l := api.NewLoader(context.Background(), func(context.Context, []int) (map[int]string, error) {
+			return nil, boom
+		}, api.LoaderConfig{})
+		_, err := l.LoadMany(context.Background(), []int{1, 2})
+		assert.ErrorIs(t, err, boom)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		t.Parallel()
+		l := api.NewLoader(context.Background(), func(context.Context, []int) (map[int]string, error) {
+			panic("bad")
+		}, api.LoaderConfig{})
+		_, err := l.Load(context.Background(), 1)
+		assert.ErrorContains(t, err, "panicked")
+	})
+
+	t.Run("caller context", func(t *testing.T) {
+		t.Parallel()
+		rec := &batchRecorder{}
+		l := api.NewLoader(context.Background(), rec.fetch, api.LoaderConfig{Wait: time.Hour})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := l.Load(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestLoader_prime(t *testing.T) {
+	t.Parallel()
+
+	rec := &batchRecorder{}
+	l := api.NewLoader(context.Background(), rec.fetch, api.LoaderConfig{})
+	l.Prime(7, "seven")
+
+	v, err := l.Load(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, "seven", v)
+	assert.Zero(t, rec.count())
+}
+
+func TestProvideLoader(t *testing.T) {
+	t.Parallel()
+
+	rec := &batchRecorder{}
+	r := api.New()
+	r.Use(api.ProvideLoader(rec.fetch, api.LoaderConfig{}))
+
+	type Resp struct {
+		Names []string `json:"names"`
+	}
+	api.Get(r, "/names", func(ctx context.Context, _ *api.Void) (*api.Resp[Resp], error) {
+		l, ok := api.GetLoader[int, string](ctx)
+		if !ok {
+			return nil, errors.New("no loader")
+		}
+		names, err := l.LoadMany(ctx, []int{0, 1, 0})
+		if err != nil {
+			return nil, err
+		}
+		return &api.Resp[Resp]{Body: Resp{Names: names}}, nil
+	})
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/names", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"names":["a","b","a"]}`, w.Body.String())
+	}
+
+	// One fetch per request: loaders are not shared across requests.
+	assert.Equal(t, 2, rec.count())
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Patterns documenting time zones and locales in the spec.
+const (
+	timeZonePattern = `^[A-Za-z0-9_+-]+(/[A-Za-z0-9_+-]+)*$`
+	localePattern   = `^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`
+)
+
+// locationType is time.Location. Fields of type *time.Location bind IANA
+// time zone names, such as America/New_York, from params and JSON bodies,
+// and encode as the name. An unknown zone is a bind error. The enum tag
+// restricts the zones accepted:
+//
+//	type ScheduleReq struct {
+//	    TZ *time.Location `query:"tz" enum:"UTC,America/New_York,Europe/London"`
+//	}
+//
+// Zones are loaded from the system's zoneinfo database; import
+// time/tzdata where it may be missing, as in scratch containers.
+var locationType = reflect.TypeFor[time.Location]()
+
+// loadLocation parses an IANA time zone name.
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("%q is not an IANA time zone", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return loc, nil
+}
+
+var locationConverter = jsonConverter{
+	encode: func(v reflect.Value) ([]byte, error) {
+		return strconv.AppendQuote(nil, addrOf[time.Location](v).String()), nil
+	},
+	decode: func(data []byte, dst reflect.Value) error {
+		name, err := strconv.Unquote(string(data))
+		if err != nil {
+			return fmt.Errorf("time zone: invalid value %s", data)
+		}
+		loc, err := loadLocation(name)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(loc).Elem())
+		return nil
+	},
+}
+
+// Locale is a BCP 47 language tag, such as en, en-US, or zh-Hant-TW. It
+// binds from params and JSON strings, rejecting values that are not
+// well-formed tags, and is normalized to the conventional case: language
+// lowercase, script title case, region uppercase. The enum tag restricts
+// the locales accepted:
+//
+//	type ContentReq struct {
+//	    Locale api.Locale `query:"locale" default:"en" enum:"en,en-GB,fr,de"`
+//	}
+//
+// Only the form of the tag is checked, not that its subtags are
+// registered.
+type Locale string
+
+// Language returns the primary language subtag, such as en for en-US.
+func (l Locale) Language() string {
+	lang, _, _ := strings.Cut(string(l), "-")
+	return lang
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *Locale) UnmarshalText(text []byte) error {
+	tag, err := parseLocale(string(text))
+	if err != nil {
+		return err
+	}
+	*l = tag
+	return nil
+}
+
+// parseLocale checks the form of a BCP 47 tag and normalizes its case.
+// Underscores, as in POSIX locales like en_US, are accepted as hyphens.
+func parseLocale(s string) (Locale, error) {
+	subtags := strings.Split(strings.ReplaceAll(s, "_", "-"), "-")
+	lang := subtags[0]
+	if len(lang) < 2 || len(lang) > 3 || !isAlpha(lang) {
+		return "", fmt.Errorf("%q is not a BCP 47 language tag", s)
+	}
+	subtags[0] = strings.ToLower(lang)
+	for i, sub := range subtags[1:] {
+		if sub == "" || len(sub) > 8 || !isAlphanumeric(sub) {
+			return "", fmt.Errorf("%q is not a BCP 47 language tag", s)
+		}
+		// Subtags after a singleton (x-private, u-extension) keep their
+		// lowercase form.
+		if slices.ContainsFunc(subtags[1:i+1], func(s string) bool { return len(s) == 1 }) {
+			subtags[i+1] = strings.ToLower(sub)
+			continue
+		}
+		switch {
+		case len(sub) == 4 && isAlpha(sub):
+			subtags[i+1] = strings.ToUpper(sub[:1]) + strings.ToLower(sub[1:])
+		case len(sub) == 2 && isAlpha(sub):
+			subtags[i+1] = strings.ToUpper(sub)
+		default:
+			subtags[i+1] = strings.ToLower(sub)
+		}
+	}
+	return Locale(strings.Join(subtags, "-")), nil
+}
+
+func isAlpha(s string) bool {
+	return !strings.ContainsFunc(s, func(r rune) bool { return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') })
+}
+
+func isAlphanumeric(s string) bool {
+	return !strings.ContainsFunc(s, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+	})
+}
@@ -0,0 +1,138 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type lcScheduleReq struct {
+	TZ     *time.Location `query:"tz" enum:"UTC,America/New_York,Europe/London"`
+	Locale api.Locale     `query:"locale" default:"en"`
+	Body   lcSchedule
+}
+
+type lcSchedule struct {
+	Zone   *time.Location `json:"zone"`
+	Locale api.Locale     `json:"locale"`
+}
+
+func newLocaleRouter() *api.Router {
+	r := api.New()
+	api.Post(r, "/schedules", func(_ context.Context, req *lcScheduleReq) (*api.Resp[lcSchedule], error) {
+		out := req.Body
+		if req.TZ != nil {
+			out.Zone = req.TZ
+		}
+		if out.Locale == "" {
+			out.Locale = req.Locale
+		}
+		return &api.Resp[lcSchedule]{Body: out}, nil
+	})
+	return r
+}
+
+func TestLocationAndLocale(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		query      string
+		body       string
+		wantStatus int
+		wantZone   string
+		wantLocale string
+	}{
+		"query zone and default locale": {
+			query:      "tz=America/New_York",
+			body:       `{}`,
+			wantStatus: http.StatusOK,
+			wantZone:   "America/New_York",
+			wantLocale: "en",
+		},
+		"body zone": {
+			body:       `{"zone":"Asia/Tokyo","locale":"zh_hant_tw"}`,
+			wantStatus: http.StatusOK,
+			wantZone:   "Asia/Tokyo",
+			wantLocale: "zh-Hant-TW",
+		},
+		"locale normalized": {
+			query:      "locale=en-gb",
+			body:       `{}`,
+			wantStatus: http.StatusOK,
+			wantLocale: "en-GB",
+		},
+		"unknown zone": {
+			query:      "tz=Mars/Olympus_Mons",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"zone not in enum": {
+			query:      "tz=Asia/Tokyo",
+			body:       `{}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		"malformed locale": {
+			query:      "locale=english!",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"unknown zone in body": {
+			body:       `{"zone":"Nowhere/Special"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	r := newLocaleRouter()
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/schedules?"+tc.query, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantStatus, w.Code, w.Body.String())
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var got map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			if tc.wantZone != "" {
+				assert.Equal(t, tc.wantZone, got["zone"])
+			}
+			assert.Equal(t, tc.wantLocale, got["locale"])
+		})
+	}
+}
+
+func TestLocationAndLocale_spec(t *testing.T) {
+	t.Parallel()
+
+	spec := newLocaleRouter().Spec()
+	op := spec.Paths["/schedules"]["post"]
+
+	params := make(map[string]api.Parameter)
+	for _, p := range op.Parameters {
+		params[p.Name] = p
+	}
+	require.Contains(t, params, "tz")
+	assert.Equal(t, "string", params["tz"].Schema.Type)
+	assert.Equal(t, "timezone", params["tz"].Schema.Format)
+	assert.Equal(t, []string{"UTC", "America/New_York", "Europe/London"}, params["tz"].Schema.Enum)
+	assert.Equal(t, "bcp47", params["locale"].Schema.Format)
+	assert.NotEmpty(t, params["locale"].Schema.Pattern)
+
+	schema := spec.Components.Schemas["lcSchedule"]
+	assert.Equal(t, "timezone", schema.Properties["zone"].Format)
+	assert.Equal(t, "bcp47", schema.Properties["locale"].Format)
+}
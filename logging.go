@@ -1,8 +1,10 @@
 package api
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -31,7 +33,7 @@ func (r *responseRecorder) Unwrap() http.ResponseWriter {
 
 // Logger returns middleware that logs each request using the provided slog.Logger.
 func Logger(logger *slog.Logger) Middleware {
-	return func(next http.Handler) http.Handler {
+	return Named("logger", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
@@ -57,5 +59,100 @@ func Logger(logger *slog.Logger) Middleware {
 
 			logger.LogAttrs(r.Context(), slog.LevelInfo, "request", attrs...)
 		})
+	})
+}
+
+// RequestLoggerConfig configures the RequestLogger middleware.
+type RequestLoggerConfig struct {
+	// Trace extracts the trace and span IDs for the request. Defaults to
+	// parsing the W3C traceparent header; return empty strings to omit.
+	Trace func(r *http.Request) (traceID, spanID string)
+}
+
+type requestLoggerKey struct{}
+
+// requestLogger is the per-request state behind GetLogger. The route
+// pattern is filled in once the mux has matched, and the principal is
+// read at call time, so RequestLogger can sit outside authentication.
+type requestLogger struct {
+	base    *slog.Logger
+	pattern string
+	traceID string
+	spanID  string
+}
+
+// RequestLogger returns middleware that makes a request-scoped logger
+// available to handlers through GetLogger. The logger carries the request
+// ID, route pattern, principal ID, and trace and span IDs as attrs, each
+// when known:
+//
+//	r.Use(api.RequestID(), api.RequestLogger(slog.Default()))
+//
+//	func (h *H) Get(ctx context.Context, req *GetReq) (*api.Resp[Item], error) {
+//	    api.GetLogger(ctx).Info("loading item", "id", req.ID)
+//	    ...
+//	}
+//
+// A nil base logs to slog.Default.
+func RequestLogger(base *slog.Logger, cfg ...RequestLoggerConfig) Middleware {
+	c := RequestLoggerConfig{Trace: traceparentIDs}
+	if len(cfg) > 0 && cfg[0].Trace != nil {
+		c.Trace = cfg[0].Trace
+	}
+
+	return Named("requestlogger", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rl := &requestLogger{base: base}
+			rl.traceID, rl.spanID = c.Trace(r)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestLoggerKey{}, rl)))
+		})
+	})
+}
+
+// GetLogger returns the request-scoped logger installed by RequestLogger,
+// or slog.Default when there is none.
+func GetLogger(ctx context.Context) *slog.Logger {
+	rl, ok := ctx.Value(requestLoggerKey{}).(*requestLogger)
+	if !ok {
+		return slog.Default()
+	}
+
+	attrs := make([]any, 0, 5)
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if rl.pattern != "" {
+		attrs = append(attrs, slog.String("route", rl.pattern))
+	}
+	if p, ok := GetPrincipal(ctx); ok && p.ID != "" {
+		attrs = append(attrs, slog.String("principal", p.ID))
+	}
+	if rl.traceID != "" {
+		attrs = append(attrs, slog.String("trace_id", rl.traceID))
+	}
+	if rl.spanID != "" {
+		attrs = append(attrs, slog.String("span_id", rl.spanID))
+	}
+	base := rl.base
+	if base == nil {
+		base = slog.Default()
+	}
+	return base.With(attrs...)
+}
+
+// setLoggerRoute records the matched route pattern for GetLogger.
+func setLoggerRoute(r *http.Request) {
+	if rl, ok := r.Context().Value(requestLoggerKey{}).(*requestLogger); ok {
+		rl.pattern = r.Pattern
+	}
+}
+
+// traceparentIDs parses the trace and span IDs from a W3C traceparent
+// header ("00-<trace-id>-<parent-id>-<flags>").
+func traceparentIDs(r *http.Request) (traceID, spanID string) {
+	parts := strings.Split(r.Header.Get("traceparent"), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", ""
 	}
+	return parts[1], parts[2]
 }
@@ -148,3 +148,94 @@ func TestLogger_with_request_id(t *testing.T) {
 	logOutput := buf.String()
 	assert.Contains(t, logOutput, "request_id")
 }
+
+type rlGetReq struct {
+	ID string `path:"id"`
+}
+
+func TestRequestLogger(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		headers  map[string]string
+		roles    string
+		want     []string
+		wantNone []string
+	}{
+		"all attrs": {
+			headers: map[string]string{
+				"X-Request-ID": "rid-1",
+				"traceparent":  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			roles: "user",
+			want: []string{
+				"request_id=rid-1",
+				`route="GET /items/{id}"`,
+				"principal=u1",
+				"trace_id=4bf92f3577b34da6a3ce929d0e0e4736",
+				"span_id=00f067aa0ba902b7",
+				"id=7",
+			},
+		},
+		"anonymous without trace": {
+			want:     []string{"request_id=", `route="GET /items/{id}"`},
+			wantNone: []string{"principal=", "trace_id=", "span_id="},
+		},
+		"malformed traceparent": {
+			headers:  map[string]string{"traceparent": "garbage"},
+			wantNone: []string{"trace_id="},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			r := api.New()
+			r.Use(api.RequestID(), api.RequestLogger(slog.New(slog.NewTextHandler(&buf, nil))), withPrincipal)
+			api.Get(r, "/items/{id}", func(ctx context.Context, req *rlGetReq) (*api.Void, error) {
+				api.GetLogger(ctx).Info("loading", "id", req.ID)
+				return &api.Void{}, nil
+			})
+
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/items/7", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			if tc.roles != "" {
+				req.Header.Set("X-Roles", tc.roles)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			out := buf.String()
+			for _, s := range tc.want {
+				assert.Contains(t, out, s)
+			}
+			for _, s := range tc.wantNone {
+				assert.NotContains(t, out, s)
+			}
+		})
+	}
+}
+
+func TestGetLogger_default(t *testing.T) {
+	t.Parallel()
+
+	assert.Same(t, slog.Default(), api.GetLogger(context.Background()))
+}
+
+func TestRequestLogger_nil_base(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	r.Use(api.RequestLogger(nil))
+	api.Get(r, "/items", func(ctx context.Context, _ *api.Void) (*api.Void, error) {
+		assert.NotNil(t, api.GetLogger(ctx))
+		return &api.Void{}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Matcher routes requests to the handlers registered on a Router. Patterns
+// use the net/http.ServeMux syntax, "[METHOD ][HOST]/path", and *http.ServeMux
+// is the default Matcher. Handler returns the pattern that matched req, or
+// "" when none did; ServeHTTP dispatches, setting Request.Pattern and the
+// path values before calling the handler.
+type Matcher interface {
+	Handle(pattern string, h http.Handler)
+	Handler(req *http.Request) (h http.Handler, pattern string)
+	ServeHTTP(w http.ResponseWriter, req *http.Request)
+}
+
+// PatternChecker is implemented by a Matcher whose pattern syntax differs
+// from net/http.ServeMux, so registration can validate patterns against it
+// rather than against a ServeMux.
+type PatternChecker interface {
+	CheckPattern(pattern string) error
+}
+
+// WithMatcher replaces the router's net/http.ServeMux with m:
+//
+//	r := api.New(api.WithMatcher(api.NewRadixMatcher()))
+//	api.Get(r, "/orders/{id:[0-9]+}", h.Get)
+//
+// Every route, spec, docs, and static handler is registered on m.
+func WithMatcher(m Matcher) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.mux = m
+	})
+}
+
+// checkMatcherPattern reports the error m would panic with for pattern.
+func checkMatcherPattern(m Matcher, pattern string) (err error) {
+	if pc, ok := m.(PatternChecker); ok {
+		return pc.CheckPattern(pattern)
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%v", rec)
+		}
+	}()
+	http.NewServeMux().Handle(pattern, http.NotFoundHandler())
+	return nil
+}
+
+// patternWildcards returns the names of the wildcards in pattern, without
+// the ... of a multi-segment wildcard or the regular expression of a
+// constrained one.
+func patternWildcards(pattern string) []string {
+	var names []string
+	for _, m := range patternParam.FindAllStringSubmatch(pattern, -1) {
+		name, _, _ := strings.Cut(m[1], ":")
+		name = strings.TrimSuffix(name, "...")
+		if name != "$" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// patternConstraints returns the regular expression of each constrained
+// wildcard in pattern, keyed by name.
+func patternConstraints(pattern string) map[string]string {
+	var out map[string]string
+	for _, m := range patternParam.FindAllStringSubmatch(pattern, -1) {
+		name, re, ok := strings.Cut(m[1], ":")
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[name] = re
+	}
+	return out
+}
+
+// constrainPathParams documents the constraint of each constrained
+// wildcard in pattern as the pattern of its path parameter.
+func constrainPathParams(params []Parameter, pattern string) {
+	constraints := patternConstraints(pattern)
+	for i := range params {
+		if re, ok := constraints[params[i].Name]; ok && params[i].In == "path" && params[i].Schema.Pattern == "" {
+			params[i].Schema.Pattern = "^(?:" + re + ")$"
+		}
+	}
+}
+
+// stripConstraints rewrites each constrained wildcard {name:re} in pattern
+// as {name}.
+func stripConstraints(pattern string) string {
+	if !strings.Contains(pattern, ":") {
+		return pattern
+	}
+	return patternParam.ReplaceAllStringFunc(pattern, func(w string) string {
+		name, _, _ := strings.Cut(w[1:len(w)-1], ":")
+		return "{" + name + "}"
+	})
+}
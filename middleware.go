@@ -10,9 +10,34 @@ import (
 // Go middleware ecosystem.
 type Middleware func(next http.Handler) http.Handler
 
+// When applies mw only to the routes pred selects, such as by tag, path
+// prefix, or method. pred runs once per route, against its description,
+// when the route is registered, so requests pay nothing for the match:
+//
+//	r := api.New(
+//	    api.When(func(d api.RouteDescription) bool { return d.HasTag("admin") }, audit),
+//	    api.When(func(d api.RouteDescription) bool { return strings.HasPrefix(d.Pattern, "/internal/") }, requireVPN),
+//	)
+//
+// The middleware runs after the router's Use middleware and before group
+// middleware, with earlier When options outermost. Raw and mounted routes
+// are covered; handlers outside the spec, such as Static, are not.
+func When(pred func(RouteDescription) bool, mw ...Middleware) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.conditional = append(r.conditional, conditionalMiddleware{pred: pred, mw: mw})
+	})
+}
+
+// conditionalMiddleware is middleware applied to the routes pred selects;
+// see When.
+type conditionalMiddleware struct {
+	pred func(RouteDescription) bool
+	mw   []Middleware
+}
+
 // Recovery returns middleware that recovers from panics and responds with 500.
 func Recovery() Middleware {
-	return func(next http.Handler) http.Handler {
+	return Named("recovery", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if rec := recover(); rec != nil {
@@ -27,5 +52,5 @@ func Recovery() Middleware {
 			}()
 			next.ServeHTTP(w, r)
 		})
-	}
+	})
 }
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+)
+
+// Named labels mw so ordering rules and MiddlewareNames can refer to it.
+// Built-in middleware is already named after its constructor in lower case
+// (e.g. "recovery", "requestid", "ratelimit", "cors", "introspect").
+//
+//	r.Use(api.Named("realip", realip.Middleware))
+func Named(name string, mw Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		if p, ok := next.(*middlewareProbe); ok {
+			p.name = name
+			return next
+		}
+		return mw(next)
+	}
+}
+
+// MiddlewareName returns the name given to mw by Named, or "" if it has
+// none. Only middleware returned by Named is inspected; any other is never
+// called, so constructing its handler has no side effects here.
+func MiddlewareName(mw Middleware) string {
+	if mw == nil || reflect.ValueOf(mw).Pointer() != namedCode {
+		return ""
+	}
+	p := &middlewareProbe{}
+	mw(p)
+	return p.name
+}
+
+// namedCode is the code pointer shared by every middleware Named returns.
+var namedCode = reflect.ValueOf(Named("", nil)).Pointer()
+
+// middlewareProbe is passed to a middleware returned by Named in place of
+// the next handler to read back its name. It is never served.
+type middlewareProbe struct {
+	name string
+}
+
+func (p *middlewareProbe) ServeHTTP(http.ResponseWriter, *http.Request) {}
+
+// middlewareRule is an ordering constraint on the router's middleware stack.
+// An empty before means after must be outermost.
+type middlewareRule struct {
+	before string
+	after  string
+}
+
+// RequireBefore declares that middleware named before must run ahead of
+// middleware named after (e.g. "realip" before "ratelimit"). The rule only
+// applies when both are installed. Use panics as soon as the stack violates
+// it, so misordering fails at startup rather than in production.
+func RequireBefore(before, after string) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.middlewareRules = append(r.middlewareRules, middlewareRule{before: before, after: after})
+	})
+}
+
+// RequireOutermost declares that middleware named name, when installed,
+// must be the first one passed to Use (e.g. "recovery").
+func RequireOutermost(name string) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.middlewareRules = append(r.middlewareRules, middlewareRule{after: name})
+	})
+}
+
+// MiddlewareNames returns the names of the router's middleware in the order
+// they run. Unnamed middleware is reported as "".
+func (r *Router) MiddlewareNames() []string {
+	return slices.Clone(r.middlewareNames)
+}
+
+// checkMiddlewareOrder returns an error describing the first rule the
+// router's current middleware stack violates. Because Use only appends, a
+// violation can never be fixed by later calls, so checking after each Use
+// is sufficient.
+func (r *Router) checkMiddlewareOrder() error {
+	for _, rule := range r.middlewareRules {
+		at := slices.Index(r.middlewareNames, rule.after)
+		if at < 0 {
+			continue
+		}
+		if rule.before == "" {
+			if at != 0 {
+				return fmt.Errorf("api: middleware %q must be outermost, found at position %d", rule.after, at)
+			}
+			continue
+		}
+		if before := slices.Index(r.middlewareNames, rule.before); before > at {
+			return fmt.Errorf("api: middleware %q must run before %q", rule.before, rule.after)
+		}
+	}
+	return nil
+}
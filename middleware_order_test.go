@@ -0,0 +1,120 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjaus/api"
+)
+
+func passthrough(next http.Handler) http.Handler { return next }
+
+func TestMiddlewareName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "recovery", api.MiddlewareName(api.Recovery()))
+	assert.Equal(t, "requestid", api.MiddlewareName(api.RequestID()))
+	assert.Equal(t, "realip", api.MiddlewareName(api.Named("realip", passthrough)))
+	assert.Equal(t, "outer", api.MiddlewareName(api.Named("outer", api.Named("inner", passthrough))))
+	assert.Empty(t, api.MiddlewareName(passthrough))
+}
+
+func TestMiddlewareName_does_not_call_unnamed(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	mw := func(next http.Handler) http.Handler {
+		calls++
+		return next
+	}
+	assert.Empty(t, api.MiddlewareName(mw))
+
+	r := api.New()
+	r.Use(mw)
+	assert.Equal(t, []string{""}, r.MiddlewareNames())
+	assert.Equal(t, 1, calls, "only building the chain calls it")
+}
+
+func TestNamed_serves_wrapped_middleware(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	r.Use(api.Named("tag", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Tag", "yes")
+			next.ServeHTTP(w, req)
+		})
+	}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	assert.Equal(t, "yes", w.Header().Get("X-Tag"))
+}
+
+func TestRouter_MiddlewareNames(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	r.Use(api.Recovery(), passthrough, api.Named("realip", passthrough))
+	assert.Equal(t, []string{"recovery", "", "realip"}, r.MiddlewareNames())
+}
+
+func TestRequireBefore(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		opts  []api.RouterOption
+		use   [][]api.Middleware
+		panic string
+	}{
+		"ordered": {
+			opts: []api.RouterOption{api.RequireBefore("realip", "ratelimit")},
+			use: [][]api.Middleware{
+				{api.Named("realip", passthrough)},
+				{api.Named("ratelimit", passthrough)},
+			},
+		},
+		"misordered": {
+			opts: []api.RouterOption{api.RequireBefore("realip", "ratelimit")},
+			use: [][]api.Middleware{
+				{api.Named("ratelimit", passthrough)},
+				{api.Named("realip", passthrough)},
+			},
+			panic: `api: middleware "realip" must run before "ratelimit"`,
+		},
+		"missing before is allowed": {
+			opts: []api.RouterOption{api.RequireBefore("realip", "ratelimit")},
+			use:  [][]api.Middleware{{api.Named("ratelimit", passthrough)}},
+		},
+		"outermost": {
+			opts: []api.RouterOption{api.RequireOutermost("recovery")},
+			use:  [][]api.Middleware{{api.Recovery(), api.RequestID()}},
+		},
+		"not outermost": {
+			opts:  []api.RouterOption{api.RequireOutermost("recovery")},
+			use:   [][]api.Middleware{{api.RequestID()}, {api.Recovery()}},
+			panic: `api: middleware "recovery" must be outermost, found at position 1`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New(tt.opts...)
+			apply := func() {
+				for _, mw := range tt.use {
+					r.Use(mw...)
+				}
+			}
+			if tt.panic == "" {
+				assert.NotPanics(t, apply)
+				return
+			}
+			assert.PanicsWithError(t, tt.panic, apply)
+		})
+	}
+}
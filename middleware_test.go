@@ -75,3 +75,47 @@ func TestMiddleware_ordering(t *testing.T) {
 	assert.Equal(t, "1", resp.Header.Get("X-First"))
 	assert.Equal(t, "2", resp.Header.Get("X-Second"))
 }
+
+func TestWhen(t *testing.T) {
+	t.Parallel()
+
+	mark := func(name string) api.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Applied", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	r := api.New(
+		api.When(func(d api.RouteDescription) bool { return d.HasTag("admin") }, mark("audit")),
+		api.When(func(d api.RouteDescription) bool { return d.Method == http.MethodDelete }, mark("confirm"), mark("log")),
+	)
+	ok := func(_ context.Context, _ *api.Void) (*api.Void, error) { return &api.Void{}, nil }
+	api.Get(r, "/users", ok)
+	admin := r.Group("/admin", api.WithGroupTags("admin"), api.WithGroupMiddleware(mark("group")))
+	api.Get(admin, "/users", ok)
+	api.Delete(admin, "/users/{id}", ok)
+
+	tests := map[string]struct {
+		method string
+		path   string
+		want   []string
+	}{
+		"no match":    {method: http.MethodGet, path: "/users"},
+		"tag":         {method: http.MethodGet, path: "/admin/users", want: []string{"audit", "group"}},
+		"tag, method": {method: http.MethodDelete, path: "/admin/users/7", want: []string{"audit", "confirm", "log", "group"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, http.NoBody))
+
+			require.Less(t, w.Code, 300, w.Body.String())
+			assert.Equal(t, tc.want, w.Header().Values("X-Applied"))
+		})
+	}
+}
@@ -0,0 +1,515 @@
+package msgpack
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var errShort = errors.New("msgpack: unexpected end of data")
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+// value kinds returned by next.
+type kind int
+
+const (
+	kindNil kind = iota
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindString
+	kindBin
+	kindArray
+	kindMap
+	kindExt
+)
+
+// token is one decoded header. For strings, bin, and ext, data holds the
+// payload; for arrays and maps, n holds the element count.
+type token struct {
+	kind kind
+	b    bool
+	i    int64
+	u    uint64
+	f    float64
+	data []byte
+	n    int
+	ext  int8
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errShort
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) readUint(size int) (uint64, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+// next reads the next value header.
+func (d *decoder) next() (token, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return token{}, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return token{kind: kindUint, u: uint64(c)}, nil
+	case c >= 0xe0:
+		return token{kind: kindInt, i: int64(int8(c))}, nil
+	case c&0xe0 == 0xa0:
+		return d.payload(kindString, int(c&0x1f), 0)
+	case c&0xf0 == 0x90:
+		return token{kind: kindArray, n: int(c & 0x0f)}, nil
+	case c&0xf0 == 0x80:
+		return token{kind: kindMap, n: int(c & 0x0f)}, nil
+	}
+
+	switch c {
+	case 0xc0:
+		return token{kind: kindNil}, nil
+	case 0xc2, 0xc3:
+		return token{kind: kindBool, b: c == 0xc3}, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.readUint(1 << (c - 0xcc))
+		return token{kind: kindUint, u: u}, err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		u, err := d.readUint(size)
+		shift := 64 - 8*size
+		return token{kind: kindInt, i: int64(u<<shift) >> shift}, err //nolint:gosec // sign extension
+	case 0xca:
+		u, err := d.readUint(4)
+		return token{kind: kindFloat, f: float64(math.Float32frombits(uint32(u)))}, err //nolint:gosec // 4-byte read
+	case 0xcb:
+		u, err := d.readUint(8)
+		return token{kind: kindFloat, f: math.Float64frombits(u)}, err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readUint(1 << (c - 0xd9))
+		if err != nil {
+			return token{}, err
+		}
+		return d.payload(kindString, int(n), 0) //nolint:gosec // bounded by read
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readUint(1 << (c - 0xc4))
+		if err != nil {
+			return token{}, err
+		}
+		return d.payload(kindBin, int(n), 0) //nolint:gosec // bounded by read
+	case 0xdc, 0xdd:
+		n, err := d.readUint(2 << (c - 0xdc))
+		return token{kind: kindArray, n: int(n)}, err //nolint:gosec // at most 32 bits
+	case 0xde, 0xdf:
+		n, err := d.readUint(2 << (c - 0xde))
+		return token{kind: kindMap, n: int(n)}, err //nolint:gosec // at most 32 bits
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		t, err := d.read(1)
+		if err != nil {
+			return token{}, err
+		}
+		return d.payload(kindExt, 1<<(c-0xd4), int8(t[0])) //nolint:gosec // ext type is a signed byte
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.readUint(1 << (c - 0xc7))
+		if err != nil {
+			return token{}, err
+		}
+		t, err := d.read(1)
+		if err != nil {
+			return token{}, err
+		}
+		return d.payload(kindExt, int(n), int8(t[0])) //nolint:gosec // bounded by read; ext type is a signed byte
+	}
+	return token{}, fmt.Errorf("msgpack: invalid byte 0x%02x at offset %d", c, d.pos-1)
+}
+
+func (d *decoder) payload(k kind, n int, ext int8) (token, error) {
+	b, err := d.read(n)
+	return token{kind: k, data: b, ext: ext}, err
+}
+
+// collection guards against headers claiming more elements than bytes
+// remain; each element takes at least one byte.
+func (d *decoder) collection(n int) error {
+	if n > len(d.data)-d.pos {
+		return errShort
+	}
+	return nil
+}
+
+func (d *decoder) decode(v reflect.Value) error {
+	tok, err := d.next()
+	if err != nil {
+		return err
+	}
+	return d.decodeToken(tok, v)
+}
+
+func (d *decoder) decodeToken(tok token, v reflect.Value) error {
+	if tok.kind == kindNil {
+		//exhaustive:ignore
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			v.SetZero()
+		}
+		return nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decodeToken(tok, v.Elem())
+	}
+
+	if v.Type() == timeType {
+		t, err := tokenTime(tok)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if tok.kind == kindString && reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(tok.data) //nolint:errcheck,forcetypeassert // checked above
+	}
+
+	//exhaustive:ignore
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("msgpack: cannot decode into non-empty interface %s", v.Type())
+		}
+		x, err := d.decodeAny(tok)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.SetZero()
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	case reflect.Bool:
+		if tok.kind != kindBool {
+			return mismatch(tok, v)
+		}
+		v.SetBool(tok.b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := tokenInt(tok)
+		if !ok || v.OverflowInt(n) {
+			return mismatch(tok, v)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := tokenUint(tok)
+		if !ok || v.OverflowUint(n) {
+			return mismatch(tok, v)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		//exhaustive:ignore
+		switch tok.kind {
+		case kindFloat:
+			v.SetFloat(tok.f)
+		case kindInt:
+			v.SetFloat(float64(tok.i))
+		case kindUint:
+			v.SetFloat(float64(tok.u))
+		default:
+			return mismatch(tok, v)
+		}
+	case reflect.String:
+		if tok.kind != kindString && tok.kind != kindBin {
+			return mismatch(tok, v)
+		}
+		v.SetString(string(tok.data))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 && (tok.kind == kindBin || tok.kind == kindString) {
+			v.SetBytes(append([]byte(nil), tok.data...))
+			return nil
+		}
+		if tok.kind != kindArray {
+			return mismatch(tok, v)
+		}
+		if err := d.collection(tok.n); err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(v.Type(), tok.n, tok.n)
+		for i := range tok.n {
+			if err := d.decode(s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Array:
+		if tok.kind != kindArray {
+			return mismatch(tok, v)
+		}
+		if err := d.collection(tok.n); err != nil {
+			return err
+		}
+		for i := range tok.n {
+			if i >= v.Len() {
+				if err := d.skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.decode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if tok.kind != kindMap {
+			return mismatch(tok, v)
+		}
+		if err := d.collection(tok.n); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), tok.n))
+		}
+		for range tok.n {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := d.decode(key); err != nil {
+				return err
+			}
+			val := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(val); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, val)
+		}
+	case reflect.Struct:
+		if tok.kind != kindMap {
+			return mismatch(tok, v)
+		}
+		return d.decodeStruct(tok.n, v)
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// decodeStruct matches keys to fields by name, preferring an exact match
+// and falling back to a case-insensitive one, as encoding/json does.
+func (d *decoder) decodeStruct(n int, v reflect.Value) error {
+	if err := d.collection(n); err != nil {
+		return err
+	}
+	fields := structFields(v.Type())
+	for range n {
+		var key string
+		if err := d.decode(reflect.ValueOf(&key).Elem()); err != nil {
+			return err
+		}
+		f := findField(fields, key)
+		if f == nil {
+			if err := d.skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decode(fieldByIndexAlloc(v, f.index)); err != nil {
+			return fmt.Errorf("msgpack: field %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func findField(fields []field, key string) *field {
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, key) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// decodeAny decodes into the natural Go representation: nil, bool, int64,
+// uint64, float64, string, []byte, time.Time, []any, or map[string]any
+// (map[any]any when a key is not a string).
+func (d *decoder) decodeAny(tok token) (any, error) {
+	switch tok.kind {
+	case kindNil:
+		return nil, nil
+	case kindBool:
+		return tok.b, nil
+	case kindInt:
+		return tok.i, nil
+	case kindUint:
+		return tok.u, nil
+	case kindFloat:
+		return tok.f, nil
+	case kindString:
+		return string(tok.data), nil
+	case kindBin:
+		return append([]byte(nil), tok.data...), nil
+	case kindExt:
+		return tokenTime(tok)
+	case kindArray:
+		if err := d.collection(tok.n); err != nil {
+			return nil, err
+		}
+		out := make([]any, tok.n)
+		for i := range out {
+			t, err := d.next()
+			if err != nil {
+				return nil, err
+			}
+			if out[i], err = d.decodeAny(t); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case kindMap:
+		if err := d.collection(tok.n); err != nil {
+			return nil, err
+		}
+		out := make(map[string]any, tok.n)
+		var generic map[any]any
+		for range tok.n {
+			kt, err := d.next()
+			if err != nil {
+				return nil, err
+			}
+			key, err := d.decodeAny(kt)
+			if err != nil {
+				return nil, err
+			}
+			vt, err := d.next()
+			if err != nil {
+				return nil, err
+			}
+			val, err := d.decodeAny(vt)
+			if err != nil {
+				return nil, err
+			}
+			if s, ok := key.(string); ok && generic == nil {
+				out[s] = val
+				continue
+			}
+			if generic == nil {
+				generic = make(map[any]any, tok.n)
+				for k, v := range out {
+					generic[k] = v
+				}
+			}
+			if !reflect.TypeOf(key).Comparable() {
+				return nil, errors.New("msgpack: map key is not comparable")
+			}
+			generic[key] = val
+		}
+		if generic != nil {
+			return generic, nil
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("msgpack: unknown value kind %d", tok.kind)
+}
+
+// skip discards the next value.
+func (d *decoder) skip() error {
+	tok, err := d.next()
+	if err != nil {
+		return err
+	}
+	count := 0
+	//exhaustive:ignore
+	switch tok.kind {
+	case kindArray:
+		count = tok.n
+	case kindMap:
+		count = 2 * tok.n
+	}
+	if err := d.collection(count); err != nil {
+		return err
+	}
+	for range count {
+		if err := d.skip(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tokenInt(tok token) (int64, bool) {
+	//exhaustive:ignore
+	switch tok.kind {
+	case kindInt:
+		return tok.i, true
+	case kindUint:
+		return int64(tok.u), tok.u <= math.MaxInt64 //nolint:gosec // range checked
+	}
+	return 0, false
+}
+
+func tokenUint(tok token) (uint64, bool) {
+	//exhaustive:ignore
+	switch tok.kind {
+	case kindUint:
+		return tok.u, true
+	case kindInt:
+		return uint64(tok.i), tok.i >= 0 //nolint:gosec // range checked
+	}
+	return 0, false
+}
+
+// tokenTime decodes a timestamp extension, or an RFC 3339 string as
+// written by peers that encode times as text.
+func tokenTime(tok token) (time.Time, error) {
+	if tok.kind == kindString {
+		return time.Parse(time.RFC3339Nano, string(tok.data))
+	}
+	if tok.kind != kindExt || tok.ext != timestampExt {
+		return time.Time{}, errors.New("msgpack: expected timestamp")
+	}
+	b := tok.data
+	switch len(b) {
+	case 4:
+		return time.Unix(int64(be32(b)), 0).UTC(), nil
+	case 8:
+		n := uint64(be32(b))<<32 | uint64(be32(b[4:]))
+		return time.Unix(int64(n&(1<<34-1)), int64(n>>34)).UTC(), nil //nolint:gosec // 34- and 30-bit fields
+	case 12:
+		sec := uint64(be32(b[4:]))<<32 | uint64(be32(b[8:]))
+		return time.Unix(int64(sec), int64(be32(b))).UTC(), nil //nolint:gosec // two's complement seconds
+	}
+	return time.Time{}, fmt.Errorf("msgpack: invalid timestamp length %d", len(b))
+}
+
+func be32(b []byte) uint32 {
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+}
+
+func mismatch(tok token, v reflect.Value) error {
+	names := [...]string{"nil", "bool", "int", "uint", "float", "string", "bin", "array", "map", "ext"}
+	return fmt.Errorf("msgpack: cannot decode %s into %s", names[tok.kind], v.Type())
+}
@@ -0,0 +1,418 @@
+// Package msgpack provides a MessagePack codec for the api framework.
+//
+// Register it on the router to negotiate application/msgpack alongside
+// JSON; handlers, validation, and the generated schemas are unchanged:
+//
+//	r := api.New(
+//	    api.WithEncoder(msgpack.Codec{}),
+//	    api.WithDecoder(msgpack.Codec{}),
+//	)
+//
+// Struct fields are named by their json tags, so a type has the same
+// shape on both wire formats. time.Time uses the MessagePack timestamp
+// extension, encoding.TextMarshaler types are carried as strings, and
+// []byte is carried as binary.
+package msgpack
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentType is the media type negotiated for MessagePack bodies.
+const ContentType = "application/msgpack"
+
+// Codec implements api.Encoder and api.Decoder for MessagePack.
+type Codec struct{}
+
+// ContentType implements api.Encoder and api.Decoder.
+func (Codec) ContentType() string { return ContentType }
+
+// Encode implements api.Encoder.
+func (Codec) Encode(w io.Writer, v any) error {
+	b, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Decode implements api.Decoder. An empty body leaves v unchanged.
+func (Codec) Decode(r io.Reader, v any) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	return Unmarshal(b, v)
+}
+
+// Marshal returns the MessagePack encoding of v.
+func Marshal(v any) ([]byte, error) {
+	e := &encoder{}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// Unmarshal decodes the MessagePack data into the value pointed to by v.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("msgpack: Unmarshal requires a non-nil pointer")
+	}
+	d := &decoder{data: data}
+	if err := d.decode(rv.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return fmt.Errorf("msgpack: %d trailing bytes", len(d.data)-d.pos)
+	}
+	return nil
+}
+
+// timestampExt is the MessagePack extension type for timestamps.
+const timestampExt = -1
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// --- struct fields ---
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var fieldCache sync.Map // reflect.Type → []field
+
+// structFields lists the encoded fields of t, named as encoding/json
+// names them.
+func structFields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field) //nolint:errcheck,forcetypeassert // cache holds []field only
+	}
+	var fields []field
+	for _, f := range reflect.VisibleFields(t) {
+		if f.Anonymous {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+				continue // promoted fields are listed separately
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, field{
+			name:      name,
+			index:     f.Index,
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// fieldByIndex returns the field at index, or an invalid Value when an
+// embedded pointer on the way is nil.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// fieldByIndexAlloc is fieldByIndex for decoding, allocating nil embedded
+// pointers on the way.
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// --- encoding ---
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf = append(e.buf, 0xc0)
+		return nil
+	}
+
+	if v.Type() == timeType {
+		e.writeTime(v.Interface().(time.Time)) //nolint:errcheck,forcetypeassert // checked above
+		return nil
+	}
+	if v.Kind() != reflect.Pointer && v.Kind() != reflect.Interface && v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText() //nolint:errcheck,forcetypeassert // checked above
+		if err != nil {
+			return err
+		}
+		e.writeString(string(b))
+		return nil
+	}
+
+	//exhaustive:ignore
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.writeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.writeUint(v.Uint())
+	case reflect.Float32:
+		e.buf = append(e.buf, 0xca)
+		e.buf = appendUint32(e.buf, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.buf = append(e.buf, 0xcb)
+		e.buf = appendUint64(e.buf, math.Float64bits(v.Float()))
+	case reflect.String:
+		e.writeString(v.String())
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		return e.encode(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.writeBin(v.Bytes())
+			return nil
+		}
+		return e.writeArray(v)
+	case reflect.Array:
+		return e.writeArray(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		e.writeLen(v.Len(), 0x80, 0xde, 0xdf, 16)
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := e.encode(iter.Key()); err != nil {
+				return err
+			}
+			if err := e.encode(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return e.writeStruct(v)
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func (e *encoder) writeStruct(v reflect.Value) error {
+	fields := structFields(v.Type())
+	values := make([]reflect.Value, 0, len(fields))
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		fv := fieldByIndex(v, f.index)
+		if !fv.IsValid() || f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		values = append(values, fv)
+		names = append(names, f.name)
+	}
+	e.writeLen(len(values), 0x80, 0xde, 0xdf, 16)
+	for i, fv := range values {
+		e.writeString(names[i])
+		if err := e.encode(fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) writeArray(v reflect.Value) error {
+	e.writeLen(v.Len(), 0x90, 0xdc, 0xdd, 16)
+	for i := range v.Len() {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLen writes a collection header: the fix form below fixMax, then the
+// 16- and 32-bit forms.
+func (e *encoder) writeLen(n int, fix, b16, b32 byte, fixMax int) {
+	switch {
+	case n < fixMax:
+		e.buf = append(e.buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, b16)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, b32)
+		e.buf = appendUint32(e.buf, uint32(n)) //nolint:gosec // lengths above 4GiB are not encodable
+	}
+}
+
+func (e *encoder) writeString(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		e.buf = append(e.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xda)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdb)
+		e.buf = appendUint32(e.buf, uint32(n)) //nolint:gosec // lengths above 4GiB are not encodable
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) writeBin(b []byte) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xc5)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xc6)
+		e.buf = appendUint32(e.buf, uint32(n)) //nolint:gosec // lengths above 4GiB are not encodable
+	}
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) writeInt(n int64) {
+	switch {
+	case n >= 0:
+		e.writeUint(uint64(n))
+	case n >= -32:
+		e.buf = append(e.buf, byte(n)) //nolint:gosec // negative fixint is the low byte
+	case n >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(n)) //nolint:gosec // two's complement byte
+	case n >= math.MinInt16:
+		e.buf = append(e.buf, 0xd1)
+		e.buf = appendUint16(e.buf, uint16(n)) //nolint:gosec // two's complement bits
+	case n >= math.MinInt32:
+		e.buf = append(e.buf, 0xd2)
+		e.buf = appendUint32(e.buf, uint32(n)) //nolint:gosec // two's complement bits
+	default:
+		e.buf = append(e.buf, 0xd3)
+		e.buf = appendUint64(e.buf, uint64(n)) //nolint:gosec // two's complement bits
+	}
+}
+
+func (e *encoder) writeUint(n uint64) {
+	switch {
+	case n <= 0x7f:
+		e.buf = append(e.buf, byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xcd)
+		e.buf = appendUint16(e.buf, uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = appendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = appendUint64(e.buf, n)
+	}
+}
+
+// writeTime uses the smallest timestamp extension form that holds t.
+func (e *encoder) writeTime(t time.Time) {
+	sec, nsec := t.Unix(), uint64(t.Nanosecond()) //nolint:gosec // Nanosecond is in [0, 1e9)
+	switch {
+	case sec >= 0 && sec <= math.MaxUint32 && nsec == 0:
+		e.buf = append(e.buf, 0xd6, 0xff)
+		e.buf = appendUint32(e.buf, uint32(sec))
+	case sec >= 0 && sec < 1<<34:
+		e.buf = append(e.buf, 0xd7, 0xff)
+		e.buf = appendUint64(e.buf, nsec<<34|uint64(sec))
+	default:
+		e.buf = append(e.buf, 0xc7, 12, 0xff)
+		e.buf = appendUint32(e.buf, uint32(nsec))
+		e.buf = appendUint64(e.buf, uint64(sec)) //nolint:gosec // two's complement bits
+	}
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	//exhaustive:ignore
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+func appendUint16(b []byte, n uint16) []byte {
+	return append(b, byte(n>>8), byte(n))
+}
+
+func appendUint32(b []byte, n uint32) []byte {
+	return append(b, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+func appendUint64(b []byte, n uint64) []byte {
+	return append(b, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+		byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
@@ -0,0 +1,165 @@
+package msgpack_test
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+	"github.com/bjaus/api/msgpack"
+)
+
+var (
+	_ api.Encoder = msgpack.Codec{}
+	_ api.Decoder = msgpack.Codec{}
+)
+
+type Base struct {
+	ID string `json:"id"`
+}
+
+type Item struct {
+	Base
+	Name    string            `json:"name"`
+	Count   int               `json:"count,omitempty"`
+	Price   float64           `json:"price"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Blob    []byte            `json:"blob,omitempty"`
+	Addr    netip.Addr        `json:"addr"`
+	Created time.Time         `json:"created"`
+	Next    *Item             `json:"next,omitempty"`
+	Skip    string            `json:"-"`
+	Raw     any               `json:"raw"`
+}
+
+func TestMarshal_wire_format(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		in   any
+		want []byte
+	}{
+		"nil":            {in: nil, want: []byte{0xc0}},
+		"true":           {in: true, want: []byte{0xc3}},
+		"positive fix":   {in: 5, want: []byte{0x05}},
+		"negative fix":   {in: -1, want: []byte{0xff}},
+		"uint8":          {in: 200, want: []byte{0xcc, 0xc8}},
+		"int16":          {in: -300, want: []byte{0xd1, 0xfe, 0xd4}},
+		"uint64":         {in: uint64(math.MaxUint64), want: []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		"float64":        {in: 1.5, want: []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		"fixstr":         {in: "hi", want: []byte{0xa2, 'h', 'i'}},
+		"bin":            {in: []byte{1, 2}, want: []byte{0xc4, 0x02, 1, 2}},
+		"fixarray":       {in: []int{1, 2}, want: []byte{0x92, 0x01, 0x02}},
+		"fixmap":         {in: map[string]int{"a": 1}, want: []byte{0x81, 0xa1, 'a', 0x01}},
+		"timestamp32":    {in: time.Unix(1, 0), want: []byte{0xd6, 0xff, 0, 0, 0, 1}},
+		"struct by tags": {in: Base{ID: "x"}, want: []byte{0x81, 0xa2, 'i', 'd', 0xa1, 'x'}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got, err := msgpack.Marshal(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	in := Item{
+		Base:    Base{ID: "i1"},
+		Name:    "widget",
+		Price:   9.99,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"env": "prod"},
+		Blob:    []byte{0, 1, 2},
+		Addr:    netip.MustParseAddr("10.0.0.1"),
+		Created: time.Date(2026, 1, 2, 3, 4, 5, 600, time.UTC),
+		Next:    &Item{Base: Base{ID: "i2"}, Created: time.Unix(-5, 7).UTC()},
+		Skip:    "dropped",
+		Raw:     map[string]any{"n": int64(-3), "list": []any{"x", true, nil}},
+	}
+
+	b, err := msgpack.Marshal(in)
+	require.NoError(t, err)
+
+	var out Item
+	require.NoError(t, msgpack.Unmarshal(b, &out))
+
+	in.Skip = ""
+	assert.Equal(t, in, out)
+}
+
+func TestUnmarshal_errors(t *testing.T) {
+	t.Parallel()
+
+	var n int8
+	tests := map[string]struct {
+		data []byte
+		into any
+	}{
+		"truncated":       {data: []byte{0xa5, 'h'}, into: new(string)},
+		"overflow":        {data: []byte{0xcd, 0x01, 0x00}, into: &n},
+		"type mismatch":   {data: []byte{0xa1, 'x'}, into: new(int)},
+		"trailing bytes":  {data: []byte{0x01, 0x02}, into: new(int)},
+		"invalid byte":    {data: []byte{0xc1}, into: new(any)},
+		"huge collection": {data: []byte{0xdd, 0xff, 0xff, 0xff, 0xff}, into: new([]int)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Error(t, msgpack.Unmarshal(tt.data, tt.into))
+		})
+	}
+
+	assert.Error(t, msgpack.Unmarshal([]byte{0x01}, 0))
+	_, err := msgpack.Marshal(make(chan int))
+	assert.Error(t, err)
+}
+
+func TestCodec_router(t *testing.T) {
+	t.Parallel()
+
+	type CreateReq struct {
+		Body Base
+	}
+
+	r := api.New(api.WithEncoder(msgpack.Codec{}), api.WithDecoder(msgpack.Codec{}))
+	api.Post(r, "/items", func(_ context.Context, req *CreateReq) (*api.Resp[Item], error) {
+		return &api.Resp[Item]{Body: Item{Base: req.Body, Name: "created"}}, nil
+	})
+
+	body, err := msgpack.Marshal(Base{ID: "abc"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/items", bytes.NewReader(body))
+	req.Header.Set("Content-Type", msgpack.ContentType)
+	req.Header.Set("Accept", msgpack.ContentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, msgpack.ContentType, w.Header().Get("Content-Type"))
+
+	var got Item
+	require.NoError(t, msgpack.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "abc", got.ID)
+	assert.Equal(t, "created", got.Name)
+
+	op := r.Spec().Paths["/items"]["post"]
+	assert.Contains(t, op.RequestBody.Content, msgpack.ContentType)
+	assert.Contains(t, op.Responses["200"].Content, msgpack.ContentType)
+	assert.Equal(t, op.Responses["200"].Content["application/json"].Schema, op.Responses["200"].Content[msgpack.ContentType].Schema)
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// defaultNegotiationCacheSize is the number of distinct Accept values
+	// whose negotiation result is cached when WithNegotiationCache is unset.
+	defaultNegotiationCacheSize = 256
+
+	// maxCachedAcceptLen keeps unusually long Accept headers out of the
+	// cache; they are negotiated on every request instead.
+	maxCachedAcceptLen = 512
+)
+
+// WithNegotiationCache sets how many distinct Accept header values keep
+// their negotiated encoder in an LRU cache, so q-values are not re-parsed
+// on every request. Services see only a handful of distinct Accept
+// strings, so the default of 256 rarely needs changing. A size of zero or
+// less disables the cache.
+func WithNegotiationCache(size int) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.negotiationCacheSize = size
+	})
+}
+
+// NegotiationStats reports the effectiveness of the negotiation cache.
+type NegotiationStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// NegotiationStats returns the negotiation cache counters. All fields are
+// zero when the cache is disabled.
+func (r *Router) NegotiationStats() NegotiationStats {
+	c := r.codecs.cache
+	if c == nil {
+		return NegotiationStats{}
+	}
+	c.mu.Lock()
+	entries := c.ll.Len()
+	c.mu.Unlock()
+	return NegotiationStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Entries: entries}
+}
+
+// negotiationCache is a fixed-size LRU of Accept value → negotiate result.
+type negotiationCache struct {
+	size int
+
+	mu    sync.Mutex
+	ll    *list.List // front is most recently used
+	items map[string]*list.Element
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type negotiationEntry struct {
+	accept string
+	enc    Encoder
+	ok     bool
+}
+
+func newNegotiationCache(size int) *negotiationCache {
+	if size <= 0 {
+		return nil
+	}
+	return &negotiationCache{size: size, ll: list.New(), items: make(map[string]*list.Element, size)}
+}
+
+// get returns the cached result for accept, computing and storing it with
+// compute on a miss.
+func (c *negotiationCache) get(accept string, compute func(string) (Encoder, bool)) (Encoder, bool) {
+	if len(accept) > maxCachedAcceptLen {
+		c.misses.Add(1)
+		return compute(accept)
+	}
+
+	c.mu.Lock()
+	if el, ok := c.items[accept]; ok {
+		c.ll.MoveToFront(el)
+		e := el.Value.(*negotiationEntry) //nolint:errcheck,forcetypeassert // list holds *negotiationEntry only
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return e.enc, e.ok
+	}
+	c.mu.Unlock()
+
+	c.misses.Add(1)
+	enc, ok := compute(accept)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.items[accept]; !exists {
+		c.items[accept] = c.ll.PushFront(&negotiationEntry{accept: accept, enc: enc, ok: ok})
+		if c.ll.Len() > c.size {
+			oldest := c.ll.Back()
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*negotiationEntry).accept) //nolint:errcheck,forcetypeassert // list holds *negotiationEntry only
+		}
+	}
+	return enc, ok
+}
@@ -287,3 +287,138 @@ func TestNegotiate_xml_request_and_xml_response(t *testing.T) {
 	require.NoError(t, xml.NewDecoder(resp.Body).Decode(&respBody))
 	assert.Equal(t, "hello both", respBody.Message)
 }
+
+func TestNegotiate_cache(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.WithNegotiationCache(2))
+	api.Get(r, "/greet", func(_ context.Context, _ *api.Void) (*api.Resp[greetResp], error) {
+		return &api.Resp[greetResp]{Body: greetResp{Message: "hello"}}, nil
+	})
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/greet", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("application/xml;q=0.9, application/json;q=0.5")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+	first := r.NegotiationStats()
+	assert.Equal(t, uint64(1), first.Misses)
+	assert.Equal(t, 1, first.Entries)
+
+	w = get("application/xml;q=0.9, application/json;q=0.5")
+	assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+	assert.Equal(t, first.Misses, r.NegotiationStats().Misses, "repeat Accept must hit")
+	assert.Greater(t, r.NegotiationStats().Hits, first.Hits)
+
+	// Negative results are cached too.
+	assert.Equal(t, http.StatusNotAcceptable, get("text/csv").Code)
+	assert.Equal(t, http.StatusNotAcceptable, get("text/csv").Code)
+
+	// Capacity is enforced.
+	get("application/json")
+	assert.Equal(t, 2, r.NegotiationStats().Entries)
+}
+
+func TestNegotiate_cache_disabled(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.WithNegotiationCache(0))
+	api.Get(r, "/greet", func(_ context.Context, _ *api.Void) (*api.Resp[greetResp], error) {
+		return &api.Resp[greetResp]{Body: greetResp{Message: "hello"}}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/greet", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+	assert.Equal(t, api.NegotiationStats{}, r.NegotiationStats())
+}
+
+// charsetEncoder declares a content-type parameter.
+type charsetEncoder struct{}
+
+func (charsetEncoder) ContentType() string { return "text/csv; charset=iso-8859-1" }
+func (charsetEncoder) Encode(w io.Writer, _ any) error {
+	_, err := io.WriteString(w, "message\nhello\n")
+	return err
+}
+
+func TestNegotiate_content_type_parameters(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		opts   []api.RouterOption
+		accept string
+		want   string
+	}{
+		"encoder parameters are sent": {
+			accept: "text/csv",
+			want:   "text/csv; charset=iso-8859-1",
+		},
+		"accept parameters are ignored": {
+			accept: "text/csv; charset=utf-8",
+			want:   "text/csv; charset=iso-8859-1",
+		},
+		"default charset on json": {
+			opts: []api.RouterOption{api.WithDefaultCharset("utf-8")},
+			want: "application/json; charset=utf-8",
+		},
+		"default charset keeps encoder charset": {
+			opts:   []api.RouterOption{api.WithDefaultCharset("utf-8")},
+			accept: "TEXT/CSV",
+			want:   "text/csv; charset=iso-8859-1",
+		},
+		"no charset by default": {
+			want: "application/json",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New(append(tc.opts, api.WithEncoder(charsetEncoder{}))...)
+			api.Get(r, "/greet", func(_ context.Context, _ *api.Void) (*api.Resp[greetResp], error) {
+				return &api.Resp[greetResp]{Body: greetResp{Message: "hello"}}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/greet", http.NoBody)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tc.want, w.Header().Get("Content-Type"))
+		})
+	}
+
+	spec := api.New(api.WithEncoder(charsetEncoder{}))
+	api.Get(spec, "/greet", func(_ context.Context, _ *api.Void) (*api.Resp[greetResp], error) { return nil, nil })
+	assert.Contains(t, spec.Spec().Paths["/greet"]["get"].Responses["200"].Content, "text/csv")
+}
+
+func TestNegotiate_default_charset_on_errors(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.WithDefaultCharset("utf-8"))
+	api.Get(r, "/fail", func(_ context.Context, _ *api.Void) (*api.Resp[greetResp], error) {
+		return nil, api.Error(api.CodeNotFound)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", http.NoBody))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/problem+json; charset=utf-8", w.Header().Get("Content-Type"))
+}
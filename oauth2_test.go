@@ -0,0 +1,214 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	t.Parallel()
+
+	scopes := map[string]string{"read": "Read access"}
+	r := api.New(api.WithSecurityScheme("oauth", api.OAuth2ClientCredentials("https://auth.example.com/token", scopes)))
+
+	spec := r.Spec()
+	scheme := spec.Components.SecuritySchemes["oauth"]
+	assert.Equal(t, "oauth2", scheme.Type)
+	require.NotNil(t, scheme.Flows)
+	require.NotNil(t, scheme.Flows.ClientCredentials)
+	assert.Equal(t, "https://auth.example.com/token", scheme.Flows.ClientCredentials.TokenURL)
+	assert.Equal(t, scopes, scheme.Flows.ClientCredentials.Scopes)
+	assert.Nil(t, scheme.Flows.AuthorizationCode)
+}
+
+func TestOAuth2_flow_builders(t *testing.T) {
+	t.Parallel()
+
+	scopes := map[string]string{"read": "Read access"}
+
+	code := api.OAuth2AuthorizationCode("https://a/authorize", "https://a/token", scopes)
+	require.NotNil(t, code.Flows.AuthorizationCode)
+	assert.Equal(t, "https://a/authorize", code.Flows.AuthorizationCode.AuthorizationURL)
+	assert.Equal(t, "https://a/token", code.Flows.AuthorizationCode.TokenURL)
+
+	implicit := api.OAuth2Implicit("https://a/authorize", scopes)
+	require.NotNil(t, implicit.Flows.Implicit)
+	assert.Equal(t, "https://a/authorize", implicit.Flows.Implicit.AuthorizationURL)
+
+	password := api.OAuth2Password("https://a/token", scopes)
+	require.NotNil(t, password.Flows.Password)
+	assert.Equal(t, "https://a/token", password.Flows.Password.TokenURL)
+}
+
+func newIntrospectionServer(t *testing.T, tokens map[string]api.Introspection) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "rs" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.NoError(t, r.ParseForm())
+		result := tokens[r.PostForm.Get("token")]
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck,gosec
+		json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestIntrospect(t *testing.T) {
+	t.Parallel()
+
+	authSrv := newIntrospectionServer(t, map[string]api.Introspection{
+		"good":    {Active: true, Scope: "read write", Sub: "user-1"},
+		"narrow":  {Active: true, Scope: "write", Sub: "user-2"},
+		"revoked": {Active: false},
+	})
+
+	mw := api.Introspect(api.IntrospectionConfig{
+		Endpoint:     authSrv.URL,
+		ClientID:     "rs",
+		ClientSecret: "s3cret",
+		Scopes:       []string{"read"},
+	})
+
+	var gotSub string
+	srv := httptest.NewServer(mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i, ok := api.GetIntrospection(r.Context())
+		if ok {
+			gotSub = i.Sub
+		}
+		w.WriteHeader(http.StatusOK)
+	})))
+	t.Cleanup(srv.Close)
+
+	tests := map[string]struct {
+		auth       string
+		wantStatus int
+		wantAuth   string
+	}{
+		"missing token":        {auth: "", wantStatus: http.StatusUnauthorized, wantAuth: "Bearer"},
+		"non-bearer scheme":    {auth: "Basic abc", wantStatus: http.StatusUnauthorized, wantAuth: "Bearer"},
+		"inactive token":       {auth: "Bearer revoked", wantStatus: http.StatusUnauthorized, wantAuth: `Bearer error="invalid_token"`},
+		"unknown token":        {auth: "Bearer nope", wantStatus: http.StatusUnauthorized, wantAuth: `Bearer error="invalid_token"`},
+		"missing scope":        {auth: "Bearer narrow", wantStatus: http.StatusForbidden, wantAuth: `Bearer error="insufficient_scope", scope="read"`},
+		"active with scope ok": {auth: "Bearer good", wantStatus: http.StatusOK},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+			require.NoError(t, err)
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer func() { require.NoError(t, resp.Body.Close()) }()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+			assert.Equal(t, tc.wantAuth, resp.Header.Get("WWW-Authenticate"))
+			if tc.wantStatus != http.StatusOK {
+				assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+
+	assert.Equal(t, "user-1", gotSub)
+}
+
+func TestIntrospect_principal(t *testing.T) {
+	t.Parallel()
+
+	authSrv := newIntrospectionServer(t, map[string]api.Introspection{
+		"user":   {Active: true, Scope: "read write", Sub: "user-1", ClientID: "web", Iss: "https://id.example.com"},
+		"client": {Active: true, Scope: "read", ClientID: "batch-job"},
+	})
+	mw := api.Introspect(api.IntrospectionConfig{Endpoint: authSrv.URL, ClientID: "rs", ClientSecret: "s3cret"})
+
+	tests := map[string]struct {
+		token string
+		want  *api.Principal
+	}{
+		"subject": {
+			token: "user",
+			want: &api.Principal{
+				ID:         "user-1",
+				Scopes:     []string{"read", "write"},
+				Attributes: map[string]any{"client_id": "web", "iss": "https://id.example.com"},
+			},
+		},
+		"client only": {
+			token: "client",
+			want: &api.Principal{
+				ID:         "batch-job",
+				Scopes:     []string{"read"},
+				Attributes: map[string]any{"client_id": "batch-job"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got *api.Principal
+			h := mw(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got, _ = api.GetPrincipal(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestIntrospect_endpoint_failure(t *testing.T) {
+	t.Parallel()
+
+	authSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(authSrv.Close)
+
+	mw := api.Introspect(api.IntrospectionConfig{Endpoint: authSrv.URL, ClientID: "rs", ClientSecret: "s3cret"})
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer t")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
+
+func TestIntrospect_scope_must_be_declared(t *testing.T) {
+	t.Parallel()
+
+	scheme := api.OAuth2ClientCredentials("https://a/token", map[string]string{"read": "Read"})
+
+	assert.NotPanics(t, func() {
+		api.Introspect(api.IntrospectionConfig{Endpoint: "https://a/introspect", Scheme: &scheme, Scopes: []string{"read"}})
+	})
+	assert.PanicsWithValue(t,
+		`api: Introspect requires scope "admin" which the security scheme does not declare`,
+		func() {
+			api.Introspect(api.IntrospectionConfig{Endpoint: "https://a/introspect", Scheme: &scheme, Scopes: []string{"admin"}})
+		})
+	assert.Panics(t, func() { api.Introspect(api.IntrospectionConfig{}) })
+}
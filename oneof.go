@@ -0,0 +1,147 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// OneOf2 is a body that is exactly one of two types, for polymorphic
+// responses such as a payment method that is a card or a bank account:
+//
+//	type Card struct {
+//	    Type  string `json:"type" discriminator:"card"`
+//	    Last4 string `json:"last4"`
+//	}
+//	type BankAccount struct {
+//	    Type string `json:"type" discriminator:"bank_account"`
+//	    IBAN string `json:"iban"`
+//	}
+//
+//	func (h *Handler) Get(ctx context.Context, req *GetReq) (*api.Resp[api.OneOf2[Card, BankAccount]], error) {
+//	    return &api.Resp[api.OneOf2[Card, BankAccount]]{Body: api.OneOf2[Card, BankAccount]{A: &card}}, nil
+//	}
+//
+// The spec documents the body as oneOf the member schemas. When every
+// member is a struct with a string field tagged discriminator, and the
+// fields share a JSON name, the schema adds a discriminator mapping each
+// tag value to its member.
+//
+// Set exactly one member. The first non-nil member is encoded in place of
+// the wrapper, with an empty discriminator field filled in from its tag;
+// no member encodes as null. Nested in another body, a OneOf encodes
+// through encoding/json, without the router's TimeFormat.
+type OneOf2[A, B any] struct {
+	A *A
+	B *B
+}
+
+// OneOf3 is OneOf2 with three members.
+type OneOf3[A, B, C any] struct {
+	A *A
+	B *B
+	C *C
+}
+
+// OneOf4 is OneOf2 with four members.
+type OneOf4[A, B, C, D any] struct {
+	A *A
+	B *B
+	C *C
+	D *D
+}
+
+// oneOf is implemented by the OneOf types.
+type oneOf interface {
+	oneOfValue() any
+	oneOfTypes() []reflect.Type
+}
+
+var oneOfType = reflect.TypeFor[oneOf]()
+
+func (o OneOf2[A, B]) oneOfValue() any       { return firstMember(o.A, o.B) }
+func (o OneOf3[A, B, C]) oneOfValue() any    { return firstMember(o.A, o.B, o.C) }
+func (o OneOf4[A, B, C, D]) oneOfValue() any { return firstMember(o.A, o.B, o.C, o.D) }
+
+func (OneOf2[A, B]) oneOfTypes() []reflect.Type {
+	return []reflect.Type{reflect.TypeFor[A](), reflect.TypeFor[B]()}
+}
+
+func (OneOf3[A, B, C]) oneOfTypes() []reflect.Type {
+	return []reflect.Type{reflect.TypeFor[A](), reflect.TypeFor[B](), reflect.TypeFor[C]()}
+}
+
+func (OneOf4[A, B, C, D]) oneOfTypes() []reflect.Type {
+	return []reflect.Type{reflect.TypeFor[A](), reflect.TypeFor[B](), reflect.TypeFor[C](), reflect.TypeFor[D]()}
+}
+
+// MarshalJSON encodes the member that is set.
+func (o OneOf2[A, B]) MarshalJSON() ([]byte, error) { return json.Marshal(o.oneOfValue()) }
+
+// MarshalJSON encodes the member that is set.
+func (o OneOf3[A, B, C]) MarshalJSON() ([]byte, error) { return json.Marshal(o.oneOfValue()) }
+
+// MarshalJSON encodes the member that is set.
+func (o OneOf4[A, B, C, D]) MarshalJSON() ([]byte, error) { return json.Marshal(o.oneOfValue()) }
+
+// firstMember returns the first non-nil member pointer, with its
+// discriminator filled in, or nil.
+func firstMember(members ...any) any {
+	for _, m := range members {
+		rv := reflect.ValueOf(m)
+		if rv.IsNil() {
+			continue
+		}
+		f, value, ok := discriminatorField(rv.Type().Elem())
+		if !ok || !rv.Elem().FieldByIndex(f.Index).IsZero() {
+			return m
+		}
+		filled := reflect.New(rv.Type().Elem())
+		filled.Elem().Set(rv.Elem())
+		filled.Elem().FieldByIndex(f.Index).SetString(value)
+		return filled.Interface()
+	}
+	return nil
+}
+
+// discriminatorField returns t's string field tagged discriminator and
+// the tag's value.
+func discriminatorField(t reflect.Type) (reflect.StructField, string, bool) {
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, "", false
+	}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if v, ok := f.Tag.Lookup("discriminator"); ok && f.IsExported() && f.Type.Kind() == reflect.String {
+			return f, v, true
+		}
+	}
+	return reflect.StructField{}, "", false
+}
+
+// oneOfSchema documents a OneOf type as oneOf its members, with a
+// discriminator when every member declares one under the same name.
+func (r *schemaRegistry) oneOfSchema(types []reflect.Type) JSONSchema {
+	var schema JSONSchema
+	disc := &Discriminator{}
+	for _, t := range types {
+		member := r.typeToSchema(t)
+		schema.OneOf = append(schema.OneOf, member)
+
+		f, value, ok := discriminatorField(derefType(t))
+		if !ok || disc != nil && disc.PropertyName != "" && disc.PropertyName != jsonFieldName(f) {
+			disc = nil
+		}
+		if disc == nil {
+			continue
+		}
+		disc.PropertyName = jsonFieldName(f)
+		if member.Ref != "" {
+			if disc.Mapping == nil {
+				disc.Mapping = make(map[string]string)
+			}
+			disc.Mapping[value] = member.Ref
+		}
+	}
+	schema.Discriminator = disc
+	return schema
+}
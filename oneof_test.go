@@ -0,0 +1,119 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type oneOfCard struct {
+	Type  string `json:"type" discriminator:"card"`
+	Last4 string `json:"last4"`
+}
+
+type oneOfBank struct {
+	Type string `json:"type" discriminator:"bank_account"`
+	IBAN string `json:"iban"`
+}
+
+type oneOfWallet struct {
+	Provider string `json:"provider"`
+}
+
+type paymentMethod = api.OneOf2[oneOfCard, oneOfBank]
+
+func TestOneOf_encode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		body paymentMethod
+		want string
+	}{
+		"first member": {
+			body: paymentMethod{A: &oneOfCard{Type: "card", Last4: "4242"}},
+			want: `{"type":"card","last4":"4242"}`,
+		},
+		"discriminator filled in": {
+			body: paymentMethod{B: &oneOfBank{IBAN: "DE89"}},
+			want: `{"type":"bank_account","iban":"DE89"}`,
+		},
+		"no member": {
+			want: `null`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New()
+			api.Get(r, "/method", func(_ context.Context, _ *api.Void) (*api.Resp[paymentMethod], error) {
+				return &api.Resp[paymentMethod]{Body: tc.body}, nil
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/method", http.NoBody))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, tc.want, w.Body.String())
+		})
+	}
+}
+
+func TestOneOf_nested(t *testing.T) {
+	t.Parallel()
+
+	type wallet struct {
+		Methods []paymentMethod `json:"methods"`
+	}
+
+	r := api.New()
+	api.Get(r, "/wallet", func(_ context.Context, _ *api.Void) (*api.Resp[wallet], error) {
+		return &api.Resp[wallet]{Body: wallet{Methods: []paymentMethod{
+			{A: &oneOfCard{Last4: "4242"}},
+			{B: &oneOfBank{IBAN: "DE89"}},
+		}}}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/wallet", http.NoBody))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"methods":[{"type":"card","last4":"4242"},{"type":"bank_account","iban":"DE89"}]}`, w.Body.String())
+}
+
+func TestOneOf_spec(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/method", func(_ context.Context, _ *api.Void) (*api.Resp[paymentMethod], error) { return nil, nil })
+	api.Get(r, "/any", func(_ context.Context, _ *api.Void) (*api.Resp[api.OneOf3[oneOfCard, oneOfBank, oneOfWallet]], error) {
+		return nil, nil
+	})
+	spec := r.Spec()
+
+	schema := spec.Paths["/method"]["get"].Responses["200"].Content["application/json"].Schema
+	require.NotNil(t, schema)
+	assert.Equal(t, []api.JSONSchema{
+		{Ref: "#/components/schemas/oneOfCard"},
+		{Ref: "#/components/schemas/oneOfBank"},
+	}, schema.OneOf)
+	require.NotNil(t, schema.Discriminator)
+	assert.Equal(t, "type", schema.Discriminator.PropertyName)
+	assert.Equal(t, map[string]string{
+		"card":         "#/components/schemas/oneOfCard",
+		"bank_account": "#/components/schemas/oneOfBank",
+	}, schema.Discriminator.Mapping)
+	assert.Contains(t, spec.Components.Schemas, "oneOfCard")
+
+	// A member without a discriminator drops it for the union.
+	schema = spec.Paths["/any"]["get"].Responses["200"].Content["application/json"].Schema
+	assert.Len(t, schema.OneOf, 3)
+	assert.Nil(t, schema.Discriminator)
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// OPAConfig configures an Open Policy Agent PolicyEngine backed by OPA's
+// REST Data API.
+type OPAConfig struct {
+	// URL is the data API document to query, e.g.
+	// "http://localhost:8181/v1/data/httpapi/authz". Required.
+	URL string
+
+	// Client performs the query. Defaults to a client with a 2s timeout.
+	Client *http.Client
+
+	// Input builds the policy input document. Defaults to PolicyInput.
+	Input func(AccessRequest) any
+}
+
+// OPA returns a PolicyEngine that posts {"input": ...} to an OPA server and
+// interprets the result (see OPAQuery for the accepted shapes). Transport
+// failures and non-200 responses are errors, which fail the request with
+// 500 rather than allowing it.
+func OPA(cfg OPAConfig) PolicyEngine {
+	if cfg.URL == "" {
+		panic("api: OPA requires a URL")
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 2 * time.Second}
+	}
+	return OPAQuery(cfg.Input, func(ctx context.Context, input any) (any, error) {
+		return queryOPA(ctx, cfg, input)
+	})
+}
+
+// OPAQuery adapts an embedded rego evaluation to a PolicyEngine. eval
+// receives the input document (built by input, or PolicyInput when nil)
+// and returns the query result:
+//
+//	pq, _ := rego.New(rego.Query("data.httpapi.authz"), rego.Module(...)).PrepareForEval(ctx)
+//	policy := api.OPAQuery(nil, func(ctx context.Context, input any) (any, error) {
+//	    rs, err := pq.Eval(ctx, rego.EvalInput(input))
+//	    if err != nil || len(rs) == 0 {
+//	        return nil, err
+//	    }
+//	    return rs[0].Expressions[0].Value, nil
+//	})
+//
+// A boolean result is the decision. An object result is read as
+// {"allow": bool, "reason": string}. A nil (undefined) result denies.
+func OPAQuery(input func(AccessRequest) any, eval func(ctx context.Context, input any) (any, error)) PolicyEngine {
+	if input == nil {
+		input = func(req AccessRequest) any { return PolicyInput(req) }
+	}
+	return PolicyFunc(func(ctx context.Context, req AccessRequest) (Decision, error) {
+		result, err := eval(ctx, input(req))
+		if err != nil {
+			return Decision{}, err
+		}
+		return opaDecision(result)
+	})
+}
+
+// opaDecision interprets an OPA query result.
+func opaDecision(result any) (Decision, error) {
+	switch v := result.(type) {
+	case nil:
+		return Deny("policy is undefined for this request"), nil
+	case bool:
+		if v {
+			return Allow(), nil
+		}
+		return Deny(""), nil
+	case map[string]any:
+		allow, ok := v["allow"].(bool)
+		if !ok {
+			return Deny("policy is undefined for this request"), nil
+		}
+		reason, _ := v["reason"].(string) //nolint:errcheck // reason is optional
+		return Decision{Allow: allow, Reason: reason}, nil
+	default:
+		return Decision{}, fmt.Errorf("api: unexpected OPA result type %T", result)
+	}
+}
+
+var errOPAStatus = errors.New("OPA query failed")
+
+func queryOPA(ctx context.Context, cfg OPAConfig, input any) (any, error) {
+	body, err := json.Marshal(map[string]any{"input": input})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cfg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", errOPAStatus, resp.StatusCode)
+	}
+
+	var out struct {
+		Result any `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode OPA response: %w", err)
+	}
+	return out.Result, nil
+}
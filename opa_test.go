@@ -0,0 +1,136 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func TestOPA(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		status   int
+		result   string
+		roles    string
+		wantCode int
+		wantBody string
+	}{
+		"boolean allow": {
+			status:   http.StatusOK,
+			result:   `{"result":true}`,
+			roles:    "reader",
+			wantCode: http.StatusOK,
+		},
+		"object deny with reason": {
+			status:   http.StatusOK,
+			result:   `{"result":{"allow":false,"reason":"outside business hours"}}`,
+			roles:    "reader",
+			wantCode: http.StatusForbidden,
+			wantBody: "outside business hours",
+		},
+		"undefined denies": {
+			status:   http.StatusOK,
+			result:   `{}`,
+			roles:    "reader",
+			wantCode: http.StatusForbidden,
+		},
+		"anonymous denial is 401": {
+			status:   http.StatusOK,
+			result:   `{"result":false}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		"server error fails closed": {
+			status:   http.StatusInternalServerError,
+			result:   `{}`,
+			roles:    "reader",
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var input map[string]any
+			opa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Input map[string]any `json:"input"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err == nil {
+					input = body.Input
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.result))
+			}))
+			t.Cleanup(opa.Close)
+
+			r := api.New(api.Authorize(api.OPA(api.OPAConfig{URL: opa.URL + "/v1/data/authz"})))
+			r.Use(withPrincipal)
+			api.Get(r, "/orders/{id}", func(_ context.Context, req *authzOrderReq) (*api.Resp[string], error) {
+				return &api.Resp[string]{Body: req.ID}, nil
+			}, api.WithOperationID("getOrder"))
+
+			req := httptest.NewRequest(http.MethodGet, "/orders/o1", nil)
+			if tt.roles != "" {
+				req.Header.Set("X-Roles", tt.roles)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantBody != "" {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			}
+
+			require.NotNil(t, input)
+			assert.Equal(t, "getOrder", input["operation"])
+			assert.Equal(t, "GET", input["method"])
+			assert.Equal(t, "/orders/{id}", input["path"])
+			assert.Equal(t, map[string]any{"ID": "o1"}, input["input"])
+			if tt.roles != "" {
+				principal, ok := input["principal"].(map[string]any)
+				require.True(t, ok)
+				assert.Equal(t, "u1", principal["id"])
+				assert.Equal(t, []any{"reader"}, principal["roles"])
+			} else {
+				assert.Nil(t, input["principal"])
+			}
+		})
+	}
+}
+
+func TestOPAQuery_embedded(t *testing.T) {
+	t.Parallel()
+
+	policy := api.OPAQuery(nil, func(_ context.Context, input any) (any, error) {
+		doc, _ := input.(map[string]any)
+		return map[string]any{"allow": doc["method"] == http.MethodGet}, nil
+	})
+
+	ctx := context.Background()
+	got, err := policy.Decide(ctx, api.AccessRequest{Route: api.RouteDescription{Method: http.MethodGet}})
+	require.NoError(t, err)
+	assert.True(t, got.Allow)
+
+	got, err = policy.Decide(ctx, api.AccessRequest{Route: api.RouteDescription{Method: http.MethodPost}})
+	require.NoError(t, err)
+	assert.False(t, got.Allow)
+
+	bad := api.OPAQuery(nil, func(context.Context, any) (any, error) { return 42, nil })
+	_, err = bad.Decide(ctx, api.AccessRequest{})
+	assert.Error(t, err)
+}
+
+func TestOPA_requires_url(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() { api.OPA(api.OPAConfig{}) })
+}
@@ -1,8 +1,11 @@
 package api
 
 import (
+	"fmt"
+	"maps"
 	"net/http"
 	"reflect"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -24,27 +27,45 @@ type HeaderObj struct {
 
 // OpenAPISpec is the top-level OpenAPI 3.1 document.
 type OpenAPISpec struct {
-	OpenAPI    string                `json:"openapi"`
-	Info       OpenAPIInfo           `json:"info"`
-	Servers    []Server              `json:"servers,omitempty"`
-	Paths      map[string]PathItem   `json:"paths"`
-	Components *Components           `json:"components,omitempty"`
-	Tags       []TagObj              `json:"tags,omitempty"`
-	Security   []SecurityRequirement `json:"security,omitempty"`
-	Webhooks   map[string]PathItem   `json:"webhooks,omitempty"`
-	Extensions map[string]any        `json:"extensions,omitempty"`
+	OpenAPI      string                `json:"openapi"`
+	Info         OpenAPIInfo           `json:"info"`
+	Servers      []Server              `json:"servers,omitempty"`
+	Paths        map[string]PathItem   `json:"paths"`
+	Components   *Components           `json:"components,omitempty"`
+	Tags         []TagObj              `json:"tags,omitempty"`
+	ExternalDocs *ExternalDocs         `json:"externalDocs,omitempty"`
+	Security     []SecurityRequirement `json:"security,omitempty"`
+	Webhooks     map[string]PathItem   `json:"webhooks,omitempty"`
+	Extensions   map[string]any        `json:"extensions,omitempty"`
 }
 
 // Server describes an API server.
 type Server struct {
-	URL         string `json:"url"`
-	Description string `json:"description,omitempty"`
+	URL         string                    `json:"url"`
+	Description string                    `json:"description,omitempty"`
+	Variables   map[string]ServerVariable `json:"variables,omitempty"`
+}
+
+// ServerVariable substitutes a {name} in a Server URL. Default is
+// required; Enum, when set, lists the allowed values and must include it.
+type ServerVariable struct {
+	Enum        []string `json:"enum,omitempty"`
+	Default     string   `json:"default"`
+	Description string   `json:"description,omitempty"`
 }
 
 // TagObj describes a tag with an optional description.
 type TagObj struct {
-	Name        string `json:"name"`
+	Name         string        `json:"name"`
+	Description  string        `json:"description,omitempty"`
+	ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+}
+
+// ExternalDocs links to documentation outside the spec, such as a
+// developer portal page.
+type ExternalDocs struct {
 	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
 }
 
 // SecurityRequirement maps security scheme names to required scopes.
@@ -86,8 +107,27 @@ type Components struct {
 
 // OpenAPIInfo holds API metadata.
 type OpenAPIInfo struct {
-	Title   string `json:"title"`
-	Version string `json:"version"`
+	Title          string   `json:"title"`
+	Description    string   `json:"description,omitempty"`
+	TermsOfService string   `json:"termsOfService,omitempty"`
+	Contact        *Contact `json:"contact,omitempty"`
+	License        *License `json:"license,omitempty"`
+	Version        string   `json:"version"`
+}
+
+// Contact is the contact information for the API.
+type Contact struct {
+	Name  string `json:"name,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// License is the license of the API: its name and either an SPDX
+// identifier or a URL.
+type License struct {
+	Name       string `json:"name"`
+	Identifier string `json:"identifier,omitempty"`
+	URL        string `json:"url,omitempty"`
 }
 
 // PathItem maps HTTP methods to operations.
@@ -95,26 +135,32 @@ type PathItem map[string]Operation
 
 // Operation describes a single API operation on a path.
 type Operation struct {
-	Summary     string                         `json:"summary,omitempty"`
-	Description string                         `json:"description,omitempty"`
-	Tags        []string                       `json:"tags,omitempty"`
-	OperationID string                         `json:"operationId,omitempty"`
-	Parameters  []Parameter                    `json:"parameters,omitempty"`
-	RequestBody *RequestBody                   `json:"requestBody,omitempty"`
-	Responses   OperationResp                  `json:"responses"`
-	Deprecated  bool                           `json:"deprecated,omitempty"`
-	Security    *[]SecurityRequirement         `json:"security,omitempty"`
-	Callbacks   map[string]map[string]PathItem `json:"callbacks,omitempty"`
-	Extensions  map[string]any                 `json:"extensions,omitempty"`
+	Summary      string                         `json:"summary,omitempty"`
+	Description  string                         `json:"description,omitempty"`
+	ExternalDocs *ExternalDocs                  `json:"externalDocs,omitempty"`
+	Tags         []string                       `json:"tags,omitempty"`
+	OperationID  string                         `json:"operationId,omitempty"`
+	Parameters   []Parameter                    `json:"parameters,omitempty"`
+	RequestBody  *RequestBody                   `json:"requestBody,omitempty"`
+	Responses    OperationResp                  `json:"responses"`
+	Deprecated   bool                           `json:"deprecated,omitempty"`
+	Security     *[]SecurityRequirement         `json:"security,omitempty"`
+	Callbacks    map[string]map[string]PathItem `json:"callbacks,omitempty"`
+	Extensions   map[string]any                 `json:"extensions,omitempty"`
 }
 
 // Parameter describes a single operation parameter.
 type Parameter struct {
-	Name        string     `json:"name"`
-	In          string     `json:"in"`
-	Description string     `json:"description,omitempty"`
-	Required    bool       `json:"required,omitempty"`
-	Schema      JSONSchema `json:"schema"`
+	Name            string     `json:"name"`
+	In              string     `json:"in"`
+	Description     string     `json:"description,omitempty"`
+	Required        bool       `json:"required,omitempty"`
+	Deprecated      bool       `json:"deprecated,omitempty"`
+	AllowEmptyValue bool       `json:"allowEmptyValue,omitempty"`
+	Style           string     `json:"style,omitempty"`
+	Explode         *bool      `json:"explode,omitempty"`
+	Schema          JSONSchema `json:"schema"`
+	Example         any        `json:"example,omitempty"`
 }
 
 // RequestBody describes the request body.
@@ -125,7 +171,20 @@ type RequestBody struct {
 
 // MediaObj is a media type object with an optional schema.
 type MediaObj struct {
-	Schema *JSONSchema `json:"schema,omitempty"`
+	Schema   *JSONSchema            `json:"schema,omitempty"`
+	Encoding map[string]EncodingObj `json:"encoding,omitempty"`
+	Examples map[string]ExampleObj  `json:"examples,omitempty"`
+}
+
+// ExampleObj is a named example of a whole request or response body.
+type ExampleObj struct {
+	Summary string `json:"summary,omitempty"`
+	Value   any    `json:"value"`
+}
+
+// EncodingObj describes how a single multipart property is serialized.
+type EncodingObj struct {
+	ContentType string `json:"contentType,omitempty"`
 }
 
 // OperationResp maps HTTP status codes to response objects.
@@ -141,17 +200,92 @@ type ResponseObj struct {
 
 // Spec generates the full OpenAPI 3.1 specification from registered routes.
 func (r *Router) Spec() OpenAPISpec {
+	return r.HostSpec("")
+}
+
+// HostSpec generates the OpenAPI spec served to host: the routes bound to
+// it with Router.Host, plus the routes that serve every host and are not
+// shadowed by one of its own. HostSpec("") equals Spec.
+func (r *Router) HostSpec(host string) OpenAPISpec {
+	spec := r.specHeader()
+	spec.Paths = make(map[string]PathItem)
+
+	reg := newSchemaRegistry()
+	reg.timeFormat = r.timeFormat
+	reg.bigIntStrings = r.bigIntStrings
+	reg.nameAnonymous = r.anonymousResponseNames
+
+	codecCTs := r.codecs.contentTypes()
+
+	routes := r.specRoutes(host)
+	reg.nameGenerics(routes)
+	for _, ri := range routes {
+		path := toOpenAPIPath(ri.pattern)
+		method := strings.ToLower(ri.method)
+
+		op := buildOperation(ri, reg, codecCTs)
+
+		if spec.Paths[path] == nil {
+			spec.Paths[path] = make(PathItem)
+		}
+		spec.Paths[path][method] = op
+	}
+
+	spec.Components = r.specComponents(reg)
+
+	return spec
+}
+
+// specRoutes returns the routes documented for host, in registration
+// order. Routes are never modified once added, so the result can be read
+// after the lock is released.
+func (r *Router) specRoutes(host string) []*routeInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	own := make(map[string]bool)
+	for i := range r.routes {
+		if ri := &r.routes[i]; ri.host != "" && ri.host == host {
+			own[ri.method+" "+ri.pattern] = true
+		}
+	}
+	out := make([]*routeInfo, 0, len(r.routes))
+	for i := range r.routes {
+		ri := &r.routes[i]
+		if ri.host == host || ri.host == "" && !own[ri.method+" "+ri.pattern] {
+			out = append(out, ri)
+		}
+	}
+	return out
+}
+
+// specHeader returns the document fields that do not depend on routes:
+// everything except Paths and Components.
+func (r *Router) specHeader() OpenAPISpec {
 	spec := OpenAPISpec{
 		OpenAPI: "3.1.0",
 		Info: OpenAPIInfo{
-			Title:   r.title,
-			Version: r.version,
+			Title:          r.title,
+			Description:    r.description,
+			TermsOfService: r.termsOfService,
+			Contact:        r.contact,
+			License:        r.license,
+			Version:        r.version,
 		},
-		Paths: make(map[string]PathItem),
 	}
 
 	if len(r.servers) > 0 {
-		spec.Servers = r.servers
+		spec.Servers = make([]Server, len(r.servers))
+		for i, s := range r.servers {
+			if vars := r.serverVars[s.URL]; len(vars) > 0 {
+				s.Variables = maps.Clone(s.Variables)
+				if s.Variables == nil {
+					s.Variables = make(map[string]ServerVariable, len(vars))
+				}
+				maps.Copy(s.Variables, vars)
+			}
+			spec.Servers[i] = s
+		}
 	}
 
 	if len(r.security) > 0 {
@@ -160,45 +294,39 @@ func (r *Router) Spec() OpenAPISpec {
 		}
 	}
 
-	if len(r.tagDescs) > 0 {
-		names := make([]string, 0, len(r.tagDescs))
+	if len(r.tagDescs) > 0 || len(r.tagDocs) > 0 {
+		names := make([]string, 0, len(r.tagDescs)+len(r.tagDocs))
 		for name := range r.tagDescs {
 			names = append(names, name)
 		}
+		for name := range r.tagDocs {
+			if _, ok := r.tagDescs[name]; !ok {
+				names = append(names, name)
+			}
+		}
 		sort.Strings(names)
 		for _, name := range names {
-			spec.Tags = append(spec.Tags, TagObj{Name: name, Description: r.tagDescs[name]})
+			spec.Tags = append(spec.Tags, TagObj{Name: name, Description: r.tagDescs[name], ExternalDocs: r.tagDocs[name]})
 		}
 	}
 
-	reg := newSchemaRegistry()
-
-	codecCTs := r.codecs.contentTypes()
-
-	for i := range r.routes {
-		ri := &r.routes[i]
-		path := toOpenAPIPath(ri.pattern)
-		method := strings.ToLower(ri.method)
-
-		op := buildOperation(ri, reg, codecCTs)
+	spec.ExternalDocs = r.externalDocs
 
-		if spec.Paths[path] == nil {
-			spec.Paths[path] = make(PathItem)
-		}
-		spec.Paths[path][method] = op
+	if len(r.webhooks) > 0 {
+		spec.Webhooks = r.webhooks
 	}
 
+	return spec
+}
+
+// specComponents returns the components section once every operation has
+// registered its schemas in reg.
+func (r *Router) specComponents(reg *schemaRegistry) *Components {
 	comp := &Components{Schemas: reg.defs}
 	if len(r.securitySchemes) > 0 {
 		comp.SecuritySchemes = r.securitySchemes
 	}
-	spec.Components = comp
-
-	if len(r.webhooks) > 0 {
-		spec.Webhooks = r.webhooks
-	}
-
-	return spec
+	return comp
 }
 
 // errorResponseContent computes the content map used for every error
@@ -250,16 +378,39 @@ func buildSuccessResponse(ri *routeInfo, reg *schemaRegistry, codecCTs []string,
 
 	if desc != nil && desc.body != nil {
 		switch desc.body.kind {
-		case bodyKindReader:
+		case bodyKindReader, bodyKindFile:
 			return status, ResponseObj{
 				Description: "Successful response",
 				Content:     map[string]MediaObj{"application/octet-stream": {}},
 			}
-		case bodyKindChan:
+		case bodyKindChan, bodyKindEventSeq:
 			return status, ResponseObj{
 				Description: "Successful response",
 				Content:     map[string]MediaObj{"text/event-stream": {Schema: &JSONSchema{Type: "string"}}},
 			}
+		case bodyKindJSONArray:
+			schema := reg.typeToSchema(reflect.SliceOf(desc.body.typ.Elem()))
+			return status, ResponseObj{
+				Description: "Successful response",
+				Content:     map[string]MediaObj{"application/json": {Schema: &schema}},
+			}
+		case bodyKindNDJSON:
+			item := reg.typeToSchema(desc.body.typ.Elem())
+			return status, ResponseObj{
+				Description: "Successful response",
+				Content:     map[string]MediaObj{ndjsonContentType: {Schema: &item}},
+			}
+		case bodyKindSeq:
+			elem, _, _ := seqElem(desc.body.typ)
+			schema := reg.typeToSchema(reflect.SliceOf(elem))
+			item := reg.typeToSchema(elem)
+			return status, ResponseObj{
+				Description: "Successful response",
+				Content: map[string]MediaObj{
+					"application/json": {Schema: &schema},
+					ndjsonContentType:  {Schema: &item},
+				},
+			}
 		}
 	}
 
@@ -267,7 +418,7 @@ func buildSuccessResponse(ri *routeInfo, reg *schemaRegistry, codecCTs []string,
 	if desc != nil && desc.body != nil {
 		bodyType = desc.body.typ
 	}
-	respSchema := reg.typeToSchema(bodyType)
+	respSchema := reg.responseSchema(bodyType, ri.resolvedOperationID())
 	content := make(map[string]MediaObj, len(codecCTs))
 	for _, ct := range codecCTs {
 		content[ct] = MediaObj{Schema: &respSchema}
@@ -275,6 +426,33 @@ func buildSuccessResponse(ri *routeInfo, reg *schemaRegistry, codecCTs []string,
 	return status, ResponseObj{Description: "Successful response", Content: content}
 }
 
+// responseSchema is typeToSchema for a route's success body. With
+// WithAnonymousResponseNames, an anonymous struct body is registered as
+// the component <OperationID>Response instead of inlined. It panics when
+// that name is taken by a different type.
+func (r *schemaRegistry) responseSchema(t reflect.Type, operationID string) JSONSchema {
+	t = derefType(t)
+	if !r.nameAnonymous || t.Kind() != reflect.Struct || t.Name() != "" || operationID == "" {
+		return r.typeToSchema(t)
+	}
+	name := capitalize(operationID) + "Response"
+	if owner, ok := r.anonymous[name]; ok {
+		if owner != t {
+			panic(fmt.Sprintf("api: response of operation %q: component %q is already the response of another operation", operationID, name))
+		}
+	} else {
+		if _, exists := r.defs[name]; exists {
+			panic(fmt.Sprintf("api: response of operation %q: component %q is already taken by a named type", operationID, name))
+		}
+		if r.anonymous == nil {
+			r.anonymous = make(map[string]reflect.Type)
+		}
+		r.anonymous[name] = t
+		r.defs[name] = r.structToSchema(t)
+	}
+	return JSONSchema{Ref: componentRefPrefix + name}
+}
+
 // buildExtraResponse produces a ResponseObj for a status documented via
 // WithResponse. A nil bodyType yields a body-less entry.
 func buildExtraResponse(code int, bodyType reflect.Type, reg *schemaRegistry, codecCTs []string) ResponseObj {
@@ -329,18 +507,15 @@ func buildResponseHeaders(desc *responseDescriptor) map[string]HeaderObj {
 // buildOperation creates an Operation from a routeInfo.
 func buildOperation(ri *routeInfo, reg *schemaRegistry, codecCTs []string) Operation {
 	op := Operation{
-		Summary:     ri.summary,
-		Description: ri.desc,
-		Tags:        ri.tags,
-		Deprecated:  ri.deprecated,
-		Responses:   make(OperationResp),
+		Summary:      ri.summary,
+		Description:  ri.desc,
+		ExternalDocs: ri.externalDocs,
+		Tags:         ri.tags,
+		Deprecated:   ri.deprecated,
+		Responses:    make(OperationResp),
 	}
 
-	if ri.operationID != "" {
-		op.OperationID = ri.operationID
-	} else {
-		op.OperationID = generateOperationID(ri.method, ri.pattern)
-	}
+	op.OperationID = ri.resolvedOperationID()
 
 	if ri.noSecurity {
 		empty := make([]SecurityRequirement, 0)
@@ -348,7 +523,8 @@ func buildOperation(ri *routeInfo, reg *schemaRegistry, codecCTs []string) Opera
 	} else if len(ri.security) > 0 {
 		reqs := make([]SecurityRequirement, 0, len(ri.security))
 		for _, name := range ri.security {
-			reqs = append(reqs, SecurityRequirement{name: {}})
+			scopes := append([]string{}, ri.scopes[name]...)
+			reqs = append(reqs, SecurityRequirement{name: scopes})
 		}
 		op.Security = &reqs
 	}
@@ -356,8 +532,12 @@ func buildOperation(ri *routeInfo, reg *schemaRegistry, codecCTs []string) Opera
 	// Build parameters and request body from Req type.
 	if ri.reqType != nil && ri.reqType != reflect.TypeFor[Void]() {
 		op.Parameters = extractParameters(ri.reqType)
+		constrainPathParams(op.Parameters, ri.pattern)
 		op.RequestBody = extractRequestBody(ri.reqType, ri.requestDesc, ri.method, reg, codecCTs)
 	}
+	if ri.requestContent != nil {
+		op.RequestBody = ri.requestContent
+	}
 
 	// Build success response.
 	status := ri.status
@@ -365,7 +545,13 @@ func buildOperation(ri *routeInfo, reg *schemaRegistry, codecCTs []string) Opera
 		status = http.StatusOK
 	}
 
-	status, respObj := buildSuccessResponse(ri, reg, codecCTs, status)
+	// Route encoders are offered on responses only.
+	respCTs := codecCTs
+	for _, enc := range ri.encoders {
+		respCTs = append(slices.Clip(respCTs), mediaTypeOf(enc.ContentType()))
+	}
+
+	status, respObj := buildSuccessResponse(ri, reg, respCTs, status)
 	op.Responses[statusToString(status)] = respObj
 
 	// Build error responses. The code set is the automatic baseline plus
@@ -381,8 +567,25 @@ func buildOperation(ri *routeInfo, reg *schemaRegistry, codecCTs []string) Opera
 	for _, c := range ri.errorCodes {
 		errorCodes[c.HTTPStatus()] = struct{}{}
 	}
+	// Authentication rejects secured routes with 401, and authorization
+	// rejects scoped ones with 403.
+	if !ri.noSecurity && (len(ri.security) > 0 || ri.routerSecurity) {
+		errorCodes[http.StatusUnauthorized] = struct{}{}
+	}
+	if len(ri.scopes) > 0 {
+		errorCodes[http.StatusForbidden] = struct{}{}
+	}
+	// A required claim or session value is rejected with 401 when absent.
+	if ri.requestDesc != nil && ri.requestDesc.requiresContext() {
+		errorCodes[http.StatusUnauthorized] = struct{}{}
+	}
+	for _, err := range ri.problems {
+		if e, ok := lookupProblem(err); ok {
+			errorCodes[e.code.HTTPStatus()] = struct{}{}
+		}
+	}
 
-	errContent := errorResponseContent(ri, reg, codecCTs)
+	errContent := errorResponseContent(ri, reg, respCTs)
 	for code := range errorCodes {
 		op.Responses[statusToString(code)] = ResponseObj{
 			Description: http.StatusText(code),
@@ -392,7 +595,7 @@ func buildOperation(ri *routeInfo, reg *schemaRegistry, codecCTs []string) Opera
 
 	// User-declared extra responses override anything in the auto baseline.
 	for code, bodyType := range ri.extraResponses {
-		op.Responses[statusToString(code)] = buildExtraResponse(code, bodyType, reg, codecCTs)
+		op.Responses[statusToString(code)] = buildExtraResponse(code, bodyType, reg, respCTs)
 	}
 
 	if hdrs := buildResponseHeaders(ri.responseDesc); hdrs != nil {
@@ -412,6 +615,8 @@ func buildOperation(ri *routeInfo, reg *schemaRegistry, codecCTs []string) Opera
 		}
 	}
 
+	applyExamples(&op, ri)
+
 	// Add callbacks.
 	if len(ri.callbacks) > 0 {
 		op.Callbacks = ri.callbacks
@@ -428,26 +633,45 @@ func buildOperation(ri *routeInfo, reg *schemaRegistry, codecCTs []string) Opera
 // extractParameters builds OpenAPI parameters from param-tagged fields,
 // including fields promoted from embedded structs.
 func extractParameters(t reflect.Type) []Parameter {
-	var params []Parameter
+	var (
+		params    []Parameter
+		timeRange *reflect.StructField
+	)
 	for _, f := range reflect.VisibleFields(t) {
 		if !f.IsExported() {
 			continue
 		}
 		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if f.Type == timeRangeType {
+				timeRange = &f
+			}
 			continue
 		}
 
 		for _, tagName := range paramTags {
-			val := f.Tag.Get(tagName)
-			if val == "" {
+			name, opts := tagOptions(f.Tag.Get(tagName))
+			if name == "" {
 				continue
 			}
 
-			schema := typeToSchema(f.Type)
+			valueType := f.Type
+			if b, ok := asCookieBinder(f.Type); ok {
+				valueType = b.cookieValueType()
+			}
+			if isCursorType(f.Type) {
+				valueType = reflect.TypeFor[string]()
+			}
+			deep := tagName == "query" && isDeepObjectType(f.Type)
+			var schema JSONSchema
+			if deep {
+				schema = deepObjectSchema(f.Type)
+			} else {
+				schema = typeToSchema(valueType)
+			}
 			applyConstraintTags(&schema, f)
 
 			p := Parameter{
-				Name:   val,
+				Name:   name,
 				In:     tagToIn(tagName),
 				Schema: schema,
 			}
@@ -460,13 +684,102 @@ func extractParameters(t reflect.Type) []Parameter {
 				p.Required = true
 			}
 
+			if f.Tag.Get("deprecated") == "true" {
+				p.Deprecated = true
+			}
+
+			// allowEmptyValue is only defined for query parameters.
+			if f.Tag.Get("allowEmptyValue") == "true" && tagName == "query" {
+				p.AllowEmptyValue = true
+			}
+
+			// Array query params document their serialization: repeated
+			// keys by default, comma-separated with explode:"false".
+			if tagName == "query" && isMultiValueType(f.Type) {
+				explode := f.Tag.Get("explode") != "false"
+				p.Style = "form"
+				p.Explode = &explode
+			}
+
+			// Struct query params are serialized as name[field]=value.
+			if deep {
+				explode := true
+				p.Style = "deepObject"
+				p.Explode = &explode
+			}
+
+			// The example belongs on the parameter, typed to match its schema.
+			if ex, ok := schema.Example.(string); ok {
+				p.Example = typedExample(ex, schema.Type)
+				p.Schema.Example = nil
+			}
+
 			params = append(params, p)
+
+			// Aliases keep a renamed query param working; each is
+			// documented as a deprecated parameter of its own.
+			if tagName == "query" {
+				for _, alias := range tagValues(opts, "alias") {
+					ap := p
+					ap.Name = alias
+					ap.Required = false
+					ap.Deprecated = true
+					ap.Description = "Deprecated alias of " + strconv.Quote(name) + "."
+					params = append(params, ap)
+				}
+			}
 		}
 	}
 
+	if timeRange != nil {
+		documentTimeRange(params, *timeRange)
+	}
 	return params
 }
 
+// documentTimeRange documents the form of an embedded TimeRange's range
+// parameter and adds the default and maxSpan tags of the embedding field.
+func documentTimeRange(params []Parameter, f reflect.StructField) {
+	for i := range params {
+		p := &params[i]
+		if p.In != "query" {
+			continue
+		}
+		switch p.Name {
+		case "range":
+			p.Schema.Pattern = relativeRangePattern
+			if def := f.Tag.Get("default"); def != "" {
+				p.Schema.Default = def
+				p.Description += " Without from, to, or range, the range is " + def + "."
+			}
+		case "from":
+			if span := f.Tag.Get("maxSpan"); span != "" {
+				p.Description += " The range may span at most " + span + "."
+			}
+		}
+	}
+}
+
+// typedExample converts a string example tag to the JSON type of its
+// schema, falling back to the raw string when it does not parse.
+func typedExample(ex, schemaType string) any {
+	switch schemaType {
+	case "integer":
+		if n, err := strconv.ParseInt(ex, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(ex, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(ex); err == nil {
+			return b
+		}
+	}
+	return ex
+}
+
 // extractRequestBody builds an OpenAPI RequestBody from the request
 // descriptor's category. The descriptor was built once at registration and
 // understands embedded fields.
@@ -478,14 +791,36 @@ func extractRequestBody(t reflect.Type, desc *requestDescriptor, method string,
 	switch desc.category {
 	case catForm:
 		schema := formFieldsToSchema(t)
+		media := MediaObj{Schema: &schema}
+		for _, ff := range desc.forms {
+			if ff.kind != formCodecPart {
+				continue
+			}
+			if media.Encoding == nil {
+				media.Encoding = make(map[string]EncodingObj)
+			}
+			media.Encoding[ff.name] = EncodingObj{ContentType: ff.contentType}
+		}
 		return &RequestBody{
 			Required: true,
 			Content: map[string]MediaObj{
-				"multipart/form-data": {Schema: &schema},
+				"multipart/form-data": media,
 			},
 		}
 	case catMixed:
+		if desc.streamContent != "" {
+			return &RequestBody{
+				Required: true,
+				Content: map[string]MediaObj{
+					desc.streamContent: {Schema: &JSONSchema{Type: "string", Format: "binary"}},
+				},
+			}
+		}
 		schema := reg.typeToSchema(desc.body.typ)
+		// Array and map bodies take their size bounds from the Body tag.
+		if k := derefType(desc.body.typ).Kind(); k == reflect.Slice || k == reflect.Array || k == reflect.Map {
+			applyConstraintTags(&schema, derefType(t).FieldByIndex(desc.body.index))
+		}
 		content := make(map[string]MediaObj, len(codecCTs))
 		for _, ct := range codecCTs {
 			content[ct] = MediaObj{Schema: &schema}
@@ -561,11 +896,12 @@ func tagToIn(tag string) string {
 }
 
 // toOpenAPIPath converts a Go 1.22 pattern like "/users/{id}" to
-// an OpenAPI path. Strips the method prefix and wildcard suffixes.
+// an OpenAPI path. Strips the method prefix, wildcard suffixes, and
+// wildcard constraints.
 func toOpenAPIPath(pattern string) string {
 	// Go's mux patterns can include {name...} for wildcards.
 	// OpenAPI uses {name} without the ellipsis.
-	result := strings.ReplaceAll(pattern, "...", "")
+	result := strings.ReplaceAll(stripConstraints(pattern), "...", "")
 	return result
 }
 
@@ -580,7 +916,7 @@ func generateOperationID(method, pattern string) string {
 	var b strings.Builder
 	b.WriteString(strings.ToLower(method))
 
-	parts := strings.Split(pattern, "/")
+	parts := strings.Split(stripConstraints(pattern), "/")
 	for _, part := range parts {
 		if part == "" {
 			continue
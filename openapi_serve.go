@@ -3,31 +3,33 @@ package api
 import (
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 
 	"gopkg.in/yaml.v3"
 )
 
 // ServeSpec registers a GET handler at the given path that serves
-// the OpenAPI spec as JSON.
+// the OpenAPI spec as JSON. With Router.Host routes, each host is served
+// its own HostSpec.
 func (r *Router) ServeSpec(pattern string) {
-	r.mux.HandleFunc("GET "+pattern, func(w http.ResponseWriter, _ *http.Request) {
-		spec := r.Spec()
+	r.handle("GET "+pattern, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		spec := r.HostSpec(r.specHost(req))
 		w.Header().Set("Content-Type", "application/json")
 		//nolint:errcheck,gosec // best-effort after WriteHeader
 		json.NewEncoder(w).Encode(spec)
-	})
+	}))
 }
 
 // ServeSpecYAML registers a GET handler at the given path that serves
-// the OpenAPI spec as YAML.
+// the OpenAPI spec as YAML, per host like ServeSpec.
 func (r *Router) ServeSpecYAML(pattern string) {
-	r.mux.HandleFunc("GET "+pattern, func(w http.ResponseWriter, _ *http.Request) {
-		spec := r.Spec()
+	r.handle("GET "+pattern, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		spec := r.HostSpec(r.specHost(req))
 		w.Header().Set("Content-Type", "application/yaml")
 		//nolint:errcheck,gosec // best-effort after WriteHeader
 		yaml.NewEncoder(w).Encode(spec)
-	})
+	}))
 }
 
 // WriteSpec writes the OpenAPI spec as indented JSON to w.
@@ -41,3 +43,21 @@ func (r *Router) WriteSpec(w io.Writer) error {
 func (r *Router) WriteSpecYAML(w io.Writer) error {
 	return yaml.NewEncoder(w).Encode(r.Spec())
 }
+
+// specHost returns the registered host that matches req, as the mux
+// matches it: the Host header with its port, then without.
+func (r *Router) specHost(req *http.Request) string {
+	hosts := make(map[string]bool)
+	r.mu.Lock()
+	for i := range r.routes {
+		hosts[r.routes[i].host] = true
+	}
+	r.mu.Unlock()
+	if hosts[req.Host] {
+		return req.Host
+	}
+	if h, _, err := net.SplitHostPort(req.Host); err == nil && hosts[h] {
+		return h
+	}
+	return ""
+}
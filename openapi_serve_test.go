@@ -95,3 +95,72 @@ func TestWriteSpecYAML(t *testing.T) {
 	assert.Equal(t, "3.0.0", info["version"])
 	assert.Contains(t, spec, "paths")
 }
+
+func TestWriteSpecStream(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	type GetReq struct {
+		ID string `path:"id"`
+	}
+	type CreateReq struct {
+		Body Item
+	}
+
+	r := api.New(
+		api.WithTitle("Stream Test"),
+		api.WithVersion("1.0.0"),
+		api.WithServers(api.Server{URL: "https://api.example.com"}),
+		api.WithTagDescriptions(map[string]string{"items": "Item operations"}),
+		api.WithSecurityScheme("bearer", api.SecurityScheme{Type: "http", Scheme: "bearer"}),
+	)
+	api.Get(r, "/items/{id}", func(_ context.Context, _ *GetReq) (*api.Resp[Item], error) {
+		return &api.Resp[Item]{}, nil
+	}, api.WithTags("items"), api.WithSecurity("bearer"))
+	api.Delete(r, "/items/{id}", func(_ context.Context, _ *GetReq) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+	api.Post(r, "/items", func(_ context.Context, _ *CreateReq) (*api.Resp[Item], error) {
+		return &api.Resp[Item]{}, nil
+	})
+	api.Get(r, "/a<b>", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	want, err := json.Marshal(r.Spec())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteSpecStream(&buf))
+	assert.Equal(t, string(want)+"\n", buf.String())
+}
+
+func TestWriteSpecStream_empty(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	want, err := json.Marshal(r.Spec())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteSpecStream(&buf))
+	assert.Equal(t, string(want)+"\n", buf.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, io.ErrClosedPipe }
+
+func TestWriteSpecStream_write_error(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/ping", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	assert.ErrorIs(t, r.WriteSpecStream(failingWriter{}), io.ErrClosedPipe)
+}
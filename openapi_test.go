@@ -333,6 +333,31 @@ func TestSpec_WithErrors(t *testing.T) {
 	assert.Equal(t, "Unprocessable Entity", op.Responses["422"].Description)
 }
 
+func TestSpec_scoped_errors(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.WithGlobalErrors(api.CodeTooManyRequests))
+	admin := r.Group("/admin", api.WithGroupErrors(api.CodeUnauthorized, api.CodeForbidden))
+	api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+	api.Delete(admin.Group("/items"), "/{id}", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return &api.Void{}, nil
+	}, api.WithError(api.WithErrors(api.CodeConflict)))
+
+	spec := r.Spec()
+
+	items := spec.Paths["/items"]["get"]
+	assert.Contains(t, items.Responses, "429")
+	assert.NotContains(t, items.Responses, "401")
+	assert.NotContains(t, items.Responses, "403")
+
+	del := spec.Paths["/admin/items/{id}"]["delete"]
+	for _, status := range []string{"401", "403", "409", "429"} {
+		assert.Contains(t, del.Responses, status)
+	}
+}
+
 func TestSpec_error_responses_dedup(t *testing.T) {
 	t.Parallel()
 
@@ -377,6 +402,8 @@ func TestSpec_ProblemDetails_schema(t *testing.T) {
 	// Framework extensions.
 	assert.Contains(t, pdSchema.Properties, "code")
 	assert.Contains(t, pdSchema.Properties, "errors")
+	assert.Contains(t, pdSchema.Properties, "retryable")
+	assert.Contains(t, pdSchema.Properties, "retryAfter")
 }
 
 func TestSpec_WithServers(t *testing.T) {
@@ -866,6 +893,38 @@ func TestSpec_header_and_cookie_params(t *testing.T) {
 	assert.True(t, session.Required)
 }
 
+func TestSpec_param_example_deprecated_allowEmptyValue(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Page   int    `query:"page" example:"2"`
+		Filter string `query:"filter" allowEmptyValue:"true" example:"active"`
+		Legacy string `query:"legacy" deprecated:"true"`
+		Trace  string `header:"X-Trace" allowEmptyValue:"true"`
+		Bad    int    `query:"bad" example:"lots"`
+	}
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *Req) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	op := r.Spec().Paths["/items"]["get"]
+	params := make(map[string]api.Parameter, len(op.Parameters))
+	for _, p := range op.Parameters {
+		params[p.Name] = p
+	}
+
+	assert.Equal(t, int64(2), params["page"].Example)
+	assert.Nil(t, params["page"].Schema.Example)
+	assert.Equal(t, "active", params["filter"].Example)
+	assert.True(t, params["filter"].AllowEmptyValue)
+	assert.True(t, params["legacy"].Deprecated)
+	assert.False(t, params["page"].Deprecated)
+	assert.False(t, params["X-Trace"].AllowEmptyValue, "allowEmptyValue applies to query params only")
+	assert.Equal(t, "lots", params["bad"].Example)
+}
+
 func TestSpec_unexported_field_ignored_in_params(t *testing.T) {
 	t.Parallel()
 
@@ -1019,3 +1078,262 @@ func TestSpec_with_response_overrides_auto_baseline(t *testing.T) {
 	require.NotNil(t, media.Schema)
 	assert.Equal(t, "#/components/schemas/ItemNotFound", media.Schema.Ref)
 }
+
+func TestSpec_query_array_params(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Tags []string `query:"tag"`
+		IDs  []int    `query:"id" explode:"false"`
+		Page int      `query:"page"`
+	}
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *Req) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	op := r.Spec().Paths["/items"]["get"]
+	params := make(map[string]api.Parameter, len(op.Parameters))
+	for _, p := range op.Parameters {
+		params[p.Name] = p
+	}
+
+	tag := params["tag"]
+	assert.Equal(t, "array", tag.Schema.Type)
+	require.NotNil(t, tag.Schema.Items)
+	assert.Equal(t, "string", tag.Schema.Items.Type)
+	assert.Equal(t, "form", tag.Style)
+	require.NotNil(t, tag.Explode)
+	assert.True(t, *tag.Explode)
+
+	id := params["id"]
+	assert.Equal(t, "integer", id.Schema.Items.Type)
+	require.NotNil(t, id.Explode)
+	assert.False(t, *id.Explode)
+
+	assert.Empty(t, params["page"].Style)
+	assert.Nil(t, params["page"].Explode)
+}
+
+func TestSpec_query_alias_params(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Search string `query:"q,alias=search" required:"true" doc:"Search text"`
+	}
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *Req) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	op := r.Spec().Paths["/items"]["get"]
+	require.Len(t, op.Parameters, 2)
+
+	current := op.Parameters[0]
+	assert.Equal(t, "q", current.Name)
+	assert.True(t, current.Required)
+	assert.False(t, current.Deprecated)
+
+	alias := op.Parameters[1]
+	assert.Equal(t, "search", alias.Name)
+	assert.Equal(t, "query", alias.In)
+	assert.True(t, alias.Deprecated)
+	assert.False(t, alias.Required)
+	assert.Equal(t, `Deprecated alias of "q".`, alias.Description)
+	assert.Equal(t, "string", alias.Schema.Type)
+}
+
+func TestSpec_array_request_body(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Name string `json:"name"`
+	}
+	type Req struct {
+		Body []Item `minItems:"1" maxItems:"100"`
+	}
+
+	r := api.New()
+	api.Post(r, "/items/batch", func(_ context.Context, _ *Req) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	op := r.Spec().Paths["/items/batch"]["post"]
+	require.NotNil(t, op.RequestBody)
+	schema := op.RequestBody.Content["application/json"].Schema
+	require.NotNil(t, schema)
+	assert.Equal(t, "array", schema.Type)
+	require.NotNil(t, schema.Items)
+	assert.Equal(t, "#/components/schemas/Item", schema.Items.Ref)
+	require.NotNil(t, schema.MinItems)
+	assert.Equal(t, 1, *schema.MinItems)
+	require.NotNil(t, schema.MaxItems)
+	assert.Equal(t, 100, *schema.MaxItems)
+}
+
+func TestSpec_map_request_body(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Body map[string]int `minItems:"1" maxItems:"50"`
+	}
+
+	r := api.New()
+	api.Put(r, "/quotas", func(_ context.Context, _ *Req) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	schema := r.Spec().Paths["/quotas"]["put"].RequestBody.Content["application/json"].Schema
+	require.NotNil(t, schema)
+	assert.Equal(t, "object", schema.Type)
+	assert.Nil(t, schema.MinItems)
+	assert.Nil(t, schema.MaxItems)
+	require.NotNil(t, schema.MinProperties)
+	assert.Equal(t, 1, *schema.MinProperties)
+	require.NotNil(t, schema.MaxProperties)
+	assert.Equal(t, 50, *schema.MaxProperties)
+}
+
+func TestSpec_security_error_responses(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.WithGlobalSecurity("bearer"))
+	api.Get(r, "/default", voidHandler)
+	api.Get(r, "/public", voidHandler, api.WithNoSecurity())
+	api.Get(r, "/scoped", voidHandler, api.WithScopes("oauth", "read"))
+	open := api.New()
+	api.Get(open, "/open", voidHandler)
+	api.Get(open, "/keyed", voidHandler, api.WithSecurity("apiKey"))
+
+	tests := map[string]struct {
+		spec    api.OpenAPISpec
+		path    string
+		want401 bool
+		want403 bool
+	}{
+		"router default": {spec: r.Spec(), path: "/default", want401: true},
+		"opted out":      {spec: r.Spec(), path: "/public"},
+		"scoped":         {spec: r.Spec(), path: "/scoped", want401: true, want403: true},
+		"unsecured":      {spec: open.Spec(), path: "/open"},
+		"route security": {spec: open.Spec(), path: "/keyed", want401: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			responses := tc.spec.Paths[tc.path]["get"].Responses
+			_, has401 := responses["401"]
+			_, has403 := responses["403"]
+			assert.Equal(t, tc.want401, has401)
+			assert.Equal(t, tc.want403, has403)
+		})
+	}
+}
+
+func TestSpec_anonymous_response_names(t *testing.T) {
+	t.Parallel()
+
+	type userReq struct {
+		ID string `path:"id"`
+	}
+	type userResp = struct {
+		Name string `json:"name"`
+	}
+	getUser := func(_ context.Context, _ *userReq) (*api.Resp[userResp], error) { return nil, nil }
+	listUsers := func(_ context.Context, _ *api.Void) (*api.Resp[[]userResp], error) { return nil, nil }
+
+	tests := map[string]struct {
+		opts     []api.RouterOption
+		wantRef  string
+		wantDefs []string
+	}{
+		"inlined by default": {},
+		"named from operation ID": {
+			opts:     []api.RouterOption{api.WithAnonymousResponseNames()},
+			wantRef:  "#/components/schemas/GetUsersByIdResponse",
+			wantDefs: []string{"GetUsersByIdResponse", "LookupUserResponse"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New(tc.opts...)
+			api.Get(r, "/users/{id}", getUser)
+			api.Get(r, "/lookup/{id}", getUser, api.WithOperationID("lookupUser"))
+			api.Get(r, "/users", listUsers)
+			spec := r.Spec()
+
+			schema := spec.Paths["/users/{id}"]["get"].Responses["200"].Content["application/json"].Schema
+			require.NotNil(t, schema)
+			assert.Equal(t, tc.wantRef, schema.Ref)
+			if tc.wantRef == "" {
+				assert.Contains(t, schema.Properties, "name")
+			}
+
+			// Only the top-level body is named; a slice of it stays inline.
+			list := spec.Paths["/users"]["get"].Responses["200"].Content["application/json"].Schema
+			assert.Contains(t, list.Items.Properties, "name")
+
+			for _, def := range tc.wantDefs {
+				assert.Contains(t, spec.Components.Schemas, def)
+			}
+		})
+	}
+}
+
+type GetUsersByIdResponse struct {
+	ID string `json:"id"`
+}
+
+func TestSpec_anonymous_response_name_collision(t *testing.T) {
+	t.Parallel()
+
+	type userReq struct {
+		ID string `path:"id"`
+	}
+	type userResp = struct {
+		Name string `json:"name"`
+	}
+	type otherResp = struct {
+		Email string `json:"email"`
+	}
+
+	tests := map[string]struct {
+		register func(r *api.Router)
+	}{
+		"another anonymous body": {
+			register: func(r *api.Router) {
+				api.Get(r, "/users/{id}", func(_ context.Context, _ *userReq) (*api.Resp[userResp], error) { return nil, nil })
+				api.Get(r, "/people/{id}", func(_ context.Context, _ *userReq) (*api.Resp[otherResp], error) { return nil, nil },
+					api.WithOperationID("getUsersById"))
+			},
+		},
+		"named type first": {
+			register: func(r *api.Router) {
+				api.Get(r, "/named", func(_ context.Context, _ *api.Void) (*api.Resp[GetUsersByIdResponse], error) { return nil, nil })
+				api.Get(r, "/users/{id}", func(_ context.Context, _ *userReq) (*api.Resp[userResp], error) { return nil, nil })
+			},
+		},
+		"named type after": {
+			register: func(r *api.Router) {
+				api.Get(r, "/users/{id}", func(_ context.Context, _ *userReq) (*api.Resp[userResp], error) { return nil, nil })
+				api.Get(r, "/named", func(_ context.Context, _ *api.Void) (*api.Resp[GetUsersByIdResponse], error) { return nil, nil })
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New(api.WithAnonymousResponseNames())
+			tc.register(r)
+			assert.Panics(t, func() { r.Spec() })
+		})
+	}
+}
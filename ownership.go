@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// ErrNotOwner is returned by an OwnershipFunc when the principal does not
+// own the resource. WithOwnership turns it into the configured denial.
+var ErrNotOwner = errors.New("not the resource owner")
+
+// OwnershipFunc verifies that p owns the resource identified by value, the
+// raw path parameter. Return nil to allow, ErrNotOwner to deny, an *Err to
+// respond with that error, or any other error to fail with 500.
+type OwnershipFunc func(ctx context.Context, p *Principal, value string) error
+
+// OwnershipConfig configures WithOwnership.
+type OwnershipConfig struct {
+	// DenyCode is the error returned on ErrNotOwner. Defaults to
+	// CodeForbidden; use CodeNotFound to avoid revealing that the resource
+	// exists.
+	DenyCode Code
+}
+
+// WithOwnership enforces resource ownership on a path parameter before the
+// request is decoded and the handler runs. Requests without a principal
+// (see SetPrincipal) are rejected with 401:
+//
+//	api.Get(g, "/users/{user_id}/orders", h.ListOrders,
+//	    api.WithOwnership("user_id", func(_ context.Context, p *api.Principal, id string) error {
+//	        if p.ID != id {
+//	            return api.ErrNotOwner
+//	        }
+//	        return nil
+//	    }),
+//	)
+//
+// Several checks on one route run in order. Registration panics if the
+// final route pattern has no such parameter.
+func WithOwnership(param string, check OwnershipFunc, cfg ...OwnershipConfig) RouteOption {
+	rule := ownershipRule{param: param, check: check, denyCode: CodeForbidden}
+	if len(cfg) > 0 && cfg[0].DenyCode != "" {
+		rule.denyCode = cfg[0].DenyCode
+	}
+	return RouteOptionFunc(func(ri *routeInfo) {
+		ri.ownership = append(ri.ownership, rule)
+	})
+}
+
+type ownershipRule struct {
+	param    string
+	check    OwnershipFunc
+	denyCode Code
+}
+
+// verify runs the rule against the request's path parameter.
+func (o ownershipRule) verify(r *http.Request) error {
+	p, ok := GetPrincipal(r.Context())
+	if !ok {
+		return Error(CodeUnauthorized, WithMessage("authentication required"))
+	}
+	err := o.check(r.Context(), p, r.PathValue(o.param))
+	if errors.Is(err, ErrNotOwner) {
+		return Error(o.denyCode)
+	}
+	return err
+}
+
+// checkOwnershipParams panics when an ownership rule names a parameter the
+// final pattern does not declare.
+func checkOwnershipParams(ri *routeInfo) {
+	for _, o := range ri.ownership {
+		if !slices.Contains(patternWildcards(ri.pattern), o.param) {
+			panic(fmt.Sprintf("api: %s %s: WithOwnership parameter %q is not in the pattern", ri.method, ri.pattern, o.param))
+		}
+	}
+}
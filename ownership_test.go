@@ -0,0 +1,95 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func ownsUser(_ context.Context, p *api.Principal, id string) error {
+	if p.ID != id {
+		return api.ErrNotOwner
+	}
+	return nil
+}
+
+func TestWithOwnership(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		UserID string `path:"user_id"`
+	}
+
+	var called bool
+	r := api.New()
+	r.Use(withPrincipal)
+	g := r.Group("/users/{user_id}")
+	api.Get(g, "/orders", func(_ context.Context, _ *Req) (*api.Void, error) {
+		called = true
+		return &api.Void{}, nil
+	}, api.WithOwnership("user_id", ownsUser))
+	api.Get(g, "/private", voidHandler,
+		api.WithOwnership("user_id", ownsUser, api.OwnershipConfig{DenyCode: api.CodeNotFound}))
+	api.Get(g, "/custom", voidHandler,
+		api.WithOwnership("user_id", func(context.Context, *api.Principal, string) error {
+			return api.Error(api.CodeGone)
+		}))
+	api.Get(g, "/broken", voidHandler,
+		api.WithOwnership("user_id", func(context.Context, *api.Principal, string) error {
+			return errors.New("lookup failed")
+		}))
+
+	tests := map[string]struct {
+		path     string
+		roles    string
+		wantCode int
+	}{
+		"owner":               {path: "/users/u1/orders", roles: "user", wantCode: http.StatusNoContent},
+		"not owner":           {path: "/users/u2/orders", roles: "user", wantCode: http.StatusForbidden},
+		"anonymous":           {path: "/users/u1/orders", wantCode: http.StatusUnauthorized},
+		"hidden as not found": {path: "/users/u2/private", roles: "user", wantCode: http.StatusNotFound},
+		"custom error":        {path: "/users/u1/custom", roles: "user", wantCode: http.StatusGone},
+		"check failure":       {path: "/users/u1/broken", roles: "user", wantCode: http.StatusInternalServerError},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.roles != "" {
+				req.Header.Set("X-Roles", tt.roles)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+		})
+	}
+
+	called = false
+	req := httptest.NewRequest(http.MethodGet, "/users/u2/orders", nil)
+	req.Header.Set("X-Roles", "user")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assert.False(t, called, "handler must not run for a non-owner")
+}
+
+func TestWithOwnership_spec_and_validation(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/users/{user_id}", voidHandler,
+		api.WithOwnership("user_id", ownsUser, api.OwnershipConfig{DenyCode: api.CodeNotFound}))
+
+	responses := r.Spec().Paths["/users/{user_id}"]["get"].Responses
+	assert.Contains(t, responses, "401")
+	assert.Contains(t, responses, "404")
+
+	require.Panics(t, func() {
+		api.Get(r, "/accounts/{id}", voidHandler, api.WithOwnership("user_id", ownsUser))
+	})
+}
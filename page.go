@@ -0,0 +1,136 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PageParams holds the standard pagination query parameters. Embed it in a
+// list request to accept either offset or cursor paging:
+//
+//	type ListUsersReq struct {
+//	    api.PageParams
+//	    Role string `query:"role"`
+//	}
+type PageParams struct {
+	Limit  int    `query:"limit" minimum:"0" doc:"Maximum number of items to return"`
+	Offset int    `query:"offset" minimum:"0" doc:"Number of items to skip"`
+	Cursor string `query:"cursor" doc:"Opaque cursor from a previous page's nextCursor"`
+}
+
+// LimitOr returns Limit, or def when the client sent none.
+func (p PageParams) LimitOr(def int) int {
+	if p.Limit <= 0 {
+		return def
+	}
+	return p.Limit
+}
+
+// PageBody is the envelope of a paginated list response. It is documented
+// as a component named after the item type, such as PageOfUser.
+type PageBody[T any] struct {
+	Items []T `json:"items"`
+
+	// Total is the number of items across all pages, when known.
+	Total *int `json:"total,omitempty"`
+
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+
+	// NextCursor fetches the next page in cursor paging; empty on the last
+	// page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Page is a paginated list response. Build it with NewPage and fill in
+// Total or NextCursor:
+//
+//	func (h *Handlers) ListUsers(ctx context.Context, req *ListUsersReq) (*api.Page[User], error) {
+//	    limit := req.LimitOr(20)
+//	    users, total, err := h.store.List(ctx, req.Offset, limit)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    page := api.NewPage(users, req.PageParams, limit)
+//	    page.Body.Total = &total
+//	    return page, nil
+//	}
+//
+// The Link header is derived from the request URL when the response is
+// written: next, prev, first, and last for offset paging, and next for
+// cursor paging. Set Link yourself to override it.
+type Page[T any] struct {
+	Body PageBody[T]
+	Link string `header:"Link" doc:"Links to adjacent pages (RFC 8288)"`
+}
+
+// NewPage returns a Page of items served for params with the given limit.
+// In cursor paging the offset is dropped.
+func NewPage[T any](items []T, params PageParams, limit int) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	body := PageBody[T]{Items: items, Limit: limit}
+	if params.Cursor == "" {
+		body.Offset = params.Offset
+	}
+	return &Page[T]{Body: body}
+}
+
+// nameComponents names PageBody[T] after T.
+func (Page[T]) nameComponents(ri *routeInfo) {
+	if name := componentName(reflect.TypeFor[T]()); name != "" {
+		ri.nameComponent(reflect.TypeFor[PageBody[T]](), "PageOf"+name)
+	}
+}
+
+// setLink fills Link from the request URL unless the handler set it.
+func (p *Page[T]) setLink(r *http.Request) {
+	if p.Link != "" {
+		return
+	}
+	b := &p.Body
+	var links []string
+	add := func(rel string, set func(q url.Values)) {
+		u := *r.URL
+		q := u.Query()
+		set(q)
+		u.RawQuery = q.Encode()
+		links = append(links, "<"+u.RequestURI()+`>; rel="`+rel+`"`)
+	}
+	offset := func(n int) func(url.Values) {
+		return func(q url.Values) {
+			q.Set("offset", strconv.Itoa(n))
+			q.Del("cursor")
+		}
+	}
+
+	switch {
+	case b.NextCursor != "":
+		add("next", func(q url.Values) {
+			q.Set("cursor", b.NextCursor)
+			q.Del("offset")
+		})
+	case r.URL.Query().Get("cursor") != "" || b.Limit <= 0:
+		// The last page of a cursor walk, or no limit to page by.
+	default:
+		more := len(b.Items) == b.Limit
+		if b.Total != nil {
+			more = b.Offset+b.Limit < *b.Total
+		}
+		if more {
+			add("next", offset(b.Offset+b.Limit))
+		}
+		if b.Offset > 0 {
+			add("prev", offset(max(0, b.Offset-b.Limit)))
+			add("first", offset(0))
+		}
+		if b.Total != nil && *b.Total > 0 {
+			add("last", offset((*b.Total-1)/b.Limit*b.Limit))
+		}
+	}
+	p.Link = strings.Join(links, ", ")
+}
@@ -0,0 +1,151 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type pgUser struct {
+	Name string `json:"name"`
+}
+
+type pgListReq struct {
+	api.PageParams
+	Role string `query:"role"`
+}
+
+func newPageRouter(total *int, nextCursor string) *api.Router {
+	r := api.New()
+	api.Get(r, "/users", func(_ context.Context, req *pgListReq) (*api.Page[pgUser], error) {
+		limit := req.LimitOr(2)
+		page := api.NewPage([]pgUser{{Name: "a"}, {Name: "b"}}[:min(limit, 2)], req.PageParams, limit)
+		page.Body.Total = total
+		page.Body.NextCursor = nextCursor
+		return page, nil
+	})
+	return r
+}
+
+func TestPage_links(t *testing.T) {
+	t.Parallel()
+
+	five := 5
+	tests := map[string]struct {
+		total      *int
+		nextCursor string
+		target     string
+		wantLink   string
+		wantOffset int
+	}{
+		"first page with total": {
+			total:    &five,
+			target:   "/users?role=admin",
+			wantLink: `</users?offset=2&role=admin>; rel="next", </users?offset=4&role=admin>; rel="last"`,
+		},
+		"middle page with total": {
+			total:      &five,
+			target:     "/users?offset=2",
+			wantOffset: 2,
+			wantLink:   `</users?offset=4>; rel="next", </users?offset=0>; rel="prev", </users?offset=0>; rel="first", </users?offset=4>; rel="last"`,
+		},
+		"last page with total": {
+			total:      &five,
+			target:     "/users?limit=2&offset=4",
+			wantOffset: 4,
+			wantLink:   `</users?limit=2&offset=2>; rel="prev", </users?limit=2&offset=0>; rel="first", </users?limit=2&offset=4>; rel="last"`,
+		},
+		"full page without total": {
+			target:   "/users",
+			wantLink: `</users?offset=2>; rel="next"`,
+		},
+		"short page without total": {
+			target: "/users?limit=5",
+		},
+		"cursor": {
+			nextCursor: "abc",
+			target:     "/users?cursor=xyz&offset=9",
+			wantLink:   `</users?cursor=abc>; rel="next"`,
+		},
+		"last cursor page": {
+			target: "/users?cursor=xyz",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := newPageRouter(tc.total, tc.nextCursor)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, tc.target, nil))
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			assert.Equal(t, tc.wantLink, rec.Header().Get("Link"))
+			var body api.PageBody[pgUser]
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tc.wantOffset, body.Offset)
+			assert.Equal(t, tc.total, body.Total)
+			assert.Equal(t, tc.nextCursor, body.NextCursor)
+		})
+	}
+}
+
+func TestPage_handlerLinkWins(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/users", func(_ context.Context, req *pgListReq) (*api.Page[pgUser], error) {
+		page := api.NewPage([]pgUser{{Name: "a"}}, req.PageParams, 1)
+		page.Link = `<https://example.com/users?page=2>; rel="next"`
+		return page, nil
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/users", nil))
+	assert.Equal(t, `<https://example.com/users?page=2>; rel="next"`, rec.Header().Get("Link"))
+	assert.JSONEq(t, `{"items":[{"name":"a"}],"limit":1}`, rec.Body.String())
+}
+
+func TestPage_spec(t *testing.T) {
+	t.Parallel()
+
+	spec := newPageRouter(nil, "").Spec()
+	op := spec.Paths["/users"]["get"]
+
+	var params []string
+	for _, p := range op.Parameters {
+		params = append(params, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"limit", "offset", "cursor", "role"}, params)
+
+	resp := op.Responses["200"]
+	assert.Equal(t, "#/components/schemas/PageOfpgUser", resp.Content["application/json"].Schema.Ref)
+	assert.Contains(t, resp.Headers, "Link")
+
+	page := spec.Components.Schemas["PageOfpgUser"]
+	require.NotNil(t, page)
+	assert.Equal(t, "#/components/schemas/pgUser", page.Properties["items"].Items.Ref)
+	assert.Contains(t, page.Properties, "nextCursor")
+}
+
+func TestPage_components_per_router(t *testing.T) {
+	t.Parallel()
+
+	paged := newPageRouter(nil, "")
+
+	plain := api.New()
+	api.Get(plain, "/users", func(_ context.Context, _ *api.Void) (*api.Resp[api.PageBody[pgUser]], error) {
+		return &api.Resp[api.PageBody[pgUser]]{}, nil
+	})
+
+	assert.Contains(t, paged.Spec().Components.Schemas, "PageOfpgUser")
+	assert.NotContains(t, plain.Spec().Components.Schemas, "PageOfpgUser")
+}
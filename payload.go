@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// PayloadMetrics describes the payloads of one request to a typed route.
+// It is passed to the WithPayloadObserver hook once the response is
+// written.
+type PayloadMetrics struct {
+	// OperationID is the route's operation ID, as in the spec.
+	OperationID string
+
+	// Operation is the matched route as "METHOD pattern".
+	Operation string
+
+	// Tags are the route's tags, for labels coarser than the operation.
+	Tags []string
+
+	// Status is the response status.
+	Status int
+
+	// RequestBytes is the size of the request body the framework read.
+	RequestBytes int64
+
+	// ResponseBytes is the size of the response body the route wrote.
+	// Compression by middleware such as Compress is not included.
+	ResponseBytes int64
+
+	// Decode is the time spent binding and decoding the request. It is
+	// zero when the request was rejected before decoding.
+	Decode time.Duration
+
+	// Encode is the time spent encoding and writing the response. It is
+	// zero for error and Void responses.
+	Encode time.Duration
+}
+
+// WithPayloadObserver sets a hook called after every request to a typed
+// route, to record payload sizes and codec time per operation in a
+// metrics system:
+//
+//	r := api.New(api.WithPayloadObserver(func(_ context.Context, m api.PayloadMetrics) {
+//	    requestSize.WithLabelValues(m.OperationID).Observe(float64(m.RequestBytes))
+//	    responseSize.WithLabelValues(m.OperationID).Observe(float64(m.ResponseBytes))
+//	    encodeTime.WithLabelValues(m.OperationID).Observe(m.Encode.Seconds())
+//	}))
+//
+// The hook runs on the request goroutine, so it should not block. Raw
+// routes are not covered.
+func WithPayloadObserver(fn func(ctx context.Context, m PayloadMetrics)) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.payloadObserver = fn
+	})
+}
+
+// payloadObserver measures one route's requests for the observer hook.
+type payloadObserver struct {
+	route    *RouteDescription // filled in when the route is added
+	observer func(ctx context.Context, m PayloadMetrics)
+}
+
+// payloadRequest measures one request.
+type payloadRequest struct {
+	m      PayloadMetrics
+	body   *payloadReader
+	writer *payloadWriter
+}
+
+// start wraps the request body and response writer to count bytes. It
+// returns nil, leaving both untouched, when no observer is set.
+func (o payloadObserver) start(w http.ResponseWriter, r *http.Request) (*payloadRequest, http.ResponseWriter) {
+	if o.observer == nil {
+		return nil, w
+	}
+	p := &payloadRequest{
+		m:      PayloadMetrics{OperationID: o.route.OperationID, Tags: o.route.Tags},
+		writer: &payloadWriter{ResponseWriter: w, status: http.StatusOK},
+	}
+	if r.Body != nil && r.Body != http.NoBody {
+		p.body = &payloadReader{ReadCloser: r.Body}
+		r.Body = p.body
+	}
+	return p, p.writer
+}
+
+// now returns the current time, or the zero time on a nil request, so the
+// clock is only read when measuring.
+func (p *payloadRequest) now() time.Time {
+	if p == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// decoded records the decode time from start.
+func (p *payloadRequest) decoded(start time.Time) {
+	if p != nil {
+		p.m.Decode = time.Since(start)
+	}
+}
+
+// encoded records the encode time from start.
+func (p *payloadRequest) encoded(start time.Time) {
+	if p != nil {
+		p.m.Encode = time.Since(start)
+	}
+}
+
+// finish reports the request to the observer.
+func (p *payloadRequest) finish(o payloadObserver, r *http.Request) {
+	if p == nil {
+		return
+	}
+	p.m.Operation = r.Pattern
+	p.m.Status = p.writer.status
+	p.m.ResponseBytes = p.writer.size
+	if p.body != nil {
+		p.m.RequestBytes = p.body.size
+	}
+	o.observer(r.Context(), p.m)
+}
+
+// payloadReader counts the bytes read from a request body.
+type payloadReader struct {
+	io.ReadCloser
+	size int64
+}
+
+func (pr *payloadReader) Read(b []byte) (int, error) {
+	n, err := pr.ReadCloser.Read(b)
+	pr.size += int64(n)
+	return n, err
+}
+
+// payloadWriter counts the bytes written to a response.
+type payloadWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (pw *payloadWriter) WriteHeader(code int) {
+	if code >= http.StatusOK {
+		pw.status = code
+	}
+	pw.ResponseWriter.WriteHeader(code)
+}
+
+func (pw *payloadWriter) Write(b []byte) (int, error) {
+	n, err := pw.ResponseWriter.Write(b)
+	pw.size += int64(n)
+	return n, err
+}
+
+// Flush forwards to the underlying writer so streams still stream.
+func (pw *payloadWriter) Flush() {
+	if f, ok := pw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (pw *payloadWriter) Unwrap() http.ResponseWriter { return pw.ResponseWriter }
@@ -0,0 +1,91 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type payloadItem struct {
+	Name string `json:"name"`
+}
+
+type payloadReq struct {
+	Body payloadItem
+}
+
+func TestWithPayloadObserver(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		body          string
+		wantStatus    int
+		wantRequest   int64
+		wantResponse  int64
+		wantEncoded   bool
+		wantOperation string
+	}{
+		"success": {
+			body:         `{"name":"widget"}`,
+			wantStatus:   http.StatusOK,
+			wantRequest:  int64(len(`{"name":"widget"}`)),
+			wantResponse: int64(len(`{"name":"widget"}` + "\n")),
+			wantEncoded:  true,
+		},
+		"decode error": {
+			body:        `{"name":`,
+			wantStatus:  http.StatusBadRequest,
+			wantRequest: int64(len(`{"name":`)),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu  sync.Mutex
+				got []api.PayloadMetrics
+			)
+			r := api.New(api.WithPayloadObserver(func(_ context.Context, m api.PayloadMetrics) {
+				mu.Lock()
+				defer mu.Unlock()
+				got = append(got, m)
+			}))
+			items := r.Group("/items", api.WithGroupTags("items"))
+			api.Post(items, "", func(_ context.Context, req *payloadReq) (*api.Resp[payloadItem], error) {
+				return &api.Resp[payloadItem]{Body: req.Body}, nil
+			}, api.WithOperationID("createItem"))
+
+			req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+
+			mu.Lock()
+			defer mu.Unlock()
+			require.Len(t, got, 1)
+			m := got[0]
+			assert.Equal(t, "createItem", m.OperationID)
+			assert.Equal(t, "POST /items", m.Operation)
+			assert.Equal(t, []string{"items"}, m.Tags)
+			assert.Equal(t, tt.wantStatus, m.Status)
+			assert.Equal(t, tt.wantRequest, m.RequestBytes)
+			assert.Equal(t, int64(w.Body.Len()), m.ResponseBytes)
+			if tt.wantResponse > 0 {
+				assert.Equal(t, tt.wantResponse, m.ResponseBytes)
+			}
+			assert.Positive(t, m.Decode)
+			assert.Equal(t, tt.wantEncoded, m.Encode > 0)
+		})
+	}
+}
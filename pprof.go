@@ -1,6 +1,9 @@
 package api
 
-import "net/http/pprof"
+import (
+	"net/http"
+	"net/http/pprof"
+)
 
 // Pprof registers pprof profiling endpoints under the given prefix.
 // Default prefix is "/debug/pprof". Routes are hidden from the OpenAPI spec.
@@ -9,15 +12,15 @@ func Pprof(r *Router, prefix string) {
 		prefix = "/debug/pprof"
 	}
 
-	r.mux.HandleFunc("GET "+prefix+"/", pprof.Index)
-	r.mux.HandleFunc("GET "+prefix+"/cmdline", pprof.Cmdline)
-	r.mux.HandleFunc("GET "+prefix+"/profile", pprof.Profile)
-	r.mux.HandleFunc("GET "+prefix+"/symbol", pprof.Symbol)
-	r.mux.HandleFunc("GET "+prefix+"/trace", pprof.Trace)
-	r.mux.Handle("GET "+prefix+"/goroutine", pprof.Handler("goroutine"))
-	r.mux.Handle("GET "+prefix+"/heap", pprof.Handler("heap"))
-	r.mux.Handle("GET "+prefix+"/allocs", pprof.Handler("allocs"))
-	r.mux.Handle("GET "+prefix+"/block", pprof.Handler("block"))
-	r.mux.Handle("GET "+prefix+"/mutex", pprof.Handler("mutex"))
-	r.mux.Handle("GET "+prefix+"/threadcreate", pprof.Handler("threadcreate"))
+	r.handle("GET "+prefix+"/", http.HandlerFunc(pprof.Index))
+	r.handle("GET "+prefix+"/cmdline", http.HandlerFunc(pprof.Cmdline))
+	r.handle("GET "+prefix+"/profile", http.HandlerFunc(pprof.Profile))
+	r.handle("GET "+prefix+"/symbol", http.HandlerFunc(pprof.Symbol))
+	r.handle("GET "+prefix+"/trace", http.HandlerFunc(pprof.Trace))
+	r.handle("GET "+prefix+"/goroutine", pprof.Handler("goroutine"))
+	r.handle("GET "+prefix+"/heap", pprof.Handler("heap"))
+	r.handle("GET "+prefix+"/allocs", pprof.Handler("allocs"))
+	r.handle("GET "+prefix+"/block", pprof.Handler("block"))
+	r.handle("GET "+prefix+"/mutex", pprof.Handler("mutex"))
+	r.handle("GET "+prefix+"/threadcreate", pprof.Handler("threadcreate"))
 }
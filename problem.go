@@ -0,0 +1,194 @@
+package api
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ProblemTemplate describes how a domain error is rendered. Register it
+// against a sentinel error or an error type so handlers can return plain
+// domain errors:
+//
+//	var ErrConflict = errors.New("version conflict")
+//
+//	func init() {
+//	    api.RegisterProblem(ErrConflict, api.ProblemTemplate{
+//	        Status: http.StatusConflict,
+//	        Type:   "https://example.com/problems/conflict",
+//	        Title:  "Version conflict",
+//	    })
+//	}
+//
+// The error is translated into an *Err with the Code for Status, so the
+// route's error template (headers, body shape, ErrorHandler) applies as for
+// any api.Error. The original error stays reachable through errors.Is.
+// Routes document the problems they return with WithProblems.
+type ProblemTemplate struct {
+	// Status is the HTTP status. It must map to one of the package Codes.
+	Status int
+
+	// Type is the problem type URI. Empty keeps the "about:blank" default.
+	Type string
+
+	// Title is the short summary of the problem type. Empty keeps the
+	// status text default.
+	Title string
+
+	// Titles holds Title translated, keyed by language tag such as de or
+	// pt-BR. The title is chosen per response from the Accept-Language
+	// header, with de-CH falling back to de, and Title used when no
+	// language matches. Type stays the same in every language:
+	//
+	//	api.ProblemTemplate{
+	//	    Status: http.StatusConflict,
+	//	    Type:   "https://example.com/problems/conflict",
+	//	    Title:  "Version conflict",
+	//	    Titles: map[string]string{"de": "Versionskonflikt", "fr": "Conflit de version"},
+	//	}
+	Titles map[string]string
+
+	// Detail replaces the error's own message in the response. Empty uses
+	// err.Error().
+	Detail string
+}
+
+type problemEntry struct {
+	match func(error) bool
+	tmpl  ProblemTemplate
+	code  Code
+}
+
+// problems holds the registered templates in registration order; the
+// first match wins.
+var problems struct {
+	mu      sync.RWMutex
+	entries []problemEntry
+}
+
+// RegisterProblem renders errors matching target (per errors.Is) with
+// tmpl. Register at init, before the router serves.
+func RegisterProblem(target error, tmpl ProblemTemplate) {
+	if target == nil {
+		panic("api: RegisterProblem requires a target error")
+	}
+	addProblem(func(err error) bool { return errors.Is(err, target) }, tmpl)
+}
+
+// RegisterProblemType renders errors whose chain contains an E (per
+// errors.As) with tmpl:
+//
+//	api.RegisterProblemType[*NotFoundError](api.ProblemTemplate{Status: 404})
+func RegisterProblemType[E error](tmpl ProblemTemplate) {
+	addProblem(func(err error) bool {
+		var target E
+		return errors.As(err, &target)
+	}, tmpl)
+}
+
+func addProblem(match func(error) bool, tmpl ProblemTemplate) {
+	code, ok := statusToCode(tmpl.Status)
+	if !ok {
+		panic(fmt.Sprintf("api: RegisterProblem: status %d has no Code", tmpl.Status))
+	}
+	problems.mu.Lock()
+	defer problems.mu.Unlock()
+	problems.entries = append(problems.entries, problemEntry{match: match, tmpl: tmpl, code: code})
+}
+
+// lookupProblem translates err through the first matching template.
+func lookupProblem(err error) (*Err, bool) {
+	problems.mu.RLock()
+	defer problems.mu.RUnlock()
+	for _, p := range problems.entries {
+		if !p.match(err) {
+			continue
+		}
+		msg := p.tmpl.Detail
+		if msg == "" {
+			msg = err.Error()
+		}
+		return &Err{
+			code:    p.code,
+			message: msg,
+			cause:   err,
+			typeURI: p.tmpl.Type,
+			title:   p.tmpl.Title,
+			titles:  p.tmpl.Titles,
+		}, true
+	}
+	return nil, false
+}
+
+// WithProblems documents the error responses of the registered problems
+// the route's handler can return, with the status each renders as:
+//
+//	api.Put(r, "/accounts/{id}", h.Update,
+//	    api.WithProblems(ErrConflict, &NotFoundError{}),
+//	)
+//
+// Each error is matched against the templates as a returned error would
+// be, so a value of a type registered with RegisterProblemType selects
+// that template. Errors without a template are ignored.
+func WithProblems(errs ...error) RouteOption {
+	return RouteOptionFunc(func(ri *routeInfo) {
+		ri.problems = append(ri.problems, errs...)
+	})
+}
+
+// statusToCode reverses codeToStatus.
+func statusToCode(status int) (Code, bool) {
+	for c, s := range codeToStatus {
+		if s == status {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// localizedTitle picks the title for the best language of an
+// Accept-Language header, by RFC 4647 lookup: each range is tried in
+// order of preference, dropping trailing subtags until a title matches.
+func localizedTitle(titles map[string]string, header string) (string, bool) {
+	if len(titles) == 0 || header == "" {
+		return "", false
+	}
+	type langRange struct {
+		tag string
+		q   float64
+	}
+	var ranges []langRange
+	for part := range strings.SplitSeq(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if tag = strings.TrimSpace(tag); tag != "" && tag != "*" && q > 0 {
+			ranges = append(ranges, langRange{tag: tag, q: q})
+		}
+	}
+	slices.SortStableFunc(ranges, func(a, b langRange) int { return cmp.Compare(b.q, a.q) })
+
+	for _, rng := range ranges {
+		for tag := rng.tag; tag != ""; {
+			for lang, title := range titles {
+				if strings.EqualFold(lang, tag) {
+					return title, true
+				}
+			}
+			i := strings.LastIndexByte(tag, '-')
+			if i < 0 {
+				break
+			}
+			tag = tag[:i]
+		}
+	}
+	return "", false
+}
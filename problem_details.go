@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"net/http"
+	"time"
 )
 
 // ProblemDetails is the RFC 9457 Problem Details for HTTP APIs shape.
@@ -12,8 +13,8 @@ import (
 // the whole struct.
 //
 // The first five fields (Type through Instance) are defined by RFC 9457.
-// Code and Errors are framework-provided extensions, permitted by the
-// RFC's extensibility rules.
+// The rest are framework-provided extensions, permitted by the RFC's
+// extensibility rules.
 type ProblemDetails struct {
 	// Type is a URI reference identifying the problem type. Defaults to
 	// "about:blank", per the RFC, which implies the problem has no
@@ -42,6 +43,16 @@ type ProblemDetails struct {
 	// Errors carries the error's attached details (validation failures,
 	// retry hints, etc.). (RFC 9457 extension.)
 	Errors []any `json:"errors,omitempty"`
+
+	// Retryable reports whether the client may retry the request
+	// unchanged; absent when the server gives no guidance. Set with
+	// WithRetryable or WithRetryAfter. (RFC 9457 extension.)
+	Retryable *bool `json:"retryable,omitempty"`
+
+	// RetryAfter is the number of seconds to wait before retrying, as in
+	// the Retry-After header. Set with WithRetryAfter. (RFC 9457
+	// extension.)
+	RetryAfter int `json:"retryAfter,omitempty"`
 }
 
 // ContentType returns the RFC 9457 media type for this body shape.
@@ -55,7 +66,7 @@ func (*ProblemDetails) ContentType() string { return "application/problem+json"
 // and then overwrite individual fields.
 func NewProblemDetails(e ErrorInfo) *ProblemDetails {
 	status := e.Code().HTTPStatus()
-	return &ProblemDetails{
+	pd := &ProblemDetails{
 		Type:     "about:blank",
 		Title:    http.StatusText(status),
 		Status:   status,
@@ -64,6 +75,25 @@ func NewProblemDetails(e ErrorInfo) *ProblemDetails {
 		Code:     e.Code(),
 		Errors:   e.Details(),
 	}
+	// Type and title come from WithProblemType, WithProblemTitle, or a
+	// registered ProblemTemplate.
+	if p, ok := e.(interface{ problem() (string, string) }); ok {
+		typeURI, title := p.problem()
+		if typeURI != "" {
+			pd.Type = typeURI
+		}
+		if title != "" {
+			pd.Title = title
+		}
+	}
+	if r, ok := e.(interface {
+		retry() (*bool, time.Duration)
+	}); ok {
+		retryable, after := r.retry()
+		pd.Retryable = retryable
+		pd.RetryAfter = int(after / time.Second)
+	}
+	return pd
 }
 
 // ErrorBodyProblemDetails is the framework's default body mapper. It
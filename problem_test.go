@@ -0,0 +1,244 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+// The registry is process-wide, so these tests use statuses no other test
+// asserts on.
+var (
+	errPbLocked   = errors.New("account locked")
+	errPbTooEarly = errors.New("replayed request")
+)
+
+type pbMisdirectedError struct{ Shard string }
+
+func (e *pbMisdirectedError) Error() string { return "wrong shard " + e.Shard }
+
+func init() {
+	api.RegisterProblem(errPbLocked, api.ProblemTemplate{
+		Status: http.StatusLocked,
+		Type:   "https://example.com/problems/locked",
+		Title:  "Account locked",
+		Titles: map[string]string{"de": "Konto gesperrt", "pt-BR": "Conta bloqueada"},
+	})
+	api.RegisterProblem(errPbTooEarly, api.ProblemTemplate{
+		Status: http.StatusTooEarly,
+		Titles: map[string]string{"de": "Zu früh"},
+	})
+	api.RegisterProblemType[*pbMisdirectedError](api.ProblemTemplate{
+		Status: http.StatusMisdirectedRequest,
+		Detail: "retry against another shard",
+	})
+}
+
+func TestRegisterProblem_translates(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err        error
+		wantStatus int
+		wantType   string
+		wantTitle  string
+		wantDetail string
+	}{
+		"sentinel": {
+			err:        errPbLocked,
+			wantStatus: http.StatusLocked,
+			wantType:   "https://example.com/problems/locked",
+			wantTitle:  "Account locked",
+			wantDetail: "account locked",
+		},
+		"wrapped sentinel": {
+			err:        fmt.Errorf("load: %w", errPbLocked),
+			wantStatus: http.StatusLocked,
+			wantType:   "https://example.com/problems/locked",
+			wantTitle:  "Account locked",
+			wantDetail: "load: account locked",
+		},
+		"error type": {
+			err:        fmt.Errorf("route: %w", &pbMisdirectedError{Shard: "b"}),
+			wantStatus: http.StatusMisdirectedRequest,
+			wantType:   "about:blank",
+			wantTitle:  "Misdirected Request",
+			wantDetail: "retry against another shard",
+		},
+		"api error wins": {
+			err:        api.Error(api.CodeConflict, api.WithCause(errPbLocked)),
+			wantStatus: http.StatusConflict,
+			wantType:   "about:blank",
+			wantTitle:  "Conflict",
+		},
+		"unregistered": {
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantType:   "about:blank",
+			wantTitle:  "Internal Server Error",
+			wantDetail: "boom",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New()
+			api.Get(r, "/x", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+				return nil, tc.err
+			})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/x", nil))
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			var pd api.ProblemDetails
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pd))
+			assert.Equal(t, tc.wantType, pd.Type)
+			assert.Equal(t, tc.wantTitle, pd.Title)
+			assert.Equal(t, tc.wantDetail, pd.Detail)
+		})
+	}
+}
+
+func TestRegisterProblem_localizedTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		acceptLanguage string
+		wantTitle      string
+	}{
+		"no header":        {wantTitle: "Account locked"},
+		"exact":            {acceptLanguage: "de", wantTitle: "Konto gesperrt"},
+		"case insensitive": {acceptLanguage: "pt-br", wantTitle: "Conta bloqueada"},
+		"falls back":       {acceptLanguage: "de-CH", wantTitle: "Konto gesperrt"},
+		"by quality":       {acceptLanguage: "fr;q=0.9, pt-BR;q=0.5, de;q=0.7", wantTitle: "Konto gesperrt"},
+		"refused":          {acceptLanguage: "de;q=0, en", wantTitle: "Account locked"},
+		"unmatched":        {acceptLanguage: "ja, *", wantTitle: "Account locked"},
+	}
+
+	r := api.New()
+	api.Get(r, "/x", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return nil, errPbLocked
+	})
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/x", nil)
+			if tc.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusLocked, rec.Code)
+			var pd api.ProblemDetails
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pd))
+			assert.Equal(t, tc.wantTitle, pd.Title)
+			assert.Equal(t, "https://example.com/problems/locked", pd.Type)
+			assert.Equal(t, "Accept-Language", rec.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestRegisterProblem_localizedTitle_without_default(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/x", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return nil, errPbTooEarly
+	})
+
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/x", nil)
+	req.Header.Set("Accept-Language", "de")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusTooEarly, rec.Code)
+	var pd api.ProblemDetails
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pd))
+	assert.Equal(t, "Zu früh", pd.Title)
+}
+
+func TestRegisterProblem_errorHandlerSeesErr(t *testing.T) {
+	t.Parallel()
+
+	var got error
+	r := api.New(api.WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+		got = err
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	api.Get(r, "/x", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return nil, errPbLocked
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/x", nil))
+
+	var apiErr *api.Err
+	require.ErrorAs(t, got, &apiErr)
+	assert.Equal(t, api.CodeLocked, apiErr.Code())
+	assert.ErrorIs(t, got, errPbLocked)
+}
+
+func TestRegisterProblem_documented(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/x", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return &api.Void{}, nil
+	}, api.WithProblems(errPbLocked, &pbMisdirectedError{}, errors.New("unregistered")))
+	api.Get(r, "/plain", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	op := r.Spec().Paths["/x"]["get"]
+	assert.Contains(t, op.Responses, "423")
+	assert.Contains(t, op.Responses, "421")
+	assert.NotContains(t, op.Responses, "425")
+
+	plain := r.Spec().Paths["/plain"]["get"]
+	for _, status := range []string{"423", "425", "421"} {
+		assert.NotContains(t, plain.Responses, status)
+	}
+}
+
+func TestRegisterProblem_invalid(t *testing.T) {
+	t.Parallel()
+
+	assert.PanicsWithValue(t, "api: RegisterProblem: status 299 has no Code", func() {
+		api.RegisterProblem(errors.New("x"), api.ProblemTemplate{Status: 299})
+	})
+	assert.Panics(t, func() {
+		api.RegisterProblem(nil, api.ProblemTemplate{Status: http.StatusConflict})
+	})
+}
+
+func TestWithProblemType(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.WithError(api.WithProblemType("https://example.com/problems/generic")))
+	api.Get(r, "/x", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return nil, api.Error(api.CodeConflict, api.WithProblemTitle("Duplicate"))
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/x", nil))
+
+	var pd api.ProblemDetails
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pd))
+	assert.Equal(t, "https://example.com/problems/generic", pd.Type)
+	assert.Equal(t, "Duplicate", pd.Title)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"runtime/pprof"
+	"strings"
+	"sync"
+)
+
+// WithProfile labels the route's goroutine with pprof labels while it
+// serves a request, so CPU and goroutine profiles can be sliced by
+// endpoint:
+//
+//	api.Get(r, "/reports/{id}", h.GetReport, api.WithProfile())
+//
+// The labels are "operation", the route's operationId, and "tags", its
+// comma-separated tags. Goroutines the handler starts inherit them.
+func WithProfile() RouteOption {
+	return RouteOptionFunc(func(ri *routeInfo) {
+		ri.profile = true
+	})
+}
+
+// profileHandler runs next under the pprof labels of the route described
+// by meta. meta is filled in when the route is added to the router, after
+// group tags apply, so the labels are built on first use.
+func profileHandler(meta *RouteDescription, next http.Handler) http.Handler {
+	labels := sync.OnceValue(func() pprof.LabelSet {
+		kv := []string{"operation", meta.OperationID}
+		if len(meta.Tags) > 0 {
+			kv = append(kv, "tags", strings.Join(meta.Tags, ","))
+		}
+		return pprof.Labels(kv...)
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pprof.Do(r.Context(), labels(), func(ctx context.Context) {
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+}
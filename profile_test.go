@@ -0,0 +1,71 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjaus/api"
+)
+
+func TestWithProfile(t *testing.T) {
+	t.Parallel()
+
+	type labels struct {
+		operation, tags string
+		hasTags         bool
+	}
+
+	tests := map[string]struct {
+		group bool
+		opts  []api.RouteOption
+		want  labels
+	}{
+		"generated operation id": {
+			opts: []api.RouteOption{api.WithProfile()},
+			want: labels{operation: "getReports"},
+		},
+		"explicit id and tags": {
+			opts: []api.RouteOption{api.WithProfile(), api.WithOperationID("listReports"), api.WithTags("reports", "admin")},
+			want: labels{operation: "listReports", tags: "reports,admin", hasTags: true},
+		},
+		"group tags": {
+			group: true,
+			opts:  []api.RouteOption{api.WithProfile(), api.WithOperationID("listReports")},
+			want:  labels{operation: "listReports", tags: "billing", hasTags: true},
+		},
+		"not profiled": {
+			opts: []api.RouteOption{api.WithOperationID("listReports")},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got labels
+			h := func(ctx context.Context, _ *api.Void) (*api.Void, error) {
+				got.operation, _ = pprof.Label(ctx, "operation")
+				got.tags, got.hasTags = pprof.Label(ctx, "tags")
+				return &api.Void{}, nil
+			}
+
+			r := api.New()
+			var reg api.Registrar = r
+			if tc.group {
+				reg = r.Group("", api.WithGroupTags("billing"))
+			}
+			api.Get(reg, "/reports", h, tc.opts...)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reports", http.NoBody))
+
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package api_test
+
+// raceEnabled reports whether the race detector is on; it adds
+// allocations that allocation-count tests must not see.
+const raceEnabled = true
@@ -0,0 +1,367 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RadixMatcher is a Matcher that stores routes in a tree keyed by path
+// segment, so matching costs one lookup per segment however many routes
+// are registered. Install it with WithMatcher.
+//
+// Patterns use the net/http.ServeMux syntax, "[METHOD ][HOST]/path" with
+// {name}, {name...}, {$}, and trailing-slash subtrees, and add regular
+// expression constraints on single-segment wildcards:
+//
+//	/orders/{id:[0-9]+}
+//	/files/{name:[a-z]+\.txt}
+//
+// A constrained wildcard matches only a whole segment its expression
+// matches, and its expression may not contain '{', '}', or '/'. The
+// constraint is documented as the path parameter's pattern in the spec.
+//
+// Static segments take precedence over constrained wildcards, constrained
+// over plain ones, and plain ones over {name...} and subtrees, falling back
+// to the next candidate when a branch has no route for the method. As with
+// ServeMux, a GET route also serves HEAD. Unlike ServeMux, RadixMatcher does
+// not redirect to cleaned paths or to a subtree's trailing slash.
+type RadixMatcher struct {
+	mu    sync.RWMutex
+	hosts map[string]*radixNode
+}
+
+// NewRadixMatcher returns an empty RadixMatcher.
+func NewRadixMatcher() *RadixMatcher {
+	return &RadixMatcher{hosts: make(map[string]*radixNode)}
+}
+
+type radixNode struct {
+	static map[string]*radixNode
+
+	// params are the wildcard children, constrained ones first.
+	params []*radixParam
+
+	// routes end at this node, keyed by method ("" for any). rest routes
+	// match any remaining segments: {name...} wildcards and subtrees.
+	routes map[string]*radixRoute
+	rest   map[string]*radixRoute
+}
+
+type radixParam struct {
+	name  string
+	expr  string
+	re    *regexp.Regexp
+	child *radixNode
+}
+
+type radixRoute struct {
+	pattern string
+	handler http.Handler
+
+	// names are the wildcards in path order; restName names the
+	// {name...} wildcard, "" for a subtree.
+	names    []string
+	restName string
+}
+
+// radixPattern is a parsed pattern.
+type radixPattern struct {
+	method, host string
+	segments     []radixSegment
+
+	// rest is set for a {name...} wildcard or trailing-slash subtree,
+	// which match any remaining segments; restName names the wildcard.
+	rest     bool
+	restName string
+}
+
+type radixSegment struct {
+	static string
+	param  bool
+	name   string
+	expr   string
+	re     *regexp.Regexp
+}
+
+// CheckPattern implements PatternChecker.
+func (m *RadixMatcher) CheckPattern(pattern string) error {
+	_, err := parseRadixPattern(pattern)
+	return err
+}
+
+// Handle registers h for pattern. It panics if the pattern is invalid or
+// conflicts with one already registered, as ServeMux does.
+func (m *RadixMatcher) Handle(pattern string, h http.Handler) {
+	p, err := parseRadixPattern(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("api: pattern %q: %v", pattern, err))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	node := m.hosts[p.host]
+	if node == nil {
+		node = &radixNode{}
+		m.hosts[p.host] = node
+	}
+	route := &radixRoute{pattern: pattern, handler: h, restName: p.restName}
+	for _, seg := range p.segments {
+		if !seg.param {
+			node = node.staticChild(seg.static)
+			continue
+		}
+		route.names = append(route.names, seg.name)
+		node = node.paramChild(seg)
+	}
+
+	routes := &node.routes
+	if p.rest {
+		routes = &node.rest
+	}
+	if *routes == nil {
+		*routes = make(map[string]*radixRoute)
+	}
+	if prev, ok := (*routes)[p.method]; ok {
+		panic(fmt.Sprintf("api: pattern %q conflicts with pattern %q", pattern, prev.pattern))
+	}
+	(*routes)[p.method] = route
+}
+
+// Handler returns the handler for req and the pattern it matched, or a
+// not-found handler and "".
+func (m *RadixMatcher) Handler(req *http.Request) (http.Handler, string) {
+	route, _ := m.match(req)
+	if route == nil {
+		return http.NotFoundHandler(), ""
+	}
+	return route.handler, route.pattern
+}
+
+// ServeHTTP dispatches req to the matching handler, setting Request.Pattern
+// and the path values, or replies 404.
+func (m *RadixMatcher) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	route, values := m.match(req)
+	if route == nil {
+		http.NotFound(w, req)
+		return
+	}
+	req.Pattern = route.pattern
+	for i, name := range route.names {
+		req.SetPathValue(name, values[i])
+	}
+	if route.restName != "" {
+		req.SetPathValue(route.restName, values[len(values)-1])
+	}
+	route.handler.ServeHTTP(w, req)
+}
+
+// match finds the route for req: among the routes for its host, with and
+// then without the port, and then among those for any host.
+func (m *RadixMatcher) match(req *http.Request) (*radixRoute, []string) {
+	path := strings.TrimPrefix(req.URL.EscapedPath(), "/")
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if req.Host != "" && (len(m.hosts) > 1 || m.hosts[""] == nil) {
+		if route, values := m.hosts[req.Host].match(path, false, req.Method, nil); route != nil {
+			return route, values
+		}
+		if h, _, err := net.SplitHostPort(req.Host); err == nil {
+			if route, values := m.hosts[h].match(path, false, req.Method, nil); route != nil {
+				return route, values
+			}
+		}
+	}
+	return m.hosts[""].match(path, false, req.Method, nil)
+}
+
+// match matches the segments of the escaped path against the subtree at n.
+// done reports that no segments remain, which differs from path being a
+// single empty segment.
+func (n *radixNode) match(path string, done bool, method string, values []string) (*radixRoute, []string) {
+	if n == nil {
+		return nil, nil
+	}
+	if done {
+		if route := routeFor(n.routes, method); route != nil {
+			return route, values
+		}
+		return nil, nil
+	}
+
+	seg, rest, more := strings.Cut(path, "/")
+	seg = unescapeSegment(seg)
+	if child := n.static[seg]; child != nil {
+		if route, vals := child.match(rest, !more, method, values); route != nil {
+			return route, vals
+		}
+	}
+	// A wildcard matches a non-empty segment.
+	for _, p := range n.params {
+		if seg == "" || p.re != nil && !p.re.MatchString(seg) {
+			continue
+		}
+		if route, vals := p.child.match(rest, !more, method, append(values, seg)); route != nil {
+			return route, vals
+		}
+	}
+	if route := routeFor(n.rest, method); route != nil {
+		return route, append(values, unescapeSegment(path))
+	}
+	return nil, nil
+}
+
+// unescapeSegment decodes the percent escapes in s, leaving s as is when
+// it has none or they are malformed.
+func unescapeSegment(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	if u, err := url.PathUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
+
+// routeFor returns the route for method, serving HEAD from GET and falling
+// back to a route for any method.
+func routeFor(routes map[string]*radixRoute, method string) *radixRoute {
+	if r, ok := routes[method]; ok {
+		return r
+	}
+	if method == http.MethodHead {
+		if r, ok := routes[http.MethodGet]; ok {
+			return r
+		}
+	}
+	return routes[""]
+}
+
+func (n *radixNode) staticChild(s string) *radixNode {
+	if n.static == nil {
+		n.static = make(map[string]*radixNode)
+	}
+	child := n.static[s]
+	if child == nil {
+		child = &radixNode{}
+		n.static[s] = child
+	}
+	return child
+}
+
+func (n *radixNode) paramChild(seg radixSegment) *radixNode {
+	for _, p := range n.params {
+		if p.name == seg.name && p.expr == seg.expr {
+			return p.child
+		}
+	}
+	p := &radixParam{name: seg.name, expr: seg.expr, re: seg.re, child: &radixNode{}}
+	// Constrained wildcards are tried before plain ones.
+	i := len(n.params)
+	if p.re != nil {
+		i = slices.IndexFunc(n.params, func(q *radixParam) bool { return q.re == nil })
+		if i < 0 {
+			i = len(n.params)
+		}
+	}
+	n.params = slices.Insert(n.params, i, p)
+	return p.child
+}
+
+func parseRadixPattern(pattern string) (radixPattern, error) {
+	var p radixPattern
+	rest := pattern
+	if method, path, ok := strings.Cut(pattern, " "); ok {
+		p.method, rest = method, strings.TrimLeft(path, " \t")
+		if p.method == "" || strings.ContainsAny(p.method, "/{}") {
+			return p, fmt.Errorf("invalid method %q", p.method)
+		}
+	}
+	i := strings.IndexByte(rest, '/')
+	if i < 0 {
+		return p, errors.New("host/path missing /")
+	}
+	p.host, rest = rest[:i], rest[i+1:]
+	if strings.Contains(p.host, "{") {
+		return p, errors.New("host contains '{' (missing initial '/'?)")
+	}
+
+	seen := make(map[string]bool)
+	parts := strings.Split(rest, "/")
+	for i, part := range parts {
+		last := i == len(parts)-1
+		switch {
+		case part == "" && last:
+			// A trailing slash makes the pattern a subtree.
+			p.rest = true
+			continue
+		case part == "{$}":
+			if !last {
+				return p, errors.New("{$} not at end")
+			}
+			p.segments = append(p.segments, radixSegment{static: ""})
+			continue
+		case !strings.HasPrefix(part, "{"):
+			if strings.ContainsAny(part, "{}") {
+				return p, fmt.Errorf("bad wildcard segment %q (must be entire segment)", part)
+			}
+			p.segments = append(p.segments, radixSegment{static: part})
+			continue
+		case !strings.HasSuffix(part, "}"):
+			return p, fmt.Errorf("bad wildcard segment %q (must be entire segment)", part)
+		}
+
+		name, expr, constrained := strings.Cut(part[1:len(part)-1], ":")
+		if multi, ok := strings.CutSuffix(name, "..."); ok && !constrained {
+			if !last {
+				return p, errors.New("{...} wildcard not at end")
+			}
+			name = multi
+			p.rest, p.restName = true, name
+		}
+		if !isIdentifier(name) {
+			return p, fmt.Errorf("bad wildcard name %q", name)
+		}
+		if seen[name] {
+			return p, fmt.Errorf("duplicate wildcard name %q", name)
+		}
+		seen[name] = true
+		if p.restName == name {
+			continue
+		}
+
+		seg := radixSegment{param: true, name: name}
+		if constrained {
+			if expr == "" || strings.ContainsAny(expr, "{}") {
+				return p, fmt.Errorf("bad constraint %q for wildcard %q", expr, name)
+			}
+			re, err := regexp.Compile("^(?:" + expr + ")$")
+			if err != nil {
+				return p, fmt.Errorf("wildcard %q: %w", name, err)
+			}
+			seg.expr, seg.re = expr, re
+		}
+		p.segments = append(p.segments, seg)
+	}
+	return p, nil
+}
+
+// isIdentifier reports whether s is a valid wildcard name.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && (i == 0 || !('0' <= c && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
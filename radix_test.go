@@ -0,0 +1,227 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+// patternHandler replies with the matched pattern and the named path values.
+func patternHandler(names ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := r.Pattern
+		for _, n := range names {
+			body += " " + n + "=" + r.PathValue(n)
+		}
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+func TestRadixMatcher(t *testing.T) {
+	t.Parallel()
+
+	m := api.NewRadixMatcher()
+	m.Handle("GET /users/{id}", patternHandler("id"))
+	m.Handle("GET /users/me", patternHandler())
+	m.Handle("GET /users/{id:[0-9]+}/posts", patternHandler("id"))
+	m.Handle("GET /users/{name}/posts", patternHandler("name"))
+	m.Handle("POST /users/{id}", patternHandler("id"))
+	m.Handle("GET /files/{path...}", patternHandler("path"))
+	m.Handle("/static/", patternHandler())
+	m.Handle("GET /exact/{$}", patternHandler())
+	m.Handle("GET api.example.com/users/{id}", patternHandler("id"))
+	m.Handle("DELETE /users/me", patternHandler())
+
+	tests := map[string]struct {
+		method string
+		host   string
+		path   string
+		want   string
+	}{
+		"wildcard":                   {path: "/users/42", want: "GET /users/{id} id=42"},
+		"static beats wildcard":      {path: "/users/me", want: "GET /users/me"},
+		"constraint matches":         {path: "/users/42/posts", want: "GET /users/{id:[0-9]+}/posts id=42"},
+		"constraint falls through":   {path: "/users/ann/posts", want: "GET /users/{name}/posts name=ann"},
+		"method backtracks":          {method: http.MethodPost, path: "/users/me", want: "POST /users/{id} id=me"},
+		"head served by get":         {method: http.MethodHead, path: "/users/42", want: "GET /users/{id} id=42"},
+		"rest wildcard":              {path: "/files/a/b%2Fc.txt", want: "GET /files/{path...} path=a/b/c.txt"},
+		"empty rest wildcard":        {path: "/files/", want: "GET /files/{path...} path="},
+		"subtree any method":         {method: http.MethodPut, path: "/static/css/site.css", want: "/static/"},
+		"exact trailing slash":       {path: "/exact/", want: "GET /exact/{$}"},
+		"host route":                 {host: "api.example.com:8443", path: "/users/7", want: "GET api.example.com/users/{id} id=7"},
+		"other host uses any host":   {host: "other.example.com", path: "/users/7", want: "GET /users/{id} id=7"},
+		"no match":                   {path: "/nope"},
+		"exact does not match below": {path: "/exact/more"},
+		"wildcard needs a segment":   {path: "/users/"},
+		"method not registered":      {method: http.MethodPatch, path: "/users/42"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tt.path, http.NoBody)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+
+			_, pattern := m.Handler(req)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+
+			if tt.want == "" {
+				assert.Empty(t, pattern)
+				assert.Equal(t, http.StatusNotFound, w.Code)
+				return
+			}
+			assert.NotEmpty(t, pattern)
+			require.Equal(t, http.StatusOK, w.Code)
+			if method != http.MethodHead {
+				assert.Equal(t, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRadixMatcher_invalid_patterns(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"missing slash":        "GET users",
+		"partial wildcard":     "GET /users/id{id}",
+		"unterminated":         "GET /users/{id",
+		"bad regexp":           "GET /users/{id:[0-9}",
+		"braces in constraint": "GET /users/{id:[0-9]{3}}",
+		"rest not at end":      "GET /files/{path...}/raw",
+		"duplicate name":       "GET /a/{id}/b/{id}",
+		"bad name":             "GET /a/{1id}",
+		"dollar not at end":    "GET /a/{$}/b",
+	}
+
+	for name, pattern := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := api.NewRadixMatcher()
+			require.Error(t, m.CheckPattern(pattern))
+			assert.Panics(t, func() { m.Handle(pattern, http.NotFoundHandler()) })
+		})
+	}
+}
+
+func TestRadixMatcher_conflict(t *testing.T) {
+	t.Parallel()
+
+	m := api.NewRadixMatcher()
+	m.Handle("GET /users/{id}", http.NotFoundHandler())
+	m.Handle("GET /users/{id:[0-9]+}", http.NotFoundHandler())
+	assert.PanicsWithValue(t, `api: pattern "GET /users/{id}" conflicts with pattern "GET /users/{id}"`, func() {
+		m.Handle("GET /users/{id}", http.NotFoundHandler())
+	})
+}
+
+func TestWithMatcher(t *testing.T) {
+	t.Parallel()
+
+	type orderReq struct {
+		ID int `path:"id"`
+	}
+
+	r := api.New(api.WithMatcher(api.NewRadixMatcher()))
+	api.Get(r, "/orders/{id:[0-9]+}", func(_ context.Context, req *orderReq) (*api.Resp[int], error) {
+		return &api.Resp[int]{Body: req.ID}, nil
+	})
+	api.Delete(r, "/orders/{id:[0-9]+}", func(_ context.Context, _ *orderReq) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	tests := map[string]struct {
+		method     string
+		path       string
+		wantStatus int
+		wantAllow  string
+	}{
+		"match":              {method: http.MethodGet, path: "/orders/12", wantStatus: http.StatusOK},
+		"constraint rejects": {method: http.MethodGet, path: "/orders/abc", wantStatus: http.StatusNotFound},
+		"head":               {method: http.MethodHead, path: "/orders/12", wantStatus: http.StatusOK},
+		"method not allowed": {
+			method: http.MethodPut, path: "/orders/12",
+			wantStatus: http.StatusMethodNotAllowed, wantAllow: "DELETE, GET, HEAD, OPTIONS",
+		},
+		"options": {
+			method: http.MethodOptions, path: "/orders/12",
+			wantStatus: http.StatusNoContent, wantAllow: "DELETE, GET, HEAD, OPTIONS",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, http.NoBody))
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantAllow != "" {
+				assert.Equal(t, tt.wantAllow, w.Header().Get("Allow"))
+			}
+		})
+	}
+
+	op := r.Spec().Paths["/orders/{id}"]["get"]
+	assert.Equal(t, "getOrdersById", op.OperationID)
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, "^(?:[0-9]+)$", op.Parameters[0].Schema.Pattern)
+}
+
+func TestWithMatcher_registration(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		ID string `path:"id"`
+	}
+	handler := func(_ context.Context, _ *Req) (*api.Void, error) { return nil, nil }
+
+	// The default ServeMux does not accept constraints.
+	err := registrationPanic(t, func() { api.Get(api.New(), "/users/{id:[0-9]+}", handler) })
+	assert.Contains(t, err.Problems[0].Message, "invalid pattern")
+
+	err = registrationPanic(t, func() {
+		api.Get(api.New(api.WithMatcher(api.NewRadixMatcher())), "/users/{id:[0-9}", handler)
+	})
+	assert.Contains(t, err.Problems[0].Message, "invalid pattern")
+
+	assert.NotPanics(t, func() {
+		api.Get(api.New(api.WithMatcher(api.NewRadixMatcher())), "/users/{id:[a-z]+}", handler)
+	})
+}
+
+func BenchmarkMatcher_large_table(b *testing.B) {
+	matchers := map[string]api.Matcher{
+		"ServeMux": http.NewServeMux(),
+		"Radix":    api.NewRadixMatcher(),
+	}
+	for name, m := range matchers {
+		for i := range 1000 {
+			m.Handle(fmt.Sprintf("GET /svc%d/items/{id}/parts/{part}", i), http.NotFoundHandler())
+		}
+		req := httptest.NewRequest(http.MethodGet, "/svc999/items/42/parts/7", http.NoBody)
+
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				m.Handler(req)
+			}
+		})
+	}
+}
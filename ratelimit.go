@@ -53,7 +53,7 @@ func RateLimit(cfg RateLimitConfig) Middleware {
 		lastCleanup time.Time
 	)
 
-	return func(next http.Handler) http.Handler {
+	return Named("ratelimit", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			key := cfg.KeyFunc(r)
 
@@ -89,7 +89,16 @@ func RateLimit(cfg RateLimitConfig) Middleware {
 
 			next.ServeHTTP(w, r)
 		})
-	}
+	})
+}
+
+// WithRateLimit applies RateLimit to this route alone, with limiters kept
+// separate from every other route. The rate and burst are also reported by
+// Routes, so gateway exporters can enforce the same limit at the edge.
+func WithRateLimit(cfg RateLimitConfig) RouteOption {
+	return RouteOptionFunc(func(ri *routeInfo) {
+		ri.rateLimit = &cfg
+	})
 }
 
 type limiterEntry struct {
@@ -69,3 +69,77 @@ func TestOperationInfo_fields(t *testing.T) {
 	assert.Equal(t, "desc", info.Description)
 	assert.Equal(t, []string{"a", "b"}, info.Tags)
 }
+
+func TestRawTyped(t *testing.T) {
+	t.Parallel()
+
+	type proxyReq struct {
+		Tenant string `path:"tenant"`
+		Trace  string `header:"X-Trace-ID"`
+		Body   struct {
+			Query string `json:"query"`
+		}
+	}
+	type proxyResp struct {
+		Cache string `header:"X-Cache"`
+		Body  struct {
+			Rows int `json:"rows"`
+		}
+	}
+
+	r := api.New()
+	api.RawTyped[proxyReq, proxyResp](r, http.MethodPost, "/tenants/{tenant}/query", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Tenant", req.PathValue("tenant"))
+		w.WriteHeader(http.StatusAccepted)
+	}, api.OperationInfo{Summary: "Proxy a query", Tags: []string{"proxy"}})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tenants/acme/query", http.NoBody))
+	assert.Equal(t, http.StatusAccepted, w.Code, "the handler is not bound or validated")
+	assert.Equal(t, "acme", w.Header().Get("X-Tenant"))
+
+	op, ok := r.Spec().Paths["/tenants/{tenant}/query"]["post"]
+	require.True(t, ok)
+	assert.Equal(t, "Proxy a query", op.Summary)
+
+	params := make(map[string]string)
+	for _, p := range op.Parameters {
+		params[p.Name] = p.In
+	}
+	assert.Equal(t, map[string]string{"tenant": "path", "X-Trace-ID": "header"}, params)
+	require.NotNil(t, op.RequestBody)
+	assert.Contains(t, op.RequestBody.Content, "application/json")
+
+	require.Contains(t, op.Responses, "200")
+	assert.Contains(t, op.Responses["200"].Content, "application/json")
+	assert.Contains(t, op.Responses["200"].Headers, "X-Cache")
+	assert.Contains(t, op.Responses, "404")
+	assert.NotEmpty(t, op.Responses["404"].Content)
+}
+
+func TestRawTyped_checks_params(t *testing.T) {
+	t.Parallel()
+
+	type missingPath struct {
+		ID string `path:"id"`
+	}
+	type badQuery struct {
+		Filter chan int `query:"filter"`
+	}
+
+	tests := map[string]func(r *api.Router){
+		"path param not in pattern": func(r *api.Router) {
+			api.RawTyped[missingPath, api.Void](r, http.MethodGet, "/items", func(http.ResponseWriter, *http.Request) {}, api.OperationInfo{})
+		},
+		"unbindable param": func(r *api.Router) {
+			api.RawTyped[badQuery, api.Void](r, http.MethodGet, "/items", func(http.ResponseWriter, *http.Request) {}, api.OperationInfo{})
+		},
+	}
+
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Panics(t, func() { register(api.New()) })
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"reflect"
+)
+
+// RedactedMask replaces the value of redacted string fields.
+const RedactedMask = "[REDACTED]"
+
+// RedactionPolicy reports whether the caller may see fields tagged with the
+// given redact category. The category is the tag value, so both
+// `redact:"true"` and `redact:"pii"` are supported; the policy decides what
+// each category means.
+type RedactionPolicy func(ctx context.Context, category string) bool
+
+// WithRedactionPolicy enforces redact tags on response bodies. Before a
+// response is encoded, every field tagged redact whose category the policy
+// rejects is masked: strings become RedactedMask and other kinds are zeroed.
+// The handler's value is never mutated; a masked copy is encoded instead.
+//
+//	type User struct {
+//	    Name string `json:"name"`
+//	    SSN  string `json:"ssn" redact:"pii"`
+//	}
+//
+// Without a policy, redact tags only affect Redact.
+func WithRedactionPolicy(p RedactionPolicy) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.redaction = p
+	})
+}
+
+// Redact returns a copy of v with every redact-tagged field masked,
+// regardless of category. Use it when passing request or response values to
+// loggers and audit sinks:
+//
+//	logger.Info("created", "user", api.Redact(user))
+func Redact(v any) any {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !typeHasFieldPolicy(rv.Type()) {
+		return v
+	}
+	return filterFields(rv, fieldFilter{reveal: func(string) bool { return false }}).Interface()
+}
@@ -0,0 +1,116 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type redactAddress struct {
+	Street string `json:"street" redact:"pii"`
+	City   string `json:"city"`
+}
+
+type redactUser struct {
+	Name     string          `json:"name"`
+	SSN      string          `json:"ssn" redact:"pii"`
+	Salary   int             `json:"salary,omitempty" redact:"finance"`
+	Address  *redactAddress  `json:"address"`
+	Previous []redactAddress `json:"previous"`
+}
+
+type redactPolicyKey struct{}
+
+func TestWithRedactionPolicy(t *testing.T) {
+	t.Parallel()
+
+	source := &redactUser{
+		Name:     "Ada",
+		SSN:      "123-45-6789",
+		Salary:   100,
+		Address:  &redactAddress{Street: "1 Main", City: "London"},
+		Previous: []redactAddress{{Street: "2 Side", City: "Paris"}},
+	}
+
+	r := api.New(api.WithRedactionPolicy(func(ctx context.Context, category string) bool {
+		allowed, _ := ctx.Value(redactPolicyKey{}).(string)
+		return allowed == category
+	}))
+	api.Get(r, "/user", func(_ context.Context, _ *api.Void) (*api.Resp[*redactUser], error) {
+		return &api.Resp[*redactUser]{Body: source}, nil
+	})
+
+	tests := map[string]struct {
+		allowed string
+		want    string
+	}{
+		"masks all": {
+			want: `{"name":"Ada","ssn":"[REDACTED]","address":{"street":"[REDACTED]","city":"London"},"previous":[{"street":"[REDACTED]","city":"Paris"}]}`,
+		},
+		"reveals category": {
+			allowed: "pii",
+			want:    `{"name":"Ada","ssn":"123-45-6789","address":{"street":"1 Main","city":"London"},"previous":[{"street":"2 Side","city":"Paris"}]}`,
+		},
+		"reveals other category": {
+			allowed: "finance",
+			want:    `{"name":"Ada","ssn":"[REDACTED]","salary":100,"address":{"street":"[REDACTED]","city":"London"},"previous":[{"street":"[REDACTED]","city":"Paris"}]}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/user", nil)
+			req = req.WithContext(context.WithValue(req.Context(), redactPolicyKey{}, tt.allowed))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, tt.want, w.Body.String())
+		})
+	}
+
+	assert.Equal(t, "123-45-6789", source.SSN, "handler value must not be mutated")
+	assert.Equal(t, "1 Main", source.Address.Street)
+}
+
+func TestRedact_without_policy(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/user", func(_ context.Context, _ *api.Void) (*api.Resp[redactUser], error) {
+		return &api.Resp[redactUser]{Body: redactUser{Name: "Ada", SSN: "123-45-6789"}}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user", nil))
+
+	assert.Contains(t, w.Body.String(), "123-45-6789")
+}
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	u := redactUser{Name: "Ada", SSN: "123-45-6789", Salary: 100}
+
+	got, ok := api.Redact(u).(redactUser)
+	require.True(t, ok)
+	assert.Equal(t, "Ada", got.Name)
+	assert.Equal(t, api.RedactedMask, got.SSN)
+	assert.Zero(t, got.Salary)
+	assert.Equal(t, "123-45-6789", u.SSN)
+
+	ptr, ok := api.Redact(&u).(*redactUser)
+	require.True(t, ok)
+	assert.Equal(t, api.RedactedMask, ptr.SSN)
+
+	assert.Nil(t, api.Redact(nil))
+	assert.Equal(t, "plain", api.Redact("plain"))
+}
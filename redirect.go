@@ -7,7 +7,7 @@ import (
 
 // HTTPSRedirect returns middleware that redirects HTTP requests to HTTPS.
 func HTTPSRedirect() Middleware {
-	return func(next http.Handler) http.Handler {
+	return Named("httpsredirect", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if r.TLS == nil && r.Header.Get("X-Forwarded-Proto") != "https" {
 				target := "https://" + r.Host + r.URL.RequestURI()
@@ -16,12 +16,12 @@ func HTTPSRedirect() Middleware {
 			}
 			next.ServeHTTP(w, r)
 		})
-	}
+	})
 }
 
 // TrailingSlash returns middleware that strips trailing slashes and redirects.
 func TrailingSlash() Middleware {
-	return func(next http.Handler) http.Handler {
+	return Named("trailingslash", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if r.URL.Path != "/" && strings.HasSuffix(r.URL.Path, "/") {
 				target := strings.TrimRight(r.URL.Path, "/")
@@ -33,12 +33,12 @@ func TrailingSlash() Middleware {
 			}
 			next.ServeHTTP(w, r)
 		})
-	}
+	})
 }
 
 // NonWWWRedirect returns middleware that redirects www subdomain to non-www.
 func NonWWWRedirect() Middleware {
-	return func(next http.Handler) http.Handler {
+	return Named("nonwwwredirect", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if strings.HasPrefix(r.Host, "www.") {
 				target := r.URL.Scheme + "://" + strings.TrimPrefix(r.Host, "www.") + r.URL.RequestURI()
@@ -47,5 +47,5 @@ func NonWWWRedirect() Middleware {
 			}
 			next.ServeHTTP(w, r)
 		})
-	}
+	})
 }
@@ -1,10 +1,12 @@
 package api
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"net/http"
 	"reflect"
+	"time"
 )
 
 // Registrar is the interface accepted by the registration functions.
@@ -16,6 +18,22 @@ type Registrar interface {
 	getMode() ValidationMode
 	getCodecs() *codecRegistry
 	getValidateResponses() bool
+	getRequiredParams() bool
+	getPathMismatch() Code
+	getSecureCookies() *SecureCookies
+	getCursorKey() []byte
+	getCookieDefaults() *CookieDefaults
+	getRedactionPolicy() RedactionPolicy
+	getFieldScopes() ScopePolicy
+	getBodyDefaults() bool
+	getPolicy() PolicyEngine
+	getBudget() *budgetLimits
+	getBudgetObserver() func(context.Context, BudgetViolation)
+	getPayloadObserver() func(context.Context, PayloadMetrics)
+	getRouter() *Router
+	// getPrefix returns the path prefix the scope adds to its patterns.
+	getPrefix() string
+	getMatcher() Matcher
 	routeMiddleware() []Middleware
 	// errorOptionChain returns the scope's error-option list, outermost
 	// first. For a Router this is just the router's own options; for a
@@ -23,13 +41,35 @@ type Registrar interface {
 	errorOptionChain() []ErrorOption
 }
 
-func (r *Router) getValidator() ValidatorFunc     { return r.validator }
-func (r *Router) getErrorHandler() ErrorHandler   { return r.errorHandler }
-func (r *Router) getMode() ValidationMode         { return r.mode }
-func (r *Router) getCodecs() *codecRegistry       { return r.codecs }
-func (r *Router) getValidateResponses() bool      { return r.validateResponses }
-func (r *Router) routeMiddleware() []Middleware   { return nil }
-func (r *Router) errorOptionChain() []ErrorOption { return r.errorOpts }
+func (r *Router) getValidator() ValidatorFunc         { return r.validator }
+func (r *Router) getErrorHandler() ErrorHandler       { return r.errorHandler }
+func (r *Router) getMode() ValidationMode             { return r.mode }
+func (r *Router) getCodecs() *codecRegistry           { return r.codecs }
+func (r *Router) getValidateResponses() bool          { return r.validateResponses }
+func (r *Router) getRequiredParams() bool             { return r.requiredParams }
+func (r *Router) getPathMismatch() Code               { return cmp.Or(r.pathMismatch, CodeBadRequest) }
+func (r *Router) getSecureCookies() *SecureCookies    { return r.secureCookies }
+func (r *Router) getCursorKey() []byte                { return r.cursorKey }
+func (r *Router) getCookieDefaults() *CookieDefaults  { return r.cookieDefaults }
+func (r *Router) getRedactionPolicy() RedactionPolicy { return r.redaction }
+func (r *Router) getFieldScopes() ScopePolicy         { return r.fieldScopes }
+func (r *Router) getBodyDefaults() bool               { return r.bodyDefaults }
+func (r *Router) getPolicy() PolicyEngine             { return r.policy }
+func (r *Router) getPrefix() string                   { return "" }
+func (r *Router) getBudget() *budgetLimits            { return r.budget }
+func (r *Router) getMatcher() Matcher                 { return r.mux }
+func (r *Router) routeMiddleware() []Middleware       { return nil }
+func (r *Router) errorOptionChain() []ErrorOption     { return r.errorOpts }
+
+func (r *Router) getBudgetObserver() func(context.Context, BudgetViolation) {
+	return r.budgetObserver
+}
+
+func (r *Router) getRouter() *Router { return r }
+
+func (r *Router) getPayloadObserver() func(context.Context, PayloadMetrics) {
+	return r.payloadObserver
+}
 
 // handlerConfig bundles the router-level configuration that buildHandler needs.
 type handlerConfig struct {
@@ -42,6 +82,24 @@ type handlerConfig struct {
 	responseDesc      *responseDescriptor
 	errorTemplate     *Err
 	validateResponses bool
+	requiredParams    bool
+	pathMismatch      Code
+	secureCookies     *SecureCookies
+	cursorKey         []byte
+	cookieDefaults    *CookieDefaults
+	redaction         RedactionPolicy
+	fieldScopes       ScopePolicy
+	bodyDefaults      *defaultPlan
+	sanitize          *sanitizePlan
+	contentEncoding   *contentEncoder
+	flushInterval     time.Duration
+	heartbeat         time.Duration
+	policy            PolicyEngine
+	routeMeta         *RouteDescription
+	ownership         []ownershipRule
+	budget            budget
+	payload           payloadObserver
+	router            *Router
 }
 
 // register is the internal generic registration function.
@@ -58,106 +116,160 @@ func register[Req, Resp any](reg Registrar, method, pattern string, h Handler[Re
 		opt.applyRoute(&ri)
 	}
 
-	// Determine default status: Void response → 204, otherwise 200.
-	if ri.status == 0 {
-		if ri.respType == reflect.TypeFor[Void]() {
-			ri.status = http.StatusNoContent
-		} else {
-			ri.status = http.StatusOK
-		}
-	}
+	// Problems are collected so a misused registration reports them all
+	// at once, with the caller's file and line.
+	var problems registrationProblems
 
-	// Void is a special "no response body" marker; it does not carry tags
-	// and does not need descriptor-driven emission.
-	if ri.respType != reflect.TypeFor[Void]() {
-		d, err := buildResponseDescriptor(ri.respType)
-		if err != nil {
-			panic(err)
+	describeTypes[Req, Resp](reg, &ri, &problems)
+	checkStatus(&problems, &ri)
+	checkExamples(&problems, &ri, reg.getCodecs())
+	if reg.getCodecs().protection.rejects(&ri) {
+		problems.add("wrap the array in an object, such as a struct with an Items field",
+			"top-level JSON array responses are rejected by WithContentProtection")
+	}
+	if ri.requestDesc != nil && ri.requestDesc.usesSecureCookies() && reg.getSecureCookies() == nil {
+		problems.add("configure the router with api.WithSecureCookies",
+			"secure cookie params require WithSecureCookies")
+	}
+	ri.applyErrorOptions(reg)
+
+	var defaults *defaultPlan
+	if reg.getBodyDefaults() {
+		if body := requestBodyType(&ri); body != nil {
+			var err error
+			defaults, err = buildDefaultPlan(body)
+			if err != nil {
+				problems.add("", "%v", err)
+			}
 		}
-		ri.responseDesc = d
 	}
 
-	reqDesc, err := buildRequestDescriptor(ri.reqType)
+	sanitize, err := buildSanitizePlan(ri.reqType)
 	if err != nil {
-		panic(err)
+		problems.add("", "%v", err)
 	}
-	ri.requestDesc = reqDesc
+	problems.check(method, pattern)
 
-	// Merge scope error options: router chain → group chain → route options.
-	// Apply them to a fresh *Err that serves as the per-route template.
-	chain := reg.errorOptionChain()
-	ri.errorTemplate = &Err{}
-	for _, opt := range chain {
-		opt.applyErr(ri.errorTemplate)
+	if ri.policy == nil {
+		ri.policy = reg.getPolicy()
 	}
-	for _, opt := range ri.errorOpts {
-		opt.applyErr(ri.errorTemplate)
+	if ri.budget == nil {
+		ri.budget = reg.getBudget()
 	}
-	// Default body mapper: RFC 9457 ProblemDetails. Consumers opt out
-	// with WithoutErrorBody or override with WithErrorBody.
-	if ri.errorTemplate.body == nil {
-		ri.errorTemplate.body = &typedBodyMapper[ProblemDetails]{fn: ErrorBodyProblemDetails}
+	ri.errorCodes = append(ri.errorCodes, ri.budget.codes()...)
+	// The description also serves APIKeyAuth, which may sit in any
+	// middleware chain, so every route gets one.
+	ri.meta = &RouteDescription{}
+	if ri.policy != nil {
+		ri.errorCodes = append(ri.errorCodes, CodeUnauthorized, CodeForbidden)
+	}
+	for _, o := range ri.ownership {
+		ri.errorCodes = append(ri.errorCodes, CodeUnauthorized, o.denyCode)
 	}
-	ri.errorCodes = append([]Code{}, ri.errorTemplate.documentedCodes...)
 
 	cfg := handlerConfig{
 		defaultStatus:     ri.status,
 		mode:              ri.mode,
 		validator:         reg.getValidator(),
 		errHandler:        reg.getErrorHandler(),
-		codecs:            reg.getCodecs(),
+		codecs:            reg.getCodecs().withEncoders(ri.encoders),
 		requestDesc:       ri.requestDesc,
 		responseDesc:      ri.responseDesc,
 		errorTemplate:     ri.errorTemplate,
 		validateResponses: reg.getValidateResponses(),
+		requiredParams:    reg.getRequiredParams(),
+		pathMismatch:      reg.getPathMismatch(),
+		secureCookies:     reg.getSecureCookies(),
+		cursorKey:         reg.getCursorKey(),
+		cookieDefaults:    reg.getCookieDefaults(),
+		redaction:         reg.getRedactionPolicy(),
+		fieldScopes:       reg.getFieldScopes(),
+		bodyDefaults:      defaults,
+		sanitize:          sanitize,
+		contentEncoding:   newContentEncoder(ri.contentEncoding),
+		flushInterval:     ri.flushInterval,
+		heartbeat:         ri.heartbeat,
+		policy:            ri.policy,
+		routeMeta:         ri.meta,
+		ownership:         ri.ownership,
+		budget:            budget{limits: ri.budget, observer: reg.getBudgetObserver()},
+		payload:           payloadObserver{route: ri.meta, observer: reg.getPayloadObserver()},
+		router:            reg.getRouter(),
 	}
 
 	ri.handler = buildHandler(h, cfg)
+	if ri.profile {
+		ri.handler = profileHandler(ri.meta, ri.handler)
+	}
 
 	// Apply per-route body limit.
 	if ri.bodyLimit > 0 {
 		ri.handler = BodyLimit(ri.bodyLimit)(ri.handler)
 	}
 
+	// Apply per-route rate limit.
+	if ri.rateLimit != nil {
+		ri.handler = RateLimit(*ri.rateLimit)(ri.handler)
+	}
+
 	// Apply route-level middleware (from Group).
 	routeMW := reg.routeMiddleware()
 	for i := len(routeMW) - 1; i >= 0; i-- {
 		ri.handler = routeMW[i](ri.handler)
 	}
+	// Middleware may look the router up, as WriteError does; the handler
+	// alone carries it in its context.
+	ri.attachesRouter = len(routeMW) == 0
 
 	reg.addRoute(ri)
 }
 
-// buildHandler wraps a typed Handler into an http.Handler. The validation
-// pipeline runs in the order dictated by cfg.mode; any returned
-// ValidationErrors is routed through cfg.errBuilder.
-func buildHandler[Req, Resp any](h Handler[Req, Resp], cfg handlerConfig) http.Handler {
-	writeErr := func(w http.ResponseWriter, r *http.Request, err error) {
-		// ValidationErrors convert to an *Err with each violation
-		// attached as a detail.
-		var ve ValidationErrors
-		if errors.As(err, &ve) {
-			opts := make([]ErrorOption, 0, len(ve)+1)
-			opts = append(opts, WithMessage("validation failed"))
-			for _, v := range ve {
-				opts = append(opts, WithDetail(v))
-			}
-			err = Error(CodeUnprocessableContent, opts...)
+// resolveErr converts ValidationErrors to a 422 *Err with each violation
+// attached as a detail, and domain errors registered with RegisterProblem
+// to an *Err carrying their problem type. Other errors are returned as is.
+func resolveErr(err error) error {
+	var ve ValidationErrors
+	if errors.As(err, &ve) {
+		opts := make([]ErrorOption, 0, len(ve)+1)
+		opts = append(opts, WithMessage("validation failed"))
+		for _, v := range ve {
+			opts = append(opts, WithDetail(v))
 		}
+		return Error(CodeUnprocessableContent, opts...)
+	}
 
-		// Consumer-provided ErrorHandler wins when set.
-		if cfg.errHandler != nil {
-			cfg.errHandler(w, r, err)
-			return
+	var apiErr *Err
+	if !errors.As(err, &apiErr) {
+		if pe, ok := lookupProblem(err); ok {
+			return pe
 		}
+	}
+	return err
+}
 
-		// Classify the error. Non-*Err errors are wrapped as CodeInternal.
-		var apiErr *Err
-		if !errors.As(err, &apiErr) {
-			apiErr = &Err{code: CodeInternal, message: err.Error(), cause: err}
-		}
-		emitErr(w, r, mergeErr(cfg.errorTemplate, apiErr), cfg.codecs)
+// writeErr renders err as the route's error response.
+func (cfg *handlerConfig) writeErr(w http.ResponseWriter, r *http.Request, err error) {
+	err = resolveErr(err)
+
+	// Consumer-provided ErrorHandler wins when set.
+	if cfg.errHandler != nil {
+		cfg.errHandler(w, r, err)
+		return
+	}
+
+	// Classify the error. Non-*Err errors are wrapped as CodeInternal.
+	var apiErr *Err
+	if !errors.As(err, &apiErr) {
+		apiErr = &Err{code: CodeInternal, message: err.Error(), cause: err}
 	}
+	emitErr(w, r, mergeErr(cfg.errorTemplate, apiErr), cfg.codecs, cfg.cookieDefaults)
+}
+
+// buildHandler wraps a typed Handler into an http.Handler. The validation
+// pipeline runs in the order dictated by cfg.mode; any returned
+// ValidationErrors is routed through cfg.errBuilder.
+func buildHandler[Req, Resp any](h Handler[Req, Resp], cfg handlerConfig) http.Handler {
+	writeErr := cfg.writeErr
 
 	runConstraints := func(req *Req) error {
 		return validateConstraints(req)
@@ -179,23 +291,74 @@ func buildHandler[Req, Resp any](h Handler[Req, Resp], cfg handlerConfig) http.H
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// 406 Not Acceptable: if Accept is explicit and no encoder matches.
+		setLoggerRoute(r)
+		setUsageRoute(r)
+		r = withCursorKey(r, cfg.cursorKey)
+		if err := checkAPIKey(r.Context(), cfg.routeMeta); err != nil {
+			writeErr(w, r, err)
+			return
+		}
+		payload, w := cfg.payload.start(w, r)
+		defer payload.finish(cfg.payload, r)
+
+		// 406 Not Acceptable: if Accept is explicit and neither an encoder
+		// nor the response's stream format matches.
 		if accept := r.Header.Get("Accept"); accept != "" {
-			if _, ok := cfg.codecs.negotiate(accept); !ok {
+			if _, ok := cfg.codecs.negotiate(accept); !ok && !cfg.responseDesc.streamsAccepted(accept) {
 				writeErr(w, r, Error(CodeNotAcceptable, WithMessage("unsupported Accept media type")))
 				return
 			}
 		}
 
-		req, err := decodeRequest[Req](r, cfg.codecs, cfg.requestDesc)
+		for _, o := range cfg.ownership {
+			if err := o.verify(r); err != nil {
+				writeErr(w, r, err)
+				return
+			}
+		}
+
+		if cfg.requiredParams {
+			if err := missingParams(r, cfg.requestDesc); err != nil {
+				writeErr(w, r, err)
+				return
+			}
+		}
+
+		overBudget := cfg.budget.limitBody(r)
+		decodeStart := payload.now()
+		req, err := decodeRequest[Req](r, cfg.codecs, cfg.requestDesc, cfg.secureCookies)
+		payload.decoded(decodeStart)
 		if err != nil {
-			writeErr(w, r, Error(CodeBadRequest, WithMessage(err.Error())))
+			err = cfg.budget.decodeErr(r, err, overBudget)
+			// A missing required claim or session value is already a 401.
+			var apiErr *Err
+			switch {
+			case errors.As(err, &apiErr):
+			case errors.Is(err, ErrBindPath):
+				err = Error(cfg.pathMismatch, WithMessage(err.Error()))
+			default:
+				err = Error(CodeBadRequest, WithMessage(err.Error()))
+			}
+			writeErr(w, r, err)
 			return
 		}
+		if cfg.bodyDefaults != nil {
+			applyBodyDefaults(req, cfg.requestDesc, cfg.bodyDefaults)
+		}
+		if cfg.sanitize != nil {
+			applySanitizers(req, cfg.sanitize)
+		}
 
-		ctx, bgQ := withBackgroundQueue(r.Context())
+		if cfg.policy != nil {
+			if err := authorize(r.Context(), cfg.policy, cfg.routeMeta, req); err != nil {
+				writeErr(w, r, err)
+				return
+			}
+		}
+
+		ctx := newHandlerContext(r.Context(), cfg.router)
 		//nolint:contextcheck // background tasks are intentionally detached
-		defer runBackgroundTasks(bgQ)
+		defer runBackgroundTasks(&ctx.bg)
 
 		steps := validationSteps(ctx, cfg.mode, req, runConstraints, runPerTypeValidator, runRouterValidator)
 		for _, step := range steps {
@@ -205,7 +368,11 @@ func buildHandler[Req, Resp any](h Handler[Req, Resp], cfg handlerConfig) http.H
 			}
 		}
 
-		resp, err := h(ctx, req)
+		hctx, cancel := cfg.budget.handlerContext(ctx)
+		resp, err := h(hctx, req)
+		err = cfg.budget.handlerErr(hctx, r, err)
+		cancel()
+		ctx.jar.flush(w, cfg.cookieDefaults)
 		if err != nil {
 			writeErr(w, r, err)
 			return
@@ -231,10 +398,47 @@ func buildHandler[Req, Resp any](h Handler[Req, Resp], cfg handlerConfig) http.H
 			}
 		}
 
-		encodeResponse(w, r, resp, cfg.responseDesc, cfg.defaultStatus, cfg.codecs)
+		encodeStart := payload.now()
+		bw, finish := cfg.budget.responseWriter(w, r)
+		encodeResponse(bw, r, resp, &cfg)
+		if err := finish(); err != nil {
+			writeErr(w, r, err)
+		}
+		payload.encoded(encodeStart)
 	})
 }
 
+// handlerContext is the context a typed handler runs with. It holds the
+// router and the per-request state read by Background and SetCookie, so a
+// request pays for one allocation rather than one context and one value
+// per feature.
+type handlerContext struct {
+	context.Context
+	router *Router
+	bg     bgQueue
+	jar    cookieJar
+}
+
+// newHandlerContext returns a handler context for router with an empty
+// background queue and cookie jar. The framework drains both once the
+// handler returns.
+func newHandlerContext(parent context.Context, router *Router) *handlerContext {
+	return &handlerContext{Context: parent, router: router}
+}
+
+// Value implements context.Context.
+func (c *handlerContext) Value(key any) any {
+	switch key.(type) {
+	case routerKey:
+		return c.router
+	case bgQueueKey:
+		return &c.bg
+	case cookieJarKey:
+		return &c.jar
+	}
+	return c.Context.Value(key)
+}
+
 // validationSteps returns the validation closures in the order dictated by
 // the configured ValidationMode. Steps that don't apply (e.g., constraints
 // when mode is Off) are omitted.
@@ -294,8 +498,111 @@ func Raw(reg Registrar, method, pattern string, h RawHandler, info OperationInfo
 		desc:    info.Description,
 		tags:    info.Tags,
 		status:  info.Status,
-		handler: http.HandlerFunc(h),
+		meta:    &RouteDescription{},
+	}
+	ri.handler = apiKeyHandler(ri.meta, http.HandlerFunc(h))
+
+	routeMW := reg.routeMiddleware()
+	for i := len(routeMW) - 1; i >= 0; i-- {
+		ri.handler = routeMW[i](ri.handler)
+	}
+
+	reg.addRoute(ri)
+}
+
+// describeTypes fills in what ri documents from Req and Resp, for typed
+// and RawTyped routes alike: the default status, the request and response
+// descriptors, and generic component names. It reports params that cannot
+// be bound or that the pattern does not declare.
+func describeTypes[Req, Resp any](reg Registrar, ri *routeInfo, p *registrationProblems) {
+	// Determine default status: Void response → 204, BulkResp → 207,
+	// otherwise 200.
+	if ri.status == 0 {
+		if ri.respType == reflect.TypeFor[Void]() {
+			ri.status = http.StatusNoContent
+		} else if _, ok := any(new(Resp)).(interface{ multiStatus() }); ok {
+			ri.status = http.StatusMultiStatus
+		} else {
+			ri.status = http.StatusOK
+		}
+	}
+
+	// Void is a special "no response body" marker; it does not carry tags
+	// and does not need descriptor-driven emission.
+	if ri.respType != reflect.TypeFor[Void]() {
+		d, err := buildResponseDescriptor(ri.respType)
+		if err != nil {
+			p.add("", "%v", err)
+		}
+		ri.responseDesc = d
+	}
+	// Generic response types name their schemas after their type arguments.
+	if c, ok := any(new(Resp)).(componentNamer); ok {
+		c.nameComponents(ri)
+	}
+
+	reqDesc, err := buildRequestDescriptor(ri.reqType)
+	if err != nil {
+		p.add("", "%v", err)
 	}
+	ri.requestDesc = reqDesc
+	checkParamTypes(p, reqDesc)
+	checkOptionalParams(p, reqDesc)
+	checkPattern(p, reg.getMatcher(), ri.method, reg.getPrefix()+ri.pattern, reqDesc)
+}
+
+// applyErrorOptions merges the scope's error options, router chain then
+// group chain then route options, into a fresh *Err that serves as the
+// route's template, and documents its codes.
+func (ri *routeInfo) applyErrorOptions(reg Registrar) {
+	ri.errorTemplate = &Err{}
+	for _, opt := range reg.errorOptionChain() {
+		opt.applyErr(ri.errorTemplate)
+	}
+	for _, opt := range ri.errorOpts {
+		opt.applyErr(ri.errorTemplate)
+	}
+	// Default body mapper: RFC 9457 ProblemDetails. Consumers opt out
+	// with WithoutErrorBody or override with WithErrorBody.
+	if ri.errorTemplate.body == nil {
+		ri.errorTemplate.body = &typedBodyMapper[ProblemDetails]{fn: ErrorBodyProblemDetails}
+	}
+	ri.errorCodes = append([]Code{}, ri.errorTemplate.documentedCodes...)
+}
+
+// RawTyped registers a raw http.Handler like Raw, documenting the operation
+// from Req and Resp as a typed route would: parameters and request body
+// from Req, the success response and its headers from Resp, and the error
+// responses of the scope. Nothing is bound, validated, or encoded at
+// runtime; h handles the request itself. It suits WebSocket upgrades and
+// proxies whose contract is still worth publishing:
+//
+//	api.RawTyped[ChatReq, api.Void](r, http.MethodGet, "/rooms/{room}/ws", h.Upgrade,
+//	    api.OperationInfo{Summary: "Join a chat room", Status: http.StatusSwitchingProtocols})
+//
+// The status defaults as for typed routes: 204 for a Void response, 207
+// for a BulkResp, otherwise 200. Like typed routes, registration panics
+// when Req declares params the pattern lacks or that cannot be bound.
+func RawTyped[Req, Resp any](reg Registrar, method, pattern string, h RawHandler, info OperationInfo) {
+	ri := routeInfo{
+		method:   method,
+		pattern:  pattern,
+		summary:  info.Summary,
+		desc:     info.Description,
+		tags:     info.Tags,
+		status:   info.Status,
+		reqType:  reflect.TypeFor[Req](),
+		respType: reflect.TypeFor[Resp](),
+		meta:     &RouteDescription{},
+	}
+	ri.handler = apiKeyHandler(ri.meta, http.HandlerFunc(h))
+
+	var problems registrationProblems
+	describeTypes[Req, Resp](reg, &ri, &problems)
+	problems.check(method, pattern)
+
+	// The scope's error options shape the documented error responses.
+	ri.applyErrorOptions(reg)
 
 	routeMW := reg.routeMiddleware()
 	for i := len(routeMW) - 1; i >= 0; i-- {
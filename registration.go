@@ -0,0 +1,215 @@
+package api
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RegistrationError reports everything wrong with one route registration.
+// Get, Post, and the other registration functions panic with it, so misuse
+// fails at startup, with every problem listed at once, rather than at
+// request time.
+type RegistrationError struct {
+	Method  string
+	Pattern string
+
+	// File and Line locate the registration call in the caller's code.
+	File string
+	Line int
+
+	Problems []RegistrationProblem
+}
+
+// RegistrationProblem is one mistake in a route registration.
+type RegistrationProblem struct {
+	// Message says what is wrong.
+	Message string
+
+	// Hint says how to fix it; empty when the message says enough.
+	Hint string
+}
+
+func (e *RegistrationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "api: %s %s", e.Method, e.Pattern)
+	if e.File != "" {
+		fmt.Fprintf(&b, " (%s:%d)", e.File, e.Line)
+	}
+	if len(e.Problems) == 1 {
+		b.WriteString(": ")
+		writeProblem(&b, e.Problems[0], "\n\t")
+		return b.String()
+	}
+	fmt.Fprintf(&b, ": %d problems:", len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n\t- ")
+		writeProblem(&b, p, "\n\t  ")
+	}
+	return b.String()
+}
+
+func writeProblem(b *strings.Builder, p RegistrationProblem, indent string) {
+	b.WriteString(p.Message)
+	if p.Hint != "" {
+		b.WriteString(indent + "fix: " + p.Hint)
+	}
+}
+
+// registrationProblems collects the problems found while registering a
+// route.
+type registrationProblems []RegistrationProblem
+
+func (p *registrationProblems) add(hint, format string, args ...any) {
+	*p = append(*p, RegistrationProblem{Message: fmt.Sprintf(format, args...), Hint: hint})
+}
+
+// check panics with a RegistrationError when any problems were found.
+func (p registrationProblems) check(method, pattern string) {
+	if len(p) == 0 {
+		return
+	}
+	file, line := registrationCaller()
+	panic(&RegistrationError{Method: method, Pattern: pattern, File: file, Line: line, Problems: p})
+}
+
+// registrationCaller returns the first caller outside this package, which
+// is where the route was registered.
+func registrationCaller() (string, int) {
+	var pcs [32]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "github.com/bjaus/api.") {
+			return f.File, f.Line
+		}
+		if !more {
+			return "", 0
+		}
+	}
+}
+
+// checkStatus reports a WithStatus that cannot work with the response type.
+func checkStatus(p *registrationProblems, ri *routeInfo) {
+	switch {
+	case ri.status < 100 || ri.status > 599:
+		p.add("use one of the net/http Status constants",
+			"WithStatus(%d) is not an HTTP status code", ri.status)
+	case ri.respType == reflect.TypeFor[Void]():
+		if ri.status >= 400 {
+			p.add("return an error such as api.Error(api.CodeNotFound) from the handler instead",
+				"WithStatus(%d) on a Void response: error statuses come from returned errors", ri.status)
+		}
+	case !bodyAllowed(ri.status) && ri.responseDesc != nil && ri.responseDesc.body != nil:
+		p.add("respond with *api.Void, or drop the Body field from "+ri.respType.String(),
+			"WithStatus(%d) forbids a response body, but %s has a Body field", ri.status, ri.respType)
+	}
+}
+
+// bodyAllowed reports whether a response with the given status may carry a
+// body.
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusResetContent && status != http.StatusNotModified
+}
+
+// checkPattern reports a pattern the matcher would reject, and path params
+// the pattern does not declare. pattern includes any group prefix.
+func checkPattern(p *registrationProblems, m Matcher, method, pattern string, desc *requestDescriptor) {
+	if err := checkMatcherPattern(m, method+" "+pattern); err != nil {
+		p.add("patterns are paths such as /users/{id}; see net/http.ServeMux for the syntax",
+			"invalid pattern: %v", err)
+		return
+	}
+	if desc == nil {
+		return
+	}
+	wildcards := patternWildcards(pattern)
+	for _, param := range desc.params {
+		if param.in != paramInPath {
+			continue
+		}
+		if !slices.Contains(wildcards, param.name) {
+			p.add(fmt.Sprintf("add {%s} to the pattern, or rename the path tag to match a wildcard", param.name),
+				"path param %q is not a wildcard in the pattern", param.name)
+		}
+	}
+}
+
+// checkParamTypes reports path, query, header, and cookie params whose
+// field type binding cannot parse.
+func checkParamTypes(p *registrationProblems, desc *requestDescriptor) {
+	if desc == nil {
+		return
+	}
+	for _, param := range desc.params {
+		t := param.typ
+		//exhaustive:ignore
+		switch {
+		case param.in == paramInClaim || param.in == paramInSession || param.cursor:
+			continue
+		case param.deep:
+			checkDeepObjectTypes(p, param.name, t)
+			continue
+		case param.cookieParam:
+			f, _ := t.FieldByName("Value")
+			t = f.Type
+		case param.multi:
+			t = t.Elem()
+		}
+		if !paramTypeSupported(t) {
+			p.add("use a string, bool, integer, float, or time.Duration, a pointer to one, or a type implementing encoding.TextUnmarshaler",
+				"%s param %q has unsupported type %s", param.in, param.name, param.typ)
+		}
+	}
+}
+
+// checkOptionalParams reports pointer query, header, and cookie params
+// tagged required or default. A pointer param is optional by design: nil
+// means the client did not send it, so neither tag can take effect.
+func checkOptionalParams(p *registrationProblems, desc *requestDescriptor) {
+	if desc == nil {
+		return
+	}
+	for _, param := range desc.params {
+		if param.typ.Kind() != reflect.Pointer || (param.in != paramInQuery && param.in != paramInHeader && param.in != paramInCookie) {
+			continue
+		}
+		if param.required {
+			p.add(fmt.Sprintf("drop the required tag, or change the field to %s", param.typ.Elem()),
+				"%s param %q is a pointer, which marks it optional, but is tagged required", param.in, param.name)
+		}
+		if param.defaultValue != "" {
+			p.add(fmt.Sprintf("drop the default tag and treat nil as absent, or change the field to %s", param.typ.Elem()),
+				"%s param %q is a pointer, which is nil when absent, but has a default", param.in, param.name)
+		}
+	}
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// paramTypeSupported reports whether setFieldValue can parse into t.
+func paramTypeSupported(t reflect.Type) bool {
+	if t == reflect.PointerTo(locationType) {
+		return true
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) || t == reflect.TypeFor[time.Duration]() {
+		return true
+	}
+	//exhaustive:ignore
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+// registrationPanic runs register and returns the RegistrationError it
+// panics with.
+func registrationPanic(t *testing.T, register func()) *api.RegistrationError {
+	t.Helper()
+	var rec any
+	func() {
+		defer func() { rec = recover() }()
+		register()
+	}()
+	require.NotNil(t, rec, "registration must panic")
+	err, ok := rec.(error)
+	require.True(t, ok, "panic value %v", rec)
+	var regErr *api.RegistrationError
+	require.ErrorAs(t, err, &regErr)
+	return regErr
+}
+
+func TestRegistrationError(t *testing.T) {
+	t.Parallel()
+
+	type complexQuery struct {
+		N complex128 `query:"n"`
+	}
+	type missingPath struct {
+		ID string `path:"id"`
+	}
+	type bodyResp struct {
+		Body struct {
+			Name string `json:"name"`
+		}
+	}
+	type requiredPointer struct {
+		Limit *int `query:"limit" required:"true"`
+	}
+	type defaultPointer struct {
+		Tenant *string `header:"X-Tenant" default:"acme"`
+	}
+
+	tests := map[string]struct {
+		register func(r *api.Router)
+		want     string
+	}{
+		"unsupported param type": {
+			register: func(r *api.Router) {
+				api.Get(r, "/n", func(_ context.Context, _ *complexQuery) (*api.Void, error) { return nil, nil })
+			},
+			want: `query param "n" has unsupported type complex128`,
+		},
+		"path param missing from pattern": {
+			register: func(r *api.Router) {
+				api.Get(r, "/users", func(_ context.Context, _ *missingPath) (*api.Void, error) { return nil, nil })
+			},
+			want: `path param "id" is not a wildcard in the pattern`,
+		},
+		"bad pattern": {
+			register: func(r *api.Router) {
+				api.Get(r, "/users/{id", voidHandler)
+			},
+			want: "invalid pattern",
+		},
+		"error status on Void": {
+			register: func(r *api.Router) {
+				api.Get(r, "/gone", voidHandler, api.WithStatus(http.StatusGone))
+			},
+			want: "WithStatus(410) on a Void response",
+		},
+		"no content with body": {
+			register: func(r *api.Router) {
+				api.Get(r, "/thing", func(_ context.Context, _ *api.Void) (*bodyResp, error) { return nil, nil },
+					api.WithStatus(http.StatusNoContent))
+			},
+			want: "WithStatus(204) forbids a response body",
+		},
+		"required pointer param": {
+			register: func(r *api.Router) {
+				api.Get(r, "/items", func(_ context.Context, _ *requiredPointer) (*api.Void, error) { return nil, nil })
+			},
+			want: `query param "limit" is a pointer, which marks it optional, but is tagged required`,
+		},
+		"pointer param with default": {
+			register: func(r *api.Router) {
+				api.Get(r, "/items", func(_ context.Context, _ *defaultPointer) (*api.Void, error) { return nil, nil })
+			},
+			want: `header param "X-Tenant" is a pointer, which is nil when absent, but has a default`,
+		},
+		"invalid status": {
+			register: func(r *api.Router) {
+				api.Get(r, "/odd", voidHandler, api.WithStatus(1000))
+			},
+			want: "WithStatus(1000) is not an HTTP status code",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := registrationPanic(t, func() { tc.register(api.New()) })
+			require.Len(t, err.Problems, 1)
+			assert.Contains(t, err.Problems[0].Message, tc.want)
+			assert.NotEmpty(t, err.Problems[0].Hint)
+			assert.Contains(t, err.Error(), "fix: ")
+		})
+	}
+}
+
+func TestRegistrationError_collects_problems(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		ID    string    `path:"id"`
+		Since complex64 `header:"X-Since"`
+	}
+
+	err := registrationPanic(t, func() {
+		api.Get(api.New(), "/events", func(_ context.Context, _ *Req) (*api.Void, error) { return nil, nil },
+			api.WithStatus(http.StatusNotFound))
+	})
+
+	assert.Equal(t, http.MethodGet, err.Method)
+	assert.Equal(t, "/events", err.Pattern)
+	assert.Len(t, err.Problems, 3)
+	assert.Contains(t, err.Error(), "3 problems:")
+}
+
+func TestRegistrationError_caller(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		N complex64 `query:"n"`
+	}
+
+	err := registrationPanic(t, func() {
+		g := api.New().Group("/v1")
+		api.Post(g, "/n", func(_ context.Context, _ *Req) (*api.Void, error) { return nil, nil })
+	})
+
+	assert.Contains(t, err.File, "registration_test.go")
+	assert.Positive(t, err.Line)
+	assert.Contains(t, err.Error(), "registration_test.go:")
+}
+
+func TestRegistration_valid_params(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Org     string        `path:"org"`
+		ID      int64         `path:"id"`
+		Rest    string        `path:"rest"`
+		Since   *time.Time    `query:"since"`
+		Tags    []string      `query:"tags"`
+		Timeout time.Duration `header:"X-Timeout"`
+		Debug   bool          `cookie:"debug"`
+	}
+
+	r := api.New()
+	g := r.Group("/orgs/{org}")
+	assert.NotPanics(t, func() {
+		api.Get(g, "/items/{id}/{rest...}", func(_ context.Context, _ *Req) (*api.Void, error) { return nil, nil })
+	})
+}
+
+func TestRegistrationError_existing_checks(t *testing.T) {
+	t.Parallel()
+
+	type SecureReq struct {
+		Token string `cookie:"token,secure"`
+	}
+
+	err := registrationPanic(t, func() {
+		api.Get(api.New(), "/s", func(_ context.Context, _ *SecureReq) (*api.Void, error) { return nil, nil })
+	})
+	require.Len(t, err.Problems, 1)
+	assert.Contains(t, err.Problems[0].Message, "secure cookie params require WithSecureCookies")
+}
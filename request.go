@@ -1,11 +1,15 @@
 package api
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,7 +29,7 @@ const (
 
 // decodeRequest creates a new Req value and populates it from the HTTP request,
 // using the precomputed request descriptor to avoid per-request reflection.
-func decodeRequest[Req any](r *http.Request, codecs *codecRegistry, desc *requestDescriptor) (*Req, error) {
+func decodeRequest[Req any](r *http.Request, codecs *codecRegistry, desc *requestDescriptor, sc *SecureCookies) (*Req, error) {
 	req := new(Req)
 
 	if desc.category == catVoid {
@@ -34,7 +38,7 @@ func decodeRequest[Req any](r *http.Request, codecs *codecRegistry, desc *reques
 
 	v := reflect.ValueOf(req).Elem()
 
-	if err := bindParams(v, r, desc); err != nil {
+	if err := bindParams(v, r, desc, sc); err != nil {
 		return nil, err
 	}
 
@@ -44,12 +48,20 @@ func decodeRequest[Req any](r *http.Request, codecs *codecRegistry, desc *reques
 			return nil, fmt.Errorf("%w: %w", ErrBindBody, err)
 		}
 	case catMixed:
+		if desc.streamContent != "" {
+			v.FieldByIndex(desc.body.index).Set(reflect.ValueOf(StreamBody{
+				Reader:      r.Body,
+				ContentType: r.Header.Get("Content-Type"),
+				Length:      r.ContentLength,
+			}))
+			break
+		}
 		bodyPtr := v.FieldByIndex(desc.body.index).Addr().Interface()
 		if err := decodeBody(r, bodyPtr, codecs); err != nil {
 			return nil, fmt.Errorf("%w: %w", ErrBindBody, err)
 		}
 	case catForm:
-		if err := bindFormFields(v, r, desc); err != nil {
+		if err := bindFormFields(v, r, desc, codecs); err != nil {
 			return nil, err
 		}
 	}
@@ -57,37 +69,76 @@ func decodeRequest[Req any](r *http.Request, codecs *codecRegistry, desc *reques
 	return req, nil
 }
 
-// bindParams binds path/query/header/cookie values and injects RawRequest
-// using the descriptor's cached field index paths.
-func bindParams(v reflect.Value, r *http.Request, desc *requestDescriptor) error {
+// bindParams binds path/query/header/cookie values, claim and session
+// values, and injects RawRequest using the descriptor's cached field index
+// paths.
+func bindParams(v reflect.Value, r *http.Request, desc *requestDescriptor, sc *SecureCookies) error {
 	if desc.rawRequest != nil {
 		v.FieldByIndex(desc.rawRequest.index).Set(reflect.ValueOf(RawRequest{Request: r}))
 	}
 
 	for _, p := range desc.params {
-		var val string
+		var (
+			val     string
+			present bool
+		)
 		switch p.in {
 		case paramInPath:
 			val = r.PathValue(p.name)
+			if p.pattern != nil && !p.pattern.MatchString(val) {
+				return fmt.Errorf("%w: %s: %q does not match pattern %s", ErrBindPath, p.name, val, p.pattern)
+			}
 		case paramInQuery:
-			val = r.URL.Query().Get(p.name)
+			if p.multi {
+				if err := bindQuerySlice(v.FieldByIndex(p.index), r, p); err != nil {
+					return fmt.Errorf("%w: %s: %w", ErrBindQuery, p.name, err)
+				}
+				continue
+			}
+			if p.deep {
+				q := r.URL.Query()
+				name, _ := deepObjectName(q, p)
+				if _, err := bindDeepObject(v.FieldByIndex(p.index), q, []string{name}); err != nil {
+					return fmt.Errorf("%w: %w", ErrBindQuery, err)
+				}
+				continue
+			}
+			q := queryValues(r, p)
+			val, present = q.Get(p.name), q.Has(p.name)
 			if val == "" {
 				val = p.defaultValue
 			}
 		case paramInHeader:
-			val = r.Header.Get(p.name)
+			val, present = r.Header.Get(p.name), len(r.Header.Values(p.name)) > 0
 			if val == "" {
 				val = p.defaultValue
 			}
 		case paramInCookie:
-			if c, err := r.Cookie(p.name); err == nil {
-				val = c.Value
+			if err := bindCookieParam(v.FieldByIndex(p.index), r, p, sc); err != nil {
+				return fmt.Errorf("%w: %s: %w", ErrBindCookie, p.name, err)
 			}
-			if val == "" {
-				val = p.defaultValue
+			continue
+		case paramInClaim, paramInSession:
+			if err := bindContextParam(v.FieldByIndex(p.index), r, p); err != nil {
+				var apiErr *Err
+				if errors.As(err, &apiErr) {
+					return err
+				}
+				return fmt.Errorf("%w: %s: %w", bindErrFor(p.in), p.name, err)
 			}
+			continue
 		}
 		if val == "" {
+			if present {
+				bindEmptyPointer(v.FieldByIndex(p.index))
+			}
+			continue
+		}
+		if p.cursor {
+			binder := v.FieldByIndex(p.index).Addr().Interface().(cursorBinder) //nolint:errcheck,forcetypeassert // descriptor guarantees Cursor
+			if err := binder.bindCursor(r.Context(), val); err != nil {
+				return fmt.Errorf("%w: %s: %w", bindErrFor(p.in), p.name, err)
+			}
 			continue
 		}
 		if err := setFieldValue(v.FieldByIndex(p.index), val); err != nil {
@@ -95,9 +146,168 @@ func bindParams(v reflect.Value, r *http.Request, desc *requestDescriptor) error
 		}
 	}
 
+	for _, tr := range desc.timeRanges {
+		field := v.FieldByIndex(tr.index).Addr().Interface().(*TimeRange) //nolint:errcheck,forcetypeassert // descriptor guarantees TimeRange
+		if err := tr.resolve(field, time.Now()); err != nil {
+			return fmt.Errorf("%w: %w", ErrBindQuery, err)
+		}
+	}
+
+	return nil
+}
+
+// missingParams returns a 400 listing the required query, header, and
+// cookie params absent from r, or nil when all are present. A param sent
+// with an empty value is present.
+func missingParams(r *http.Request, desc *requestDescriptor) error {
+	if desc == nil {
+		return nil
+	}
+	var (
+		missing []string
+		opts    []ErrorOption
+	)
+	for _, p := range desc.params {
+		if !p.required {
+			continue
+		}
+		var present bool
+		//exhaustive:ignore
+		switch p.in {
+		case paramInQuery:
+			if p.deep {
+				_, present = deepObjectName(r.URL.Query(), p)
+			} else {
+				present = queryValues(r, p).Has(p.name)
+			}
+		case paramInHeader:
+			present = len(r.Header.Values(p.name)) > 0
+		case paramInCookie:
+			_, err := r.Cookie(p.name)
+			present = err == nil
+		default:
+			continue
+		}
+		if !present {
+			missing = append(missing, p.in.String()+" "+strconv.Quote(p.name))
+			opts = append(opts, WithDetail(ValidationError{Field: p.name, Message: "missing required " + p.in.String() + " param"}))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	opts = append(opts, WithMessage("missing required params: "+strings.Join(missing, ", ")))
+	return Error(CodeBadRequest, opts...)
+}
+
+// bindQuerySlice binds every value of a repeated query parameter into a
+// slice field. With explode disabled, each value is split on commas, so
+// both ?tag=a,b and ?tag=a&tag=b yield [a b]. The default tag is always
+// comma-separated.
+func bindQuerySlice(field reflect.Value, r *http.Request, p requestParamDesc) error {
+	vals := queryValues(r, p)[p.name]
+	if !p.explode {
+		var split []string
+		for _, v := range vals {
+			split = append(split, strings.Split(v, ",")...)
+		}
+		vals = split
+	}
+	if len(vals) == 0 {
+		if p.defaultValue == "" {
+			return nil
+		}
+		vals = strings.Split(p.defaultValue, ",")
+	}
+
+	out := reflect.MakeSlice(field.Type(), len(vals), len(vals))
+	for i, v := range vals {
+		if err := setFieldValue(out.Index(i), v); err != nil {
+			return err
+		}
+	}
+	field.Set(out)
 	return nil
 }
 
+// queryValues returns the request's query values with p's current name
+// filled from the first alias present when the current name is absent.
+func queryValues(r *http.Request, p requestParamDesc) url.Values {
+	q := r.URL.Query()
+	if len(p.aliases) == 0 || q.Has(p.name) {
+		return q
+	}
+	for _, alias := range p.aliases {
+		if q.Has(alias) {
+			q[p.name] = q[alias]
+			break
+		}
+	}
+	return q
+}
+
+// isMultiValueType reports whether a param of type t binds from repeated
+// values: any slice other than []byte or a type that parses itself.
+func isMultiValueType(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice || t.Elem().Kind() == reflect.Uint8 {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(reflect.TypeFor[encoding.TextUnmarshaler]())
+}
+
+// bindCookieParam binds a single cookie parameter, decoding secure values
+// and populating CookieParam metadata when the field uses that type.
+func bindCookieParam(field reflect.Value, r *http.Request, p requestParamDesc, sc *SecureCookies) error {
+	c, err := r.Cookie(p.name)
+	if err != nil {
+		c = nil
+	}
+
+	val := ""
+	if c != nil {
+		val = c.Value
+		if p.secure && val != "" {
+			decoded, err := sc.Decode(p.name, val)
+			if err != nil {
+				return err
+			}
+			val = decoded
+		}
+	}
+
+	if p.cookieParam {
+		if c == nil {
+			// Defaults fill Value but leave Present false.
+			if p.defaultValue == "" {
+				return nil
+			}
+			return setFieldValue(field.FieldByName("Value"), p.defaultValue)
+		}
+		binder := field.Addr().Interface().(cookieBinder) //nolint:errcheck,forcetypeassert // descriptor guarantees CookieParam
+		return binder.bindCookie(c, val)
+	}
+
+	if val == "" {
+		val = p.defaultValue
+	}
+	if val == "" {
+		if c != nil {
+			bindEmptyPointer(field)
+		}
+		return nil
+	}
+	return setFieldValue(field, val)
+}
+
+// bindEmptyPointer binds a param that is present with an empty value. A
+// *string field gets a pointer to "", so handlers can tell ?name= from an
+// absent name; other fields, which cannot parse "", are left unset.
+func bindEmptyPointer(field reflect.Value) {
+	if field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.String {
+		field.Set(reflect.New(field.Type().Elem()))
+	}
+}
+
 // bindErrFor returns the sentinel bind error for a parameter source.
 func bindErrFor(in paramIn) error {
 	switch in {
@@ -109,13 +319,17 @@ func bindErrFor(in paramIn) error {
 		return ErrBindHeader
 	case paramInCookie:
 		return ErrBindCookie
+	case paramInClaim:
+		return ErrBindClaim
+	case paramInSession:
+		return ErrBindSession
 	}
 	return ErrBindPath
 }
 
 // bindFormFields binds multipart form fields and files using the
 // descriptor's cached form field map.
-func bindFormFields(v reflect.Value, r *http.Request, desc *requestDescriptor) error {
+func bindFormFields(v reflect.Value, r *http.Request, desc *requestDescriptor, codecs *codecRegistry) error {
 	if err := r.ParseMultipartForm(maxMultipartMemory); err != nil {
 		return fmt.Errorf("%w: %w", ErrBindForm, err)
 	}
@@ -159,6 +373,11 @@ func bindFormFields(v reflect.Value, r *http.Request, desc *requestDescriptor) e
 			}
 			field.Set(reflect.ValueOf(uploads))
 
+		case formCodecPart:
+			if err := bindCodecPart(field, r, ff, codecs); err != nil {
+				return fmt.Errorf("%w: %s: %w", ErrBindForm, ff.name, err)
+			}
+
 		case formScalar:
 			val := r.FormValue(ff.name)
 			if val == "" {
@@ -173,8 +392,58 @@ func bindFormFields(v reflect.Value, r *http.Request, desc *requestDescriptor) e
 	return nil
 }
 
-// setFieldValue sets a reflect.Value from a string, supporting common types.
+// bindCodecPart decodes a structured multipart part (e.g. a JSON metadata
+// document) into field using the decoder registered for the part's declared
+// content type. The part may arrive as a plain value or as a file part.
+func bindCodecPart(field reflect.Value, r *http.Request, ff requestFormDesc, codecs *codecRegistry) error {
+	dec, ok := codecs.decoderFor(ff.contentType)
+	if !ok {
+		return fmt.Errorf("no decoder for content type %s", ff.contentType)
+	}
+
+	var src io.Reader
+	switch {
+	case len(r.MultipartForm.Value[ff.name]) > 0:
+		src = strings.NewReader(r.MultipartForm.Value[ff.name][0])
+	case len(r.MultipartForm.File[ff.name]) > 0:
+		f, err := r.MultipartForm.File[ff.name][0].Open()
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint:errcheck // read-only part
+		src = f
+	default:
+		return nil
+	}
+
+	return dec.Decode(src, field.Addr().Interface())
+}
+
+// setFieldValue sets a reflect.Value from a string, supporting common types,
+// pointers to them, and types implementing encoding.TextUnmarshaler (e.g.
+// big.Int, big.Float, and decimal types).
 func setFieldValue(field reflect.Value, value string) error {
+	if field.Type() == reflect.PointerTo(locationType) {
+		loc, err := loadLocation(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(loc))
+		return nil
+	}
+	if field.Kind() == reflect.Pointer {
+		elem := reflect.New(field.Type().Elem())
+		if err := setFieldValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
 	if field.Type() == reflect.TypeFor[time.Duration]() {
 		d, err := time.ParseDuration(value)
 		if err != nil {
@@ -188,16 +457,22 @@ func setFieldValue(field reflect.Value, value string) error {
 	switch field.Kind() {
 	case reflect.String:
 		field.SetString(value)
-	case reflect.Int, reflect.Int64:
-		n, err := strconv.ParseInt(value, 10, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
 		if err != nil {
-			return err
+			return numError(err, value, field.Type())
 		}
 		field.SetInt(n)
-	case reflect.Float64:
-		n, err := strconv.ParseFloat(value, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
 		if err != nil {
-			return err
+			return numError(err, value, field.Type())
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return numError(err, value, field.Type())
 		}
 		field.SetFloat(n)
 	case reflect.Bool:
@@ -212,6 +487,15 @@ func setFieldValue(field reflect.Value, value string) error {
 	return nil
 }
 
+// numError rewords a strconv failure for a field of type t, so clients see
+// "300 is out of range for uint8" rather than strconv's internals.
+func numError(err error, value string, t reflect.Type) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("%s is out of range for %s", value, t)
+	}
+	return fmt.Errorf("%q is not a valid %s", value, t)
+}
+
 // decodeBody decodes the request body using the codec matched by Content-Type.
 func decodeBody(r *http.Request, target any, codecs *codecRegistry) error {
 	if r.Body == nil || r.ContentLength == 0 {
@@ -645,27 +645,265 @@ func TestRequest_setFieldValue_unsupported_type(t *testing.T) {
 	t.Parallel()
 
 	type Req struct {
-		Data uint `query:"data"`
+		Data complex64 `query:"data"`
 	}
 
+	// complex64 is not supported by setFieldValue, so registration fails
+	// instead of every request getting a 400.
+	assert.Panics(t, func() {
+		api.Get(api.New(), "/unsupported", func(_ context.Context, _ *Req) (*api.Void, error) {
+			return &api.Void{}, nil
+		})
+	})
+}
+
+func TestRequest_setFieldValue_widths(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		I8  int8    `query:"i8"`
+		I16 int16   `query:"i16"`
+		I32 int32   `query:"i32"`
+		U   uint    `query:"u"`
+		U8  uint8   `query:"u8"`
+		U16 uint16  `query:"u16"`
+		U32 uint32  `query:"u32"`
+		U64 uint64  `query:"u64"`
+		F32 float32 `query:"f32"`
+		Ptr *uint16 `header:"X-Port"`
+	}
+
+	var got Req
 	r := api.New()
-	api.Get(r, "/unsupported", func(_ context.Context, _ *Req) (*api.Void, error) {
+	api.Get(r, "/widths", func(_ context.Context, req *Req) (*api.Void, error) {
+		got = *req
 		return &api.Void{}, nil
 	})
 
-	srv := httptest.NewServer(r)
-	t.Cleanup(srv.Close)
+	req := httptest.NewRequest(http.MethodGet,
+		"/widths?i8=-128&i16=32767&i32=-2147483648&u=7&u8=255&u16=65535&u32=4294967295&u64=18446744073709551615&f32=1.5", http.NoBody)
+	req.Header.Set("X-Port", "8080")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	port := uint16(8080)
+	assert.Equal(t, Req{
+		I8: -128, I16: 32767, I32: -2147483648,
+		U: 7, U8: 255, U16: 65535, U32: 4294967295, U64: 18446744073709551615,
+		F32: 1.5, Ptr: &port,
+	}, got)
+}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet,
-		srv.URL+"/unsupported?data=42", nil)
-	require.NoError(t, err)
+func TestRequest_pointer_params_presence(t *testing.T) {
+	t.Parallel()
 
-	resp, err := http.DefaultClient.Do(req)
-	require.NoError(t, err)
-	defer func() { require.NoError(t, resp.Body.Close()) }()
+	type Req struct {
+		Name   *string `query:"name"`
+		Limit  *int    `query:"limit"`
+		Active *bool   `query:"active"`
+		Tenant *string `header:"X-Tenant"`
+		Theme  *string `cookie:"theme"`
+	}
 
-	// uint is not supported by setFieldValue, should get 400.
-	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	ptr := func(s string) *string { return &s }
+	limit := 0
+	active := false
+
+	tests := map[string]struct {
+		query  string
+		header map[string]string
+		cookie *http.Cookie
+		want   Req
+	}{
+		"absent": {},
+		"zero values": {
+			query: "?name=&limit=0&active=false",
+			want:  Req{Name: ptr(""), Limit: &limit, Active: &active},
+		},
+		"empty non-string values stay absent": {
+			query: "?limit=&active=",
+		},
+		"empty header and cookie": {
+			header: map[string]string{"X-Tenant": ""},
+			cookie: &http.Cookie{Name: "theme", Value: ""},
+			want:   Req{Tenant: ptr(""), Theme: ptr("")},
+		},
+		"values": {
+			query:  "?name=ann",
+			header: map[string]string{"X-Tenant": "acme"},
+			cookie: &http.Cookie{Name: "theme", Value: "dark"},
+			want:   Req{Name: ptr("ann"), Tenant: ptr("acme"), Theme: ptr("dark")},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got Req
+			r := api.New()
+			api.Get(r, "/items", func(_ context.Context, req *Req) (*api.Void, error) {
+				got = *req
+				return &api.Void{}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/items"+tt.query, http.NoBody)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRequest_required_params(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Page    int    `query:"page" required:"true"`
+		Tenant  string `header:"X-Tenant" required:"true"`
+		Session string `cookie:"session" required:"true"`
+		Sort    string `query:"sort"`
+	}
+
+	tests := map[string]struct {
+		opts        []api.RouterOption
+		query       string
+		header      bool
+		cookie      bool
+		wantStatus  int
+		wantMissing []string
+	}{
+		"not enforced by default": {
+			wantStatus: http.StatusNoContent,
+		},
+		"all present": {
+			opts:       []api.RouterOption{api.WithRequiredParams()},
+			query:      "?page=1",
+			header:     true,
+			cookie:     true,
+			wantStatus: http.StatusNoContent,
+		},
+		"empty value counts as present": {
+			opts:       []api.RouterOption{api.WithRequiredParams()},
+			query:      "?page=",
+			header:     true,
+			cookie:     true,
+			wantStatus: http.StatusNoContent,
+		},
+		"all missing": {
+			opts:        []api.RouterOption{api.WithRequiredParams()},
+			wantStatus:  http.StatusBadRequest,
+			wantMissing: []string{"page", "X-Tenant", "session"},
+		},
+		"one missing": {
+			opts:        []api.RouterOption{api.WithRequiredParams()},
+			query:       "?page=1",
+			cookie:      true,
+			wantStatus:  http.StatusBadRequest,
+			wantMissing: []string{"X-Tenant"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New(tt.opts...)
+			api.Get(r, "/items", func(_ context.Context, _ *Req) (*api.Void, error) {
+				return &api.Void{}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/items"+tt.query, http.NoBody)
+			if tt.header {
+				req.Header.Set("X-Tenant", "acme")
+			}
+			if tt.cookie {
+				req.AddCookie(&http.Cookie{Name: "session", Value: "s"})
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantMissing == nil {
+				return
+			}
+			var pd struct {
+				Errors []api.ValidationError `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pd))
+			var missing []string
+			for _, e := range pd.Errors {
+				missing = append(missing, e.Field)
+			}
+			assert.ElementsMatch(t, tt.wantMissing, missing)
+		})
+	}
+}
+
+func TestRequest_pointer_params_spec(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Limit *int `query:"limit"`
+	}
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *Req) (*api.Void, error) { return nil, nil })
+
+	params := r.Spec().Paths["/items"]["get"].Parameters
+	require.Len(t, params, 1)
+	assert.False(t, params[0].Required)
+	assert.Equal(t, "integer", params[0].Schema.Type)
+}
+
+func TestRequest_setFieldValue_overflow(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		I8  int8    `query:"i8"`
+		U8  uint8   `query:"u8"`
+		U   uint    `query:"u"`
+		F32 float32 `query:"f32"`
+	}
+
+	r := api.New()
+	api.Get(r, "/n", func(_ context.Context, _ *Req) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	tests := map[string]struct {
+		query string
+		want  string
+	}{
+		"int8 too small":    {query: "i8=-129", want: "-129 is out of range for int8"},
+		"uint8 too large":   {query: "u8=300", want: "300 is out of range for uint8"},
+		"negative uint":     {query: "u=-1", want: `"-1" is not a valid uint`},
+		"float32 too large": {query: "f32=1e40", want: "1e40 is out of range for float32"},
+		"not a number":      {query: "u8=ten", want: `"ten" is not a valid uint8`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/n?"+tc.query, http.NoBody))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var pd api.ProblemDetails
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pd))
+			assert.Contains(t, pd.Detail, tc.want)
+		})
+	}
 }
 
 func TestRequest_params_only_no_body(t *testing.T) {
@@ -794,6 +1032,94 @@ func TestRequest_path_binding_error(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 }
 
+func TestRequest_path_pattern(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		ID   string `path:"id" pattern:"^[0-9]+$"`
+		Page int    `path:"page"`
+	}
+
+	tests := map[string]struct {
+		opts       []api.RouterOption
+		path       string
+		wantStatus int
+		wantDetail string
+	}{
+		"match": {
+			path:       "/items/42/1",
+			wantStatus: http.StatusNoContent,
+		},
+		"pattern mismatch": {
+			path:       "/items/abc/1",
+			wantStatus: http.StatusBadRequest,
+			wantDetail: `"abc" does not match pattern ^[0-9]+$`,
+		},
+		"pattern mismatch as not found": {
+			opts:       []api.RouterOption{api.WithPathMismatch(api.CodeNotFound)},
+			path:       "/items/abc/1",
+			wantStatus: http.StatusNotFound,
+		},
+		"type mismatch as not found": {
+			opts:       []api.RouterOption{api.WithPathMismatch(api.CodeNotFound)},
+			path:       "/items/42/first",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			called := false
+			r := api.New(tt.opts...)
+			api.Get(r, "/items/{id}/{page}", func(_ context.Context, _ *Req) (*api.Void, error) {
+				called = true
+				return &api.Void{}, nil
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, http.NoBody))
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.Equal(t, tt.wantStatus == http.StatusNoContent, called)
+			if tt.wantDetail != "" {
+				var pd api.ProblemDetails
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pd))
+				assert.Contains(t, pd.Detail, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestRequest_path_pattern_spec(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		ID string `path:"id" pattern:"^[0-9]+$"`
+	}
+
+	r := api.New()
+	api.Get(r, "/items/{id}", func(_ context.Context, _ *Req) (*api.Void, error) { return nil, nil })
+
+	params := r.Spec().Paths["/items/{id}"]["get"].Parameters
+	require.Len(t, params, 1)
+	assert.Equal(t, "^[0-9]+$", params[0].Schema.Pattern)
+}
+
+func TestRequest_path_pattern_invalid(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		ID string `path:"id" pattern:"[0-9"`
+	}
+
+	err := registrationPanic(t, func() {
+		api.Get(api.New(), "/items/{id}", func(_ context.Context, _ *Req) (*api.Void, error) { return nil, nil })
+	})
+	assert.Contains(t, err.Problems[0].Message, `invalid pattern tag on path param "id"`)
+}
+
 func TestRequest_header_binding_error(t *testing.T) {
 	t.Parallel()
 
@@ -1024,3 +1350,192 @@ func TestRequest_duplicate_param_fails_at_registration(t *testing.T) {
 		})
 	})
 }
+
+func TestRequest_query_slice_binding(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Tags  []string `query:"tag"`
+		IDs   []int    `query:"id" explode:"false"`
+		Sizes []string `query:"size" default:"s,m"`
+	}
+	type Resp struct {
+		Tags  []string `json:"tags"`
+		IDs   []int    `json:"ids"`
+		Sizes []string `json:"sizes"`
+	}
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, req *Req) (*api.Resp[Resp], error) {
+		return &api.Resp[Resp]{Body: Resp{Tags: req.Tags, IDs: req.IDs, Sizes: req.Sizes}}, nil
+	})
+
+	tests := map[string]struct {
+		query    string
+		wantCode int
+		want     string
+	}{
+		"repeated keys": {
+			query:    "?tag=a&tag=b,c",
+			wantCode: http.StatusOK,
+			want:     `{"tags":["a","b,c"],"ids":null,"sizes":["s","m"]}`,
+		},
+		"comma separated": {
+			query:    "?id=1,2&id=3&size=xl",
+			wantCode: http.StatusOK,
+			want:     `{"tags":null,"ids":[1,2,3],"sizes":["xl"]}`,
+		},
+		"invalid element": {
+			query:    "?id=1,x",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items"+tt.query, nil))
+
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.want != "" {
+				assert.JSONEq(t, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequest_query_alias(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Search string   `query:"q,alias=search,alias=term" default:"all"`
+		Tags   []string `query:"tag,alias=label"`
+	}
+	type Resp struct {
+		Search string   `json:"search"`
+		Tags   []string `json:"tags"`
+	}
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, req *Req) (*api.Resp[Resp], error) {
+		return &api.Resp[Resp]{Body: Resp{Search: req.Search, Tags: req.Tags}}, nil
+	})
+
+	tests := map[string]struct {
+		query      string
+		wantSearch string
+		wantTags   []string
+	}{
+		"current name":        {query: "?q=new&tag=a", wantSearch: "new", wantTags: []string{"a"}},
+		"alias":               {query: "?search=old&label=a&label=b", wantSearch: "old", wantTags: []string{"a", "b"}},
+		"second alias":        {query: "?term=older", wantSearch: "older"},
+		"current name wins":   {query: "?search=old&q=new&label=x&tag=y", wantSearch: "new", wantTags: []string{"y"}},
+		"default when absent": {query: "", wantSearch: "all"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/items"+tc.query, nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var got Resp
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tc.wantSearch, got.Search)
+			assert.Equal(t, tc.wantTags, got.Tags)
+		})
+	}
+}
+
+func TestRequest_query_alias_invalid(t *testing.T) {
+	t.Parallel()
+
+	type headerAlias struct {
+		Token string `header:"X-Token,alias=X-Auth"`
+	}
+	type dupAlias struct {
+		A string `query:"a"`
+		B string `query:"b,alias=a"`
+	}
+
+	assert.Panics(t, func() {
+		api.Get(api.New(), "/h", func(_ context.Context, _ *headerAlias) (*api.Void, error) { return nil, nil })
+	})
+	assert.Panics(t, func() {
+		api.Get(api.New(), "/d", func(_ context.Context, _ *dupAlias) (*api.Void, error) { return nil, nil })
+	})
+}
+
+type batchItem struct {
+	Name string `json:"name" minLength:"1"`
+	Qty  int    `json:"qty" minimum:"1"`
+}
+
+func TestRequest_array_and_map_body(t *testing.T) {
+	t.Parallel()
+
+	type BatchReq struct {
+		DryRun bool        `query:"dry_run"`
+		Body   []batchItem `maxItems:"2"`
+	}
+	type MapReq struct {
+		Body map[string]*batchItem `minItems:"1" maxItems:"2"`
+	}
+
+	r := api.New()
+	api.Post(r, "/batch", func(_ context.Context, req *BatchReq) (*api.Resp[[]batchItem], error) {
+		return &api.Resp[[]batchItem]{Body: req.Body}, nil
+	})
+	api.Post(r, "/named", func(_ context.Context, req *MapReq) (*api.Resp[map[string]*batchItem], error) {
+		return &api.Resp[map[string]*batchItem]{Body: req.Body}, nil
+	})
+
+	tests := map[string]struct {
+		path       string
+		body       string
+		wantStatus int
+		wantFields []string
+	}{
+		"array":                {path: "/batch", body: `[{"name":"a","qty":1},{"name":"b","qty":2}]`, wantStatus: http.StatusOK},
+		"array element errors": {path: "/batch", body: `[{"name":"a","qty":1},{"name":"","qty":0}]`, wantStatus: http.StatusUnprocessableEntity, wantFields: []string{"body[1].name", "body[1].qty"}},
+		"array too long":       {path: "/batch", body: `[{"name":"a","qty":1},{"name":"b","qty":1},{"name":"c","qty":1}]`, wantStatus: http.StatusUnprocessableEntity, wantFields: []string{"body"}},
+		"object for array":     {path: "/batch", body: `{"name":"a"}`, wantStatus: http.StatusBadRequest},
+		"map":                  {path: "/named", body: `{"x":{"name":"a","qty":1},"y":null}`, wantStatus: http.StatusOK},
+		"map element errors":   {path: "/named", body: `{"x":{"name":"a","qty":0}}`, wantStatus: http.StatusUnprocessableEntity, wantFields: []string{"body.x.qty"}},
+		"map empty":            {path: "/named", body: `{}`, wantStatus: http.StatusUnprocessableEntity, wantFields: []string{"body"}},
+		"map too large":        {path: "/named", body: `{"x":null,"y":null,"z":null}`, wantStatus: http.StatusUnprocessableEntity, wantFields: []string{"body"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.wantStatus, rec.Code, rec.Body.String())
+			if tc.wantStatus == http.StatusOK {
+				assert.JSONEq(t, tc.body, rec.Body.String())
+				return
+			}
+			if tc.wantFields == nil {
+				return
+			}
+			var pd struct {
+				Errors []api.ValidationError `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pd))
+			fields := make([]string, len(pd.Errors))
+			for i, e := range pd.Errors {
+				fields[i] = e.Field
+			}
+			assert.Equal(t, tc.wantFields, fields)
+		})
+	}
+}
@@ -32,7 +32,7 @@ func RequestID(cfg ...RequestIDConfig) Middleware {
 		}
 	}
 
-	return func(next http.Handler) http.Handler {
+	return Named("requestid", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			id := r.Header.Get(c.Header)
 			if id == "" {
@@ -43,7 +43,7 @@ func RequestID(cfg ...RequestIDConfig) Middleware {
 			w.Header().Set(c.Header, id)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
-	}
+	})
 }
 
 // GetRequestID extracts the request ID from the request context.
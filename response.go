@@ -17,7 +17,10 @@ import (
 //
 //	func(...) (*api.Resp[User], error)           // JSON/XML body
 //	func(...) (*api.Resp[io.Reader], error)      // streamed body
+//	func(...) (*api.Resp[api.File], error)       // file download
 //	func(...) (*api.Resp[<-chan api.Event], error) // SSE body
+//	func(...) (*api.Resp[api.JSONArrayStream[User]], error) // streamed JSON array
+//	func(...) (*api.Resp[api.NDJSONStream[User]], error) // streamed NDJSON
 //
 // For responses that also carry status, headers, or cookies, declare
 // a custom response struct with tagged fields plus a Body field.
@@ -28,33 +31,31 @@ type Resp[T any] struct {
 // encodeResponse writes a non-error handler response to w using the
 // route's precomputed descriptor. It applies cookies, headers, resolves
 // status, and dispatches the body by kind.
-func encodeResponse(
-	w http.ResponseWriter,
-	r *http.Request,
-	resp any,
-	desc *responseDescriptor,
-	defaultStatus int,
-	codecs *codecRegistry,
-) {
+func encodeResponse(w http.ResponseWriter, r *http.Request, resp any, cfg *handlerConfig) {
+	desc := cfg.responseDesc
 	rv := reflect.ValueOf(resp)
 	if rv.Kind() == reflect.Pointer {
 		rv = rv.Elem()
 	}
 
-	status := defaultStatus
+	status := cfg.defaultStatus
 	if desc.status != nil {
 		if s := intFieldValue(rv.FieldByIndex(desc.status.index)); s != 0 {
 			status = s
 		}
 	}
 
+	if l, ok := resp.(interface{ setLink(r *http.Request) }); ok {
+		l.setLink(r)
+	}
+
 	for _, ck := range desc.cookies {
 		fv := rv.FieldByIndex(ck.index)
 		c, ok := fv.Interface().(Cookie)
 		if !ok || c.IsZero() {
 			continue
 		}
-		http.SetCookie(w, c.ToHTTPCookie(ck.name))
+		writeCookie(w, ck.name, c, cfg.cookieDefaults)
 	}
 
 	for _, h := range desc.headers {
@@ -83,11 +84,21 @@ func encodeResponse(
 
 	switch desc.body.kind {
 	case bodyKindCodec:
-		writeCodecBody(w, r, bv, status, codecs)
+		writeCodecBody(w, r, bv, status, cfg)
 	case bodyKindReader:
 		writeReaderBody(w, r, bv, status)
 	case bodyKindChan:
-		writeChanBody(r.Context(), w, bv, status)
+		writeChanBody(r.Context(), w, bv, status, cfg)
+	case bodyKindJSONArray:
+		writeJSONArrayBody(r.Context(), w, bv, status, cfg)
+	case bodyKindNDJSON:
+		writeNDJSONBody(r.Context(), w, bv, status, cfg)
+	case bodyKindSeq:
+		writeSeqBody(w, r, bv, status, cfg)
+	case bodyKindEventSeq:
+		writeEventSeqBody(w, r, bv, status, cfg)
+	case bodyKindFile:
+		writeFileBody(w, r, bv, cfg)
 	}
 
 	writeTrailers(w, rv, desc.trailers)
@@ -115,10 +126,30 @@ func isNoBodyStatus(status int) bool {
 	return (status >= 100 && status < 200) || status == http.StatusNoContent || status == http.StatusNotModified
 }
 
-// writeCodecBody encodes a value via the negotiated response codec.
-func writeCodecBody(w http.ResponseWriter, r *http.Request, bv reflect.Value, status int, codecs *codecRegistry) {
-	enc, _ := codecs.negotiate(r.Header.Get("Accept"))
-	w.Header().Set("Content-Type", enc.ContentType())
+// writeCodecBody encodes a value via the negotiated response codec. Fields
+// tagged redact or scope are filtered first when a policy is configured.
+func writeCodecBody(w http.ResponseWriter, r *http.Request, bv reflect.Value, status int, cfg *handlerConfig) {
+	if ff, ok := newFieldFilter(r.Context(), cfg.redaction, cfg.fieldScopes); ok && typeHasFieldPolicy(bv.Type()) {
+		bv = filterFields(bv, ff)
+	}
+
+	// A OneOf encodes as its member, through any codec.
+	if cfg.responseDesc.body.oneOf {
+		if o, ok := bv.Interface().(oneOf); ok {
+			if m := o.oneOfValue(); m != nil {
+				bv = reflect.ValueOf(m)
+			}
+		}
+	}
+
+	enc, _ := cfg.codecs.negotiate(r.Header.Get("Accept"))
+	enc = cfg.codecs.protection.wrapEncoder(enc, bv.Type())
+	cfg.codecs.protection.setNoSniff(w.Header())
+	if cfg.contentEncoding != nil {
+		cfg.contentEncoding.write(w, r, enc, cfg.codecs.contentType(enc.ContentType()), bv.Interface(), status)
+		return
+	}
+	w.Header().Set("Content-Type", cfg.codecs.contentType(enc.ContentType()))
 	w.WriteHeader(status)
 	//nolint:errcheck,gosec // best-effort after WriteHeader
 	enc.Encode(w, bv.Interface())
@@ -132,14 +163,20 @@ func writeCodecBody(w http.ResponseWriter, r *http.Request, bv reflect.Value, st
 // When ServeContent is used, the response status is owned by ServeContent
 // (200 OK or 206 Partial Content) — a Status field on the response struct is
 // ignored for ReadSeeker bodies.
+//
+// ETag and Last-Modified response headers act as the validators for
+// If-Range: a Range request whose If-Range matches gets 206, otherwise the
+// full 200. A reader that is not seekable but implements io.ReaderAt is
+// served the same way when the response sets Content-Length.
 func writeReaderBody(w http.ResponseWriter, r *http.Request, bv reflect.Value, status int) {
 	if bv.IsNil() {
 		w.WriteHeader(status)
 		return
 	}
 	reader := bv.Interface().(io.Reader) //nolint:errcheck,forcetypeassert // descriptor guarantees io.Reader
-	if rs, ok := reader.(io.ReadSeeker); ok {
-		http.ServeContent(w, r, "", time.Time{}, rs)
+	if rs, ok := seekableBody(reader, w.Header()); ok {
+		modtime, _ := http.ParseTime(w.Header().Get("Last-Modified")) //nolint:errcheck // zero time disables date validators
+		http.ServeContent(w, r, "", modtime, rs)
 		return
 	}
 	w.WriteHeader(status)
@@ -147,35 +184,51 @@ func writeReaderBody(w http.ResponseWriter, r *http.Request, bv reflect.Value, s
 	io.Copy(w, reader)
 }
 
+// seekableBody returns reader as an io.ReadSeeker when it supports random
+// access, either directly or as an io.ReaderAt with a known Content-Length.
+func seekableBody(reader io.Reader, h http.Header) (io.ReadSeeker, bool) {
+	if rs, ok := reader.(io.ReadSeeker); ok {
+		return rs, true
+	}
+	ra, ok := reader.(io.ReaderAt)
+	if !ok {
+		return nil, false
+	}
+	size, err := strconv.ParseInt(h.Get("Content-Length"), 10, 64)
+	if err != nil || size < 0 {
+		return nil, false
+	}
+	return io.NewSectionReader(ra, 0, size), true
+}
+
 // writeChanBody consumes events from a channel and emits them as SSE. It
 // exits when the channel closes or the request context is cancelled.
-func writeChanBody(ctx context.Context, w http.ResponseWriter, bv reflect.Value, status int) {
+func writeChanBody(ctx context.Context, w http.ResponseWriter, bv reflect.Value, status int, cfg *handlerConfig) {
 	if bv.IsNil() {
 		w.WriteHeader(status)
 		return
 	}
 
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.WriteHeader(status)
+	writeEventStreamHeader(w, status)
 
-	flusher, _ := w.(http.Flusher) //nolint:errcheck // ok being false means no flushing
+	ctx, cancel := streamContext(ctx, cfg.router)
+	defer cancel()
+	ew := newEventWriter(w, cfg)
+	defer ew.close()
 
 	for {
 		chosen, recv, ok := reflect.Select([]reflect.SelectCase{
 			{Dir: reflect.SelectRecv, Chan: bv},
 			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
 		})
-		if chosen == 1 || !ok {
+		if chosen == 1 {
+			ew.end(ctx)
 			return
 		}
-		ev := recv.Interface().(Event) //nolint:errcheck,forcetypeassert // descriptor guarantees chan Event
-		//nolint:errcheck,gosec // best-effort SSE write
-		writeEvent(w, ev)
-		if flusher != nil {
-			flusher.Flush()
+		if !ok {
+			return
 		}
+		ew.send(recv.Interface().(Event)) //nolint:errcheck,forcetypeassert // descriptor guarantees chan Event
 	}
 }
 
@@ -251,6 +304,30 @@ func mergeErr(template, inline *Err) *Err {
 		final.cause = template.cause
 	}
 
+	if inline.typeURI != "" {
+		final.typeURI = inline.typeURI
+	} else if template != nil {
+		final.typeURI = template.typeURI
+	}
+
+	if inline.title != "" {
+		final.title = inline.title
+	} else if template != nil {
+		final.title = template.title
+	}
+
+	if inline.titles != nil {
+		final.titles = inline.titles
+	} else if template != nil {
+		final.titles = template.titles
+	}
+
+	if inline.retryable != nil {
+		final.retryable, final.retryAfter = inline.retryable, inline.retryAfter
+	} else if template != nil {
+		final.retryable, final.retryAfter = template.retryable, template.retryAfter
+	}
+
 	if template != nil {
 		for name, values := range template.headers {
 			if final.headers == nil {
@@ -291,19 +368,30 @@ func mergeErr(template, inline *Err) *Err {
 //nolint:errname // internal view type, not a distinct error.
 type errInfoView struct {
 	*Err
-	instance string
+	instance       string
+	acceptLanguage string
 }
 
 func (v *errInfoView) Instance() string { return v.instance }
 
+// problem returns the type and title, with the title in the client's
+// language when the problem has one.
+func (v *errInfoView) problem() (typeURI, title string) {
+	typeURI, title = v.Err.problem()
+	if t, ok := localizedTitle(v.titles, v.acceptLanguage); ok {
+		title = t
+	}
+	return typeURI, title
+}
+
 // emitErr renders a fully-resolved *Err to the response writer. Status
 // comes from the Code. Cookies and headers are written first, then body
 // (if any) is emitted via the configured mapper.
-func emitErr(w http.ResponseWriter, r *http.Request, e *Err, codecs *codecRegistry) {
+func emitErr(w http.ResponseWriter, r *http.Request, e *Err, codecs *codecRegistry, cookieDefaults *CookieDefaults) {
 	status := e.code.HTTPStatus()
 
 	for name, c := range e.cookies {
-		http.SetCookie(w, c.ToHTTPCookie(name))
+		writeCookie(w, name, c, cookieDefaults)
 	}
 	for name, values := range e.headers {
 		for _, v := range values {
@@ -317,7 +405,10 @@ func emitErr(w http.ResponseWriter, r *http.Request, e *Err, codecs *codecRegist
 		return
 	}
 
-	info := &errInfoView{Err: e, instance: r.URL.RequestURI()}
+	if len(e.titles) > 0 {
+		w.Header().Add("Vary", "Accept-Language")
+	}
+	info := &errInfoView{Err: e, instance: r.URL.RequestURI(), acceptLanguage: r.Header.Get("Accept-Language")}
 	rv, skip := e.body.produce(r.Context(), info)
 	if skip {
 		w.WriteHeader(status)
@@ -345,13 +436,14 @@ func emitErr(w http.ResponseWriter, r *http.Request, e *Err, codecs *codecRegist
 	if !ok || enc == nil {
 		enc = codecs.defaultEncoder()
 	}
+	codecs.protection.setNoSniff(w.Header())
 	contentType := enc.ContentType()
 	// If the body value declares its own content type (e.g. ProblemDetails
 	// emits application/problem+json per RFC 9457), honor it.
 	if ct, ok := bodyVal.(interface{ ContentType() string }); ok {
 		contentType = ct.ContentType()
 	}
-	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Type", codecs.contentType(contentType))
 	w.WriteHeader(status)
 	//nolint:errcheck,gosec // best-effort after WriteHeader
 	enc.Encode(w, bodyVal)
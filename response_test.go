@@ -515,6 +515,34 @@ func TestResponse_chanBody_nil_emits_no_body(t *testing.T) {
 	assert.Empty(t, body)
 }
 
+func TestResponse_chanBody_heartbeat(t *testing.T) {
+	t.Parallel()
+
+	type eventsReq struct {
+		LastEventID string `header:"Last-Event-ID"`
+	}
+
+	r := api.New()
+	api.Get(r, "/events", func(_ context.Context, req *eventsReq) (*eventsResponse, error) {
+		ch := make(chan api.Event)
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			ch <- api.Event{ID: "8", Data: "after " + req.LastEventID}
+			close(ch)
+		}()
+		return &eventsResponse{Body: ch}, nil
+	}, api.WithHeartbeat(10*time.Millisecond))
+
+	req := httptest.NewRequest(http.MethodGet, "/events", http.NoBody)
+	req.Header.Set("Last-Event-ID", "7")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, ": ping\n\n"), body)
+	assert.True(t, strings.HasSuffix(body, "id: 8\ndata: after 7\n\n"), body)
+}
+
 // --- Status-driven body suppression ---
 
 type conditionalResp struct {
@@ -897,6 +925,87 @@ func TestResponse_io_Reader_supports_range(t *testing.T) {
 	assert.Equal(t, "456789", string(body))
 }
 
+type rangedFileResp struct {
+	ETag         string    `header:"ETag"`
+	LastModified time.Time `header:"Last-Modified"`
+	Size         int64     `header:"Content-Length"`
+	Body         io.Reader
+}
+
+// readerAtOnly hides io.Seeker so only io.ReaderAt is available.
+type readerAtOnly struct {
+	r *strings.Reader
+}
+
+func (r readerAtOnly) Read(p []byte) (int, error)              { return r.r.Read(p) }
+func (r readerAtOnly) ReadAt(p []byte, off int64) (int, error) { return r.r.ReadAt(p, off) }
+
+func TestResponse_io_Reader_if_range(t *testing.T) {
+	t.Parallel()
+
+	const payload = "0123456789ABCDEFGHIJ"
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r := api.New()
+	api.Get(r, "/file", func(_ context.Context, _ *api.Void) (*rangedFileResp, error) {
+		return &rangedFileResp{
+			ETag:         `"v1"`,
+			LastModified: modified,
+			Body:         strings.NewReader(payload),
+		}, nil
+	})
+	api.Get(r, "/sized", func(_ context.Context, _ *api.Void) (*rangedFileResp, error) {
+		return &rangedFileResp{
+			ETag: `"v1"`,
+			Size: int64(len(payload)),
+			Body: readerAtOnly{r: strings.NewReader(payload)},
+		}, nil
+	})
+
+	tests := map[string]struct {
+		path     string
+		ifRange  string
+		wantCode int
+		wantBody string
+	}{
+		"etag match": {
+			path: "/file", ifRange: `"v1"`,
+			wantCode: http.StatusPartialContent, wantBody: "456789",
+		},
+		"etag mismatch": {
+			path: "/file", ifRange: `"v0"`,
+			wantCode: http.StatusOK, wantBody: payload,
+		},
+		"date match": {
+			path: "/file", ifRange: modified.Format(http.TimeFormat),
+			wantCode: http.StatusPartialContent, wantBody: "456789",
+		},
+		"date mismatch": {
+			path: "/file", ifRange: modified.Add(-time.Hour).Format(http.TimeFormat),
+			wantCode: http.StatusOK, wantBody: payload,
+		},
+		"reader at with size": {
+			path: "/sized", ifRange: `"v1"`,
+			wantCode: http.StatusPartialContent, wantBody: "456789",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Range", "bytes=4-9")
+			req.Header.Set("If-Range", tt.ifRange)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
 func TestResponse_validation_catches_bad_body(t *testing.T) {
 	t.Parallel()
 
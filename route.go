@@ -3,6 +3,8 @@ package api
 import (
 	"net/http"
 	"reflect"
+	"slices"
+	"time"
 )
 
 // routeInfo holds metadata for a registered route, used for both
@@ -10,21 +12,33 @@ import (
 type routeInfo struct {
 	method  string
 	pattern string
+	host    string // empty when the route serves every host
 	summary string
 	desc    string
 	tags    []string
-	status     int
-	deprecated bool
+
+	externalDocs *ExternalDocs
+	status       int
+	deprecated   bool
 
 	operationID string
 	security    []string
 	noSecurity  bool
 
+	// routerSecurity is true when the router declares default security,
+	// which applies to the route unless it sets its own or opts out.
+	routerSecurity bool
+
+	// scopes records the OAuth2/OIDC scopes each security scheme requires
+	// for this route, keyed by scheme name.
+	scopes map[string][]string
+
 	extensions map[string]any
 	links      map[string]Link
 	callbacks  map[string]map[string]PathItem
 
 	bodyLimit int64
+	rateLimit *RateLimitConfig
 
 	mode ValidationMode
 
@@ -34,6 +48,11 @@ type routeInfo struct {
 	requestDesc  *requestDescriptor
 	responseDesc *responseDescriptor
 
+	// attachesRouter is true when the handler puts the router in its
+	// handler's context itself, so addRoute need not attach it per
+	// request.
+	attachesRouter bool
+
 	// extraResponses documents additional response status codes beyond the
 	// success and the auto-baseline error codes. Keyed by HTTP status; value
 	// is the response body type (nil = no body).
@@ -43,6 +62,10 @@ type routeInfo struct {
 	// this route via api.WithError.
 	errorOpts []ErrorOption
 
+	// problems are the domain errors the route documents; see
+	// WithProblems.
+	problems []error
+
 	// errorCodes is the set of Codes documented for this route via
 	// WithError(WithErrors(...)). Populated at registration after
 	// merging router/group/route scope options.
@@ -53,6 +76,50 @@ type routeInfo struct {
 	// the returned *Err overlay this template.
 	errorTemplate *Err
 
+	// requestContent overrides the documented request body for routes
+	// that read it themselves (e.g. via RawRequest).
+	requestContent *RequestBody
+
+	// contentEncoding, when set, compresses codec bodies at encode time.
+	contentEncoding string
+
+	// encoders are negotiated for this route's responses after the
+	// router's; see WithResponseEncoder.
+	encoders []Encoder
+
+	// flushInterval batches the flushes of streamed array bodies; see
+	// WithFlushInterval.
+	flushInterval time.Duration
+
+	// heartbeat is the idle interval between heartbeats on server-sent
+	// event streams; see WithHeartbeat.
+	heartbeat time.Duration
+
+	// componentNames names generic schemas of this route; see
+	// componentNamer.
+	componentNames map[reflect.Type]string
+
+	// policy authorizes requests to this route. meta is filled with the
+	// route's final description when it is added to the router, for use
+	// in policy decisions, API key checks, and profile labels.
+	policy PolicyEngine
+	meta   *RouteDescription
+
+	// ownership lists the resource ownership checks for this route.
+	ownership []ownershipRule
+
+	// profile sets pprof labels while the route serves; see WithProfile.
+	profile bool
+
+	// budget limits the route's decode, handler, and response phases;
+	// see WithBudgetLimits.
+	budget *budgetLimits
+
+	// requestExamples and responseExamples document whole bodies by name;
+	// see WithRequestExample and WithResponseExample.
+	requestExamples  map[string]any
+	responseExamples map[int]map[string]any
+
 	handler http.Handler
 }
 
@@ -88,13 +155,48 @@ func WithSummary(s string) RouteOption {
 	})
 }
 
-// WithDescription sets the OpenAPI description for the route.
-func WithDescription(d string) RouteOption {
-	return RouteOptionFunc(func(ri *routeInfo) {
-		ri.desc = d
-	})
+// WithDescription sets an OpenAPI description. Passed to New it describes
+// the API in the spec's info; passed to a route, the operation. The
+// returned value satisfies RouterOption and RouteOption.
+func WithDescription(d string) *DescriptionScope {
+	return &DescriptionScope{desc: d}
+}
+
+// DescriptionScope is a description for the API or for a route. It
+// implements RouterOption and RouteOption.
+type DescriptionScope struct {
+	desc string
 }
 
+// applyRouter implements the router-level option interface.
+func (s *DescriptionScope) applyRouter(r *Router) { r.description = s.desc }
+
+// applyRoute implements the route-level option interface.
+func (s *DescriptionScope) applyRoute(ri *routeInfo) { ri.desc = s.desc }
+
+// WithExternalDocs links to external documentation in the OpenAPI spec.
+// Passed to New it links the whole API; passed to a route, the operation:
+//
+//	api.Get(r, "/orders/{id}", h.Get,
+//	    api.WithExternalDocs("https://developer.example.com/orders", "Orders guide"))
+//
+// The returned value satisfies RouterOption and RouteOption.
+func WithExternalDocs(url, description string) *ExternalDocsScope {
+	return &ExternalDocsScope{docs: ExternalDocs{URL: url, Description: description}}
+}
+
+// ExternalDocsScope is an external documentation link for the API or for
+// a route. It implements RouterOption and RouteOption.
+type ExternalDocsScope struct {
+	docs ExternalDocs
+}
+
+// applyRouter implements the router-level option interface.
+func (s *ExternalDocsScope) applyRouter(r *Router) { r.externalDocs = &s.docs }
+
+// applyRoute implements the route-level option interface.
+func (s *ExternalDocsScope) applyRoute(ri *routeInfo) { ri.externalDocs = &s.docs }
+
 // WithTags adds OpenAPI tags to the route.
 func WithTags(tags ...string) RouteOption {
 	return RouteOptionFunc(func(ri *routeInfo) {
@@ -123,6 +225,21 @@ func WithSecurity(schemes ...string) RouteOption {
 	})
 }
 
+// WithScopes requires the named security scheme with the given scopes for
+// this route. The scheme is added to the route's security requirements if
+// it is not already present.
+func WithScopes(scheme string, scopes ...string) RouteOption {
+	return RouteOptionFunc(func(ri *routeInfo) {
+		if !slices.Contains(ri.security, scheme) {
+			ri.security = append(ri.security, scheme)
+		}
+		if ri.scopes == nil {
+			ri.scopes = make(map[string][]string)
+		}
+		ri.scopes[scheme] = append(ri.scopes[scheme], scopes...)
+	})
+}
+
 // WithNoSecurity disables security for this route (overrides global security).
 func WithNoSecurity() RouteOption {
 	return RouteOptionFunc(func(ri *routeInfo) {
@@ -159,6 +276,17 @@ func WithBodyLimit(maxBytes int64) RouteOption {
 	})
 }
 
+// withRequestContent documents the request body as a single media type.
+// Used by built-in routes that consume the raw body.
+func withRequestContent(contentType string, schema JSONSchema) RouteOption {
+	return RouteOptionFunc(func(ri *routeInfo) {
+		ri.requestContent = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaObj{contentType: {Schema: &schema}},
+		}
+	})
+}
+
 // WithCallback adds an OpenAPI callback to the operation.
 func WithCallback(name string, cb map[string]PathItem) RouteOption {
 	return RouteOptionFunc(func(ri *routeInfo) {
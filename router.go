@@ -2,32 +2,65 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Router is the central type that holds routes, middleware, and configuration.
 // It implements http.Handler.
 type Router struct {
-	mux        *http.ServeMux
+	mux        Matcher
 	middleware []Middleware
 	routes     []routeInfo
 
+	// conditional holds middleware applied per route at registration; see
+	// When.
+	conditional []conditionalMiddleware
+
+	// chain is the middleware stack compiled around dispatch, rebuilt on
+	// every Use so ServeHTTP never reads the middleware slice.
+	chain atomic.Pointer[http.Handler]
+
+	// frozen rejects further Use and route registration; see Freeze.
+	frozen bool
+
+	// middlewareNames parallels middleware; middlewareRules are checked
+	// against it on every Use.
+	middlewareNames []string
+	middlewareRules []middlewareRule
+
 	// methodsByPattern tracks which HTTP methods have been registered for
 	// each pattern. Used to auto-generate HEAD (from GET) and OPTIONS (Allow
 	// header) responses without requiring per-route registration.
 	methodsByPattern map[string]map[string]struct{}
 
-	title   string
-	version string
+	title          string
+	version        string
+	description    string
+	termsOfService string
+	contact        *Contact
+	license        *License
+
+	anonymousResponseNames bool
 
 	servers         []Server
+	serverVars      map[string]map[string]ServerVariable
 	securitySchemes map[string]SecurityScheme
 	security        []string
 	tagDescs        map[string]string
+	tagDocs         map[string]*ExternalDocs
+	externalDocs    *ExternalDocs
+
+	// securityHandlers enforce schemes; see WithSecurityHandler.
+	securityHandlers map[string]SecurityHandler
 
 	webhooks map[string]PathItem
 
@@ -36,6 +69,8 @@ type Router struct {
 	errorHandler      ErrorHandler
 	errorOpts         []ErrorOption
 	validateResponses bool
+	requiredParams    bool
+	pathMismatch      Code
 
 	encoders []Encoder
 	decoders []Decoder
@@ -43,6 +78,39 @@ type Router struct {
 
 	tracer SpanStarter
 
+	secureCookies  *SecureCookies
+	cursorKey      []byte
+	cookieDefaults *CookieDefaults
+	redaction      RedactionPolicy
+	timeFormat     TimeFormat
+	bigIntStrings  bool
+	fieldScopes    ScopePolicy
+	bodyDefaults   bool
+	policy         PolicyEngine
+
+	negotiationCacheSize int
+	contentProtection    *ContentProtection
+	defaultCharset       string
+	xmlEnvelope          *XMLEnvelope
+	callCounter          *CallCounter
+	serverOpts           []func(*http.Server)
+	budget               *budgetLimits
+	budgetObserver       func(context.Context, BudgetViolation)
+	payloadObserver      func(context.Context, PayloadMetrics)
+
+	// Lifecycle hooks and readiness; see OnStart, OnShutdown, and
+	// HealthRoutes.
+	onStart         []func(context.Context) error
+	onShutdown      []func(context.Context) error
+	drainDelay      time.Duration
+	draining        atomic.Bool
+	shutdownTimeout time.Duration
+
+	// signals are replaced each time the router is served; see
+	// ShuttingDown and WithStreamGrace.
+	signals     atomic.Pointer[shutdownSignals]
+	streamGrace time.Duration
+
 	mu sync.Mutex
 }
 
@@ -73,6 +141,39 @@ func WithVersion(version string) RouterOption {
 	})
 }
 
+// WithContact sets the API contact information (used in OpenAPI spec).
+func WithContact(c Contact) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.contact = &c
+	})
+}
+
+// WithLicense sets the API license (used in OpenAPI spec).
+func WithLicense(l License) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.license = &l
+	})
+}
+
+// WithTermsOfService sets the URL of the API terms of service (used in
+// OpenAPI spec).
+func WithTermsOfService(url string) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.termsOfService = url
+	})
+}
+
+// WithAnonymousResponseNames documents each route whose success body is an
+// anonymous struct as a component schema named after its operation ID,
+// such as GetUsersByIdResponse, rather than inlining it, so generated
+// clients get a named type. Set WithOperationID to control the name.
+// Building the spec panics when a name is already taken by another type.
+func WithAnonymousResponseNames() RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.anonymousResponseNames = true
+	})
+}
+
 // WithValidator sets a router-level request validator. Typically used to plug
 // in a reflection-based library; see ValidatorFunc.
 func WithValidator(v ValidatorFunc) RouterOption {
@@ -100,6 +201,34 @@ func WithResponseValidation() RouterOption {
 	})
 }
 
+// WithRequiredParams enforces `required:"true"` on query, header, and cookie
+// params at binding time. A request missing any of them fails with 400,
+// with each missing param attached as a detail. Off by default, when the
+// tag only marks the param required in the spec.
+func WithRequiredParams() RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.requiredParams = true
+	})
+}
+
+// WithPathMismatch sets the error for a path value that does not match its
+// param's pattern tag or does not parse as the param's type:
+//
+//	type GetOrderReq struct {
+//	    ID string `path:"id" pattern:"^[0-9]+$"`
+//	}
+//
+//	r := api.New(api.WithPathMismatch(api.CodeNotFound))
+//
+// The check runs while binding, before validation and the handler. The
+// default, CodeBadRequest, reports a malformed request; CodeNotFound treats
+// the path as naming no resource.
+func WithPathMismatch(code Code) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.pathMismatch = code
+	})
+}
+
 // WithServers sets the OpenAPI servers array.
 func WithServers(servers ...Server) RouterOption {
 	return RouterOptionFunc(func(r *Router) {
@@ -107,6 +236,41 @@ func WithServers(servers ...Server) RouterOption {
 	})
 }
 
+// WithServerVariables documents the variables of the server whose URL is
+// url, as set by WithServers, for deployments whose URL varies by region,
+// tenant, or environment:
+//
+//	r := api.New(
+//	    api.WithServers(api.Server{URL: "https://{region}.api.example.com"}),
+//	    api.WithServerVariables("https://{region}.api.example.com", map[string]api.ServerVariable{
+//	        "region": {Enum: []string{"us", "eu"}, Default: "us"},
+//	    }),
+//	)
+//
+// It panics if a variable has no default, if the default is not in its
+// enum, or if url does not contain the variable.
+func WithServerVariables(url string, vars map[string]ServerVariable) RouterOption {
+	for name, v := range vars {
+		switch {
+		case !strings.Contains(url, "{"+name+"}"):
+			panic(fmt.Sprintf("api: WithServerVariables: %q has no {%s}", url, name))
+		case v.Default == "":
+			panic(fmt.Sprintf("api: WithServerVariables: variable %q has no default", name))
+		case len(v.Enum) > 0 && !slices.Contains(v.Enum, v.Default):
+			panic(fmt.Sprintf("api: WithServerVariables: default %q of variable %q is not in its enum", v.Default, name))
+		}
+	}
+	return RouterOptionFunc(func(r *Router) {
+		if r.serverVars == nil {
+			r.serverVars = make(map[string]map[string]ServerVariable)
+		}
+		if r.serverVars[url] == nil {
+			r.serverVars[url] = make(map[string]ServerVariable, len(vars))
+		}
+		maps.Copy(r.serverVars[url], vars)
+	})
+}
+
 // WithSecurityScheme registers a named security scheme for the OpenAPI spec.
 func WithSecurityScheme(name string, scheme SecurityScheme) RouterOption {
 	return RouterOptionFunc(func(r *Router) {
@@ -131,6 +295,17 @@ func WithTagDescriptions(descs map[string]string) RouterOption {
 	})
 }
 
+// WithTagExternalDocs links tag to external documentation in the OpenAPI
+// spec.
+func WithTagExternalDocs(tag, url, description string) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		if r.tagDocs == nil {
+			r.tagDocs = make(map[string]*ExternalDocs)
+		}
+		r.tagDocs[tag] = &ExternalDocs{URL: url, Description: description}
+	})
+}
+
 // ErrorHandler is a custom error response writer.
 type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
 
@@ -155,6 +330,18 @@ func WithDecoder(dec Decoder) RouterOption {
 	})
 }
 
+// WithDefaultCharset adds a charset parameter to the Content-Type of
+// textual codec responses (text/*, JSON, and XML media types) whose
+// encoder does not declare one:
+//
+//	r := api.New(api.WithDefaultCharset("utf-8"))
+//	// Content-Type: application/json; charset=utf-8
+func WithDefaultCharset(charset string) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.defaultCharset = charset
+	})
+}
+
 // WithWebhook registers a webhook path item for the OpenAPI spec.
 func WithWebhook(name string, item PathItem) RouterOption {
 	return RouterOptionFunc(func(r *Router) {
@@ -181,30 +368,111 @@ func WithTracer(s SpanStarter) RouterOption {
 // New creates a new Router with the given options.
 func New(opts ...RouterOption) *Router {
 	r := &Router{
-		mux:              http.NewServeMux(),
-		methodsByPattern: make(map[string]map[string]struct{}),
+		mux:                  http.NewServeMux(),
+		methodsByPattern:     make(map[string]map[string]struct{}),
+		negotiationCacheSize: defaultNegotiationCacheSize,
 	}
+	r.signals.Store(newShutdownSignals())
 	for _, opt := range opts {
 		opt.applyRouter(r)
 	}
-	r.codecs = newCodecRegistry(r.encoders, r.decoders)
+	r.codecs = newCodecRegistry(jsonCodec{mirror: newJSONMirror(r.timeFormat, r.bigIntStrings)}, xmlCodec{env: r.xmlEnvelope}, r.encoders, r.decoders)
+	r.codecs.cache = newNegotiationCache(r.negotiationCacheSize)
+	r.codecs.protection = r.contentProtection
+	r.codecs.charset = r.defaultCharset
+	r.compileChain()
 	return r
 }
 
+// ErrRouterFrozen is the panic value (wrapped) when a frozen Router is
+// modified.
+var ErrRouterFrozen = errors.New("api: router is frozen")
+
 // Use adds middleware to the router. Middleware is applied in the order added.
+// Panics if the stack violates a RequireBefore or RequireOutermost rule, or
+// if the router is frozen.
 func (r *Router) Use(mw ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkMutable("Use")
+
 	r.middleware = append(r.middleware, mw...)
+	for _, m := range mw {
+		r.middlewareNames = append(r.middlewareNames, MiddlewareName(m))
+	}
+	if err := r.checkMiddlewareOrder(); err != nil {
+		panic(err)
+	}
+	r.compileChain()
 }
 
-// ServeHTTP implements http.Handler. Middleware is applied in registration
-// order, then dispatch goes through autoMethodsHandler so HEAD and OPTIONS
-// requests get derived responses when no explicit handler exists.
-func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+// Freeze ends configuration: any later Use or route registration panics
+// with ErrRouterFrozen. It returns the compiled handler, equivalent to the
+// Router itself, for passing to http.Server:
+//
+//	srv := &http.Server{Handler: r.Freeze()}
+//
+// Freezing is optional — ServeHTTP is safe alongside Use either way — but
+// it turns a late registration, which would otherwise apply to some
+// requests and not others, into an immediate failure. Calling Freeze
+// again returns the same handler.
+func (r *Router) Freeze() http.Handler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frozen = true
+	return *r.chain.Load()
+}
+
+// checkMutable panics when the router is frozen. Caller must hold r.mu.
+func (r *Router) checkMutable(op string) {
+	if r.frozen {
+		panic(fmt.Errorf("%w: %s after Freeze", ErrRouterFrozen, op))
+	}
+}
+
+// compileChain wraps dispatch in the current middleware and publishes it
+// for ServeHTTP. Caller must hold r.mu, except during New.
+func (r *Router) compileChain() {
 	handler := http.Handler(http.HandlerFunc(r.dispatch))
 	for i := len(r.middleware) - 1; i >= 0; i-- {
 		handler = r.middleware[i](handler)
 	}
-	handler.ServeHTTP(w, req)
+	if len(r.middleware) > 0 {
+		handler = r.withRouter(handler)
+	}
+	r.chain.Store(&handler)
+}
+
+// ServeHTTP implements http.Handler. Middleware is applied in registration
+// order, then dispatch goes through autoMethodsHandler so HEAD and OPTIONS
+// requests get derived responses when no explicit handler exists. Each
+// request runs the chain as compiled by the latest Use.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	(*r.chain.Load()).ServeHTTP(w, req)
+}
+
+type routerKey struct{}
+
+// withRouter puts r in the request context for middleware and raw
+// handlers, which look it up through WriteError, ShuttingDown, and the
+// like. Typed handlers carry it in their handler context instead, so
+// requests that reach one directly skip the allocation.
+func (r *Router) withRouter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if rt, _ := req.Context().Value(routerKey{}).(*Router); rt != r {
+			req = req.WithContext(context.WithValue(req.Context(), routerKey{}, r))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// handle registers a non-operation handler (spec, docs, pprof, static
+// files) on the mux.
+func (r *Router) handle(pattern string, h http.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkMutable(pattern)
+	r.mux.Handle(pattern, h)
 }
 
 // dispatch routes the request through the mux, deriving HEAD from GET and
@@ -231,10 +499,68 @@ func (r *Router) dispatch(w http.ResponseWriter, req *http.Request) {
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
+	default:
+		// A path registered for other methods gets 405 in the router's
+		// error shape rather than ServeMux's plain-text one.
+		if _, pattern := r.mux.Handler(req); pattern == "" {
+			if methods := r.allowedMethods(req); len(methods) > 0 {
+				methods = appendMethod(methods, http.MethodOptions)
+				w.Header().Set("Allow", strings.Join(methods, ", "))
+				r.writeErr(w, req, Error(CodeMethodNotAllowed,
+					WithMessagef("method %s is not allowed for %s", req.Method, req.URL.Path)))
+				return
+			}
+		}
 	}
 	r.mux.ServeHTTP(w, req)
 }
 
+// WriteError renders err the way the Router serving r renders errors
+// raised outside a route: through its ErrorHandler, or as ProblemDetails
+// shaped by the router-scope error options. Authentication and other
+// middleware use it so their failures match handler errors:
+//
+//	if token == "" {
+//	    api.WriteError(w, r, api.Error(api.CodeUnauthorized,
+//	        api.WithHeader("WWW-Authenticate", "Bearer")))
+//	    return
+//	}
+//
+// Errors that are not *Err are translated like handler errors. Outside a
+// Router, WriteError uses the defaults of New.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	rt, ok := r.Context().Value(routerKey{}).(*Router)
+	if !ok {
+		rt = defaultRouter()
+	}
+	rt.writeErr(w, r, err)
+}
+
+// defaultRouter renders errors for WriteError outside a Router.
+var defaultRouter = sync.OnceValue(func() *Router { return New() })
+
+// writeErr renders an error raised by the router itself, outside any
+// route, through the ErrorHandler or the router-scope error options.
+func (r *Router) writeErr(w http.ResponseWriter, req *http.Request, err error) {
+	err = resolveErr(err)
+	if r.errorHandler != nil {
+		r.errorHandler(w, req, err)
+		return
+	}
+	tmpl := &Err{}
+	for _, opt := range r.errorOpts {
+		opt.applyErr(tmpl)
+	}
+	if tmpl.body == nil {
+		tmpl.body = &typedBodyMapper[ProblemDetails]{fn: ErrorBodyProblemDetails}
+	}
+	var apiErr *Err
+	if !errors.As(err, &apiErr) {
+		apiErr = &Err{code: CodeInternal, message: err.Error(), cause: err}
+	}
+	emitErr(w, req, mergeErr(tmpl, apiErr), r.codecs, r.cookieDefaults)
+}
+
 // methodRegistered reports whether the given method is explicitly registered
 // for the pattern that matches req's URL path.
 func (r *Router) methodRegistered(req *http.Request, method string) bool {
@@ -270,40 +596,35 @@ func stripMethodPrefix(pattern string) string {
 	return pattern
 }
 
-// allowedMethods returns the list of methods registered for the pattern that
-// matches req's URL path, regardless of method.
+// allowedMethods returns the methods that have a handler for req's URL
+// path, sorted. Each method is resolved through the mux, so a path matched
+// by several patterns (/items/special and /items/{id}) reports the union,
+// exactly what a request with that method would reach. HEAD is included
+// whenever GET is, since HEAD is served from GET.
 func (r *Router) allowedMethods(req *http.Request) []string {
 	r.mu.Lock()
-	defer r.mu.Unlock()
-	for pattern, methods := range r.methodsByPattern {
-		probe := req.Clone(req.Context())
-		probe.Method = http.MethodGet
-		// Re-resolve through mux to honor the same matching semantics; if any
-		// method matches the path we report that pattern's methods.
-		if matched := r.patternMatchesUnsafe(probe, pattern); matched {
-			out := make([]string, 0, len(methods))
-			for m := range methods {
-				out = append(out, m)
-			}
-			sort.Strings(out)
-			return out
+	candidates := make(map[string]struct{})
+	for _, methods := range r.methodsByPattern {
+		for m := range methods {
+			candidates[m] = struct{}{}
 		}
 	}
-	return nil
-}
-
-// patternMatchesUnsafe checks whether req's URL path matches pattern via mux
-// resolution. Caller must hold r.mu.
-func (r *Router) patternMatchesUnsafe(req *http.Request, pattern string) bool {
-	for method := range r.methodsByPattern[pattern] {
-		probe := req.Clone(req.Context())
-		probe.Method = method
-		_, matched := r.mux.Handler(probe)
-		if stripMethodPrefix(matched) == pattern {
-			return true
+	r.mu.Unlock()
+	candidates[http.MethodGet] = struct{}{} // spec, docs, and static files
+
+	var out []string
+	probe := req.Clone(req.Context())
+	for m := range candidates {
+		probe.Method = m
+		if _, pattern := r.mux.Handler(probe); pattern != "" {
+			out = append(out, m)
 		}
 	}
-	return false
+	if slices.Contains(out, http.MethodGet) && !slices.Contains(out, http.MethodHead) {
+		out = append(out, http.MethodHead)
+	}
+	sort.Strings(out)
+	return out
 }
 
 // serveHEADFromGET swaps a HEAD request to GET, runs the GET handler, and
@@ -336,27 +657,80 @@ func appendMethod(methods []string, method string) []string {
 	return out
 }
 
+// WithServerOptions tunes the http.Server that ListenAndServe and
+// ListenAndServeTLS create: timeouts, TLSConfig, MaxHeaderBytes, protocols,
+// and so on. Options run in order after the defaults are set. Cleartext
+// HTTP/2 (h2c), for example:
+//
+//	api.WithServerOptions(func(srv *http.Server) {
+//	    srv.Protocols = new(http.Protocols)
+//	    srv.Protocols.SetHTTP1(true)
+//	    srv.Protocols.SetUnencryptedHTTP2(true)
+//	})
+func WithServerOptions(opts ...func(*http.Server)) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.serverOpts = append(r.serverOpts, opts...)
+	})
+}
+
 // ListenAndServe starts an HTTP server on the given address.
 // It blocks until the context is cancelled, then shuts down gracefully.
 func (r *Router) ListenAndServe(ctx context.Context, addr string) error {
+	srv := r.newServer(addr)
+	return r.serve(ctx, srv, srv.ListenAndServe)
+}
+
+// ListenAndServeTLS is ListenAndServe over HTTPS, with HTTP/2 negotiated
+// automatically. certFile and keyFile may be empty when the TLSConfig set
+// through WithServerOptions already holds the certificates.
+func (r *Router) ListenAndServeTLS(ctx context.Context, addr, certFile, keyFile string) error {
+	srv := r.newServer(addr)
+	return r.serve(ctx, srv, func() error {
+		return srv.ListenAndServeTLS(certFile, keyFile)
+	})
+}
+
+// newServer returns the server for addr with the defaults and
+// WithServerOptions applied.
+func (r *Router) newServer(addr string) *http.Server {
 	srv := &http.Server{
 		Addr:              addr,
 		Handler:           r,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
+	for _, opt := range r.serverOpts {
+		opt(srv)
+	}
+	return srv
+}
+
+// serve runs the OnStart hooks and then listen until it fails or ctx is
+// cancelled, then shuts srv down gracefully. Each call starts from a ready
+// router, so a router can be served again after shutting down.
+func (r *Router) serve(ctx context.Context, srv *http.Server, listen func() error) error {
+	r.draining.Store(false)
+	r.signals.Store(newShutdownSignals())
+	if err := r.start(ctx); err != nil {
+		return err
+	}
 
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- srv.ListenAndServe()
+		errCh <- listen()
 	}()
 
 	select {
 	case err := <-errCh:
-		return err
+		// The OnStart hooks ran, so what they opened still needs closing.
+		return errors.Join(err, r.shutdownPhase(ctx, r.shutdown))
 	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-		defer cancel()
-		return srv.Shutdown(shutdownCtx)
+		r.shutdownPhase(ctx, func(ctx context.Context) error { //nolint:errcheck,gosec // drain does not fail
+			r.drain(ctx)
+			return nil
+		})
+		r.stopStreams()
+		err := r.shutdownPhase(ctx, srv.Shutdown)
+		return errors.Join(err, r.shutdownPhase(ctx, r.shutdown))
 	}
 }
 
@@ -366,12 +740,38 @@ func (r *Router) ListenAndServe(ctx context.Context, addr string) error {
 func (r *Router) addRoute(ri routeInfo) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	r.checkMutable(ri.method + " " + ri.pattern)
 
-	r.mux.Handle(ri.method+" "+ri.pattern, ri.handler)
+	if ri.meta != nil {
+		*ri.meta = r.describeRoute(&ri)
+	}
+	checkOwnershipParams(&ri)
+	ri.routerSecurity = len(r.security) > 0
+	if len(r.conditional) > 0 || len(r.securityHandlers) > 0 {
+		desc := r.describeRoute(&ri)
+		for _, c := range slices.Backward(r.conditional) {
+			if c.pred(desc) {
+				for _, mw := range slices.Backward(c.mw) {
+					ri.handler = mw(ri.handler)
+				}
+				ri.attachesRouter = false
+			}
+		}
+		r.enforceSecurity(&ri, desc)
+	}
+	if !ri.attachesRouter {
+		ri.handler = r.withRouter(ri.handler)
+	}
+	// Host routes use the mux's host-qualified patterns.
+	pattern := ri.host + ri.pattern
+	if r.callCounter != nil {
+		ri.handler = r.callCounter.wrap(ri.method+" "+pattern, ri.handler)
+	}
+	r.mux.Handle(ri.method+" "+pattern, ri.handler)
 	r.routes = append(r.routes, ri)
 
-	if r.methodsByPattern[ri.pattern] == nil {
-		r.methodsByPattern[ri.pattern] = make(map[string]struct{})
+	if r.methodsByPattern[pattern] == nil {
+		r.methodsByPattern[pattern] = make(map[string]struct{})
 	}
-	r.methodsByPattern[ri.pattern][ri.method] = struct{}{}
+	r.methodsByPattern[pattern][ri.method] = struct{}{}
 }
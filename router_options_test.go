@@ -1,12 +1,16 @@
 package api_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -126,6 +130,87 @@ func TestListenAndServe_port_in_use(t *testing.T) {
 	assert.Contains(t, err.Error(), "bind")
 }
 
+func TestListenAndServeTLS(t *testing.T) {
+	t.Parallel()
+
+	// Borrow httptest's self-signed certificate and a client that trusts it.
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(ts.Close)
+	cert := ts.TLS.Certificates[0]
+	tlsConfig := ts.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig, ForceAttemptHTTP2: true}}
+
+	addr := freeAddr(t)
+
+	r := api.New(api.WithServerOptions(func(srv *http.Server) {
+		srv.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}))
+	api.Get(r, "/ping", voidHandler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- r.ListenAndServeTLS(ctx, addr, "", "") }()
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	require.Eventually(t, func() bool {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+addr+"/ping", http.NoBody)
+		resp, err = client.Do(req)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, 2, resp.ProtoMajor)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWithServerOptions(t *testing.T) {
+	t.Parallel()
+
+	addr := freeAddr(t)
+
+	var applied []string
+	r := api.New(api.WithServerOptions(
+		func(srv *http.Server) {
+			applied = append(applied, "timeouts")
+			srv.ReadTimeout = time.Second
+		},
+		func(srv *http.Server) {
+			applied = append(applied, "handler")
+			next := srv.Handler
+			srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("X-Server", "custom")
+				next.ServeHTTP(w, req)
+			})
+		},
+	))
+	api.Get(r, "/ping", voidHandler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- r.ListenAndServe(ctx, addr) }()
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	require.Eventually(t, func() bool {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+"/ping", http.NoBody)
+		resp, err = http.DefaultClient.Do(req)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "custom", resp.Header.Get("X-Server"))
+	assert.Equal(t, []string{"timeouts", "handler"}, applied)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
 func TestWithValidator(t *testing.T) {
 	t.Parallel()
 
@@ -150,6 +235,95 @@ func TestWithServers(t *testing.T) {
 	assert.Equal(t, "Production", spec.Servers[0].Description)
 }
 
+func TestInfoOptions(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(
+		api.WithTitle("Orders"),
+		api.WithVersion("1.2.0"),
+		api.WithDescription("Order management."),
+		api.WithTermsOfService("https://example.com/terms"),
+		api.WithContact(api.Contact{Name: "Orders team", Email: "orders@example.com"}),
+		api.WithLicense(api.License{Name: "Apache 2.0", Identifier: "Apache-2.0"}),
+	)
+	api.Get(r, "/orders", func(_ context.Context, _ *api.Void) (*api.Void, error) { return nil, nil },
+		api.WithDescription("Lists orders."))
+
+	spec := r.Spec()
+	assert.Equal(t, api.OpenAPIInfo{
+		Title:          "Orders",
+		Description:    "Order management.",
+		TermsOfService: "https://example.com/terms",
+		Contact:        &api.Contact{Name: "Orders team", Email: "orders@example.com"},
+		License:        &api.License{Name: "Apache 2.0", Identifier: "Apache-2.0"},
+		Version:        "1.2.0",
+	}, spec.Info)
+	assert.Equal(t, "Lists orders.", spec.Paths["/orders"]["get"].Description)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteSpec(&buf))
+	assert.Contains(t, buf.String(), `"termsOfService": "https://example.com/terms"`)
+	assert.Contains(t, buf.String(), `"identifier": "Apache-2.0"`)
+}
+
+func TestWithExternalDocs(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(
+		api.WithExternalDocs("https://developer.example.com", "Developer portal"),
+		api.WithTagDescriptions(map[string]string{"orders": "Order operations"}),
+		api.WithTagExternalDocs("orders", "https://developer.example.com/orders", ""),
+		api.WithTagExternalDocs("billing", "https://developer.example.com/billing", "Billing guide"),
+	)
+	api.Get(r, "/orders", func(_ context.Context, _ *api.Void) (*api.Void, error) { return nil, nil },
+		api.WithExternalDocs("https://developer.example.com/orders/list", ""))
+
+	spec := r.Spec()
+	assert.Equal(t, &api.ExternalDocs{URL: "https://developer.example.com", Description: "Developer portal"}, spec.ExternalDocs)
+	assert.Equal(t, []api.TagObj{
+		{Name: "billing", ExternalDocs: &api.ExternalDocs{URL: "https://developer.example.com/billing", Description: "Billing guide"}},
+		{Name: "orders", Description: "Order operations", ExternalDocs: &api.ExternalDocs{URL: "https://developer.example.com/orders"}},
+	}, spec.Tags)
+	assert.Equal(t, &api.ExternalDocs{URL: "https://developer.example.com/orders/list"}, spec.Paths["/orders"]["get"].ExternalDocs)
+
+	var streamed, whole bytes.Buffer
+	require.NoError(t, r.WriteSpecStream(&streamed))
+	require.NoError(t, json.NewEncoder(&whole).Encode(spec))
+	assert.Equal(t, whole.String(), streamed.String())
+}
+
+func TestWithServerVariables(t *testing.T) {
+	t.Parallel()
+
+	const regional = "https://{region}.api.example.com/{version}"
+	r := api.New(
+		api.WithServers(
+			api.Server{URL: regional, Variables: map[string]api.ServerVariable{"version": {Default: "v1"}}},
+			api.Server{URL: "https://api.example.com"},
+		),
+		api.WithServerVariables(regional, map[string]api.ServerVariable{
+			"region": {Enum: []string{"us", "eu"}, Default: "us", Description: "Deployment region"},
+		}),
+	)
+
+	spec := r.Spec()
+	require.Len(t, spec.Servers, 2)
+	assert.Equal(t, map[string]api.ServerVariable{
+		"region":  {Enum: []string{"us", "eu"}, Default: "us", Description: "Deployment region"},
+		"version": {Default: "v1"},
+	}, spec.Servers[0].Variables)
+	assert.Nil(t, spec.Servers[1].Variables)
+
+	invalid := map[string]map[string]api.ServerVariable{
+		"missing placeholder": {"zone": {Default: "a"}},
+		"no default":          {"region": {Enum: []string{"us"}}},
+		"default not in enum": {"region": {Enum: []string{"us", "eu"}, Default: "ap"}},
+	}
+	for name, vars := range invalid {
+		assert.Panics(t, func() { api.WithServerVariables(regional, vars) }, name)
+	}
+}
+
 func TestWithGlobalSecurity(t *testing.T) {
 	t.Parallel()
 
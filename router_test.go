@@ -169,3 +169,260 @@ func TestRouter_auto_OPTIONS_returns_allow(t *testing.T) {
 	assert.Contains(t, allow, "POST")
 	assert.Contains(t, allow, "OPTIONS")
 }
+
+func TestRouter_Freeze(t *testing.T) {
+	t.Parallel()
+
+	built := 0
+	r := api.New()
+	r.Use(func(next http.Handler) http.Handler {
+		built++
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Custom", "applied")
+			next.ServeHTTP(w, req)
+		})
+	})
+	api.Get(r, "/test", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	h := r.Freeze()
+	compiled := built
+
+	for range 3 {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/test", nil))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "applied", rec.Header().Get("X-Custom"))
+	}
+	assert.Equal(t, compiled, built, "chain is compiled once, not per request")
+}
+
+func TestRouter_Freeze_rejectsMutation(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]func(r *api.Router){
+		"use": func(r *api.Router) {
+			r.Use(api.Recovery())
+		},
+		"route": func(r *api.Router) {
+			api.Get(r, "/late", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+				return &api.Void{}, nil
+			})
+		},
+		"serve spec": func(r *api.Router) {
+			r.ServeSpec("/openapi.json")
+		},
+	}
+
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New()
+			r.Freeze()
+
+			defer func() {
+				rec := recover()
+				err, ok := rec.(error)
+				require.True(t, ok, "panic value %v", rec)
+				assert.ErrorIs(t, err, api.ErrRouterFrozen)
+			}()
+			mutate(r)
+		})
+	}
+}
+
+func TestRouter_Use_concurrentWithServe(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/test", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 50 {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/test", nil))
+		}
+	}()
+	for range 50 {
+		r.Use(func(next http.Handler) http.Handler { return next })
+	}
+	<-done
+}
+
+func TestRouter_auto_OPTIONS_allowedMethods(t *testing.T) {
+	t.Parallel()
+
+	type itemReq struct {
+		ID string `path:"id"`
+	}
+	r := api.New()
+	api.Get(r, "/items/{id}", func(_ context.Context, _ *itemReq) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+	api.Delete(r, "/items/{id}", func(_ context.Context, _ *itemReq) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+	api.Put(r, "/items/special", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+	api.Post(r, "/things", func(_ context.Context, _ *api.Void) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+	r.ServeSpec("/openapi.json")
+
+	tests := map[string]struct {
+		path       string
+		wantStatus int
+		wantAllow  string
+	}{
+		"wildcard":    {path: "/items/1", wantStatus: http.StatusNoContent, wantAllow: "DELETE, GET, HEAD, OPTIONS"},
+		"overlapping": {path: "/items/special", wantStatus: http.StatusNoContent, wantAllow: "DELETE, GET, HEAD, OPTIONS, PUT"},
+		"no GET":      {path: "/things", wantStatus: http.StatusNoContent, wantAllow: "OPTIONS, POST"},
+		"non-route":   {path: "/openapi.json", wantStatus: http.StatusNoContent, wantAllow: "GET, HEAD, OPTIONS"},
+		"unknown":     {path: "/nope", wantStatus: http.StatusNotFound},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodOptions, tc.path, nil))
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantAllow, rec.Header().Get("Allow"))
+		})
+	}
+}
+
+func TestRouter_methodNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	type itemReq struct {
+		ID string `path:"id"`
+	}
+	newRouter := func(opts ...api.RouterOption) *api.Router {
+		r := api.New(opts...)
+		api.Get(r, "/items/{id}", func(_ context.Context, _ *itemReq) (*api.Void, error) {
+			return &api.Void{}, nil
+		})
+		api.Delete(r, "/items/{id}", func(_ context.Context, _ *itemReq) (*api.Void, error) {
+			return &api.Void{}, nil
+		})
+		return r
+	}
+
+	t.Run("problem details", func(t *testing.T) {
+		t.Parallel()
+
+		r := newRouter(api.WithError(api.WithHeader("X-Scope", "router")))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodPut, "/items/1", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "DELETE, GET, HEAD, OPTIONS", rec.Header().Get("Allow"))
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "router", rec.Header().Get("X-Scope"))
+
+		var pd api.ProblemDetails
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pd))
+		assert.Equal(t, api.CodeMethodNotAllowed, pd.Code)
+		assert.Equal(t, "method PUT is not allowed for /items/1", pd.Detail)
+	})
+
+	t.Run("error handler", func(t *testing.T) {
+		t.Parallel()
+
+		var got error
+		r := newRouter(api.WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+			got = err
+			w.WriteHeader(http.StatusTeapot)
+		}))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/items/1", nil))
+
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+		var apiErr *api.Err
+		require.ErrorAs(t, got, &apiErr)
+		assert.Equal(t, api.CodeMethodNotAllowed, apiErr.Code())
+	})
+
+	t.Run("unknown path is 404", func(t *testing.T) {
+		t.Parallel()
+
+		rec := httptest.NewRecorder()
+		newRouter().ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodPut, "/nope", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Header().Get("Allow"))
+	})
+}
+
+func TestWriteError(t *testing.T) {
+	t.Parallel()
+
+	deny := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				api.WriteError(w, r, api.Error(api.CodeUnauthorized,
+					api.WithMessage("sign in first"), api.WithHeader("WWW-Authenticate", "Bearer")))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	t.Run("router error options", func(t *testing.T) {
+		t.Parallel()
+
+		r := api.New(api.WithError(api.WithProblemType("https://example.com/problems/auth")))
+		r.Use(deny)
+		api.Get(r, "/x", voidHandler)
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/x", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+		var pd api.ProblemDetails
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pd))
+		assert.Equal(t, "https://example.com/problems/auth", pd.Type)
+		assert.Equal(t, "sign in first", pd.Detail)
+	})
+
+	t.Run("error handler", func(t *testing.T) {
+		t.Parallel()
+
+		var got error
+		r := api.New(api.WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+			got = err
+			w.WriteHeader(api.ErrorStatus(err))
+		}))
+		r.Use(deny)
+		api.Get(r, "/x", voidHandler)
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/x", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, http.StatusUnauthorized, api.ErrorStatus(got))
+	})
+
+	t.Run("outside a router", func(t *testing.T) {
+		t.Parallel()
+
+		rec := httptest.NewRecorder()
+		deny(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/x", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		var pd api.ProblemDetails
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pd))
+		assert.Equal(t, "sign in first", pd.Detail)
+	})
+}
@@ -0,0 +1,103 @@
+package api
+
+import "slices"
+
+// RouteDescription is a read-only view of a registered route's metadata.
+// It is the shape reported by Routes and consumed by introspection tooling.
+type RouteDescription struct {
+	Method string
+
+	// Host is the virtual host the route is bound to with Router.Host, or
+	// empty when it serves every host.
+	Host string
+
+	Pattern     string
+	OperationID string
+	Summary     string
+	Tags        []string
+	Deprecated  bool
+
+	// Security lists the security scheme names required by the route after
+	// applying group and router defaults. Empty when the route is public.
+	Security []string
+
+	// Scopes maps scheme names to the scopes the route requires.
+	Scopes map[string][]string
+
+	// NoSecurity is true when the route explicitly opted out of security
+	// via WithNoSecurity.
+	NoSecurity bool
+
+	// BodyLimit is the per-route request body limit in bytes set via
+	// WithBodyLimit, or zero.
+	BodyLimit int64
+
+	// RateLimit is the per-route limit set via WithRateLimit, or nil.
+	RateLimit *RouteRateLimit
+}
+
+// HasTag reports whether the route is tagged tag.
+func (d RouteDescription) HasTag(tag string) bool {
+	return slices.Contains(d.Tags, tag)
+}
+
+// RouteRateLimit is the rate and burst of a per-route rate limit.
+type RouteRateLimit struct {
+	Rate  float64 // requests per second
+	Burst int
+}
+
+// Routes returns descriptions of every typed and raw route registered on
+// the router, in registration order.
+func (r *Router) Routes() []RouteDescription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]RouteDescription, 0, len(r.routes))
+	for i := range r.routes {
+		out = append(out, r.describeRoute(&r.routes[i]))
+	}
+	return out
+}
+
+// describeRoute builds the public description of ri, resolving the
+// effective security requirements against the router's global security.
+func (r *Router) describeRoute(ri *routeInfo) RouteDescription {
+	d := RouteDescription{
+		Method:      ri.method,
+		Host:        ri.host,
+		Pattern:     ri.pattern,
+		OperationID: ri.resolvedOperationID(),
+		Summary:     ri.summary,
+		Tags:        append([]string{}, ri.tags...),
+		Deprecated:  ri.deprecated,
+		NoSecurity:  ri.noSecurity,
+		BodyLimit:   ri.bodyLimit,
+	}
+	if ri.rateLimit != nil {
+		d.RateLimit = &RouteRateLimit{Rate: ri.rateLimit.Rate, Burst: ri.rateLimit.Burst}
+	}
+	if !ri.noSecurity {
+		if len(ri.security) > 0 {
+			d.Security = append([]string{}, ri.security...)
+		} else {
+			d.Security = append([]string{}, r.security...)
+		}
+	}
+	if len(ri.scopes) > 0 {
+		d.Scopes = make(map[string][]string, len(ri.scopes))
+		for name, scopes := range ri.scopes {
+			d.Scopes[name] = append([]string{}, scopes...)
+		}
+	}
+	return d
+}
+
+// resolvedOperationID returns the explicit operationId if set, otherwise
+// the one generated from method and pattern.
+func (ri *routeInfo) resolvedOperationID() string {
+	if ri.operationID != "" {
+		return ri.operationID
+	}
+	return generateOperationID(ri.method, ri.pattern)
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// SanitizerFunc rewrites a bound string value. Sanitizers are named in
+// `sanitize` tags and run in tag order after the request is bound and
+// before any validation:
+//
+//	type CreateReq struct {
+//	    Email string `query:"email" sanitize:"trim,lower"`
+//	    Body  struct {
+//	        Bio string `json:"bio" sanitize:"trim,stripHTML"`
+//	    }
+//	}
+//
+// A tag applies to string fields, string pointers, and string slices,
+// including named string types, in parameters and through nested body
+// structs, whether held directly, by pointer, or in slices, arrays, and
+// map values. A tag on any other type, or naming an unknown sanitizer, panics
+// at registration.
+type SanitizerFunc func(string) string
+
+// sanitizers holds the built-in and registered sanitizers by name.
+var sanitizers sync.Map // map[string]SanitizerFunc
+
+func init() {
+	RegisterSanitizer("trim", strings.TrimSpace)
+	RegisterSanitizer("lower", strings.ToLower)
+	RegisterSanitizer("upper", strings.ToUpper)
+	RegisterSanitizer("stripHTML", stripHTML)
+	RegisterSanitizer("squish", squish)
+}
+
+// RegisterSanitizer makes fn available to `sanitize` tags under name,
+// replacing any sanitizer already registered with that name. Tags are
+// resolved when a route is registered, so register custom sanitizers at
+// init:
+//
+//	func init() {
+//	    api.RegisterSanitizer("digits", func(s string) string {
+//	        return strings.Map(keepDigits, s)
+//	    })
+//	}
+//
+// Built-in sanitizers: trim, lower, upper, stripHTML (removes markup
+// tags), and squish (trims and collapses inner whitespace runs).
+func RegisterSanitizer(name string, fn SanitizerFunc) {
+	if name == "" || fn == nil {
+		panic("api: RegisterSanitizer requires a name and a func")
+	}
+	sanitizers.Store(name, fn)
+}
+
+// sanitizePlan lists the fields of one struct type that carry a sanitize
+// tag or contain nested fields that do.
+type sanitizePlan struct {
+	fields []sanitizeField
+}
+
+type sanitizeField struct {
+	index  int
+	funcs  []SanitizerFunc
+	nested *sanitizePlan
+}
+
+// buildSanitizePlan compiles the sanitize tags reachable from t. It returns
+// nil when there are none.
+func buildSanitizePlan(t reflect.Type) (*sanitizePlan, error) {
+	return buildSanitizePlanSeen(t, map[reflect.Type]*sanitizePlan{})
+}
+
+func buildSanitizePlanSeen(t reflect.Type, seen map[reflect.Type]*sanitizePlan) (*sanitizePlan, error) {
+	t = sanitizeElem(t)
+	if t.Kind() != reflect.Struct {
+		return nil, nil //nolint:nilnil // no plan for non-structs
+	}
+	if p, ok := seen[t]; ok {
+		// A recursive type shares the plan still being built.
+		return p, nil
+	}
+	plan := &sanitizePlan{}
+	seen[t] = plan
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		sf := sanitizeField{index: i}
+
+		if tag := f.Tag.Get("sanitize"); tag != "" {
+			if !isSanitizableType(f.Type) {
+				return nil, fmt.Errorf("field %s.%s: sanitize tag on non-string type %s", t, f.Name, f.Type)
+			}
+			for name := range strings.SplitSeq(tag, ",") {
+				name = strings.TrimSpace(name)
+				fn, ok := sanitizers.Load(name)
+				if !ok {
+					return nil, fmt.Errorf("field %s.%s: unknown sanitizer %q", t, f.Name, name)
+				}
+				sf.funcs = append(sf.funcs, fn.(SanitizerFunc)) //nolint:errcheck,forcetypeassert // map holds SanitizerFunc only
+			}
+		}
+
+		if hasSanitizeTags(f.Type, map[reflect.Type]bool{}) {
+			nested, err := buildSanitizePlanSeen(f.Type, seen)
+			if err != nil {
+				return nil, err
+			}
+			sf.nested = nested
+		}
+
+		if len(sf.funcs) > 0 || sf.nested != nil {
+			plan.fields = append(plan.fields, sf)
+		}
+	}
+
+	if len(plan.fields) == 0 {
+		return nil, nil //nolint:nilnil // nothing to sanitize
+	}
+	return plan, nil
+}
+
+// sanitizeElem returns the type reached from t through pointers, slices,
+// arrays, and map values.
+func sanitizeElem(t reflect.Type) reflect.Type {
+	for {
+		//exhaustive:ignore
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// hasSanitizeTags reports whether a sanitize tag is reachable from t
+// through structs and the pointers, slices, arrays, and maps holding them.
+func hasSanitizeTags(t reflect.Type, visited map[reflect.Type]bool) bool {
+	t = sanitizeElem(t)
+	if t.Kind() != reflect.Struct || visited[t] {
+		return false
+	}
+	visited[t] = true
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Tag.Get("sanitize") != "" || hasSanitizeTags(f.Type, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSanitizableType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String
+}
+
+// apply sanitizes the tagged fields of the struct v in place.
+func (p *sanitizePlan) apply(v reflect.Value) {
+	for _, sf := range p.fields {
+		fv := v.Field(sf.index)
+		if len(sf.funcs) > 0 {
+			sanitizeValue(fv, sf.funcs)
+		}
+		if sf.nested != nil {
+			sf.nested.applyNested(fv)
+		}
+	}
+}
+
+// applyNested applies p to the structs v holds, directly or through
+// pointers, slices, arrays, and map values.
+func (p *sanitizePlan) applyNested(v reflect.Value) {
+	//exhaustive:ignore
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			p.applyNested(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			p.applyNested(v.Index(i))
+		}
+	case reflect.Map:
+		// Map values are not addressable: sanitize a copy and store it.
+		elem := reflect.New(v.Type().Elem()).Elem()
+		for iter := v.MapRange(); iter.Next(); {
+			elem.Set(iter.Value())
+			p.applyNested(elem)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.Struct:
+		p.apply(v)
+	}
+}
+
+func sanitizeValue(v reflect.Value, funcs []SanitizerFunc) {
+	//exhaustive:ignore
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			sanitizeValue(v.Elem(), funcs)
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			sanitizeValue(v.Index(i), funcs)
+		}
+	case reflect.String:
+		s := v.String()
+		for _, fn := range funcs {
+			s = fn(s)
+		}
+		v.SetString(s)
+	}
+}
+
+// applySanitizers applies plan to the bound request req.
+func applySanitizers(req any, plan *sanitizePlan) {
+	plan.apply(reflect.ValueOf(req).Elem())
+}
+
+// stripHTML removes markup tags, leaving their text content. It is meant
+// for tidying plain-text input, not as an HTML sanitizer for rendering.
+func stripHTML(s string) string {
+	if !strings.ContainsRune(s, '<') {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	inTag := false
+	for _, r := range s {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>' && inTag:
+			inTag = false
+		case !inTag:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// squish trims s and collapses each inner whitespace run to one space.
+func squish(s string) string {
+	return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+}
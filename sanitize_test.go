@@ -0,0 +1,155 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type snHandle string
+
+type snProfile struct {
+	Bio    string    `json:"bio" sanitize:"trim,stripHTML"`
+	Handle *snHandle `json:"handle,omitempty" sanitize:"trim,lower"`
+	Tags   []string  `json:"tags" sanitize:"squish,lower"`
+	Next   *snProfile
+}
+
+func init() {
+	api.RegisterSanitizer("snDigits", func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, s)
+	})
+}
+
+func TestSanitize(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Email string `query:"email" sanitize:"trim,lower" format:"email"`
+		Phone string `header:"X-Phone" sanitize:"snDigits" minLength:"10"`
+		Body  snProfile
+	}
+
+	var got *Req
+	r := api.New()
+	api.Post(r, "/profiles", func(_ context.Context, req *Req) (*api.Void, error) {
+		got = req
+		return &api.Void{}, nil
+	})
+
+	body := `{"bio":"  <b>hello</b> world ","handle":" Ann ","tags":["  Go   Lang "],"Next":{"bio":"<i>x</i>"}}`
+	req := httptest.NewRequest(http.MethodPost, "/profiles?email=%20Ann@Example.COM%20", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Phone", "(555) 123-4567")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	assert.Equal(t, "ann@example.com", got.Email)
+	assert.Equal(t, "5551234567", got.Phone)
+	assert.Equal(t, "hello world", got.Body.Bio)
+	require.NotNil(t, got.Body.Handle)
+	assert.Equal(t, snHandle("ann"), *got.Body.Handle)
+	assert.Equal(t, []string{"go lang"}, got.Body.Tags)
+	require.NotNil(t, got.Body.Next)
+	assert.Equal(t, "x", got.Body.Next.Bio)
+}
+
+func TestSanitize_collections(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Body struct {
+			Members  []snProfile             `json:"members"`
+			Owners   []*snProfile            `json:"owners"`
+			ByRegion map[string]snProfile    `json:"byRegion"`
+			Pinned   [1]snProfile            `json:"pinned"`
+			Teams    map[string][]*snProfile `json:"teams"`
+		}
+	}
+
+	var got *Req
+	r := api.New()
+	api.Post(r, "/orgs", func(_ context.Context, req *Req) (*api.Void, error) {
+		got = req
+		return &api.Void{}, nil
+	})
+
+	body := `{
+		"members":[{"bio":" <b>a</b> "},{"bio":" b "}],
+		"owners":[{"bio":" <i>c</i>"},null],
+		"byRegion":{"eu":{"bio":" d ","tags":[" X  Y "]}},
+		"pinned":[{"bio":" e "}],
+		"teams":{"core":[{"bio":" f "}]}
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/orgs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	assert.Equal(t, "a", got.Body.Members[0].Bio)
+	assert.Equal(t, "b", got.Body.Members[1].Bio)
+	assert.Equal(t, "c", got.Body.Owners[0].Bio)
+	assert.Equal(t, "d", got.Body.ByRegion["eu"].Bio)
+	assert.Equal(t, []string{"x y"}, got.Body.ByRegion["eu"].Tags)
+	assert.Equal(t, "e", got.Body.Pinned[0].Bio)
+	assert.Equal(t, "f", got.Body.Teams["core"][0].Bio)
+}
+
+func TestSanitize_before_validation(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Name string `query:"name" sanitize:"trim" minLength:"1"`
+	}
+
+	r := api.New()
+	api.Get(r, "/names", func(_ context.Context, _ *Req) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/names?name=%20%20", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestSanitize_invalid_tags(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]func(r *api.Router){
+		"unknown sanitizer": func(r *api.Router) {
+			type Req struct {
+				Name string `query:"name" sanitize:"trim,nope"`
+			}
+			api.Get(r, "/a", func(context.Context, *Req) (*api.Void, error) { return &api.Void{}, nil })
+		},
+		"non-string field": func(r *api.Router) {
+			type Req struct {
+				Body struct {
+					Count int `json:"count" sanitize:"trim"`
+				}
+			}
+			api.Post(r, "/b", func(context.Context, *Req) (*api.Void, error) { return &api.Void{}, nil })
+		},
+	}
+
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Panics(t, func() { register(api.New()) })
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"fmt"
+	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
@@ -16,6 +18,7 @@ type JSONSchema struct {
 	Items           *JSONSchema           `json:"items,omitempty"`
 	Required        []string              `json:"required,omitempty"`
 	Description     string                `json:"description,omitempty"`
+	Deprecated      bool                  `json:"deprecated,omitempty"`
 	Enum            []string              `json:"enum,omitempty"`
 	Ref             string                `json:"$ref,omitempty"`
 
@@ -31,6 +34,11 @@ type JSONSchema struct {
 	MinItems  *int     `json:"minItems,omitempty"`
 	MaxItems  *int     `json:"maxItems,omitempty"`
 
+	// MinProperties and MaxProperties bound the entries of a map, set from
+	// its minItems and maxItems tags.
+	MinProperties *int `json:"minProperties,omitempty"`
+	MaxProperties *int `json:"maxProperties,omitempty"`
+
 	// Defaults and examples.
 	Default any `json:"default,omitempty"`
 	Example any `json:"example,omitempty"`
@@ -62,13 +70,22 @@ func typeToSchema(t reflect.Type) JSONSchema {
 	switch t {
 	case reflect.TypeFor[time.Time]():
 		return JSONSchema{Type: "string", Format: "date-time"}
+	case rawMessageType: // any JSON value, not a base64 []byte
+		return JSONSchema{}
 	case reflect.TypeFor[time.Duration]():
 		return JSONSchema{Type: "string", Format: "duration"}
+	case locationType:
+		return JSONSchema{Type: "string", Format: "timezone", Pattern: timeZonePattern}
+	case reflect.TypeFor[Locale]():
+		return JSONSchema{Type: "string", Format: "bcp47", Pattern: localePattern}
 	case reflect.TypeFor[Void]():
 		return JSONSchema{}
 	case reflect.TypeFor[FileUpload]():
 		return JSONSchema{Type: "string", Format: "binary"}
 	}
+	if s, ok := numericStringSchema(t); ok {
+		return s
+	}
 
 	//exhaustive:ignore
 	switch t.Kind() {
@@ -106,6 +123,17 @@ func typeToSchema(t reflect.Type) JSONSchema {
 	}
 }
 
+// namedStructSchema builds the component schema for a named struct,
+// applying SchemaTransformer if implemented.
+func (r *schemaRegistry) namedStructSchema(t reflect.Type) JSONSchema {
+	schema := r.structToSchema(t)
+	ptr := reflect.New(t)
+	if st, ok := ptr.Interface().(SchemaTransformer); ok {
+		schema = st.TransformSchema(schema)
+	}
+	return schema
+}
+
 // structToSchema converts a struct type to a JSONSchema with properties.
 func structToSchema(t reflect.Type) JSONSchema {
 	schema := JSONSchema{
@@ -135,12 +163,20 @@ func structToSchema(t reflect.Type) JSONSchema {
 		}
 
 		prop := typeToSchema(f.Type)
+		if ref := f.Tag.Get("schemaRef"); ref != "" {
+			prop = JSONSchema{Ref: schemaRefTarget(ref)}
+		}
 
 		if doc := f.Tag.Get("doc"); doc != "" {
 			prop.Description = doc
 		}
 
+		if f.Tag.Get("deprecated") == "true" {
+			prop.Deprecated = true
+		}
+
 		applyConstraintTags(&prop, f)
+		applyScopeTag(&prop, f)
 
 		schema.Properties[name] = prop
 
@@ -177,6 +213,12 @@ func isParamField(f reflect.StructField) bool {
 	return f.Tag.Get("form") != ""
 }
 
+// isFormPartField reports whether a form-tagged field is a structured
+// multipart part decoded by a codec (declared with a `content` tag).
+func isFormPartField(f reflect.StructField) bool {
+	return f.Tag.Get("form") != "" && f.Tag.Get("content") != ""
+}
+
 const errorSchemaName = "ProblemDetail"
 
 // errorResponseSchema returns the JSON Schema for RFC 9457 ProblemDetail.
@@ -211,12 +253,57 @@ func errorResponseSchema() JSONSchema {
 type schemaRegistry struct {
 	schemas map[reflect.Type]string
 	defs    map[string]JSONSchema
+
+	// timeFormat documents time.Time fields per WithTimeFormat.
+	timeFormat TimeFormat
+
+	// bigIntStrings documents big.Int fields as strings; see
+	// WithBigIntStrings.
+	bigIntStrings bool
+
+	// nameAnonymous registers anonymous response structs as components;
+	// see WithAnonymousResponseNames.
+	nameAnonymous bool
+
+	// anonymous maps the component names given to anonymous response
+	// bodies to their types.
+	anonymous map[string]reflect.Type
+
+	// generic holds the component names routes give generic types; see
+	// componentNamer.
+	generic map[reflect.Type]string
+
+	// declared maps the names of registered and generic components
+	// in defs to their types, and named maps the Go names of the
+	// other named structs in defs to theirs.
+	declared map[string]reflect.Type
+	named    map[string]reflect.Type
 }
 
 func newSchemaRegistry() *schemaRegistry {
 	return &schemaRegistry{
-		schemas: make(map[reflect.Type]string),
-		defs:    make(map[string]JSONSchema),
+		schemas:  make(map[reflect.Type]string),
+		defs:     make(map[string]JSONSchema),
+		declared: make(map[string]reflect.Type),
+		named:    make(map[string]reflect.Type),
+	}
+}
+
+// checkName panics when a registered or generic component and a struct
+// documented under its Go name share name but not type, as one schema
+// would silently replace the other.
+func (r *schemaRegistry) checkName(name string, t reflect.Type, declared bool) {
+	other := r.declared[name]
+	if declared {
+		other = r.named[name]
+	}
+	if other != nil && other != t {
+		panic(fmt.Sprintf("api: component %q names both %s and %s", name, other, t))
+	}
+	if declared {
+		r.declared[name] = t
+	} else {
+		r.named[name] = t
 	}
 }
 
@@ -226,16 +313,85 @@ func (r *schemaRegistry) typeToSchema(t reflect.Type) JSONSchema {
 		return r.typeToSchema(t.Elem())
 	}
 
+	// Registered components → register under their declared name.
+	c, ok := componentFor(t)
+	if !ok {
+		c.name, ok = r.generic[t]
+	}
+	if ok {
+		if _, exists := r.schemas[t]; !exists {
+			r.checkName(c.name, t, true)
+			r.schemas[t] = c.name
+			var schema JSONSchema
+			if t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(schemaProviderType) {
+				schema = r.namedStructSchema(t)
+			} else {
+				schema = r.unnamedSchema(t)
+			}
+			if c.description != "" {
+				schema.Description = c.description
+			}
+			r.defs[c.name] = schema
+		}
+		return JSONSchema{Ref: componentRefPrefix + c.name}
+	}
+	return r.unnamedSchema(t)
+}
+
+// nameGenerics collects the component names routes give generic types,
+// before any schema is built. It panics when two types claim one name.
+func (r *schemaRegistry) nameGenerics(routes []*routeInfo) {
+	owners := make(map[string]reflect.Type)
+	for _, ri := range routes {
+		for t, name := range ri.componentNames {
+			if _, ok := componentFor(t); ok {
+				continue
+			}
+			prev, ok := owners[name]
+			if !ok {
+				prev, ok = componentByName(name)
+			}
+			if ok && prev != t {
+				panic(fmt.Sprintf("api: component %q names both %s and %s", name, prev, t))
+			}
+			owners[name] = t
+			if r.generic == nil {
+				r.generic = make(map[reflect.Type]string)
+			}
+			r.generic[t] = name
+		}
+	}
+}
+
+// unnamedSchema is typeToSchema without the component lookup.
+func (r *schemaRegistry) unnamedSchema(t reflect.Type) JSONSchema {
 	// Well-known types — return directly, no registration.
 	switch t {
 	case reflect.TypeFor[time.Time]():
-		return JSONSchema{Type: "string", Format: "date-time"}
+		return r.timeFormat.schema()
+	case rawMessageType: // any JSON value, not a base64 []byte
+		return JSONSchema{}
 	case reflect.TypeFor[time.Duration]():
 		return JSONSchema{Type: "string", Format: "duration"}
+	case locationType:
+		return JSONSchema{Type: "string", Format: "timezone", Pattern: timeZonePattern}
+	case reflect.TypeFor[Locale]():
+		return JSONSchema{Type: "string", Format: "bcp47", Pattern: localePattern}
 	case reflect.TypeFor[Void]():
 		return JSONSchema{}
 	case reflect.TypeFor[FileUpload]():
 		return JSONSchema{Type: "string", Format: "binary"}
+	case reflect.TypeFor[big.Int]():
+		if !r.bigIntStrings {
+			return JSONSchema{Type: "integer"}
+		}
+	}
+	if s, ok := numericStringSchema(t); ok {
+		return s
+	}
+
+	if t.Kind() == reflect.Struct && t.Implements(oneOfType) {
+		return r.oneOfSchema(reflect.Zero(t).Interface().(oneOf).oneOfTypes())
 	}
 
 	// Check SchemaProvider interface.
@@ -279,17 +435,15 @@ func (r *schemaRegistry) typeToSchema(t reflect.Type) JSONSchema {
 		if t.Name() != "" {
 			name := t.Name()
 			if _, exists := r.schemas[t]; !exists {
+				if _, taken := r.anonymous[name]; taken {
+					panic(fmt.Sprintf("api: schema %q of %s is already the name of an anonymous response", name, t))
+				}
+				r.checkName(name, t, false)
 				// Register name before recursing to handle circular refs.
 				r.schemas[t] = name
-				schema := r.structToSchema(t)
-				// Apply SchemaTransformer if implemented.
-				ptr := reflect.New(t)
-				if st, ok := ptr.Interface().(SchemaTransformer); ok {
-					schema = st.TransformSchema(schema)
-				}
-				r.defs[name] = schema
+				r.defs[name] = r.namedStructSchema(t)
 			}
-			return JSONSchema{Ref: "#/components/schemas/" + name}
+			return JSONSchema{Ref: componentRefPrefix + name}
 		}
 		// Anonymous struct → inline.
 		return r.structToSchema(t)
@@ -326,13 +480,18 @@ func (r *schemaRegistry) structToSchema(t reflect.Type) JSONSchema {
 			continue
 		}
 
-		prop := r.typeToSchema(f.Type)
+		prop := r.fieldSchema(f)
 
 		if doc := f.Tag.Get("doc"); doc != "" {
 			prop.Description = doc
 		}
 
+		if f.Tag.Get("deprecated") == "true" {
+			prop.Deprecated = true
+		}
+
 		applyConstraintTags(&prop, f)
+		applyScopeTag(&prop, f)
 
 		schema.Properties[name] = prop
 
@@ -344,11 +503,66 @@ func (r *schemaRegistry) structToSchema(t reflect.Type) JSONSchema {
 	return schema
 }
 
+// fieldSchema returns the schema of a struct field: a $ref to the
+// component named by its schemaRef tag, or else the schema of its type.
+// schemaRef gives a json.RawMessage or any field, whose type says nothing
+// about its content, the schema of the payload it carries:
+//
+//	type Envelope struct {
+//	    Kind    string          `json:"kind"`
+//	    Payload json.RawMessage `json:"payload" schemaRef:"Order"`
+//	}
+//
+// The tag holds a component name or a full #/components/schemas/ reference.
+// A name registered with RegisterComponent brings its type's schema into
+// the spec; any other name must be documented by another operation.
+func (r *schemaRegistry) fieldSchema(f reflect.StructField) JSONSchema {
+	ref := f.Tag.Get("schemaRef")
+	if ref == "" {
+		return r.typeToSchema(f.Type)
+	}
+	target := schemaRefTarget(ref)
+	if t, ok := componentByName(strings.TrimPrefix(target, componentRefPrefix)); ok {
+		r.typeToSchema(t)
+	}
+	return JSONSchema{Ref: target}
+}
+
+const componentRefPrefix = "#/components/schemas/"
+
+// schemaRefTarget expands a schemaRef tag holding a bare component name to
+// a reference.
+func schemaRefTarget(ref string) string {
+	if strings.HasPrefix(ref, "#") {
+		return ref
+	}
+	return componentRefPrefix + ref
+}
+
+// applyScopeTag records a field's scope tag as the x-required-scopes
+// extension so clients can tell which properties may be filtered out.
+func applyScopeTag(schema *JSONSchema, f reflect.StructField) {
+	v := f.Tag.Get("scope")
+	if v == "" {
+		return
+	}
+	var scopes []string
+	for s := range strings.SplitSeq(v, ",") {
+		scopes = append(scopes, strings.TrimSpace(s))
+	}
+	if schema.Extensions == nil {
+		schema.Extensions = map[string]any{}
+	}
+	schema.Extensions["x-required-scopes"] = scopes
+}
+
 // SchemaProvider is implemented by types that control their own JSON Schema.
 type SchemaProvider interface {
 	JSONSchema() JSONSchema
 }
 
+var schemaProviderType = reflect.TypeFor[SchemaProvider]()
+
 // SchemaTransformer is implemented by types that modify the auto-generated schema.
 type SchemaTransformer interface {
 	TransformSchema(s JSONSchema) JSONSchema
@@ -379,14 +593,18 @@ func applyConstraintTags(schema *JSONSchema, f reflect.StructField) {
 	if v := f.Tag.Get("pattern"); v != "" {
 		schema.Pattern = v
 	}
+	minItems, maxItems := &schema.MinItems, &schema.MaxItems
+	if derefType(f.Type).Kind() == reflect.Map {
+		minItems, maxItems = &schema.MinProperties, &schema.MaxProperties
+	}
 	if v := f.Tag.Get("minItems"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil {
-			schema.MinItems = &n
+			*minItems = &n
 		}
 	}
 	if v := f.Tag.Get("maxItems"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil {
-			schema.MaxItems = &n
+			*maxItems = &n
 		}
 	}
 	if v := f.Tag.Get("enum"); v != "" {
@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
 	"time"
@@ -267,6 +268,10 @@ func TestTypeToSchema_additional_kinds(t *testing.T) {
 			typ:    reflect.TypeFor[any](),
 			expect: api.JSONSchema{},
 		},
+		"json.RawMessage": {
+			typ:    reflect.TypeFor[json.RawMessage](),
+			expect: api.JSONSchema{},
+		},
 		"uint": {
 			typ:    reflect.TypeFor[uint](),
 			expect: api.JSONSchema{Type: "integer"},
@@ -27,7 +27,7 @@ func Secure(cfg ...SecureConfig) Middleware {
 		c = cfg[0]
 	}
 
-	return func(next http.Handler) http.Handler {
+	return Named("secure", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if c.ContentTypeNosniff {
 				w.Header().Set("X-Content-Type-Options", "nosniff")
@@ -47,5 +47,5 @@ func Secure(cfg ...SecureConfig) Middleware {
 
 			next.ServeHTTP(w, r)
 		})
-	}
+	})
 }
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidCookie is returned when a secure cookie fails signature
+// verification or decryption.
+var ErrInvalidCookie = errors.New("invalid secure cookie")
+
+// SecureCookies signs (and optionally encrypts) cookie values. Values are
+// bound to the cookie name, so a value minted for one cookie cannot be
+// replayed under another.
+//
+// Request fields opt in with the secure tag option:
+//
+//	Session string `cookie:"session,secure"`
+type SecureCookies struct {
+	hashKey []byte
+	aead    cipher.AEAD
+}
+
+// NewSecureCookies creates a SecureCookies codec. hashKey is required and
+// should be at least 32 random bytes. blockKey is optional; when non-nil it
+// must be 16, 24, or 32 bytes and enables AES-GCM encryption of values.
+func NewSecureCookies(hashKey, blockKey []byte) (*SecureCookies, error) {
+	if len(hashKey) == 0 {
+		return nil, errors.New("secure cookies: hash key is required")
+	}
+	sc := &SecureCookies{hashKey: hashKey}
+	if blockKey != nil {
+		block, err := aes.NewCipher(blockKey)
+		if err != nil {
+			return nil, fmt.Errorf("secure cookies: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("secure cookies: %w", err)
+		}
+		sc.aead = aead
+	}
+	return sc, nil
+}
+
+// WithSecureCookies installs the codec used to decode request cookies
+// tagged with the secure option.
+func WithSecureCookies(sc *SecureCookies) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.secureCookies = sc
+	})
+}
+
+// Encode signs (and encrypts, if configured) value for the named cookie.
+func (s *SecureCookies) Encode(name, value string) (string, error) {
+	data := []byte(value)
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		data = s.aead.Seal(nonce, nonce, data, []byte(name))
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(name, payload)), nil
+}
+
+// Decode verifies (and decrypts, if configured) an encoded cookie value.
+// Returns ErrInvalidCookie when the value was tampered with, minted for a
+// different cookie name, or produced with different keys.
+func (s *SecureCookies) Decode(name, encoded string) (string, error) {
+	payload, sig, ok := strings.Cut(encoded, ".")
+	if !ok {
+		return "", ErrInvalidCookie
+	}
+	gotMAC, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotMAC, s.mac(name, payload)) {
+		return "", ErrInvalidCookie
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	if s.aead != nil {
+		n := s.aead.NonceSize()
+		if len(data) < n {
+			return "", ErrInvalidCookie
+		}
+		data, err = s.aead.Open(nil, data[:n], data[n:], []byte(name))
+		if err != nil {
+			return "", ErrInvalidCookie
+		}
+	}
+	return string(data), nil
+}
+
+func (s *SecureCookies) mac(name, payload string) []byte {
+	h := hmac.New(sha256.New, s.hashKey)
+	h.Write([]byte(name))
+	h.Write([]byte{'|'})
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
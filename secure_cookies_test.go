@@ -0,0 +1,73 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func TestSecureCookies_roundtrip(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		blockKey []byte
+	}{
+		"signed only":          {},
+		"signed and encrypted": {blockKey: []byte("0123456789abcdef0123456789abcdef")},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sc, err := api.NewSecureCookies([]byte("hash-key-hash-key-hash-key-hash!"), tc.blockKey)
+			require.NoError(t, err)
+
+			enc, err := sc.Encode("session", "user-42")
+			require.NoError(t, err)
+			if tc.blockKey != nil {
+				assert.NotContains(t, enc, "user-42")
+			}
+
+			got, err := sc.Decode("session", enc)
+			require.NoError(t, err)
+			assert.Equal(t, "user-42", got)
+
+			_, err = sc.Decode("other", enc)
+			require.ErrorIs(t, err, api.ErrInvalidCookie)
+
+			_, err = sc.Decode("session", enc+"x")
+			require.ErrorIs(t, err, api.ErrInvalidCookie)
+
+			_, err = sc.Decode("session", "garbage")
+			require.ErrorIs(t, err, api.ErrInvalidCookie)
+		})
+	}
+}
+
+func TestSecureCookies_different_keys(t *testing.T) {
+	t.Parallel()
+
+	a, err := api.NewSecureCookies([]byte("key-a"), nil)
+	require.NoError(t, err)
+	b, err := api.NewSecureCookies([]byte("key-b"), nil)
+	require.NoError(t, err)
+
+	enc, err := a.Encode("session", "v")
+	require.NoError(t, err)
+	_, err = b.Decode("session", enc)
+	require.ErrorIs(t, err, api.ErrInvalidCookie)
+}
+
+func TestNewSecureCookies_invalid_keys(t *testing.T) {
+	t.Parallel()
+
+	_, err := api.NewSecureCookies(nil, nil)
+	require.Error(t, err)
+
+	_, err = api.NewSecureCookies([]byte("k"), []byte("short"))
+	require.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package api
+
+import "sort"
+
+// SecurityCoverage reports gaps between the router's declared security
+// schemes and the requirements attached to its routes. Run it in CI or at
+// startup to catch auth misconfiguration before deploy.
+type SecurityCoverage struct {
+	// Unsecured lists routes with no security requirement at all — neither
+	// their own, their group's, nor the router's global requirement.
+	Unsecured []RouteDescription
+
+	// Public lists routes that explicitly opted out via WithNoSecurity.
+	// These are reported separately since they are usually intentional.
+	Public []RouteDescription
+
+	// UnusedScopes maps each OAuth2 scheme to the scopes its flows declare
+	// but no route requires. Scope lists are sorted.
+	UnusedScopes map[string][]string
+
+	// UndeclaredScopes maps each OAuth2 scheme to the scopes routes require
+	// but the scheme's flows never declare. Scope lists are sorted.
+	UndeclaredScopes map[string][]string
+
+	// UnregisteredSchemes lists scheme names referenced by routes or global
+	// security but never registered via WithSecurityScheme. Sorted.
+	UnregisteredSchemes []string
+}
+
+// OK reports whether the coverage report found no problems. Public routes
+// do not count as problems.
+func (c SecurityCoverage) OK() bool {
+	return len(c.Unsecured) == 0 &&
+		len(c.UnusedScopes) == 0 &&
+		len(c.UndeclaredScopes) == 0 &&
+		len(c.UnregisteredSchemes) == 0
+}
+
+// SecurityCoverage analyzes the registered routes against the router's
+// security schemes. Raw routes are included; routes mounted directly on
+// the mux (ServeSpec, Static, Pprof) are not tracked and are not reported.
+func (r *Router) SecurityCoverage() SecurityCoverage {
+	routes := r.Routes()
+
+	var cov SecurityCoverage
+	used := map[string]map[string]struct{}{}
+	unregistered := map[string]struct{}{}
+
+	for _, name := range r.security {
+		if _, ok := r.securitySchemes[name]; !ok {
+			unregistered[name] = struct{}{}
+		}
+	}
+
+	for _, rd := range routes {
+		switch {
+		case rd.NoSecurity:
+			cov.Public = append(cov.Public, rd)
+			continue
+		case len(rd.Security) == 0:
+			cov.Unsecured = append(cov.Unsecured, rd)
+			continue
+		}
+		for _, name := range rd.Security {
+			if _, ok := r.securitySchemes[name]; !ok {
+				unregistered[name] = struct{}{}
+			}
+		}
+		for name, scopes := range rd.Scopes {
+			if used[name] == nil {
+				used[name] = map[string]struct{}{}
+			}
+			for _, s := range scopes {
+				used[name][s] = struct{}{}
+			}
+		}
+	}
+
+	for name, scheme := range r.securitySchemes {
+		declared := scheme.declaredScopes()
+		if unused := missingFrom(declared, used[name]); len(unused) > 0 {
+			if cov.UnusedScopes == nil {
+				cov.UnusedScopes = map[string][]string{}
+			}
+			cov.UnusedScopes[name] = unused
+		}
+		if scheme.Type != "oauth2" {
+			// Only OAuth2 flows enumerate their scopes in the spec.
+			continue
+		}
+		if undeclared := missingFrom(used[name], declared); len(undeclared) > 0 {
+			if cov.UndeclaredScopes == nil {
+				cov.UndeclaredScopes = map[string][]string{}
+			}
+			cov.UndeclaredScopes[name] = undeclared
+		}
+	}
+
+	for name := range unregistered {
+		cov.UnregisteredSchemes = append(cov.UnregisteredSchemes, name)
+	}
+	sort.Strings(cov.UnregisteredSchemes)
+
+	return cov
+}
+
+// missingFrom returns the sorted members of a that are absent from b.
+func missingFrom(a, b map[string]struct{}) []string {
+	var out []string
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
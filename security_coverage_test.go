@@ -0,0 +1,112 @@
+package api_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func voidHandler(_ context.Context, _ *api.Void) (*api.Void, error) {
+	return &api.Void{}, nil
+}
+
+func TestSecurityCoverage(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(
+		api.WithSecurityScheme("oauth", api.OAuth2ClientCredentials("https://a/token", map[string]string{
+			"read":  "Read",
+			"write": "Write",
+			"admin": "Admin",
+		})),
+		api.WithSecurityScheme("bearer", api.SecurityScheme{Type: "http", Scheme: "bearer"}),
+	)
+
+	api.Get(r, "/open", voidHandler)
+	api.Get(r, "/health", voidHandler, api.WithNoSecurity())
+	api.Get(r, "/items", voidHandler, api.WithScopes("oauth", "read"))
+	api.Post(r, "/items", voidHandler, api.WithScopes("oauth", "write", "delete"))
+	api.Get(r, "/legacy", voidHandler, api.WithSecurity("apiKey"))
+	api.Get(r, "/me", voidHandler, api.WithScopes("bearer", "profile"))
+
+	cov := r.SecurityCoverage()
+	assert.False(t, cov.OK())
+
+	require.Len(t, cov.Unsecured, 1)
+	assert.Equal(t, "/open", cov.Unsecured[0].Pattern)
+
+	require.Len(t, cov.Public, 1)
+	assert.Equal(t, "/health", cov.Public[0].Pattern)
+
+	assert.Equal(t, map[string][]string{"oauth": {"admin"}}, cov.UnusedScopes)
+	assert.Equal(t, map[string][]string{"oauth": {"delete"}}, cov.UndeclaredScopes)
+	assert.Equal(t, []string{"apiKey"}, cov.UnregisteredSchemes)
+}
+
+func TestSecurityCoverage_ok(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(
+		api.WithSecurityScheme("oauth", api.OAuth2ClientCredentials("https://a/token", map[string]string{
+			"read": "Read",
+		})),
+		api.WithGlobalSecurity("oauth"),
+	)
+
+	api.Get(r, "/items", voidHandler, api.WithScopes("oauth", "read"))
+	api.Get(r, "/other", voidHandler)
+	api.Get(r, "/health", voidHandler, api.WithNoSecurity())
+
+	cov := r.SecurityCoverage()
+	assert.True(t, cov.OK())
+	assert.Empty(t, cov.Unsecured)
+	assert.Len(t, cov.Public, 1)
+}
+
+func TestSecurityCoverage_unregistered_global(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.WithGlobalSecurity("missing"))
+	cov := r.SecurityCoverage()
+	assert.Equal(t, []string{"missing"}, cov.UnregisteredSchemes)
+}
+
+func TestRoutes(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.WithGlobalSecurity("bearer"))
+	g := r.Group("/v1", api.WithGroupTags("v1"))
+	api.Get(g, "/users/{id}", voidHandler, api.WithSummary("Get user"), api.WithDeprecated())
+	api.Post(r, "/login", voidHandler, api.WithNoSecurity(), api.WithOperationID("login"))
+
+	routes := r.Routes()
+	require.Len(t, routes, 2)
+
+	assert.Equal(t, "GET", routes[0].Method)
+	assert.Equal(t, "/v1/users/{id}", routes[0].Pattern)
+	assert.Equal(t, "getV1UsersById", routes[0].OperationID)
+	assert.Equal(t, "Get user", routes[0].Summary)
+	assert.Equal(t, []string{"v1"}, routes[0].Tags)
+	assert.True(t, routes[0].Deprecated)
+	assert.Equal(t, []string{"bearer"}, routes[0].Security)
+
+	assert.Equal(t, "login", routes[1].OperationID)
+	assert.True(t, routes[1].NoSecurity)
+	assert.Empty(t, routes[1].Security)
+}
+
+func TestWithScopes_spec(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/items", voidHandler, api.WithScopes("oauth", "read", "list"))
+
+	op := r.Spec().Paths["/items"]["get"]
+	require.NotNil(t, op.Security)
+	require.Len(t, *op.Security, 1)
+	assert.Equal(t, []string{"read", "list"}, (*op.Security)[0]["oauth"])
+}
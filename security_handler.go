@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+// SecurityHandler authenticates a request for one security scheme. It
+// returns the context the route runs with, typically carrying the
+// principal, or an error to reject the request.
+type SecurityHandler func(ctx context.Context, r *http.Request) (context.Context, error)
+
+// WithSecurityHandler enforces the named security scheme: every route that
+// requires it, through WithSecurity, WithGroupSecurity, or
+// WithGlobalSecurity, runs h before its group and route middleware:
+//
+//	r := api.New(
+//	    api.WithSecurityScheme("bearerAuth", api.SecurityScheme{Type: "http", Scheme: "bearer"}),
+//	    api.WithSecurityHandler("bearerAuth", func(ctx context.Context, r *http.Request) (context.Context, error) {
+//	        p, err := verify(ctx, r.Header.Get("Authorization"))
+//	        if err != nil {
+//	            return nil, err
+//	        }
+//	        return api.SetPrincipal(r, p).Context(), nil
+//	    }),
+//	)
+//
+// An error that is not an *Err is answered with 401. When the route lists
+// scopes for the scheme with WithScopes, a principal lacking any of them is
+// answered with 403. A route requiring several schemes accepts a request
+// any of them authenticates, as in OpenAPI; schemes without a handler stay
+// documentation only and are skipped. Routes with WithNoSecurity are never
+// checked. Register handlers before the routes they protect.
+func WithSecurityHandler(name string, h SecurityHandler) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		if r.securityHandlers == nil {
+			r.securityHandlers = make(map[string]SecurityHandler)
+		}
+		r.securityHandlers[name] = h
+	})
+}
+
+// securityCheck is one scheme a route enforces.
+type securityCheck struct {
+	scheme  string
+	handler SecurityHandler
+	scopes  []string
+}
+
+// enforceSecurity wraps the handler of a route requiring schemes with a
+// registered SecurityHandler.
+func (r *Router) enforceSecurity(ri *routeInfo, desc RouteDescription) {
+	var checks []securityCheck
+	for _, name := range desc.Security {
+		if h, ok := r.securityHandlers[name]; ok {
+			checks = append(checks, securityCheck{scheme: name, handler: h, scopes: ri.scopes[name]})
+		}
+	}
+	if len(checks) == 0 {
+		return
+	}
+	next := ri.handler
+	ri.attachesRouter = false
+	ri.handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var first error
+		for _, c := range checks {
+			ctx, err := c.authenticate(req)
+			if err == nil {
+				next.ServeHTTP(w, req.WithContext(ctx))
+				return
+			}
+			if first == nil {
+				first = err
+			}
+		}
+		WriteError(w, req, first)
+	})
+}
+
+// authenticate runs the scheme's handler and checks the required scopes.
+func (c securityCheck) authenticate(r *http.Request) (context.Context, error) {
+	ctx, err := c.handler(r.Context(), r)
+	if err != nil {
+		var apiErr *Err
+		if errors.As(err, &apiErr) {
+			return nil, err
+		}
+		return nil, Error(CodeUnauthorized, WithMessage(err.Error()), WithCause(err))
+	}
+	if ctx == nil {
+		ctx = r.Context()
+	}
+	if len(c.scopes) == 0 {
+		return ctx, nil
+	}
+	p, _ := GetPrincipal(ctx)
+	for _, scope := range c.scopes {
+		if p == nil || !slices.Contains(p.Scopes, scope) {
+			return nil, Error(CodeForbidden, WithMessagef("%s requires scope %q", c.scheme, scope))
+		}
+	}
+	return ctx, nil
+}
@@ -0,0 +1,125 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func TestWithSecurityHandler(t *testing.T) {
+	t.Parallel()
+
+	bearer := func(_ context.Context, r *http.Request) (context.Context, error) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			return nil, errors.New("missing bearer token")
+		}
+		id, scopes, _ := strings.Cut(token, ":")
+		return api.SetPrincipal(r, &api.Principal{ID: id, Scopes: strings.Fields(scopes)}).Context(), nil
+	}
+	apiKey := func(ctx context.Context, r *http.Request) (context.Context, error) {
+		if r.Header.Get("X-API-Key") != "secret" {
+			return nil, api.Error(api.CodeForbidden, api.WithMessage("unknown key"))
+		}
+		return ctx, nil
+	}
+
+	r := api.New(
+		api.WithSecurityScheme("bearerAuth", api.SecurityScheme{Type: "http", Scheme: "bearer"}),
+		api.WithSecurityScheme("apiKey", api.SecurityScheme{Type: "apiKey", Name: "X-API-Key", In: "header"}),
+		api.WithGlobalSecurity("bearerAuth"),
+		api.WithSecurityHandler("bearerAuth", bearer),
+		api.WithSecurityHandler("apiKey", apiKey),
+	)
+	whoami := func(ctx context.Context, _ *api.Void) (*api.Resp[string], error) {
+		p, ok := api.GetPrincipal(ctx)
+		if !ok {
+			return &api.Resp[string]{Body: "anonymous"}, nil
+		}
+		return &api.Resp[string]{Body: p.ID}, nil
+	}
+	api.Get(r, "/me", whoami)
+	api.Get(r, "/health", whoami, api.WithNoSecurity())
+	api.Get(r, "/reports", whoami, api.WithScopes("bearerAuth", "reports:read"))
+	machine := r.Group("/machine", api.WithGroupSecurity("apiKey", "bearerAuth"))
+	api.Get(machine, "/sync", whoami)
+
+	tests := map[string]struct {
+		path       string
+		header     http.Header
+		wantStatus int
+		wantBody   string
+	}{
+		"global scheme": {
+			path:       "/me",
+			header:     http.Header{"Authorization": {"Bearer ada"}},
+			wantStatus: http.StatusOK,
+			wantBody:   "ada",
+		},
+		"missing credentials": {
+			path:       "/me",
+			wantStatus: http.StatusUnauthorized,
+		},
+		"no security": {
+			path:       "/health",
+			wantStatus: http.StatusOK,
+			wantBody:   "anonymous",
+		},
+		"scope granted": {
+			path:       "/reports",
+			header:     http.Header{"Authorization": {"Bearer ada:reports:read"}},
+			wantStatus: http.StatusOK,
+			wantBody:   "ada",
+		},
+		"scope missing": {
+			path:       "/reports",
+			header:     http.Header{"Authorization": {"Bearer ada"}},
+			wantStatus: http.StatusForbidden,
+		},
+		"any scheme, first": {
+			path:       "/machine/sync",
+			header:     http.Header{"X-Api-Key": {"secret"}},
+			wantStatus: http.StatusOK,
+			wantBody:   "anonymous",
+		},
+		"any scheme, second": {
+			path:       "/machine/sync",
+			header:     http.Header{"Authorization": {"Bearer ada"}},
+			wantStatus: http.StatusOK,
+			wantBody:   "ada",
+		},
+		"all schemes fail reports the first": {
+			path:       "/machine/sync",
+			header:     http.Header{"X-Api-Key": {"wrong"}},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, http.NoBody)
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantStatus, w.Code, w.Body.String())
+			if tc.wantStatus != http.StatusOK {
+				assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+				return
+			}
+			assert.Contains(t, w.Body.String(), tc.wantBody)
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ndjsonContentType is the media type of newline-delimited JSON.
+const ndjsonContentType = "application/x-ndjson"
+
+var errorType = reflect.TypeFor[error]()
+
+// seqElem reports whether t is an iterator, iter.Seq[T] or
+// iter.Seq2[T, error], returning T and whether it carries errors. Any func
+// type of the same shape qualifies.
+func seqElem(t reflect.Type) (elem reflect.Type, withErr, ok bool) {
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+		return nil, false, false
+	}
+	yield := t.In(0)
+	if yield.Kind() != reflect.Func || yield.NumOut() != 1 || yield.Out(0).Kind() != reflect.Bool {
+		return nil, false, false
+	}
+	switch {
+	case yield.NumIn() == 1:
+		return yield.In(0), false, true
+	case yield.NumIn() == 2 && yield.In(1) == errorType:
+		return yield.In(0), true, true
+	}
+	return nil, false, false
+}
+
+// rangeSeq calls fn for each value the iterator in seq yields, stopping
+// when fn returns false. err is the value's error for iter.Seq2[T, error].
+func rangeSeq(seq reflect.Value, fn func(item reflect.Value, err error) bool) {
+	if seq.IsNil() {
+		return
+	}
+	_, withErr, _ := seqElem(seq.Type())
+	yield := reflect.MakeFunc(seq.Type().In(0), func(args []reflect.Value) []reflect.Value {
+		var err error
+		if withErr {
+			err, _ = args[1].Interface().(error) //nolint:errcheck // nil when no error
+		}
+		return []reflect.Value{reflect.ValueOf(fn(args[0], err))}
+	})
+	seq.Call([]reflect.Value{yield})
+}
+
+// writeSeqBody writes an iter.Seq[T] or iter.Seq2[T, error] body as a JSON
+// array, or as NDJSON when the client prefers application/x-ndjson. Values
+// are encoded as the iterator yields them and flushed one by one, or per
+// the route's flush interval. An error before the first value is written
+// as the route's error response; after it, the stream is cut short,
+// leaving a JSON array unterminated.
+func writeSeqBody(w http.ResponseWriter, r *http.Request, bv reflect.Value, status int, cfg *handlerConfig) {
+	ctx := r.Context()
+	ndjson := prefersNDJSON(r.Header.Get("Accept"))
+	elem, _, _ := seqElem(bv.Type())
+	ff, filter := newFieldFilter(ctx, cfg.redaction, cfg.fieldScopes)
+	filter = filter && typeHasFieldPolicy(elem)
+	flusher := newStreamFlusher(w, cfg.flushInterval)
+
+	started := false
+	start := func() {
+		started = true
+		if ndjson {
+			w.Header().Set("Content-Type", ndjsonContentType)
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		cfg.codecs.protection.setNoSniff(w.Header())
+		w.WriteHeader(status)
+		if ndjson {
+			return
+		}
+		if p := cfg.codecs.protection; p != nil && p.JSONArrays == JSONArrayPrefix {
+			//nolint:errcheck,gosec // best-effort after WriteHeader
+			io.WriteString(w, p.Prefix)
+		}
+		//nolint:errcheck,gosec // best-effort streaming writes
+		io.WriteString(w, "[")
+	}
+
+	var failed error
+	rangeSeq(bv, func(item reflect.Value, err error) bool {
+		if err != nil {
+			failed = err
+			return false
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		if filter {
+			item = filterFields(item, ff)
+		}
+		b, err := cfg.codecs.marshalJSON(item.Interface())
+		if err != nil {
+			failed = err
+			return false
+		}
+		switch {
+		case !started:
+			start()
+		case !ndjson:
+			//nolint:errcheck,gosec // best-effort streaming writes
+			io.WriteString(w, ",")
+		}
+		//nolint:errcheck,gosec // best-effort streaming writes
+		w.Write(b)
+		if ndjson {
+			//nolint:errcheck,gosec // best-effort streaming writes
+			io.WriteString(w, "\n")
+		}
+		flusher.written()
+		return true
+	})
+
+	switch {
+	case failed != nil && !started:
+		cfg.writeErr(w, r, failed)
+	case failed != nil || ctx.Err() != nil:
+		// Client gone or the iterator failed mid-stream; an unterminated
+		// array signals the failure.
+	default:
+		if !started {
+			start()
+		}
+		if !ndjson {
+			//nolint:errcheck,gosec // best-effort streaming writes
+			io.WriteString(w, "]\n")
+		}
+	}
+}
+
+// writeEventSeqBody writes an iter.Seq[Event] or iter.Seq2[Event, error]
+// body as server-sent events, flushing after each. An error before the
+// first event is written as the route's error response; after it, the
+// stream ends.
+func writeEventSeqBody(w http.ResponseWriter, r *http.Request, bv reflect.Value, status int, cfg *handlerConfig) {
+	ctx, cancel := streamContext(r.Context(), cfg.router)
+	defer cancel()
+
+	var ew *eventWriter
+	defer func() {
+		if ew != nil {
+			ew.close()
+		}
+	}()
+	var failed error
+	rangeSeq(bv, func(item reflect.Value, err error) bool {
+		if err != nil {
+			failed = err
+			return false
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		if ew == nil {
+			writeEventStreamHeader(w, status)
+			ew = newEventWriter(w, cfg)
+		}
+		ew.send(item.Interface().(Event)) //nolint:errcheck,forcetypeassert // descriptor guarantees Event
+		return true
+	})
+
+	if failed != nil && ew == nil {
+		cfg.writeErr(w, r, failed)
+		return
+	}
+	if ew == nil && ctx.Err() == nil {
+		writeEventStreamHeader(w, status)
+	}
+	if ew != nil {
+		ew.end(ctx)
+	}
+}
+
+// writeEventStreamHeader starts a text/event-stream response.
+func writeEventStreamHeader(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(status)
+}
+
+// prefersNDJSON reports whether accept asks for NDJSON at least as much as
+// for JSON.
+func prefersNDJSON(accept string) bool {
+	q := acceptQuality(accept, ndjsonContentType)
+	return q > 0 && q >= acceptQuality(accept, "application/json")
+}
+
+// acceptQuality returns the quality accept gives mediaType when it names
+// it explicitly, or -1.
+func acceptQuality(accept, mediaType string) float64 {
+	for part := range strings.SplitSeq(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mt != mediaType {
+			continue
+		}
+		if qs, ok := params["q"]; ok {
+			if q, err := strconv.ParseFloat(qs, 64); err == nil {
+				return q
+			}
+		}
+		return 1
+	}
+	return -1
+}
+
+// streamsAccepted reports whether the response streams a media type that
+// accept names, which codec negotiation does not know about. File bodies
+// always qualify, since their type is known only when served.
+func (d *responseDescriptor) streamsAccepted(accept string) bool {
+	if d == nil || d.body == nil {
+		return false
+	}
+	//exhaustive:ignore
+	switch d.body.kind {
+	case bodyKindChan, bodyKindEventSeq:
+		return acceptQuality(accept, "text/event-stream") > 0
+	case bodyKindSeq, bodyKindNDJSON:
+		return acceptQuality(accept, ndjsonContentType) > 0
+	case bodyKindFile:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,306 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+// seqWithErr yields items, then err when it is non-nil.
+func seqWithErr(err error, items ...jsItem) iter.Seq2[jsItem, error] {
+	return func(yield func(jsItem, error) bool) {
+		for _, it := range items {
+			if !yield(it, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(jsItem{}, err)
+		}
+	}
+}
+
+func TestSeq(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		accept string
+		body   iter.Seq[jsItem]
+		wantCT string
+		want   string
+	}{
+		"json array": {
+			body:   slices.Values([]jsItem{{ID: 1}, {ID: 2}}),
+			wantCT: "application/json",
+			want:   `[{"id":1},{"id":2}]` + "\n",
+		},
+		"empty": {
+			body:   slices.Values([]jsItem(nil)),
+			wantCT: "application/json",
+			want:   "[]\n",
+		},
+		"nil iterator": {
+			wantCT: "application/json",
+			want:   "[]\n",
+		},
+		"ndjson": {
+			accept: "application/x-ndjson",
+			body:   slices.Values([]jsItem{{ID: 1}, {ID: 2}}),
+			wantCT: "application/x-ndjson",
+			want:   `{"id":1}` + "\n" + `{"id":2}` + "\n",
+		},
+		"json preferred over ndjson": {
+			accept: "application/x-ndjson;q=0.5, application/json",
+			body:   slices.Values([]jsItem{{ID: 1}}),
+			wantCT: "application/json",
+			want:   `[{"id":1}]` + "\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New()
+			api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[iter.Seq[jsItem]], error) {
+				return &api.Resp[iter.Seq[jsItem]]{Body: tt.body}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/items", http.NoBody)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantCT, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.want, w.Body.String())
+		})
+	}
+}
+
+func TestSeq2_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		body       iter.Seq2[jsItem, error]
+		wantStatus int
+		want       string
+	}{
+		"no error": {
+			body:       seqWithErr(nil, jsItem{ID: 1}),
+			wantStatus: http.StatusOK,
+			want:       `[{"id":1}]` + "\n",
+		},
+		"error before first item": {
+			body:       seqWithErr(api.Error(api.CodeNotFound)),
+			wantStatus: http.StatusNotFound,
+		},
+		"error mid-stream": {
+			body:       seqWithErr(errors.New("db gone"), jsItem{ID: 1}),
+			wantStatus: http.StatusOK,
+			want:       `[{"id":1}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New()
+			api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[iter.Seq2[jsItem, error]], error) {
+				return &api.Resp[iter.Seq2[jsItem, error]]{Body: tt.body}, nil
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", http.NoBody))
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.want, w.Body.String())
+			} else {
+				assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestSeq_stops_when_client_goes_away(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	yielded := 0
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[iter.Seq[jsItem]], error) {
+		return &api.Resp[iter.Seq[jsItem]]{Body: func(yield func(jsItem) bool) {
+			for i := 0; ; i++ {
+				yielded++
+				if i == 2 {
+					cancel()
+				}
+				if !yield(jsItem{ID: i}) {
+					return
+				}
+			}
+		}}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", http.NoBody).WithContext(ctx))
+
+	assert.Equal(t, 3, yielded)
+	assert.Equal(t, `[{"id":0},{"id":1}`, w.Body.String())
+}
+
+func TestSeq_redaction(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.WithRedactionPolicy(func(context.Context, string) bool { return false }))
+	api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[iter.Seq[jsItem]], error) {
+		return &api.Resp[iter.Seq[jsItem]]{Body: slices.Values([]jsItem{{ID: 1, Secret: "s"}})}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", http.NoBody))
+
+	assert.Equal(t, `[{"id":1,"secret":"[REDACTED]"}]`+"\n", w.Body.String())
+}
+
+func TestSeq_events(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		body       iter.Seq2[api.Event, error]
+		wantStatus int
+		want       string
+	}{
+		"events": {
+			body: func(yield func(api.Event, error) bool) {
+				_ = yield(api.Event{Name: "tick", Data: "1"}, nil) && yield(api.Event{Name: "tick", Data: "2"}, nil)
+			},
+			wantStatus: http.StatusOK,
+			want:       "event: tick\ndata: 1\n\nevent: tick\ndata: 2\n\n",
+		},
+		"error before first event": {
+			body: func(yield func(api.Event, error) bool) {
+				yield(api.Event{}, api.Error(api.CodeForbidden))
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New()
+			api.Get(r, "/events", func(_ context.Context, _ *api.Void) (*api.Resp[iter.Seq2[api.Event, error]], error) {
+				return &api.Resp[iter.Seq2[api.Event, error]]{Body: tt.body}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/events", http.NoBody)
+			req.Header.Set("Accept", "text/event-stream")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSeq_not_acceptable(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[iter.Seq[jsItem]], error) {
+		return &api.Resp[iter.Seq[jsItem]]{}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items", http.NoBody)
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+}
+
+func TestSeq_spec(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[iter.Seq2[jsItem, error]], error) {
+		return nil, nil
+	})
+	api.Get(r, "/events", func(_ context.Context, _ *api.Void) (*api.Resp[iter.Seq[api.Event]], error) {
+		return nil, nil
+	})
+
+	spec := r.Spec()
+
+	items := spec.Paths["/items"]["get"].Responses["200"].Content
+	require.Contains(t, items, "application/json")
+	assert.Equal(t, "array", items["application/json"].Schema.Type)
+	require.Contains(t, items, "application/x-ndjson")
+	assert.NotEqual(t, "array", items["application/x-ndjson"].Schema.Type)
+
+	events := spec.Paths["/events"]["get"].Responses["200"].Content
+	assert.Contains(t, events, "text/event-stream")
+}
+
+// flushCounter counts the flushes of a streamed response.
+type flushCounter struct {
+	*httptest.ResponseRecorder
+	flushes int
+}
+
+func (f *flushCounter) Flush() {
+	f.flushes++
+	f.ResponseRecorder.Flush()
+}
+
+func TestSeq_flush_interval(t *testing.T) {
+	t.Parallel()
+
+	items := make([]jsItem, 50)
+	tests := map[string]struct {
+		opts        []api.RouteOption
+		wantFlushes int
+	}{
+		"flush per value": {wantFlushes: len(items)},
+		"interval":        {opts: []api.RouteOption{api.WithFlushInterval(time.Hour)}, wantFlushes: 0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New()
+			api.Get(r, "/items", func(_ context.Context, _ *api.Void) (*api.Resp[iter.Seq[jsItem]], error) {
+				return &api.Resp[iter.Seq[jsItem]]{Body: slices.Values(items)}, nil
+			}, tc.opts...)
+
+			w := &flushCounter{ResponseRecorder: httptest.NewRecorder()}
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", http.NoBody))
+
+			assert.Equal(t, tc.wantFlushes, w.flushes)
+			assert.Equal(t, len(items), strings.Count(w.Body.String(), `{"id":0}`))
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// Session exposes server-side session values to request binding. Session
+// middleware loads the session and stores it with SetSession; request
+// fields tagged `session:"key"` are then bound from it:
+//
+//	type CheckoutReq struct {
+//	    CartID string `session:"cart_id" required:"true"`
+//	    UserID string `claim:"sub" required:"true"`
+//	}
+type Session interface {
+	// Get returns the value stored under key.
+	Get(key string) (any, bool)
+}
+
+// SessionValues is a Session backed by a map.
+type SessionValues map[string]any
+
+// Get implements Session.
+func (s SessionValues) Get(key string) (any, bool) {
+	v, ok := s[key]
+	return v, ok
+}
+
+// SetSession stores the caller's session in the request context. For use
+// in session middleware.
+func SetSession(r *http.Request, s Session) *http.Request {
+	return SetValue(r, s)
+}
+
+// GetSession returns the session stored by SetSession.
+func GetSession(ctx context.Context) (Session, bool) {
+	s, ok := GetValue[Session](ctx)
+	return s, ok && s != nil
+}
+
+// claimValue returns the principal's claim name: "sub" is the principal's
+// ID, anything else is looked up in its Attributes.
+func claimValue(ctx context.Context, name string) (any, bool) {
+	p, ok := GetPrincipal(ctx)
+	if !ok {
+		return nil, false
+	}
+	if name == "sub" {
+		return p.ID, p.ID != ""
+	}
+	v, ok := p.Attributes[name]
+	return v, ok
+}
+
+// sessionValue returns the session value stored under key.
+func sessionValue(ctx context.Context, key string) (any, bool) {
+	s, ok := GetSession(ctx)
+	if !ok {
+		return nil, false
+	}
+	return s.Get(key)
+}
+
+// bindContextParam binds a claim or session param. A missing value fails
+// with 401 when the field is required, since the caller's credentials or
+// session lack it; a value that does not fit the field is a bind error.
+func bindContextParam(field reflect.Value, r *http.Request, p requestParamDesc) error {
+	source, lookup := "claim", claimValue
+	if p.in == paramInSession {
+		source, lookup = "session value", sessionValue
+	}
+
+	val, ok := lookup(r.Context(), p.name)
+	if !ok || val == nil {
+		if p.defaultValue != "" {
+			return setFieldValue(field, p.defaultValue)
+		}
+		if p.required {
+			return Error(CodeUnauthorized, WithMessagef("missing %s %q", source, p.name))
+		}
+		return nil
+	}
+	return setContextValue(field, reflect.ValueOf(val))
+}
+
+// setContextValue stores a claim or session value in field: directly when
+// the types line up, element by element for slices, and otherwise through
+// its string form, so a JSON number claim binds to an int field.
+func setContextValue(field reflect.Value, v reflect.Value) error {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Type().AssignableTo(field.Type()) {
+		field.Set(v)
+		return nil
+	}
+	if field.Kind() == reflect.Slice && v.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		out := reflect.MakeSlice(field.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			if err := setContextValue(out.Index(i), v.Index(i)); err != nil {
+				return err
+			}
+		}
+		field.Set(out)
+		return nil
+	}
+
+	//exhaustive:ignore
+	switch v.Kind() {
+	case reflect.String:
+		return setFieldValue(field, v.String())
+	case reflect.Float32, reflect.Float64:
+		return setFieldValue(field, strconv.FormatFloat(v.Float(), 'f', -1, 64))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Bool:
+		return setFieldValue(field, fmt.Sprint(v.Interface()))
+	}
+	return fmt.Errorf("cannot bind %s to %s", v.Type(), field.Type())
+}
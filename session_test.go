@@ -0,0 +1,144 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type ssCheckoutReq struct {
+	UserID string   `claim:"sub" required:"true"`
+	Tenant int      `claim:"tenant"`
+	Groups []string `claim:"groups"`
+	CartID string   `session:"cart_id" required:"true"`
+	Locale string   `session:"locale" default:"en"`
+	Coupon string   `query:"coupon"`
+}
+
+func newSessionRouter(principal *api.Principal, session api.Session) (*api.Router, *ssCheckoutReq) {
+	var got ssCheckoutReq
+	r := api.New()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if principal != nil {
+				req = api.SetPrincipal(req, principal)
+			}
+			if session != nil {
+				req = api.SetSession(req, session)
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+	api.Post(r, "/checkout", func(_ context.Context, req *ssCheckoutReq) (*api.Void, error) {
+		got = *req
+		return &api.Void{}, nil
+	})
+	return r, &got
+}
+
+func TestBind_claimAndSession(t *testing.T) {
+	t.Parallel()
+
+	principal := &api.Principal{
+		ID: "u1",
+		Attributes: map[string]any{
+			"tenant": float64(42), // as decoded from a JSON token
+			"groups": []any{"a", "b"},
+		},
+	}
+	r, got := newSessionRouter(principal, api.SessionValues{"cart_id": "c9"})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/checkout?coupon=x", nil))
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	assert.Equal(t, ssCheckoutReq{
+		UserID: "u1",
+		Tenant: 42,
+		Groups: []string{"a", "b"},
+		CartID: "c9",
+		Locale: "en",
+		Coupon: "x",
+	}, *got)
+}
+
+func TestBind_claimAndSession_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		principal  *api.Principal
+		session    api.Session
+		wantStatus int
+		wantDetail string
+	}{
+		"unauthenticated": {
+			session:    api.SessionValues{"cart_id": "c9"},
+			wantStatus: http.StatusUnauthorized,
+			wantDetail: `missing claim "sub"`,
+		},
+		"no session": {
+			principal:  &api.Principal{ID: "u1"},
+			wantStatus: http.StatusUnauthorized,
+			wantDetail: `missing session value "cart_id"`,
+		},
+		"claim of wrong type": {
+			principal:  &api.Principal{ID: "u1", Attributes: map[string]any{"tenant": "acme"}},
+			session:    api.SessionValues{"cart_id": "c9"},
+			wantStatus: http.StatusBadRequest,
+		},
+		"unbindable claim": {
+			principal:  &api.Principal{ID: "u1", Attributes: map[string]any{"groups": map[string]any{}}},
+			session:    api.SessionValues{"cart_id": "c9"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r, _ := newSessionRouter(tc.principal, tc.session)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/checkout", nil))
+			assert.Equal(t, tc.wantStatus, rec.Code)
+
+			if tc.wantDetail != "" {
+				var pd api.ProblemDetails
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pd))
+				assert.Equal(t, tc.wantDetail, pd.Detail)
+			}
+		})
+	}
+}
+
+func TestBind_claimAndSession_spec(t *testing.T) {
+	t.Parallel()
+
+	r, _ := newSessionRouter(nil, nil)
+	op := r.Spec().Paths["/checkout"]["post"]
+
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, "coupon", op.Parameters[0].Name)
+	assert.Contains(t, op.Responses, "401")
+}
+
+func TestGetSession(t *testing.T) {
+	t.Parallel()
+
+	_, ok := api.GetSession(context.Background())
+	assert.False(t, ok)
+
+	req := api.SetSession(httptest.NewRequest(http.MethodGet, "/", nil), api.SessionValues{"k": 1})
+	s, ok := api.GetSession(req.Context())
+	require.True(t, ok)
+	v, ok := s.Get("k")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
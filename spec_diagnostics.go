@@ -0,0 +1,249 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// SpecWarningKind classifies a SpecWarning.
+type SpecWarningKind string
+
+// Spec warning kinds reported by SpecWithDiagnostics.
+const (
+	// WarnAnonymousType flags a response body whose struct type is unnamed,
+	// so it is inlined instead of appearing under components/schemas.
+	WarnAnonymousType SpecWarningKind = "anonymous_type"
+
+	// WarnMissingParamDoc flags a path, query, header, or cookie parameter
+	// without a doc tag.
+	WarnMissingParamDoc SpecWarningKind = "missing_param_doc"
+
+	// WarnEmptySchema flags a body field whose type (interface, chan, func,
+	// complex) cannot be described and is emitted as an empty schema.
+	WarnEmptySchema SpecWarningKind = "empty_schema"
+
+	// WarnSchemaNameCollision flags distinct types sharing a schema name;
+	// only one of them appears under components/schemas.
+	WarnSchemaNameCollision SpecWarningKind = "schema_name_collision"
+)
+
+// SpecWarning is a non-fatal problem found while generating the spec.
+type SpecWarning struct {
+	Kind    SpecWarningKind
+	Method  string // empty for spec-wide warnings
+	Pattern string // empty for spec-wide warnings
+	Message string
+}
+
+// String formats the warning as "METHOD pattern: message".
+func (w SpecWarning) String() string {
+	if w.Method == "" {
+		return w.Message
+	}
+	return w.Method + " " + w.Pattern + ": " + w.Message
+}
+
+// SpecWithDiagnostics returns the spec along with warnings about
+// documentation debt that Spec silently tolerates. Fail a CI job on a
+// non-empty result, or filter by Kind to enforce only some rules:
+//
+//	_, warnings := r.SpecWithDiagnostics()
+//	for _, w := range warnings {
+//	    t.Error(w)
+//	}
+func (r *Router) SpecWithDiagnostics() (OpenAPISpec, []SpecWarning) {
+	spec := r.Spec()
+
+	d := &specDiagnostics{names: make(map[string]reflect.Type)}
+	r.mu.Lock()
+	routes := make([]*routeInfo, len(r.routes))
+	for i := range r.routes {
+		routes[i] = &r.routes[i]
+	}
+	r.mu.Unlock()
+	for _, ri := range routes {
+		d.route(ri)
+	}
+
+	return spec, d.warnings
+}
+
+type specDiagnostics struct {
+	warnings []SpecWarning
+
+	// names maps schema names to the first type registered under them.
+	names map[string]reflect.Type
+}
+
+func (d *specDiagnostics) warn(ri *routeInfo, kind SpecWarningKind, format string, args ...any) {
+	w := SpecWarning{Kind: kind, Message: fmt.Sprintf(format, args...)}
+	if ri != nil {
+		w.Method, w.Pattern = ri.method, ri.pattern
+	}
+	d.warnings = append(d.warnings, w)
+}
+
+func (d *specDiagnostics) route(ri *routeInfo) {
+	if ri.reqType != nil && ri.reqType != reflect.TypeFor[Void]() {
+		for _, p := range extractParameters(ri.reqType) {
+			if p.Description == "" {
+				d.warn(ri, WarnMissingParamDoc, "%s parameter %q has no doc tag", p.In, p.Name)
+			}
+		}
+		if body := requestBodyType(ri); body != nil {
+			d.walk(ri, body, "request body", map[reflect.Type]bool{})
+		}
+	}
+
+	if body := responseBodyType(ri); body != nil {
+		d.checkAnonymous(ri, body, "response body")
+		d.walk(ri, body, "response body", map[reflect.Type]bool{})
+	}
+
+	codes := make([]int, 0, len(ri.extraResponses))
+	for code := range ri.extraResponses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		if body := ri.extraResponses[code]; body != nil {
+			where := fmt.Sprintf("%d response body", code)
+			d.checkAnonymous(ri, body, where)
+			d.walk(ri, body, where, map[reflect.Type]bool{})
+		}
+	}
+}
+
+// checkAnonymous warns when t (through pointers and containers) is an
+// unnamed struct.
+func (d *specDiagnostics) checkAnonymous(ri *routeInfo, t reflect.Type, where string) {
+	t = schemaElem(t)
+	if t.Kind() == reflect.Struct && t.Name() == "" {
+		d.warn(ri, WarnAnonymousType, "%s is an anonymous struct; name the type to publish it as a component", where)
+	}
+}
+
+// walk visits every field reachable from t that contributes to the body
+// schema, reporting empty schemas and schema name collisions.
+func (d *specDiagnostics) walk(ri *routeInfo, t reflect.Type, path string, seen map[reflect.Type]bool) {
+	t = schemaElem(t)
+	if isWellKnownSchemaType(t) {
+		return
+	}
+
+	//exhaustive:ignore
+	switch t.Kind() {
+	case reflect.Interface, reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		d.warn(ri, WarnEmptySchema, "%s has type %s, which produces an empty schema", path, t)
+		return
+	case reflect.Struct:
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+	default:
+		return
+	}
+
+	if _, ok := reflect.New(t).Interface().(SchemaProvider); ok {
+		return
+	}
+	if name := t.Name(); name != "" {
+		if prev, ok := d.names[name]; ok && prev != t {
+			d.warn(nil, WarnSchemaNameCollision, "schema %q is claimed by both %s and %s", name, prev, t)
+		} else if !ok {
+			d.names[name] = t
+		}
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() || isParamField(f) || f.Type == reflect.TypeFor[RawRequest]() {
+			continue
+		}
+		name := jsonFieldName(f)
+		if name == "-" {
+			continue
+		}
+		// A schemaRef field is documented by the component it names.
+		if f.Tag.Get("schemaRef") != "" {
+			continue
+		}
+		d.walk(ri, f.Type, path+"."+name, seen)
+	}
+}
+
+// requestBodyType returns the type documented as the route's request body,
+// or nil when the route has none.
+func requestBodyType(ri *routeInfo) reflect.Type {
+	desc := ri.requestDesc
+	if desc == nil {
+		return nil
+	}
+	//exhaustive:ignore
+	switch desc.category {
+	case catBodyOnly:
+		return ri.reqType
+	case catMixed:
+		if desc.streamContent != "" {
+			return nil
+		}
+		return desc.body.typ
+	}
+	return nil
+}
+
+// responseBodyType returns the type documented as the route's encoded
+// success body, or nil for Void, stream, and body-less responses. A
+// JSONArrayStream[T] or iter.Seq[T] is documented as []T.
+func responseBodyType(ri *routeInfo) reflect.Type {
+	if ri.respType == nil || ri.respType == reflect.TypeFor[Void]() {
+		return nil
+	}
+	desc := ri.responseDesc
+	if desc == nil {
+		return ri.respType
+	}
+	if desc.body != nil && desc.body.kind == bodyKindJSONArray {
+		return reflect.SliceOf(desc.body.typ.Elem())
+	}
+	if desc.body != nil && desc.body.kind == bodyKindSeq {
+		elem, _, _ := seqElem(desc.body.typ)
+		return reflect.SliceOf(elem)
+	}
+	if desc.body == nil || desc.body.kind != bodyKindCodec {
+		return nil
+	}
+	return desc.body.typ
+}
+
+// schemaElem unwraps pointers, slices, arrays, and maps to the type whose
+// schema is ultimately emitted. []byte is left as is.
+func schemaElem(t reflect.Type) reflect.Type {
+	for {
+		//exhaustive:ignore
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Array, reflect.Map:
+			t = t.Elem()
+		case reflect.Slice:
+			if t.Elem().Kind() == reflect.Uint8 {
+				return t
+			}
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// isWellKnownSchemaType reports whether t has a fixed schema.
+func isWellKnownSchemaType(t reflect.Type) bool {
+	switch t {
+	case reflect.TypeFor[time.Time](), reflect.TypeFor[time.Duration](),
+		reflect.TypeFor[Void](), reflect.TypeFor[FileUpload]():
+		return true
+	}
+	return false
+}
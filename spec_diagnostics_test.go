@@ -0,0 +1,104 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type diagItem struct {
+	ID    string `json:"id"`
+	Extra any    `json:"extra"`
+}
+
+type diagListReq struct {
+	Page   int    `query:"page" doc:"Page number"`
+	Filter string `query:"filter"`
+}
+
+type diagCreateReq struct {
+	Body struct {
+		Name     string         `json:"name"`
+		Callback func()         `json:"-"`
+		Meta     map[string]any `json:"meta"`
+	}
+}
+
+func TestSpecWithDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	api.Get(r, "/items", func(_ context.Context, _ *diagListReq) (*api.Resp[[]diagItem], error) {
+		return nil, nil //nolint:nilnil // never called
+	})
+	api.Post(r, "/items", func(_ context.Context, _ *diagCreateReq) (*api.Resp[struct {
+		ID string `json:"id"`
+	}], error) {
+		return nil, nil //nolint:nilnil // never called
+	})
+
+	spec, warnings := r.SpecWithDiagnostics()
+	require.NotEmpty(t, spec.Paths)
+
+	byKind := map[api.SpecWarningKind][]string{}
+	for _, w := range warnings {
+		byKind[w.Kind] = append(byKind[w.Kind], w.String())
+	}
+
+	assert.Equal(t, []string{`GET /items: query parameter "filter" has no doc tag`}, byKind[api.WarnMissingParamDoc])
+	assert.Equal(t, []string{
+		"GET /items: response body.extra has type interface {}, which produces an empty schema",
+		"POST /items: request body.meta has type interface {}, which produces an empty schema",
+	}, byKind[api.WarnEmptySchema])
+	assert.Equal(t, []string{
+		"POST /items: response body is an anonymous struct; name the type to publish it as a component",
+	}, byKind[api.WarnAnonymousType])
+}
+
+func TestSpecWithDiagnostics_clean(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		ID      string `json:"id"`
+		Payload any    `json:"payload" schemaRef:"ItemPayload"`
+	}
+
+	r := api.New()
+	api.Get(r, "/items/{id}", func(_ context.Context, _ *struct {
+		ID string `path:"id" doc:"Item ID"`
+	}) (*api.Resp[Item], error) {
+		return nil, nil //nolint:nilnil // never called
+	})
+	api.Delete(r, "/items/{id}", voidHandler)
+
+	_, warnings := r.SpecWithDiagnostics()
+	assert.Empty(t, warnings)
+}
+
+func TestSpecWithDiagnostics_concurrent_registration(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 50 {
+			api.Get(r, fmt.Sprintf("/items/%d", i), func(_ context.Context, _ *api.Void) (*api.Resp[diagItem], error) {
+				return nil, nil //nolint:nilnil // never called
+			})
+		}
+	}()
+	for range 20 {
+		r.SpecWithDiagnostics()
+		r.HostSpec("")
+	}
+	<-done
+
+	spec, _ := r.SpecWithDiagnostics()
+	assert.Len(t, spec.Paths, 50)
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WriteSpecSplit writes the OpenAPI spec to dir as several JSON documents
+// linked by relative $refs:
+//
+//	openapi.json      the root document; each path is a $ref
+//	paths/<tag>.json  the path items whose first operation has the tag
+//	components.json   the component schemas and security schemes
+//
+// A path item belongs to the file of the first tag of its first operation,
+// in method order, or to paths/default.json when untagged. The root keeps
+// the security schemes inline and lists each schema as a $ref, so tools
+// that resolve names against the root still find them. dir is created if
+// needed; existing files are overwritten.
+func (r *Router) WriteSpecSplit(dir string) error {
+	var doc map[string]any
+	if err := roundTripJSON(r.Spec(), &doc); err != nil {
+		return err
+	}
+
+	files := make(map[string]map[string]any)
+	rootPaths := make(map[string]any)
+	paths, _ := doc["paths"].(map[string]any)
+	for path, item := range paths {
+		name := "paths/" + specFileName(firstTag(item)) + ".json"
+		if files[name] == nil {
+			files[name] = make(map[string]any)
+		}
+		files[name][path] = rewriteRefs(item, "../components.json#/schemas/")
+		rootPaths[path] = map[string]any{"$ref": name + "#/" + jsonPointerEscape(path)}
+	}
+	doc["paths"] = rootPaths
+
+	split := make(map[string]any)
+	components, _ := doc["components"].(map[string]any)
+	if schemas, ok := components["schemas"].(map[string]any); ok {
+		refs := make(map[string]any, len(schemas))
+		for name := range schemas {
+			refs[name] = map[string]any{"$ref": "components.json#/schemas/" + jsonPointerEscape(name)}
+		}
+		components["schemas"] = refs
+		split["schemas"] = rewriteRefs(schemas, "#/schemas/")
+	}
+	if schemes, ok := components["securitySchemes"]; ok {
+		split["securitySchemes"] = schemes
+	}
+	files["components.json"] = split
+	files["openapi.json"] = doc
+
+	if err := os.MkdirAll(filepath.Join(dir, "paths"), 0o750); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		b, err := json.MarshalIndent(files[name], "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), append(b, '\n'), 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// roundTripJSON decodes the JSON encoding of v into out.
+func roundTripJSON(v, out any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// firstTag returns the first tag of the first operation of a decoded path
+// item, in method order, or "".
+func firstTag(item any) string {
+	ops, _ := item.(map[string]any)
+	for _, method := range slices.Sorted(maps.Keys(ops)) {
+		op, _ := ops[method].(map[string]any)
+		if tags, _ := op["tags"].([]any); len(tags) > 0 {
+			if tag, ok := tags[0].(string); ok {
+				return tag
+			}
+		}
+	}
+	return ""
+}
+
+// specFileName turns a tag into a file name: lowercase, with every run of
+// other characters than letters and digits replaced by a dash.
+func specFileName(tag string) string {
+	var b strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(tag) {
+		if 'a' <= c && c <= 'z' || '0' <= c && c <= '9' {
+			b.WriteRune(c)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	name := strings.TrimSuffix(b.String(), "-")
+	if name == "" {
+		return "default"
+	}
+	return name
+}
+
+// rewriteRefs replaces the #/components/schemas/ prefix of every $ref and
+// discriminator mapping in a decoded document with prefix.
+func rewriteRefs(v any, prefix string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			if ref, ok := e.(string); ok && k == "$ref" {
+				v[k] = rewriteRef(ref, prefix)
+				continue
+			}
+			if mapping, ok := e.(map[string]any); ok && k == "mapping" {
+				for value, ref := range mapping {
+					if ref, ok := ref.(string); ok {
+						mapping[value] = rewriteRef(ref, prefix)
+					}
+				}
+			}
+			v[k] = rewriteRefs(e, prefix)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = rewriteRefs(e, prefix)
+		}
+	}
+	return v
+}
+
+func rewriteRef(ref, prefix string) string {
+	if name, ok := strings.CutPrefix(ref, componentRefPrefix); ok {
+		return prefix + name
+	}
+	return ref
+}
+
+// jsonPointerEscape escapes s as a JSON Pointer reference token.
+func jsonPointerEscape(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
@@ -0,0 +1,74 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func TestWriteSpecSplit(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Name string `json:"name"`
+	}
+	type userReq struct {
+		ID string `path:"id"`
+	}
+
+	r := api.New(api.WithTitle("Split"), api.WithSecurityScheme("bearer", api.SecurityScheme{Type: "http", Scheme: "bearer"}))
+	api.Get(r, "/users/{id}", func(_ context.Context, _ *userReq) (*api.Resp[user], error) { return nil, nil },
+		api.WithTags("User Accounts"))
+	api.Delete(r, "/users/{id}", func(_ context.Context, _ *userReq) (*api.Void, error) { return nil, nil },
+		api.WithTags("Admin"))
+	api.Get(r, "/users", func(_ context.Context, _ *api.Void) (*api.Resp[[]user], error) { return nil, nil },
+		api.WithTags("User Accounts"))
+	api.Get(r, "/methods", func(_ context.Context, _ *api.Void) (*api.Resp[paymentMethod], error) { return nil, nil })
+
+	dir := t.TempDir()
+	require.NoError(t, r.WriteSpecSplit(dir))
+
+	read := func(name string) map[string]any {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		var doc map[string]any
+		require.NoError(t, json.Unmarshal(b, &doc))
+		return doc
+	}
+
+	root := read("openapi.json")
+	assert.Equal(t, map[string]any{
+		"/users/{id}": map[string]any{"$ref": "paths/admin.json#/~1users~1{id}"},
+		"/users":      map[string]any{"$ref": "paths/user-accounts.json#/~1users"},
+		"/methods":    map[string]any{"$ref": "paths/default.json#/~1methods"},
+	}, root["paths"])
+	components := root["components"].(map[string]any)
+	assert.Equal(t, map[string]any{"$ref": "components.json#/schemas/user"}, components["schemas"].(map[string]any)["user"])
+	assert.Contains(t, components["securitySchemes"], "bearer")
+
+	// DELETE sorts before GET, so the path is filed under its tag.
+	admin := read("paths/admin.json")
+	get := admin["/users/{id}"].(map[string]any)["get"].(map[string]any)
+	schema := get["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"]
+	assert.Equal(t, map[string]any{"$ref": "../components.json#/schemas/user"}, schema)
+
+	methods := read("paths/default.json")["/methods"].(map[string]any)["get"].(map[string]any)
+	schema = methods["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"]
+	mapping := schema.(map[string]any)["discriminator"].(map[string]any)["mapping"]
+	assert.Equal(t, map[string]any{
+		"card":         "../components.json#/schemas/oneOfCard",
+		"bank_account": "../components.json#/schemas/oneOfBank",
+	}, mapping)
+
+	split := read("components.json")
+	assert.Contains(t, split["schemas"], "user")
+	assert.Contains(t, split["securitySchemes"], "bearer")
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteSpecStream writes the OpenAPI spec as compact JSON to w, one path at
+// a time. Unlike WriteSpec it never holds the whole document in memory:
+// each path's operations are built, encoded, and released before the next,
+// so only the shared component schemas accumulate. The output is
+// byte-for-byte the JSON encoding of Spec(), followed by a newline. Prefer
+// it for APIs with thousands of routes.
+func (r *Router) WriteSpecStream(w io.Writer) error {
+	routes := r.specRoutes("")
+	byPath := make(map[string][]*routeInfo)
+	for _, ri := range routes {
+		path := toOpenAPIPath(ri.pattern)
+		byPath[path] = append(byPath[path], ri)
+	}
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	reg := newSchemaRegistry()
+	reg.timeFormat = r.timeFormat
+	reg.bigIntStrings = r.bigIntStrings
+	reg.nameAnonymous = r.anonymousResponseNames
+	reg.nameGenerics(routes)
+	codecCTs := r.codecs.contentTypes()
+	header := r.specHeader()
+
+	sw := &specWriter{w: bufio.NewWriter(w)}
+	sw.raw("{")
+	sw.field("openapi", header.OpenAPI)
+	sw.field("info", header.Info)
+	if len(header.Servers) > 0 {
+		sw.field("servers", header.Servers)
+	}
+
+	sw.key("paths")
+	sw.raw("{")
+	for i, path := range paths {
+		if i > 0 {
+			sw.raw(",")
+		}
+		item := make(PathItem, len(byPath[path]))
+		for _, ri := range byPath[path] {
+			item[strings.ToLower(ri.method)] = buildOperation(ri, reg, codecCTs)
+		}
+		sw.value(path)
+		sw.raw(":")
+		sw.value(item)
+	}
+	sw.raw("}")
+
+	sw.field("components", r.specComponents(reg))
+	if len(header.Tags) > 0 {
+		sw.field("tags", header.Tags)
+	}
+	if header.ExternalDocs != nil {
+		sw.field("externalDocs", header.ExternalDocs)
+	}
+	if len(header.Security) > 0 {
+		sw.field("security", header.Security)
+	}
+	if len(header.Webhooks) > 0 {
+		sw.field("webhooks", header.Webhooks)
+	}
+	if len(header.Extensions) > 0 {
+		sw.field("extensions", header.Extensions)
+	}
+	sw.raw("}\n")
+
+	if sw.err != nil {
+		return sw.err
+	}
+	return sw.w.Flush()
+}
+
+// specWriter emits a JSON object incrementally, keeping the first error.
+type specWriter struct {
+	w      *bufio.Writer
+	err    error
+	fields int
+}
+
+func (s *specWriter) raw(str string) {
+	if s.err == nil {
+		_, s.err = s.w.WriteString(str)
+	}
+}
+
+func (s *specWriter) value(v any) {
+	if s.err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		s.err = err
+		return
+	}
+	_, s.err = s.w.Write(b)
+}
+
+// key writes a top-level object key, preceded by a comma after the first.
+func (s *specWriter) key(name string) {
+	if s.fields > 0 {
+		s.raw(",")
+	}
+	s.fields++
+	s.value(name)
+	s.raw(":")
+}
+
+func (s *specWriter) field(name string, v any) {
+	s.key(name)
+	s.value(v)
+}
@@ -0,0 +1,123 @@
+package specdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/bjaus/api"
+)
+
+// Changelog is a human-readable summary of the changes between two specs,
+// grouped by tag and then by severity, for publishing as release notes.
+type Changelog struct {
+	// From and To are the info.version of the compared specs.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	// Tags groups the changes by operation tag, in tag order. An operation
+	// with several tags is listed under each; untagged operations are
+	// grouped last under an empty tag.
+	Tags []TagChanges `json:"tags"`
+}
+
+// TagChanges is the changes to the operations of one tag.
+type TagChanges struct {
+	Tag string `json:"tag"`
+
+	// Breaking, Deprecations, and NonBreaking hold the tag's changes of
+	// each severity, ordered by operation.
+	Breaking     []Change `json:"breaking,omitempty"`
+	Deprecations []Change `json:"deprecations,omitempty"`
+	NonBreaking  []Change `json:"nonBreaking,omitempty"`
+}
+
+// NewChangelog diffs from against to and groups the changes.
+func NewChangelog(from, to api.OpenAPISpec) *Changelog {
+	c := &Changelog{From: from.Info.Version, To: to.Info.Version, Tags: []TagChanges{}}
+	byTag := map[string]*TagChanges{}
+	for _, ch := range Diff(from, to) {
+		tags := ch.Tags
+		if len(tags) == 0 {
+			tags = []string{""}
+		}
+		for _, tag := range tags {
+			tc, ok := byTag[tag]
+			if !ok {
+				tc = &TagChanges{Tag: tag}
+				byTag[tag] = tc
+			}
+			switch ch.Severity {
+			case Breaking:
+				tc.Breaking = append(tc.Breaking, ch)
+			case Deprecation:
+				tc.Deprecations = append(tc.Deprecations, ch)
+			default:
+				tc.NonBreaking = append(tc.NonBreaking, ch)
+			}
+		}
+	}
+	for _, tag := range sortedKeys(byTag) {
+		if tag != "" {
+			c.Tags = append(c.Tags, *byTag[tag])
+		}
+	}
+	if tc, ok := byTag[""]; ok {
+		c.Tags = append(c.Tags, *tc)
+	}
+	return c
+}
+
+// HasBreaking reports whether any change is breaking, for failing a
+// release pipeline that is not meant to break clients.
+func (c *Changelog) HasBreaking() bool {
+	return slices.ContainsFunc(c.Tags, func(tc TagChanges) bool { return len(tc.Breaking) > 0 })
+}
+
+// JSON returns the changelog as indented JSON.
+func (c *Changelog) JSON() ([]byte, error) {
+	return json.MarshalIndent(c, "", "  ")
+}
+
+// WriteMarkdown writes the changelog as a Markdown document with a section
+// per tag and a subsection per severity.
+func (c *Changelog) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# API changes")
+	switch {
+	case c.From != "" && c.To != "":
+		fmt.Fprintf(&b, ": %s → %s", c.From, c.To)
+	case c.To != "":
+		fmt.Fprintf(&b, ": %s", c.To)
+	}
+	b.WriteString("\n")
+
+	if len(c.Tags) == 0 {
+		b.WriteString("\nNo changes.\n")
+	}
+	for _, tc := range c.Tags {
+		title := tc.Tag
+		if title == "" {
+			title = "Other"
+		}
+		fmt.Fprintf(&b, "\n## %s\n", title)
+		writeSection(&b, "Breaking changes", tc.Breaking)
+		writeSection(&b, "Deprecations", tc.Deprecations)
+		writeSection(&b, "Changes", tc.NonBreaking)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeSection(b *strings.Builder, title string, changes []Change) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n### %s\n\n", title)
+	for _, ch := range changes {
+		fmt.Fprintf(b, "- `%s`: %s\n", ch.Operation, ch.Message)
+	}
+}
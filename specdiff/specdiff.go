@@ -0,0 +1,355 @@
+// Package specdiff compares two OpenAPI specs generated by the api
+// framework and classifies what changed, so release notes and
+// compatibility checks can be produced from two router builds:
+//
+//	changes := specdiff.Diff(previous.Spec(), current.Spec())
+//	log := specdiff.NewChangelog(previous.Spec(), current.Spec())
+//	log.WriteMarkdown(os.Stdout)
+//
+// Each change is classified by its effect on existing clients: breaking
+// changes can fail requests that worked before, deprecations announce a
+// future removal, and non-breaking changes are additive.
+package specdiff
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/bjaus/api"
+)
+
+// Severity classifies a change by its effect on existing clients.
+type Severity string
+
+// Severities, from most to least disruptive.
+const (
+	Breaking    Severity = "breaking"
+	Deprecation Severity = "deprecation"
+	NonBreaking Severity = "non-breaking"
+)
+
+// rank orders severities for sorting, most disruptive first.
+func (s Severity) rank() int {
+	switch s {
+	case Breaking:
+		return 0
+	case Deprecation:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Change is one difference between two specs.
+type Change struct {
+	Severity Severity `json:"severity"`
+
+	// Operation is the affected operation as "METHOD /path".
+	Operation string `json:"operation"`
+
+	// Tags are the operation's tags, from the newer spec when it still has
+	// the operation.
+	Tags []string `json:"tags,omitempty"`
+
+	// Message says what changed, such as `query param "limit" is now
+	// required`.
+	Message string `json:"message"`
+}
+
+// Diff returns the changes from the from spec to the to spec, ordered by
+// operation and then by severity.
+func Diff(from, to api.OpenAPISpec) []Change {
+	d := &differ{from: from, to: to}
+	for _, path := range unionKeys(from.Paths, to.Paths) {
+		oldItem, newItem := from.Paths[path], to.Paths[path]
+		for _, method := range unionKeys(oldItem, newItem) {
+			oldOp, hadOp := oldItem[method]
+			newOp, hasOp := newItem[method]
+			d.op = strings.ToUpper(method) + " " + path
+			switch {
+			case !hasOp:
+				d.tags = oldOp.Tags
+				d.add(Breaking, "operation removed")
+			case !hadOp:
+				d.tags = newOp.Tags
+				d.add(NonBreaking, "operation added")
+			default:
+				d.tags = newOp.Tags
+				d.operation(oldOp, newOp)
+			}
+		}
+	}
+	slices.SortStableFunc(d.changes, func(a, b Change) int {
+		return cmp.Or(
+			strings.Compare(a.Operation, b.Operation),
+			cmp.Compare(a.Severity.rank(), b.Severity.rank()),
+		)
+	})
+	return d.changes
+}
+
+// differ accumulates the changes of one Diff call.
+type differ struct {
+	from, to api.OpenAPISpec
+	changes  []Change
+
+	// op and tags describe the operation being compared.
+	op   string
+	tags []string
+}
+
+func (d *differ) add(sev Severity, format string, args ...any) {
+	d.changes = append(d.changes, Change{
+		Severity:  sev,
+		Operation: d.op,
+		Tags:      d.tags,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *differ) operation(oldOp, newOp api.Operation) {
+	if newOp.Deprecated && !oldOp.Deprecated {
+		d.add(Deprecation, "operation deprecated")
+	}
+
+	oldSec, newSec := d.security(d.from, oldOp), d.security(d.to, newOp)
+	switch {
+	case oldSec == 0 && newSec > 0:
+		d.add(Breaking, "operation now requires authentication")
+	case oldSec > 0 && newSec == 0:
+		d.add(NonBreaking, "operation no longer requires authentication")
+	}
+
+	d.params(oldOp.Parameters, newOp.Parameters)
+	d.requestBody(oldOp.RequestBody, newOp.RequestBody)
+	d.responses(oldOp.Responses, newOp.Responses)
+}
+
+// security returns the number of security requirements that apply to op.
+func (d *differ) security(spec api.OpenAPISpec, op api.Operation) int {
+	if op.Security != nil {
+		return len(*op.Security)
+	}
+	return len(spec.Security)
+}
+
+func (d *differ) params(oldParams, newParams []api.Parameter) {
+	key := func(p api.Parameter) string { return p.In + " " + p.Name }
+	old := make(map[string]api.Parameter, len(oldParams))
+	for _, p := range oldParams {
+		old[key(p)] = p
+	}
+	seen := make(map[string]bool, len(newParams))
+	for _, p := range newParams {
+		k := key(p)
+		seen[k] = true
+		prev, ok := old[k]
+		switch {
+		case !ok && p.Required:
+			d.add(Breaking, "required %s param %q added", p.In, p.Name)
+		case !ok:
+			d.add(NonBreaking, "optional %s param %q added", p.In, p.Name)
+		default:
+			if p.Required && !prev.Required {
+				d.add(Breaking, "%s param %q is now required", p.In, p.Name)
+			}
+			if !p.Required && prev.Required {
+				d.add(NonBreaking, "%s param %q is now optional", p.In, p.Name)
+			}
+			if p.Deprecated && !prev.Deprecated {
+				d.add(Deprecation, "%s param %q deprecated", p.In, p.Name)
+			}
+			if t, pt := schemaType(prev.Schema), schemaType(p.Schema); t != pt {
+				d.add(Breaking, "%s param %q changed type from %s to %s", p.In, p.Name, t, pt)
+			}
+		}
+	}
+	for _, p := range oldParams {
+		if !seen[key(p)] {
+			d.add(NonBreaking, "%s param %q removed", p.In, p.Name)
+		}
+	}
+}
+
+func (d *differ) requestBody(oldBody, newBody *api.RequestBody) {
+	switch {
+	case oldBody == nil && newBody == nil:
+		return
+	case oldBody == nil:
+		if newBody.Required {
+			d.add(Breaking, "required request body added")
+		} else {
+			d.add(NonBreaking, "optional request body added")
+		}
+		return
+	case newBody == nil:
+		d.add(NonBreaking, "request body removed")
+		return
+	}
+	if newBody.Required && !oldBody.Required {
+		d.add(Breaking, "request body is now required")
+	}
+	for _, ct := range sortedKeys(oldBody.Content) {
+		if _, ok := newBody.Content[ct]; !ok {
+			d.add(Breaking, "request body no longer accepts %s", ct)
+		}
+	}
+	oldSchema, newSchema := jsonSchema(oldBody.Content), jsonSchema(newBody.Content)
+	if oldSchema != nil && newSchema != nil {
+		d.schema("request", "", oldSchema, newSchema, map[string]bool{})
+	}
+}
+
+func (d *differ) responses(oldResp, newResp api.OperationResp) {
+	for _, code := range unionKeys(oldResp, newResp) {
+		prev, had := oldResp[code]
+		next, has := newResp[code]
+		switch {
+		case !has && strings.HasPrefix(code, "2"):
+			d.add(Breaking, "response %s removed", code)
+		case !has:
+			d.add(NonBreaking, "response %s removed", code)
+		case !had:
+			d.add(NonBreaking, "response %s added", code)
+		default:
+			oldSchema, newSchema := jsonSchema(prev.Content), jsonSchema(next.Content)
+			if oldSchema != nil && newSchema != nil {
+				d.schema("response "+code, "", oldSchema, newSchema, map[string]bool{})
+			}
+		}
+	}
+}
+
+// schema compares the fields of a request or response body, recursing
+// into objects and arrays. where names the body; at is the field path so
+// far. seen guards against recursive component schemas.
+func (d *differ) schema(where, at string, oldSchema, newSchema *api.JSONSchema, seen map[string]bool) {
+	if ref := oldSchema.Ref + " " + newSchema.Ref; oldSchema.Ref != "" || newSchema.Ref != "" {
+		if seen[ref] {
+			return
+		}
+		seen[ref] = true
+		defer delete(seen, ref)
+	}
+	oldSchema, newSchema = resolve(d.from, oldSchema), resolve(d.to, newSchema)
+	if oldSchema == nil || newSchema == nil {
+		return
+	}
+
+	if t, nt := schemaType(*oldSchema), schemaType(*newSchema); t != nt {
+		d.add(Breaking, "%s %s changed type from %s to %s", where, fieldName(at), t, nt)
+		return
+	}
+	if oldSchema.Items != nil && newSchema.Items != nil {
+		d.schema(where, at+"[]", oldSchema.Items, newSchema.Items, seen)
+	}
+
+	request := where == "request"
+	oldReq, newReq := set(oldSchema.Required), set(newSchema.Required)
+	for _, name := range unionKeys(oldSchema.Properties, newSchema.Properties) {
+		field := joinField(at, name)
+		prev, had := oldSchema.Properties[name]
+		next, has := newSchema.Properties[name]
+		switch {
+		case !has && request:
+			d.add(NonBreaking, "%s field %q removed", where, field)
+		case !has:
+			d.add(Breaking, "%s field %q removed", where, field)
+		case !had && request && newReq[name]:
+			d.add(Breaking, "required %s field %q added", where, field)
+		case !had:
+			d.add(NonBreaking, "%s field %q added", where, field)
+		default:
+			if request && newReq[name] && !oldReq[name] {
+				d.add(Breaking, "%s field %q is now required", where, field)
+			}
+			if next.Deprecated && !prev.Deprecated {
+				d.add(Deprecation, "%s field %q deprecated", where, field)
+			}
+			d.schema(where, field, &prev, &next, seen)
+		}
+	}
+}
+
+// componentRefPrefix starts a reference to a component schema.
+const componentRefPrefix = "#/components/schemas/"
+
+// resolve follows a local component reference.
+func resolve(spec api.OpenAPISpec, s *api.JSONSchema) *api.JSONSchema {
+	name, ok := strings.CutPrefix(s.Ref, componentRefPrefix)
+	if !ok {
+		return s
+	}
+	if spec.Components == nil {
+		return nil
+	}
+	target, ok := spec.Components.Schemas[name]
+	if !ok {
+		return nil
+	}
+	return &target
+}
+
+// jsonSchema returns the schema of the JSON media type in content, or of
+// the only media type when there is one.
+func jsonSchema(content map[string]api.MediaObj) *api.JSONSchema {
+	if m, ok := content["application/json"]; ok {
+		return m.Schema
+	}
+	if len(content) == 1 {
+		for _, m := range content {
+			return m.Schema
+		}
+	}
+	return nil
+}
+
+// schemaType describes a schema's type for messages, "any" when it has
+// none.
+func schemaType(s api.JSONSchema) string {
+	if s.Type == "" {
+		return "any"
+	}
+	if s.Format != "" {
+		return s.Type + " (" + s.Format + ")"
+	}
+	return s.Type
+}
+
+func fieldName(at string) string {
+	if at == "" {
+		return "body"
+	}
+	return fmt.Sprintf("field %q", at)
+}
+
+func joinField(at, name string) string {
+	if at == "" {
+		return name
+	}
+	return at + "." + name
+}
+
+func set(names []string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// unionKeys returns the keys of a and b, sorted.
+func unionKeys[M ~map[string]V, V any](a, b M) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	keys = slices.AppendSeq(keys, maps.Keys(a))
+	keys = slices.AppendSeq(keys, maps.Keys(b))
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
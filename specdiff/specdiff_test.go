@@ -0,0 +1,162 @@
+package specdiff_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+	"github.com/bjaus/api/specdiff"
+)
+
+type user struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+type getUserV1 struct {
+	ID string `path:"id"`
+}
+
+type getUserV2 struct {
+	ID     string `path:"id"`
+	Fields string `query:"fields"`
+	Tenant string `header:"X-Tenant" required:"true"`
+}
+
+type userV2 struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func v1() api.OpenAPISpec {
+	r := api.New(api.WithVersion("1.0.0"))
+	api.Get(r, "/users/{id}", func(context.Context, *getUserV1) (*api.Resp[user], error) {
+		return nil, nil
+	}, api.WithTags("users"))
+	api.Delete(r, "/users/{id}", func(context.Context, *getUserV1) (*api.Void, error) {
+		return nil, nil
+	}, api.WithTags("users"))
+	api.Get(r, "/status", func(context.Context, *api.Void) (*api.Void, error) {
+		return nil, nil
+	})
+	return r.Spec()
+}
+
+func v2() api.OpenAPISpec {
+	r := api.New(api.WithVersion("2.0.0"))
+	api.Get(r, "/users/{id}", func(context.Context, *getUserV2) (*api.Resp[userV2], error) {
+		return nil, nil
+	}, api.WithTags("users"))
+	api.Get(r, "/status", func(context.Context, *api.Void) (*api.Void, error) {
+		return nil, nil
+	}, api.WithDeprecated())
+	api.Get(r, "/orgs", func(context.Context, *api.Void) (*api.Resp[[]user], error) {
+		return nil, nil
+	}, api.WithTags("orgs", "users"))
+	return r.Spec()
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	for _, c := range specdiff.Diff(v1(), v2()) {
+		got = append(got, string(c.Severity)+": "+c.Operation+": "+c.Message)
+	}
+
+	assert.Subset(t, got, []string{
+		"breaking: DELETE /users/{id}: operation removed",
+		"breaking: GET /users/{id}: required header param \"X-Tenant\" added",
+		"non-breaking: GET /users/{id}: optional query param \"fields\" added",
+		"breaking: GET /users/{id}: response 200 field \"id\" changed type from string to integer",
+		"breaking: GET /users/{id}: response 200 field \"email\" removed",
+		"deprecation: GET /status: operation deprecated",
+		"non-breaking: GET /orgs: operation added",
+	})
+}
+
+func TestDiff_identical(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, specdiff.Diff(v1(), v1()))
+}
+
+func TestDiff_request_body(t *testing.T) {
+	t.Parallel()
+
+	type before struct {
+		Body struct {
+			Name string `json:"name"`
+			Note string `json:"note,omitempty"`
+		}
+	}
+	type after struct {
+		Body struct {
+			Name string `json:"name"`
+			Team string `json:"team" required:"true"`
+		}
+	}
+
+	r1 := api.New()
+	api.Post(r1, "/users", func(context.Context, *before) (*api.Void, error) { return nil, nil })
+	r2 := api.New()
+	api.Post(r2, "/users", func(context.Context, *after) (*api.Void, error) { return nil, nil })
+
+	changes := specdiff.Diff(r1.Spec(), r2.Spec())
+
+	assert.Contains(t, changes, specdiff.Change{
+		Severity:  specdiff.Breaking,
+		Operation: "POST /users",
+		Message:   `required request field "team" added`,
+	})
+	assert.Contains(t, changes, specdiff.Change{
+		Severity:  specdiff.NonBreaking,
+		Operation: "POST /users",
+		Message:   `request field "note" removed`,
+	})
+}
+
+func TestChangelog(t *testing.T) {
+	t.Parallel()
+
+	log := specdiff.NewChangelog(v1(), v2())
+
+	require.True(t, log.HasBreaking())
+	assert.Equal(t, "1.0.0", log.From)
+	assert.Equal(t, "2.0.0", log.To)
+
+	var tags []string
+	for _, tc := range log.Tags {
+		tags = append(tags, tc.Tag)
+	}
+	assert.Equal(t, []string{"orgs", "users", ""}, tags)
+
+	var md bytes.Buffer
+	require.NoError(t, log.WriteMarkdown(&md))
+	assert.Contains(t, md.String(), "# API changes: 1.0.0 → 2.0.0\n")
+	assert.Contains(t, md.String(), "## users\n\n### Breaking changes\n\n- `DELETE /users/{id}`: operation removed\n")
+	assert.Contains(t, md.String(), "## Other\n\n### Deprecations\n\n- `GET /status`: operation deprecated\n")
+
+	b, err := log.JSON()
+	require.NoError(t, err)
+	var decoded specdiff.Changelog
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, *log, decoded)
+}
+
+func TestChangelog_no_changes(t *testing.T) {
+	t.Parallel()
+
+	log := specdiff.NewChangelog(v1(), v1())
+	assert.False(t, log.HasBreaking())
+
+	var md bytes.Buffer
+	require.NoError(t, log.WriteMarkdown(&md))
+	assert.Equal(t, "# API changes: 1.0.0 → 1.0.0\n\nNo changes.\n", md.String())
+}
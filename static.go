@@ -1,13 +1,111 @@
 package api
 
 import (
+	"io"
 	"io/fs"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// StaticConfig configures Static and SPA.
+type StaticConfig struct {
+	// MaxAge lets clients use a file for this long without revalidating.
+	// Set it for fingerprinted assets (app.3f9a1c.js), whose content never
+	// changes under the same name. Without it, files are sent with
+	// Cache-Control: no-cache, so clients revalidate with If-Modified-Since
+	// and get a 304 while the file is unchanged.
+	MaxAge time.Duration
+}
+
+// cacheControl returns the Cache-Control value for files.
+func (c StaticConfig) cacheControl() string {
+	if c.MaxAge <= 0 {
+		return "no-cache"
+	}
+	return "public, max-age=" + strconv.FormatInt(int64(c.MaxAge/time.Second), 10)
+}
+
 // Static serves static files from the given filesystem under the URL path.
 // The route is hidden from the OpenAPI spec.
-func (r *Router) Static(urlPath string, fsys fs.FS) {
-	handler := http.StripPrefix(urlPath, http.FileServerFS(fsys))
-	r.mux.Handle("GET "+urlPath+"/{path...}", handler)
+//
+//	r.Static("/assets", assets, api.StaticConfig{MaxAge: 365 * 24 * time.Hour})
+func (r *Router) Static(urlPath string, fsys fs.FS, cfg ...StaticConfig) {
+	var c StaticConfig
+	if len(cfg) > 0 {
+		c = cfg[0]
+	}
+	prefix := strings.TrimSuffix(urlPath, "/")
+	cache := c.cacheControl()
+	files := http.StripPrefix(prefix, http.FileServerFS(fsys))
+	r.handle("GET "+prefix+"/{path...}", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Cache-Control", cache)
+		files.ServeHTTP(w, req)
+	}))
+}
+
+// SPA serves a single-page application from the given filesystem under
+// the URL path. Files that exist are served like Static; any other path
+// without a file extension gets the index file, so the application's
+// client-side router handles it:
+//
+//	r.SPA("/", dist, "index.html", api.StaticConfig{MaxAge: 365 * 24 * time.Hour})
+//
+// The index is always sent with Cache-Control: no-cache, so a deploy takes
+// effect on the next load while fingerprinted assets stay cached for
+// MaxAge. A missing path with an extension, such as a stale asset, gets
+// 404 rather than the index. API routes take precedence, since they are
+// more specific patterns; the routes are hidden from the OpenAPI spec.
+func (r *Router) SPA(urlPath string, fsys fs.FS, index string, cfg ...StaticConfig) {
+	var c StaticConfig
+	if len(cfg) > 0 {
+		c = cfg[0]
+	}
+	prefix := strings.TrimSuffix(urlPath, "/")
+	cache := c.cacheControl()
+	files := http.StripPrefix(prefix, http.FileServerFS(fsys))
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name := strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, prefix), "/")
+		if name != "" && name != index {
+			info, err := fs.Stat(fsys, name)
+			if (err == nil && !info.IsDir()) || (err != nil && path.Ext(name) != "") {
+				w.Header().Set("Cache-Control", cache)
+				files.ServeHTTP(w, req)
+				return
+			}
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		serveIndex(w, req, fsys, index)
+	})
+
+	r.handle("GET "+prefix+"/{path...}", handler)
+	if prefix != "" {
+		r.handle("GET "+prefix, handler)
+	}
+}
+
+// serveIndex writes the SPA index file. It prefers http.ServeContent to
+// http.ServeFileFS, which redirects requests for paths ending in
+// /index.html.
+func serveIndex(w http.ResponseWriter, req *http.Request, fsys fs.FS, index string) {
+	f, err := fsys.Open(index)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	defer f.Close() //nolint:errcheck // read-only file
+	info, err := f.Stat()
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		http.ServeFileFS(w, req, fsys, index)
+		return
+	}
+	http.ServeContent(w, req, index, info.ModTime(), content)
 }
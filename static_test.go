@@ -5,8 +5,10 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -63,3 +65,111 @@ func TestStatic_not_found(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 }
+
+func TestStatic_cache_control(t *testing.T) {
+	t.Parallel()
+
+	fs := fstest.MapFS{
+		"app.3f9a1c.js": &fstest.MapFile{Data: []byte("console.log(1)")},
+	}
+
+	r := api.New()
+	r.Static("/assets/", fs, api.StaticConfig{MaxAge: 24 * time.Hour})
+	r.Static("/files", fs)
+
+	tests := map[string]struct {
+		path      string
+		wantCache string
+	}{
+		"max age":    {path: "/assets/app.3f9a1c.js", wantCache: "public, max-age=86400"},
+		"revalidate": {path: "/files/app.3f9a1c.js", wantCache: "no-cache"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, http.NoBody))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "console.log(1)", w.Body.String())
+			assert.Equal(t, tc.wantCache, w.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func TestSPA(t *testing.T) {
+	t.Parallel()
+
+	fs := fstest.MapFS{
+		"index.html":           &fstest.MapFile{Data: []byte("<html>app</html>")},
+		"assets/app.3f9a1c.js": &fstest.MapFile{Data: []byte("console.log(1)")},
+	}
+
+	r := api.New()
+	api.Get(r, "/api/status", func(_ context.Context, _ *api.Void) (*api.Resp[string], error) {
+		return &api.Resp[string]{Body: "up"}, nil
+	})
+	r.SPA("/", fs, "index.html", api.StaticConfig{MaxAge: time.Hour})
+
+	tests := map[string]struct {
+		path       string
+		wantStatus int
+		wantBody   string
+		wantCache  string
+	}{
+		"root": {
+			path:       "/",
+			wantStatus: http.StatusOK,
+			wantBody:   "<html>app</html>",
+			wantCache:  "no-cache",
+		},
+		"client route": {
+			path:       "/orders/42",
+			wantStatus: http.StatusOK,
+			wantBody:   "<html>app</html>",
+			wantCache:  "no-cache",
+		},
+		"directory": {
+			path:       "/assets",
+			wantStatus: http.StatusOK,
+			wantBody:   "<html>app</html>",
+			wantCache:  "no-cache",
+		},
+		"asset": {
+			path:       "/assets/app.3f9a1c.js",
+			wantStatus: http.StatusOK,
+			wantBody:   "console.log(1)",
+			wantCache:  "public, max-age=3600",
+		},
+		"missing asset": {
+			path:       "/assets/app.0000.js",
+			wantStatus: http.StatusNotFound,
+		},
+		"api route wins": {
+			path:       "/api/status",
+			wantStatus: http.StatusOK,
+			wantBody:   `"up"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, http.NoBody))
+
+			require.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantBody != "" {
+				assert.Equal(t, tc.wantBody, strings.TrimSpace(w.Body.String()))
+			}
+			if tc.wantCache != "" {
+				assert.Equal(t, tc.wantCache, w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+
+	assert.NotContains(t, r.Spec().Paths, "/{path...}")
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// Workflow runs the steps of an operation that spans several calls, undoing
+// the completed ones when a later step fails. Start one with Steps.
+type Workflow struct {
+	ctx    context.Context
+	tracer SpanStarter
+
+	done []workflowStep
+	err  error
+}
+
+type workflowStep struct {
+	name       string
+	compensate func(ctx context.Context) error
+}
+
+// StepFailure is the error detail of a failed Workflow: the step that
+// failed, the completed steps whose compensations ran, in the order they
+// ran, and those whose compensation failed.
+type StepFailure struct {
+	Step               string   `json:"step"`
+	Compensated        []string `json:"compensated,omitempty"`
+	CompensationFailed []string `json:"compensationFailed,omitempty"`
+}
+
+// Steps starts a Workflow in a handler:
+//
+//	err := api.Steps(ctx).
+//	    Do("reserve", inv.Reserve).Compensate(inv.Release).
+//	    Do("charge", pay.Charge).Compensate(pay.Refund).
+//	    Do("ship", ship.Create).
+//	    Err()
+//	if err != nil {
+//	    return nil, err
+//	}
+//
+// Each step runs as Do is called, in a span named "step <name>" when the
+// router has a tracer. Once a step fails, the remaining ones are skipped
+// and the compensations of the completed steps run in reverse order, each
+// in a "compensate <name>" span and with a context that is not canceled
+// with the request.
+func Steps(ctx context.Context) *Workflow {
+	w := &Workflow{ctx: ctx}
+	if r, ok := ctx.Value(routerKey{}).(*Router); ok {
+		w.tracer = r.tracer
+	}
+	return w
+}
+
+// Do runs fn as the step name, unless an earlier step failed.
+func (w *Workflow) Do(name string, fn func(ctx context.Context) error) *Workflow {
+	if w.err != nil {
+		return w
+	}
+	if err := w.run(w.ctx, "step", name, fn); err != nil {
+		w.fail(name, err)
+		return w
+	}
+	w.done = append(w.done, workflowStep{name: name})
+	return w
+}
+
+// Compensate sets how to undo the step added by the preceding Do, should a
+// later step fail. It has no effect after a failure.
+func (w *Workflow) Compensate(fn func(ctx context.Context) error) *Workflow {
+	if w.err == nil && len(w.done) > 0 {
+		w.done[len(w.done)-1].compensate = fn
+	}
+	return w
+}
+
+// Err returns nil when every step succeeded. Otherwise it returns the
+// failed step's error as an *Err carrying a StepFailure detail: an *Err
+// keeps its code, message, and options, and any other error is
+// CodeInternal, as when a handler returns it.
+func (w *Workflow) Err() error { return w.err }
+
+// fail runs the compensations and records the failure of step.
+func (w *Workflow) fail(step string, err error) {
+	failure := StepFailure{Step: step}
+	causes := []error{err}
+	ctx := context.WithoutCancel(w.ctx)
+	for _, s := range slices.Backward(w.done) {
+		if s.compensate == nil {
+			continue
+		}
+		if cerr := w.run(ctx, "compensate", s.name, s.compensate); cerr != nil {
+			failure.CompensationFailed = append(failure.CompensationFailed, s.name)
+			causes = append(causes, cerr)
+			continue
+		}
+		failure.Compensated = append(failure.Compensated, s.name)
+	}
+
+	var apiErr *Err
+	if !errors.As(resolveErr(err), &apiErr) {
+		apiErr = &Err{code: CodeInternal, message: err.Error()}
+	}
+	out := *apiErr
+	out.details = append(slices.Clip(apiErr.details), failure)
+	out.cause = errors.Join(causes...)
+	w.err = &out
+}
+
+// run calls fn in a span when a tracer is set.
+func (w *Workflow) run(ctx context.Context, kind, name string, fn func(ctx context.Context) error) error {
+	if w.tracer != nil {
+		var end func()
+		ctx, end = w.tracer.StartSpan(ctx, kind+" "+name, map[string]string{"step": name})
+		defer end()
+	}
+	return fn(ctx)
+}
@@ -0,0 +1,138 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+// spanRecorder records the names of the spans started and ended.
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []string
+}
+
+func (s *spanRecorder) StartSpan(ctx context.Context, name string, _ map[string]string) (context.Context, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spans = append(s.spans, name)
+	return ctx, func() {}
+}
+
+func TestSteps(t *testing.T) {
+	t.Parallel()
+
+	errDeclined := errors.New("card declined")
+	ok := func(context.Context) error { return nil }
+
+	tests := map[string]struct {
+		charge     error
+		refund     error
+		wantStatus int
+		wantSpans  []string
+		wantDetail map[string]any
+	}{
+		"all steps succeed": {
+			wantStatus: http.StatusNoContent,
+			wantSpans:  []string{"step reserve", "step charge", "step ship"},
+		},
+		"api error keeps its code": {
+			charge:     api.Error(api.CodePaymentRequired, api.WithMessage("card declined")),
+			wantStatus: http.StatusPaymentRequired,
+			wantSpans:  []string{"step reserve", "step charge", "compensate reserve"},
+			wantDetail: map[string]any{"step": "charge", "compensated": []any{"reserve"}},
+		},
+		"plain error is internal": {
+			charge:     errDeclined,
+			refund:     errors.New("unused"),
+			wantStatus: http.StatusInternalServerError,
+			wantSpans:  []string{"step reserve", "step charge", "compensate reserve"},
+			wantDetail: map[string]any{"step": "charge", "compensated": []any{"reserve"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tracer := &spanRecorder{}
+			r := api.New(api.WithTracer(tracer))
+			api.Post(r, "/orders", func(ctx context.Context, _ *api.Void) (*api.Void, error) {
+				err := api.Steps(ctx).
+					Do("reserve", ok).Compensate(ok).
+					Do("charge", func(context.Context) error { return tc.charge }).
+					Compensate(func(context.Context) error { return tc.refund }).
+					Do("ship", ok).
+					Err()
+				if err != nil {
+					return nil, err
+				}
+				return &api.Void{}, nil
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", http.NoBody))
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			assert.Equal(t, tc.wantSpans, tracer.spans)
+			if tc.wantDetail == nil {
+				return
+			}
+			var pd struct {
+				Detail string `json:"detail"`
+				Errors []any  `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pd))
+			assert.Equal(t, "card declined", pd.Detail)
+			assert.Equal(t, []any{tc.wantDetail}, pd.Errors)
+		})
+	}
+}
+
+func TestSteps_compensation(t *testing.T) {
+	t.Parallel()
+
+	errShip := errors.New("no carrier")
+	errRefund := errors.New("refund failed")
+	var undone []string
+	undo := func(name string, err error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			require.NoError(t, ctx.Err())
+			undone = append(undone, name)
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	skipped := true
+	err := api.Steps(ctx).
+		Do("reserve", func(context.Context) error { return nil }).Compensate(undo("reserve", nil)).
+		Do("charge", func(context.Context) error { return nil }).Compensate(undo("charge", errRefund)).
+		Do("notify", func(context.Context) error { return nil }).
+		Do("ship", func(context.Context) error { cancel(); return errShip }).Compensate(undo("ship", nil)).
+		Do("close", func(context.Context) error { skipped = false; return nil }).
+		Err()
+
+	require.Error(t, err)
+	assert.True(t, skipped)
+	assert.Equal(t, []string{"charge", "reserve"}, undone)
+	require.ErrorIs(t, err, errShip)
+	require.ErrorIs(t, err, errRefund)
+
+	var apiErr *api.Err
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, []any{api.StepFailure{
+		Step:               "ship",
+		Compensated:        []string{"reserve"},
+		CompensationFailed: []string{"charge"},
+	}}, apiErr.Details())
+}
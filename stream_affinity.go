@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// StreamAffinityConfig configures StreamAffinity.
+type StreamAffinityConfig struct {
+	// Instance identifies this server instance, such as a pod name.
+	// Default: the hostname.
+	Instance string
+
+	// CookieName is the cookie the load balancer routes on. Default:
+	// "stream_affinity".
+	CookieName string
+
+	// HeaderName, when set, also sends the instance in this response
+	// header, for balancers and clients that route on a header instead.
+	// It is read back from the request like the cookie.
+	HeaderName string
+
+	Secure   bool          // cookie secure flag
+	SameSite http.SameSite // default: Lax
+
+	// OnReconnect is called when a client reconnects with a Last-Event-ID,
+	// with the instance its cookie or header names, or "" when it has
+	// none. The instance differs from this one when the balancer could not
+	// keep the client on its instance, such as after a restart. Return an
+	// error to reject the reconnection; it is written with WriteError.
+	// Without OnReconnect, every reconnection is handled.
+	OnReconnect func(r *http.Request, instance string) error
+}
+
+type streamAffinityKey struct{}
+
+// StreamAffinity returns middleware that pins server-sent event streams to
+// a server instance behind a load balancer with cookie- or header-based
+// session affinity:
+//
+//	events := r.Group("/events", api.WithGroupMiddleware(api.StreamAffinity(api.StreamAffinityConfig{
+//	    Instance: os.Getenv("POD_NAME"),
+//	    OnReconnect: func(r *http.Request, instance string) error {
+//	        if instance != os.Getenv("POD_NAME") {
+//	            metrics.StreamFailovers.Inc()
+//	        }
+//	        return nil
+//	    },
+//	})))
+//
+// Requests that accept text/event-stream get the affinity cookie, and the
+// header when configured, naming this instance, so the browser's automatic
+// reconnection returns to it. A reconnection, which carries Last-Event-ID,
+// passes through OnReconnect first. Handlers read the instance the client
+// was pinned to with ReconnectedFrom, to decide where to resume from.
+// Other requests pass through untouched.
+func StreamAffinity(cfg ...StreamAffinityConfig) Middleware {
+	c := StreamAffinityConfig{
+		CookieName: "stream_affinity",
+		SameSite:   http.SameSiteLaxMode,
+	}
+	if len(cfg) > 0 {
+		in := cfg[0]
+		c.Instance = in.Instance
+		if in.CookieName != "" {
+			c.CookieName = in.CookieName
+		}
+		c.HeaderName = in.HeaderName
+		c.Secure = in.Secure
+		if in.SameSite != 0 {
+			c.SameSite = in.SameSite
+		}
+		c.OnReconnect = in.OnReconnect
+	}
+	if c.Instance == "" {
+		c.Instance, _ = os.Hostname() //nolint:errcheck // falls back to an empty instance
+	}
+
+	return Named("streamaffinity", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.Header.Get("Last-Event-ID") != "" {
+				pinned := ""
+				if ck, err := r.Cookie(c.CookieName); err == nil {
+					pinned = ck.Value
+				} else if c.HeaderName != "" {
+					pinned = r.Header.Get(c.HeaderName)
+				}
+				if c.OnReconnect != nil {
+					if err := c.OnReconnect(r, pinned); err != nil {
+						WriteError(w, r, err)
+						return
+					}
+				}
+				r = r.WithContext(context.WithValue(r.Context(), streamAffinityKey{}, pinned))
+			}
+
+			http.SetCookie(w, &http.Cookie{
+				Name:     c.CookieName,
+				Value:    c.Instance,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   c.Secure,
+				SameSite: c.SameSite,
+			})
+			if c.HeaderName != "" {
+				w.Header().Set(c.HeaderName, c.Instance)
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// ReconnectedFrom returns the instance a reconnecting event stream was
+// pinned to, as seen by StreamAffinity. ok is false for a first
+// connection; instance is "" when the client carried no affinity.
+func ReconnectedFrom(ctx context.Context) (instance string, ok bool) {
+	instance, ok = ctx.Value(streamAffinityKey{}).(string)
+	return instance, ok
+}
@@ -0,0 +1,127 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+func TestStreamAffinity(t *testing.T) {
+	t.Parallel()
+
+	r := api.New()
+	events := r.Group("/events", api.WithGroupMiddleware(api.StreamAffinity(api.StreamAffinityConfig{
+		Instance:   "pod-a",
+		HeaderName: "X-Stream-Instance",
+		OnReconnect: func(_ *http.Request, instance string) error {
+			if instance == "retired" {
+				return api.Error(api.CodeGone, api.WithMessage("stream session expired"))
+			}
+			return nil
+		},
+	})))
+	api.Get(events, "", func(ctx context.Context, _ *api.Void) (*api.Resp[<-chan api.Event], error) {
+		data := "new"
+		if instance, ok := api.ReconnectedFrom(ctx); ok {
+			data = "from:" + instance
+		}
+		ch := make(chan api.Event, 1)
+		ch <- api.Event{Data: data}
+		close(ch)
+		return &api.Resp[<-chan api.Event]{Body: ch}, nil
+	})
+
+	tests := map[string]struct {
+		accept       string
+		lastEventID  string
+		cookie       string
+		header       string
+		wantStatus   int
+		wantBody     string
+		wantAffinity bool
+	}{
+		"first connection": {
+			accept:       "text/event-stream",
+			wantStatus:   http.StatusOK,
+			wantBody:     "new",
+			wantAffinity: true,
+		},
+		"reconnect by cookie": {
+			accept:       "text/event-stream",
+			lastEventID:  "41",
+			cookie:       "pod-b",
+			wantStatus:   http.StatusOK,
+			wantBody:     "from:pod-b",
+			wantAffinity: true,
+		},
+		"reconnect by header": {
+			accept:       "text/event-stream",
+			lastEventID:  "41",
+			header:       "pod-a",
+			wantStatus:   http.StatusOK,
+			wantBody:     "from:pod-a",
+			wantAffinity: true,
+		},
+		"reconnect without affinity": {
+			accept:       "text/event-stream",
+			lastEventID:  "41",
+			wantStatus:   http.StatusOK,
+			wantBody:     "from:",
+			wantAffinity: true,
+		},
+		"reconnect rejected": {
+			accept:      "text/event-stream",
+			lastEventID: "41",
+			cookie:      "retired",
+			wantStatus:  http.StatusGone,
+		},
+		"accept unset": {
+			wantStatus: http.StatusOK,
+			wantBody:   "new",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/events", http.NoBody)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			if tc.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tc.lastEventID)
+			}
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "stream_affinity", Value: tc.cookie})
+			}
+			if tc.header != "" {
+				req.Header.Set("X-Stream-Instance", tc.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantStatus, w.Code, w.Body.String())
+			if tc.wantBody != "" {
+				assert.Equal(t, "data: "+tc.wantBody+"\n\n", w.Body.String())
+			}
+			cookies := w.Result().Cookies()
+			if !tc.wantAffinity {
+				assert.Empty(t, cookies)
+				assert.Empty(t, w.Header().Get("X-Stream-Instance"))
+				return
+			}
+			require.Len(t, cookies, 1)
+			assert.Equal(t, "stream_affinity", cookies[0].Name)
+			assert.Equal(t, "pod-a", cookies[0].Value)
+			assert.True(t, cookies[0].HttpOnly)
+			assert.Equal(t, "pod-a", w.Header().Get("X-Stream-Instance"))
+		})
+	}
+}
@@ -24,3 +24,18 @@ func tagContains(opts string, name string) bool {
 	}
 	return false
 }
+
+// tagValues returns the values of every key=value option named key, in
+// order. `query:"q,alias=search,alias=term"` yields [search term] for
+// "alias".
+func tagValues(opts string, key string) []string {
+	var out []string
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if v, ok := strings.CutPrefix(opt, key+"="); ok && v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
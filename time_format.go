@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type timeEncoding int
+
+const (
+	timeEncRFC3339 timeEncoding = iota
+	timeEncUnix
+	timeEncUnixMilli
+	timeEncLayout
+)
+
+// TimeFormat selects how time.Time values in JSON bodies are encoded and
+// decoded. Use one of the predefined formats or TimeLayout.
+type TimeFormat struct {
+	enc    timeEncoding
+	layout string
+}
+
+// Predefined time formats for WithTimeFormat.
+var (
+	// TimeRFC3339 encodes times as RFC 3339 strings (the encoding/json default).
+	TimeRFC3339 = TimeFormat{enc: timeEncRFC3339}
+
+	// TimeUnix encodes times as integer seconds since the Unix epoch.
+	TimeUnix = TimeFormat{enc: timeEncUnix}
+
+	// TimeUnixMilli encodes times as integer milliseconds since the Unix epoch.
+	TimeUnixMilli = TimeFormat{enc: timeEncUnixMilli}
+)
+
+// TimeLayout encodes times as strings in the given time.Format layout.
+func TimeLayout(layout string) TimeFormat {
+	return TimeFormat{enc: timeEncLayout, layout: layout}
+}
+
+// WithTimeFormat sets the encoding of time.Time and *time.Time fields in
+// JSON request and response bodies, and documents it in the spec. This
+// avoids a MarshalJSON method on every type in codebases standardized on
+// e.g. epoch milliseconds:
+//
+//	r := api.New(api.WithTimeFormat(api.TimeUnixMilli))
+//
+// Types implementing json.Marshaler or json.Unmarshaler keep their own
+// encoding, and times held in interface-typed fields are not converted.
+// Other codecs (XML, custom) are unaffected.
+func WithTimeFormat(f TimeFormat) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.timeFormat = f
+	})
+}
+
+// schema returns the JSON Schema describing a time in this format.
+func (f TimeFormat) schema() JSONSchema {
+	switch f.enc {
+	case timeEncUnix:
+		return JSONSchema{Type: "integer", Format: "int64", Description: "Unix time in seconds"}
+	case timeEncUnixMilli:
+		return JSONSchema{Type: "integer", Format: "int64", Description: "Unix time in milliseconds"}
+	case timeEncLayout:
+		return JSONSchema{Type: "string", Description: "Time in Go layout " + strconv.Quote(f.layout)}
+	case timeEncRFC3339:
+	}
+	return JSONSchema{Type: "string", Format: "date-time"}
+}
+
+func (f TimeFormat) appendJSON(b []byte, t time.Time) []byte {
+	switch f.enc {
+	case timeEncUnix:
+		return strconv.AppendInt(b, t.Unix(), 10)
+	case timeEncUnixMilli:
+		return strconv.AppendInt(b, t.UnixMilli(), 10)
+	case timeEncLayout:
+		return strconv.AppendQuote(b, t.Format(f.layout))
+	case timeEncRFC3339:
+	}
+	return strconv.AppendQuote(b, t.Format(time.RFC3339Nano))
+}
+
+func (f TimeFormat) parseJSON(data []byte) (time.Time, error) {
+	switch f.enc {
+	case timeEncUnix, timeEncUnixMilli:
+		n, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("time: expected integer, got %s", data)
+		}
+		if f.enc == timeEncUnix {
+			return time.Unix(n, 0).UTC(), nil
+		}
+		return time.UnixMilli(n).UTC(), nil
+	case timeEncLayout:
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return time.Time{}, fmt.Errorf("time: expected string, got %s", data)
+		}
+		return time.Parse(f.layout, s)
+	case timeEncRFC3339:
+	}
+	var t time.Time
+	err := t.UnmarshalJSON(data)
+	return t, err
+}
+
+// jsonConverter returns the mirror converter for time.Time in this format.
+func (f TimeFormat) jsonConverter() jsonConverter {
+	return jsonConverter{
+		encode: func(v reflect.Value) ([]byte, error) {
+			return f.appendJSON(nil, v.Interface().(time.Time)), nil //nolint:forcetypeassert // registered for time.Time
+		},
+		decode: func(data []byte, dst reflect.Value) error {
+			t, err := f.parseJSON(data)
+			if err != nil {
+				return err
+			}
+			dst.Set(reflect.ValueOf(t))
+			return nil
+		},
+	}
+}
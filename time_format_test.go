@@ -0,0 +1,221 @@
+package api_test
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type tfAudit struct {
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type tfNode struct {
+	Name     string    `json:"name"`
+	At       time.Time `json:"at"`
+	Children []tfNode  `json:"children,omitempty"`
+}
+
+type tfEvent struct {
+	tfAudit
+	Name     string               `json:"name"`
+	At       time.Time            `json:"at"`
+	Deleted  *time.Time           `json:"deleted,omitempty"`
+	History  []time.Time          `json:"history"`
+	ByRegion map[string]time.Time `json:"byRegion"`
+	Tree     *tfNode              `json:"tree,omitempty"`
+}
+
+func TestWithTimeFormat_encode(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		format api.TimeFormat
+		want   string
+	}{
+		"unix millis": {
+			format: api.TimeUnixMilli,
+			want:   `{"createdAt":1709294400000,"name":"e","at":1709294400000,"history":[1709294400000],"byRegion":{"eu":1709294400000},"tree":{"name":"root","at":1709294400000,"children":[{"name":"leaf","at":1709294400000}]}}`,
+		},
+		"unix seconds": {
+			format: api.TimeUnix,
+			want:   `{"createdAt":1709294400,"name":"e","at":1709294400,"history":[1709294400],"byRegion":{"eu":1709294400},"tree":{"name":"root","at":1709294400,"children":[{"name":"leaf","at":1709294400}]}}`,
+		},
+		"layout": {
+			format: api.TimeLayout(time.DateOnly),
+			want:   `{"createdAt":"2024-03-01","name":"e","at":"2024-03-01","history":["2024-03-01"],"byRegion":{"eu":"2024-03-01"},"tree":{"name":"root","at":"2024-03-01","children":[{"name":"leaf","at":"2024-03-01"}]}}`,
+		},
+		"rfc3339 default": {
+			format: api.TimeRFC3339,
+			want:   `{"createdAt":"2024-03-01T12:00:00Z","name":"e","at":"2024-03-01T12:00:00Z","history":["2024-03-01T12:00:00Z"],"byRegion":{"eu":"2024-03-01T12:00:00Z"},"tree":{"name":"root","at":"2024-03-01T12:00:00Z","children":[{"name":"leaf","at":"2024-03-01T12:00:00Z"}]}}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := api.New(api.WithTimeFormat(tt.format))
+			api.Get(r, "/event", func(_ context.Context, _ *api.Void) (*api.Resp[tfEvent], error) {
+				return &api.Resp[tfEvent]{Body: tfEvent{
+					tfAudit:  tfAudit{CreatedAt: at},
+					Name:     "e",
+					At:       at,
+					History:  []time.Time{at},
+					ByRegion: map[string]time.Time{"eu": at},
+					Tree: &tfNode{Name: "root", At: at, Children: []tfNode{
+						{Name: "leaf", At: at},
+					}},
+				}}, nil
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/event", nil))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, tt.want, w.Body.String())
+		})
+	}
+}
+
+func TestWithTimeFormat_decode(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Body tfEvent
+	}
+
+	var got tfEvent
+	r := api.New(api.WithTimeFormat(api.TimeUnixMilli))
+	api.Post(r, "/event", func(_ context.Context, req *Req) (*api.Void, error) {
+		got = req.Body
+		return &api.Void{}, nil
+	})
+
+	body := `{"createdAt":1709294400000,"name":"e","at":1709294400000,"deleted":1709294400500,"history":[0],"tree":{"name":"root","at":1000,"children":[{"name":"leaf","at":2000}]}}`
+	req := httptest.NewRequest(http.MethodPost, "/event", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, at, got.CreatedAt)
+	assert.Equal(t, at, got.At)
+	require.NotNil(t, got.Deleted)
+	assert.Equal(t, at.Add(500*time.Millisecond), *got.Deleted)
+	assert.Equal(t, []time.Time{time.UnixMilli(0).UTC()}, got.History)
+	assert.Nil(t, got.ByRegion)
+	require.NotNil(t, got.Tree)
+	assert.Equal(t, time.UnixMilli(1000).UTC(), got.Tree.At)
+	require.Len(t, got.Tree.Children, 1)
+	assert.Equal(t, time.UnixMilli(2000).UTC(), got.Tree.Children[0].At)
+}
+
+func TestWithTimeFormat_decode_rejects_wrong_type(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Body tfAudit
+	}
+
+	r := api.New(api.WithTimeFormat(api.TimeUnix))
+	api.Post(r, "/audit", func(_ context.Context, _ *Req) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/audit", strings.NewReader(`{"createdAt":"2024-03-01T12:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWithTimeFormat_schema(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.WithTimeFormat(api.TimeUnixMilli))
+	api.Get(r, "/audit", func(_ context.Context, _ *api.Void) (*api.Resp[tfAudit], error) {
+		return nil, nil //nolint:nilnil // never called
+	})
+
+	schema := r.Spec().Components.Schemas["tfAudit"].Properties["createdAt"]
+	assert.Equal(t, "integer", schema.Type)
+	assert.Equal(t, "int64", schema.Format)
+	assert.Equal(t, "Unix time in milliseconds", schema.Description)
+
+	def := api.New()
+	api.Get(def, "/audit", func(_ context.Context, _ *api.Void) (*api.Resp[tfAudit], error) {
+		return nil, nil //nolint:nilnil // never called
+	})
+	assert.Equal(t, "date-time", def.Spec().Components.Schemas["tfAudit"].Properties["createdAt"].Format)
+}
+
+func TestWithTimeFormat_streams(t *testing.T) {
+	t.Parallel()
+
+	at := time.UnixMilli(1700000000000).UTC()
+	r := api.New(api.WithTimeFormat(api.TimeUnixMilli))
+	api.Get(r, "/array", func(_ context.Context, _ *api.Void) (*api.Resp[api.JSONArrayStream[tfAudit]], error) {
+		ch := make(chan tfAudit, 1)
+		ch <- tfAudit{CreatedAt: at}
+		close(ch)
+		return &api.Resp[api.JSONArrayStream[tfAudit]]{Body: ch}, nil
+	})
+	api.Get(r, "/ndjson", func(_ context.Context, _ *api.Void) (*api.Resp[api.NDJSONStream[tfAudit]], error) {
+		ch := make(chan tfAudit, 1)
+		ch <- tfAudit{CreatedAt: at}
+		close(ch)
+		return &api.Resp[api.NDJSONStream[tfAudit]]{Body: ch}, nil
+	})
+	api.Get(r, "/seq", func(_ context.Context, _ *api.Void) (*api.Resp[iter.Seq[tfAudit]], error) {
+		return &api.Resp[iter.Seq[tfAudit]]{Body: slices.Values([]tfAudit{{CreatedAt: at}})}, nil
+	})
+	api.Get(r, "/events", func(_ context.Context, _ *api.Void) (*api.Resp[<-chan api.Event], error) {
+		ch := make(chan api.Event, 1)
+		ch <- api.Event{Data: tfAudit{CreatedAt: at}}
+		close(ch)
+		return &api.Resp[<-chan api.Event]{Body: ch}, nil
+	})
+
+	tests := map[string]struct {
+		path   string
+		accept string
+		want   string
+	}{
+		"json array": {path: "/array", want: `[{"createdAt":1700000000000}]` + "\n"},
+		"ndjson":     {path: "/ndjson", want: `{"createdAt":1700000000000}` + "\n"},
+		"seq":        {path: "/seq", want: `[{"createdAt":1700000000000}]` + "\n"},
+		"seq ndjson": {path: "/seq", accept: "application/x-ndjson", want: `{"createdAt":1700000000000}` + "\n"},
+		"events":     {path: "/events", accept: "text/event-stream", want: `data: {"createdAt":1700000000000}` + "\n\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, http.NoBody)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, tc.want, w.Body.String())
+		})
+	}
+}
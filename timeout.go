@@ -10,11 +10,11 @@ import (
 // If the handler does not complete within the duration, a 503 Service
 // Unavailable response is sent.
 func Timeout(d time.Duration) Middleware {
-	return func(next http.Handler) http.Handler {
+	return Named("timeout", func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx, cancel := context.WithTimeout(r.Context(), d)
 			defer cancel()
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
-	}
+	})
 }
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeRange holds the query parameters of a time window. Embed it in a
+// request to accept either absolute bounds or a relative range ending now:
+//
+//	type MetricsReq struct {
+//	    api.TimeRange `default:"last_7d" maxSpan:"90d"`
+//	    Metric string `query:"metric"`
+//	}
+//
+// Both ?from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z and
+// ?range=last_24h resolve to From and To before the handler runs; a
+// missing to means now. When the client sends neither, the default tag on
+// the embedded field applies, and without one From and To stay zero. The
+// range is rejected with 400 when from is after to, when range is combined
+// with from or to, or when it spans more than the maxSpan tag. Spans and
+// relative ranges are a number followed by m, h, d, or w.
+type TimeRange struct {
+	From  time.Time `query:"from" doc:"Start of the time range (RFC 3339)"`
+	To    time.Time `query:"to" doc:"End of the time range (RFC 3339); defaults to now"`
+	Range string    `query:"range" doc:"Relative time range ending now, such as last_24h or last_7d; instead of from and to"`
+}
+
+// Span returns the duration between From and To.
+func (t TimeRange) Span() time.Duration { return t.To.Sub(t.From) }
+
+// IsZero reports whether the range is unset.
+func (t TimeRange) IsZero() bool { return t.From.IsZero() && t.To.IsZero() }
+
+var timeRangeType = reflect.TypeFor[TimeRange]()
+
+// relativeRangePattern documents the form of the range parameter. It is
+// not a pattern tag, which would reject the empty value when range is
+// absent.
+const relativeRangePattern = "^last_[0-9]+[mhdw]$"
+
+// timeRangeDesc locates an embedded TimeRange and holds the default and
+// maxSpan tags of the embedding field.
+type timeRangeDesc struct {
+	index   []int
+	def     time.Duration // relative default range; 0 for none
+	maxSpan time.Duration // 0 for no limit
+	maxTag  string        // maxSpan as written, for error messages
+}
+
+// newTimeRangeDesc parses the tags of an embedded TimeRange field.
+func newTimeRangeDesc(f reflect.StructField) (timeRangeDesc, error) {
+	d := timeRangeDesc{index: f.Index}
+	if tag := f.Tag.Get("default"); tag != "" {
+		def, err := parseRelativeRange(tag)
+		if err != nil {
+			return d, fmt.Errorf("invalid default tag on TimeRange: %w", err)
+		}
+		d.def = def
+	}
+	if tag := f.Tag.Get("maxSpan"); tag != "" {
+		span, err := parseSpan(tag)
+		if err != nil {
+			return d, fmt.Errorf("invalid maxSpan tag on TimeRange: %w", err)
+		}
+		d.maxSpan, d.maxTag = span, tag
+	}
+	if d.maxSpan > 0 && d.def > d.maxSpan {
+		return d, errors.New("TimeRange default exceeds its maxSpan")
+	}
+	return d, nil
+}
+
+// resolve fills From and To from the bound query values and checks them.
+func (d timeRangeDesc) resolve(tr *TimeRange, now time.Time) error {
+	switch {
+	case tr.Range != "":
+		if !tr.From.IsZero() || !tr.To.IsZero() {
+			return errors.New("range cannot be combined with from or to")
+		}
+		span, err := parseRelativeRange(tr.Range)
+		if err != nil {
+			return fmt.Errorf("range: %w", err)
+		}
+		tr.From, tr.To = now.Add(-span), now
+	case tr.From.IsZero() && tr.To.IsZero():
+		if d.def == 0 {
+			return nil
+		}
+		tr.From, tr.To = now.Add(-d.def), now
+	case tr.From.IsZero():
+		return errors.New("from is required with to")
+	case tr.To.IsZero():
+		tr.To = now
+	}
+
+	if tr.From.After(tr.To) {
+		return errors.New("from must not be after to")
+	}
+	if d.maxSpan > 0 && tr.Span() > d.maxSpan {
+		return fmt.Errorf("time range exceeds the maximum span of %s", d.maxTag)
+	}
+	return nil
+}
+
+// parseRelativeRange parses a relative range such as last_7d.
+func parseRelativeRange(s string) (time.Duration, error) {
+	span, ok := strings.CutPrefix(s, "last_")
+	if !ok {
+		return 0, fmt.Errorf("%q is not of the form last_<n><unit>", s)
+	}
+	return parseSpan(span)
+}
+
+var spanUnits = map[byte]time.Duration{
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// parseSpan parses a positive span such as 90m, 24h, 7d, or 2w.
+func parseSpan(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, errors.New("empty span")
+	}
+	unit, ok := spanUnits[s[len(s)-1]]
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if !ok || err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q is not a number followed by m, h, d, or w", s)
+	}
+	return time.Duration(n) * unit, nil
+}
@@ -0,0 +1,138 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type trMetricsReq struct {
+	api.TimeRange `default:"last_7d" maxSpan:"30d"`
+	Metric        string `query:"metric"`
+}
+
+func newTimeRangeRouter() *api.Router {
+	r := api.New()
+	api.Get(r, "/metrics", func(_ context.Context, req *trMetricsReq) (*api.Resp[api.TimeRange], error) {
+		return &api.Resp[api.TimeRange]{Body: req.TimeRange}, nil
+	})
+	return r
+}
+
+func TestTimeRange(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		query      string
+		wantStatus int
+		wantSpan   time.Duration
+		wantFrom   string
+	}{
+		"absolute": {
+			query:      "from=2026-01-01T00:00:00Z&to=2026-01-02T00:00:00Z",
+			wantStatus: http.StatusOK,
+			wantSpan:   24 * time.Hour,
+			wantFrom:   "2026-01-01T00:00:00Z",
+		},
+		"relative": {
+			query:      "range=last_36h",
+			wantStatus: http.StatusOK,
+			wantSpan:   36 * time.Hour,
+		},
+		"default": {
+			wantStatus: http.StatusOK,
+			wantSpan:   7 * 24 * time.Hour,
+		},
+		"from without to ends now": {
+			query:      "from=" + time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+			wantStatus: http.StatusOK,
+		},
+		"from after to": {
+			query:      "from=2026-01-02T00:00:00Z&to=2026-01-01T00:00:00Z",
+			wantStatus: http.StatusBadRequest,
+		},
+		"to without from": {
+			query:      "to=2026-01-01T00:00:00Z",
+			wantStatus: http.StatusBadRequest,
+		},
+		"range with from": {
+			query:      "range=last_1d&from=2026-01-01T00:00:00Z",
+			wantStatus: http.StatusBadRequest,
+		},
+		"span too long": {
+			query:      "range=last_5w",
+			wantStatus: http.StatusBadRequest,
+		},
+		"malformed range": {
+			query:      "range=yesterday",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	r := newTimeRangeRouter()
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics?"+tc.query, http.NoBody))
+
+			require.Equal(t, tc.wantStatus, w.Code, w.Body.String())
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var got api.TimeRange
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.False(t, got.From.After(got.To))
+			if tc.wantSpan != 0 {
+				assert.Equal(t, tc.wantSpan, got.Span())
+			}
+			if tc.wantFrom != "" {
+				assert.Equal(t, tc.wantFrom, got.From.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestTimeRange_invalid_tags_panic(t *testing.T) {
+	t.Parallel()
+
+	type badDefault struct {
+		api.TimeRange `default:"yesterday"`
+	}
+	type defaultOverMax struct {
+		api.TimeRange `default:"last_30d" maxSpan:"7d"`
+	}
+
+	assert.Panics(t, func() {
+		api.Get(api.New(), "/a", func(_ context.Context, _ *badDefault) (*api.Void, error) { return nil, nil })
+	})
+	assert.Panics(t, func() {
+		api.Get(api.New(), "/b", func(_ context.Context, _ *defaultOverMax) (*api.Void, error) { return nil, nil })
+	})
+}
+
+func TestTimeRange_spec(t *testing.T) {
+	t.Parallel()
+
+	op := newTimeRangeRouter().Spec().Paths["/metrics"]["get"]
+
+	params := make(map[string]api.Parameter)
+	for _, p := range op.Parameters {
+		params[p.Name] = p
+	}
+	require.Contains(t, params, "range")
+	assert.Equal(t, "last_7d", params["range"].Schema.Default)
+	assert.Equal(t, "^last_[0-9]+[mhdw]$", params["range"].Schema.Pattern)
+	assert.Contains(t, params["from"].Description, "at most 30d")
+	assert.Equal(t, "date-time", params["to"].Schema.Format)
+	assert.Contains(t, params, "metric")
+}
@@ -44,3 +44,31 @@ func ParseFileUpload(r *http.Request, fieldName string) (*FileUpload, error) {
 		file:     file,
 	}, nil
 }
+
+// StreamBody is a request Body field type for large non-multipart uploads.
+// The request body is not buffered or decoded; read it directly, e.g. to
+// pipe it to object storage:
+//
+//	type PutObjectReq struct {
+//	    Key  string         `path:"key"`
+//	    Body api.StreamBody `content:"application/octet-stream"`
+//	}
+//
+//	func (h *H) Put(ctx context.Context, req *PutObjectReq) (*api.Void, error) {
+//	    return &api.Void{}, h.bucket.Upload(ctx, req.Key, req.Body, req.Body.ContentType)
+//	}
+//
+// The optional content tag sets the documented media type (default
+// application/octet-stream). Body limits still apply to the stream.
+type StreamBody struct {
+	io.Reader
+
+	// ContentType is the request's Content-Type header.
+	ContentType string
+
+	// Length is the declared Content-Length, or -1 when unknown.
+	Length int64
+}
+
+// defaultStreamContentType documents StreamBody routes without a content tag.
+const defaultStreamContentType = "application/octet-stream"
@@ -3,9 +3,13 @@ package api_test
 import (
 	"bytes"
 	"context"
+	"errors"
+	"io"
 	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -243,3 +247,97 @@ func TestFileUpload_Open_header_open_error_via_bad_tmpfile(t *testing.T) {
 		assert.Error(t, openErr)
 	}
 }
+
+func TestStreamBody(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Key  string         `path:"key"`
+		Body api.StreamBody `content:"video/mp4"`
+	}
+	type Resp struct {
+		Key         string `json:"key"`
+		Size        int    `json:"size"`
+		ContentType string `json:"contentType"`
+		Length      int64  `json:"length"`
+	}
+
+	r := api.New()
+	api.Put(r, "/objects/{key}", func(_ context.Context, req *Req) (*api.Resp[Resp], error) {
+		data, err := io.ReadAll(req.Body)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, api.Error(api.CodeContentTooLarge)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &api.Resp[Resp]{Body: Resp{
+			Key:         req.Key,
+			Size:        len(data),
+			ContentType: req.Body.ContentType,
+			Length:      req.Body.Length,
+		}}, nil
+	}, api.WithBodyLimit(1024))
+
+	tests := map[string]struct {
+		body     string
+		wantCode int
+		want     string
+	}{
+		"streams raw bytes": {
+			body:     "not json at all",
+			wantCode: http.StatusOK,
+			want:     `{"key":"clip","size":15,"contentType":"video/mp4","length":15}`,
+		},
+		"body limit applies": {
+			body:     strings.Repeat("x", 2048),
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPut, "/objects/clip", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "video/mp4")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.want != "" {
+				assert.JSONEq(t, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	body := r.Spec().Paths["/objects/{key}"]["put"].RequestBody
+	require.NotNil(t, body)
+	require.Len(t, body.Content, 1)
+	media, ok := body.Content["video/mp4"]
+	require.True(t, ok)
+	assert.Equal(t, "binary", media.Schema.Format)
+
+	_, warnings := r.SpecWithDiagnostics()
+	for _, w := range warnings {
+		assert.NotEqual(t, api.WarnEmptySchema, w.Kind, w.String())
+	}
+}
+
+func TestStreamBody_default_content_type(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		Body api.StreamBody
+	}
+
+	r := api.New()
+	api.Post(r, "/blobs", func(_ context.Context, _ *Req) (*api.Void, error) {
+		return &api.Void{}, nil
+	})
+
+	body := r.Spec().Paths["/blobs"]["post"].RequestBody
+	require.NotNil(t, body)
+	assert.Contains(t, body.Content, "application/octet-stream")
+}
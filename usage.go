@@ -0,0 +1,288 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// UsageRecord aggregates one client's requests to one operation over a
+// flush window.
+type UsageRecord struct {
+	// Client identifies the caller, as returned by UsageConfig.Client.
+	Client string `json:"client"`
+
+	// Operation is the matched route as "METHOD pattern". It is empty for
+	// requests no registered operation served, such as unmatched paths and
+	// ServeSpec endpoints.
+	Operation string `json:"operation"`
+
+	// Start and End bound the window the counts cover.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	Requests     int64 `json:"requests"`
+	ClientErrors int64 `json:"clientErrors"` // 4xx responses
+	ServerErrors int64 `json:"serverErrors"` // 5xx responses
+
+	// TotalLatency and MaxLatency are encoded as nanoseconds in JSON.
+	TotalLatency time.Duration `json:"totalLatency"`
+	MaxLatency   time.Duration `json:"maxLatency"`
+}
+
+// ErrorRate returns the fraction of requests that ended in a 4xx or 5xx.
+func (u UsageRecord) ErrorRate() float64 {
+	if u.Requests == 0 {
+		return 0
+	}
+	return float64(u.ClientErrors+u.ServerErrors) / float64(u.Requests)
+}
+
+// MeanLatency returns the average request latency.
+func (u UsageRecord) MeanLatency() time.Duration {
+	if u.Requests == 0 {
+		return 0
+	}
+	return u.TotalLatency / time.Duration(u.Requests)
+}
+
+// add folds one request into u.
+func (u *UsageRecord) add(status int, latency time.Duration) {
+	u.Requests++
+	switch {
+	case status >= 500:
+		u.ServerErrors++
+	case status >= 400:
+		u.ClientErrors++
+	}
+	u.TotalLatency += latency
+	u.MaxLatency = max(u.MaxLatency, latency)
+}
+
+// merge folds another window's counts into u and widens its bounds.
+func (u *UsageRecord) merge(o UsageRecord) {
+	if u.Start.IsZero() || o.Start.Before(u.Start) {
+		u.Start = o.Start
+	}
+	if o.End.After(u.End) {
+		u.End = o.End
+	}
+	u.Requests += o.Requests
+	u.ClientErrors += o.ClientErrors
+	u.ServerErrors += o.ServerErrors
+	u.TotalLatency += o.TotalLatency
+	u.MaxLatency = max(u.MaxLatency, o.MaxLatency)
+}
+
+// UsageSink receives the records of each closed window from Usage. Write
+// them to a database, a log, or a billing system; UsageReport keeps them in
+// memory.
+type UsageSink interface {
+	WriteUsage(ctx context.Context, records []UsageRecord) error
+}
+
+// UsageSinkFunc adapts a function to the UsageSink interface.
+type UsageSinkFunc func(ctx context.Context, records []UsageRecord) error
+
+// WriteUsage implements UsageSink.
+func (f UsageSinkFunc) WriteUsage(ctx context.Context, records []UsageRecord) error {
+	return f(ctx, records)
+}
+
+// UsageConfig configures the Usage middleware.
+type UsageConfig struct {
+	// Sink receives each window's records. Required.
+	Sink UsageSink
+
+	// Client identifies the caller. Defaults to the principal's ID, or
+	// "anonymous" for unauthenticated requests, so install Usage after the
+	// authentication middleware.
+	Client func(r *http.Request) string
+
+	// FlushInterval is the window length. Defaults to one minute.
+	FlushInterval time.Duration
+
+	// OnError is called when the sink fails; the window's records are
+	// dropped. Defaults to logging with slog.
+	OnError func(err error)
+}
+
+type usageKey struct{ client, operation string }
+
+type usageRouteKey struct{}
+
+// usageRoute carries the matched pattern back out of the mux, which sets
+// it on its own copy of the request.
+type usageRoute struct {
+	pattern string
+}
+
+// Usage returns middleware that counts requests, errors, and latency per
+// client and operation, for lightweight analytics without a metrics
+// pipeline:
+//
+//	report := api.NewUsageReport()
+//	r.Use(auth, api.Usage(api.UsageConfig{Sink: report}))
+//	r.ServeUsageReport("/admin/usage", report)
+//
+// Counts accumulate in memory and are handed to the sink once per
+// FlushInterval. A window is flushed, in the background, by the first
+// request to finish after it closes, so an idle server holds its last
+// window until traffic resumes.
+func Usage(cfg UsageConfig) Middleware {
+	if cfg.Sink == nil {
+		panic("api: Usage requires a Sink")
+	}
+	if cfg.Client == nil {
+		cfg.Client = func(r *http.Request) string {
+			if p, ok := GetPrincipal(r.Context()); ok && p.ID != "" {
+				return p.ID
+			}
+			return "anonymous"
+		}
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Minute
+	}
+	if cfg.OnError == nil {
+		cfg.OnError = func(err error) {
+			slog.Error("usage sink failed", "error", err)
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		start   = time.Now()
+		records = make(map[usageKey]*UsageRecord)
+	)
+
+	return Named("usage", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			begin := time.Now()
+			route := &usageRoute{}
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), usageRouteKey{}, route)))
+			now := time.Now()
+
+			key := usageKey{client: cfg.Client(r), operation: route.pattern}
+
+			mu.Lock()
+			u, ok := records[key]
+			if !ok {
+				u = &UsageRecord{Client: key.client, Operation: key.operation}
+				records[key] = u
+			}
+			u.add(rec.status, now.Sub(begin))
+
+			var closed []UsageRecord
+			if now.Sub(start) >= cfg.FlushInterval {
+				closed = make([]UsageRecord, 0, len(records))
+				for _, u := range records {
+					u.Start, u.End = start, now
+					closed = append(closed, *u)
+				}
+				start = now
+				records = make(map[usageKey]*UsageRecord)
+			}
+			mu.Unlock()
+
+			if closed != nil {
+				go func() {
+					if err := cfg.Sink.WriteUsage(context.Background(), closed); err != nil {
+						cfg.OnError(err)
+					}
+				}()
+			}
+		})
+	})
+}
+
+// setUsageRoute records the matched route pattern for Usage.
+func setUsageRoute(r *http.Request) {
+	if u, ok := r.Context().Value(usageRouteKey{}).(*usageRoute); ok {
+		u.pattern = r.Pattern
+	}
+}
+
+// UsageReport is an in-memory UsageSink that totals every window it
+// receives. It is also a UsageSource, so the same counts can feed
+// DeprecationReport.
+type UsageReport struct {
+	mu      sync.Mutex
+	records map[usageKey]*UsageRecord
+}
+
+// NewUsageReport returns an empty UsageReport.
+func NewUsageReport() *UsageReport {
+	return &UsageReport{records: make(map[usageKey]*UsageRecord)}
+}
+
+// WriteUsage implements UsageSink.
+func (u *UsageReport) WriteUsage(_ context.Context, records []UsageRecord) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, rec := range records {
+		key := usageKey{client: rec.Client, operation: rec.Operation}
+		total, ok := u.records[key]
+		if !ok {
+			total = &UsageRecord{Client: rec.Client, Operation: rec.Operation}
+			u.records[key] = total
+		}
+		total.merge(rec)
+	}
+	return nil
+}
+
+// Records returns the totals per client and operation, sorted by client
+// and then operation.
+func (u *UsageReport) Records() []UsageRecord {
+	u.mu.Lock()
+	out := make([]UsageRecord, 0, len(u.records))
+	for _, rec := range u.records {
+		out = append(out, *rec)
+	}
+	u.mu.Unlock()
+
+	slices.SortFunc(out, func(a, b UsageRecord) int {
+		return cmp.Or(cmp.Compare(a.Client, b.Client), cmp.Compare(a.Operation, b.Operation))
+	})
+	return out
+}
+
+// Calls implements UsageSource, summing the route's requests across
+// clients.
+func (u *UsageReport) Calls(_ context.Context, route RouteDescription) (int64, error) {
+	op := route.Method + " " + route.Host + route.Pattern
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	var n int64
+	for key, rec := range u.records {
+		if key.operation == op {
+			n += rec.Requests
+		}
+	}
+	return n, nil
+}
+
+// Reset discards every total, starting a new reporting period.
+func (u *UsageReport) Reset() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	clear(u.records)
+}
+
+// ServeUsageReport registers a GET handler at the given path that serves
+// the report's records as JSON. Like ServeSpec, the endpoint is not part
+// of the spec; protect it with middleware when exposed publicly.
+func (r *Router) ServeUsageReport(pattern string, report *UsageReport) {
+	r.handle("GET "+pattern, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck,gosec // best-effort after WriteHeader
+		json.NewEncoder(w).Encode(report.Records())
+	}))
+}
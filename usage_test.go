@@ -0,0 +1,181 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+type usgItemReq struct {
+	ID string `path:"id"`
+}
+
+func newUsageRouter(cfg api.UsageConfig) *api.Router {
+	r := api.New()
+	r.Use(withPrincipal, api.Usage(cfg))
+	api.Get(r, "/items/{id}", func(_ context.Context, req *usgItemReq) (*api.Void, error) {
+		if req.ID == "missing" {
+			return nil, api.Error(api.CodeNotFound)
+		}
+		if req.ID == "broken" {
+			return nil, errors.New("boom")
+		}
+		return &api.Void{}, nil
+	})
+	return r
+}
+
+func usageGet(r http.Handler, path, roles string) {
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, path, nil)
+	if roles != "" {
+		req.Header.Set("X-Roles", roles)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestUsage_holdsOpenWindow(t *testing.T) {
+	t.Parallel()
+
+	flushed := make(chan []api.UsageRecord, 1)
+	sink := api.UsageSinkFunc(func(_ context.Context, records []api.UsageRecord) error {
+		flushed <- records
+		return nil
+	})
+	r := newUsageRouter(api.UsageConfig{Sink: sink, FlushInterval: time.Hour})
+
+	usageGet(r, "/items/a", "admin")
+	usageGet(r, "/items/missing", "admin")
+	usageGet(r, "/items/broken", "admin")
+	usageGet(r, "/items/a", "")
+	usageGet(r, "/nope", "")
+
+	select {
+	case <-flushed:
+		t.Fatal("window flushed before it closed")
+	default:
+	}
+}
+
+func TestUsage_report(t *testing.T) {
+	t.Parallel()
+
+	report := api.NewUsageReport()
+	r := newUsageRouter(api.UsageConfig{Sink: report, FlushInterval: time.Nanosecond})
+	r.ServeUsageReport("/admin/usage", report)
+
+	usageGet(r, "/items/a", "admin")
+	usageGet(r, "/items/missing", "admin")
+	usageGet(r, "/items/broken", "admin")
+	usageGet(r, "/items/a", "")
+	usageGet(r, "/nope", "")
+
+	// Every request closes its own window; flushes run in the background.
+	require.Eventually(t, func() bool {
+		var n int64
+		for _, rec := range report.Records() {
+			n += rec.Requests
+		}
+		return n == 5
+	}, time.Second, time.Millisecond)
+
+	records := report.Records()
+	require.Len(t, records, 3)
+
+	anon := records[0]
+	assert.Equal(t, "anonymous", anon.Client)
+	assert.Empty(t, anon.Operation)
+	assert.Equal(t, int64(1), anon.ClientErrors)
+
+	assert.Equal(t, "anonymous", records[1].Client)
+	assert.Equal(t, "GET /items/{id}", records[1].Operation)
+	assert.Equal(t, int64(1), records[1].Requests)
+
+	u1 := records[2]
+	assert.Equal(t, "u1", u1.Client)
+	assert.Equal(t, "GET /items/{id}", u1.Operation)
+	assert.Equal(t, int64(3), u1.Requests)
+	assert.Equal(t, int64(1), u1.ClientErrors)
+	assert.Equal(t, int64(1), u1.ServerErrors)
+	assert.InDelta(t, 2.0/3.0, u1.ErrorRate(), 1e-9)
+	assert.GreaterOrEqual(t, u1.MaxLatency, u1.MeanLatency())
+	assert.False(t, u1.Start.After(u1.End))
+
+	calls, err := report.Calls(context.Background(), api.RouteDescription{Method: http.MethodGet, Pattern: "/items/{id}"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), calls)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/admin/usage", nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var served []api.UsageRecord
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	assert.Len(t, served, 3)
+}
+
+func TestUsageReport_totalsWindows(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := api.NewUsageReport()
+	require.NoError(t, report.WriteUsage(context.Background(), []api.UsageRecord{{
+		Client: "k", Operation: "GET /x", Start: t0, End: t0.Add(time.Minute),
+		Requests: 2, ServerErrors: 1, TotalLatency: 4 * time.Millisecond, MaxLatency: 3 * time.Millisecond,
+	}}))
+	require.NoError(t, report.WriteUsage(context.Background(), []api.UsageRecord{{
+		Client: "k", Operation: "GET /x", Start: t0.Add(time.Minute), End: t0.Add(2 * time.Minute),
+		Requests: 2, ClientErrors: 1, TotalLatency: 2 * time.Millisecond, MaxLatency: time.Millisecond,
+	}}))
+
+	records := report.Records()
+	require.Len(t, records, 1)
+	got := records[0]
+	assert.Equal(t, t0, got.Start)
+	assert.Equal(t, t0.Add(2*time.Minute), got.End)
+	assert.Equal(t, int64(4), got.Requests)
+	assert.InDelta(t, 0.5, got.ErrorRate(), 1e-9)
+	assert.Equal(t, 3*time.Millisecond, got.MaxLatency)
+	assert.Equal(t, 1500*time.Microsecond, got.MeanLatency())
+
+	report.Reset()
+	assert.Empty(t, report.Records())
+}
+
+func TestUsage_sinkError(t *testing.T) {
+	t.Parallel()
+
+	errs := make(chan error, 1)
+	r := newUsageRouter(api.UsageConfig{
+		Sink: api.UsageSinkFunc(func(context.Context, []api.UsageRecord) error {
+			return errors.New("sink down")
+		}),
+		Client:        func(*http.Request) string { return "k" },
+		FlushInterval: time.Nanosecond,
+		OnError:       func(err error) { errs <- err },
+	})
+
+	usageGet(r, "/items/a", "")
+
+	select {
+	case err := <-errs:
+		assert.EqualError(t, err, "sink down")
+	case <-time.After(time.Second):
+		t.Fatal("OnError not called")
+	}
+}
+
+func TestUsage_requiresSink(t *testing.T) {
+	t.Parallel()
+
+	assert.PanicsWithValue(t, "api: Usage requires a Sink", func() {
+		api.Usage(api.UsageConfig{})
+	})
+}
@@ -34,3 +34,20 @@ func (s *ErrorScope) applyGroup(g *Group) {
 func (s *ErrorScope) applyRoute(ri *routeInfo) {
 	ri.errorOpts = append(ri.errorOpts, s.opts...)
 }
+
+// WithGlobalErrors documents codes on every route of the router, for
+// errors any operation may return, such as those of auth or rate-limit
+// middleware. It is shorthand for WithError(WithErrors(codes...)):
+//
+//	r := api.New(api.WithGlobalErrors(api.CodeUnauthorized, api.CodeTooManyRequests))
+func WithGlobalErrors(codes ...Code) RouterOption {
+	return WithError(WithErrors(codes...))
+}
+
+// WithGroupErrors documents codes on every route of the group and its
+// nested groups. Routes add their own with WithError(WithErrors(...)).
+//
+//	admin := r.Group("/admin", api.WithGroupErrors(api.CodeForbidden))
+func WithGroupErrors(codes ...Code) GroupOption {
+	return WithError(WithErrors(codes...))
+}
@@ -0,0 +1,354 @@
+package api
+
+import (
+	"encoding"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// xsiNamespace is the XML Schema instance namespace that defines xsi:nil.
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// XMLEnvelope configures the XML codec for legacy contracts that wrap every
+// body in a fixed envelope. A SOAP 1.1 service, for example:
+//
+//	api.WithXMLEnvelope(api.XMLEnvelope{
+//	    ContentType: "text/xml",
+//	    Root:        "soap:Envelope",
+//	    Body:        "soap:Body",
+//	    Namespaces:  map[string]string{"soap": "http://schemas.xmlsoap.org/soap/envelope/"},
+//	    Nil:         true,
+//	})
+//
+// Responses are written inside Root (and Body, when set); requests are
+// decoded from the first element inside them, whatever its prefix, so
+// clients may bind the envelope namespace to any prefix.
+type XMLEnvelope struct {
+	// ContentType replaces "application/xml" for negotiation, request
+	// matching, and the spec.
+	ContentType string
+
+	// Root is the qualified name of the outermost element, such as
+	// "soap:Envelope". Empty writes the body as the document element.
+	Root string
+
+	// Body is the qualified name of an element between Root and the body,
+	// such as "soap:Body". Ignored when Root is empty.
+	Body string
+
+	// Namespaces declares prefix → URI bindings on the outermost element.
+	Namespaces map[string]string
+
+	// Nil writes nil pointer fields as empty elements with
+	// xsi:nil="true" instead of omitting them, and decodes such elements
+	// back to nil. Fields tagged omitempty are still omitted.
+	Nil bool
+}
+
+// WithXMLEnvelope switches the built-in XML codec to envelope mode.
+func WithXMLEnvelope(env XMLEnvelope) RouterOption {
+	return RouterOptionFunc(func(r *Router) {
+		r.xmlEnvelope = &env
+	})
+}
+
+// encodeEnvelope writes v inside the configured envelope.
+func (c xmlCodec) encodeEnvelope(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+
+	var open []xml.StartElement
+	if c.env.Root != "" {
+		root := xml.StartElement{Name: xml.Name{Local: c.env.Root}}
+		root.Attr = c.namespaceAttrs()
+		open = append(open, root)
+		if c.env.Body != "" {
+			open = append(open, xml.StartElement{Name: xml.Name{Local: c.env.Body}})
+		}
+	}
+	for _, s := range open {
+		if err := enc.EncodeToken(s); err != nil {
+			return err
+		}
+	}
+
+	if err := c.encodeBody(enc, v, len(open) == 0); err != nil {
+		return err
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		if err := enc.EncodeToken(open[i].End()); err != nil {
+			return err
+		}
+	}
+	return enc.Flush()
+}
+
+// namespaceAttrs returns the xmlns declarations for the outermost element,
+// sorted by prefix.
+func (c xmlCodec) namespaceAttrs() []xml.Attr {
+	ns := make(map[string]string, len(c.env.Namespaces)+1)
+	for p, uri := range c.env.Namespaces {
+		ns[p] = uri
+	}
+	if c.env.Nil {
+		if _, ok := ns["xsi"]; !ok {
+			ns["xsi"] = xsiNamespace
+		}
+	}
+	attrs := make([]xml.Attr, 0, len(ns))
+	for p, uri := range ns {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + p}, Value: uri})
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Name.Local < attrs[j].Name.Local })
+	return attrs
+}
+
+// encodeBody writes v; document is true when v is the document element and
+// must carry the namespace declarations itself.
+func (c xmlCodec) encodeBody(enc *xml.Encoder, v any, document bool) error {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	t := indirectType(rv.Type())
+	start := xml.StartElement{Name: xml.Name{Local: xmlTypeName(t)}}
+	if document {
+		start.Attr = c.namespaceAttrs()
+	}
+	if c.env.Nil && plainXMLStruct(t) {
+		return encodeNillable(enc, rv, start)
+	}
+	if len(start.Attr) == 0 {
+		return enc.Encode(v)
+	}
+	return enc.EncodeElement(v, start)
+}
+
+// encodeNillable writes v as start, emitting xsi:nil for nil pointers at
+// any depth of plain structs. Anything else goes through encoding/xml.
+func encodeNillable(enc *xml.Encoder, v reflect.Value, start xml.StartElement) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xsi:nil"}, Value: "true"})
+			if err := enc.EncodeToken(start); err != nil {
+				return err
+			}
+			return enc.EncodeToken(start.End())
+		}
+		v = v.Elem()
+	}
+	if !plainXMLStruct(v.Type()) {
+		return enc.EncodeElement(v.Interface(), start)
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		if !f.IsExported() || f.Anonymous || f.Name == "XMLName" {
+			continue
+		}
+		name, omitEmpty := xmlFieldName(f)
+		if name == "-" {
+			continue
+		}
+		fv := v.FieldByIndex(f.Index)
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		el := xml.StartElement{Name: xml.Name{Local: name}}
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			for i := range fv.Len() {
+				if err := encodeNillable(enc, fv.Index(i), el); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := encodeNillable(enc, fv, el); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+var (
+	xmlMarshalerType  = reflect.TypeFor[xml.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// plainXMLStruct reports whether t is a struct whose fields are all plain
+// child elements, so encodeNillable can walk it field by field. Attributes,
+// character data, inner XML, comments, nested paths, and custom marshalers
+// fall back to encoding/xml.
+func plainXMLStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct ||
+		reflect.PointerTo(t).Implements(xmlMarshalerType) ||
+		reflect.PointerTo(t).Implements(textMarshalerType) {
+		return false
+	}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() != reflect.Struct {
+			return false
+		}
+		tag := f.Tag.Get("xml")
+		if f.Name == "XMLName" {
+			if strings.Contains(tag, " ") {
+				return false // namespaced root name
+			}
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(name, ">") || strings.Contains(name, " ") {
+			return false
+		}
+		for opt := range strings.SplitSeq(opts, ",") {
+			if opt != "" && opt != "omitempty" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// xmlFieldName returns the element name encoding/xml uses for f.
+func xmlFieldName(f reflect.StructField) (name string, omitEmpty bool) {
+	name, opts, _ := strings.Cut(f.Tag.Get("xml"), ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, strings.Contains(opts, "omitempty")
+}
+
+// xmlTypeName returns the document element name encoding/xml uses for t.
+// A slice or array, other than []byte, is written as one element per item,
+// named after the element type.
+func xmlTypeName(t reflect.Type) string {
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+		return xmlTypeName(indirectType(t.Elem()))
+	}
+	if t.Kind() != reflect.Struct {
+		return t.Name()
+	}
+	if f, ok := t.FieldByName("XMLName"); ok {
+		if name, _, _ := strings.Cut(f.Tag.Get("xml"), ","); name != "" {
+			return name
+		}
+	}
+	return t.Name()
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// decodeEnvelope reads v from inside the configured envelope.
+func (c xmlCodec) decodeEnvelope(r io.Reader, v any) error {
+	var src xml.TokenReader = xml.NewDecoder(r)
+	if c.env.Nil {
+		src = &nilFilter{src: src}
+	}
+	dec := xml.NewTokenDecoder(src)
+
+	depth := 0
+	if c.env.Root != "" {
+		depth = 1
+		if c.env.Body != "" {
+			depth = 2
+		}
+	}
+	for level := 0; ; {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			if level == 0 {
+				return nil
+			}
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if level == depth {
+				return dec.DecodeElement(v, &t)
+			}
+			if want := localName(c.wrapper(level)); t.Name.Local != want {
+				return fmt.Errorf("api: expected <%s> element, got <%s>", want, t.Name.Local)
+			}
+			level++
+		case xml.EndElement:
+			// An empty envelope body leaves v untouched.
+			return nil
+		}
+	}
+}
+
+// wrapper returns the configured element name at depth level.
+func (c xmlCodec) wrapper(level int) string {
+	if level == 0 {
+		return c.env.Root
+	}
+	return c.env.Body
+}
+
+// localName strips the prefix from a qualified name.
+func localName(qname string) string {
+	if _, local, ok := strings.Cut(qname, ":"); ok {
+		return local
+	}
+	return qname
+}
+
+// nilFilter drops elements marked xsi:nil="true", so the fields they
+// populate decode as nil.
+type nilFilter struct {
+	src xml.TokenReader
+}
+
+func (f *nilFilter) Token() (xml.Token, error) {
+	tok, err := f.src.Token()
+	if err != nil {
+		return tok, err
+	}
+	start, ok := tok.(xml.StartElement)
+	if !ok || !isXSINil(start) {
+		return tok, nil
+	}
+	for depth := 1; depth > 0; {
+		t, err := f.src.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return f.Token()
+}
+
+func isXSINil(start xml.StartElement) bool {
+	for _, a := range start.Attr {
+		if a.Name.Space == xsiNamespace && a.Name.Local == "nil" {
+			return a.Value == "true" || a.Value == "1"
+		}
+	}
+	return false
+}
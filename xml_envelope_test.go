@@ -0,0 +1,148 @@
+package api_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/api"
+)
+
+const soapNS = "http://schemas.xmlsoap.org/soap/envelope/"
+
+type xeQuote struct {
+	Symbol string   `xml:"symbol"`
+	Price  *float64 `xml:"price"`
+	Note   *string  `xml:"note,omitempty"`
+	Lots   []int    `xml:"lot"`
+}
+
+type xeReq struct {
+	Body xeQuote
+}
+
+func newEnvelopeRouter(t *testing.T, env api.XMLEnvelope) *api.Router {
+	t.Helper()
+
+	r := api.New(api.WithXMLEnvelope(env))
+	api.Post(r, "/quote", func(_ context.Context, req *xeReq) (*api.Resp[xeQuote], error) {
+		return &api.Resp[xeQuote]{Body: req.Body}, nil
+	})
+	return r
+}
+
+func TestXMLEnvelope_soap(t *testing.T) {
+	t.Parallel()
+
+	r := newEnvelopeRouter(t, api.XMLEnvelope{
+		ContentType: "text/xml",
+		Root:        "soap:Envelope",
+		Body:        "soap:Body",
+		Namespaces:  map[string]string{"soap": soapNS},
+		Nil:         true,
+	})
+
+	// The client binds the envelope namespace to its own prefix.
+	in := `<?xml version="1.0"?>
+<s:Envelope xmlns:s="` + soapNS + `" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <s:Body>
+    <xeQuote><symbol>ACME</symbol><price xsi:nil="true"/><lot>1</lot><lot>2</lot></xeQuote>
+  </s:Body>
+</s:Envelope>`
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/quote", strings.NewReader(in))
+	req.Header.Set("Content-Type", "text/xml")
+	req.Header.Set("Accept", "text/xml")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "text/xml", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<soap:Envelope xmlns:soap="`+soapNS+`" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">`+
+		`<soap:Body><xeQuote><symbol>ACME</symbol><price xsi:nil="true"></price><lot>1</lot><lot>2</lot></xeQuote></soap:Body>`+
+		`</soap:Envelope>`, rec.Body.String())
+
+	spec := r.Spec()
+	assert.Contains(t, spec.Paths["/quote"]["post"].RequestBody.Content, "text/xml")
+}
+
+func TestXMLEnvelope_rootOnly(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		env  api.XMLEnvelope
+		in   string
+		want string
+	}{
+		"wrapper": {
+			env:  api.XMLEnvelope{Root: "Message"},
+			in:   `<Message><xeQuote><symbol>X</symbol><price>1.5</price></xeQuote></Message>`,
+			want: `<Message><xeQuote><symbol>X</symbol><price>1.5</price></xeQuote></Message>`,
+		},
+		"namespaces on document element": {
+			env:  api.XMLEnvelope{Namespaces: map[string]string{"m": "urn:m"}},
+			in:   `<xeQuote><symbol>X</symbol></xeQuote>`,
+			want: `<xeQuote xmlns:m="urn:m"><symbol>X</symbol></xeQuote>`,
+		},
+		"nil without wrapper": {
+			env:  api.XMLEnvelope{Nil: true},
+			in:   `<xeQuote><symbol>X</symbol></xeQuote>`,
+			want: `<xeQuote xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><symbol>X</symbol><price xsi:nil="true"></price></xeQuote>`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := newEnvelopeRouter(t, tc.env)
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/quote", strings.NewReader(tc.in))
+			req.Header.Set("Content-Type", "application/xml")
+			req.Header.Set("Accept", "application/xml")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			body, err := io.ReadAll(rec.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, strings.TrimPrefix(string(body), `<?xml version="1.0" encoding="UTF-8"?>`+"\n"))
+		})
+	}
+}
+
+func TestXMLEnvelope_wrongRoot(t *testing.T) {
+	t.Parallel()
+
+	r := newEnvelopeRouter(t, api.XMLEnvelope{Root: "soap:Envelope", Namespaces: map[string]string{"soap": soapNS}})
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/quote",
+		strings.NewReader(`<xeQuote><symbol>X</symbol></xeQuote>`))
+	req.Header.Set("Content-Type", "application/xml")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestXMLEnvelope_document_slice(t *testing.T) {
+	t.Parallel()
+
+	r := api.New(api.WithXMLEnvelope(api.XMLEnvelope{Namespaces: map[string]string{"q": "urn:quotes"}}))
+	api.Get(r, "/quotes", func(_ context.Context, _ *api.Void) (*api.Resp[[]*xeQuote], error) {
+		return &api.Resp[[]*xeQuote]{Body: []*xeQuote{{Symbol: "A"}, {Symbol: "B"}}}, nil
+	})
+
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/quotes", nil)
+	req.Header.Set("Accept", "application/xml")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `<xeQuote xmlns:q="urn:quotes"><symbol>A</symbol>`)
+	assert.Contains(t, rec.Body.String(), `<symbol>B</symbol>`)
+}